@@ -13,8 +13,10 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
 	cryptoutil "github.com/moneyvessel/kifu/internal/infrastructure/crypto"
 	"github.com/moneyvessel/kifu/internal/infrastructure/database"
+	"github.com/moneyvessel/kifu/internal/infrastructure/marketdata"
 	"github.com/moneyvessel/kifu/internal/infrastructure/notification"
 	"github.com/moneyvessel/kifu/internal/infrastructure/repositories"
 	"github.com/moneyvessel/kifu/internal/interfaces/http"
@@ -69,26 +71,71 @@ func Run() error {
 	outcomeRepo := repositories.NewOutcomeRepository(pool)
 	accuracyRepo := repositories.NewAIOpinionAccuracyRepository(pool)
 	noteRepo := repositories.NewReviewNoteRepository(pool)
+	searchRepo := repositories.NewSearchRepository(pool)
 	alertRuleRepo := repositories.NewAlertRuleRepository(pool)
 	alertRepo := repositories.NewAlertRepository(pool)
 	alertBriefingRepo := repositories.NewAlertBriefingRepository(pool)
 	alertDecisionRepo := repositories.NewAlertDecisionRepository(pool)
 	alertOutcomeRepo := repositories.NewAlertOutcomeRepository(pool)
+	alertEventRepo := repositories.NewAlertEventRepository(pool)
 	channelRepo := repositories.NewNotificationChannelRepository(pool)
 	verifyCodeRepo := repositories.NewTelegramVerifyCodeRepository(pool)
+	notifCodeRepo := repositories.NewNotificationVerifyCodeRepository(pool)
+	deliveryRepo := repositories.NewNotificationDeliveryRepository(pool)
+	notifPrefRepo := repositories.NewNotificationPreferenceRepository(pool)
+	tgPromptRepo := repositories.NewTelegramReplyPromptRepository(pool)
 	portfolioRepo := repositories.NewPortfolioRepository(pool)
 	manualPositionRepo := repositories.NewManualPositionRepository(pool)
 	safetyRepo := repositories.NewTradeSafetyReviewRepository(pool)
 	guidedReviewRepo := repositories.NewGuidedReviewRepository(pool)
-	poller := jobs.NewTradePoller(pool, exchangeRepo, userSymbolRepo, tradeSyncRepo, portfolioRepo, encKey)
+	reviewTemplateRepo := repositories.NewReviewTemplateRepository(pool)
+	playbookRepo := repositories.NewPlaybookRepository(pool)
+	planAdherenceService := services.NewPlanAdherenceService(repositories.NewPlanExecutionRepository(pool), manualPositionRepo)
+	positionExcursionRepo := repositories.NewPositionExcursionRepository(pool)
+	behaviorService := services.NewBehaviorPatternService(repositories.NewBehaviorRepository(pool), userRepo)
+	candleRepo := repositories.NewCandleRepository(pool)
+	candleStore := services.NewCandleStore(candleRepo, marketdata.NewKlineClient())
+	safetyScoringService := services.NewTradeSafetyScoringService(repositories.NewTradeSafetySignalRepository(pool), candleStore)
+	marketContextService := services.NewMarketContextService(repositories.NewBubbleFingerprintRepository(pool), candleStore)
 
 	// Telegram sender (optional - only if TELEGRAM_BOT_TOKEN is set)
 	var tgSender *notification.TelegramSender
 	tgBotToken := strings.TrimSpace(os.Getenv("TELEGRAM_BOT_TOKEN"))
 	tgBotUsername := strings.TrimSpace(os.Getenv("TELEGRAM_BOT_USERNAME"))
+	// The webhook rejects every update unless TELEGRAM_WEBHOOK_SECRET is set.
+	tgWebhookSecret := strings.TrimSpace(os.Getenv("TELEGRAM_WEBHOOK_SECRET"))
 	if tgBotToken != "" {
 		tgSender = notification.NewTelegramSender(tgBotToken, channelRepo)
+		if tgWebhookSecret == "" {
+			log.Printf("telegram: TELEGRAM_WEBHOOK_SECRET is not set; webhook updates will be rejected")
+		} else if webhookURL := strings.TrimSpace(os.Getenv("TELEGRAM_WEBHOOK_URL")); webhookURL != "" {
+			if err := tgSender.SetWebhook(context.Background(), webhookURL, tgWebhookSecret); err != nil {
+				log.Printf("telegram: register webhook failed: %v", err)
+			}
+		}
+	}
+
+	// Dispatcher routes alerts to every verified channel a user has enabled.
+	dispatcher := notification.NewDispatcher(channelRepo, deliveryRepo, notifPrefRepo)
+	if tgSender != nil {
+		dispatcher.Register(entities.ChannelTelegram, tgSender)
 	}
+	dispatcher.Register(entities.ChannelDiscord, notification.NewDiscordSender())
+	dispatcher.Register(entities.ChannelSlack, notification.NewSlackSender())
+	// Email sender (optional - only if SMTP_HOST is set)
+	if smtpHost := strings.TrimSpace(os.Getenv("SMTP_HOST")); smtpHost != "" {
+		dispatcher.Register(entities.ChannelEmail, notification.NewEmailSender(notification.SMTPConfig{
+			Host:     smtpHost,
+			Port:     strings.TrimSpace(os.Getenv("SMTP_PORT")),
+			Username: strings.TrimSpace(os.Getenv("SMTP_USERNAME")),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     strings.TrimSpace(os.Getenv("SMTP_FROM")),
+		}))
+	}
+
+	alertLifecycleService := services.NewAlertLifecycleService(alertRepo, alertEventRepo, dispatcher)
+	riskLimitService := services.NewRiskLimitService(repositories.NewRiskLimitRepository(pool), userRepo, alertRepo, dispatcher)
+	poller := jobs.NewTradePoller(pool, exchangeRepo, userSymbolRepo, tradeSyncRepo, portfolioRepo, riskLimitService, encKey)
 
 	app := fiber.New(fiber.Config{
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
@@ -118,6 +165,7 @@ func Run() error {
 		path := c.Path()
 		if path == "/health" ||
 			strings.HasPrefix(path, "/api/v1/auth/") ||
+			strings.HasPrefix(path, "/api/v1/public/") ||
 			path == "/api/v1/webhook/telegram" ||
 			path == "/api/v1/market/klines" {
 			return c.Next()
@@ -144,7 +192,14 @@ func Run() error {
 		})(c)
 	})
 
-	summaryPackService := services.NewSummaryPackService(tradeRepo)
+	summaryPackService := services.NewSummaryPackService(tradeRepo, userRepo)
+	packScheduleService := services.NewSummaryPackScheduleService(
+		repositories.NewSummaryPackScheduleRepository(pool), summaryPackRepo, runRepo, userRepo,
+		summaryPackService, dispatcher,
+	)
+	packShareService := services.NewSummaryPackShareService(
+		repositories.NewSummaryPackShareRepository(pool), summaryPackRepo, summaryPackService,
+	)
 
 	http.RegisterRoutes(
 		app,
@@ -162,25 +217,44 @@ func Run() error {
 		outcomeRepo,
 		accuracyRepo,
 		noteRepo,
+		searchRepo,
 		alertRuleRepo,
 		alertRepo,
 		alertBriefingRepo,
 		alertDecisionRepo,
 		alertOutcomeRepo,
+		alertLifecycleService,
 		channelRepo,
 		verifyCodeRepo,
+		notifCodeRepo,
+		deliveryRepo,
+		notifPrefRepo,
+		dispatcher,
 		tgSender,
+		tgPromptRepo,
 		tgBotUsername,
+		tgWebhookSecret,
 		portfolioRepo,
 		manualPositionRepo,
 		safetyRepo,
+		safetyScoringService,
 		guidedReviewRepo,
+		reviewTemplateRepo,
+		playbookRepo,
+		planAdherenceService,
+		positionExcursionRepo,
+		behaviorService,
+		riskLimitService,
 		poller,
 		encKey,
 		jwtSecret,
 		runRepo,
 		summaryPackRepo,
 		summaryPackService,
+		packScheduleService,
+		packShareService,
+		candleStore,
+		marketContextService,
 	)
 
 	go poller.Start(context.Background())
@@ -190,7 +264,7 @@ func Run() error {
 
 	outcomeCalcEnabled := !strings.EqualFold(strings.TrimSpace(os.Getenv("OUTCOME_CALC_ENABLED")), "false")
 	if outcomeCalcEnabled {
-		outcomes := jobs.NewOutcomeCalculator(outcomeRepo)
+		outcomes := jobs.NewOutcomeCalculator(outcomeRepo, candleStore, marketdata.NewStockPriceClient())
 		outcomes.Start(context.Background())
 	} else {
 		log.Println("outcome calc: disabled by OUTCOME_CALC_ENABLED=false")
@@ -200,23 +274,55 @@ func Run() error {
 	accuracyCalc.Start(context.Background())
 
 	// Alert briefing service
-	var briefingSender notification.Sender
-	if tgSender != nil {
-		briefingSender = tgSender
-	}
 	briefingService := services.NewAlertBriefingService(
 		alertRepo, alertBriefingRepo, aiProviderRepo, userAIKeyRepo,
-		channelRepo, tradeRepo, encKey, briefingSender,
+		channelRepo, tradeRepo, candleStore, encKey, dispatcher,
 	)
 
+	// Notification outbox: retries, quiet-hours holds and digests
+	outboxJob := jobs.NewNotificationOutboxJob(dispatcher)
+	outboxJob.Start(context.Background())
+
 	// Alert monitor job
 	alertMonitor := jobs.NewAlertMonitor(alertRuleRepo, alertRepo, briefingService.HandleTrigger)
 	alertMonitor.Start(context.Background())
 
+	// Alert lifecycle job: expiry, snooze re-notification and escalation
+	alertLifecycleJob := jobs.NewAlertLifecycleJob(alertLifecycleService)
+	alertLifecycleJob.Start(context.Background())
+
 	// Alert outcome calculator job
-	alertOutcomeCalc := jobs.NewAlertOutcomeCalculator(alertOutcomeRepo)
+	alertOutcomeCalc := jobs.NewAlertOutcomeCalculator(alertOutcomeRepo, candleStore)
 	alertOutcomeCalc.Start(context.Background())
 
+	// Market-context fingerprints for similarity search
+	fingerprintJob := jobs.NewBubbleFingerprintJob(marketContextService)
+	fingerprintJob.Start(context.Background())
+
+	// MAE/MFE and R-multiple for closed manual positions and synced round trips
+	excursionJob := jobs.NewPositionExcursionJob(services.NewPositionExcursionService(positionExcursionRepo, candleStore))
+	excursionJob.Start(context.Background())
+
+	// Plan vs execution scoring for manual positions
+	planAdherenceJob := jobs.NewPlanAdherenceJob(planAdherenceService)
+	planAdherenceJob.Start(context.Background())
+
+	// Risk limit breaches and discipline scores for newly stored trades
+	riskLimitJob := jobs.NewRiskLimitJob(riskLimitService)
+	riskLimitJob.Start(context.Background())
+
+	// Mistake checks for newly stored trades in the daily safety check
+	safetyScoringJob := jobs.NewTradeSafetyScoringJob(safetyScoringService)
+	safetyScoringJob.Start(context.Background())
+
+	// Revenge trading, overtrading and off-hours detection
+	behaviorJob := jobs.NewBehaviorPatternJob(behaviorService)
+	behaviorJob.Start(context.Background())
+
+	// Scheduled summary pack digests and pack retention
+	packScheduleJob := jobs.NewSummaryPackScheduleJob(packScheduleService)
+	packScheduleJob.Start(context.Background())
+
 	positionCalc := jobs.NewPositionCalculator(portfolioRepo)
 	positionCalc.Start(context.Background())
 
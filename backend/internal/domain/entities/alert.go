@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,34 +17,98 @@ const (
 type AlertStatus string
 
 const (
-	AlertStatusPending AlertStatus = "pending"
-	AlertStatusBriefed AlertStatus = "briefed"
-	AlertStatusDecided AlertStatus = "decided"
-	AlertStatusExpired AlertStatus = "expired"
+	AlertStatusPending      AlertStatus = "pending"
+	AlertStatusBriefed      AlertStatus = "briefed"
+	AlertStatusAcknowledged AlertStatus = "acknowledged"
+	AlertStatusSnoozed      AlertStatus = "snoozed"
+	AlertStatusDecided      AlertStatus = "decided"
+	AlertStatusExpired      AlertStatus = "expired"
 )
 
+// IsOpen reports whether the alert still awaits the user: it can be snoozed,
+// acknowledged, escalated or expired.
+func (s AlertStatus) IsOpen() bool {
+	switch s {
+	case AlertStatusPending, AlertStatusBriefed, AlertStatusAcknowledged, AlertStatusSnoozed:
+		return true
+	}
+	return false
+}
+
+// alertTransitions lists the statuses each status may move to through a plain
+// status update. Decided and expired are terminal, and a late briefing never
+// overrides what the user already did with the alert.
+var alertTransitions = map[AlertStatus][]AlertStatus{
+	AlertStatusPending:      {AlertStatusBriefed, AlertStatusAcknowledged, AlertStatusSnoozed, AlertStatusDecided, AlertStatusExpired},
+	AlertStatusBriefed:      {AlertStatusAcknowledged, AlertStatusSnoozed, AlertStatusDecided, AlertStatusExpired},
+	AlertStatusAcknowledged: {AlertStatusSnoozed, AlertStatusDecided, AlertStatusExpired},
+	AlertStatusSnoozed:      {AlertStatusBriefed, AlertStatusAcknowledged, AlertStatusDecided, AlertStatusExpired},
+}
+
+// CanTransitionTo reports whether an alert in status s may move to status to.
+func (s AlertStatus) CanTransitionTo(to AlertStatus) bool {
+	for _, allowed := range alertTransitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
 type Alert struct {
-	ID           uuid.UUID     `json:"id"`
-	UserID       uuid.UUID     `json:"user_id"`
-	RuleID       uuid.UUID     `json:"rule_id"`
-	Symbol       string        `json:"symbol"`
-	TriggerPrice string        `json:"trigger_price"`
-	TriggerReason string       `json:"trigger_reason"`
-	Severity     AlertSeverity `json:"severity"`
-	Status       AlertStatus   `json:"status"`
-	NotifiedAt   *time.Time    `json:"notified_at,omitempty"`
-	CreatedAt    time.Time     `json:"created_at"`
+	ID             uuid.UUID     `json:"id"`
+	UserID         uuid.UUID     `json:"user_id"`
+	RuleID         uuid.UUID     `json:"rule_id"`
+	Symbol         string        `json:"symbol"`
+	TriggerPrice   string        `json:"trigger_price"`
+	TriggerReason  string        `json:"trigger_reason"`
+	Severity       AlertSeverity `json:"severity"`
+	Status         AlertStatus   `json:"status"`
+	NotifiedAt     *time.Time    `json:"notified_at,omitempty"`
+	ExpiresAt      time.Time     `json:"expires_at"`
+	SnoozedUntil   *time.Time    `json:"snoozed_until,omitempty"`
+	AcknowledgedAt *time.Time    `json:"acknowledged_at,omitempty"`
+	EscalatedAt    *time.Time    `json:"escalated_at,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+type AlertEventType string
+
+const (
+	AlertEventCreated      AlertEventType = "created"
+	AlertEventBriefed      AlertEventType = "briefed"
+	AlertEventNotified     AlertEventType = "notified"
+	AlertEventAcknowledged AlertEventType = "acknowledged"
+	AlertEventSnoozed      AlertEventType = "snoozed"
+	AlertEventRenotified   AlertEventType = "renotified"
+	AlertEventEscalated    AlertEventType = "escalated"
+	AlertEventDecided      AlertEventType = "decided"
+	AlertEventDismissed    AlertEventType = "dismissed"
+	AlertEventExpired      AlertEventType = "expired"
+)
+
+// AlertEvent is one entry in an alert's history. FromStatus and ToStatus are
+// equal for events that don't change the status (notified, escalated).
+type AlertEvent struct {
+	ID         uuid.UUID       `json:"id"`
+	AlertID    uuid.UUID       `json:"alert_id"`
+	UserID     uuid.UUID       `json:"user_id"`
+	EventType  AlertEventType  `json:"event_type"`
+	FromStatus *AlertStatus    `json:"from_status,omitempty"`
+	ToStatus   *AlertStatus    `json:"to_status,omitempty"`
+	Detail     json.RawMessage `json:"detail,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
 }
 
 type AlertBriefing struct {
-	ID        uuid.UUID `json:"id"`
-	AlertID   uuid.UUID `json:"alert_id"`
-	Provider  string    `json:"provider"`
-	Model     string    `json:"model"`
-	Prompt    string    `json:"prompt"`
-	Response  string    `json:"response"`
-	TokensUsed *int     `json:"tokens_used,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
+	ID         uuid.UUID `json:"id"`
+	AlertID    uuid.UUID `json:"alert_id"`
+	Provider   string    `json:"provider"`
+	Model      string    `json:"model"`
+	Prompt     string    `json:"prompt"`
+	Response   string    `json:"response"`
+	TokensUsed *int      `json:"tokens_used,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type DecisionAction string
@@ -10,32 +10,54 @@ import (
 type RuleType string
 
 const (
-	RuleTypePriceChange    RuleType = "price_change"
-	RuleTypeMACross        RuleType = "ma_cross"
-	RuleTypePriceLevel     RuleType = "price_level"
+	RuleTypePriceChange     RuleType = "price_change"
+	RuleTypeMACross         RuleType = "ma_cross"
+	RuleTypePriceLevel      RuleType = "price_level"
 	RuleTypeVolatilitySpike RuleType = "volatility_spike"
+	// RuleTypeRiskLimit holds a user's risk limits. It is evaluated when
+	// trades arrive rather than by the price monitor.
+	RuleTypeRiskLimit RuleType = "risk_limit"
 )
 
 type AlertRule struct {
-	ID               uuid.UUID       `json:"id"`
-	UserID           uuid.UUID       `json:"user_id"`
-	Name             string          `json:"name"`
-	Symbol           string          `json:"symbol"`
-	RuleType         RuleType        `json:"rule_type"`
-	Config           json.RawMessage `json:"config"`
-	CooldownMinutes  int             `json:"cooldown_minutes"`
-	Enabled          bool            `json:"enabled"`
-	LastTriggeredAt  *time.Time      `json:"last_triggered_at,omitempty"`
-	LastCheckState   json.RawMessage `json:"last_check_state,omitempty"`
-	CreatedAt        time.Time       `json:"created_at"`
-	UpdatedAt        time.Time       `json:"updated_at"`
+	ID                   uuid.UUID       `json:"id"`
+	UserID               uuid.UUID       `json:"user_id"`
+	Name                 string          `json:"name"`
+	Symbol               string          `json:"symbol"`
+	RuleType             RuleType        `json:"rule_type"`
+	Config               json.RawMessage `json:"config"`
+	CooldownMinutes      int             `json:"cooldown_minutes"`
+	ExpiryMinutes        int             `json:"expiry_minutes"`
+	EscalateAfterMinutes int             `json:"escalate_after_minutes"`
+	Enabled              bool            `json:"enabled"`
+	LastTriggeredAt      *time.Time      `json:"last_triggered_at,omitempty"`
+	LastCheckState       json.RawMessage `json:"last_check_state,omitempty"`
+	CreatedAt            time.Time       `json:"created_at"`
+	UpdatedAt            time.Time       `json:"updated_at"`
+}
+
+const (
+	DefaultAlertExpiryMinutes   = 1440
+	MinAlertExpiryMinutes       = 5
+	MaxAlertExpiryMinutes       = 10080
+	DefaultEscalateAfterMinutes = 15
+	MaxEscalateAfterMinutes     = 1440
+)
+
+// AlertExpiry is how long an alert raised by this rule stays open.
+func (r *AlertRule) AlertExpiry() time.Duration {
+	minutes := r.ExpiryMinutes
+	if minutes <= 0 {
+		minutes = DefaultAlertExpiryMinutes
+	}
+	return time.Duration(minutes) * time.Minute
 }
 
 type PriceChangeConfig struct {
-	Direction      string `json:"direction"`       // "drop" | "rise" | "both"
-	ThresholdType  string `json:"threshold_type"`  // "absolute" | "percent"
+	Direction      string `json:"direction"`      // "drop" | "rise" | "both"
+	ThresholdType  string `json:"threshold_type"` // "absolute" | "percent"
 	ThresholdValue string `json:"threshold_value"`
-	Reference      string `json:"reference"`       // "24h" | "1h" | "4h"
+	Reference      string `json:"reference"` // "24h" | "1h" | "4h"
 }
 
 type MACrossConfig struct {
@@ -54,8 +76,21 @@ type VolatilitySpikeConfig struct {
 	Multiplier string `json:"multiplier"`
 }
 
+// RiskLimitConfig is the config of a risk_limit rule. Nil fields are not
+// enforced. Amounts are in the quote currency of the trades.
+type RiskLimitConfig struct {
+	MaxDailyLoss        *string `json:"max_daily_loss,omitempty"`
+	MaxTradesPerDay     *int    `json:"max_trades_per_day,omitempty"`
+	MaxPositionNotional *string `json:"max_position_notional,omitempty"`
+	MaxLeverage         *string `json:"max_leverage,omitempty"`
+	// After CooldownAfterLosses losing trades in a row, no new trade should
+	// be opened for CooldownMinutes.
+	CooldownAfterLosses *int `json:"cooldown_after_losses,omitempty"`
+	CooldownMinutes     *int `json:"cooldown_minutes,omitempty"`
+}
+
 type CheckState struct {
-	LastPrice    string `json:"last_price,omitempty"`
-	WasAboveMA   *bool  `json:"was_above_ma,omitempty"`
-	WasAboveLevel *bool `json:"was_above_level,omitempty"`
+	LastPrice     string `json:"last_price,omitempty"`
+	WasAboveMA    *bool  `json:"was_above_ma,omitempty"`
+	WasAboveLevel *bool  `json:"was_above_level,omitempty"`
 }
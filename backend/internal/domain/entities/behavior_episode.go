@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BehaviorPattern string
+
+const (
+	// BehaviorRevengeReentry is a new entry within minutes of a losing close.
+	BehaviorRevengeReentry BehaviorPattern = "revenge_reentry"
+	// BehaviorSizeEscalation is an entry well above the usual size after a
+	// streak of losses.
+	BehaviorSizeEscalation BehaviorPattern = "size_escalation"
+	// BehaviorOvertrading is a day with far more trades than the baseline.
+	BehaviorOvertrading BehaviorPattern = "overtrading"
+	// BehaviorOffHours is trading in the small hours of the user's timezone.
+	BehaviorOffHours BehaviorPattern = "off_hours"
+)
+
+type BehaviorSeverity string
+
+const (
+	BehaviorSeverityLow    BehaviorSeverity = "low"
+	BehaviorSeverityMedium BehaviorSeverity = "medium"
+	BehaviorSeverityHigh   BehaviorSeverity = "high"
+)
+
+type BehaviorEpisode struct {
+	ID         uuid.UUID        `json:"id"`
+	UserID     uuid.UUID        `json:"user_id"`
+	Pattern    BehaviorPattern  `json:"pattern"`
+	Symbol     string           `json:"symbol,omitempty"`
+	Severity   BehaviorSeverity `json:"severity"`
+	StartedAt  time.Time        `json:"started_at"`
+	EndedAt    time.Time        `json:"ended_at"`
+	TradeDate  string           `json:"trade_date"`
+	TradeCount int              `json:"trade_count"`
+	Details    json.RawMessage  `json:"details"`
+	DetectedAt time.Time        `json:"detected_at"`
+}
@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FingerprintStatus string
+
+const (
+	FingerprintReady FingerprintStatus = "ready"
+	// FingerprintUnavailable marks bubbles whose market has no candle history
+	// we can read, so they aren't retried on every pass.
+	FingerprintUnavailable FingerprintStatus = "unavailable"
+	// FingerprintRetry marks bubbles whose candles failed to load for a
+	// reason that may pass, such as an exchange outage. They are picked up
+	// again after RetryAfter.
+	FingerprintRetry FingerprintStatus = "retry"
+)
+
+// BubbleFingerprint describes the market in the window that closed just
+// before a bubble's candle. Vectors are only comparable within one Version.
+type BubbleFingerprint struct {
+	BubbleID       uuid.UUID         `json:"bubble_id"`
+	UserID         uuid.UUID         `json:"user_id"`
+	Version        string            `json:"version"`
+	Status         FingerprintStatus `json:"status"`
+	CandleInterval string            `json:"candle_interval"`
+	WindowEnd      time.Time         `json:"window_end"`
+	Vector         []float64         `json:"vector,omitempty"`
+	Attempts       int               `json:"attempts"`
+	RetryAfter     *time.Time        `json:"retry_after,omitempty"`
+	ComputedAt     time.Time         `json:"computed_at"`
+}
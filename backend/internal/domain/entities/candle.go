@@ -0,0 +1,33 @@
+package entities
+
+import "time"
+
+const (
+	CandleVenueBinance = "binance"
+	CandleVenueUpbit   = "upbit"
+)
+
+// Candle is a single OHLCV bar. Prices are kept as decimal strings.
+type Candle struct {
+	Venue     string    `json:"venue"`
+	Symbol    string    `json:"symbol"`
+	Interval  string    `json:"interval"`
+	OpenTime  time.Time `json:"open_time"`
+	Open      string    `json:"open"`
+	High      string    `json:"high"`
+	Low       string    `json:"low"`
+	Close     string    `json:"close"`
+	Volume    string    `json:"volume"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// CandleEmptyRange is an open-time range the venue returned no bars for,
+// such as the time before a listing or after a delisting.
+type CandleEmptyRange struct {
+	Venue     string    `json:"venue"`
+	Symbol    string    `json:"symbol"`
+	Interval  string    `json:"interval"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	CheckedAt time.Time `json:"checked_at"`
+}
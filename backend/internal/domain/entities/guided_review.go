@@ -15,6 +15,14 @@ const (
 	GuidedReviewStatusSkipped    = "skipped"
 )
 
+// Review cadences. A daily review covers one day; weekly reviews run Monday
+// through Sunday and monthly reviews cover a calendar month.
+const (
+	ReviewCadenceDaily   = "daily"
+	ReviewCadenceWeekly  = "weekly"
+	ReviewCadenceMonthly = "monthly"
+)
+
 // Intent constants (Layer 1)
 const (
 	IntentTechnicalSignal = "technical_signal"
@@ -47,14 +55,35 @@ const (
 )
 
 type GuidedReview struct {
-	ID          uuid.UUID  `json:"id"`
-	UserID      uuid.UUID  `json:"user_id"`
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+	// ReviewDate is the first day of the period and PeriodEnd the last.
 	ReviewDate  string     `json:"review_date"`
+	PeriodEnd   string     `json:"period_end"`
+	Cadence     string     `json:"cadence"`
+	TemplateID  *uuid.UUID `json:"template_id,omitempty"`
 	Status      string     `json:"status"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 }
 
+// ReviewPeriod returns the first and last day of the cadence period that
+// contains day. ok is false for an unknown cadence.
+func ReviewPeriod(cadence string, day time.Time) (start, end time.Time, ok bool) {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	switch cadence {
+	case ReviewCadenceDaily:
+		return day, day, true
+	case ReviewCadenceWeekly:
+		start = day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 6), true
+	case ReviewCadenceMonthly:
+		start = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1), true
+	}
+	return time.Time{}, time.Time{}, false
+}
+
 type GuidedReviewItem struct {
 	ID           uuid.UUID       `json:"id"`
 	ReviewID     uuid.UUID       `json:"review_id"`
@@ -70,10 +99,39 @@ type GuidedReviewItem struct {
 	Memo         *string         `json:"memo,omitempty"`
 	OrderIndex   int             `json:"order_index"`
 	CreatedAt    time.Time       `json:"created_at"`
+	// PlanAdherence is set when a manual position plan on the same symbol
+	// had fills on the review day.
+	PlanAdherence *PlanAdherenceSummary `json:"plan_adherence,omitempty"`
+	// DetectedPatterns lists behavior patterns (revenge_reentry, overtrading,
+	// ...) detected on this symbol or for the whole review day.
+	DetectedPatterns []string `json:"detected_patterns,omitempty"`
+	// Answers holds replies to the review template questions by key.
+	Answers json.RawMessage `json:"answers,omitempty"`
+	// DailyAnswers rolls up the daily review answers on this symbol for
+	// weekly and monthly reviews.
+	DailyAnswers *PeriodAnswerSummary `json:"daily_answers,omitempty"`
+}
+
+type PeriodAnswerSummary struct {
+	ReviewedDays   int            `json:"reviewed_days"`
+	AnsweredItems  int            `json:"answered_items"`
+	Intents        map[string]int `json:"intents"`
+	Emotions       map[string]int `json:"emotions"`
+	PatternMatches map[string]int `json:"pattern_matches"`
+}
+
+type PlanAdherenceSummary struct {
+	PositionID           uuid.UUID      `json:"position_id"`
+	Score                *int           `json:"score,omitempty"`
+	EntrySlippagePercent *string        `json:"entry_slippage_percent,omitempty"`
+	SizeDeviationPercent *string        `json:"size_deviation_percent,omitempty"`
+	StopStatus           PlanStopStatus `json:"stop_status"`
+	EarlyExit            *bool          `json:"early_exit,omitempty"`
 }
 
 type UserStreak struct {
 	UserID         uuid.UUID `json:"user_id"`
+	Cadence        string    `json:"cadence"`
 	CurrentStreak  int       `json:"current_streak"`
 	LongestStreak  int       `json:"longest_streak"`
 	LastReviewDate *string   `json:"last_review_date,omitempty"`
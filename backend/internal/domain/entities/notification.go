@@ -12,6 +12,9 @@ type ChannelType string
 const (
 	ChannelTelegram ChannelType = "telegram"
 	ChannelWebPush  ChannelType = "web_push"
+	ChannelEmail    ChannelType = "email"
+	ChannelDiscord  ChannelType = "discord"
+	ChannelSlack    ChannelType = "slack"
 )
 
 type NotificationChannel struct {
@@ -21,13 +24,146 @@ type NotificationChannel struct {
 	Config      json.RawMessage `json:"config"`
 	Enabled     bool            `json:"enabled"`
 	Verified    bool            `json:"verified"`
+	Severities  []string        `json:"severities"`
 	CreatedAt   time.Time       `json:"created_at"`
 }
 
+// RoutesSeverity reports whether alerts of the given severity go to this channel.
+// An empty list routes everything.
+func (c *NotificationChannel) RoutesSeverity(severity string) bool {
+	if len(c.Severities) == 0 {
+		return true
+	}
+	for _, s := range c.Severities {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
+
 type TelegramConfig struct {
 	ChatID int64 `json:"chat_id"`
 }
 
+type EmailConfig struct {
+	Address string `json:"address"`
+}
+
+type WebhookConfig struct {
+	WebhookURL string `json:"webhook_url"`
+}
+
+type NotificationVerifyCode struct {
+	ID          uuid.UUID   `json:"id"`
+	UserID      uuid.UUID   `json:"user_id"`
+	ChannelType ChannelType `json:"channel_type"`
+	Code        string      `json:"code"`
+	ExpiresAt   time.Time   `json:"expires_at"`
+	Used        bool        `json:"used"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+type DeliveryStatus string
+
+// A delivery starts pending, moves to failed while retries remain, and ends
+// as sent or dead.
+const (
+	DeliveryStatusPending DeliveryStatus = "pending"
+	DeliveryStatusSent    DeliveryStatus = "sent"
+	DeliveryStatusFailed  DeliveryStatus = "failed"
+	DeliveryStatusDead    DeliveryStatus = "dead"
+)
+
+// NotificationDelivery is one outbox row: a message queued for one channel.
+type NotificationDelivery struct {
+	ID            uuid.UUID      `json:"id"`
+	UserID        uuid.UUID      `json:"user_id"`
+	ChannelType   ChannelType    `json:"channel_type"`
+	AlertID       *uuid.UUID     `json:"alert_id,omitempty"`
+	Title         string         `json:"title"`
+	Body          string         `json:"body"`
+	DeepLink      string         `json:"deep_link,omitempty"`
+	Severity      string         `json:"severity"`
+	Digest        bool           `json:"digest"`
+	Status        DeliveryStatus `json:"status"`
+	Attempts      int            `json:"attempts"`
+	NextAttemptAt time.Time      `json:"next_attempt_at"`
+	Error         *string        `json:"error,omitempty"`
+	SentAt        *time.Time     `json:"sent_at,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// NotificationPreference holds per-user quiet hours and digest settings.
+// Quiet hours are minutes from local midnight and may wrap past midnight.
+// Timezone is the user's profile timezone, not a separate setting.
+type NotificationPreference struct {
+	UserID                uuid.UUID `json:"user_id"`
+	Timezone              string    `json:"timezone"`
+	QuietHoursEnabled     bool      `json:"quiet_hours_enabled"`
+	QuietStartMinute      int       `json:"quiet_start_minute"`
+	QuietEndMinute        int       `json:"quiet_end_minute"`
+	UrgentBypassQuiet     bool      `json:"urgent_bypass_quiet"`
+	DigestEnabled         bool      `json:"digest_enabled"`
+	DigestIntervalMinutes int       `json:"digest_interval_minutes"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+func DefaultNotificationPreference(userID uuid.UUID) *NotificationPreference {
+	return &NotificationPreference{
+		UserID:                userID,
+		Timezone:              DefaultUserTimezone,
+		QuietStartMinute:      23 * 60,
+		QuietEndMinute:        7 * 60,
+		UrgentBypassQuiet:     true,
+		DigestIntervalMinutes: 60,
+	}
+}
+
+func (p *NotificationPreference) Location() *time.Location {
+	if loc, err := time.LoadLocation(p.Timezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+func (p *NotificationPreference) InQuietHours(t time.Time) bool {
+	if !p.QuietHoursEnabled || p.QuietStartMinute == p.QuietEndMinute {
+		return false
+	}
+	local := t.In(p.Location())
+	minute := local.Hour()*60 + local.Minute()
+	if p.QuietStartMinute < p.QuietEndMinute {
+		return minute >= p.QuietStartMinute && minute < p.QuietEndMinute
+	}
+	return minute >= p.QuietStartMinute || minute < p.QuietEndMinute
+}
+
+// QuietHoursEnd returns the first moment after t at which quiet hours end.
+func (p *NotificationPreference) QuietHoursEnd(t time.Time) time.Time {
+	local := t.In(p.Location())
+	end := time.Date(local.Year(), local.Month(), local.Day(), p.QuietEndMinute/60, p.QuietEndMinute%60, 0, 0, local.Location())
+	if !end.After(local) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end.UTC()
+}
+
+// NextDigestAt returns the next digest boundary after t, aligned to local
+// midnight so a 60 minute digest goes out on the hour.
+func (p *NotificationPreference) NextDigestAt(t time.Time) time.Time {
+	interval := time.Duration(p.DigestIntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	local := t.In(p.Location())
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	elapsed := local.Sub(midnight)
+	next := midnight.Add((elapsed/interval + 1) * interval)
+	return next.UTC()
+}
+
 type TelegramVerifyCode struct {
 	ID        uuid.UUID `json:"id"`
 	UserID    uuid.UUID `json:"user_id"`
@@ -36,3 +172,14 @@ type TelegramVerifyCode struct {
 	Used      bool      `json:"used"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// TelegramReplyPrompt links a bot message to the alert decision whose memo
+// and confidence the user is asked to reply with.
+type TelegramReplyPrompt struct {
+	ChatID    int64     `json:"chat_id"`
+	MessageID int64     `json:"message_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	AlertID   uuid.UUID `json:"alert_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
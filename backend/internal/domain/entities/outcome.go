@@ -7,11 +7,13 @@ import (
 )
 
 type Outcome struct {
-	ID             uuid.UUID `json:"id"`
-	BubbleID       uuid.UUID `json:"bubble_id"`
-	Period         string    `json:"period"`
-	ReferencePrice string    `json:"reference_price"`
-	OutcomePrice   string    `json:"outcome_price"`
-	PnLPercent     string    `json:"pnl_percent"`
-	CalculatedAt   time.Time `json:"calculated_at"`
+	ID             uuid.UUID  `json:"id"`
+	BubbleID       uuid.UUID  `json:"bubble_id"`
+	Period         string     `json:"period"`
+	ReferencePrice string     `json:"reference_price"`
+	OutcomePrice   string     `json:"outcome_price"`
+	PnLPercent     string     `json:"pnl_percent"`
+	PriceSource    *string    `json:"price_source,omitempty"`
+	TargetTime     *time.Time `json:"target_time,omitempty"`
+	CalculatedAt   time.Time  `json:"calculated_at"`
 }
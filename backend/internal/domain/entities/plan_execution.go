@@ -0,0 +1,56 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PlanExecutionStatus string
+
+const (
+	// PlanExecutionPending means no fill has matched the plan yet.
+	PlanExecutionPending PlanExecutionStatus = "pending"
+	PlanExecutionOpen    PlanExecutionStatus = "open"
+	PlanExecutionClosed  PlanExecutionStatus = "closed"
+)
+
+type PlanStopStatus string
+
+const (
+	PlanStopNone PlanStopStatus = "no_stop"
+	// PlanStopHonored means no exit filled beyond the stop the plan had at entry.
+	PlanStopHonored PlanStopStatus = "honored"
+	// PlanStopTrailed means the stop was tightened after entry.
+	PlanStopTrailed PlanStopStatus = "trailed"
+	// PlanStopMoved means the stop was widened after entry.
+	PlanStopMoved PlanStopStatus = "moved"
+	// PlanStopViolated means an exit filled beyond the stop at entry.
+	PlanStopViolated PlanStopStatus = "violated"
+)
+
+// PlanExecution compares the fills matched to a manual position with its
+// plan. Percentages are signed so positive is worse than planned for
+// slippage, and larger than planned for size.
+type PlanExecution struct {
+	PositionID           uuid.UUID           `json:"position_id"`
+	UserID               uuid.UUID           `json:"user_id"`
+	SymbolKey            string              `json:"symbol_key"`
+	Status               PlanExecutionStatus `json:"status"`
+	FillCount            int                 `json:"fill_count"`
+	EntryQty             *string             `json:"entry_qty,omitempty"`
+	EntryPrice           *string             `json:"entry_price,omitempty"`
+	ExitQty              *string             `json:"exit_qty,omitempty"`
+	ExitPrice            *string             `json:"exit_price,omitempty"`
+	FirstFillAt          *time.Time          `json:"first_fill_at,omitempty"`
+	LastFillAt           *time.Time          `json:"last_fill_at,omitempty"`
+	StopAtEntry          *string             `json:"stop_at_entry,omitempty"`
+	EntrySlippagePercent *string             `json:"entry_slippage_percent,omitempty"`
+	SizeDeviationPercent *string             `json:"size_deviation_percent,omitempty"`
+	StopStatus           PlanStopStatus      `json:"stop_status"`
+	EarlyExit            *bool               `json:"early_exit,omitempty"`
+	Score                *int                `json:"score,omitempty"`
+	EvaluatedAt          time.Time           `json:"evaluated_at"`
+	// FillIDs are the fills this plan claimed; no other plan counts them.
+	FillIDs []uuid.UUID `json:"-"`
+}
@@ -0,0 +1,42 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SetupDirection string
+
+const (
+	SetupDirectionLong  SetupDirection = "long"
+	SetupDirectionShort SetupDirection = "short"
+)
+
+// PlaybookSubject is the kind of record that can be linked to a setup.
+type PlaybookSubject string
+
+const (
+	PlaybookSubjectBubble         PlaybookSubject = "bubble"
+	PlaybookSubjectTrade          PlaybookSubject = "trade"
+	PlaybookSubjectManualPosition PlaybookSubject = "manual_position"
+)
+
+// PlaybookSetup is a named trade setup. Direction signs bubble outcomes so a
+// short setup wins when price falls; RiskPercent is the stop distance the
+// setup risks, which R multiples are measured against.
+type PlaybookSetup struct {
+	ID            uuid.UUID      `json:"id"`
+	UserID        uuid.UUID      `json:"user_id"`
+	Name          string         `json:"name"`
+	Description   *string        `json:"description,omitempty"`
+	Direction     SetupDirection `json:"direction"`
+	EntryCriteria *string        `json:"entry_criteria,omitempty"`
+	Invalidation  *string        `json:"invalidation,omitempty"`
+	TargetRR      *string        `json:"target_rr,omitempty"`
+	RiskPercent   *string        `json:"risk_percent,omitempty"`
+	Checklist     []string       `json:"checklist"`
+	ArchivedAt    *time.Time     `json:"archived_at,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
@@ -0,0 +1,67 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ExcursionStatus string
+
+const (
+	ExcursionReady ExcursionStatus = "ready"
+	// ExcursionUnavailable marks positions we couldn't price, e.g. no entry
+	// price or no candle history for the market, so they aren't retried until
+	// the position or its fills change.
+	ExcursionUnavailable ExcursionStatus = "unavailable"
+	// ExcursionRetry marks positions whose candles failed to load for a
+	// reason that may pass. They are picked up again after RetryAfter.
+	ExcursionRetry ExcursionStatus = "retry"
+	// ExcursionPending marks round trips that haven't been measured yet.
+	ExcursionPending ExcursionStatus = "pending"
+)
+
+// PositionExcursion is how far a closed position went against and in favor
+// of its entry while it was held. Percentages are signed from the position's
+// side; the R fields are multiples of the initial risk between entry and stop.
+type PositionExcursion struct {
+	PositionID      uuid.UUID       `json:"position_id"`
+	UserID          uuid.UUID       `json:"user_id"`
+	Status          ExcursionStatus `json:"status"`
+	CandleInterval  *string         `json:"candle_interval,omitempty"`
+	EntryPrice      *string         `json:"entry_price,omitempty"`
+	ExitPrice       *string         `json:"exit_price,omitempty"`
+	StopPrice       *string         `json:"stop_price,omitempty"`
+	PnLPercent      *string         `json:"pnl_percent,omitempty"`
+	MAEPercent      *string         `json:"mae_percent,omitempty"`
+	MFEPercent      *string         `json:"mfe_percent,omitempty"`
+	MAEPrice        *string         `json:"mae_price,omitempty"`
+	MFEPrice        *string         `json:"mfe_price,omitempty"`
+	RMultiple       *string         `json:"r_multiple,omitempty"`
+	MAER            *string         `json:"mae_r,omitempty"`
+	MFER            *string         `json:"mfe_r,omitempty"`
+	Attempts        int             `json:"-"`
+	RetryAfter      *time.Time      `json:"-"`
+	FillSignature   *string         `json:"-"`
+	SourceUpdatedAt time.Time       `json:"-"`
+	ComputedAt      time.Time       `json:"computed_at"`
+}
+
+// TradeRoundTrip is a synced position on one exchange and symbol from flat to
+// flat, rebuilt from the trades table. Its excursion is measured like a
+// manual position's, without the R fields since there is no stop.
+type TradeRoundTrip struct {
+	ID            uuid.UUID          `json:"id"`
+	UserID        uuid.UUID          `json:"user_id"`
+	Exchange      string             `json:"exchange"`
+	Symbol        string             `json:"symbol"`
+	PositionSide  string             `json:"position_side"`
+	OpenedAt      time.Time          `json:"opened_at"`
+	ClosedAt      time.Time          `json:"closed_at"`
+	Qty           string             `json:"qty"`
+	EntryPrice    string             `json:"entry_price"`
+	ExitPrice     string             `json:"exit_price"`
+	TradeIDs      []uuid.UUID        `json:"-"`
+	FillSignature string             `json:"-"`
+	Excursion     *PositionExcursion `json:"excursion,omitempty"`
+}
@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReviewQuestionType string
+
+const (
+	ReviewQuestionSingleChoice ReviewQuestionType = "single_choice"
+	ReviewQuestionMultiChoice  ReviewQuestionType = "multi_choice"
+	ReviewQuestionText         ReviewQuestionType = "text"
+)
+
+type ReviewQuestionOption struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+// ReviewQuestion is a user-defined guided review question. Answers are
+// stored by Key, so a key keeps its meaning across template versions.
+type ReviewQuestion struct {
+	Key       string                 `json:"key"`
+	Label     string                 `json:"label"`
+	Type      ReviewQuestionType     `json:"type"`
+	Options   []ReviewQuestionOption `json:"options,omitempty"`
+	Required  bool                   `json:"required"`
+	MaxLength int                    `json:"max_length,omitempty"`
+}
+
+// ReviewQuestionTemplate is one version of a user's questions for a review
+// cadence. Saving creates a new version; old versions are kept.
+type ReviewQuestionTemplate struct {
+	ID        uuid.UUID        `json:"id"`
+	UserID    uuid.UUID        `json:"user_id"`
+	Cadence   string           `json:"cadence"`
+	Version   int              `json:"version"`
+	Name      string           `json:"name"`
+	Questions []ReviewQuestion `json:"questions"`
+	CreatedAt time.Time        `json:"created_at"`
+}
@@ -0,0 +1,45 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RiskLimitType string
+
+const (
+	RiskLimitDailyLoss        RiskLimitType = "max_daily_loss"
+	RiskLimitTradesPerDay     RiskLimitType = "max_trades_per_day"
+	RiskLimitPositionNotional RiskLimitType = "max_position_notional"
+	RiskLimitLeverage         RiskLimitType = "max_leverage"
+	RiskLimitLossCooldown     RiskLimitType = "loss_cooldown"
+)
+
+// RiskLimitBreach is the first fill of a trading day that broke a limit.
+// Scope is the symbol key for per-symbol limits and empty otherwise.
+type RiskLimitBreach struct {
+	ID          uuid.UUID     `json:"id"`
+	UserID      uuid.UUID     `json:"user_id"`
+	LimitType   RiskLimitType `json:"limit_type"`
+	TradeDate   string        `json:"trade_date"`
+	Scope       string        `json:"scope"`
+	Symbol      string        `json:"symbol"`
+	Observed    string        `json:"observed"`
+	Limit       string        `json:"limit"`
+	Price       string        `json:"price"`
+	TriggeredAt time.Time     `json:"triggered_at"`
+	AlertID     *uuid.UUID    `json:"alert_id,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// DisciplineScore summarizes how well a user kept to their limits on one
+// trading day.
+type DisciplineScore struct {
+	UserID         uuid.UUID       `json:"-"`
+	TradeDate      string          `json:"trade_date"`
+	Score          int             `json:"score"`
+	TradeCount     int             `json:"trade_count"`
+	BreachedLimits []RiskLimitType `json:"breached_limits"`
+	ComputedAt     time.Time       `json:"computed_at"`
+}
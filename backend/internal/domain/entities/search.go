@@ -0,0 +1,38 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SearchSource string
+
+const (
+	SearchSourceBubble       SearchSource = "bubble"
+	SearchSourceNote         SearchSource = "note"
+	SearchSourceGuidedReview SearchSource = "guided_review"
+	SearchSourceAIOpinion    SearchSource = "ai_opinion"
+)
+
+var SearchSources = []SearchSource{
+	SearchSourceBubble,
+	SearchSourceNote,
+	SearchSourceGuidedReview,
+	SearchSourceAIOpinion,
+}
+
+// SearchHit is one matching document from any searchable source. BubbleID and
+// ReviewID point at the record the hit belongs to, for linking.
+type SearchHit struct {
+	Source    SearchSource `json:"source"`
+	ID        uuid.UUID    `json:"id"`
+	Title     *string      `json:"title,omitempty"`
+	Snippet   string       `json:"snippet"`
+	Rank      float64      `json:"rank"`
+	Symbol    *string      `json:"symbol,omitempty"`
+	BubbleID  *uuid.UUID   `json:"bubble_id,omitempty"`
+	ReviewID  *uuid.UUID   `json:"review_id,omitempty"`
+	Tags      []string     `json:"tags,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+}
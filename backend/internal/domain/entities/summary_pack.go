@@ -20,7 +20,24 @@ type SummaryPack struct {
 	DuplicateSuspectsCount int             `json:"duplicate_suspects_count"`
 	NormalizationWarnings  []string        `json:"normalization_warnings"`
 	Payload                json.RawMessage `json:"payload"`
-	CreatedAt              time.Time       `json:"created_at"`
+	EvidenceTradeIDs       []string        `json:"-"`
+	// EvidenceBundle is set on freshly generated packs and stored with them;
+	// read it back with SummaryPackRepository.GetEvidenceBundle. Reports read
+	// their evidence from it.
+	EvidenceBundle *SummaryPackEvidenceBundle `json:"-"`
+	CreatedAt      time.Time                  `json:"created_at"`
+}
+
+// SummaryPackEvidenceBundle is the zip archive a pack's evidence_pack_ref
+// points at. SHA256 is the hash of Archive.
+type SummaryPackEvidenceBundle struct {
+	PackID    uuid.UUID       `json:"pack_id"`
+	UserID    uuid.UUID       `json:"user_id"`
+	Archive   []byte          `json:"-"`
+	SHA256    string          `json:"sha256"`
+	SizeBytes int             `json:"size_bytes"`
+	Manifest  json.RawMessage `json:"manifest"`
+	CreatedAt time.Time       `json:"created_at"`
 }
 
 type SummaryPackPayload struct {
@@ -0,0 +1,82 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PackScheduleFrequency string
+
+const (
+	PackScheduleDaily   PackScheduleFrequency = "daily"
+	PackScheduleWeekly  PackScheduleFrequency = "weekly"
+	PackScheduleMonthly PackScheduleFrequency = "monthly"
+)
+
+// Outcomes of a scheduled run, kept in LastStatus.
+const (
+	PackScheduleStatusSent      = "sent"
+	PackScheduleStatusNoRun     = "no_run"
+	PackScheduleStatusNoNewRun  = "no_new_run"
+	PackScheduleStatusNoChannel = "no_channel"
+	PackScheduleStatusFailed    = "failed"
+)
+
+// SummaryPackSchedule generates a pack from the user's latest completed run
+// and delivers a digest. SendHour, Weekday (0 = Sunday, weekly only) and
+// DayOfMonth (monthly only) are local to Timezone, the user's profile
+// timezone.
+type SummaryPackSchedule struct {
+	ID         uuid.UUID             `json:"id"`
+	UserID     uuid.UUID             `json:"user_id"`
+	Frequency  PackScheduleFrequency `json:"frequency"`
+	Range      string                `json:"range"`
+	Timezone   string                `json:"timezone"`
+	SendHour   int                   `json:"send_hour"`
+	Weekday    int                   `json:"weekday"`
+	DayOfMonth int                   `json:"day_of_month"`
+	Channels   []ChannelType         `json:"channels"`
+	Enabled    bool                  `json:"enabled"`
+	NextRunAt  time.Time             `json:"next_run_at"`
+	LastRunAt  *time.Time            `json:"last_run_at,omitempty"`
+	LastStatus *string               `json:"last_status,omitempty"`
+	LastError  *string               `json:"last_error,omitempty"`
+	LastPackID *uuid.UUID            `json:"last_pack_id,omitempty"`
+	CreatedAt  time.Time             `json:"created_at"`
+	UpdatedAt  time.Time             `json:"updated_at"`
+}
+
+// NextRunAfter returns the first send time strictly after t. An unknown
+// timezone falls back to UTC.
+func (s *SummaryPackSchedule) NextRunAfter(t time.Time) time.Time {
+	loc, err := time.LoadLocation(s.Timezone)
+	if s.Timezone == "" || err != nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	// The longest gap is a month, so two months of days always finds one.
+	for i := 0; i < 62; i++ {
+		candidate := day.AddDate(0, 0, i)
+		if !s.runsOn(candidate) {
+			continue
+		}
+		at := time.Date(candidate.Year(), candidate.Month(), candidate.Day(), s.SendHour, 0, 0, 0, loc)
+		if at.After(t) {
+			return at.UTC()
+		}
+	}
+	return t.Add(24 * time.Hour).UTC()
+}
+
+func (s *SummaryPackSchedule) runsOn(day time.Time) bool {
+	switch s.Frequency {
+	case PackScheduleWeekly:
+		return int(day.Weekday()) == s.Weekday
+	case PackScheduleMonthly:
+		return day.Day() == s.DayOfMonth
+	default:
+		return true
+	}
+}
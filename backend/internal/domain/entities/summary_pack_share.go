@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Parts of a pack a share can hide.
+const (
+	// PackShareRedactAmounts hides absolute PnL, fees, flows and volume;
+	// the share shows them as percentages of notional volume instead.
+	PackShareRedactAmounts = "amounts"
+	// PackShareRedactEvidence hides the trade IDs behind the pack.
+	PackShareRedactEvidence = "evidence"
+)
+
+// SummaryPackShare backs a signed, read-only link to one of a user's packs.
+type SummaryPackShare struct {
+	ID           uuid.UUID  `json:"id"`
+	UserID       uuid.UUID  `json:"user_id"`
+	PackID       uuid.UUID  `json:"pack_id"`
+	Redactions   []string   `json:"redactions"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	ViewCount    int        `json:"view_count"`
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// Active reports whether the link still opens at now.
+func (s *SummaryPackShare) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+func (s *SummaryPackShare) Redacts(field string) bool {
+	for _, redaction := range s.Redactions {
+		if redaction == field {
+			return true
+		}
+	}
+	return false
+}
@@ -17,6 +17,7 @@ type Trade struct {
 	PositionSide   *string    `json:"position_side,omitempty"`
 	OpenClose      *string    `json:"open_close,omitempty"`
 	ReduceOnly     *bool      `json:"reduce_only,omitempty"`
+	OrderID        *string    `json:"order_id,omitempty"`
 	Quantity       string     `json:"quantity"`
 	Price          string     `json:"price"`
 	RealizedPnL    *string    `json:"realized_pnl,omitempty"`
@@ -0,0 +1,53 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TradeSafetyFlagCode string
+
+const (
+	TradeSafetyFlagOversized      TradeSafetyFlagCode = "oversized"
+	TradeSafetyFlagPriceOffMarket TradeSafetyFlagCode = "price_off_market"
+	TradeSafetyFlagQuickReversal  TradeSafetyFlagCode = "quick_reversal"
+	TradeSafetyFlagNewInstrument  TradeSafetyFlagCode = "new_instrument"
+	TradeSafetyFlagLeverageJump   TradeSafetyFlagCode = "leverage_jump"
+)
+
+// TradeSafetyFeatures are the measurements the safety checks run on. A nil
+// value means the trade couldn't be measured, e.g. without enough history.
+type TradeSafetyFeatures struct {
+	// SizeRatio is the notional over the median notional of earlier orders
+	// on the instrument.
+	SizeRatio *float64 `json:"size_ratio,omitempty"`
+	// PriceDeviation is |price - market close| / market close at execution.
+	PriceDeviation *float64 `json:"price_deviation,omitempty"`
+	// ReversalSeconds is the delay until an opposite fill of similar size
+	// closed the trade out.
+	ReversalSeconds *float64 `json:"reversal_seconds,omitempty"`
+	NewInstrument   bool     `json:"new_instrument"`
+	// LeverageRatio is the leverage over the median of earlier leverages.
+	LeverageRatio *float64 `json:"leverage_ratio,omitempty"`
+}
+
+// TradeSafetyFlag is one failed check. Weight is the estimated probability
+// that a trade with this flag is a mistake, learned from past verdicts.
+type TradeSafetyFlag struct {
+	Code   TradeSafetyFlagCode `json:"code"`
+	Reason string              `json:"reason"`
+	Weight float64             `json:"weight"`
+}
+
+// TradeSafetySignal is the scoring result for one trade or trade event.
+// Verdict is only set when read back together with its review.
+type TradeSafetySignal struct {
+	TargetType string              `json:"target_type"`
+	TargetID   uuid.UUID           `json:"target_id"`
+	Features   TradeSafetyFeatures `json:"features"`
+	Flags      []TradeSafetyFlag   `json:"flags"`
+	Score      float64             `json:"score"`
+	ComputedAt time.Time           `json:"computed_at"`
+	Verdict    *TradeSafetyVerdict `json:"verdict,omitempty"`
+}
@@ -0,0 +1,99 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+)
+
+const tradingDayLayout = "2006-01-02"
+
+// TradingDay describes how a user's trades are bucketed into days: a day
+// starts at CutoffHour local time in Location, so with Asia/Seoul and 9 the
+// day "2026-03-05" runs from 09:00 KST on the 5th to 09:00 KST on the 6th.
+type TradingDay struct {
+	Location   *time.Location
+	CutoffHour int
+}
+
+// NewTradingDay builds a TradingDay, falling back to UTC for an unknown
+// timezone and to midnight for an out-of-range cutoff.
+func NewTradingDay(timezone string, cutoffHour int) TradingDay {
+	loc, err := time.LoadLocation(timezone)
+	if timezone == "" || err != nil {
+		loc = time.UTC
+	}
+	if cutoffHour < 0 || cutoffHour > 23 {
+		cutoffHour = 0
+	}
+	return TradingDay{Location: loc, CutoffHour: cutoffHour}
+}
+
+// ValidTradingDay reports whether timezone and cutoffHour can be stored on a
+// profile.
+func ValidTradingDay(timezone string, cutoffHour int) error {
+	if timezone == "" {
+		return fmt.Errorf("timezone is required")
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf("unknown timezone %q", timezone)
+	}
+	if cutoffHour < 0 || cutoffHour > 23 {
+		return fmt.Errorf("day_cutoff_hour must be between 0 and 23")
+	}
+	return nil
+}
+
+func (d TradingDay) location() *time.Location {
+	if d.Location == nil {
+		return time.UTC
+	}
+	return d.Location
+}
+
+// Timezone is the IANA name of the location.
+func (d TradingDay) Timezone() string {
+	return d.location().String()
+}
+
+// Start returns the trading day containing t as local midnight of its date,
+// suitable for date arithmetic and ReviewPeriod.
+func (d TradingDay) Start(t time.Time) time.Time {
+	local := t.In(d.location())
+	date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, d.location())
+	if local.Hour() < d.CutoffHour {
+		date = date.AddDate(0, 0, -1)
+	}
+	return date
+}
+
+// Date returns the trading day containing t as YYYY-MM-DD.
+func (d TradingDay) Date(t time.Time) string {
+	return d.Start(t).Format(tradingDayLayout)
+}
+
+// Bounds returns the instants the trading day named by date (YYYY-MM-DD)
+// starts and ends; end is exclusive.
+func (d TradingDay) Bounds(date string) (time.Time, time.Time, error) {
+	day, err := time.ParseInLocation(tradingDayLayout, date, d.location())
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	loc := d.location()
+	start := time.Date(day.Year(), day.Month(), day.Day(), d.CutoffHour, 0, 0, 0, loc)
+	end := time.Date(day.Year(), day.Month(), day.Day()+1, d.CutoffHour, 0, 0, 0, loc)
+	return start.UTC(), end.UTC(), nil
+}
+
+// RangeBounds returns the instants spanning the trading days from fromDate
+// through toDate inclusive.
+func (d TradingDay) RangeBounds(fromDate, toDate string) (time.Time, time.Time, error) {
+	start, _, err := d.Bounds(fromDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	_, end, err := d.Bounds(toDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
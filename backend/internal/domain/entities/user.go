@@ -6,6 +6,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// DefaultUserTimezone is the timezone of users who have not picked one.
+const DefaultUserTimezone = "Asia/Seoul"
+
 type User struct {
 	ID            uuid.UUID `json:"id"`
 	Email         string    `json:"email"`
@@ -13,6 +16,16 @@ type User struct {
 	Name          string    `json:"name"`
 	AIAllowlisted bool      `json:"ai_allowlisted"`
 	IsAdmin       bool      `json:"is_admin"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	Timezone      string    `json:"timezone"`
+	DayCutoffHour int       `json:"day_cutoff_hour"`
+	// TimezoneSetAt is when the user last saved a timezone; nil while
+	// Timezone is still the default or a backfilled value.
+	TimezoneSetAt *time.Time `json:"-"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TradingDay returns the user's trading-day settings.
+func (u *User) TradingDay() TradingDay {
+	return NewTradingDay(u.Timezone, u.DayCutoffHour)
 }
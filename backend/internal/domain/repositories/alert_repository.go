@@ -12,9 +12,28 @@ type AlertRepository interface {
 	Create(ctx context.Context, alert *entities.Alert) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entities.Alert, error)
 	ListByUser(ctx context.Context, userID uuid.UUID, status *entities.AlertStatus, limit, offset int) ([]*entities.Alert, int, error)
+	// UpdateStatus and the lifecycle methods below record every change in
+	// the alert's event history in the same transaction. UpdateStatus only
+	// follows AlertStatus.CanTransitionTo and ignores any other update.
 	UpdateStatus(ctx context.Context, id uuid.UUID, status entities.AlertStatus) error
 	SetNotified(ctx context.Context, id uuid.UUID) error
-	ExpireOlderThan(ctx context.Context, before time.Time) (int, error)
+	// Snooze, Acknowledge and Dismiss only apply to open alerts and report
+	// whether the alert was changed.
+	Snooze(ctx context.Context, id uuid.UUID, until, expiresAt time.Time) (bool, error)
+	Acknowledge(ctx context.Context, id uuid.UUID, at time.Time) (bool, error)
+	Dismiss(ctx context.Context, id uuid.UUID) (bool, error)
+	// ClaimDueSnoozed moves snoozed alerts whose snooze has ended back to
+	// briefed and returns them for re-notification.
+	ClaimDueSnoozed(ctx context.Context, now time.Time, limit int) ([]*entities.Alert, error)
+	// ClaimEscalations marks unacknowledged urgent alerts whose rule
+	// escalation delay has passed as escalated and returns them.
+	ClaimEscalations(ctx context.Context, now time.Time, limit int) ([]*entities.Alert, error)
+	ExpireDue(ctx context.Context, now time.Time) (int, error)
+}
+
+type AlertEventRepository interface {
+	Create(ctx context.Context, event *entities.AlertEvent) error
+	ListByAlert(ctx context.Context, alertID uuid.UUID) ([]*entities.AlertEvent, error)
 }
 
 type AlertBriefingRepository interface {
@@ -25,18 +44,33 @@ type AlertBriefingRepository interface {
 type AlertDecisionRepository interface {
 	Create(ctx context.Context, decision *entities.AlertDecision) error
 	GetByAlert(ctx context.Context, alertID uuid.UUID) (*entities.AlertDecision, error)
+	UpdateNote(ctx context.Context, id uuid.UUID, memo *string, confidence *entities.Confidence) error
 }
 
 type AlertOutcomeRepository interface {
 	CreateIfNotExists(ctx context.Context, outcome *entities.AlertOutcome) (bool, error)
 	ListByAlert(ctx context.Context, alertID uuid.UUID) ([]*entities.AlertOutcome, error)
 	ListPendingDecisions(ctx context.Context, period string, cutoff time.Time, limit int) ([]*PendingAlertDecision, error)
+	ListDecisionOutcomes(ctx context.Context, userID uuid.UUID, period string, from, to *time.Time) ([]*AlertDecisionOutcome, error)
 }
 
 type PendingAlertDecision struct {
-	AlertID        uuid.UUID
-	DecisionID     uuid.UUID
-	Symbol         string
-	TriggerPrice   string
-	DecisionTime   time.Time
+	AlertID      uuid.UUID
+	DecisionID   uuid.UUID
+	Symbol       string
+	TriggerPrice string
+	DecisionTime time.Time
+}
+
+// AlertDecisionOutcome joins a decision with its outcome for one period and
+// the context needed to slice decision quality.
+type AlertDecisionOutcome struct {
+	AlertID      uuid.UUID
+	Action       entities.DecisionAction
+	Confidence   *entities.Confidence
+	RuleType     entities.RuleType
+	Severity     entities.AlertSeverity
+	PnLPercent   string
+	Briefing     *string
+	DecisionTime time.Time
 }
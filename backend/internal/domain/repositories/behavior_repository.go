@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+// BehaviorFill is one execution from trades or trade_events; Source is
+// "trade" or "trade_event". RealizedPnL is only known for synced trades.
+type BehaviorFill struct {
+	Source      string
+	ID          uuid.UUID
+	SymbolKey   string
+	Side        string
+	Qty         string
+	Price       string
+	RealizedPnL *string
+	ExecutedAt  time.Time
+}
+
+// BehaviorReviewSignal is a guided review item the user answered, with the
+// patterns detected on its symbol (or for the whole day) on the review date.
+type BehaviorReviewSignal struct {
+	ItemID     uuid.UUID
+	ReviewDate string
+	Symbol     string
+	Emotions   json.RawMessage
+	Patterns   []string
+}
+
+type BehaviorRepository interface {
+	// ListActiveUsers returns users who traded since the given time, least
+	// recently scanned first.
+	ListActiveUsers(ctx context.Context, since time.Time, limit int) ([]uuid.UUID, error)
+	ListFills(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*BehaviorFill, error)
+	// ReplaceEpisodes swaps the user's episodes started at or after from for
+	// episodes in one transaction and records the scan time.
+	ReplaceEpisodes(ctx context.Context, userID uuid.UUID, from time.Time, episodes []*entities.BehaviorEpisode, scannedAt time.Time) error
+	ListEpisodes(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*entities.BehaviorEpisode, error)
+	ListReviewSignals(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*BehaviorReviewSignal, error)
+}
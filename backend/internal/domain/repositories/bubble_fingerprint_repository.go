@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+type BubbleFingerprintRepository interface {
+	Upsert(ctx context.Context, fingerprint *entities.BubbleFingerprint) error
+	GetByBubble(ctx context.Context, bubbleID uuid.UUID) (*entities.BubbleFingerprint, error)
+	// ListMissing returns bubbles with no fingerprint for version yet, and
+	// bubbles whose fingerprint is waiting for a retry that is now due.
+	ListMissing(ctx context.Context, version string, now time.Time, limit int) ([]*entities.Bubble, error)
+	// ListCandidates returns the user's ready fingerprints for version whose
+	// bubble candle is before `before`, with the bubble and its outcome for
+	// period when one exists.
+	ListCandidates(ctx context.Context, userID uuid.UUID, version string, before time.Time, period string) ([]*FingerprintCandidate, error)
+}
+
+type FingerprintCandidate struct {
+	Bubble  *entities.Bubble
+	Vector  []float64
+	Outcome *entities.Outcome
+}
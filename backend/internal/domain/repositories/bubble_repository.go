@@ -17,8 +17,10 @@ type BubbleRepository interface {
 	SummarySimilar(ctx context.Context, userID uuid.UUID, symbol string, tags []string, excludeID *uuid.UUID, period string) (*SimilarSummary, error)
 	Update(ctx context.Context, bubble *entities.Bubble) error
 	DeleteByIDAndUser(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error)
-	GetReviewStats(ctx context.Context, userID uuid.UUID, period string, symbol string, tag string, assetClass string, venueName string) (*ReviewStats, error)
-	GetCalendarData(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time, assetClass string, venueName string) (map[string]CalendarDay, error)
+	GetReviewStats(ctx context.Context, userID uuid.UUID, period string, symbol string, tag string, assetClass string, venueName string, setupID string) (*ReviewStats, error)
+	// GetCalendarData buckets bubbles into the trading days from through to
+	// (dates, inclusive) as defined by day.
+	GetCalendarData(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time, day entities.TradingDay, assetClass string, venueName string) (map[string]CalendarDay, error)
 }
 
 type BubbleFilter struct {
@@ -43,13 +45,15 @@ type SimilarSummary struct {
 }
 
 type ReviewStats struct {
-	Period             string                 `json:"period"`
-	TotalBubbles       int                    `json:"total_bubbles"`
-	BubblesWithOutcome int                    `json:"bubbles_with_outcome"`
-	Overall            OverallReviewStats     `json:"overall"`
-	ByPeriod           map[string]PeriodStats `json:"by_period"`
-	ByTag              map[string]TagStats    `json:"by_tag"`
-	BySymbol           map[string]SymbolStats `json:"by_symbol"`
+	Period             string                      `json:"period"`
+	TotalBubbles       int                         `json:"total_bubbles"`
+	BubblesWithOutcome int                         `json:"bubbles_with_outcome"`
+	Overall            OverallReviewStats          `json:"overall"`
+	ByPeriod           map[string]PeriodStats      `json:"by_period"`
+	ByTag              map[string]TagStats         `json:"by_tag"`
+	BySymbol           map[string]SymbolStats      `json:"by_symbol"`
+	BySetup            map[string]SetupReviewStats `json:"by_setup"`
+	Excursions         ExcursionStats              `json:"excursions"`
 }
 
 type OverallReviewStats struct {
@@ -78,6 +82,31 @@ type SymbolStats struct {
 	AvgPnL  string  `json:"avg_pnl"`
 }
 
+// SetupReviewStats is keyed by setup name; bubbles without a setup are left
+// out. Outcomes of short setups are sign-flipped like in PlaybookService.
+type SetupReviewStats struct {
+	SetupID string  `json:"setup_id"`
+	Count   int     `json:"count"`
+	WinRate float64 `json:"win_rate"`
+	AvgPnL  string  `json:"avg_pnl"`
+}
+
+// ExcursionStats summarizes MAE/MFE over closed manual positions and synced
+// round trips in the same window. The R averages only cover manual positions
+// that had a stop. A winner MAE
+// near -1R suggests stops are tight; a low capture ratio (realized PnL over
+// MFE for winners) suggests winners are cut early.
+type ExcursionStats struct {
+	Count            int    `json:"count"`
+	WithStop         int    `json:"with_stop"`
+	AvgMAE           string `json:"avg_mae"`
+	AvgMFE           string `json:"avg_mfe"`
+	AvgRMultiple     string `json:"avg_r_multiple"`
+	AvgWinnerMAER    string `json:"avg_winner_mae_r"`
+	AvgLoserMFER     string `json:"avg_loser_mfe_r"`
+	AvgWinnerCapture string `json:"avg_winner_capture"`
+}
+
 type CalendarDay struct {
 	BubbleCount int    `json:"bubble_count"`
 	WinCount    int    `json:"win_count"`
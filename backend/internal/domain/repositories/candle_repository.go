@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+type CandleRepository interface {
+	UpsertMany(ctx context.Context, candles []*entities.Candle) error
+	ListRange(ctx context.Context, venue, symbol, interval string, start, end time.Time) ([]*entities.Candle, error)
+	MarkEmpty(ctx context.Context, ranges []*entities.CandleEmptyRange) error
+	ListEmpty(ctx context.Context, venue, symbol, interval string, start, end time.Time) ([]*entities.CandleEmptyRange, error)
+}
@@ -13,12 +13,28 @@ type SubmitItemInput struct {
 	Emotions     json.RawMessage `json:"emotions"`
 	PatternMatch string          `json:"pattern_match"`
 	Memo         string          `json:"memo"`
+	Answers      json.RawMessage `json:"answers"`
+}
+
+// ReviewInsightItem is an answered guided review item with the fields the
+// emotion and intent analytics group by. PnL is nil when the item had none.
+type ReviewInsightItem struct {
+	ReviewDate   string
+	Symbol       string
+	Intent       string
+	Emotions     json.RawMessage
+	PatternMatch *string
+	PnL          *string
 }
 
 type GuidedReviewRepository interface {
 	GetOrCreateToday(ctx context.Context, userID uuid.UUID, date string) (*entities.GuidedReview, []*entities.GuidedReviewItem, error)
+	// GetOrCreatePeriod returns the weekly or monthly review for the period,
+	// rolling up its trades per symbol and the daily answers given in it.
+	GetOrCreatePeriod(ctx context.Context, userID uuid.UUID, cadence, startDate, endDate string) (*entities.GuidedReview, []*entities.GuidedReviewItem, error)
 	SubmitItem(ctx context.Context, userID uuid.UUID, itemID uuid.UUID, input SubmitItemInput) error
 	CompleteReview(ctx context.Context, userID uuid.UUID, reviewID uuid.UUID) (*entities.UserStreak, error)
-	GetStreak(ctx context.Context, userID uuid.UUID) (*entities.UserStreak, error)
+	GetStreak(ctx context.Context, userID uuid.UUID, cadence string) (*entities.UserStreak, error)
 	ListReviews(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entities.GuidedReview, int, error)
+	ListAnsweredItems(ctx context.Context, userID uuid.UUID, fromDate, toDate string) ([]*ReviewInsightItem, error)
 }
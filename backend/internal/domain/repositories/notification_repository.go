@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/moneyvessel/kifu/internal/domain/entities"
@@ -13,6 +14,8 @@ type NotificationChannelRepository interface {
 	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.NotificationChannel, error)
 	DeleteByUserAndType(ctx context.Context, userID uuid.UUID, channelType entities.ChannelType) error
 	ListVerifiedByUser(ctx context.Context, userID uuid.UUID) ([]*entities.NotificationChannel, error)
+	UpdateRouting(ctx context.Context, userID uuid.UUID, channelType entities.ChannelType, enabled bool, severities []string) error
+	GetTelegramByChatID(ctx context.Context, chatID int64) (*entities.NotificationChannel, error)
 }
 
 type TelegramVerifyCodeRepository interface {
@@ -20,3 +23,34 @@ type TelegramVerifyCodeRepository interface {
 	FindValidCode(ctx context.Context, code string) (*entities.TelegramVerifyCode, error)
 	MarkUsed(ctx context.Context, id uuid.UUID) error
 }
+
+type NotificationVerifyCodeRepository interface {
+	Create(ctx context.Context, code *entities.NotificationVerifyCode) error
+	FindValidCode(ctx context.Context, userID uuid.UUID, channelType entities.ChannelType, code string) (*entities.NotificationVerifyCode, error)
+	MarkUsed(ctx context.Context, id uuid.UUID) error
+	// RecordFailedAttempt counts a wrong guess against the user's open codes
+	// for the channel and burns them once maxAttempts is reached. It reports
+	// whether the codes are now locked.
+	RecordFailedAttempt(ctx context.Context, userID uuid.UUID, channelType entities.ChannelType, maxAttempts int) (bool, error)
+}
+
+type NotificationDeliveryRepository interface {
+	Create(ctx context.Context, delivery *entities.NotificationDelivery) error
+	ListByUser(ctx context.Context, userID uuid.UUID, status string, limit int) ([]*entities.NotificationDelivery, error)
+	ListByAlert(ctx context.Context, alertID uuid.UUID) ([]*entities.NotificationDelivery, error)
+	// ClaimDue locks up to limit pending/failed rows whose next attempt is due
+	// and pushes their next_attempt_at out by lease so other workers skip them.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.NotificationDelivery, error)
+	UpdateAttempt(ctx context.Context, delivery *entities.NotificationDelivery) error
+	Requeue(ctx context.Context, userID uuid.UUID, id uuid.UUID, now time.Time) (bool, error)
+}
+
+type NotificationPreferenceRepository interface {
+	GetByUser(ctx context.Context, userID uuid.UUID) (*entities.NotificationPreference, error)
+	Upsert(ctx context.Context, pref *entities.NotificationPreference) error
+}
+
+type TelegramReplyPromptRepository interface {
+	Create(ctx context.Context, prompt *entities.TelegramReplyPrompt) error
+	FindValid(ctx context.Context, chatID, messageID int64, now time.Time) (*entities.TelegramReplyPrompt, error)
+}
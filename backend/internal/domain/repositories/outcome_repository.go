@@ -13,7 +13,18 @@ type OutcomeRepository interface {
 	ListByBubble(ctx context.Context, bubbleID uuid.UUID) ([]*entities.Outcome, error)
 	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.Outcome, error)
 	ListPending(ctx context.Context, period string, cutoff time.Time, limit int) ([]*PendingOutcomeBubble, error)
+	// ListPendingForHorizon is ListPending limited to users who added period
+	// to their own horizons.
+	ListPendingForHorizon(ctx context.Context, period string, cutoff time.Time, limit int) ([]*PendingOutcomeBubble, error)
+	// ListPendingUntilNext returns bubbles of users with the next_bubble
+	// horizon whose next bubble on the same symbol is at or before cutoff,
+	// with TargetTime set to that bubble's candle time.
+	ListPendingUntilNext(ctx context.Context, cutoff time.Time, limit int) ([]*PendingOutcomeBubble, error)
 	ListRecentWithoutAccuracy(ctx context.Context, since time.Time, limit int) ([]*entities.Outcome, error)
+	// ListCustomHorizons returns every period some user has configured.
+	ListCustomHorizons(ctx context.Context) ([]string, error)
+	ListUserHorizons(ctx context.Context, userID uuid.UUID) ([]string, error)
+	ReplaceUserHorizons(ctx context.Context, userID uuid.UUID, periods []string) error
 }
 
 type PendingOutcomeBubble struct {
@@ -21,4 +32,7 @@ type PendingOutcomeBubble struct {
 	Symbol     string
 	CandleTime time.Time
 	Price      string
+	AssetClass string
+	VenueName  string
+	TargetTime *time.Time
 }
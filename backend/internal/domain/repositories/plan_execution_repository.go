@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+// PlanFill is one synced execution that may belong to a plan. Side is
+// lowercase buy or sell.
+type PlanFill struct {
+	Source     string
+	ID         uuid.UUID
+	Side       string
+	Qty        string
+	Price      string
+	ExecutedAt time.Time
+}
+
+type PlanExecutionRepository interface {
+	// ListPlansToEvaluate returns open manual positions and those closed
+	// after closedSince, least recently evaluated first.
+	ListPlansToEvaluate(ctx context.Context, closedSince time.Time, limit int) ([]*entities.ManualPosition, error)
+	// ListFills returns the user's trades, and imported trade events that
+	// aren't mirrors of a trade, whose normalized symbol is symbolKey and
+	// that executed in [from, to], oldest first.
+	ListFills(ctx context.Context, userID uuid.UUID, symbolKey string, from, to time.Time) ([]*PlanFill, error)
+	// ListClaimedFillIDs returns the fills already claimed by the user's
+	// other plans on symbolKey.
+	ListClaimedFillIDs(ctx context.Context, userID uuid.UUID, symbolKey string, excludePositionID uuid.UUID) ([]uuid.UUID, error)
+	Upsert(ctx context.Context, execution *entities.PlanExecution) error
+	GetByPosition(ctx context.Context, positionID uuid.UUID) (*entities.PlanExecution, error)
+	// ListByUser returns executions whose first fill falls in [from, to],
+	// plus pending ones when includePending is set.
+	ListByUser(ctx context.Context, userID uuid.UUID, from, to time.Time, includePending bool) ([]*entities.PlanExecution, error)
+}
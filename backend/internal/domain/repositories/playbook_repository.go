@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+type PlaybookRepository interface {
+	Create(ctx context.Context, setup *entities.PlaybookSetup) error
+	GetByID(ctx context.Context, id, userID uuid.UUID) (*entities.PlaybookSetup, error)
+	List(ctx context.Context, userID uuid.UUID, includeArchived bool) ([]*entities.PlaybookSetup, error)
+	Update(ctx context.Context, setup *entities.PlaybookSetup) error
+	Delete(ctx context.Context, id, userID uuid.UUID) (bool, error)
+	// SetLink points one of the user's bubbles, trades or manual positions at
+	// a setup (nil unlinks). It reports false when the record doesn't exist.
+	SetLink(ctx context.Context, userID uuid.UUID, subject entities.PlaybookSubject, subjectID uuid.UUID, setupID *uuid.UUID, checked []string) (bool, error)
+	ListSamples(ctx context.Context, userID uuid.UUID, filter PlaybookSampleFilter) ([]*PlaybookSample, error)
+}
+
+// PlaybookSampleFilter mirrors the review stats filters. Tag only exists on
+// bubbles, so trades and manual positions drop out when it is set.
+type PlaybookSampleFilter struct {
+	SetupID       *uuid.UUID
+	OutcomePeriod string
+	Since         *time.Time
+	Symbol        string
+	Tag           string
+	AssetClass    string
+	Venue         string
+}
+
+// PlaybookSample is one record linked to a setup. Bubbles carry their outcome
+// move for the filter's period, trades their realized PnL.
+type PlaybookSample struct {
+	SetupID     uuid.UUID
+	Subject     entities.PlaybookSubject
+	SubjectID   uuid.UUID
+	Checked     []string
+	PnLPercent  *string
+	RealizedPnL *string
+}
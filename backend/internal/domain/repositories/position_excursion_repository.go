@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+// ExcursionCandidate is a closed manual position waiting for excursions, with
+// what plan adherence already learned from its fills. FillSignature
+// identifies those fills; Attempts counts earlier transient failures.
+type ExcursionCandidate struct {
+	Position      *entities.ManualPosition
+	FillEntry     *string
+	FillExit      *string
+	StopAtEntry   *string
+	FillSignature *string
+	Attempts      int
+}
+
+// RoundTripFill is one synced trade read when rebuilding round trips. Side is
+// lowercase buy or sell.
+type RoundTripFill struct {
+	ID         uuid.UUID
+	Exchange   string
+	Symbol     string
+	Side       string
+	Qty        string
+	Price      string
+	ExecutedAt time.Time
+}
+
+type PositionExcursionRepository interface {
+	// ListPending returns closed positions with no excursion row, one
+	// computed before the position was last edited or its fills changed, or
+	// one whose retry is due at now.
+	ListPending(ctx context.Context, now time.Time, limit int) ([]*ExcursionCandidate, error)
+	Upsert(ctx context.Context, excursion *entities.PositionExcursion) error
+	ListByPositions(ctx context.Context, userID uuid.UUID, positionIDs []uuid.UUID) (map[uuid.UUID]*entities.PositionExcursion, error)
+
+	// ListRoundTripUsers returns users with synced trades, least recently
+	// rebuilt first.
+	ListRoundTripUsers(ctx context.Context, limit int) ([]uuid.UUID, error)
+	// ListRoundTripFills returns the user's synced trades ordered by
+	// exchange, symbol and execution time.
+	ListRoundTripFills(ctx context.Context, userID uuid.UUID) ([]*RoundTripFill, error)
+	// ReplaceRoundTrips stores the user's rebuilt round trips. A trip whose
+	// fill signature changed goes back to pending, and trips that no longer
+	// exist are removed.
+	ReplaceRoundTrips(ctx context.Context, userID uuid.UUID, trips []*entities.TradeRoundTrip, scannedAt time.Time) error
+	// ListPendingRoundTrips returns round trips not measured yet, or whose
+	// retry is due at now, most recently closed first.
+	ListPendingRoundTrips(ctx context.Context, now time.Time, limit int) ([]*entities.TradeRoundTrip, error)
+	SaveRoundTripExcursion(ctx context.Context, tripID uuid.UUID, excursion *entities.PositionExcursion) error
+	// ListRoundTrips returns the user's round trips closed in [from, to],
+	// most recent first.
+	ListRoundTrips(ctx context.Context, userID uuid.UUID, from, to time.Time, limit int) ([]*entities.TradeRoundTrip, error)
+}
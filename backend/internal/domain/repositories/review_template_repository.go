@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+type ReviewTemplateRepository interface {
+	// Create stores the template as the next version for its user and
+	// cadence and sets Version.
+	Create(ctx context.Context, template *entities.ReviewQuestionTemplate) error
+	GetByID(ctx context.Context, userID, id uuid.UUID) (*entities.ReviewQuestionTemplate, error)
+	GetLatest(ctx context.Context, userID uuid.UUID, cadence string) (*entities.ReviewQuestionTemplate, error)
+	ListVersions(ctx context.Context, userID uuid.UUID, cadence string) ([]*entities.ReviewQuestionTemplate, error)
+	// GetForItem returns the template the item's review was created with, or
+	// nil when it has none.
+	GetForItem(ctx context.Context, userID, itemID uuid.UUID) (*entities.ReviewQuestionTemplate, error)
+}
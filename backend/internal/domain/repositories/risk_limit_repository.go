@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+// RiskFill is a trade or an imported trade event as seen by the risk limit
+// checks. Trade events mirrored from synced trades are left out. Market is
+// spot or perp; OrderID falls back to the fill's own id when the order isn't
+// known.
+type RiskFill struct {
+	OrderID     string
+	Market      string
+	SymbolKey   string
+	Side        string
+	Qty         string
+	Price       string
+	RealizedPnL *string
+	Leverage    *string
+	ExecutedAt  time.Time
+}
+
+// RiskEvaluation is a user queued for a risk limit check from Since.
+// QueuedAt is when the user was last queued.
+type RiskEvaluation struct {
+	UserID   uuid.UUID
+	Since    time.Time
+	QueuedAt time.Time
+}
+
+type RiskLimitRepository interface {
+	// GetRule returns the user's risk_limit alert rule, or nil if none.
+	GetRule(ctx context.Context, userID uuid.UUID) (*entities.AlertRule, error)
+	SaveRule(ctx context.Context, rule *entities.AlertRule) error
+	// ListFills returns fills executed in [from, to), oldest first.
+	ListFills(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*RiskFill, error)
+	// NetPositions returns the signed net quantity, as decimal text, of each
+	// open position from fills executed before the given time, keyed by
+	// RiskPositionKey so spot and perp holdings of a symbol stay apart.
+	NetPositions(ctx context.Context, userID uuid.UUID, before time.Time) (map[string]string, error)
+	// QueueEvaluation asks for the user's trading days from since on to be
+	// re-checked, keeping an earlier pending start.
+	QueueEvaluation(ctx context.Context, userID uuid.UUID, since, queuedAt time.Time) error
+	// ListPendingEvaluations returns queued users, longest pending first.
+	ListPendingEvaluations(ctx context.Context, limit int) ([]*RiskEvaluation, error)
+	// CompleteEvaluation clears the queue entry unless the user was queued
+	// again after evaluation.QueuedAt.
+	CompleteEvaluation(ctx context.Context, evaluation *RiskEvaluation, evaluatedAt time.Time) error
+	// CreateBreach stores a breach and reports false if the limit was already
+	// breached that day.
+	CreateBreach(ctx context.Context, breach *entities.RiskLimitBreach) (bool, error)
+	SetBreachAlert(ctx context.Context, breachID, alertID uuid.UUID) error
+	ListBreaches(ctx context.Context, userID uuid.UUID, fromDate, toDate string) ([]*entities.RiskLimitBreach, error)
+	UpsertDisciplineScore(ctx context.Context, score *entities.DisciplineScore) error
+	ListDisciplineScores(ctx context.Context, userID uuid.UUID, fromDate, toDate string) ([]*entities.DisciplineScore, error)
+}
+
+// RiskPositionKey identifies a position in NetPositions.
+func RiskPositionKey(market, symbolKey string) string {
+	return market + ":" + symbolKey
+}
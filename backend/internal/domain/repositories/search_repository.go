@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+// SearchFilter narrows a full-text search. TSQuery is a to_tsquery('simple')
+// expression built from sanitized terms. Snippet highlights are wrapped in
+// HighlightStart/HighlightStop.
+type SearchFilter struct {
+	TSQuery        string
+	Sources        []entities.SearchSource
+	Symbol         string
+	Tags           []string
+	From           *time.Time
+	To             *time.Time
+	HighlightStart string
+	HighlightStop  string
+	Limit          int
+	Offset         int
+}
+
+type SearchRepository interface {
+	Search(ctx context.Context, userID uuid.UUID, filter SearchFilter) ([]*entities.SearchHit, int, error)
+}
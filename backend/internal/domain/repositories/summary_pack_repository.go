@@ -3,15 +3,23 @@ package repositories
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/moneyvessel/kifu/internal/domain/entities"
 )
 
 type SummaryPackRepository interface {
+	// Create stores the pack together with its EvidenceBundle, when set.
 	Create(ctx context.Context, pack *entities.SummaryPack) error
 	GetByID(ctx context.Context, userID uuid.UUID, packID uuid.UUID) (*entities.SummaryPack, error)
 	GetLatest(ctx context.Context, userID uuid.UUID, rangeValue string) (*entities.SummaryPack, error)
+	// GetEvidenceBundle returns nil when the pack has no bundle.
+	GetEvidenceBundle(ctx context.Context, userID uuid.UUID, packID uuid.UUID) (*entities.SummaryPackEvidenceBundle, error)
+	// DeleteOlderThan removes packs created before the cutoff, except each
+	// user's latest pack per range and packs with an unrevoked, unexpired
+	// share, and returns how many were deleted.
+	DeleteOlderThan(ctx context.Context, before time.Time) (int64, error)
 }
 
 type SummaryPackPayloadStore struct {
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+type SummaryPackScheduleRepository interface {
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.SummaryPackSchedule, error)
+	// Upsert saves the schedule, replacing the user's schedule of the same
+	// frequency. ID and CreatedAt are set from the stored row.
+	Upsert(ctx context.Context, schedule *entities.SummaryPackSchedule) error
+	Delete(ctx context.Context, userID uuid.UUID, frequency entities.PackScheduleFrequency) (bool, error)
+	// ClaimDue returns enabled schedules due at now and pushes their next run
+	// out by lease so other workers skip them until RecordRun.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.SummaryPackSchedule, error)
+	RecordRun(ctx context.Context, schedule *entities.SummaryPackSchedule) error
+}
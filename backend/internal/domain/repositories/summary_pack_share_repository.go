@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+type SummaryPackShareRepository interface {
+	Create(ctx context.Context, share *entities.SummaryPackShare) error
+	// GetByID looks a share up without an owner, for public links.
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.SummaryPackShare, error)
+	ListByPack(ctx context.Context, userID uuid.UUID, packID uuid.UUID) ([]*entities.SummaryPackShare, error)
+	Revoke(ctx context.Context, userID uuid.UUID, id uuid.UUID, at time.Time) (bool, error)
+	// RecordView counts a view unless the share was revoked or expired by
+	// at, and reports whether it did.
+	RecordView(ctx context.Context, id uuid.UUID, at time.Time) (bool, error)
+}
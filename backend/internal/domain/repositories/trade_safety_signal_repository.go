@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+// SafetyFill is a trade or trade event as seen by the safety checks.
+// Mirrored marks trade events copied from a synced trade, which are scored
+// but left out of baselines so they aren't counted twice.
+type SafetyFill struct {
+	TargetType string
+	ID         uuid.UUID
+	Venue      string
+	SymbolKey  string
+	Side       string
+	Qty        string
+	Price      string
+	Leverage   *string
+	ExecutedAt time.Time
+	Mirrored   bool
+}
+
+// UnscoredSafetyUser is a user with fills that have no signal yet; From is
+// when the earliest of them was executed.
+type UnscoredSafetyUser struct {
+	UserID uuid.UUID
+	From   time.Time
+}
+
+type TradeSafetySignalRepository interface {
+	// ListUnscoredUsers returns users with fills that have no signal, those
+	// with the oldest unscored fill first.
+	ListUnscoredUsers(ctx context.Context, limit int) ([]*UnscoredSafetyUser, error)
+	// ListFills returns fills executed in [from, to], oldest first.
+	ListFills(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*SafetyFill, error)
+	// ListTradedSymbols returns the symbol keys traded before the given time.
+	ListTradedSymbols(ctx context.Context, userID uuid.UUID, before time.Time) ([]string, error)
+	// UpsertSignals stores signals, leaving those of targets that already
+	// have a verdict as they were when the verdict was given.
+	UpsertSignals(ctx context.Context, userID uuid.UUID, signals []*entities.TradeSafetySignal) error
+	// ListSignals returns the stored signals of the given targets.
+	ListSignals(ctx context.Context, userID uuid.UUID, targetIDs []uuid.UUID) ([]*entities.TradeSafetySignal, error)
+	// ListReviewedSignals returns signals of targets reviewed since the given
+	// time, with their verdict.
+	ListReviewedSignals(ctx context.Context, userID uuid.UUID, since time.Time) ([]*entities.TradeSafetySignal, error)
+}
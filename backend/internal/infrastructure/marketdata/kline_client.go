@@ -0,0 +1,232 @@
+package marketdata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/services"
+)
+
+const (
+	binanceKlineMaxLimit = 1500
+	upbitCandleMaxCount  = 200
+)
+
+// KlineClient fetches OHLCV candles from Binance futures and Upbit.
+type KlineClient struct {
+	binanceBaseURL string
+	upbitBaseURL   string
+	client         *http.Client
+}
+
+func NewKlineClient() *KlineClient {
+	return &KlineClient{
+		binanceBaseURL: "https://fapi.binance.com",
+		upbitBaseURL:   "https://api.upbit.com",
+		client: &http.Client{
+			Timeout: 12 * time.Second,
+		},
+	}
+}
+
+func (c *KlineClient) FetchCandles(ctx context.Context, venue, symbol, interval string, start, end time.Time, limit int) ([]*entities.Candle, error) {
+	switch venue {
+	case entities.CandleVenueUpbit:
+		return c.fetchUpbit(ctx, symbol, interval, start, end, limit)
+	case entities.CandleVenueBinance:
+		return c.fetchBinance(ctx, symbol, interval, start, end, limit)
+	default:
+		return nil, services.ErrUnsupportedCandleVenue
+	}
+}
+
+func (c *KlineClient) fetchBinance(ctx context.Context, symbol, interval string, start, end time.Time, limit int) ([]*entities.Candle, error) {
+	if limit <= 0 || limit > binanceKlineMaxLimit {
+		limit = binanceKlineMaxLimit
+	}
+
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("interval", interval)
+	params.Set("startTime", strconv.FormatInt(start.UTC().UnixMilli(), 10))
+	params.Set("endTime", strconv.FormatInt(end.UTC().UnixMilli(), 10))
+	params.Set("limit", strconv.Itoa(limit))
+
+	requestURL := fmt.Sprintf("%s/fapi/v1/klines?%s", c.binanceBaseURL, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot {
+		return nil, &services.CandleRateLimitError{Venue: entities.CandleVenueBinance, RetryAfter: resp.Header.Get("Retry-After")}
+	}
+	if resp.StatusCode != http.StatusOK {
+		payload, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("binance klines error %d: %s", resp.StatusCode, strings.TrimSpace(string(payload)))
+	}
+
+	var raw [][]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	candles := make([]*entities.Candle, 0, len(raw))
+	for _, row := range raw {
+		if len(row) < 6 {
+			continue
+		}
+		openTime, ok := row[0].(float64)
+		if !ok {
+			continue
+		}
+		values := make([]string, 0, 5)
+		for _, field := range row[1:6] {
+			value, ok := field.(string)
+			if !ok {
+				break
+			}
+			values = append(values, value)
+		}
+		if len(values) != 5 {
+			continue
+		}
+
+		candles = append(candles, &entities.Candle{
+			Venue:    entities.CandleVenueBinance,
+			Symbol:   symbol,
+			Interval: interval,
+			OpenTime: time.UnixMilli(int64(openTime)).UTC(),
+			Open:     values[0],
+			High:     values[1],
+			Low:      values[2],
+			Close:    values[3],
+			Volume:   values[4],
+		})
+	}
+
+	return candles, nil
+}
+
+type upbitCandle struct {
+	CandleDateTimeUTC string  `json:"candle_date_time_utc"`
+	OpenPrice         float64 `json:"opening_price"`
+	HighPrice         float64 `json:"high_price"`
+	LowPrice          float64 `json:"low_price"`
+	ClosePrice        float64 `json:"trade_price"`
+	AccVolume         float64 `json:"candle_acc_trade_volume"`
+}
+
+func upbitIntervalPath(interval string) (string, bool) {
+	switch interval {
+	case "1m":
+		return "minutes/1", true
+	case "5m":
+		return "minutes/5", true
+	case "15m":
+		return "minutes/15", true
+	case "1h":
+		return "minutes/60", true
+	case "4h":
+		return "minutes/240", true
+	case "1d":
+		return "days", true
+	default:
+		return "", false
+	}
+}
+
+// fetchUpbit pages forward from start. Upbit only supports a "to" cursor and
+// returns newest first, so we ask for the window [start, start+count*step).
+func (c *KlineClient) fetchUpbit(ctx context.Context, market, interval string, start, end time.Time, limit int) ([]*entities.Candle, error) {
+	path, ok := upbitIntervalPath(interval)
+	if !ok {
+		return nil, services.ErrUnsupportedCandleInterval
+	}
+	step, _ := services.CandleIntervalDuration(interval)
+	if limit <= 0 || limit > upbitCandleMaxCount {
+		limit = upbitCandleMaxCount
+	}
+
+	to := start.Add(time.Duration(limit) * step)
+	if windowEnd := end.Add(step); to.After(windowEnd) {
+		to = windowEnd
+	}
+
+	params := url.Values{}
+	params.Set("market", market)
+	params.Set("to", to.UTC().Format(time.RFC3339))
+	params.Set("count", strconv.Itoa(limit))
+
+	requestURL := fmt.Sprintf("%s/v1/candles/%s?%s", c.upbitBaseURL, path, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, &services.CandleRateLimitError{Venue: entities.CandleVenueUpbit, RetryAfter: resp.Header.Get("Retry-After")}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		// Upbit returns 404 "Code not found" for unsupported/delisted markets.
+		return []*entities.Candle{}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		payload, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("upbit candles error %d: %s", resp.StatusCode, strings.TrimSpace(string(payload)))
+	}
+
+	var raw []upbitCandle
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	candles := make([]*entities.Candle, 0, len(raw))
+	for i := len(raw) - 1; i >= 0; i-- {
+		row := raw[i]
+		openTime, err := time.Parse("2006-01-02T15:04:05", row.CandleDateTimeUTC)
+		if err != nil {
+			continue
+		}
+		if openTime.Before(start) || openTime.After(end) {
+			continue
+		}
+		candles = append(candles, &entities.Candle{
+			Venue:    entities.CandleVenueUpbit,
+			Symbol:   market,
+			Interval: interval,
+			OpenTime: openTime.UTC(),
+			Open:     formatUpbitPrice(row.OpenPrice),
+			High:     formatUpbitPrice(row.HighPrice),
+			Low:      formatUpbitPrice(row.LowPrice),
+			Close:    formatUpbitPrice(row.ClosePrice),
+			Volume:   formatUpbitPrice(row.AccVolume),
+		})
+	}
+
+	return candles, nil
+}
+
+func formatUpbitPrice(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,84 @@
+package marketdata
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/services"
+)
+
+func newTestKlineClient(baseURL string) *KlineClient {
+	return &KlineClient{
+		binanceBaseURL: baseURL,
+		upbitBaseURL:   baseURL,
+		client:         &http.Client{Timeout: 2 * time.Second},
+	}
+}
+
+func TestFetchUpbitNotFoundSkips(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprint(w, `{"error":{"name":404,"message":"Code not found"}}`)
+	}))
+	defer srv.Close()
+
+	now := time.Now().UTC().Truncate(time.Minute)
+	candles, err := newTestKlineClient(srv.URL).FetchCandles(t.Context(), entities.CandleVenueUpbit, "KRW-UNKNOWN", "1m", now.Add(-time.Minute), now, 1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(candles) != 0 {
+		t.Fatalf("expected not found to skip, got %d candles", len(candles))
+	}
+}
+
+func TestFetchUpbitRateLimited(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	now := time.Now().UTC().Truncate(time.Minute)
+	_, err := newTestKlineClient(srv.URL).FetchCandles(t.Context(), entities.CandleVenueUpbit, "KRW-BTC", "1m", now.Add(-time.Minute), now, 1)
+	var rateErr *services.CandleRateLimitError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if rateErr.RetryAfter != "30" {
+		t.Fatalf("retry-after mismatch: got %q", rateErr.RetryAfter)
+	}
+}
+
+func TestFetchBinanceParsesRows(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fapi/v1/klines" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		_, _ = fmt.Fprint(w, `[[1700000000000,"100.5","101","99","100.8","12.3",1700000059999],[1700000060000,"x"]]`)
+	}))
+	defer srv.Close()
+
+	start := time.UnixMilli(1700000000000).UTC()
+	candles, err := newTestKlineClient(srv.URL).FetchCandles(t.Context(), entities.CandleVenueBinance, "BTCUSDT", "1m", start, start.Add(time.Minute), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(candles) != 1 {
+		t.Fatalf("expected 1 candle, got %d", len(candles))
+	}
+	if !candles[0].OpenTime.Equal(start) || candles[0].Close != "100.8" || candles[0].Volume != "12.3" {
+		t.Fatalf("unexpected candle: %+v", candles[0])
+	}
+}
@@ -0,0 +1,150 @@
+package marketdata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/services"
+)
+
+const stockPriceVenue = "yahoo"
+
+var (
+	krxStockCodePattern  = regexp.MustCompile(`^[0-9]{6}$`)
+	usStockTickerPattern = regexp.MustCompile(`^[A-Z]{1,5}([.-][A-Z])?$`)
+)
+
+// StockPriceClient prices stock bubbles (e.g. KIS imports) from the Yahoo
+// Finance chart API. KRX codes are tried on KOSPI (.KS) first, then KOSDAQ
+// (.KQ); US tickers are used as they are.
+type StockPriceClient struct {
+	baseURL string
+	client  *http.Client
+	now     func() time.Time
+}
+
+func NewStockPriceClient() *StockPriceClient {
+	return &StockPriceClient{
+		baseURL: "https://query1.finance.yahoo.com",
+		client: &http.Client{
+			Timeout: 12 * time.Second,
+		},
+		now: time.Now,
+	}
+}
+
+func (c *StockPriceClient) Name() string {
+	return stockPriceVenue
+}
+
+// Resolve accepts bubbles with asset class stock whose symbol is a KRX code
+// or US ticker, optionally with a KRW/USD quote as written by the importer.
+func (c *StockPriceClient) Resolve(bubble *repositories.PendingOutcomeBubble) (string, bool) {
+	if !strings.EqualFold(strings.TrimSpace(bubble.AssetClass), "stock") {
+		return "", false
+	}
+	base, quote := strings.ToUpper(strings.TrimSpace(bubble.Symbol)), ""
+	if parts := strings.Split(base, "/"); len(parts) == 2 {
+		base, quote = parts[0], parts[1]
+	}
+	switch {
+	case krxStockCodePattern.MatchString(base) && (quote == "" || quote == "KRW"):
+		return base + ".KS", true
+	case usStockTickerPattern.MatchString(base) && (quote == "" || quote == "USD"):
+		return strings.ReplaceAll(base, ".", "-"), true
+	}
+	return "", false
+}
+
+// PriceAt returns the last close at or before target. The bar size shrinks
+// to what Yahoo keeps for the target's age: 1m for about a month, 1h for two
+// years, daily beyond that.
+func (c *StockPriceClient) PriceAt(ctx context.Context, symbol string, target time.Time) (string, bool, error) {
+	price, found, err := c.priceAt(ctx, symbol, target)
+	if err == nil && !found && strings.HasSuffix(symbol, ".KS") {
+		return c.priceAt(ctx, strings.TrimSuffix(symbol, ".KS")+".KQ", target)
+	}
+	return price, found, err
+}
+
+type yahooChartResponse struct {
+	Chart struct {
+		Result []struct {
+			Timestamp  []int64 `json:"timestamp"`
+			Indicators struct {
+				Quote []struct {
+					Close []*float64 `json:"close"`
+				} `json:"quote"`
+			} `json:"indicators"`
+		} `json:"result"`
+	} `json:"chart"`
+}
+
+func (c *StockPriceClient) priceAt(ctx context.Context, symbol string, target time.Time) (string, bool, error) {
+	age := c.now().Sub(target)
+	interval := "1d"
+	switch {
+	case age < 29*24*time.Hour:
+		interval = "1m"
+	case age < 700*24*time.Hour:
+		interval = "1h"
+	}
+
+	// Look back far enough to cross a long weekend or holiday.
+	params := url.Values{}
+	params.Set("period1", strconv.FormatInt(target.Add(-5*24*time.Hour).Unix(), 10))
+	params.Set("period2", strconv.FormatInt(target.Add(time.Minute).Unix(), 10))
+	params.Set("interval", interval)
+
+	requestURL := fmt.Sprintf("%s/v8/finance/chart/%s?%s", c.baseURL, url.PathEscape(symbol), params.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return "", false, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; kifu/1.0)")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return "", false, &services.CandleRateLimitError{Venue: stockPriceVenue, RetryAfter: resp.Header.Get("Retry-After")}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		// Unknown or delisted symbol.
+		return "", false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		payload, _ := io.ReadAll(resp.Body)
+		return "", false, fmt.Errorf("yahoo chart error %d: %s", resp.StatusCode, strings.TrimSpace(string(payload)))
+	}
+
+	var payload yahooChartResponse
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", false, err
+	}
+	if len(payload.Chart.Result) == 0 || len(payload.Chart.Result[0].Indicators.Quote) == 0 {
+		return "", false, nil
+	}
+	result := payload.Chart.Result[0]
+	closes := result.Indicators.Quote[0].Close
+	cutoff := target.Unix()
+	for i := len(result.Timestamp) - 1; i >= 0; i-- {
+		if result.Timestamp[i] > cutoff || i >= len(closes) || closes[i] == nil {
+			continue
+		}
+		return strconv.FormatFloat(*closes[i], 'f', -1, 64), true, nil
+	}
+	return "", false, nil
+}
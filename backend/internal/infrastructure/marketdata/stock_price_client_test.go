@@ -0,0 +1,74 @@
+package marketdata
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+func TestStockPriceClientResolve(t *testing.T) {
+	t.Parallel()
+
+	client := NewStockPriceClient()
+	tests := []struct {
+		symbol     string
+		assetClass string
+		want       string
+		ok         bool
+	}{
+		{"005930/KRW", "stock", "005930.KS", true},
+		{"005930", "stock", "005930.KS", true},
+		{"AAPL/USD", "stock", "AAPL", true},
+		{"BRK.B", "stock", "BRK-B", true},
+		{"BTC/KRW", "stock", "", false},
+		{"005930/KRW", "crypto", "", false},
+	}
+	for _, tc := range tests {
+		got, ok := client.Resolve(&repositories.PendingOutcomeBubble{Symbol: tc.symbol, AssetClass: tc.assetClass})
+		if ok != tc.ok || got != tc.want {
+			t.Fatalf("Resolve(%q, %q) = %q %v, want %q %v", tc.symbol, tc.assetClass, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestStockPriceClientFallsBackToKosdaq(t *testing.T) {
+	t.Parallel()
+
+	target := time.Date(2026, 3, 3, 2, 30, 0, 0, time.UTC)
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		if strings.HasSuffix(r.URL.Path, ".KS") {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `{"chart":{"result":null,"error":{"code":"Not Found"}}}`)
+			return
+		}
+		if got := r.URL.Query().Get("interval"); got != "1m" {
+			t.Errorf("expected 1m bars for a recent target, got %s", got)
+		}
+		_, _ = fmt.Fprintf(w, `{"chart":{"result":[{"timestamp":[%d,%d,%d],"indicators":{"quote":[{"close":[101.5,null,103]}]}}]}}`,
+			target.Add(-2*time.Minute).Unix(), target.Unix(), target.Add(time.Minute).Unix())
+	}))
+	defer srv.Close()
+
+	client := &StockPriceClient{
+		baseURL: srv.URL,
+		client:  &http.Client{Timeout: 2 * time.Second},
+		now:     func() time.Time { return target.Add(time.Hour) },
+	}
+	price, ok, err := client.PriceAt(t.Context(), "247540.KS", target)
+	if err != nil || !ok {
+		t.Fatalf("expected a price, got %v %v", ok, err)
+	}
+	if price != "101.5" {
+		t.Fatalf("expected last non-null close at or before target, got %s", price)
+	}
+	if len(requested) != 2 || !strings.HasSuffix(requested[1], "247540.KQ") {
+		t.Fatalf("expected KOSDAQ retry, got %v", requested)
+	}
+}
@@ -0,0 +1,379 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+const (
+	outboxMaxAttempts = 6
+	outboxBaseBackoff = 30 * time.Second
+	outboxMaxBackoff  = time.Hour
+	outboxClaimLease  = 2 * time.Minute
+	outboxClaimLimit  = 200
+)
+
+var ErrChannelNotSupported = errors.New("notification channel not configured")
+
+// Dispatcher queues messages in the notification outbox, one row per verified
+// channel that routes the message severity, and delivers them either right
+// away or later from FlushDue. Failed sends are retried with exponential
+// backoff and dead-lettered after outboxMaxAttempts.
+type Dispatcher struct {
+	channelRepo  repositories.NotificationChannelRepository
+	deliveryRepo repositories.NotificationDeliveryRepository
+	prefRepo     repositories.NotificationPreferenceRepository
+	senders      map[entities.ChannelType]ChannelSender
+	now          func() time.Time
+}
+
+func NewDispatcher(
+	channelRepo repositories.NotificationChannelRepository,
+	deliveryRepo repositories.NotificationDeliveryRepository,
+	prefRepo repositories.NotificationPreferenceRepository,
+) *Dispatcher {
+	return &Dispatcher{
+		channelRepo:  channelRepo,
+		deliveryRepo: deliveryRepo,
+		prefRepo:     prefRepo,
+		senders:      make(map[entities.ChannelType]ChannelSender),
+		now:          time.Now,
+	}
+}
+
+func (d *Dispatcher) Register(channelType entities.ChannelType, sender ChannelSender) {
+	d.senders[channelType] = sender
+}
+
+func (d *Dispatcher) Supports(channelType entities.ChannelType) bool {
+	_, ok := d.senders[channelType]
+	return ok
+}
+
+// Send enqueues msg for every matching channel. Messages that are due now are
+// attempted immediately; anything held for quiet hours or a digest waits for
+// FlushDue. It only fails when nothing could be queued.
+func (d *Dispatcher) Send(ctx context.Context, userID uuid.UUID, msg Message) error {
+	channels, err := d.channelRepo.ListVerifiedByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	targets := make([]*entities.NotificationChannel, 0, len(channels))
+	for _, channel := range channels {
+		if !d.Supports(channel.ChannelType) || !channel.RoutesSeverity(msg.Severity) {
+			continue
+		}
+		targets = append(targets, channel)
+	}
+	return d.enqueue(ctx, userID, msg, targets)
+}
+
+// SendEscalation re-sends an alert message on every verified channel that has
+// not carried it yet, ignoring severity routing: the point is to reach the
+// user somewhere else. It returns the channels used, which is empty when the
+// user has no other channel.
+func (d *Dispatcher) SendEscalation(ctx context.Context, userID uuid.UUID, msg Message) ([]entities.ChannelType, error) {
+	if msg.AlertID == nil {
+		return nil, errors.New("escalation requires an alert id")
+	}
+	previous, err := d.deliveryRepo.ListByAlert(ctx, *msg.AlertID)
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[entities.ChannelType]bool, len(previous))
+	for _, delivery := range previous {
+		used[delivery.ChannelType] = true
+	}
+
+	channels, err := d.channelRepo.ListVerifiedByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var targets []*entities.NotificationChannel
+	var types []entities.ChannelType
+	for _, channel := range channels {
+		if used[channel.ChannelType] || !d.Supports(channel.ChannelType) {
+			continue
+		}
+		targets = append(targets, channel)
+		types = append(types, channel.ChannelType)
+	}
+	if err := d.enqueue(ctx, userID, msg, targets); err != nil {
+		return nil, err
+	}
+	return types, nil
+}
+
+// SendToChannels enqueues msg only on the user's verified channels of the
+// given types, ignoring severity routing since the user picked them for this
+// message. It returns the channels used, which is empty when none of them is
+// set up.
+func (d *Dispatcher) SendToChannels(ctx context.Context, userID uuid.UUID, msg Message, types []entities.ChannelType) ([]entities.ChannelType, error) {
+	wanted := make(map[entities.ChannelType]bool, len(types))
+	for _, channelType := range types {
+		wanted[channelType] = true
+	}
+
+	channels, err := d.channelRepo.ListVerifiedByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var targets []*entities.NotificationChannel
+	var used []entities.ChannelType
+	for _, channel := range channels {
+		if !wanted[channel.ChannelType] || !d.Supports(channel.ChannelType) {
+			continue
+		}
+		targets = append(targets, channel)
+		used = append(used, channel.ChannelType)
+	}
+	if err := d.enqueue(ctx, userID, msg, targets); err != nil {
+		return nil, err
+	}
+	return used, nil
+}
+
+// enqueue writes one outbox row per target and, when the message is due now,
+// attempts them inline.
+func (d *Dispatcher) enqueue(ctx context.Context, userID uuid.UUID, msg Message, targets []*entities.NotificationChannel) error {
+	if len(targets) == 0 {
+		return nil
+	}
+
+	pref := d.preference(ctx, userID)
+	now := d.now().UTC()
+	nextAt, digest := scheduleDelivery(pref, msg.Severity, now)
+	immediate := !nextAt.After(now)
+	if immediate {
+		// Keep the worker off these rows while we try them inline.
+		nextAt = now.Add(outboxClaimLease)
+	}
+
+	type queued struct {
+		channel  *entities.NotificationChannel
+		delivery *entities.NotificationDelivery
+	}
+	var rows []queued
+	var enqueueErrs []error
+	for _, channel := range targets {
+		delivery := &entities.NotificationDelivery{
+			ID:            uuid.New(),
+			UserID:        userID,
+			ChannelType:   channel.ChannelType,
+			AlertID:       msg.AlertID,
+			Title:         msg.Title,
+			Body:          msg.Body,
+			DeepLink:      msg.DeepLink,
+			Severity:      msg.Severity,
+			Digest:        digest,
+			Status:        entities.DeliveryStatusPending,
+			NextAttemptAt: nextAt,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		if err := d.deliveryRepo.Create(ctx, delivery); err != nil {
+			enqueueErrs = append(enqueueErrs, fmt.Errorf("%s: %w", channel.ChannelType, err))
+			continue
+		}
+		rows = append(rows, queued{channel: channel, delivery: delivery})
+	}
+	if len(rows) == 0 {
+		return errors.Join(enqueueErrs...)
+	}
+	if !immediate {
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for _, row := range rows {
+		wg.Add(1)
+		go func(row queued) {
+			defer wg.Done()
+			d.attempt(ctx, row.channel, msg, []*entities.NotificationDelivery{row.delivery})
+		}(row)
+	}
+	wg.Wait()
+	return nil
+}
+
+// SendDirect sends to a single channel regardless of verification or routing,
+// e.g. for verification codes. It bypasses the outbox.
+func (d *Dispatcher) SendDirect(ctx context.Context, channel *entities.NotificationChannel, msg Message) error {
+	sender, ok := d.senders[channel.ChannelType]
+	if !ok {
+		return ErrChannelNotSupported
+	}
+	return sender.SendToChannel(ctx, channel, msg)
+}
+
+// FlushDue delivers every outbox row whose next attempt is due. Digest rows
+// for the same user and channel are folded into one message. It returns the
+// number of rows processed.
+func (d *Dispatcher) FlushDue(ctx context.Context) (int, error) {
+	now := d.now().UTC()
+	due, err := d.deliveryRepo.ClaimDue(ctx, now, outboxClaimLease, outboxClaimLimit)
+	if err != nil {
+		return 0, err
+	}
+
+	type groupKey struct {
+		userID      uuid.UUID
+		channelType entities.ChannelType
+	}
+	digests := make(map[groupKey][]*entities.NotificationDelivery)
+	var order []groupKey
+	var singles [][]*entities.NotificationDelivery
+	for _, delivery := range due {
+		if !delivery.Digest {
+			singles = append(singles, []*entities.NotificationDelivery{delivery})
+			continue
+		}
+		key := groupKey{userID: delivery.UserID, channelType: delivery.ChannelType}
+		if _, ok := digests[key]; !ok {
+			order = append(order, key)
+		}
+		digests[key] = append(digests[key], delivery)
+	}
+	groups := singles
+	for _, key := range order {
+		groups = append(groups, digests[key])
+	}
+
+	for _, group := range groups {
+		first := group[0]
+		channel, err := d.channelRepo.GetByUserAndType(ctx, first.UserID, first.ChannelType)
+		if err != nil {
+			log.Printf("notification outbox: load channel failed: %v", err)
+			continue
+		}
+		if channel == nil || !channel.Enabled || !channel.Verified || !d.Supports(channel.ChannelType) {
+			d.markDead(ctx, group, "channel unavailable")
+			continue
+		}
+		d.attempt(ctx, channel, composeDigest(group), group)
+	}
+
+	return len(due), nil
+}
+
+// attempt sends msg once and records the outcome on every row in the group.
+func (d *Dispatcher) attempt(ctx context.Context, channel *entities.NotificationChannel, msg Message, group []*entities.NotificationDelivery) {
+	sendErr := d.senders[channel.ChannelType].SendToChannel(ctx, channel, msg)
+	now := d.now().UTC()
+
+	for _, delivery := range group {
+		delivery.Attempts++
+		delivery.UpdatedAt = now
+		if sendErr == nil {
+			delivery.Status = entities.DeliveryStatusSent
+			delivery.SentAt = &now
+			delivery.Error = nil
+		} else {
+			errText := sendErr.Error()
+			delivery.Error = &errText
+			if delivery.Attempts >= outboxMaxAttempts {
+				delivery.Status = entities.DeliveryStatusDead
+			} else {
+				delivery.Status = entities.DeliveryStatusFailed
+				delivery.NextAttemptAt = now.Add(retryBackoff(delivery.Attempts))
+			}
+		}
+		if err := d.deliveryRepo.UpdateAttempt(ctx, delivery); err != nil {
+			log.Printf("notification outbox: update delivery %s failed: %v", delivery.ID, err)
+		}
+	}
+
+	if sendErr != nil {
+		log.Printf("notification outbox: %s send failed (attempt %d): %v", channel.ChannelType, group[0].Attempts, sendErr)
+	}
+}
+
+func (d *Dispatcher) markDead(ctx context.Context, group []*entities.NotificationDelivery, reason string) {
+	now := d.now().UTC()
+	for _, delivery := range group {
+		delivery.Status = entities.DeliveryStatusDead
+		delivery.Error = &reason
+		delivery.UpdatedAt = now
+		if err := d.deliveryRepo.UpdateAttempt(ctx, delivery); err != nil {
+			log.Printf("notification outbox: update delivery %s failed: %v", delivery.ID, err)
+		}
+	}
+}
+
+func (d *Dispatcher) preference(ctx context.Context, userID uuid.UUID) *entities.NotificationPreference {
+	if d.prefRepo != nil {
+		pref, err := d.prefRepo.GetByUser(ctx, userID)
+		if err != nil {
+			log.Printf("notification outbox: load preferences failed: %v", err)
+		}
+		if pref != nil {
+			return pref
+		}
+	}
+	return entities.DefaultNotificationPreference(userID)
+}
+
+// scheduleDelivery decides when a message may go out and whether it should be
+// folded into a digest. Urgent alerts go out immediately unless the user has
+// turned off the quiet-hours bypass; normal alerts wait out quiet hours and
+// the digest window.
+func scheduleDelivery(pref *entities.NotificationPreference, severity string, now time.Time) (time.Time, bool) {
+	quiet := pref.InQuietHours(now)
+	if severity == string(entities.AlertSeverityUrgent) {
+		if quiet && !pref.UrgentBypassQuiet {
+			return pref.QuietHoursEnd(now), false
+		}
+		return now, false
+	}
+	if quiet {
+		return pref.QuietHoursEnd(now), true
+	}
+	if pref.DigestEnabled {
+		return pref.NextDigestAt(now), true
+	}
+	return now, false
+}
+
+func retryBackoff(attempts int) time.Duration {
+	backoff := outboxBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+	return backoff
+}
+
+func composeDigest(group []*entities.NotificationDelivery) Message {
+	first := group[0]
+	if len(group) == 1 {
+		return Message{
+			Title:    first.Title,
+			Body:     first.Body,
+			Severity: first.Severity,
+			DeepLink: first.DeepLink,
+			AlertID:  first.AlertID,
+		}
+	}
+
+	lines := make([]string, 0, len(group))
+	for _, delivery := range group {
+		lines = append(lines, "• "+delivery.Title)
+	}
+	return Message{
+		Title:    fmt.Sprintf("알림 요약 %d건", len(group)),
+		Body:     strings.Join(lines, "\n"),
+		Severity: string(entities.AlertSeverityNormal),
+	}
+}
@@ -0,0 +1,329 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+type fakeChannelRepo struct {
+	channels []*entities.NotificationChannel
+}
+
+func (r *fakeChannelRepo) Upsert(context.Context, *entities.NotificationChannel) error { return nil }
+func (r *fakeChannelRepo) GetByUserAndType(_ context.Context, _ uuid.UUID, channelType entities.ChannelType) (*entities.NotificationChannel, error) {
+	for _, channel := range r.channels {
+		if channel.ChannelType == channelType {
+			return channel, nil
+		}
+	}
+	return nil, nil
+}
+func (r *fakeChannelRepo) ListByUser(context.Context, uuid.UUID) ([]*entities.NotificationChannel, error) {
+	return r.channels, nil
+}
+func (r *fakeChannelRepo) DeleteByUserAndType(context.Context, uuid.UUID, entities.ChannelType) error {
+	return nil
+}
+func (r *fakeChannelRepo) ListVerifiedByUser(context.Context, uuid.UUID) ([]*entities.NotificationChannel, error) {
+	return r.channels, nil
+}
+func (r *fakeChannelRepo) GetTelegramByChatID(context.Context, int64) (*entities.NotificationChannel, error) {
+	return nil, nil
+}
+func (r *fakeChannelRepo) UpdateRouting(context.Context, uuid.UUID, entities.ChannelType, bool, []string) error {
+	return nil
+}
+
+type fakeDeliveryRepo struct {
+	mu    sync.Mutex
+	items []*entities.NotificationDelivery
+}
+
+func (r *fakeDeliveryRepo) Create(_ context.Context, delivery *entities.NotificationDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items = append(r.items, delivery)
+	return nil
+}
+func (r *fakeDeliveryRepo) ListByUser(context.Context, uuid.UUID, string, int) ([]*entities.NotificationDelivery, error) {
+	return r.items, nil
+}
+func (r *fakeDeliveryRepo) ListByAlert(_ context.Context, alertID uuid.UUID) ([]*entities.NotificationDelivery, error) {
+	var out []*entities.NotificationDelivery
+	for _, d := range r.items {
+		if d.AlertID != nil && *d.AlertID == alertID {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+func (r *fakeDeliveryRepo) ClaimDue(_ context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.NotificationDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*entities.NotificationDelivery
+	for _, d := range r.items {
+		if len(out) == limit {
+			break
+		}
+		if (d.Status == entities.DeliveryStatusPending || d.Status == entities.DeliveryStatusFailed) && !d.NextAttemptAt.After(now) {
+			d.NextAttemptAt = now.Add(lease)
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+func (r *fakeDeliveryRepo) UpdateAttempt(context.Context, *entities.NotificationDelivery) error {
+	return nil
+}
+func (r *fakeDeliveryRepo) Requeue(context.Context, uuid.UUID, uuid.UUID, time.Time) (bool, error) {
+	return false, nil
+}
+
+func (r *fakeDeliveryRepo) countStatus(status entities.DeliveryStatus) int {
+	count := 0
+	for _, d := range r.items {
+		if d.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+type fakePrefRepo struct {
+	pref *entities.NotificationPreference
+}
+
+func (r *fakePrefRepo) GetByUser(context.Context, uuid.UUID) (*entities.NotificationPreference, error) {
+	return r.pref, nil
+}
+func (r *fakePrefRepo) Upsert(context.Context, *entities.NotificationPreference) error { return nil }
+
+type fakeChannelSender struct {
+	mu       sync.Mutex
+	sent     int
+	err      error
+	messages []Message
+}
+
+func (s *fakeChannelSender) SendToChannel(_ context.Context, _ *entities.NotificationChannel, msg Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent++
+	s.messages = append(s.messages, msg)
+	return s.err
+}
+
+func TestDispatcherRoutesBySeverity(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	channels := &fakeChannelRepo{channels: []*entities.NotificationChannel{
+		{UserID: userID, ChannelType: entities.ChannelSlack, Severities: []string{"normal", "urgent"}},
+		{UserID: userID, ChannelType: entities.ChannelDiscord, Severities: []string{"urgent"}},
+	}}
+	deliveries := &fakeDeliveryRepo{}
+	slack := &fakeChannelSender{}
+	discord := &fakeChannelSender{}
+
+	d := NewDispatcher(channels, deliveries, &fakePrefRepo{})
+	d.Register(entities.ChannelSlack, slack)
+	d.Register(entities.ChannelDiscord, discord)
+
+	if err := d.Send(context.Background(), userID, Message{Title: "t", Severity: "normal"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if slack.sent != 1 || discord.sent != 0 {
+		t.Fatalf("expected normal alert only on slack, got slack=%d discord=%d", slack.sent, discord.sent)
+	}
+
+	if err := d.Send(context.Background(), userID, Message{Title: "t", Severity: "urgent"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if slack.sent != 2 || discord.sent != 1 {
+		t.Fatalf("expected urgent alert on both, got slack=%d discord=%d", slack.sent, discord.sent)
+	}
+	if len(deliveries.items) != 3 {
+		t.Fatalf("expected 3 delivery logs, got %d", len(deliveries.items))
+	}
+}
+
+func TestDispatcherRetriesWithBackoffThenDeadLetters(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	channels := &fakeChannelRepo{channels: []*entities.NotificationChannel{
+		{UserID: userID, ChannelType: entities.ChannelSlack, Enabled: true, Verified: true},
+	}}
+	deliveries := &fakeDeliveryRepo{}
+	broken := &fakeChannelSender{err: errors.New("boom")}
+
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	d := NewDispatcher(channels, deliveries, &fakePrefRepo{})
+	d.Register(entities.ChannelSlack, broken)
+	d.now = func() time.Time { return now }
+
+	if err := d.Send(context.Background(), userID, Message{Title: "t", Severity: "urgent"}); err != nil {
+		t.Fatalf("send should queue despite failure, got %v", err)
+	}
+	delivery := deliveries.items[0]
+	if delivery.Status != entities.DeliveryStatusFailed || !delivery.NextAttemptAt.Equal(now.Add(30*time.Second)) {
+		t.Fatalf("expected retry in 30s, got %s at %v", delivery.Status, delivery.NextAttemptAt)
+	}
+
+	for i := 0; i < outboxMaxAttempts; i++ {
+		now = delivery.NextAttemptAt
+		if _, err := d.FlushDue(context.Background()); err != nil {
+			t.Fatalf("flush: %v", err)
+		}
+	}
+	if delivery.Status != entities.DeliveryStatusDead {
+		t.Fatalf("expected dead letter, got %s", delivery.Status)
+	}
+	if delivery.Attempts != outboxMaxAttempts || broken.sent != outboxMaxAttempts {
+		t.Fatalf("expected %d attempts, got %d (sent %d)", outboxMaxAttempts, delivery.Attempts, broken.sent)
+	}
+}
+
+func TestDispatcherHoldsNormalAlertsDuringQuietHours(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	channels := &fakeChannelRepo{channels: []*entities.NotificationChannel{
+		{UserID: userID, ChannelType: entities.ChannelSlack, Enabled: true, Verified: true},
+	}}
+	pref := entities.DefaultNotificationPreference(userID)
+	pref.Timezone = "Asia/Seoul"
+	pref.QuietHoursEnabled = true
+	deliveries := &fakeDeliveryRepo{}
+	slack := &fakeChannelSender{}
+
+	// 03:00 KST
+	now := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	d := NewDispatcher(channels, deliveries, &fakePrefRepo{pref: pref})
+	d.Register(entities.ChannelSlack, slack)
+	d.now = func() time.Time { return now }
+
+	for _, title := range []string{"a", "b"} {
+		if err := d.Send(context.Background(), userID, Message{Title: title, Severity: "normal"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := d.Send(context.Background(), userID, Message{Title: "urgent", Severity: "urgent"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if slack.sent != 1 || slack.messages[0].Title != "urgent" {
+		t.Fatalf("expected only the urgent alert to break through, got %d sends", slack.sent)
+	}
+
+	quietEnd := time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC) // 07:00 KST
+	for _, delivery := range deliveries.items[:2] {
+		if !delivery.Digest || !delivery.NextAttemptAt.Equal(quietEnd) {
+			t.Fatalf("expected digest held until %v, got digest=%v at %v", quietEnd, delivery.Digest, delivery.NextAttemptAt)
+		}
+	}
+
+	now = quietEnd
+	if _, err := d.FlushDue(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if slack.sent != 2 || slack.messages[1].Title != "알림 요약 2건" {
+		t.Fatalf("expected one digest message, got %d sends", slack.sent)
+	}
+	if deliveries.countStatus(entities.DeliveryStatusSent) != 3 {
+		t.Fatalf("expected all deliveries sent, got %d", deliveries.countStatus(entities.DeliveryStatusSent))
+	}
+}
+
+func TestDispatcherEscalatesOnUnusedChannel(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	alertID := uuid.New()
+	channels := &fakeChannelRepo{channels: []*entities.NotificationChannel{
+		{UserID: userID, ChannelType: entities.ChannelSlack, Severities: []string{"urgent"}},
+		{UserID: userID, ChannelType: entities.ChannelDiscord, Severities: []string{"normal"}},
+	}}
+	deliveries := &fakeDeliveryRepo{}
+	slack := &fakeChannelSender{}
+	discord := &fakeChannelSender{}
+
+	d := NewDispatcher(channels, deliveries, &fakePrefRepo{})
+	d.Register(entities.ChannelSlack, slack)
+	d.Register(entities.ChannelDiscord, discord)
+
+	msg := Message{Title: "t", Severity: "urgent", AlertID: &alertID}
+	if err := d.Send(context.Background(), userID, msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	used, err := d.SendEscalation(context.Background(), userID, msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(used) != 1 || used[0] != entities.ChannelDiscord {
+		t.Fatalf("expected escalation on discord, got %v", used)
+	}
+	if slack.sent != 1 || discord.sent != 1 {
+		t.Fatalf("expected one send per channel, got slack=%d discord=%d", slack.sent, discord.sent)
+	}
+
+	used, err = d.SendEscalation(context.Background(), userID, msg)
+	if err != nil || len(used) != 0 {
+		t.Fatalf("expected no channel left to escalate to, got %v (%v)", used, err)
+	}
+}
+
+func TestDispatcherSendsOnlyToRequestedChannels(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	channels := &fakeChannelRepo{channels: []*entities.NotificationChannel{
+		{UserID: userID, ChannelType: entities.ChannelTelegram, Severities: []string{"urgent"}},
+		{UserID: userID, ChannelType: entities.ChannelSlack},
+	}}
+	deliveries := &fakeDeliveryRepo{}
+	telegram := &fakeChannelSender{}
+	slack := &fakeChannelSender{}
+
+	d := NewDispatcher(channels, deliveries, &fakePrefRepo{})
+	d.Register(entities.ChannelTelegram, telegram)
+	d.Register(entities.ChannelSlack, slack)
+
+	types := []entities.ChannelType{entities.ChannelTelegram, entities.ChannelEmail}
+	used, err := d.SendToChannels(context.Background(), userID, Message{Title: "t", Severity: "normal"}, types)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(used) != 1 || used[0] != entities.ChannelTelegram {
+		t.Fatalf("expected delivery on telegram only, got %v", used)
+	}
+	if telegram.sent != 1 || slack.sent != 0 {
+		t.Fatalf("expected one telegram send, got telegram=%d slack=%d", telegram.sent, slack.sent)
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		channelType entities.ChannelType
+		url         string
+		ok          bool
+	}{
+		{entities.ChannelDiscord, "https://discord.com/api/webhooks/1/abc", true},
+		{entities.ChannelSlack, "https://hooks.slack.com/services/T/B/X", true},
+		{entities.ChannelSlack, "https://discord.com/api/webhooks/1/abc", false},
+		{entities.ChannelDiscord, "http://169.254.169.254/latest", false},
+	}
+	for _, tc := range cases {
+		err := ValidateWebhookURL(tc.channelType, tc.url)
+		if (err == nil) != tc.ok {
+			t.Fatalf("%s %s: expected ok=%v, got %v", tc.channelType, tc.url, tc.ok, err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package notification
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+// smtpTimeout bounds a send when the caller's context has no deadline.
+const smtpTimeout = 30 * time.Second
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+type EmailSender struct {
+	cfg SMTPConfig
+}
+
+func NewEmailSender(cfg SMTPConfig) *EmailSender {
+	if cfg.Port == "" {
+		cfg.Port = "587"
+	}
+	return &EmailSender{cfg: cfg}
+}
+
+func (s *EmailSender) SendToChannel(ctx context.Context, channel *entities.NotificationChannel, msg Message) error {
+	var cfg entities.EmailConfig
+	if err := json.Unmarshal(channel.Config, &cfg); err != nil {
+		return err
+	}
+	if cfg.Address == "" {
+		return nil
+	}
+
+	subject := msg.Title
+	if msg.Severity == "urgent" {
+		subject = "[긴급] " + subject
+	}
+	body := msg.Body
+	if msg.DeepLink != "" {
+		body += "\n\n상세 확인하기: " + msg.DeepLink
+	}
+	return s.sendMail(ctx, cfg.Address, subject, body)
+}
+
+// sendMail does what smtp.SendMail does over a connection bound to ctx, so a
+// stalled server cannot hold the caller past its deadline.
+func (s *EmailSender) sendMail(ctx context.Context, to string, subject string, body string) error {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("From: %s\r\n", s.cfg.From))
+	b.WriteString(fmt.Sprintf("To: %s\r\n", to))
+	b.WriteString(fmt.Sprintf("Subject: %s\r\n", encodeSubject(subject)))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, smtpTimeout)
+		defer cancel()
+	}
+	if err := s.deliver(ctx, to, []byte(b.String())); err != nil {
+		return fmt.Errorf("smtp send error: %w", err)
+	}
+	return nil
+}
+
+func (s *EmailSender) deliver(ctx context.Context, to string, msg []byte) error {
+	addr := net.JoinHostPort(s.cfg.Host, s.cfg.Port)
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	// Cancellation before the deadline unblocks any pending read or write.
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Username != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := client.Mail(s.cfg.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// encodeSubject RFC 2047-encodes non-ASCII subjects so Korean titles survive
+// mail clients that read raw headers as ASCII.
+func encodeSubject(subject string) string {
+	return mime.BEncoding.Encode("UTF-8", sanitizeHeader(subject))
+}
+
+func sanitizeHeader(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
@@ -0,0 +1,62 @@
+package notification
+
+import (
+	"context"
+	"mime"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestEncodeSubjectRoundTripsKorean(t *testing.T) {
+	t.Parallel()
+
+	subject := "[긴급] BTCUSDT 하락 알림"
+	encoded := encodeSubject(subject)
+	if encoded == subject {
+		t.Fatalf("expected non-ASCII subject to be encoded, got %q", encoded)
+	}
+	decoded, err := new(mime.WordDecoder).DecodeHeader(encoded)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if decoded != subject {
+		t.Fatalf("expected %q, got %q", subject, decoded)
+	}
+	if got := encodeSubject("plain\r\nBcc: x"); got != "plain  Bcc: x" {
+		t.Fatalf("expected ASCII subject sanitized and unencoded, got %q", got)
+	}
+}
+
+func TestSendMailGivesUpOnStalledServer(t *testing.T) {
+	t.Parallel()
+
+	// The server accepts but never sends its greeting.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	sender := NewEmailSender(SMTPConfig{Host: host, Port: port, From: "kifu@example.com"})
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	if err := sender.sendMail(ctx, "user@example.com", "subject", "body"); err == nil {
+		t.Fatalf("expected an error from a stalled server")
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Fatalf("send took %s, want it bounded by the context", elapsed)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
 )
 
 type Message struct {
@@ -11,8 +12,14 @@ type Message struct {
 	Body     string
 	Severity string // "normal" | "urgent"
 	DeepLink string
+	AlertID  *uuid.UUID
 }
 
 type Sender interface {
 	Send(ctx context.Context, userID uuid.UUID, msg Message) error
 }
+
+// ChannelSender delivers a message to one already-resolved channel.
+type ChannelSender interface {
+	SendToChannel(ctx context.Context, channel *entities.NotificationChannel, msg Message) error
+}
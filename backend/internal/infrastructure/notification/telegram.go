@@ -37,6 +37,10 @@ func (t *TelegramSender) Send(ctx context.Context, userID uuid.UUID, msg Message
 		return nil // No verified Telegram channel
 	}
 
+	return t.SendToChannel(ctx, channel, msg)
+}
+
+func (t *TelegramSender) SendToChannel(ctx context.Context, channel *entities.NotificationChannel, msg Message) error {
 	var tgConfig entities.TelegramConfig
 	if err := json.Unmarshal(channel.Config, &tgConfig); err != nil {
 		return err
@@ -45,26 +49,86 @@ func (t *TelegramSender) Send(ctx context.Context, userID uuid.UUID, msg Message
 		return nil
 	}
 
-	text := formatTelegramMessage(msg)
-	return t.sendMessage(ctx, tgConfig.ChatID, text)
+	payload := map[string]interface{}{
+		"chat_id":    tgConfig.ChatID,
+		"text":       formatTelegramMessage(msg),
+		"parse_mode": "HTML",
+	}
+	if msg.AlertID != nil {
+		payload["reply_markup"] = decisionKeyboard(*msg.AlertID)
+	}
+	return t.call(ctx, "sendMessage", payload, nil)
 }
 
 func (t *TelegramSender) SendToChatID(ctx context.Context, chatID int64, text string) error {
 	return t.sendMessage(ctx, chatID, text)
 }
 
+// SendPrompt sends a message that opens the reply box in the client and
+// returns its message id so the answer can be matched to the prompt.
+func (t *TelegramSender) SendPrompt(ctx context.Context, chatID int64, text string) (int64, error) {
+	payload := map[string]interface{}{
+		"chat_id":      chatID,
+		"text":         text,
+		"parse_mode":   "HTML",
+		"reply_markup": map[string]interface{}{"force_reply": true},
+	}
+	var sent struct {
+		MessageID int64 `json:"message_id"`
+	}
+	if err := t.call(ctx, "sendMessage", payload, &sent); err != nil {
+		return 0, err
+	}
+	return sent.MessageID, nil
+}
+
+// AnswerCallback acknowledges an inline button press so the client stops
+// showing a spinner.
+func (t *TelegramSender) AnswerCallback(ctx context.Context, callbackID string, text string) error {
+	payload := map[string]interface{}{
+		"callback_query_id": callbackID,
+		"text":              text,
+	}
+	return t.call(ctx, "answerCallbackQuery", payload, nil)
+}
+
+// ClearButtons removes the inline keyboard from a message once it is used.
+func (t *TelegramSender) ClearButtons(ctx context.Context, chatID int64, messageID int64) error {
+	payload := map[string]interface{}{
+		"chat_id":      chatID,
+		"message_id":   messageID,
+		"reply_markup": map[string]interface{}{"inline_keyboard": [][]interface{}{}},
+	}
+	return t.call(ctx, "editMessageReplyMarkup", payload, nil)
+}
+
+// SetWebhook points the bot at url. Telegram echoes secretToken back in the
+// X-Telegram-Bot-Api-Secret-Token header of every update it delivers.
+func (t *TelegramSender) SetWebhook(ctx context.Context, url string, secretToken string) error {
+	payload := map[string]interface{}{
+		"url":             url,
+		"secret_token":    secretToken,
+		"allowed_updates": []string{"message", "callback_query"},
+	}
+	return t.call(ctx, "setWebhook", payload, nil)
+}
+
 func (t *TelegramSender) sendMessage(ctx context.Context, chatID int64, text string) error {
 	payload := map[string]interface{}{
 		"chat_id":    chatID,
 		"text":       text,
 		"parse_mode": "HTML",
 	}
+	return t.call(ctx, "sendMessage", payload, nil)
+}
+
+func (t *TelegramSender) call(ctx context.Context, method string, payload map[string]interface{}, result interface{}) error {
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return err
 	}
 
-	reqURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", t.botToken)
+	reqURL := fmt.Sprintf("https://api.telegram.org/bot%s/%s", t.botToken, method)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, bytes.NewReader(body))
 	if err != nil {
 		return err
@@ -79,10 +143,64 @@ func (t *TelegramSender) sendMessage(ctx context.Context, chatID int64, text str
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("telegram sendMessage error %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+		return fmt.Errorf("telegram %s error %d: %s", method, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if result == nil {
+		return nil
+	}
+
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return err
+	}
+	return json.Unmarshal(envelope.Result, result)
+}
+
+const telegramDecisionPrefix = "decision:"
+
+var telegramDecisionButtons = []struct {
+	Label  string
+	Action entities.DecisionAction
+}{
+	{"매수", entities.DecisionBuy},
+	{"매도", entities.DecisionSell},
+	{"홀드", entities.DecisionHold},
+	{"청산", entities.DecisionClose},
+	{"무시", entities.DecisionIgnore},
+}
+
+func decisionKeyboard(alertID uuid.UUID) map[string]interface{} {
+	row := make([]map[string]string, 0, len(telegramDecisionButtons))
+	for _, button := range telegramDecisionButtons {
+		row = append(row, map[string]string{
+			"text":          button.Label,
+			"callback_data": TelegramDecisionCallback(button.Action, alertID),
+		})
 	}
+	return map[string]interface{}{"inline_keyboard": [][]map[string]string{row}}
+}
 
-	return nil
+// TelegramDecisionCallback builds the callback_data for a decision button.
+// Telegram caps callback_data at 64 bytes.
+func TelegramDecisionCallback(action entities.DecisionAction, alertID uuid.UUID) string {
+	return telegramDecisionPrefix + string(action) + ":" + alertID.String()
+}
+
+func ParseTelegramDecisionCallback(data string) (entities.DecisionAction, uuid.UUID, bool) {
+	if !strings.HasPrefix(data, telegramDecisionPrefix) {
+		return "", uuid.Nil, false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(data, telegramDecisionPrefix), ":", 2)
+	if len(parts) != 2 {
+		return "", uuid.Nil, false
+	}
+	alertID, err := uuid.Parse(parts[1])
+	if err != nil {
+		return "", uuid.Nil, false
+	}
+	return entities.DecisionAction(parts[0]), alertID, true
 }
 
 func formatTelegramMessage(msg Message) string {
@@ -0,0 +1,110 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+var ErrInvalidWebhookURL = errors.New("invalid webhook url")
+
+var webhookURLPrefixes = map[entities.ChannelType][]string{
+	entities.ChannelDiscord: {"https://discord.com/api/webhooks/", "https://discordapp.com/api/webhooks/"},
+	entities.ChannelSlack:   {"https://hooks.slack.com/services/"},
+}
+
+// ValidateWebhookURL only accepts the official webhook hosts so user input
+// can't point the server at arbitrary URLs.
+func ValidateWebhookURL(channelType entities.ChannelType, raw string) error {
+	trimmed := strings.TrimSpace(raw)
+	for _, prefix := range webhookURLPrefixes[channelType] {
+		if strings.HasPrefix(trimmed, prefix) && len(trimmed) > len(prefix) {
+			return nil
+		}
+	}
+	return ErrInvalidWebhookURL
+}
+
+// WebhookSender posts messages to Discord or Slack incoming webhooks.
+type WebhookSender struct {
+	channelType entities.ChannelType
+	client      *http.Client
+}
+
+func NewDiscordSender() *WebhookSender {
+	return &WebhookSender{
+		channelType: entities.ChannelDiscord,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func NewSlackSender() *WebhookSender {
+	return &WebhookSender{
+		channelType: entities.ChannelSlack,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (w *WebhookSender) SendToChannel(ctx context.Context, channel *entities.NotificationChannel, msg Message) error {
+	var cfg entities.WebhookConfig
+	if err := json.Unmarshal(channel.Config, &cfg); err != nil {
+		return err
+	}
+	if err := ValidateWebhookURL(w.channelType, cfg.WebhookURL); err != nil {
+		return err
+	}
+
+	var payload map[string]interface{}
+	if w.channelType == entities.ChannelDiscord {
+		payload = map[string]interface{}{"content": formatWebhookMessage(msg, "**%s**")}
+	} else {
+		payload = map[string]interface{}{"text": formatWebhookMessage(msg, "*%s*")}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSpace(cfg.WebhookURL), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s webhook error %d: %s", w.channelType, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+func formatWebhookMessage(msg Message, boldFormat string) string {
+	var b strings.Builder
+	if msg.Severity == "urgent" {
+		b.WriteString("🔴 [긴급] ")
+	} else {
+		b.WriteString("🔔 ")
+	}
+	b.WriteString(fmt.Sprintf(boldFormat, msg.Title))
+	b.WriteString("\n\n")
+	b.WriteString(msg.Body)
+	if msg.DeepLink != "" {
+		b.WriteString("\n\n상세 확인하기: ")
+		b.WriteString(msg.DeepLink)
+	}
+	return b.String()
+}
@@ -6,8 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"math/big"
 	"net/http"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
@@ -16,27 +18,53 @@ import (
 	"github.com/moneyvessel/kifu/internal/services"
 )
 
-type BaseRPCClient struct {
+// onchainLookbackSlack widens block ranges for block time drift, and
+// onchainMaxLookback caps them just past the longest quick check range.
+const (
+	onchainMaxLookback   = 35 * 24 * time.Hour
+	onchainLookbackSlack = 6 * time.Hour
+)
+
+// RPCClient reads ERC20 transfers from one EVM chain.
+type RPCClient struct {
+	chain  services.OnchainChain
 	rpcURL string
 	client *http.Client
 }
 
-func NewBaseRPCClient(rpcURL string) *BaseRPCClient {
-	trimmed := strings.TrimSpace(rpcURL)
-	if trimmed == "" {
-		trimmed = "https://mainnet.base.org"
-	}
-	return &BaseRPCClient{
-		rpcURL: trimmed,
+// NewRPCClient takes an Alchemy URL for the chain.
+func NewRPCClient(chain services.OnchainChain, rpcURL string) *RPCClient {
+	return &RPCClient{
+		chain:  chain,
+		rpcURL: strings.TrimSpace(rpcURL),
 		client: &http.Client{
 			Timeout: 90 * time.Second,
 		},
 	}
 }
 
+// NewProvidersFromEnv builds a client for every registered chain whose RPC
+// URL variable, such as BASE_RPC_URL, is set. Chains without one are left
+// out and rejected by quick check.
+func NewProvidersFromEnv() map[string]services.OnchainProvider {
+	providers := make(map[string]services.OnchainProvider)
+	for _, chain := range services.OnchainChains() {
+		rpcURL := strings.TrimSpace(os.Getenv(chain.RPCEnv))
+		if rpcURL == "" {
+			log.Printf("[onchain] WARNING: %s not set, %s quick check disabled", chain.RPCEnv, chain.ID)
+			continue
+		}
+		// Log only the host portion for debugging, not the full key
+		parts := strings.SplitN(rpcURL, "/v2/", 2)
+		log.Printf("[onchain] %s configured: %s/v2/***", chain.RPCEnv, parts[0])
+		providers[chain.ID] = NewRPCClient(chain, rpcURL)
+	}
+	return providers
+}
+
 // ListERC20Transfers uses alchemy_getAssetTransfers to fetch ERC20 transfers.
 // This avoids the 10-block eth_getLogs limit on Alchemy Free tier.
-func (c *BaseRPCClient) ListERC20Transfers(ctx context.Context, address string, startTime, endTime time.Time) ([]services.TransferEvent, error) {
+func (c *RPCClient) ListERC20Transfers(ctx context.Context, address string, startTime, endTime time.Time) ([]services.TransferEvent, error) {
 	normalizedAddr := strings.ToLower(strings.TrimSpace(address))
 
 	// Fetch incoming and outgoing transfers
@@ -82,16 +110,16 @@ type alchemyTransferResult struct {
 }
 
 type alchemyTransfer struct {
-	BlockNum        string            `json:"blockNum"`
-	Hash            string            `json:"hash"`
-	From            string            `json:"from"`
-	To              string            `json:"to"`
-	Value           float64           `json:"value"`
-	RawContract     alchemyRawContract `json:"rawContract"`
-	Metadata        alchemyMetadata   `json:"metadata"`
-	Category        string            `json:"category"`
-	Asset           string            `json:"asset"`
-	UniqueID        string            `json:"uniqueId"`
+	BlockNum    string             `json:"blockNum"`
+	Hash        string             `json:"hash"`
+	From        string             `json:"from"`
+	To          string             `json:"to"`
+	Value       float64            `json:"value"`
+	RawContract alchemyRawContract `json:"rawContract"`
+	Metadata    alchemyMetadata    `json:"metadata"`
+	Category    string             `json:"category"`
+	Asset       string             `json:"asset"`
+	UniqueID    string             `json:"uniqueId"`
 }
 
 type alchemyRawContract struct {
@@ -104,7 +132,13 @@ type alchemyMetadata struct {
 	BlockTimestamp string `json:"blockTimestamp"`
 }
 
-func (c *BaseRPCClient) fetchAssetTransfers(ctx context.Context, fromAddr, toAddr string, startTime, endTime time.Time) ([]services.TransferEvent, error) {
+func (c *RPCClient) fetchAssetTransfers(ctx context.Context, fromAddr, toAddr string, startTime, endTime time.Time) ([]services.TransferEvent, error) {
+	// Every page must ask for the same block range as the first.
+	startBlock, endBlock, err := c.estimateBlockRange(ctx, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+
 	var allEvents []services.TransferEvent
 	var pageKey string
 
@@ -114,10 +148,10 @@ func (c *BaseRPCClient) fetchAssetTransfers(ctx context.Context, fromAddr, toAdd
 		}
 
 		params := map[string]interface{}{
-			"category":       []string{"erc20"},
-			"withMetadata":   true,
-			"order":          "asc",
-			"maxCount":       "0x3e8", // 1000 per page
+			"category":     []string{"erc20"},
+			"withMetadata": true,
+			"order":        "asc",
+			"maxCount":     "0x3e8", // 1000 per page
 		}
 		if fromAddr != "" {
 			params["fromAddress"] = fromAddr
@@ -129,13 +163,6 @@ func (c *BaseRPCClient) fetchAssetTransfers(ctx context.Context, fromAddr, toAdd
 			params["pageKey"] = pageKey
 		}
 
-		// Use block hex for fromBlock/toBlock estimation
-		// Alchemy also supports fromBlock/toBlock with hex strings
-		// We estimate start block from time to limit the range
-		startBlock, endBlock, blockErr := c.estimateBlockRange(ctx, startTime, endTime)
-		if blockErr != nil {
-			return nil, blockErr
-		}
 		params["fromBlock"] = toHex(startBlock)
 		params["toBlock"] = toHex(endBlock)
 
@@ -160,7 +187,10 @@ func (c *BaseRPCClient) fetchAssetTransfers(ctx context.Context, fromAddr, toAdd
 	return allEvents, nil
 }
 
-func (c *BaseRPCClient) estimateBlockRange(ctx context.Context, startTime, endTime time.Time) (uint64, uint64, error) {
+// estimateBlockRange turns the time range into blocks ending at the latest
+// block, using the chain's average block time plus slack. Ranges reaching
+// past onchainMaxLookback are cut short with a warning.
+func (c *RPCClient) estimateBlockRange(ctx context.Context, startTime, endTime time.Time) (uint64, uint64, error) {
 	latestBlock, err := c.getLatestBlockNumber(ctx)
 	if err != nil {
 		return 0, 0, err
@@ -171,12 +201,12 @@ func (c *BaseRPCClient) estimateBlockRange(ctx context.Context, startTime, endTi
 		return latestBlock, latestBlock, nil
 	}
 
-	// Base block time ~2s, add buffer
-	estimatedDistance := uint64(window/(2*time.Second)) + 5000
-	const maxLookback uint64 = 120000
-	if estimatedDistance > maxLookback {
-		estimatedDistance = maxLookback
+	lookback := time.Since(startTime) + onchainLookbackSlack
+	if lookback > onchainMaxLookback {
+		log.Printf("[incident:onchain] severity=warning event=provider.range_truncated chain=%s requested=%s max=%s", c.chain.ID, lookback.Round(time.Hour), onchainMaxLookback)
+		lookback = onchainMaxLookback
 	}
+	estimatedDistance := uint64(lookback / c.chain.BlockTime)
 
 	startBlock := uint64(0)
 	if estimatedDistance < latestBlock {
@@ -261,7 +291,7 @@ func (e *rpcCallError) Error() string {
 	return fmt.Sprintf("rpc error code=%d message=%s", e.code, e.message)
 }
 
-func (c *BaseRPCClient) callRPC(ctx context.Context, method string, params interface{}, out interface{}) error {
+func (c *RPCClient) callRPC(ctx context.Context, method string, params interface{}, out interface{}) error {
 	const maxRetries = 3
 	var lastErr error
 
@@ -296,7 +326,7 @@ func isRateLimitError(err error) bool {
 	return strings.Contains(msg, "429") || strings.Contains(msg, "rate") || strings.Contains(msg, "throttl")
 }
 
-func (c *BaseRPCClient) doRPC(ctx context.Context, method string, params interface{}, out interface{}) error {
+func (c *RPCClient) doRPC(ctx context.Context, method string, params interface{}, out interface{}) error {
 	payload, err := json.Marshal(rpcRequest{
 		JSONRPC: "2.0",
 		ID:      1,
@@ -343,7 +373,7 @@ func (c *BaseRPCClient) doRPC(ctx context.Context, method string, params interfa
 	return json.Unmarshal(decoded.Result, out)
 }
 
-func (c *BaseRPCClient) getLatestBlockNumber(ctx context.Context) (uint64, error) {
+func (c *RPCClient) getLatestBlockNumber(ctx context.Context) (uint64, error) {
 	var result string
 	if err := c.callRPC(ctx, "eth_blockNumber", []interface{}{}, &result); err != nil {
 		return 0, err
@@ -0,0 +1,181 @@
+// Package report writes documents for sharing outside the app.
+package report
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A4 in PDF points.
+const (
+	PageWidth  = 595.0
+	PageHeight = 842.0
+)
+
+// Color is an RGB color with components between 0 and 1.
+type Color struct {
+	R, G, B float64
+}
+
+// PDF builds a small PDF 1.4 document with the standard Helvetica fonts.
+// Coordinates start at the top-left corner of the page and grow downwards.
+// Text is encoded as WinAnsi; characters outside it are written as '?'.
+type PDF struct {
+	info  map[string]string
+	pages []*bytes.Buffer
+}
+
+// NewPDF starts a document. info is written to the document information
+// dictionary, e.g. Title or a custom ContentHash key.
+func NewPDF(info map[string]string) *PDF {
+	return &PDF{info: info}
+}
+
+func (p *PDF) AddPage() {
+	p.pages = append(p.pages, &bytes.Buffer{})
+}
+
+func (p *PDF) PageCount() int {
+	return len(p.pages)
+}
+
+func (p *PDF) current() *bytes.Buffer {
+	if len(p.pages) == 0 {
+		p.AddPage()
+	}
+	return p.pages[len(p.pages)-1]
+}
+
+// Text writes a single line with its baseline at y.
+func (p *PDF) Text(x, y, size float64, bold bool, color Color, text string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(p.current(), "BT %s rg /%s %s Tf %s %s Td (%s) Tj ET\n",
+		color.fill(), font, num(size), num(x), num(PageHeight-y), escapeText(text))
+}
+
+// Rect fills a rectangle whose top-left corner is at x, y.
+func (p *PDF) Rect(x, y, w, h float64, color Color) {
+	fmt.Fprintf(p.current(), "%s rg %s %s %s %s re f\n",
+		color.fill(), num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// Line strokes a polyline through points given as x, y pairs.
+func (p *PDF) Line(width float64, color Color, points ...float64) {
+	if len(points) < 4 {
+		return
+	}
+	buf := p.current()
+	fmt.Fprintf(buf, "%s RG %s w %s %s m", color.fill(), num(width), num(points[0]), num(PageHeight-points[1]))
+	for i := 2; i+1 < len(points); i += 2 {
+		fmt.Fprintf(buf, " %s %s l", num(points[i]), num(PageHeight-points[i+1]))
+	}
+	buf.WriteString(" S\n")
+}
+
+// Bytes serializes the document.
+func (p *PDF) Bytes() ([]byte, error) {
+	if len(p.pages) == 0 {
+		p.AddPage()
+	}
+
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 catalog, 2 page tree, 3-4 fonts, 5 info, then a page and its
+	// content stream for every page.
+	const firstPage = 6
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(p.infoDictionary())
+
+	for i, page := range p.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			num(PageWidth), num(PageHeight), firstPage+2*i+1))
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(page.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream",
+			compressed.Len(), compressed.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes(), nil
+}
+
+func (p *PDF) infoDictionary() string {
+	keys := make([]string, 0, len(p.info))
+	for key := range p.info {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("<< /Producer (kifu)")
+	for _, key := range keys {
+		fmt.Fprintf(&b, " /%s (%s)", key, escapeText(p.info[key]))
+	}
+	b.WriteString(" >>")
+	return b.String()
+}
+
+func (c Color) fill() string {
+	return fmt.Sprintf("%s %s %s", num(c.R), num(c.G), num(c.B))
+}
+
+// escapeText encodes s as the body of a PDF literal string.
+func escapeText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+func num(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "" || s == "-0" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,58 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPDFCrossReferenceTable(t *testing.T) {
+	t.Parallel()
+
+	doc := NewPDF(map[string]string{"Title": "Pack (report)", "ContentHash": "abc123"})
+	doc.AddPage()
+	doc.Text(40, 60, 18, true, Color{}, "Summary pack")
+	doc.Rect(40, 80, 100, 20, Color{R: 0.2, G: 0.6, B: 0.3})
+	doc.AddPage()
+	doc.Line(1, Color{}, 40, 100, 80, 120, 120, 90)
+	doc.Text(40, 60, 10, false, Color{}, "한글 café")
+
+	out, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes failed: %v", err)
+	}
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatalf("missing PDF header or trailer")
+	}
+	if !bytes.Contains(out, []byte("/ContentHash (abc123)")) || !bytes.Contains(out, []byte(`/Title (Pack \(report\))`)) {
+		t.Fatalf("info dictionary not written")
+	}
+	if !bytes.Contains(out, []byte("/Count 2")) {
+		t.Fatalf("expected two pages")
+	}
+
+	text := string(out)
+	start := strings.LastIndex(text, "startxref\n")
+	xref, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(text[start+len("startxref\n"):], "%%EOF\n")))
+	if err != nil || !strings.HasPrefix(text[xref:], "xref\n") {
+		t.Fatalf("startxref does not point at the xref table: %v", err)
+	}
+	lines := strings.Split(text[xref:], "\n")
+	count, _ := strconv.Atoi(strings.Fields(lines[1])[1])
+	for i := 1; i < count; i++ {
+		offset, _ := strconv.Atoi(strings.Fields(lines[2+i])[0])
+		if want := fmt.Sprintf("%d 0 obj", i); !strings.HasPrefix(text[offset:], want) {
+			t.Fatalf("xref entry %d points at %q", i, text[offset:offset+10])
+		}
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	t.Parallel()
+
+	if got := escapeText(`a(b)\ é 한`); got != `a\(b\)\\ \351 ?` {
+		t.Fatalf("escapeText = %q", got)
+	}
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -22,33 +23,48 @@ func NewAlertRepository(pool *pgxpool.Pool) repositories.AlertRepository {
 	return &AlertRepositoryImpl{pool: pool}
 }
 
-func (r *AlertRepositoryImpl) Create(ctx context.Context, alert *entities.Alert) error {
+const alertColumns = `id, user_id, rule_id, symbol, trigger_price, trigger_reason, severity, status,
+	notified_at, expires_at, snoozed_until, acknowledged_at, escalated_at, created_at`
+
+const openAlertStatuses = `('pending', 'briefed', 'acknowledged', 'snoozed')`
+
+func (r *AlertRepositoryImpl) Create(ctx context.Context, alert *entities.Alert) (err error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
 	query := `
-		INSERT INTO alerts (id, user_id, rule_id, symbol, trigger_price, trigger_reason, severity, status, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO alerts (id, user_id, rule_id, symbol, trigger_price, trigger_reason, severity, status, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
-	_, err := r.pool.Exec(ctx, query,
+	_, err = tx.Exec(ctx, query,
 		alert.ID, alert.UserID, alert.RuleID, alert.Symbol,
-		alert.TriggerPrice, alert.TriggerReason, alert.Severity, alert.Status, alert.CreatedAt)
-	return err
+		alert.TriggerPrice, alert.TriggerReason, alert.Severity, alert.Status, alert.ExpiresAt, alert.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if err = insertAlertEvent(ctx, tx, alert.ID, alert.UserID, entities.AlertEventCreated, nil, &alert.Status, nil, alert.CreatedAt); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func (r *AlertRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*entities.Alert, error) {
-	query := `
-		SELECT id, user_id, rule_id, symbol, trigger_price, trigger_reason, severity, status, notified_at, created_at
-		FROM alerts WHERE id = $1
-	`
-	var a entities.Alert
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&a.ID, &a.UserID, &a.RuleID, &a.Symbol,
-		&a.TriggerPrice, &a.TriggerReason, &a.Severity, &a.Status, &a.NotifiedAt, &a.CreatedAt)
+	query := `SELECT ` + alertColumns + ` FROM alerts WHERE id = $1`
+	a, err := scanAlert(r.pool.QueryRow(ctx, query, id))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
 		}
 		return nil, err
 	}
-	return &a, nil
+	return a, nil
 }
 
 func (r *AlertRepositoryImpl) ListByUser(ctx context.Context, userID uuid.UUID, status *entities.AlertStatus, limit, offset int) ([]*entities.Alert, int, error) {
@@ -64,10 +80,7 @@ func (r *AlertRepositoryImpl) ListByUser(ctx context.Context, userID uuid.UUID,
 		return nil, 0, err
 	}
 
-	query := `
-		SELECT id, user_id, rule_id, symbol, trigger_price, trigger_reason, severity, status, notified_at, created_at
-		FROM alerts WHERE user_id = $1
-	`
+	query := `SELECT ` + alertColumns + ` FROM alerts WHERE user_id = $1`
 	queryArgs := []interface{}{userID}
 	paramIdx := 2
 	if status != nil {
@@ -84,38 +97,264 @@ func (r *AlertRepositoryImpl) ListByUser(ctx context.Context, userID uuid.UUID,
 	}
 	defer rows.Close()
 
-	var alerts []*entities.Alert
-	for rows.Next() {
-		var a entities.Alert
-		if err := rows.Scan(
-			&a.ID, &a.UserID, &a.RuleID, &a.Symbol,
-			&a.TriggerPrice, &a.TriggerReason, &a.Severity, &a.Status, &a.NotifiedAt, &a.CreatedAt); err != nil {
-			return nil, 0, err
-		}
-		alerts = append(alerts, &a)
+	alerts, err := scanAlerts(rows)
+	if err != nil {
+		return nil, 0, err
 	}
-	return alerts, total, rows.Err()
+	return alerts, total, nil
 }
 
 func (r *AlertRepositoryImpl) UpdateStatus(ctx context.Context, id uuid.UUID, status entities.AlertStatus) error {
-	_, err := r.pool.Exec(ctx, `UPDATE alerts SET status = $1 WHERE id = $2`, status, id)
+	_, err := r.transition(ctx, id, entities.AlertStatus.CanTransitionTo, status, entities.AlertEventType(status), nil, "")
 	return err
 }
 
 func (r *AlertRepositoryImpl) SetNotified(ctx context.Context, id uuid.UUID) error {
-	_, err := r.pool.Exec(ctx, `UPDATE alerts SET notified_at = $1 WHERE id = $2`, time.Now().UTC(), id)
+	now := time.Now().UTC()
+	query := `
+		WITH updated AS (
+			UPDATE alerts SET notified_at = $1 WHERE id = $2
+			RETURNING id, user_id, status
+		)
+		INSERT INTO alert_events (alert_id, user_id, event_type, from_status, to_status, created_at)
+		SELECT id, user_id, $3, status, status, $1 FROM updated
+	`
+	_, err := r.pool.Exec(ctx, query, now, id, entities.AlertEventNotified)
 	return err
 }
 
-func (r *AlertRepositoryImpl) ExpireOlderThan(ctx context.Context, before time.Time) (int, error) {
-	result, err := r.pool.Exec(ctx,
-		`UPDATE alerts SET status = 'expired' WHERE status IN ('pending', 'briefed') AND created_at < $1`, before)
+func (r *AlertRepositoryImpl) Snooze(ctx context.Context, id uuid.UUID, until, expiresAt time.Time) (bool, error) {
+	detail, _ := json.Marshal(map[string]time.Time{"until": until})
+	return r.transition(ctx, id, alertIsOpen, entities.AlertStatusSnoozed, entities.AlertEventSnoozed, detail,
+		`, snoozed_until = $3, expires_at = $4`, until, expiresAt)
+}
+
+func (r *AlertRepositoryImpl) Acknowledge(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	return r.transition(ctx, id, alertIsOpen, entities.AlertStatusAcknowledged, entities.AlertEventAcknowledged, nil,
+		`, acknowledged_at = COALESCE(acknowledged_at, $3), snoozed_until = NULL`, at)
+}
+
+func (r *AlertRepositoryImpl) Dismiss(ctx context.Context, id uuid.UUID) (bool, error) {
+	return r.transition(ctx, id, alertIsOpen, entities.AlertStatusExpired, entities.AlertEventDismissed, nil,
+		`, snoozed_until = NULL`)
+}
+
+// alertIsOpen allows the lifecycle actions on any alert still awaiting the user.
+func alertIsOpen(from, _ entities.AlertStatus) bool {
+	return from.IsOpen()
+}
+
+// transition moves one alert to status `to` and records eventType in its
+// history. Alerts whose current status fails allow are left alone and false
+// is returned. extraSet is appended to the UPDATE's SET clause; its
+// placeholders start at $3.
+func (r *AlertRepositoryImpl) transition(
+	ctx context.Context,
+	id uuid.UUID,
+	allow func(from, to entities.AlertStatus) bool,
+	to entities.AlertStatus,
+	eventType entities.AlertEventType,
+	detail json.RawMessage,
+	extraSet string,
+	extraArgs ...interface{},
+) (changed bool, err error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil || !changed {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	var userID uuid.UUID
+	var from entities.AlertStatus
+	err = tx.QueryRow(ctx, `SELECT user_id, status FROM alerts WHERE id = $1 FOR UPDATE`, id).Scan(&userID, &from)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	if !allow(from, to) {
+		return false, nil
+	}
+
+	args := append([]interface{}{id, to}, extraArgs...)
+	if _, err = tx.Exec(ctx, `UPDATE alerts SET status = $2`+extraSet+` WHERE id = $1`, args...); err != nil {
+		return false, err
+	}
+	if err = insertAlertEvent(ctx, tx, id, userID, eventType, &from, &to, detail, time.Now().UTC()); err != nil {
+		return false, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *AlertRepositoryImpl) ClaimDueSnoozed(ctx context.Context, now time.Time, limit int) ([]*entities.Alert, error) {
+	query := `
+		WITH due AS (
+			UPDATE alerts
+			SET status = 'briefed', snoozed_until = NULL, notified_at = $1
+			WHERE id IN (
+				SELECT id FROM alerts
+				WHERE status = 'snoozed' AND snoozed_until <= $1
+				ORDER BY snoozed_until
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING ` + alertColumns + `
+		), events AS (
+			INSERT INTO alert_events (alert_id, user_id, event_type, from_status, to_status, created_at)
+			SELECT id, user_id, $3, 'snoozed', 'briefed', $1 FROM due
+		)
+		SELECT ` + alertColumns + ` FROM due
+	`
+	rows, err := r.pool.Query(ctx, query, now, limit, entities.AlertEventRenotified)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanAlerts(rows)
+}
+
+func (r *AlertRepositoryImpl) ClaimEscalations(ctx context.Context, now time.Time, limit int) ([]*entities.Alert, error) {
+	query := `
+		UPDATE alerts
+		SET escalated_at = $1
+		WHERE id IN (
+			SELECT a.id FROM alerts a
+			JOIN alert_rules ar ON ar.id = a.rule_id
+			WHERE a.severity = 'urgent'
+				AND a.status IN ('pending', 'briefed')
+				AND a.acknowledged_at IS NULL
+				AND a.escalated_at IS NULL
+				AND a.notified_at IS NOT NULL
+				AND ar.escalate_after_minutes > 0
+				AND a.notified_at + make_interval(mins => ar.escalate_after_minutes) <= $1
+			ORDER BY a.notified_at
+			LIMIT $2
+			FOR UPDATE OF a SKIP LOCKED
+		)
+		RETURNING ` + alertColumns + `
+	`
+	rows, err := r.pool.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanAlerts(rows)
+}
+
+func (r *AlertRepositoryImpl) ExpireDue(ctx context.Context, now time.Time) (int, error) {
+	query := `
+		WITH expired AS (
+			UPDATE alerts a
+			SET status = 'expired', snoozed_until = NULL
+			FROM (
+				SELECT id, status FROM alerts
+				WHERE status IN ` + openAlertStatuses + ` AND expires_at <= $1
+				FOR UPDATE SKIP LOCKED
+			) prev
+			WHERE a.id = prev.id
+			RETURNING a.id, a.user_id, prev.status AS from_status
+		)
+		INSERT INTO alert_events (alert_id, user_id, event_type, from_status, to_status, created_at)
+		SELECT id, user_id, $2, from_status, 'expired', $1 FROM expired
+	`
+	result, err := r.pool.Exec(ctx, query, now, entities.AlertEventExpired)
 	if err != nil {
 		return 0, err
 	}
 	return int(result.RowsAffected()), nil
 }
 
+func insertAlertEvent(
+	ctx context.Context,
+	tx pgx.Tx,
+	alertID, userID uuid.UUID,
+	eventType entities.AlertEventType,
+	from, to *entities.AlertStatus,
+	detail json.RawMessage,
+	at time.Time,
+) error {
+	query := `
+		INSERT INTO alert_events (id, alert_id, user_id, event_type, from_status, to_status, detail, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := tx.Exec(ctx, query, uuid.New(), alertID, userID, eventType, from, to, detail, at)
+	return err
+}
+
+func scanAlert(row pgx.Row) (*entities.Alert, error) {
+	var a entities.Alert
+	err := row.Scan(
+		&a.ID, &a.UserID, &a.RuleID, &a.Symbol,
+		&a.TriggerPrice, &a.TriggerReason, &a.Severity, &a.Status,
+		&a.NotifiedAt, &a.ExpiresAt, &a.SnoozedUntil, &a.AcknowledgedAt, &a.EscalatedAt, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func scanAlerts(rows pgx.Rows) ([]*entities.Alert, error) {
+	var alerts []*entities.Alert
+	for rows.Next() {
+		a, err := scanAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
+
+// --- AlertEvent ---
+
+type AlertEventRepositoryImpl struct {
+	pool *pgxpool.Pool
+}
+
+func NewAlertEventRepository(pool *pgxpool.Pool) repositories.AlertEventRepository {
+	return &AlertEventRepositoryImpl{pool: pool}
+}
+
+func (r *AlertEventRepositoryImpl) Create(ctx context.Context, e *entities.AlertEvent) error {
+	query := `
+		INSERT INTO alert_events (id, alert_id, user_id, event_type, from_status, to_status, detail, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := r.pool.Exec(ctx, query,
+		e.ID, e.AlertID, e.UserID, e.EventType, e.FromStatus, e.ToStatus, e.Detail, e.CreatedAt)
+	return err
+}
+
+func (r *AlertEventRepositoryImpl) ListByAlert(ctx context.Context, alertID uuid.UUID) ([]*entities.AlertEvent, error) {
+	query := `
+		SELECT id, alert_id, user_id, event_type, from_status, to_status, detail, created_at
+		FROM alert_events WHERE alert_id = $1 ORDER BY created_at, id
+	`
+	rows, err := r.pool.Query(ctx, query, alertID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*entities.AlertEvent
+	for rows.Next() {
+		var e entities.AlertEvent
+		if err := rows.Scan(&e.ID, &e.AlertID, &e.UserID, &e.EventType, &e.FromStatus, &e.ToStatus, &e.Detail, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, &e)
+	}
+	return events, rows.Err()
+}
+
 // --- AlertBriefing ---
 
 type AlertBriefingRepositoryImpl struct {
@@ -195,6 +434,16 @@ func (r *AlertDecisionRepositoryImpl) GetByAlert(ctx context.Context, alertID uu
 	return &d, nil
 }
 
+func (r *AlertDecisionRepositoryImpl) UpdateNote(ctx context.Context, id uuid.UUID, memo *string, confidence *entities.Confidence) error {
+	query := `
+		UPDATE alert_decisions
+		SET memo = COALESCE($2, memo), confidence = COALESCE($3, confidence)
+		WHERE id = $1
+	`
+	_, err := r.pool.Exec(ctx, query, id, memo, confidence)
+	return err
+}
+
 // --- AlertOutcome ---
 
 type AlertOutcomeRepositoryImpl struct {
@@ -268,6 +517,38 @@ func (r *AlertOutcomeRepositoryImpl) ListPendingDecisions(ctx context.Context, p
 	return pending, rows.Err()
 }
 
+func (r *AlertOutcomeRepositoryImpl) ListDecisionOutcomes(ctx context.Context, userID uuid.UUID, period string, from, to *time.Time) ([]*repositories.AlertDecisionOutcome, error) {
+	query := `
+		SELECT a.id, d.action, d.confidence, ar.rule_type, a.severity, o.pnl_percent::text,
+			(SELECT b.response FROM alert_briefings b WHERE b.alert_id = a.id ORDER BY b.created_at LIMIT 1),
+			d.created_at
+		FROM alert_decisions d
+		JOIN alerts a ON a.id = d.alert_id
+		JOIN alert_rules ar ON ar.id = a.rule_id
+		JOIN alert_outcomes o ON o.alert_id = a.id AND o.period = $2
+		WHERE d.user_id = $1
+			AND ($3::timestamptz IS NULL OR d.created_at >= $3)
+			AND ($4::timestamptz IS NULL OR d.created_at <= $4)
+		ORDER BY d.created_at
+	`
+	rows, err := r.pool.Query(ctx, query, userID, period, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*repositories.AlertDecisionOutcome
+	for rows.Next() {
+		var item repositories.AlertDecisionOutcome
+		if err := rows.Scan(&item.AlertID, &item.Action, &item.Confidence, &item.RuleType, &item.Severity,
+			&item.PnLPercent, &item.Briefing, &item.DecisionTime); err != nil {
+			return nil, err
+		}
+		items = append(items, &item)
+	}
+	return items, rows.Err()
+}
+
 func itoa(n int) string {
 	return fmt.Sprintf("%d", n)
 }
@@ -21,8 +21,9 @@ func NewAlertRuleRepository(pool *pgxpool.Pool) repositories.AlertRuleRepository
 
 func (r *AlertRuleRepositoryImpl) Create(ctx context.Context, rule *entities.AlertRule) error {
 	query := `
-		INSERT INTO alert_rules (id, user_id, name, symbol, rule_type, config, cooldown_minutes, enabled, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO alert_rules (id, user_id, name, symbol, rule_type, config, cooldown_minutes,
+		                         expiry_minutes, escalate_after_minutes, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
 	rule.ID = uuid.New()
 	now := time.Now().UTC()
@@ -31,20 +32,23 @@ func (r *AlertRuleRepositoryImpl) Create(ctx context.Context, rule *entities.Ale
 
 	_, err := r.pool.Exec(ctx, query,
 		rule.ID, rule.UserID, rule.Name, rule.Symbol, rule.RuleType,
-		rule.Config, rule.CooldownMinutes, rule.Enabled, rule.CreatedAt, rule.UpdatedAt)
+		rule.Config, rule.CooldownMinutes, rule.ExpiryMinutes, rule.EscalateAfterMinutes,
+		rule.Enabled, rule.CreatedAt, rule.UpdatedAt)
 	return err
 }
 
 func (r *AlertRuleRepositoryImpl) Update(ctx context.Context, rule *entities.AlertRule) error {
 	query := `
 		UPDATE alert_rules
-		SET name = $1, symbol = $2, rule_type = $3, config = $4, cooldown_minutes = $5, enabled = $6, updated_at = $7
-		WHERE id = $8 AND user_id = $9
+		SET name = $1, symbol = $2, rule_type = $3, config = $4, cooldown_minutes = $5,
+		    expiry_minutes = $6, escalate_after_minutes = $7, enabled = $8, updated_at = $9
+		WHERE id = $10 AND user_id = $11
 	`
 	rule.UpdatedAt = time.Now().UTC()
 	_, err := r.pool.Exec(ctx, query,
 		rule.Name, rule.Symbol, rule.RuleType, rule.Config,
-		rule.CooldownMinutes, rule.Enabled, rule.UpdatedAt, rule.ID, rule.UserID)
+		rule.CooldownMinutes, rule.ExpiryMinutes, rule.EscalateAfterMinutes,
+		rule.Enabled, rule.UpdatedAt, rule.ID, rule.UserID)
 	return err
 }
 
@@ -55,7 +59,8 @@ func (r *AlertRuleRepositoryImpl) Delete(ctx context.Context, id, userID uuid.UU
 
 func (r *AlertRuleRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*entities.AlertRule, error) {
 	query := `
-		SELECT id, user_id, name, symbol, rule_type, config, cooldown_minutes, enabled,
+		SELECT id, user_id, name, symbol, rule_type, config, cooldown_minutes,
+		       expiry_minutes, escalate_after_minutes, enabled,
 		       last_triggered_at, last_check_state, created_at, updated_at
 		FROM alert_rules WHERE id = $1
 	`
@@ -64,7 +69,8 @@ func (r *AlertRuleRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*e
 
 func (r *AlertRuleRepositoryImpl) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.AlertRule, error) {
 	query := `
-		SELECT id, user_id, name, symbol, rule_type, config, cooldown_minutes, enabled,
+		SELECT id, user_id, name, symbol, rule_type, config, cooldown_minutes,
+		       expiry_minutes, escalate_after_minutes, enabled,
 		       last_triggered_at, last_check_state, created_at, updated_at
 		FROM alert_rules WHERE user_id = $1 ORDER BY created_at DESC
 	`
@@ -73,7 +79,8 @@ func (r *AlertRuleRepositoryImpl) ListByUser(ctx context.Context, userID uuid.UU
 
 func (r *AlertRuleRepositoryImpl) ListActiveBySymbol(ctx context.Context, symbol string) ([]*entities.AlertRule, error) {
 	query := `
-		SELECT id, user_id, name, symbol, rule_type, config, cooldown_minutes, enabled,
+		SELECT id, user_id, name, symbol, rule_type, config, cooldown_minutes,
+		       expiry_minutes, escalate_after_minutes, enabled,
 		       last_triggered_at, last_check_state, created_at, updated_at
 		FROM alert_rules WHERE symbol = $1 AND enabled = true
 	`
@@ -82,9 +89,10 @@ func (r *AlertRuleRepositoryImpl) ListActiveBySymbol(ctx context.Context, symbol
 
 func (r *AlertRuleRepositoryImpl) ListAllActive(ctx context.Context) ([]*entities.AlertRule, error) {
 	query := `
-		SELECT id, user_id, name, symbol, rule_type, config, cooldown_minutes, enabled,
+		SELECT id, user_id, name, symbol, rule_type, config, cooldown_minutes,
+		       expiry_minutes, escalate_after_minutes, enabled,
 		       last_triggered_at, last_check_state, created_at, updated_at
-		FROM alert_rules WHERE enabled = true
+		FROM alert_rules WHERE enabled = true AND rule_type <> 'risk_limit'
 	`
 	rows, err := r.pool.Query(ctx, query)
 	if err != nil {
@@ -146,7 +154,7 @@ func (r *AlertRuleRepositoryImpl) scanRuleFromRows(rows pgx.Rows) (*entities.Ale
 	var rule entities.AlertRule
 	err := rows.Scan(
 		&rule.ID, &rule.UserID, &rule.Name, &rule.Symbol, &rule.RuleType,
-		&rule.Config, &rule.CooldownMinutes, &rule.Enabled,
+		&rule.Config, &rule.CooldownMinutes, &rule.ExpiryMinutes, &rule.EscalateAfterMinutes, &rule.Enabled,
 		&rule.LastTriggeredAt, &rule.LastCheckState, &rule.CreatedAt, &rule.UpdatedAt)
 	if err != nil {
 		return nil, err
@@ -158,7 +166,7 @@ func (r *AlertRuleRepositoryImpl) scanRule(row pgx.Row) (*entities.AlertRule, er
 	var rule entities.AlertRule
 	err := row.Scan(
 		&rule.ID, &rule.UserID, &rule.Name, &rule.Symbol, &rule.RuleType,
-		&rule.Config, &rule.CooldownMinutes, &rule.Enabled,
+		&rule.Config, &rule.CooldownMinutes, &rule.ExpiryMinutes, &rule.EscalateAfterMinutes, &rule.Enabled,
 		&rule.LastTriggeredAt, &rule.LastCheckState, &rule.CreatedAt, &rule.UpdatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -0,0 +1,189 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+type BehaviorRepositoryImpl struct {
+	pool *pgxpool.Pool
+}
+
+func NewBehaviorRepository(pool *pgxpool.Pool) repositories.BehaviorRepository {
+	return &BehaviorRepositoryImpl{pool: pool}
+}
+
+func (r *BehaviorRepositoryImpl) ListActiveUsers(ctx context.Context, since time.Time, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT active.user_id FROM (
+			SELECT DISTINCT user_id FROM trades WHERE trade_time >= $1
+			UNION
+			SELECT DISTINCT user_id FROM trade_events WHERE executed_at >= $1
+		) active
+		LEFT JOIN behavior_scans s ON s.user_id = active.user_id
+		ORDER BY s.scanned_at ASC NULLS FIRST
+		LIMIT $2
+	`
+	rows, err := r.pool.Query(ctx, query, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
+}
+
+// ListFills reads synced trades and imported trade events across all symbols.
+// Events mirrored from trades carry the trade id in metadata and are skipped.
+func (r *BehaviorRepositoryImpl) ListFills(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*repositories.BehaviorFill, error) {
+	query := `
+		SELECT 'trade' AS source, t.id, upper(regexp_replace(t.symbol, '[^A-Za-z0-9]', '', 'g')), lower(t.side),
+			t.quantity::text, t.price::text,
+			CASE WHEN t.realized_pnl::text ~ '^-?[0-9]+(\.[0-9]+)?$' THEN t.realized_pnl::text END,
+			t.trade_time
+		FROM trades t
+		WHERE t.user_id = $1 AND t.trade_time BETWEEN $2 AND $3
+		UNION ALL
+		SELECT 'trade_event', e.id, upper(i.base_asset || i.quote_asset), e.side, e.qty::text, e.price::text, NULL, e.executed_at
+		FROM trade_events e
+		JOIN instruments i ON i.id = e.instrument_id
+		WHERE e.user_id = $1
+			AND e.executed_at BETWEEN $2 AND $3
+			AND e.event_type IN ('spot_trade', 'perp_trade', 'dex_swap')
+			AND e.side IS NOT NULL AND e.qty IS NOT NULL AND e.price IS NOT NULL
+			AND NOT (COALESCE(e.metadata, '{}'::jsonb) ? 'trade_id')
+		ORDER BY 8, 2
+	`
+	rows, err := r.pool.Query(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fills []*repositories.BehaviorFill
+	for rows.Next() {
+		var fill repositories.BehaviorFill
+		if err := rows.Scan(&fill.Source, &fill.ID, &fill.SymbolKey, &fill.Side, &fill.Qty, &fill.Price, &fill.RealizedPnL, &fill.ExecutedAt); err != nil {
+			return nil, err
+		}
+		fills = append(fills, &fill)
+	}
+	return fills, rows.Err()
+}
+
+// ReplaceEpisodes deletes first so episodes that no longer match after a
+// re-detection, e.g. once late fills arrive, don't linger.
+func (r *BehaviorRepositoryImpl) ReplaceEpisodes(ctx context.Context, userID uuid.UUID, from time.Time, episodes []*entities.BehaviorEpisode, scannedAt time.Time) error {
+	query := `
+		INSERT INTO behavior_episodes (
+			id, user_id, pattern, symbol, severity, started_at, ended_at, trade_date, trade_count, details, detected_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (user_id, pattern, symbol, started_at) DO UPDATE SET
+			severity = EXCLUDED.severity,
+			ended_at = EXCLUDED.ended_at,
+			trade_date = EXCLUDED.trade_date,
+			trade_count = EXCLUDED.trade_count,
+			details = EXCLUDED.details,
+			detected_at = EXCLUDED.detected_at
+	`
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM behavior_episodes WHERE user_id = $1 AND started_at >= $2`, userID, from); err != nil {
+		return err
+	}
+	for _, e := range episodes {
+		if _, err := tx.Exec(ctx, query,
+			e.ID, e.UserID, e.Pattern, e.Symbol, e.Severity, e.StartedAt, e.EndedAt, e.TradeDate,
+			e.TradeCount, e.Details, e.DetectedAt); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO behavior_scans (user_id, scanned_at) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET scanned_at = EXCLUDED.scanned_at
+	`, userID, scannedAt); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *BehaviorRepositoryImpl) ListEpisodes(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*entities.BehaviorEpisode, error) {
+	query := `
+		SELECT id, user_id, pattern, symbol, severity, started_at, ended_at, trade_date::text,
+			trade_count, details, detected_at
+		FROM behavior_episodes
+		WHERE user_id = $1 AND started_at BETWEEN $2 AND $3
+		ORDER BY started_at DESC
+	`
+	rows, err := r.pool.Query(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var episodes []*entities.BehaviorEpisode
+	for rows.Next() {
+		var e entities.BehaviorEpisode
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Pattern, &e.Symbol, &e.Severity, &e.StartedAt, &e.EndedAt,
+			&e.TradeDate, &e.TradeCount, &e.Details, &e.DetectedAt); err != nil {
+			return nil, err
+		}
+		episodes = append(episodes, &e)
+	}
+	return episodes, rows.Err()
+}
+
+// ListReviewSignals returns answered review items in the range with the
+// patterns detected on their symbol or for the whole review day.
+func (r *BehaviorRepositoryImpl) ListReviewSignals(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*repositories.BehaviorReviewSignal, error) {
+	query := `
+		SELECT gri.id, gr.review_date::text, gri.symbol, gri.emotions,
+			ARRAY(
+				SELECT DISTINCT be.pattern
+				FROM behavior_episodes be
+				WHERE be.user_id = gr.user_id
+					AND be.trade_date = gr.review_date
+					AND be.symbol IN ('', upper(regexp_replace(gri.symbol, '[^A-Za-z0-9]', '', 'g')))
+				ORDER BY be.pattern
+			)
+		FROM guided_review_items gri
+		JOIN guided_reviews gr ON gr.id = gri.review_id
+		WHERE gr.user_id = $1
+			AND gr.cadence = 'daily'
+			AND gr.review_date BETWEEN $2::date AND $3::date
+			AND gri.intent IS NOT NULL
+		ORDER BY gr.review_date DESC, gri.order_index ASC
+	`
+	rows, err := r.pool.Query(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var signals []*repositories.BehaviorReviewSignal
+	for rows.Next() {
+		var signal repositories.BehaviorReviewSignal
+		if err := rows.Scan(&signal.ItemID, &signal.ReviewDate, &signal.Symbol, &signal.Emotions, &signal.Patterns); err != nil {
+			return nil, err
+		}
+		signals = append(signals, &signal)
+	}
+	return signals, rows.Err()
+}
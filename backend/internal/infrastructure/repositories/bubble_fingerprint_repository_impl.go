@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+type BubbleFingerprintRepositoryImpl struct {
+	pool *pgxpool.Pool
+}
+
+func NewBubbleFingerprintRepository(pool *pgxpool.Pool) repositories.BubbleFingerprintRepository {
+	return &BubbleFingerprintRepositoryImpl{pool: pool}
+}
+
+func (r *BubbleFingerprintRepositoryImpl) Upsert(ctx context.Context, fp *entities.BubbleFingerprint) error {
+	query := `
+		INSERT INTO bubble_fingerprints (bubble_id, user_id, version, status, candle_interval, window_end, vector, attempts, retry_after, computed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (bubble_id) DO UPDATE SET
+			version = EXCLUDED.version,
+			status = EXCLUDED.status,
+			candle_interval = EXCLUDED.candle_interval,
+			window_end = EXCLUDED.window_end,
+			vector = EXCLUDED.vector,
+			attempts = EXCLUDED.attempts,
+			retry_after = EXCLUDED.retry_after,
+			computed_at = EXCLUDED.computed_at
+	`
+	_, err := r.pool.Exec(ctx, query,
+		fp.BubbleID, fp.UserID, fp.Version, fp.Status, fp.CandleInterval, fp.WindowEnd, fp.Vector,
+		fp.Attempts, fp.RetryAfter, fp.ComputedAt)
+	return err
+}
+
+func (r *BubbleFingerprintRepositoryImpl) GetByBubble(ctx context.Context, bubbleID uuid.UUID) (*entities.BubbleFingerprint, error) {
+	query := `
+		SELECT bubble_id, user_id, version, status, candle_interval, window_end, vector, attempts, retry_after, computed_at
+		FROM bubble_fingerprints WHERE bubble_id = $1
+	`
+	var fp entities.BubbleFingerprint
+	err := r.pool.QueryRow(ctx, query, bubbleID).Scan(
+		&fp.BubbleID, &fp.UserID, &fp.Version, &fp.Status, &fp.CandleInterval, &fp.WindowEnd, &fp.Vector,
+		&fp.Attempts, &fp.RetryAfter, &fp.ComputedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &fp, nil
+}
+
+func (r *BubbleFingerprintRepositoryImpl) ListMissing(ctx context.Context, version string, now time.Time, limit int) ([]*entities.Bubble, error) {
+	query := `
+		SELECT b.id, b.user_id, b.symbol, b.timeframe, b.candle_time, b.price, b.bubble_type, b.asset_class, b.venue_name, b.memo, b.tags, b.created_at
+		FROM bubbles b
+		LEFT JOIN bubble_fingerprints f ON f.bubble_id = b.id AND f.version = $1
+		WHERE f.bubble_id IS NULL
+		   OR (f.status = 'retry' AND f.retry_after <= $2)
+		ORDER BY f.bubble_id IS NOT NULL, b.created_at DESC
+		LIMIT $3
+	`
+	rows, err := r.pool.Query(ctx, query, version, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bubbles []*entities.Bubble
+	for rows.Next() {
+		var b entities.Bubble
+		if err := rows.Scan(&b.ID, &b.UserID, &b.Symbol, &b.Timeframe, &b.CandleTime, &b.Price, &b.BubbleType,
+			&b.AssetClass, &b.VenueName, &b.Memo, &b.Tags, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		bubbles = append(bubbles, &b)
+	}
+	return bubbles, rows.Err()
+}
+
+func (r *BubbleFingerprintRepositoryImpl) ListCandidates(ctx context.Context, userID uuid.UUID, version string, before time.Time, period string) ([]*repositories.FingerprintCandidate, error) {
+	query := `
+		SELECT b.id, b.user_id, b.symbol, b.timeframe, b.candle_time, b.price, b.bubble_type, b.asset_class, b.venue_name, b.memo, b.tags, b.created_at,
+		       f.vector,
+		       o.period, o.reference_price, o.outcome_price, o.pnl_percent, o.calculated_at
+		FROM bubble_fingerprints f
+		JOIN bubbles b ON b.id = f.bubble_id
+		LEFT JOIN outcomes o ON o.bubble_id = b.id AND o.period = $4
+		WHERE f.user_id = $1 AND f.version = $2 AND f.status = 'ready' AND b.candle_time < $3
+	`
+	rows, err := r.pool.Query(ctx, query, userID, version, before, period)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []*repositories.FingerprintCandidate
+	for rows.Next() {
+		var b entities.Bubble
+		var vector []float64
+		var outcomePeriod, referencePrice, outcomePrice, pnlPercent *string
+		var calculatedAt *time.Time
+		if err := rows.Scan(&b.ID, &b.UserID, &b.Symbol, &b.Timeframe, &b.CandleTime, &b.Price, &b.BubbleType,
+			&b.AssetClass, &b.VenueName, &b.Memo, &b.Tags, &b.CreatedAt,
+			&vector,
+			&outcomePeriod, &referencePrice, &outcomePrice, &pnlPercent, &calculatedAt); err != nil {
+			return nil, err
+		}
+
+		candidate := &repositories.FingerprintCandidate{Bubble: &b, Vector: vector}
+		if outcomePeriod != nil {
+			candidate.Outcome = &entities.Outcome{
+				BubbleID:       b.ID,
+				Period:         *outcomePeriod,
+				ReferencePrice: safeString(referencePrice),
+				OutcomePrice:   safeString(outcomePrice),
+				PnLPercent:     safeString(pnlPercent),
+				CalculatedAt:   safeTime(calculatedAt),
+			}
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, rows.Err()
+}
@@ -358,7 +358,7 @@ func safeTime(value *time.Time) time.Time {
 	return *value
 }
 
-func (r *BubbleRepositoryImpl) GetReviewStats(ctx context.Context, userID uuid.UUID, period string, symbol string, tag string, assetClass string, venueName string) (*repositories.ReviewStats, error) {
+func (r *BubbleRepositoryImpl) GetReviewStats(ctx context.Context, userID uuid.UUID, period string, symbol string, tag string, assetClass string, venueName string, setupID string) (*repositories.ReviewStats, error) {
 	// Calculate date range
 	var since time.Time
 	switch period {
@@ -400,6 +400,11 @@ func (r *BubbleRepositoryImpl) GetReviewStats(ctx context.Context, userID uuid.U
 		args = append(args, venueName)
 		argIndex++
 	}
+	if setupID != "" {
+		conditions = append(conditions, fmt.Sprintf("b.setup_id = $%d::uuid", argIndex))
+		args = append(args, setupID)
+		argIndex++
+	}
 
 	whereClause := strings.Join(conditions, " AND ")
 
@@ -564,6 +569,57 @@ func (r *BubbleRepositoryImpl) GetReviewStats(ctx context.Context, userID uuid.U
 		}
 	}
 
+	// Stats by playbook setup
+	setupQuery := fmt.Sprintf(`
+		SELECT
+			s.id::text,
+			s.name,
+			COALESCE(SUM(CASE WHEN m.move > 0 THEN 1 ELSE 0 END), 0) as wins,
+			COALESCE(SUM(CASE WHEN m.move <= 0 THEN 1 ELSE 0 END), 0) as losses,
+			COALESCE(AVG(m.move), 0) as avg_pnl,
+			COUNT(*) as count
+		FROM bubbles b
+		JOIN playbook_setups s ON s.id = b.setup_id
+		JOIN outcomes o ON o.bubble_id = b.id AND o.period = '1h'
+		-- Short setups profit from a falling price, as in the playbook stats.
+		CROSS JOIN LATERAL (
+			SELECT CAST(o.pnl_percent AS DECIMAL) * CASE WHEN s.direction = 'short' THEN -1 ELSE 1 END AS move
+		) m
+		WHERE %s
+		GROUP BY s.id, s.name
+	`, whereClause)
+
+	setupRows, err := r.pool.Query(ctx, setupQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer setupRows.Close()
+
+	bySetup := make(map[string]repositories.SetupReviewStats)
+	for setupRows.Next() {
+		var id, name string
+		var stWins, stLosses, stCount int
+		var stAvgPnL float64
+		if err := setupRows.Scan(&id, &name, &stWins, &stLosses, &stAvgPnL, &stCount); err != nil {
+			return nil, err
+		}
+		stWinRate := 0.0
+		if stWins+stLosses > 0 {
+			stWinRate = float64(stWins) / float64(stWins+stLosses) * 100
+		}
+		bySetup[name] = repositories.SetupReviewStats{
+			SetupID: id,
+			Count:   stCount,
+			WinRate: stWinRate,
+			AvgPnL:  fmt.Sprintf("%.4f", stAvgPnL),
+		}
+	}
+
+	excursions, err := r.excursionStats(ctx, userID, since, symbol, tag, assetClass, venueName, setupID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &repositories.ReviewStats{
 		Period:             period,
 		TotalBubbles:       totalBubbles,
@@ -575,16 +631,117 @@ func (r *BubbleRepositoryImpl) GetReviewStats(ctx context.Context, userID uuid.U
 			MaxGain:  fmt.Sprintf("%.4f", maxGain),
 			MaxLoss:  fmt.Sprintf("%.4f", maxLoss),
 		},
-		ByPeriod: byPeriod,
-		ByTag:    byTag,
-		BySymbol: bySymbol,
+		ByPeriod:   byPeriod,
+		ByTag:      byTag,
+		BySymbol:   bySymbol,
+		BySetup:    bySetup,
+		Excursions: excursions,
 	}, nil
 }
 
-func (r *BubbleRepositoryImpl) GetCalendarData(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time, assetClass string, venueName string) (map[string]repositories.CalendarDay, error) {
+// excursionStats applies the review filters to closed manual positions and
+// synced round trips. Positions carry no tags, so a tag filter leaves nothing
+// to summarize. Round trips are all crypto and have no venue label or setup,
+// so those filters leave only manual positions. A round trip whose fills a
+// plan already claimed is counted once, through the plan's position.
+func (r *BubbleRepositoryImpl) excursionStats(ctx context.Context, userID uuid.UUID, since time.Time, symbol string, tag string, assetClass string, venueName string, setupID string) (repositories.ExcursionStats, error) {
+	stats := repositories.ExcursionStats{
+		AvgMAE:           "0.0000",
+		AvgMFE:           "0.0000",
+		AvgRMultiple:     "0.0000",
+		AvgWinnerMAER:    "0.0000",
+		AvgLoserMFER:     "0.0000",
+		AvgWinnerCapture: "0.0000",
+	}
+	if tag != "" {
+		return stats, nil
+	}
+
+	conditions := []string{"x.user_id = $1", "x.status = 'ready'"}
+	tripConditions := []string{"rt.user_id = $1", "rt.status = 'ready'"}
+	args := []interface{}{userID}
+	argIndex := 2
+
+	if !since.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("mp.closed_at >= $%d", argIndex))
+		tripConditions = append(tripConditions, fmt.Sprintf("rt.closed_at >= $%d", argIndex))
+		args = append(args, since)
+		argIndex++
+	}
+	if symbol != "" {
+		conditions = append(conditions, fmt.Sprintf("mp.symbol = $%d", argIndex))
+		tripConditions = append(tripConditions, fmt.Sprintf("rt.symbol = $%d", argIndex))
+		args = append(args, symbol)
+		argIndex++
+	}
+	if assetClass != "" {
+		conditions = append(conditions, fmt.Sprintf("mp.asset_class = $%d", argIndex))
+		tripConditions = append(tripConditions, fmt.Sprintf("$%d = 'crypto'", argIndex))
+		args = append(args, assetClass)
+		argIndex++
+	}
+	if venueName != "" {
+		conditions = append(conditions, fmt.Sprintf("mp.venue = $%d", argIndex))
+		tripConditions = append(tripConditions, "FALSE")
+		args = append(args, venueName)
+		argIndex++
+	}
+	if setupID != "" {
+		conditions = append(conditions, fmt.Sprintf("mp.setup_id = $%d::uuid", argIndex))
+		tripConditions = append(tripConditions, "FALSE")
+		args = append(args, setupID)
+		argIndex++
+	}
+
+	query := fmt.Sprintf(`
+		WITH samples AS (
+			SELECT x.mae_percent, x.mfe_percent, x.pnl_percent, x.r_multiple, x.mae_r, x.mfe_r
+			FROM position_excursions x
+			JOIN manual_positions mp ON mp.id = x.position_id
+			WHERE %s
+			UNION ALL
+			SELECT rt.mae_percent, rt.mfe_percent, rt.pnl_percent, NULL, NULL, NULL
+			FROM trade_round_trips rt
+			WHERE %s
+				AND NOT EXISTS (
+					SELECT 1 FROM plan_executions pe
+					WHERE pe.user_id = rt.user_id AND pe.fill_ids && rt.trade_ids
+				)
+		)
+		SELECT
+			COUNT(*),
+			COUNT(r_multiple),
+			COALESCE(AVG(mae_percent), 0),
+			COALESCE(AVG(mfe_percent), 0),
+			COALESCE(AVG(r_multiple), 0),
+			COALESCE(AVG(CASE WHEN pnl_percent > 0 THEN mae_r END), 0),
+			COALESCE(AVG(CASE WHEN pnl_percent <= 0 THEN mfe_r END), 0),
+			COALESCE(AVG(CASE WHEN pnl_percent > 0 AND mfe_percent > 0 THEN pnl_percent / mfe_percent END), 0)
+		FROM samples
+	`, strings.Join(conditions, " AND "), strings.Join(tripConditions, " AND "))
+
+	var avgMAE, avgMFE, avgR, winnerMAER, loserMFER, capture float64
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(&stats.Count, &stats.WithStop, &avgMAE, &avgMFE, &avgR,
+		&winnerMAER, &loserMFER, &capture); err != nil {
+		return stats, err
+	}
+	stats.AvgMAE = fmt.Sprintf("%.4f", avgMAE)
+	stats.AvgMFE = fmt.Sprintf("%.4f", avgMFE)
+	stats.AvgRMultiple = fmt.Sprintf("%.4f", avgR)
+	stats.AvgWinnerMAER = fmt.Sprintf("%.4f", winnerMAER)
+	stats.AvgLoserMFER = fmt.Sprintf("%.4f", loserMFER)
+	stats.AvgWinnerCapture = fmt.Sprintf("%.4f", capture)
+	return stats, nil
+}
+
+func (r *BubbleRepositoryImpl) GetCalendarData(ctx context.Context, userID uuid.UUID, from time.Time, to time.Time, day entities.TradingDay, assetClass string, venueName string) (map[string]repositories.CalendarDay, error) {
+	start, end, err := day.RangeBounds(from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
 	conditions := []string{"b.user_id = $1", "b.candle_time >= $2", "b.candle_time < $3"}
-	args := []interface{}{userID, from, to.AddDate(0, 0, 1)}
-	argIndex := 4
+	args := []interface{}{userID, start, end, day.Timezone(), day.CutoffHour}
+	argIndex := 6
 
 	if assetClass != "" {
 		conditions = append(conditions, fmt.Sprintf("b.asset_class = $%d", argIndex))
@@ -601,7 +758,7 @@ func (r *BubbleRepositoryImpl) GetCalendarData(ctx context.Context, userID uuid.
 
 	query := fmt.Sprintf(`
 		SELECT
-			DATE(b.candle_time) as date,
+			(b.candle_time AT TIME ZONE $4 - make_interval(hours => $5))::date as date,
 			COUNT(DISTINCT b.id) as bubble_count,
 			COALESCE(SUM(CASE WHEN CAST(o.pnl_percent AS DECIMAL) > 0 THEN 1 ELSE 0 END), 0) as win_count,
 			COALESCE(SUM(CASE WHEN CAST(o.pnl_percent AS DECIMAL) <= 0 THEN 1 ELSE 0 END), 0) as loss_count,
@@ -609,7 +766,7 @@ func (r *BubbleRepositoryImpl) GetCalendarData(ctx context.Context, userID uuid.
 		FROM bubbles b
 		LEFT JOIN outcomes o ON o.bubble_id = b.id AND o.period = '1h'
 		WHERE %s
-		GROUP BY DATE(b.candle_time)
+		GROUP BY 1
 		ORDER BY date
 	`, whereClause)
 
@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+type CandleRepositoryImpl struct {
+	pool *pgxpool.Pool
+}
+
+func NewCandleRepository(pool *pgxpool.Pool) repositories.CandleRepository {
+	return &CandleRepositoryImpl{pool: pool}
+}
+
+func (r *CandleRepositoryImpl) UpsertMany(ctx context.Context, candles []*entities.Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	query := `
+		INSERT INTO market_candles (venue, symbol, interval, open_time, open, high, low, close, volume, fetched_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (venue, symbol, interval, open_time) DO UPDATE
+		SET open = EXCLUDED.open,
+			high = EXCLUDED.high,
+			low = EXCLUDED.low,
+			close = EXCLUDED.close,
+			volume = EXCLUDED.volume,
+			fetched_at = EXCLUDED.fetched_at
+	`
+	for _, candle := range candles {
+		_, err = tx.Exec(ctx, query,
+			candle.Venue, candle.Symbol, candle.Interval, candle.OpenTime,
+			candle.Open, candle.High, candle.Low, candle.Close, candle.Volume, candle.FetchedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *CandleRepositoryImpl) ListRange(ctx context.Context, venue, symbol, interval string, start, end time.Time) ([]*entities.Candle, error) {
+	query := `
+		SELECT venue, symbol, interval, open_time, open::text, high::text, low::text, close::text, volume::text, fetched_at
+		FROM market_candles
+		WHERE venue = $1 AND symbol = $2 AND interval = $3
+		  AND open_time >= $4 AND open_time <= $5
+		ORDER BY open_time ASC
+	`
+	rows, err := r.pool.Query(ctx, query, venue, symbol, interval, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candles []*entities.Candle
+	for rows.Next() {
+		var candle entities.Candle
+		if err := rows.Scan(
+			&candle.Venue, &candle.Symbol, &candle.Interval, &candle.OpenTime,
+			&candle.Open, &candle.High, &candle.Low, &candle.Close, &candle.Volume, &candle.FetchedAt); err != nil {
+			return nil, err
+		}
+		candles = append(candles, &candle)
+	}
+
+	return candles, rows.Err()
+}
+
+func (r *CandleRepositoryImpl) MarkEmpty(ctx context.Context, ranges []*entities.CandleEmptyRange) error {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	query := `
+		INSERT INTO market_candle_empty_ranges (venue, symbol, interval, start_time, end_time, checked_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (venue, symbol, interval, start_time) DO UPDATE
+		SET end_time = GREATEST(market_candle_empty_ranges.end_time, EXCLUDED.end_time),
+			checked_at = EXCLUDED.checked_at
+	`
+	for _, item := range ranges {
+		_, err = tx.Exec(ctx, query,
+			item.Venue, item.Symbol, item.Interval, item.StartTime, item.EndTime, item.CheckedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *CandleRepositoryImpl) ListEmpty(ctx context.Context, venue, symbol, interval string, start, end time.Time) ([]*entities.CandleEmptyRange, error) {
+	query := `
+		SELECT venue, symbol, interval, start_time, end_time, checked_at
+		FROM market_candle_empty_ranges
+		WHERE venue = $1 AND symbol = $2 AND interval = $3
+		  AND start_time <= $5 AND end_time >= $4
+		ORDER BY start_time ASC
+	`
+	rows, err := r.pool.Query(ctx, query, venue, symbol, interval, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ranges []*entities.CandleEmptyRange
+	for rows.Next() {
+		var item entities.CandleEmptyRange
+		if err := rows.Scan(
+			&item.Venue, &item.Symbol, &item.Interval, &item.StartTime, &item.EndTime, &item.CheckedAt); err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, &item)
+	}
+
+	return ranges, rows.Err()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -29,39 +30,15 @@ func NewGuidedReviewRepository(pool *pgxpool.Pool) repositories.GuidedReviewRepo
 }
 
 func (r *GuidedReviewRepositoryImpl) GetOrCreateToday(ctx context.Context, userID uuid.UUID, date string) (*entities.GuidedReview, []*entities.GuidedReviewItem, error) {
-	// Try to get existing review for the date
-	var review entities.GuidedReview
-	err := r.pool.QueryRow(ctx, `
-		SELECT id, user_id, review_date::text, status, completed_at, created_at
-		FROM guided_reviews
-		WHERE user_id = $1 AND review_date = $2
-	`, userID, date).Scan(
-		&review.ID, &review.UserID, &review.ReviewDate,
-		&review.Status, &review.CompletedAt, &review.CreatedAt,
-	)
-
-	if err == pgx.ErrNoRows {
-		// Create new review
-		review.ID = uuid.New()
-		review.UserID = userID
-		review.ReviewDate = date
-		review.Status = entities.GuidedReviewStatusPending
-		review.CreatedAt = time.Now().UTC()
-
-		_, err = r.pool.Exec(ctx, `
-			INSERT INTO guided_reviews (id, user_id, review_date, status, created_at)
-			VALUES ($1, $2, $3, $4, $5)
-		`, review.ID, review.UserID, review.ReviewDate, review.Status, review.CreatedAt)
-		if err != nil {
-			return nil, nil, fmt.Errorf("insert guided_reviews: %w", err)
-		}
-
+	review, created, err := r.getOrCreateReview(ctx, userID, entities.ReviewCadenceDaily, date, date)
+	if err != nil {
+		return nil, nil, err
+	}
+	if created {
 		// Auto-create items from trades for this date
 		if err := r.createItemsFromTrades(ctx, userID, review.ID, date); err != nil {
 			return nil, nil, fmt.Errorf("create items from trades: %w", err)
 		}
-	} else if err != nil {
-		return nil, nil, fmt.Errorf("query guided_reviews: %w", err)
 	}
 
 	// Load items
@@ -99,10 +76,178 @@ func (r *GuidedReviewRepositoryImpl) GetOrCreateToday(ctx context.Context, userI
 		return nil, nil, err
 	}
 
-	return &review, items, nil
+	return review, items, nil
+}
+
+func (r *GuidedReviewRepositoryImpl) GetOrCreatePeriod(ctx context.Context, userID uuid.UUID, cadence, startDate, endDate string) (*entities.GuidedReview, []*entities.GuidedReviewItem, error) {
+	review, _, err := r.getOrCreateReview(ctx, userID, cadence, startDate, endDate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Keep unanswered rollups current until the review is completed.
+	if review.Status != entities.GuidedReviewStatusCompleted {
+		if err := r.syncPeriodItems(ctx, userID, review); err != nil {
+			return nil, nil, fmt.Errorf("sync period items: %w", err)
+		}
+	}
+
+	items, err := r.loadItems(ctx, review.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	summaries, err := r.dailyAnswerSummaries(ctx, userID, startDate, endDate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("summarize daily answers: %w", err)
+	}
+	for _, item := range items {
+		item.DailyAnswers = summaries[item.Symbol]
+	}
+	return review, items, nil
+}
+
+// getOrCreateReview loads the user's review for the cadence period, creating
+// it with the latest question template when missing. created reports whether
+// the row was inserted by this call.
+func (r *GuidedReviewRepositoryImpl) getOrCreateReview(ctx context.Context, userID uuid.UUID, cadence, startDate, endDate string) (*entities.GuidedReview, bool, error) {
+	var review entities.GuidedReview
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, user_id, review_date::text, period_end::text, cadence, template_id, status, completed_at, created_at
+		FROM guided_reviews
+		WHERE user_id = $1 AND cadence = $2 AND review_date = $3
+	`, userID, cadence, startDate).Scan(
+		&review.ID, &review.UserID, &review.ReviewDate, &review.PeriodEnd, &review.Cadence,
+		&review.TemplateID, &review.Status, &review.CompletedAt, &review.CreatedAt,
+	)
+	if err == nil {
+		return &review, false, nil
+	}
+	if err != pgx.ErrNoRows {
+		return nil, false, fmt.Errorf("query guided_reviews: %w", err)
+	}
+
+	review = entities.GuidedReview{
+		ID:         uuid.New(),
+		UserID:     userID,
+		ReviewDate: startDate,
+		PeriodEnd:  endDate,
+		Cadence:    cadence,
+		Status:     entities.GuidedReviewStatusPending,
+		CreatedAt:  time.Now().UTC(),
+	}
+	err = r.pool.QueryRow(ctx, `
+		INSERT INTO guided_reviews (id, user_id, review_date, period_end, cadence, template_id, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, (
+			SELECT id FROM review_question_templates
+			WHERE user_id = $2 AND cadence = $5
+			ORDER BY version DESC
+			LIMIT 1
+		), $6, $7)
+		RETURNING template_id
+	`, review.ID, review.UserID, review.ReviewDate, review.PeriodEnd, review.Cadence, review.Status, review.CreatedAt).Scan(&review.TemplateID)
+	if err != nil {
+		return nil, false, fmt.Errorf("insert guided_reviews: %w", err)
+	}
+	return &review, true, nil
 }
 
-func (r *GuidedReviewRepositoryImpl) aggregateTradesAfter(ctx context.Context, userID uuid.UUID, date string, after *time.Time) (map[string]reviewTradeAgg, error) {
+// syncPeriodItems rolls the period's trades up into one item per symbol.
+// Answered items are left as they are; a placeholder item is kept while the
+// period has no trades.
+func (r *GuidedReviewRepositoryImpl) syncPeriodItems(ctx context.Context, userID uuid.UUID, review *entities.GuidedReview) error {
+	aggs, err := r.aggregateTradesAfter(ctx, userID, review.ReviewDate, review.PeriodEnd, nil)
+	if err != nil {
+		return err
+	}
+	keySuffix := review.Cadence + ":" + review.ReviewDate
+
+	if len(aggs) == 0 {
+		_, err := r.pool.Exec(ctx, `
+			INSERT INTO guided_review_items (id, review_id, trade_id, bundle_key, symbol, side, pnl, trade_count, order_index, created_at)
+			SELECT $1, $2, NULL, $3, $4, NULL, NULL, 0, 0, NOW()
+			WHERE NOT EXISTS (SELECT 1 FROM guided_review_items WHERE review_id = $2)
+		`, uuid.New(), review.ID, "NO_TRADE:"+keySuffix, "__NO_TRADE__")
+		return err
+	}
+
+	if _, err := r.pool.Exec(ctx, `
+		DELETE FROM guided_review_items
+		WHERE review_id = $1 AND bundle_key = $2 AND intent IS NULL
+	`, review.ID, "NO_TRADE:"+keySuffix); err != nil {
+		return err
+	}
+
+	symbols := make([]string, 0, len(aggs))
+	for symbol := range aggs {
+		symbols = append(symbols, symbol)
+	}
+	sort.Slice(symbols, func(i, j int) bool {
+		if aggs[symbols[i]].TradeCount != aggs[symbols[j]].TradeCount {
+			return aggs[symbols[i]].TradeCount > aggs[symbols[j]].TradeCount
+		}
+		return symbols[i] < symbols[j]
+	})
+
+	for orderIndex, symbol := range symbols {
+		agg := aggs[symbol]
+		bundleKey := fmt.Sprintf("%s:%s", symbol, keySuffix)
+		if _, err := r.pool.Exec(ctx, `
+			UPDATE guided_review_items
+			SET side = $3, pnl = $4, trade_count = $5, order_index = $6
+			WHERE review_id = $1 AND bundle_key = $2 AND intent IS NULL
+		`, review.ID, bundleKey, agg.Side, agg.TotalPnL, agg.TradeCount, orderIndex); err != nil {
+			return err
+		}
+		if _, err := r.pool.Exec(ctx, `
+			INSERT INTO guided_review_items (id, review_id, trade_id, bundle_key, symbol, side, pnl, trade_count, order_index, created_at)
+			SELECT $1, $2, NULL, $3, $4, $5, $6, $7, $8, NOW()
+			WHERE NOT EXISTS (SELECT 1 FROM guided_review_items WHERE review_id = $2 AND bundle_key = $3)
+		`, uuid.New(), review.ID, bundleKey, symbol, agg.Side, agg.TotalPnL, agg.TradeCount, orderIndex); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dailyAnswerSummaries counts the answers given in daily reviews between
+// startDate and endDate, per symbol.
+func (r *GuidedReviewRepositoryImpl) dailyAnswerSummaries(ctx context.Context, userID uuid.UUID, startDate, endDate string) (map[string]*entities.PeriodAnswerSummary, error) {
+	answered, err := r.ListAnsweredItems(ctx, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make(map[string]*entities.PeriodAnswerSummary)
+	days := make(map[string]map[string]bool)
+	for _, item := range answered {
+		summary := summaries[item.Symbol]
+		if summary == nil {
+			summary = &entities.PeriodAnswerSummary{
+				Intents:        map[string]int{},
+				Emotions:       map[string]int{},
+				PatternMatches: map[string]int{},
+			}
+			summaries[item.Symbol] = summary
+			days[item.Symbol] = map[string]bool{}
+		}
+		summary.AnsweredItems++
+		days[item.Symbol][item.ReviewDate] = true
+		summary.ReviewedDays = len(days[item.Symbol])
+		summary.Intents[item.Intent]++
+		var emotions []string
+		if len(item.Emotions) > 0 {
+			_ = json.Unmarshal(item.Emotions, &emotions)
+		}
+		for _, emotion := range emotions {
+			summary.Emotions[emotion]++
+		}
+		if item.PatternMatch != nil {
+			summary.PatternMatches[*item.PatternMatch]++
+		}
+	}
+	return summaries, nil
+}
+
+func (r *GuidedReviewRepositoryImpl) aggregateTradesAfter(ctx context.Context, userID uuid.UUID, startDate, endDate string, after *time.Time) (map[string]reviewTradeAgg, error) {
 	base := `
 		WITH base AS (
 			SELECT
@@ -115,11 +260,19 @@ func (r *GuidedReviewRepositoryImpl) aggregateTradesAfter(ctx context.Context, u
 				END AS pnl
 			FROM trades
 			WHERE user_id = $1
-			  AND trade_time::date = $2::date
+			  AND trade_time >= $2 AND trade_time < $3
 	`
-	args := []any{userID, date}
+	day, err := r.tradingDay(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	from, to, err := day.RangeBounds(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	args := []any{userID, from, to}
 	if after != nil {
-		base += ` AND trade_time > $3 `
+		base += ` AND trade_time > $4 `
 		args = append(args, *after)
 	}
 	base += `
@@ -206,7 +359,7 @@ func (r *GuidedReviewRepositoryImpl) appendSupplementItems(ctx context.Context,
 	if completedAt == nil {
 		return nil
 	}
-	tradesAfter, err := r.aggregateTradesAfter(ctx, userID, date, completedAt)
+	tradesAfter, err := r.aggregateTradesAfter(ctx, userID, date, date, completedAt)
 	if err != nil {
 		return err
 	}
@@ -287,7 +440,7 @@ func (r *GuidedReviewRepositoryImpl) appendRolloverFromPreviousDay(ctx context.C
 	err = r.pool.QueryRow(ctx, `
 		SELECT id, completed_at
 		FROM guided_reviews
-		WHERE user_id = $1 AND review_date = $2::date AND status = $3
+		WHERE user_id = $1 AND cadence = 'daily' AND review_date = $2::date AND status = $3
 	`, userID, prevDate, entities.GuidedReviewStatusCompleted).Scan(&prevReviewID, &prevCompletedAt)
 	if err == pgx.ErrNoRows || prevCompletedAt == nil {
 		return nil
@@ -296,7 +449,7 @@ func (r *GuidedReviewRepositoryImpl) appendRolloverFromPreviousDay(ctx context.C
 		return err
 	}
 
-	prevAfter, err := r.aggregateTradesAfter(ctx, userID, prevDate, prevCompletedAt)
+	prevAfter, err := r.aggregateTradesAfter(ctx, userID, prevDate, prevDate, prevCompletedAt)
 	if err != nil {
 		return err
 	}
@@ -331,7 +484,16 @@ func (r *GuidedReviewRepositoryImpl) appendRolloverFromPreviousDay(ctx context.C
 }
 
 func (r *GuidedReviewRepositoryImpl) createItemsFromTrades(ctx context.Context, userID, reviewID uuid.UUID, date string) error {
-	// Query trades for this user on this date, grouped by symbol.
+	day, err := r.tradingDay(ctx, userID)
+	if err != nil {
+		return err
+	}
+	from, to, err := day.Bounds(date)
+	if err != nil {
+		return err
+	}
+
+	// Query trades for this user on this trading day, grouped by symbol.
 	// For guided review count, merge split fills into one "order-like" bundle when
 	// same symbol+side trades occur within 90 seconds.
 	rows, err := r.pool.Query(ctx, `
@@ -346,7 +508,7 @@ func (r *GuidedReviewRepositoryImpl) createItemsFromTrades(ctx context.Context,
 				END AS pnl
 			FROM trades
 			WHERE user_id = $1
-			  AND trade_time::date = $2::date
+			  AND trade_time >= $2 AND trade_time < $3
 		),
 		marked AS (
 			SELECT
@@ -381,7 +543,7 @@ func (r *GuidedReviewRepositoryImpl) createItemsFromTrades(ctx context.Context,
 		FROM bundled
 		GROUP BY symbol
 		ORDER BY COUNT(DISTINCT side || ':' || bundle_idx::text) DESC
-	`, userID, date)
+	`, userID, from, to)
 	if err != nil {
 		return fmt.Errorf("query trades: %w", err)
 	}
@@ -434,12 +596,38 @@ func (r *GuidedReviewRepositoryImpl) createItemsFromTrades(ctx context.Context,
 }
 
 func (r *GuidedReviewRepositoryImpl) loadItems(ctx context.Context, reviewID uuid.UUID) ([]*entities.GuidedReviewItem, error) {
+	// Each item picks up the latest plan on its symbol with fills in the
+	// review period (in the user's trading days), and the behavior patterns
+	// detected on its symbol or for whole days in it.
 	rows, err := r.pool.Query(ctx, `
-		SELECT id, review_id, trade_id, bundle_key, symbol, side, pnl, trade_count,
-		       intent, emotions, pattern_match, memo, order_index, created_at
-		FROM guided_review_items
-		WHERE review_id = $1
-		ORDER BY order_index ASC
+		SELECT gri.id, gri.review_id, gri.trade_id, gri.bundle_key, gri.symbol, gri.side, gri.pnl, gri.trade_count,
+		       gri.intent, gri.emotions, gri.pattern_match, gri.memo, gri.order_index, gri.created_at, gri.answers,
+		       pe.position_id, pe.score, pe.entry_slippage_percent::text, pe.size_deviation_percent::text,
+		       pe.stop_status, pe.early_exit,
+		       ARRAY(
+		           SELECT DISTINCT be.pattern
+		           FROM behavior_episodes be
+		           WHERE be.user_id = gr.user_id
+		             AND be.trade_date BETWEEN gr.review_date AND gr.period_end
+		             AND be.symbol IN ('', upper(regexp_replace(gri.symbol, '[^A-Za-z0-9]', '', 'g')))
+		           ORDER BY be.pattern
+		       ) AS detected_patterns
+		FROM guided_review_items gri
+		JOIN guided_reviews gr ON gr.id = gri.review_id
+		JOIN users u ON u.id = gr.user_id
+		LEFT JOIN LATERAL (
+			SELECT position_id, score, entry_slippage_percent, size_deviation_percent, stop_status, early_exit
+			FROM plan_executions
+			WHERE user_id = gr.user_id
+			  AND symbol_key = upper(regexp_replace(gri.symbol, '[^A-Za-z0-9]', '', 'g'))
+			  AND status <> 'pending'
+			  AND (first_fill_at AT TIME ZONE u.timezone - make_interval(hours => u.day_cutoff_hour))::date <= gr.period_end
+			  AND (last_fill_at AT TIME ZONE u.timezone - make_interval(hours => u.day_cutoff_hour))::date >= gr.review_date
+			ORDER BY first_fill_at DESC
+			LIMIT 1
+		) pe ON true
+		WHERE gri.review_id = $1
+		ORDER BY gri.order_index ASC
 	`, reviewID)
 	if err != nil {
 		return nil, fmt.Errorf("query guided_review_items: %w", err)
@@ -449,14 +637,24 @@ func (r *GuidedReviewRepositoryImpl) loadItems(ctx context.Context, reviewID uui
 	var items []*entities.GuidedReviewItem
 	for rows.Next() {
 		var item entities.GuidedReviewItem
+		var planID *uuid.UUID
+		var plan entities.PlanAdherenceSummary
+		var stopStatus *string
 		if err := rows.Scan(
 			&item.ID, &item.ReviewID, &item.TradeID, &item.BundleKey,
 			&item.Symbol, &item.Side, &item.PnL, &item.TradeCount,
 			&item.Intent, &item.Emotions, &item.PatternMatch, &item.Memo,
-			&item.OrderIndex, &item.CreatedAt,
+			&item.OrderIndex, &item.CreatedAt, &item.Answers,
+			&planID, &plan.Score, &plan.EntrySlippagePercent, &plan.SizeDeviationPercent,
+			&stopStatus, &plan.EarlyExit, &item.DetectedPatterns,
 		); err != nil {
 			return nil, fmt.Errorf("scan guided_review_item: %w", err)
 		}
+		if planID != nil {
+			plan.PositionID = *planID
+			plan.StopStatus = entities.PlanStopStatus(safeString(stopStatus))
+			item.PlanAdherence = &plan
+		}
 		items = append(items, &item)
 	}
 
@@ -500,12 +698,16 @@ func (r *GuidedReviewRepositoryImpl) SubmitItem(ctx context.Context, userID uuid
 	if input.Memo != "" {
 		memoPtr = &input.Memo
 	}
+	var answersPtr json.RawMessage
+	if len(input.Answers) > 0 && string(input.Answers) != "null" {
+		answersPtr = input.Answers
+	}
 
 	_, err = r.pool.Exec(ctx, `
 		UPDATE guided_review_items
-		SET intent = $1, emotions = $2, pattern_match = $3, memo = $4
-		WHERE id = $5
-	`, intentPtr, emotionsPtr, patternPtr, memoPtr, itemID)
+		SET intent = $1, emotions = $2, pattern_match = $3, memo = $4, answers = $5
+		WHERE id = $6
+	`, intentPtr, emotionsPtr, patternPtr, memoPtr, answersPtr, itemID)
 	if err != nil {
 		return fmt.Errorf("update guided_review_items: %w", err)
 	}
@@ -524,8 +726,8 @@ func (r *GuidedReviewRepositoryImpl) CompleteReview(ctx context.Context, userID
 	// Verify ownership
 	var review entities.GuidedReview
 	err := r.pool.QueryRow(ctx, `
-		SELECT id, user_id, review_date::text, status FROM guided_reviews WHERE id = $1
-	`, reviewID).Scan(&review.ID, &review.UserID, &review.ReviewDate, &review.Status)
+		SELECT id, user_id, review_date::text, cadence, status FROM guided_reviews WHERE id = $1
+	`, reviewID).Scan(&review.ID, &review.UserID, &review.ReviewDate, &review.Cadence, &review.Status)
 	if err == pgx.ErrNoRows {
 		return nil, fmt.Errorf("review not found")
 	}
@@ -548,7 +750,7 @@ func (r *GuidedReviewRepositoryImpl) CompleteReview(ctx context.Context, userID
 	}
 
 	// Update streak
-	streak, err := r.updateStreak(ctx, userID, review.ReviewDate)
+	streak, err := r.updateStreak(ctx, userID, review.Cadence, review.ReviewDate)
 	if err != nil {
 		return nil, fmt.Errorf("update streak: %w", err)
 	}
@@ -556,27 +758,30 @@ func (r *GuidedReviewRepositoryImpl) CompleteReview(ctx context.Context, userID
 	return streak, nil
 }
 
-func (r *GuidedReviewRepositoryImpl) updateStreak(ctx context.Context, userID uuid.UUID, reviewDate string) (*entities.UserStreak, error) {
+// updateStreak extends the cadence streak when reviewDate starts the period
+// right after the last reviewed one, and restarts it otherwise.
+func (r *GuidedReviewRepositoryImpl) updateStreak(ctx context.Context, userID uuid.UUID, cadence, reviewDate string) (*entities.UserStreak, error) {
 	// Upsert user_streaks
 	var streak entities.UserStreak
 	err := r.pool.QueryRow(ctx, `
-		SELECT user_id, current_streak, longest_streak, last_review_date::text, updated_at
-		FROM user_streaks WHERE user_id = $1
-	`, userID).Scan(&streak.UserID, &streak.CurrentStreak, &streak.LongestStreak, &streak.LastReviewDate, &streak.UpdatedAt)
+		SELECT user_id, cadence, current_streak, longest_streak, last_review_date::text, updated_at
+		FROM user_streaks WHERE user_id = $1 AND cadence = $2
+	`, userID, cadence).Scan(&streak.UserID, &streak.Cadence, &streak.CurrentStreak, &streak.LongestStreak, &streak.LastReviewDate, &streak.UpdatedAt)
 
 	if err == pgx.ErrNoRows {
 		// First ever review
 		streak = entities.UserStreak{
 			UserID:         userID,
+			Cadence:        cadence,
 			CurrentStreak:  1,
 			LongestStreak:  1,
 			LastReviewDate: &reviewDate,
 			UpdatedAt:      time.Now().UTC(),
 		}
 		_, err = r.pool.Exec(ctx, `
-			INSERT INTO user_streaks (user_id, current_streak, longest_streak, last_review_date, updated_at)
-			VALUES ($1, $2, $3, $4, $5)
-		`, streak.UserID, streak.CurrentStreak, streak.LongestStreak, streak.LastReviewDate, streak.UpdatedAt)
+			INSERT INTO user_streaks (user_id, cadence, current_streak, longest_streak, last_review_date, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, streak.UserID, streak.Cadence, streak.CurrentStreak, streak.LongestStreak, streak.LastReviewDate, streak.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -588,17 +793,16 @@ func (r *GuidedReviewRepositoryImpl) updateStreak(ctx context.Context, userID uu
 
 	// Calculate new streak
 	if streak.LastReviewDate != nil && *streak.LastReviewDate == reviewDate {
-		// Same day, no change
+		// Same period, no change
 		return &streak, nil
 	}
 
-	// Check if yesterday
-	reviewTime, _ := time.Parse("2006-01-02", reviewDate)
+	// Check if the previous period
 	var isConsecutive bool
 	if streak.LastReviewDate != nil {
-		lastTime, _ := time.Parse("2006-01-02", *streak.LastReviewDate)
-		diff := reviewTime.Sub(lastTime).Hours() / 24
-		isConsecutive = diff >= 0.5 && diff <= 1.5
+		reviewTime, _ := time.Parse("2006-01-02", reviewDate)
+		previous, _, _ := entities.ReviewPeriod(cadence, reviewTime.AddDate(0, 0, -1))
+		isConsecutive = previous.Format("2006-01-02") == *streak.LastReviewDate
 	}
 
 	if isConsecutive {
@@ -615,8 +819,8 @@ func (r *GuidedReviewRepositoryImpl) updateStreak(ctx context.Context, userID uu
 	_, err = r.pool.Exec(ctx, `
 		UPDATE user_streaks
 		SET current_streak = $1, longest_streak = $2, last_review_date = $3, updated_at = $4
-		WHERE user_id = $5
-	`, streak.CurrentStreak, streak.LongestStreak, streak.LastReviewDate, streak.UpdatedAt, streak.UserID)
+		WHERE user_id = $5 AND cadence = $6
+	`, streak.CurrentStreak, streak.LongestStreak, streak.LastReviewDate, streak.UpdatedAt, streak.UserID, streak.Cadence)
 	if err != nil {
 		return nil, err
 	}
@@ -624,16 +828,17 @@ func (r *GuidedReviewRepositoryImpl) updateStreak(ctx context.Context, userID uu
 	return &streak, nil
 }
 
-func (r *GuidedReviewRepositoryImpl) GetStreak(ctx context.Context, userID uuid.UUID) (*entities.UserStreak, error) {
+func (r *GuidedReviewRepositoryImpl) GetStreak(ctx context.Context, userID uuid.UUID, cadence string) (*entities.UserStreak, error) {
 	var streak entities.UserStreak
 	err := r.pool.QueryRow(ctx, `
-		SELECT user_id, current_streak, longest_streak, last_review_date::text, updated_at
-		FROM user_streaks WHERE user_id = $1
-	`, userID).Scan(&streak.UserID, &streak.CurrentStreak, &streak.LongestStreak, &streak.LastReviewDate, &streak.UpdatedAt)
+		SELECT user_id, cadence, current_streak, longest_streak, last_review_date::text, updated_at
+		FROM user_streaks WHERE user_id = $1 AND cadence = $2
+	`, userID, cadence).Scan(&streak.UserID, &streak.Cadence, &streak.CurrentStreak, &streak.LongestStreak, &streak.LastReviewDate, &streak.UpdatedAt)
 
 	if err == pgx.ErrNoRows {
 		return &entities.UserStreak{
 			UserID:        userID,
+			Cadence:       cadence,
 			CurrentStreak: 0,
 			LongestStreak: 0,
 			UpdatedAt:     time.Now().UTC(),
@@ -642,9 +847,47 @@ func (r *GuidedReviewRepositoryImpl) GetStreak(ctx context.Context, userID uuid.
 	if err != nil {
 		return nil, err
 	}
+
+	// A streak whose last review is older than the previous period has
+	// already been broken, even though it's only reset on the next completion.
+	day, err := r.tradingDay(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if streakBroken(day, cadence, streak.LastReviewDate, time.Now()) {
+		streak.CurrentStreak = 0
+	}
 	return &streak, nil
 }
 
+// streakBroken reports whether the review dated lastReviewDate is neither in
+// the current period nor the one before it, counted in the user's trading
+// days.
+func streakBroken(day entities.TradingDay, cadence string, lastReviewDate *string, now time.Time) bool {
+	if lastReviewDate == nil {
+		return false
+	}
+	current, _, ok := entities.ReviewPeriod(cadence, day.Start(now))
+	if !ok {
+		return false
+	}
+	previous, _, _ := entities.ReviewPeriod(cadence, current.AddDate(0, 0, -1))
+	last := *lastReviewDate
+	return last != current.Format("2006-01-02") && last != previous.Format("2006-01-02")
+}
+
+// tradingDay loads the timezone and cutoff hour that trades are bucketed into
+// review days with.
+func (r *GuidedReviewRepositoryImpl) tradingDay(ctx context.Context, userID uuid.UUID) (entities.TradingDay, error) {
+	var timezone string
+	var cutoff int
+	err := r.pool.QueryRow(ctx, `SELECT timezone, day_cutoff_hour FROM users WHERE id = $1`, userID).Scan(&timezone, &cutoff)
+	if err != nil && err != pgx.ErrNoRows {
+		return entities.TradingDay{}, err
+	}
+	return entities.NewTradingDay(timezone, cutoff), nil
+}
+
 func (r *GuidedReviewRepositoryImpl) ListReviews(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*entities.GuidedReview, int, error) {
 	var total int
 	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM guided_reviews WHERE user_id = $1`, userID).Scan(&total); err != nil {
@@ -652,10 +895,10 @@ func (r *GuidedReviewRepositoryImpl) ListReviews(ctx context.Context, userID uui
 	}
 
 	rows, err := r.pool.Query(ctx, `
-		SELECT id, user_id, review_date::text, status, completed_at, created_at
+		SELECT id, user_id, review_date::text, period_end::text, cadence, template_id, status, completed_at, created_at
 		FROM guided_reviews
 		WHERE user_id = $1
-		ORDER BY review_date DESC
+		ORDER BY review_date DESC, cadence
 		LIMIT $2 OFFSET $3
 	`, userID, limit, offset)
 	if err != nil {
@@ -666,7 +909,7 @@ func (r *GuidedReviewRepositoryImpl) ListReviews(ctx context.Context, userID uui
 	var reviews []*entities.GuidedReview
 	for rows.Next() {
 		var r entities.GuidedReview
-		if err := rows.Scan(&r.ID, &r.UserID, &r.ReviewDate, &r.Status, &r.CompletedAt, &r.CreatedAt); err != nil {
+		if err := rows.Scan(&r.ID, &r.UserID, &r.ReviewDate, &r.PeriodEnd, &r.Cadence, &r.TemplateID, &r.Status, &r.CompletedAt, &r.CreatedAt); err != nil {
 			return nil, 0, err
 		}
 		reviews = append(reviews, &r)
@@ -674,3 +917,32 @@ func (r *GuidedReviewRepositoryImpl) ListReviews(ctx context.Context, userID uui
 
 	return reviews, total, rows.Err()
 }
+
+// ListAnsweredItems returns items with an intent on daily reviews dated
+// between fromDate and toDate (inclusive), oldest first.
+func (r *GuidedReviewRepositoryImpl) ListAnsweredItems(ctx context.Context, userID uuid.UUID, fromDate, toDate string) ([]*repositories.ReviewInsightItem, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT gr.review_date::text, gri.symbol, gri.intent, gri.emotions, gri.pattern_match, gri.pnl::text
+		FROM guided_review_items gri
+		JOIN guided_reviews gr ON gr.id = gri.review_id
+		WHERE gr.user_id = $1
+		  AND gr.cadence = 'daily'
+		  AND gr.review_date BETWEEN $2::date AND $3::date
+		  AND gri.intent IS NOT NULL
+		ORDER BY gr.review_date ASC, gri.order_index ASC
+	`, userID, fromDate, toDate)
+	if err != nil {
+		return nil, fmt.Errorf("query answered items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*repositories.ReviewInsightItem
+	for rows.Next() {
+		var item repositories.ReviewInsightItem
+		if err := rows.Scan(&item.ReviewDate, &item.Symbol, &item.Intent, &item.Emotions, &item.PatternMatch, &item.PnL); err != nil {
+			return nil, fmt.Errorf("scan answered item: %w", err)
+		}
+		items = append(items, &item)
+	}
+	return items, rows.Err()
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,25 +22,50 @@ func NewNotificationChannelRepository(pool *pgxpool.Pool) repositories.Notificat
 	return &NotificationChannelRepositoryImpl{pool: pool}
 }
 
+var defaultChannelSeverities = []string{string(entities.AlertSeverityNormal), string(entities.AlertSeverityUrgent)}
+
 func (r *NotificationChannelRepositoryImpl) Upsert(ctx context.Context, ch *entities.NotificationChannel) error {
+	if len(ch.Severities) == 0 {
+		ch.Severities = defaultChannelSeverities
+	}
 	query := `
-		INSERT INTO notification_channels (id, user_id, channel_type, config, enabled, verified, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		ON CONFLICT (user_id, channel_type) DO UPDATE SET config = $4, enabled = $5, verified = $6
+		INSERT INTO notification_channels (id, user_id, channel_type, config, enabled, verified, severities, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, channel_type) DO UPDATE SET config = $4, enabled = $5, verified = $6, severities = $7
 	`
 	_, err := r.pool.Exec(ctx, query,
-		ch.ID, ch.UserID, ch.ChannelType, ch.Config, ch.Enabled, ch.Verified, ch.CreatedAt)
+		ch.ID, ch.UserID, ch.ChannelType, ch.Config, ch.Enabled, ch.Verified, ch.Severities, ch.CreatedAt)
 	return err
 }
 
 func (r *NotificationChannelRepositoryImpl) GetByUserAndType(ctx context.Context, userID uuid.UUID, channelType entities.ChannelType) (*entities.NotificationChannel, error) {
 	query := `
-		SELECT id, user_id, channel_type, config, enabled, verified, created_at
+		SELECT id, user_id, channel_type, config, enabled, verified, severities, created_at
 		FROM notification_channels WHERE user_id = $1 AND channel_type = $2
 	`
 	var ch entities.NotificationChannel
 	err := r.pool.QueryRow(ctx, query, userID, channelType).Scan(
-		&ch.ID, &ch.UserID, &ch.ChannelType, &ch.Config, &ch.Enabled, &ch.Verified, &ch.CreatedAt)
+		&ch.ID, &ch.UserID, &ch.ChannelType, &ch.Config, &ch.Enabled, &ch.Verified, &ch.Severities, &ch.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ch, nil
+}
+
+func (r *NotificationChannelRepositoryImpl) GetTelegramByChatID(ctx context.Context, chatID int64) (*entities.NotificationChannel, error) {
+	query := `
+		SELECT id, user_id, channel_type, config, enabled, verified, severities, created_at
+		FROM notification_channels
+		WHERE channel_type = 'telegram' AND verified = true AND config->>'chat_id' = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	var ch entities.NotificationChannel
+	err := r.pool.QueryRow(ctx, query, strconv.FormatInt(chatID, 10)).Scan(
+		&ch.ID, &ch.UserID, &ch.ChannelType, &ch.Config, &ch.Enabled, &ch.Verified, &ch.Severities, &ch.CreatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
@@ -51,7 +77,7 @@ func (r *NotificationChannelRepositoryImpl) GetByUserAndType(ctx context.Context
 
 func (r *NotificationChannelRepositoryImpl) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.NotificationChannel, error) {
 	query := `
-		SELECT id, user_id, channel_type, config, enabled, verified, created_at
+		SELECT id, user_id, channel_type, config, enabled, verified, severities, created_at
 		FROM notification_channels WHERE user_id = $1
 	`
 	rows, err := r.pool.Query(ctx, query, userID)
@@ -63,7 +89,7 @@ func (r *NotificationChannelRepositoryImpl) ListByUser(ctx context.Context, user
 	var channels []*entities.NotificationChannel
 	for rows.Next() {
 		var ch entities.NotificationChannel
-		if err := rows.Scan(&ch.ID, &ch.UserID, &ch.ChannelType, &ch.Config, &ch.Enabled, &ch.Verified, &ch.CreatedAt); err != nil {
+		if err := rows.Scan(&ch.ID, &ch.UserID, &ch.ChannelType, &ch.Config, &ch.Enabled, &ch.Verified, &ch.Severities, &ch.CreatedAt); err != nil {
 			return nil, err
 		}
 		channels = append(channels, &ch)
@@ -79,7 +105,7 @@ func (r *NotificationChannelRepositoryImpl) DeleteByUserAndType(ctx context.Cont
 
 func (r *NotificationChannelRepositoryImpl) ListVerifiedByUser(ctx context.Context, userID uuid.UUID) ([]*entities.NotificationChannel, error) {
 	query := `
-		SELECT id, user_id, channel_type, config, enabled, verified, created_at
+		SELECT id, user_id, channel_type, config, enabled, verified, severities, created_at
 		FROM notification_channels WHERE user_id = $1 AND enabled = true AND verified = true
 	`
 	rows, err := r.pool.Query(ctx, query, userID)
@@ -91,7 +117,7 @@ func (r *NotificationChannelRepositoryImpl) ListVerifiedByUser(ctx context.Conte
 	var channels []*entities.NotificationChannel
 	for rows.Next() {
 		var ch entities.NotificationChannel
-		if err := rows.Scan(&ch.ID, &ch.UserID, &ch.ChannelType, &ch.Config, &ch.Enabled, &ch.Verified, &ch.CreatedAt); err != nil {
+		if err := rows.Scan(&ch.ID, &ch.UserID, &ch.ChannelType, &ch.Config, &ch.Enabled, &ch.Verified, &ch.Severities, &ch.CreatedAt); err != nil {
 			return nil, err
 		}
 		channels = append(channels, &ch)
@@ -99,6 +125,13 @@ func (r *NotificationChannelRepositoryImpl) ListVerifiedByUser(ctx context.Conte
 	return channels, rows.Err()
 }
 
+func (r *NotificationChannelRepositoryImpl) UpdateRouting(ctx context.Context, userID uuid.UUID, channelType entities.ChannelType, enabled bool, severities []string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE notification_channels SET enabled = $3, severities = $4 WHERE user_id = $1 AND channel_type = $2`,
+		userID, channelType, enabled, severities)
+	return err
+}
+
 // --- TelegramVerifyCode ---
 
 type TelegramVerifyCodeRepositoryImpl struct {
@@ -144,3 +177,314 @@ func (r *TelegramVerifyCodeRepositoryImpl) MarkUsed(ctx context.Context, id uuid
 	_, err := r.pool.Exec(ctx, `UPDATE telegram_verify_codes SET used = true WHERE id = $1`, id)
 	return err
 }
+
+// --- NotificationVerifyCode ---
+
+type NotificationVerifyCodeRepositoryImpl struct {
+	pool *pgxpool.Pool
+}
+
+func NewNotificationVerifyCodeRepository(pool *pgxpool.Pool) repositories.NotificationVerifyCodeRepository {
+	return &NotificationVerifyCodeRepositoryImpl{pool: pool}
+}
+
+func (r *NotificationVerifyCodeRepositoryImpl) Create(ctx context.Context, code *entities.NotificationVerifyCode) error {
+	query := `
+		INSERT INTO notification_verify_codes (id, user_id, channel_type, code, expires_at, used, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	code.ID = uuid.New()
+	code.CreatedAt = time.Now().UTC()
+	_, err := r.pool.Exec(ctx, query,
+		code.ID, code.UserID, code.ChannelType, code.Code, code.ExpiresAt, code.Used, code.CreatedAt)
+	return err
+}
+
+func (r *NotificationVerifyCodeRepositoryImpl) FindValidCode(ctx context.Context, userID uuid.UUID, channelType entities.ChannelType, code string) (*entities.NotificationVerifyCode, error) {
+	query := `
+		SELECT id, user_id, channel_type, code, expires_at, used, created_at
+		FROM notification_verify_codes
+		WHERE user_id = $1 AND channel_type = $2 AND code = $3 AND used = false AND expires_at > $4
+		ORDER BY created_at DESC LIMIT 1
+	`
+	var vc entities.NotificationVerifyCode
+	err := r.pool.QueryRow(ctx, query, userID, channelType, code, time.Now().UTC()).Scan(
+		&vc.ID, &vc.UserID, &vc.ChannelType, &vc.Code, &vc.ExpiresAt, &vc.Used, &vc.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &vc, nil
+}
+
+func (r *NotificationVerifyCodeRepositoryImpl) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `UPDATE notification_verify_codes SET used = true WHERE id = $1`, id)
+	return err
+}
+
+func (r *NotificationVerifyCodeRepositoryImpl) RecordFailedAttempt(ctx context.Context, userID uuid.UUID, channelType entities.ChannelType, maxAttempts int) (bool, error) {
+	query := `
+		UPDATE notification_verify_codes
+		SET attempts = attempts + 1,
+			used = attempts + 1 >= $3
+		WHERE user_id = $1 AND channel_type = $2 AND used = false AND expires_at > $4
+		RETURNING used
+	`
+	rows, err := r.pool.Query(ctx, query, userID, channelType, maxAttempts, time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	locked := false
+	for rows.Next() {
+		var used bool
+		if err := rows.Scan(&used); err != nil {
+			return false, err
+		}
+		locked = locked || used
+	}
+	return locked, rows.Err()
+}
+
+// --- NotificationDelivery ---
+
+type NotificationDeliveryRepositoryImpl struct {
+	pool *pgxpool.Pool
+}
+
+func NewNotificationDeliveryRepository(pool *pgxpool.Pool) repositories.NotificationDeliveryRepository {
+	return &NotificationDeliveryRepositoryImpl{pool: pool}
+}
+
+const notificationDeliveryColumns = `id, user_id, channel_type, alert_id, title, body, deep_link, severity, digest,
+	status, attempts, next_attempt_at, error, sent_at, created_at, updated_at`
+
+func (r *NotificationDeliveryRepositoryImpl) Create(ctx context.Context, d *entities.NotificationDelivery) error {
+	query := `
+		INSERT INTO notification_deliveries (` + notificationDeliveryColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`
+	_, err := r.pool.Exec(ctx, query,
+		d.ID, d.UserID, d.ChannelType, d.AlertID, d.Title, d.Body, d.DeepLink, d.Severity, d.Digest,
+		d.Status, d.Attempts, d.NextAttemptAt, d.Error, d.SentAt, d.CreatedAt, d.UpdatedAt)
+	return err
+}
+
+func (r *NotificationDeliveryRepositoryImpl) ListByUser(ctx context.Context, userID uuid.UUID, status string, limit int) ([]*entities.NotificationDelivery, error) {
+	query := `
+		SELECT ` + notificationDeliveryColumns + `
+		FROM notification_deliveries
+		WHERE user_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+	rows, err := r.pool.Query(ctx, query, userID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanNotificationDeliveries(rows)
+}
+
+func (r *NotificationDeliveryRepositoryImpl) ListByAlert(ctx context.Context, alertID uuid.UUID) ([]*entities.NotificationDelivery, error) {
+	query := `
+		SELECT ` + notificationDeliveryColumns + `
+		FROM notification_deliveries
+		WHERE alert_id = $1
+		ORDER BY created_at
+	`
+	rows, err := r.pool.Query(ctx, query, alertID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanNotificationDeliveries(rows)
+}
+
+func (r *NotificationDeliveryRepositoryImpl) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.NotificationDelivery, error) {
+	query := `
+		UPDATE notification_deliveries
+		SET next_attempt_at = $2, updated_at = $1
+		WHERE id IN (
+			SELECT id FROM notification_deliveries
+			WHERE status IN ('pending', 'failed') AND next_attempt_at <= $1
+			ORDER BY next_attempt_at ASC
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + notificationDeliveryColumns + `
+	`
+	rows, err := r.pool.Query(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanNotificationDeliveries(rows)
+}
+
+func (r *NotificationDeliveryRepositoryImpl) UpdateAttempt(ctx context.Context, d *entities.NotificationDelivery) error {
+	query := `
+		UPDATE notification_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, error = $5, sent_at = $6, updated_at = $7
+		WHERE id = $1
+	`
+	_, err := r.pool.Exec(ctx, query, d.ID, d.Status, d.Attempts, d.NextAttemptAt, d.Error, d.SentAt, d.UpdatedAt)
+	return err
+}
+
+func (r *NotificationDeliveryRepositoryImpl) Requeue(ctx context.Context, userID uuid.UUID, id uuid.UUID, now time.Time) (bool, error) {
+	query := `
+		UPDATE notification_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = $3, error = NULL, updated_at = $3
+		WHERE id = $1 AND user_id = $2 AND status = 'dead' AND body <> ''
+	`
+	tag, err := r.pool.Exec(ctx, query, id, userID, now)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func scanNotificationDeliveries(rows pgx.Rows) ([]*entities.NotificationDelivery, error) {
+	var deliveries []*entities.NotificationDelivery
+	for rows.Next() {
+		var d entities.NotificationDelivery
+		if err := rows.Scan(
+			&d.ID, &d.UserID, &d.ChannelType, &d.AlertID, &d.Title, &d.Body, &d.DeepLink, &d.Severity, &d.Digest,
+			&d.Status, &d.Attempts, &d.NextAttemptAt, &d.Error, &d.SentAt, &d.CreatedAt, &d.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
+
+// --- NotificationPreference ---
+
+type NotificationPreferenceRepositoryImpl struct {
+	pool *pgxpool.Pool
+}
+
+func NewNotificationPreferenceRepository(pool *pgxpool.Pool) repositories.NotificationPreferenceRepository {
+	return &NotificationPreferenceRepositoryImpl{pool: pool}
+}
+
+// GetByUser reads the timezone from the user's profile, so a user without a
+// preferences row still gets the defaults in their own timezone.
+func (r *NotificationPreferenceRepositoryImpl) GetByUser(ctx context.Context, userID uuid.UUID) (*entities.NotificationPreference, error) {
+	query := `
+		SELECT u.timezone, np.user_id IS NOT NULL, COALESCE(np.quiet_hours_enabled, false),
+			COALESCE(np.quiet_start_minute, 0), COALESCE(np.quiet_end_minute, 0),
+			COALESCE(np.urgent_bypass_quiet, false), COALESCE(np.digest_enabled, false),
+			COALESCE(np.digest_interval_minutes, 0), np.updated_at
+		FROM users u
+		LEFT JOIN notification_preferences np ON np.user_id = u.id
+		WHERE u.id = $1
+	`
+	var (
+		timezone  string
+		stored    bool
+		row       entities.NotificationPreference
+		updatedAt *time.Time
+	)
+	err := r.pool.QueryRow(ctx, query, userID).Scan(
+		&timezone, &stored, &row.QuietHoursEnabled, &row.QuietStartMinute, &row.QuietEndMinute,
+		&row.UrgentBypassQuiet, &row.DigestEnabled, &row.DigestIntervalMinutes, &updatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	p := entities.DefaultNotificationPreference(userID)
+	if stored {
+		row.UserID = userID
+		if updatedAt != nil {
+			row.UpdatedAt = *updatedAt
+		}
+		p = &row
+	}
+	p.Timezone = timezone
+	return p, nil
+}
+
+// Upsert stores the quiet-hours and digest settings. A non-empty Timezone is
+// saved on the user's profile, which is where every timezone is kept.
+func (r *NotificationPreferenceRepositoryImpl) Upsert(ctx context.Context, p *entities.NotificationPreference) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO notification_preferences (user_id, quiet_hours_enabled, quiet_start_minute, quiet_end_minute,
+			urgent_bypass_quiet, digest_enabled, digest_interval_minutes, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id) DO UPDATE SET
+			quiet_hours_enabled = EXCLUDED.quiet_hours_enabled,
+			quiet_start_minute = EXCLUDED.quiet_start_minute,
+			quiet_end_minute = EXCLUDED.quiet_end_minute,
+			urgent_bypass_quiet = EXCLUDED.urgent_bypass_quiet,
+			digest_enabled = EXCLUDED.digest_enabled,
+			digest_interval_minutes = EXCLUDED.digest_interval_minutes,
+			updated_at = EXCLUDED.updated_at
+	`
+	if _, err := tx.Exec(ctx, query,
+		p.UserID, p.QuietHoursEnabled, p.QuietStartMinute, p.QuietEndMinute,
+		p.UrgentBypassQuiet, p.DigestEnabled, p.DigestIntervalMinutes, p.UpdatedAt); err != nil {
+		return err
+	}
+	if p.Timezone != "" {
+		if _, err := tx.Exec(ctx, `
+			UPDATE users SET timezone = $2, timezone_set_at = $3, updated_at = $3
+			WHERE id = $1
+		`, p.UserID, p.Timezone, p.UpdatedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// --- TelegramReplyPrompt ---
+
+type TelegramReplyPromptRepositoryImpl struct {
+	pool *pgxpool.Pool
+}
+
+func NewTelegramReplyPromptRepository(pool *pgxpool.Pool) repositories.TelegramReplyPromptRepository {
+	return &TelegramReplyPromptRepositoryImpl{pool: pool}
+}
+
+func (r *TelegramReplyPromptRepositoryImpl) Create(ctx context.Context, p *entities.TelegramReplyPrompt) error {
+	query := `
+		INSERT INTO telegram_reply_prompts (chat_id, message_id, user_id, alert_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (chat_id, message_id) DO NOTHING
+	`
+	_, err := r.pool.Exec(ctx, query, p.ChatID, p.MessageID, p.UserID, p.AlertID, p.ExpiresAt, p.CreatedAt)
+	return err
+}
+
+func (r *TelegramReplyPromptRepositoryImpl) FindValid(ctx context.Context, chatID, messageID int64, now time.Time) (*entities.TelegramReplyPrompt, error) {
+	query := `
+		SELECT chat_id, message_id, user_id, alert_id, expires_at, created_at
+		FROM telegram_reply_prompts
+		WHERE chat_id = $1 AND message_id = $2 AND expires_at > $3
+	`
+	var p entities.TelegramReplyPrompt
+	err := r.pool.QueryRow(ctx, query, chatID, messageID, now).Scan(
+		&p.ChatID, &p.MessageID, &p.UserID, &p.AlertID, &p.ExpiresAt, &p.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &p, nil
+}
@@ -20,12 +20,12 @@ func NewOutcomeRepository(pool *pgxpool.Pool) repositories.OutcomeRepository {
 
 func (r *OutcomeRepositoryImpl) CreateIfNotExists(ctx context.Context, outcome *entities.Outcome) (bool, error) {
 	query := `
-        INSERT INTO outcomes (id, bubble_id, period, reference_price, outcome_price, pnl_percent, calculated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        INSERT INTO outcomes (id, bubble_id, period, reference_price, outcome_price, pnl_percent, price_source, target_time, calculated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
         ON CONFLICT (bubble_id, period) DO NOTHING
     `
 	result, err := r.pool.Exec(ctx, query,
-		outcome.ID, outcome.BubbleID, outcome.Period, outcome.ReferencePrice, outcome.OutcomePrice, outcome.PnLPercent, outcome.CalculatedAt)
+		outcome.ID, outcome.BubbleID, outcome.Period, outcome.ReferencePrice, outcome.OutcomePrice, outcome.PnLPercent, outcome.PriceSource, outcome.TargetTime, outcome.CalculatedAt)
 	if err != nil {
 		return false, err
 	}
@@ -34,7 +34,7 @@ func (r *OutcomeRepositoryImpl) CreateIfNotExists(ctx context.Context, outcome *
 
 func (r *OutcomeRepositoryImpl) ListByBubble(ctx context.Context, bubbleID uuid.UUID) ([]*entities.Outcome, error) {
 	query := `
-        SELECT id, bubble_id, period, reference_price, outcome_price, pnl_percent, calculated_at
+        SELECT id, bubble_id, period, reference_price, outcome_price, pnl_percent, price_source, target_time, calculated_at
         FROM outcomes
         WHERE bubble_id = $1
         ORDER BY calculated_at DESC
@@ -49,7 +49,7 @@ func (r *OutcomeRepositoryImpl) ListByBubble(ctx context.Context, bubbleID uuid.
 	for rows.Next() {
 		var outcome entities.Outcome
 		if err := rows.Scan(
-			&outcome.ID, &outcome.BubbleID, &outcome.Period, &outcome.ReferencePrice, &outcome.OutcomePrice, &outcome.PnLPercent, &outcome.CalculatedAt); err != nil {
+			&outcome.ID, &outcome.BubbleID, &outcome.Period, &outcome.ReferencePrice, &outcome.OutcomePrice, &outcome.PnLPercent, &outcome.PriceSource, &outcome.TargetTime, &outcome.CalculatedAt); err != nil {
 			return nil, err
 		}
 		outcomes = append(outcomes, &outcome)
@@ -64,7 +64,7 @@ func (r *OutcomeRepositoryImpl) ListByBubble(ctx context.Context, bubbleID uuid.
 
 func (r *OutcomeRepositoryImpl) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.Outcome, error) {
 	query := `
-        SELECT o.id, o.bubble_id, o.period, o.reference_price, o.outcome_price, o.pnl_percent, o.calculated_at
+        SELECT o.id, o.bubble_id, o.period, o.reference_price, o.outcome_price, o.pnl_percent, o.price_source, o.target_time, o.calculated_at
         FROM outcomes o
         JOIN bubbles b ON b.id = o.bubble_id
         WHERE b.user_id = $1
@@ -81,7 +81,7 @@ func (r *OutcomeRepositoryImpl) ListByUser(ctx context.Context, userID uuid.UUID
 	for rows.Next() {
 		var outcome entities.Outcome
 		if err := rows.Scan(
-			&outcome.ID, &outcome.BubbleID, &outcome.Period, &outcome.ReferencePrice, &outcome.OutcomePrice, &outcome.PnLPercent, &outcome.CalculatedAt); err != nil {
+			&outcome.ID, &outcome.BubbleID, &outcome.Period, &outcome.ReferencePrice, &outcome.OutcomePrice, &outcome.PnLPercent, &outcome.PriceSource, &outcome.TargetTime, &outcome.CalculatedAt); err != nil {
 			return nil, err
 		}
 		outcomes = append(outcomes, &outcome)
@@ -96,7 +96,7 @@ func (r *OutcomeRepositoryImpl) ListByUser(ctx context.Context, userID uuid.UUID
 
 func (r *OutcomeRepositoryImpl) ListPending(ctx context.Context, period string, cutoff time.Time, limit int) ([]*repositories.PendingOutcomeBubble, error) {
 	query := `
-        SELECT b.id, b.symbol, b.candle_time, b.price
+        SELECT b.id, b.symbol, b.candle_time, b.price, COALESCE(b.asset_class, ''), COALESCE(b.venue_name, ''), NULL::timestamptz
         FROM bubbles b
         WHERE b.candle_time <= $1
           AND NOT EXISTS (
@@ -106,7 +106,50 @@ func (r *OutcomeRepositoryImpl) ListPending(ctx context.Context, period string,
         ORDER BY b.candle_time ASC
         LIMIT $3
     `
-	rows, err := r.pool.Query(ctx, query, cutoff, period, limit)
+	return r.queryPending(ctx, query, cutoff, period, limit)
+}
+
+func (r *OutcomeRepositoryImpl) ListPendingForHorizon(ctx context.Context, period string, cutoff time.Time, limit int) ([]*repositories.PendingOutcomeBubble, error) {
+	query := `
+        SELECT b.id, b.symbol, b.candle_time, b.price, COALESCE(b.asset_class, ''), COALESCE(b.venue_name, ''), NULL::timestamptz
+        FROM bubbles b
+        JOIN user_outcome_horizons h ON h.user_id = b.user_id AND h.period = $2
+        WHERE b.candle_time <= $1
+          AND NOT EXISTS (
+            SELECT 1 FROM outcomes o
+            WHERE o.bubble_id = b.id AND o.period = $2
+          )
+        ORDER BY b.candle_time ASC
+        LIMIT $3
+    `
+	return r.queryPending(ctx, query, cutoff, period, limit)
+}
+
+func (r *OutcomeRepositoryImpl) ListPendingUntilNext(ctx context.Context, cutoff time.Time, limit int) ([]*repositories.PendingOutcomeBubble, error) {
+	query := `
+        SELECT b.id, b.symbol, b.candle_time, b.price, COALESCE(b.asset_class, ''), COALESCE(b.venue_name, ''), nb.candle_time
+        FROM bubbles b
+        JOIN user_outcome_horizons h ON h.user_id = b.user_id AND h.period = 'next_bubble'
+        JOIN LATERAL (
+            SELECT n.candle_time
+            FROM bubbles n
+            WHERE n.user_id = b.user_id AND n.symbol = b.symbol AND n.candle_time > b.candle_time
+            ORDER BY n.candle_time ASC
+            LIMIT 1
+        ) nb ON TRUE
+        WHERE nb.candle_time <= $1
+          AND NOT EXISTS (
+            SELECT 1 FROM outcomes o
+            WHERE o.bubble_id = b.id AND o.period = 'next_bubble'
+          )
+        ORDER BY b.candle_time ASC
+        LIMIT $2
+    `
+	return r.queryPending(ctx, query, cutoff, limit)
+}
+
+func (r *OutcomeRepositoryImpl) queryPending(ctx context.Context, query string, args ...interface{}) ([]*repositories.PendingOutcomeBubble, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -115,7 +158,7 @@ func (r *OutcomeRepositoryImpl) ListPending(ctx context.Context, period string,
 	var pending []*repositories.PendingOutcomeBubble
 	for rows.Next() {
 		var item repositories.PendingOutcomeBubble
-		if err := rows.Scan(&item.BubbleID, &item.Symbol, &item.CandleTime, &item.Price); err != nil {
+		if err := rows.Scan(&item.BubbleID, &item.Symbol, &item.CandleTime, &item.Price, &item.AssetClass, &item.VenueName, &item.TargetTime); err != nil {
 			return nil, err
 		}
 		pending = append(pending, &item)
@@ -130,7 +173,7 @@ func (r *OutcomeRepositoryImpl) ListPending(ctx context.Context, period string,
 
 func (r *OutcomeRepositoryImpl) ListRecentWithoutAccuracy(ctx context.Context, since time.Time, limit int) ([]*entities.Outcome, error) {
 	query := `
-		SELECT o.id, o.bubble_id, o.period, o.reference_price, o.outcome_price, o.pnl_percent, o.calculated_at
+		SELECT o.id, o.bubble_id, o.period, o.reference_price, o.outcome_price, o.pnl_percent, o.price_source, o.target_time, o.calculated_at
 		FROM outcomes o
 		WHERE o.calculated_at >= $1
 		  AND NOT EXISTS (
@@ -155,7 +198,7 @@ func (r *OutcomeRepositoryImpl) ListRecentWithoutAccuracy(ctx context.Context, s
 		var outcome entities.Outcome
 		if err := rows.Scan(
 			&outcome.ID, &outcome.BubbleID, &outcome.Period, &outcome.ReferencePrice,
-			&outcome.OutcomePrice, &outcome.PnLPercent, &outcome.CalculatedAt); err != nil {
+			&outcome.OutcomePrice, &outcome.PnLPercent, &outcome.PriceSource, &outcome.TargetTime, &outcome.CalculatedAt); err != nil {
 			return nil, err
 		}
 		outcomes = append(outcomes, &outcome)
@@ -163,3 +206,60 @@ func (r *OutcomeRepositoryImpl) ListRecentWithoutAccuracy(ctx context.Context, s
 
 	return outcomes, rows.Err()
 }
+
+func (r *OutcomeRepositoryImpl) ListCustomHorizons(ctx context.Context) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `SELECT DISTINCT period FROM user_outcome_horizons ORDER BY period`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	periods := make([]string, 0)
+	for rows.Next() {
+		var period string
+		if err := rows.Scan(&period); err != nil {
+			return nil, err
+		}
+		periods = append(periods, period)
+	}
+	return periods, rows.Err()
+}
+
+func (r *OutcomeRepositoryImpl) ListUserHorizons(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `SELECT period FROM user_outcome_horizons WHERE user_id = $1 ORDER BY created_at, period`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	periods := make([]string, 0)
+	for rows.Next() {
+		var period string
+		if err := rows.Scan(&period); err != nil {
+			return nil, err
+		}
+		periods = append(periods, period)
+	}
+	return periods, rows.Err()
+}
+
+func (r *OutcomeRepositoryImpl) ReplaceUserHorizons(ctx context.Context, userID uuid.UUID, periods []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM user_outcome_horizons WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for i, period := range periods {
+		// Stagger created_at so the list reads back in the order it was sent.
+		if _, err := tx.Exec(ctx, `INSERT INTO user_outcome_horizons (user_id, period, created_at) VALUES ($1, $2, $3)`,
+			userID, period, now.Add(time.Duration(i)*time.Microsecond)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
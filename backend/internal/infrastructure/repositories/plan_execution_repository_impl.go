@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+type PlanExecutionRepositoryImpl struct {
+	pool *pgxpool.Pool
+}
+
+func NewPlanExecutionRepository(pool *pgxpool.Pool) repositories.PlanExecutionRepository {
+	return &PlanExecutionRepositoryImpl{pool: pool}
+}
+
+const planExecutionColumns = `position_id, user_id, symbol_key, status, fill_count, entry_qty::text, entry_price::text,
+	exit_qty::text, exit_price::text, first_fill_at, last_fill_at, stop_at_entry::text,
+	entry_slippage_percent::text, size_deviation_percent::text, stop_status, early_exit, score, evaluated_at, fill_ids`
+
+func scanPlanExecution(row pgx.Row) (*entities.PlanExecution, error) {
+	var e entities.PlanExecution
+	if err := row.Scan(&e.PositionID, &e.UserID, &e.SymbolKey, &e.Status, &e.FillCount, &e.EntryQty, &e.EntryPrice,
+		&e.ExitQty, &e.ExitPrice, &e.FirstFillAt, &e.LastFillAt, &e.StopAtEntry,
+		&e.EntrySlippagePercent, &e.SizeDeviationPercent, &e.StopStatus, &e.EarlyExit, &e.Score, &e.EvaluatedAt, &e.FillIDs); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *PlanExecutionRepositoryImpl) ListPlansToEvaluate(ctx context.Context, closedSince time.Time, limit int) ([]*entities.ManualPosition, error) {
+	query := `
+		SELECT p.id, p.user_id, p.symbol, p.asset_class, p.venue, p.position_side, p.size, p.entry_price,
+			p.stop_loss, p.take_profit, p.leverage, p.strategy, p.memo, p.status, p.opened_at, p.closed_at,
+			p.created_at, p.updated_at
+		FROM manual_positions p
+		LEFT JOIN plan_executions e ON e.position_id = p.id
+		WHERE p.status = 'open' OR p.closed_at >= $1
+		ORDER BY e.evaluated_at ASC NULLS FIRST, p.created_at ASC
+		LIMIT $2
+	`
+	rows, err := r.pool.Query(ctx, query, closedSince, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var positions []*entities.ManualPosition
+	for rows.Next() {
+		var item entities.ManualPosition
+		if err := rows.Scan(&item.ID, &item.UserID, &item.Symbol, &item.AssetClass, &item.Venue, &item.PositionSide,
+			&item.Size, &item.EntryPrice, &item.StopLoss, &item.TakeProfit, &item.Leverage, &item.Strategy, &item.Memo,
+			&item.Status, &item.OpenedAt, &item.ClosedAt, &item.CreatedAt, &item.UpdatedAt); err != nil {
+			return nil, err
+		}
+		positions = append(positions, &item)
+	}
+	return positions, rows.Err()
+}
+
+// ListFills reads synced trades and imported trade events. Events written by
+// the trade poller carry the trade id in metadata and are skipped, since the
+// trade itself is already in the result.
+func (r *PlanExecutionRepositoryImpl) ListFills(ctx context.Context, userID uuid.UUID, symbolKey string, from, to time.Time) ([]*repositories.PlanFill, error) {
+	query := `
+		SELECT 'trade' AS source, t.id, lower(t.side), t.quantity::text, t.price::text, t.trade_time
+		FROM trades t
+		WHERE t.user_id = $1
+			AND upper(regexp_replace(t.symbol, '[^A-Za-z0-9]', '', 'g')) = $2
+			AND t.trade_time BETWEEN $3 AND $4
+		UNION ALL
+		SELECT 'trade_event', e.id, e.side, e.qty::text, e.price::text, e.executed_at
+		FROM trade_events e
+		JOIN instruments i ON i.id = e.instrument_id
+		WHERE e.user_id = $1
+			AND upper(i.base_asset || i.quote_asset) = $2
+			AND e.executed_at BETWEEN $3 AND $4
+			AND e.event_type IN ('spot_trade', 'perp_trade', 'dex_swap')
+			AND e.side IS NOT NULL AND e.qty IS NOT NULL AND e.price IS NOT NULL
+			AND NOT (COALESCE(e.metadata, '{}'::jsonb) ? 'trade_id')
+		ORDER BY 6, 2
+	`
+	rows, err := r.pool.Query(ctx, query, userID, symbolKey, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fills []*repositories.PlanFill
+	for rows.Next() {
+		var fill repositories.PlanFill
+		if err := rows.Scan(&fill.Source, &fill.ID, &fill.Side, &fill.Qty, &fill.Price, &fill.ExecutedAt); err != nil {
+			return nil, err
+		}
+		fills = append(fills, &fill)
+	}
+	return fills, rows.Err()
+}
+
+func (r *PlanExecutionRepositoryImpl) ListClaimedFillIDs(ctx context.Context, userID uuid.UUID, symbolKey string, excludePositionID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT DISTINCT unnest(fill_ids)
+		FROM plan_executions
+		WHERE user_id = $1 AND symbol_key = $2 AND position_id <> $3
+	`
+	rows, err := r.pool.Query(ctx, query, userID, symbolKey, excludePositionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *PlanExecutionRepositoryImpl) Upsert(ctx context.Context, e *entities.PlanExecution) error {
+	fillIDs := e.FillIDs
+	if fillIDs == nil {
+		fillIDs = []uuid.UUID{}
+	}
+	query := `
+		INSERT INTO plan_executions (
+			position_id, user_id, symbol_key, status, fill_count, entry_qty, entry_price, exit_qty, exit_price,
+			first_fill_at, last_fill_at, stop_at_entry, entry_slippage_percent, size_deviation_percent,
+			stop_status, early_exit, score, evaluated_at, fill_ids
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+		ON CONFLICT (position_id) DO UPDATE SET
+			symbol_key = EXCLUDED.symbol_key,
+			status = EXCLUDED.status,
+			fill_count = EXCLUDED.fill_count,
+			entry_qty = EXCLUDED.entry_qty,
+			entry_price = EXCLUDED.entry_price,
+			exit_qty = EXCLUDED.exit_qty,
+			exit_price = EXCLUDED.exit_price,
+			first_fill_at = EXCLUDED.first_fill_at,
+			last_fill_at = EXCLUDED.last_fill_at,
+			stop_at_entry = EXCLUDED.stop_at_entry,
+			entry_slippage_percent = EXCLUDED.entry_slippage_percent,
+			size_deviation_percent = EXCLUDED.size_deviation_percent,
+			stop_status = EXCLUDED.stop_status,
+			early_exit = EXCLUDED.early_exit,
+			score = EXCLUDED.score,
+			evaluated_at = EXCLUDED.evaluated_at,
+			fill_ids = EXCLUDED.fill_ids
+	`
+	_, err := r.pool.Exec(ctx, query,
+		e.PositionID, e.UserID, e.SymbolKey, e.Status, e.FillCount, e.EntryQty, e.EntryPrice, e.ExitQty, e.ExitPrice,
+		e.FirstFillAt, e.LastFillAt, e.StopAtEntry, e.EntrySlippagePercent, e.SizeDeviationPercent,
+		e.StopStatus, e.EarlyExit, e.Score, e.EvaluatedAt, fillIDs)
+	return err
+}
+
+func (r *PlanExecutionRepositoryImpl) GetByPosition(ctx context.Context, positionID uuid.UUID) (*entities.PlanExecution, error) {
+	query := `SELECT ` + planExecutionColumns + ` FROM plan_executions WHERE position_id = $1`
+	execution, err := scanPlanExecution(r.pool.QueryRow(ctx, query, positionID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return execution, nil
+}
+
+func (r *PlanExecutionRepositoryImpl) ListByUser(ctx context.Context, userID uuid.UUID, from, to time.Time, includePending bool) ([]*entities.PlanExecution, error) {
+	query := `
+		SELECT ` + planExecutionColumns + `
+		FROM plan_executions
+		WHERE user_id = $1
+			AND ((first_fill_at BETWEEN $2 AND $3) OR ($4 AND status = 'pending'))
+		ORDER BY first_fill_at DESC NULLS LAST
+	`
+	rows, err := r.pool.Query(ctx, query, userID, from, to, includePending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var executions []*entities.PlanExecution
+	for rows.Next() {
+		execution, err := scanPlanExecution(rows)
+		if err != nil {
+			return nil, err
+		}
+		executions = append(executions, execution)
+	}
+	return executions, rows.Err()
+}
@@ -0,0 +1,190 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+type PlaybookRepositoryImpl struct {
+	pool *pgxpool.Pool
+}
+
+func NewPlaybookRepository(pool *pgxpool.Pool) repositories.PlaybookRepository {
+	return &PlaybookRepositoryImpl{pool: pool}
+}
+
+const playbookSetupColumns = `id, user_id, name, description, direction, entry_criteria, invalidation,
+	target_rr, risk_percent, checklist, archived_at, created_at, updated_at`
+
+func scanPlaybookSetup(row pgx.Row) (*entities.PlaybookSetup, error) {
+	var setup entities.PlaybookSetup
+	if err := row.Scan(&setup.ID, &setup.UserID, &setup.Name, &setup.Description, &setup.Direction,
+		&setup.EntryCriteria, &setup.Invalidation, &setup.TargetRR, &setup.RiskPercent, &setup.Checklist,
+		&setup.ArchivedAt, &setup.CreatedAt, &setup.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if setup.Checklist == nil {
+		setup.Checklist = []string{}
+	}
+	return &setup, nil
+}
+
+func (r *PlaybookRepositoryImpl) Create(ctx context.Context, setup *entities.PlaybookSetup) error {
+	setup.ID = uuid.New()
+	setup.CreatedAt = time.Now().UTC()
+	setup.UpdatedAt = setup.CreatedAt
+
+	query := `
+		INSERT INTO playbook_setups (` + playbookSetupColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+	_, err := r.pool.Exec(ctx, query,
+		setup.ID, setup.UserID, setup.Name, setup.Description, setup.Direction, setup.EntryCriteria,
+		setup.Invalidation, setup.TargetRR, setup.RiskPercent, setup.Checklist, setup.ArchivedAt,
+		setup.CreatedAt, setup.UpdatedAt)
+	return err
+}
+
+func (r *PlaybookRepositoryImpl) GetByID(ctx context.Context, id, userID uuid.UUID) (*entities.PlaybookSetup, error) {
+	query := `SELECT ` + playbookSetupColumns + ` FROM playbook_setups WHERE id = $1 AND user_id = $2`
+	setup, err := scanPlaybookSetup(r.pool.QueryRow(ctx, query, id, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return setup, nil
+}
+
+func (r *PlaybookRepositoryImpl) List(ctx context.Context, userID uuid.UUID, includeArchived bool) ([]*entities.PlaybookSetup, error) {
+	query := `SELECT ` + playbookSetupColumns + ` FROM playbook_setups WHERE user_id = $1`
+	if !includeArchived {
+		query += ` AND archived_at IS NULL`
+	}
+	query += ` ORDER BY lower(name)`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	setups := make([]*entities.PlaybookSetup, 0)
+	for rows.Next() {
+		setup, err := scanPlaybookSetup(rows)
+		if err != nil {
+			return nil, err
+		}
+		setups = append(setups, setup)
+	}
+	return setups, rows.Err()
+}
+
+func (r *PlaybookRepositoryImpl) Update(ctx context.Context, setup *entities.PlaybookSetup) error {
+	setup.UpdatedAt = time.Now().UTC()
+	query := `
+		UPDATE playbook_setups
+		SET name = $3, description = $4, direction = $5, entry_criteria = $6, invalidation = $7,
+			target_rr = $8, risk_percent = $9, checklist = $10, archived_at = $11, updated_at = $12
+		WHERE id = $1 AND user_id = $2
+	`
+	_, err := r.pool.Exec(ctx, query,
+		setup.ID, setup.UserID, setup.Name, setup.Description, setup.Direction, setup.EntryCriteria,
+		setup.Invalidation, setup.TargetRR, setup.RiskPercent, setup.Checklist, setup.ArchivedAt, setup.UpdatedAt)
+	return err
+}
+
+func (r *PlaybookRepositoryImpl) Delete(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM playbook_setups WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+var playbookSubjectTables = map[entities.PlaybookSubject]string{
+	entities.PlaybookSubjectBubble:         "bubbles",
+	entities.PlaybookSubjectTrade:          "trades",
+	entities.PlaybookSubjectManualPosition: "manual_positions",
+}
+
+func (r *PlaybookRepositoryImpl) SetLink(ctx context.Context, userID uuid.UUID, subject entities.PlaybookSubject, subjectID uuid.UUID, setupID *uuid.UUID, checked []string) (bool, error) {
+	table, ok := playbookSubjectTables[subject]
+	if !ok {
+		return false, fmt.Errorf("unknown playbook subject %q", subject)
+	}
+	if setupID == nil {
+		checked = nil
+	}
+	query := fmt.Sprintf(`UPDATE %s SET setup_id = $3, setup_checked = $4 WHERE id = $1 AND user_id = $2`, table)
+	tag, err := r.pool.Exec(ctx, query, subjectID, userID, setupID, checked)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListSamples binds $1 user, $2 setup (NULL = any), $3 since, $4 symbol,
+// $5 tag, $6 asset class, $7 venue and $8 the bubble outcome period in every
+// part of the union; an empty string turns a filter off.
+func (r *PlaybookRepositoryImpl) ListSamples(ctx context.Context, userID uuid.UUID, filter repositories.PlaybookSampleFilter) ([]*repositories.PlaybookSample, error) {
+	parts := []string{
+		`SELECT b.setup_id, 'bubble' AS subject, b.id, b.setup_checked, o.pnl_percent::text, NULL::text AS realized_pnl
+		FROM bubbles b
+		LEFT JOIN outcomes o ON o.bubble_id = b.id AND o.period = $8
+		WHERE b.user_id = $1 AND b.setup_id IS NOT NULL
+			AND ($2::uuid IS NULL OR b.setup_id = $2)
+			AND ($3::timestamptz IS NULL OR b.candle_time >= $3)
+			AND ($4 = '' OR b.symbol = $4)
+			AND ($5 = '' OR $5 = ANY(b.tags))
+			AND ($6 = '' OR b.asset_class = $6)
+			AND ($7 = '' OR b.venue_name = $7)`,
+		`SELECT t.setup_id, 'trade', t.id, t.setup_checked, NULL::text, t.realized_pnl::text
+		FROM trades t
+		WHERE t.user_id = $1 AND t.setup_id IS NOT NULL
+			AND ($2::uuid IS NULL OR t.setup_id = $2)
+			AND ($3::timestamptz IS NULL OR t.trade_time >= $3)
+			AND ($4 = '' OR t.symbol = $4)
+			AND $5 = ''
+			AND ($6 = '' OR $6 = 'crypto')
+			AND ($7 = '' OR t.exchange = $7)`,
+		`SELECT mp.setup_id, 'manual_position', mp.id, mp.setup_checked, NULL::text, NULL::text
+		FROM manual_positions mp
+		WHERE mp.user_id = $1 AND mp.setup_id IS NOT NULL
+			AND ($2::uuid IS NULL OR mp.setup_id = $2)
+			AND ($3::timestamptz IS NULL OR COALESCE(mp.opened_at, mp.created_at) >= $3)
+			AND ($4 = '' OR mp.symbol = $4)
+			AND $5 = ''
+			AND ($6 = '' OR mp.asset_class = $6)
+			AND ($7 = '' OR mp.venue = $7)`,
+	}
+	query := strings.Join(parts, "\n\t\tUNION ALL\n\t\t")
+
+	rows, err := r.pool.Query(ctx, query,
+		userID, filter.SetupID, filter.Since, filter.Symbol, filter.Tag, filter.AssetClass, filter.Venue, filter.OutcomePeriod)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []*repositories.PlaybookSample
+	for rows.Next() {
+		var sample repositories.PlaybookSample
+		if err := rows.Scan(&sample.SetupID, &sample.Subject, &sample.SubjectID, &sample.Checked,
+			&sample.PnLPercent, &sample.RealizedPnL); err != nil {
+			return nil, err
+		}
+		samples = append(samples, &sample)
+	}
+	return samples, rows.Err()
+}
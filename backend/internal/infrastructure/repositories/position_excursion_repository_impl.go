@@ -0,0 +1,321 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+type PositionExcursionRepositoryImpl struct {
+	pool *pgxpool.Pool
+}
+
+func NewPositionExcursionRepository(pool *pgxpool.Pool) repositories.PositionExcursionRepository {
+	return &PositionExcursionRepositoryImpl{pool: pool}
+}
+
+// ListPending signs each candidate's plan fills with their ids and the
+// entry and exit prices plan adherence derived from them.
+func (r *PositionExcursionRepositoryImpl) ListPending(ctx context.Context, now time.Time, limit int) ([]*repositories.ExcursionCandidate, error) {
+	query := `
+		WITH candidates AS (
+			SELECT mp.id, mp.user_id, mp.symbol, mp.asset_class, mp.venue, mp.position_side, mp.size, mp.entry_price,
+				mp.stop_loss, mp.take_profit, mp.leverage, mp.strategy, mp.memo, mp.status, mp.opened_at, mp.closed_at,
+				mp.created_at, mp.updated_at,
+				pe.entry_price::text AS fill_entry, pe.exit_price::text AS fill_exit, pe.stop_at_entry::text AS stop_at_entry,
+				md5(COALESCE(array_to_string(pe.fill_ids, ','), '') || '|' || COALESCE(pe.entry_price::text, '')
+					|| '|' || COALESCE(pe.exit_price::text, '')) AS fill_signature,
+				x.position_id AS stored_id, x.status AS stored_status, x.attempts, x.retry_after,
+				x.source_updated_at, x.fill_signature AS stored_signature
+			FROM manual_positions mp
+			LEFT JOIN position_excursions x ON x.position_id = mp.id
+			LEFT JOIN plan_executions pe ON pe.position_id = mp.id AND pe.status = 'closed'
+			WHERE mp.status = 'closed' AND mp.closed_at IS NOT NULL
+		)
+		SELECT id, user_id, symbol, asset_class, venue, position_side, size, entry_price,
+			stop_loss, take_profit, leverage, strategy, memo, status, opened_at, closed_at,
+			created_at, updated_at, fill_entry, fill_exit, stop_at_entry, fill_signature,
+			CASE WHEN stored_status = 'retry' AND source_updated_at >= updated_at AND stored_signature = fill_signature
+				THEN attempts ELSE 0 END
+		FROM candidates
+		WHERE stored_id IS NULL
+			OR source_updated_at < updated_at
+			OR stored_signature IS DISTINCT FROM fill_signature
+			OR (stored_status = 'retry' AND retry_after <= $1)
+		ORDER BY closed_at DESC
+		LIMIT $2
+	`
+	rows, err := r.pool.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []*repositories.ExcursionCandidate
+	for rows.Next() {
+		var item entities.ManualPosition
+		candidate := repositories.ExcursionCandidate{Position: &item}
+		if err := rows.Scan(&item.ID, &item.UserID, &item.Symbol, &item.AssetClass, &item.Venue, &item.PositionSide,
+			&item.Size, &item.EntryPrice, &item.StopLoss, &item.TakeProfit, &item.Leverage, &item.Strategy, &item.Memo,
+			&item.Status, &item.OpenedAt, &item.ClosedAt, &item.CreatedAt, &item.UpdatedAt,
+			&candidate.FillEntry, &candidate.FillExit, &candidate.StopAtEntry, &candidate.FillSignature,
+			&candidate.Attempts); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, &candidate)
+	}
+	return candidates, rows.Err()
+}
+
+func (r *PositionExcursionRepositoryImpl) Upsert(ctx context.Context, x *entities.PositionExcursion) error {
+	query := `
+		INSERT INTO position_excursions (
+			position_id, user_id, status, candle_interval, entry_price, exit_price, stop_price, pnl_percent,
+			mae_percent, mfe_percent, mae_price, mfe_price, r_multiple, mae_r, mfe_r, attempts, retry_after,
+			fill_signature, source_updated_at, computed_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+		ON CONFLICT (position_id) DO UPDATE SET
+			status = EXCLUDED.status,
+			candle_interval = EXCLUDED.candle_interval,
+			entry_price = EXCLUDED.entry_price,
+			exit_price = EXCLUDED.exit_price,
+			stop_price = EXCLUDED.stop_price,
+			pnl_percent = EXCLUDED.pnl_percent,
+			mae_percent = EXCLUDED.mae_percent,
+			mfe_percent = EXCLUDED.mfe_percent,
+			mae_price = EXCLUDED.mae_price,
+			mfe_price = EXCLUDED.mfe_price,
+			r_multiple = EXCLUDED.r_multiple,
+			mae_r = EXCLUDED.mae_r,
+			mfe_r = EXCLUDED.mfe_r,
+			attempts = EXCLUDED.attempts,
+			retry_after = EXCLUDED.retry_after,
+			fill_signature = EXCLUDED.fill_signature,
+			source_updated_at = EXCLUDED.source_updated_at,
+			computed_at = EXCLUDED.computed_at
+	`
+	_, err := r.pool.Exec(ctx, query,
+		x.PositionID, x.UserID, x.Status, x.CandleInterval, x.EntryPrice, x.ExitPrice, x.StopPrice, x.PnLPercent,
+		x.MAEPercent, x.MFEPercent, x.MAEPrice, x.MFEPrice, x.RMultiple, x.MAER, x.MFER, x.Attempts, x.RetryAfter,
+		x.FillSignature, x.SourceUpdatedAt, x.ComputedAt)
+	return err
+}
+
+func (r *PositionExcursionRepositoryImpl) ListByPositions(ctx context.Context, userID uuid.UUID, positionIDs []uuid.UUID) (map[uuid.UUID]*entities.PositionExcursion, error) {
+	result := make(map[uuid.UUID]*entities.PositionExcursion)
+	if len(positionIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT position_id, user_id, status, candle_interval, entry_price::text, exit_price::text, stop_price::text,
+			pnl_percent::text, mae_percent::text, mfe_percent::text, mae_price::text, mfe_price::text,
+			r_multiple::text, mae_r::text, mfe_r::text, source_updated_at, computed_at
+		FROM position_excursions
+		WHERE user_id = $1 AND position_id = ANY($2)
+	`
+	rows, err := r.pool.Query(ctx, query, userID, positionIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var x entities.PositionExcursion
+		if err := rows.Scan(&x.PositionID, &x.UserID, &x.Status, &x.CandleInterval, &x.EntryPrice, &x.ExitPrice,
+			&x.StopPrice, &x.PnLPercent, &x.MAEPercent, &x.MFEPercent, &x.MAEPrice, &x.MFEPrice,
+			&x.RMultiple, &x.MAER, &x.MFER, &x.SourceUpdatedAt, &x.ComputedAt); err != nil {
+			return nil, err
+		}
+		result[x.PositionID] = &x
+	}
+	return result, rows.Err()
+}
+
+func (r *PositionExcursionRepositoryImpl) ListRoundTripUsers(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT t.user_id
+		FROM (SELECT DISTINCT user_id FROM trades) t
+		LEFT JOIN trade_round_trip_scans s ON s.user_id = t.user_id
+		ORDER BY s.scanned_at ASC NULLS FIRST
+		LIMIT $1
+	`
+	rows, err := r.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
+}
+
+func (r *PositionExcursionRepositoryImpl) ListRoundTripFills(ctx context.Context, userID uuid.UUID) ([]*repositories.RoundTripFill, error) {
+	query := `
+		SELECT id, exchange, symbol, lower(side), quantity::text, price::text, trade_time
+		FROM trades
+		WHERE user_id = $1
+		ORDER BY exchange, symbol, trade_time, binance_trade_id
+	`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fills []*repositories.RoundTripFill
+	for rows.Next() {
+		var fill repositories.RoundTripFill
+		if err := rows.Scan(&fill.ID, &fill.Exchange, &fill.Symbol, &fill.Side, &fill.Qty, &fill.Price, &fill.ExecutedAt); err != nil {
+			return nil, err
+		}
+		fills = append(fills, &fill)
+	}
+	return fills, rows.Err()
+}
+
+func (r *PositionExcursionRepositoryImpl) ReplaceRoundTrips(ctx context.Context, userID uuid.UUID, trips []*entities.TradeRoundTrip, scannedAt time.Time) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	upsert := `
+		INSERT INTO trade_round_trips (
+			id, user_id, exchange, symbol, position_side, opened_at, closed_at, qty, entry_price, exit_price,
+			trade_ids, fill_signature, status
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, 'pending')
+		ON CONFLICT (user_id, exchange, symbol, opened_at) DO UPDATE SET
+			position_side = EXCLUDED.position_side,
+			closed_at = EXCLUDED.closed_at,
+			qty = EXCLUDED.qty,
+			entry_price = EXCLUDED.entry_price,
+			exit_price = EXCLUDED.exit_price,
+			trade_ids = EXCLUDED.trade_ids,
+			fill_signature = EXCLUDED.fill_signature,
+			status = 'pending',
+			attempts = 0,
+			retry_after = NULL,
+			candle_interval = NULL,
+			pnl_percent = NULL,
+			mae_percent = NULL,
+			mfe_percent = NULL,
+			mae_price = NULL,
+			mfe_price = NULL,
+			computed_at = NULL
+		WHERE trade_round_trips.fill_signature <> EXCLUDED.fill_signature
+	`
+	signatures := make([]string, 0, len(trips))
+	for _, trip := range trips {
+		if _, err := tx.Exec(ctx, upsert,
+			trip.ID, userID, trip.Exchange, trip.Symbol, trip.PositionSide, trip.OpenedAt, trip.ClosedAt,
+			trip.Qty, trip.EntryPrice, trip.ExitPrice, trip.TradeIDs, trip.FillSignature); err != nil {
+			return err
+		}
+		signatures = append(signatures, trip.FillSignature)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM trade_round_trips
+		WHERE user_id = $1 AND NOT (fill_signature = ANY($2))
+	`, userID, signatures); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO trade_round_trip_scans (user_id, scanned_at) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET scanned_at = EXCLUDED.scanned_at
+	`, userID, scannedAt); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+const roundTripColumns = `id, user_id, exchange, symbol, position_side, opened_at, closed_at, qty::text, entry_price::text,
+	exit_price::text, trade_ids, fill_signature, status, attempts, retry_after, candle_interval, pnl_percent::text,
+	mae_percent::text, mfe_percent::text, mae_price::text, mfe_price::text, computed_at`
+
+func scanRoundTrip(row pgx.Row) (*entities.TradeRoundTrip, error) {
+	var trip entities.TradeRoundTrip
+	var x entities.PositionExcursion
+	var computedAt *time.Time
+	if err := row.Scan(&trip.ID, &trip.UserID, &trip.Exchange, &trip.Symbol, &trip.PositionSide, &trip.OpenedAt,
+		&trip.ClosedAt, &trip.Qty, &trip.EntryPrice, &trip.ExitPrice, &trip.TradeIDs, &trip.FillSignature,
+		&x.Status, &x.Attempts, &x.RetryAfter, &x.CandleInterval, &x.PnLPercent, &x.MAEPercent, &x.MFEPercent,
+		&x.MAEPrice, &x.MFEPrice, &computedAt); err != nil {
+		return nil, err
+	}
+	x.PositionID = trip.ID
+	x.UserID = trip.UserID
+	x.EntryPrice = &trip.EntryPrice
+	x.ExitPrice = &trip.ExitPrice
+	if computedAt != nil {
+		x.ComputedAt = *computedAt
+	}
+	trip.Excursion = &x
+	return &trip, nil
+}
+
+func (r *PositionExcursionRepositoryImpl) queryRoundTrips(ctx context.Context, query string, args ...interface{}) ([]*entities.TradeRoundTrip, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trips []*entities.TradeRoundTrip
+	for rows.Next() {
+		trip, err := scanRoundTrip(rows)
+		if err != nil {
+			return nil, err
+		}
+		trips = append(trips, trip)
+	}
+	return trips, rows.Err()
+}
+
+func (r *PositionExcursionRepositoryImpl) ListPendingRoundTrips(ctx context.Context, now time.Time, limit int) ([]*entities.TradeRoundTrip, error) {
+	query := `
+		SELECT ` + roundTripColumns + `
+		FROM trade_round_trips
+		WHERE status = 'pending' OR (status = 'retry' AND retry_after <= $1)
+		ORDER BY closed_at DESC
+		LIMIT $2
+	`
+	return r.queryRoundTrips(ctx, query, now, limit)
+}
+
+func (r *PositionExcursionRepositoryImpl) SaveRoundTripExcursion(ctx context.Context, tripID uuid.UUID, x *entities.PositionExcursion) error {
+	query := `
+		UPDATE trade_round_trips SET
+			status = $2, attempts = $3, retry_after = $4, candle_interval = $5, pnl_percent = $6,
+			mae_percent = $7, mfe_percent = $8, mae_price = $9, mfe_price = $10, computed_at = $11
+		WHERE id = $1
+	`
+	_, err := r.pool.Exec(ctx, query, tripID, x.Status, x.Attempts, x.RetryAfter, x.CandleInterval, x.PnLPercent,
+		x.MAEPercent, x.MFEPercent, x.MAEPrice, x.MFEPrice, x.ComputedAt)
+	return err
+}
+
+func (r *PositionExcursionRepositoryImpl) ListRoundTrips(ctx context.Context, userID uuid.UUID, from, to time.Time, limit int) ([]*entities.TradeRoundTrip, error) {
+	query := `
+		SELECT ` + roundTripColumns + `
+		FROM trade_round_trips
+		WHERE user_id = $1 AND closed_at BETWEEN $2 AND $3
+		ORDER BY closed_at DESC
+		LIMIT $4
+	`
+	return r.queryRoundTrips(ctx, query, userID, from, to, limit)
+}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+type ReviewTemplateRepositoryImpl struct {
+	pool *pgxpool.Pool
+}
+
+func NewReviewTemplateRepository(pool *pgxpool.Pool) repositories.ReviewTemplateRepository {
+	return &ReviewTemplateRepositoryImpl{pool: pool}
+}
+
+const reviewTemplateColumns = `t.id, t.user_id, t.cadence, t.version, t.name, t.questions, t.created_at`
+
+func scanReviewTemplate(row pgx.Row) (*entities.ReviewQuestionTemplate, error) {
+	var t entities.ReviewQuestionTemplate
+	var questions []byte
+	if err := row.Scan(&t.ID, &t.UserID, &t.Cadence, &t.Version, &t.Name, &questions, &t.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(questions, &t.Questions); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *ReviewTemplateRepositoryImpl) Create(ctx context.Context, t *entities.ReviewQuestionTemplate) error {
+	questions, err := json.Marshal(t.Questions)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO review_question_templates (id, user_id, cadence, version, name, questions, created_at)
+		SELECT $1, $2, $3, COALESCE(MAX(version), 0) + 1, $4, $5, $6
+		FROM review_question_templates
+		WHERE user_id = $2 AND cadence = $3
+		RETURNING version
+	`
+	return r.pool.QueryRow(ctx, query, t.ID, t.UserID, t.Cadence, t.Name, questions, t.CreatedAt).Scan(&t.Version)
+}
+
+func (r *ReviewTemplateRepositoryImpl) queryOne(ctx context.Context, query string, args ...any) (*entities.ReviewQuestionTemplate, error) {
+	template, err := scanReviewTemplate(r.pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return template, nil
+}
+
+func (r *ReviewTemplateRepositoryImpl) GetByID(ctx context.Context, userID, id uuid.UUID) (*entities.ReviewQuestionTemplate, error) {
+	query := `SELECT ` + reviewTemplateColumns + ` FROM review_question_templates t WHERE t.id = $1 AND t.user_id = $2`
+	return r.queryOne(ctx, query, id, userID)
+}
+
+func (r *ReviewTemplateRepositoryImpl) GetLatest(ctx context.Context, userID uuid.UUID, cadence string) (*entities.ReviewQuestionTemplate, error) {
+	query := `
+		SELECT ` + reviewTemplateColumns + `
+		FROM review_question_templates t
+		WHERE t.user_id = $1 AND t.cadence = $2
+		ORDER BY t.version DESC
+		LIMIT 1
+	`
+	return r.queryOne(ctx, query, userID, cadence)
+}
+
+func (r *ReviewTemplateRepositoryImpl) ListVersions(ctx context.Context, userID uuid.UUID, cadence string) ([]*entities.ReviewQuestionTemplate, error) {
+	query := `
+		SELECT ` + reviewTemplateColumns + `
+		FROM review_question_templates t
+		WHERE t.user_id = $1 AND t.cadence = $2
+		ORDER BY t.version DESC
+	`
+	rows, err := r.pool.Query(ctx, query, userID, cadence)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []*entities.ReviewQuestionTemplate
+	for rows.Next() {
+		template, err := scanReviewTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	return templates, rows.Err()
+}
+
+func (r *ReviewTemplateRepositoryImpl) GetForItem(ctx context.Context, userID, itemID uuid.UUID) (*entities.ReviewQuestionTemplate, error) {
+	query := `
+		SELECT ` + reviewTemplateColumns + `
+		FROM guided_review_items gri
+		JOIN guided_reviews gr ON gr.id = gri.review_id
+		JOIN review_question_templates t ON t.id = gr.template_id
+		WHERE gri.id = $1 AND gr.user_id = $2
+	`
+	return r.queryOne(ctx, query, itemID, userID)
+}
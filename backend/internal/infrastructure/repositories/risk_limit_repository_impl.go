@@ -0,0 +1,313 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+type RiskLimitRepositoryImpl struct {
+	pool *pgxpool.Pool
+}
+
+func NewRiskLimitRepository(pool *pgxpool.Pool) repositories.RiskLimitRepository {
+	return &RiskLimitRepositoryImpl{pool: pool}
+}
+
+func (r *RiskLimitRepositoryImpl) GetRule(ctx context.Context, userID uuid.UUID) (*entities.AlertRule, error) {
+	query := `
+		SELECT id, user_id, name, symbol, rule_type, config, cooldown_minutes,
+		       expiry_minutes, escalate_after_minutes, enabled,
+		       last_triggered_at, last_check_state, created_at, updated_at
+		FROM alert_rules WHERE user_id = $1 AND rule_type = 'risk_limit'
+	`
+	var rule entities.AlertRule
+	err := r.pool.QueryRow(ctx, query, userID).Scan(
+		&rule.ID, &rule.UserID, &rule.Name, &rule.Symbol, &rule.RuleType,
+		&rule.Config, &rule.CooldownMinutes, &rule.ExpiryMinutes, &rule.EscalateAfterMinutes, &rule.Enabled,
+		&rule.LastTriggeredAt, &rule.LastCheckState, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *RiskLimitRepositoryImpl) SaveRule(ctx context.Context, rule *entities.AlertRule) error {
+	query := `
+		INSERT INTO alert_rules (id, user_id, name, symbol, rule_type, config, cooldown_minutes,
+		                         expiry_minutes, escalate_after_minutes, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 'risk_limit', $5, $6, $7, $8, $9, $10, $10)
+		ON CONFLICT (user_id) WHERE rule_type = 'risk_limit'
+		DO UPDATE SET name = EXCLUDED.name, config = EXCLUDED.config,
+			expiry_minutes = EXCLUDED.expiry_minutes, escalate_after_minutes = EXCLUDED.escalate_after_minutes,
+			enabled = EXCLUDED.enabled, updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at
+	`
+	if rule.ID == uuid.Nil {
+		rule.ID = uuid.New()
+	}
+	rule.RuleType = entities.RuleTypeRiskLimit
+	rule.UpdatedAt = time.Now().UTC()
+	return r.pool.QueryRow(ctx, query,
+		rule.ID, rule.UserID, rule.Name, rule.Symbol, rule.Config, rule.CooldownMinutes,
+		rule.ExpiryMinutes, rule.EscalateAfterMinutes, rule.Enabled, rule.UpdatedAt,
+	).Scan(&rule.ID, &rule.CreatedAt)
+}
+
+// ListFills reads synced trades and imported trade events. Realized PnL and
+// leverage of trade events come from their metadata; leverage falls back to
+// the manual position open on the symbol at execution time.
+func (r *RiskLimitRepositoryImpl) ListFills(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*repositories.RiskFill, error) {
+	query := `
+		WITH fills AS (
+			SELECT t.id, COALESCE(NULLIF(t.order_id, ''), t.id::text) AS order_id,
+				CASE WHEN t.exchange LIKE '%futures%' THEN 'perp' ELSE 'spot' END AS market,
+				upper(regexp_replace(t.symbol, '[^A-Za-z0-9]', '', 'g')) AS symbol_key, lower(t.side) AS side,
+				t.quantity::text AS qty, t.price::text AS price,
+				CASE WHEN t.realized_pnl::text ~ '^-?[0-9]+(\.[0-9]+)?$' THEN t.realized_pnl::text END AS realized_pnl,
+				NULL::text AS leverage, t.trade_time AS executed_at
+			FROM trades t
+			WHERE t.user_id = $1 AND t.trade_time >= $2 AND t.trade_time < $3
+			UNION ALL
+			SELECT e.id, COALESCE(NULLIF(e.metadata->>'order_id', ''), e.id::text),
+				CASE WHEN e.event_type = 'perp_trade' THEN 'perp' ELSE 'spot' END,
+				upper(i.base_asset || i.quote_asset), e.side, e.qty::text, e.price::text,
+				CASE WHEN e.metadata->>'realized_pnl' ~ '^-?[0-9]+(\.[0-9]+)?$' THEN e.metadata->>'realized_pnl' END,
+				CASE WHEN e.metadata->>'leverage' ~ '^[0-9]+(\.[0-9]+)?$' THEN e.metadata->>'leverage' END,
+				e.executed_at
+			FROM trade_events e
+			JOIN instruments i ON i.id = e.instrument_id
+			WHERE e.user_id = $1
+				AND e.executed_at >= $2 AND e.executed_at < $3
+				AND e.event_type IN ('spot_trade', 'perp_trade', 'dex_swap')
+				AND e.side IS NOT NULL AND e.qty IS NOT NULL AND e.price IS NOT NULL
+				AND NOT (COALESCE(e.metadata, '{}'::jsonb) ? 'trade_id')
+		)
+		SELECT f.order_id, f.market, f.symbol_key, f.side, f.qty, f.price, f.realized_pnl,
+			COALESCE(f.leverage, (
+				SELECT mp.leverage::text
+				FROM manual_positions mp
+				WHERE mp.user_id = $1
+					AND upper(regexp_replace(mp.symbol, '[^A-Za-z0-9]', '', 'g')) = f.symbol_key
+					AND mp.leverage IS NOT NULL
+					AND mp.opened_at <= f.executed_at
+					AND (mp.closed_at IS NULL OR mp.closed_at >= f.executed_at)
+				ORDER BY mp.opened_at DESC
+				LIMIT 1
+			)),
+			f.executed_at
+		FROM fills f
+		ORDER BY f.executed_at, f.id
+	`
+	rows, err := r.pool.Query(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fills []*repositories.RiskFill
+	for rows.Next() {
+		var fill repositories.RiskFill
+		if err := rows.Scan(&fill.OrderID, &fill.Market, &fill.SymbolKey, &fill.Side, &fill.Qty, &fill.Price, &fill.RealizedPnL,
+			&fill.Leverage, &fill.ExecutedAt); err != nil {
+			return nil, err
+		}
+		fills = append(fills, &fill)
+	}
+	return fills, rows.Err()
+}
+
+func (r *RiskLimitRepositoryImpl) NetPositions(ctx context.Context, userID uuid.UUID, before time.Time) (map[string]string, error) {
+	query := `
+		SELECT market, symbol_key, SUM(CASE WHEN side = 'buy' THEN qty ELSE -qty END)::text
+		FROM (
+			SELECT CASE WHEN t.exchange LIKE '%futures%' THEN 'perp' ELSE 'spot' END AS market,
+				upper(regexp_replace(t.symbol, '[^A-Za-z0-9]', '', 'g')) AS symbol_key, lower(t.side) AS side,
+				t.quantity AS qty
+			FROM trades t
+			WHERE t.user_id = $1 AND t.trade_time < $2
+			UNION ALL
+			SELECT CASE WHEN e.event_type = 'perp_trade' THEN 'perp' ELSE 'spot' END,
+				upper(i.base_asset || i.quote_asset), e.side, e.qty
+			FROM trade_events e
+			JOIN instruments i ON i.id = e.instrument_id
+			WHERE e.user_id = $1
+				AND e.executed_at < $2
+				AND e.event_type IN ('spot_trade', 'perp_trade', 'dex_swap')
+				AND e.side IS NOT NULL AND e.qty IS NOT NULL
+				AND NOT (COALESCE(e.metadata, '{}'::jsonb) ? 'trade_id')
+		) fills
+		GROUP BY market, symbol_key
+	`
+	rows, err := r.pool.Query(ctx, query, userID, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	positions := map[string]string{}
+	for rows.Next() {
+		var market, symbol, net string
+		if err := rows.Scan(&market, &symbol, &net); err != nil {
+			return nil, err
+		}
+		if value, ok := new(big.Rat).SetString(net); ok && value.Sign() != 0 {
+			positions[repositories.RiskPositionKey(market, symbol)] = net
+		}
+	}
+	return positions, rows.Err()
+}
+
+func (r *RiskLimitRepositoryImpl) QueueEvaluation(ctx context.Context, userID uuid.UUID, since, queuedAt time.Time) error {
+	query := `
+		INSERT INTO risk_limit_evaluations (user_id, pending_since, queued_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+			pending_since = LEAST(COALESCE(risk_limit_evaluations.pending_since, EXCLUDED.pending_since), EXCLUDED.pending_since),
+			queued_at = EXCLUDED.queued_at
+	`
+	_, err := r.pool.Exec(ctx, query, userID, since, queuedAt)
+	return err
+}
+
+func (r *RiskLimitRepositoryImpl) ListPendingEvaluations(ctx context.Context, limit int) ([]*repositories.RiskEvaluation, error) {
+	query := `
+		SELECT user_id, pending_since, queued_at
+		FROM risk_limit_evaluations
+		WHERE pending_since IS NOT NULL
+		ORDER BY queued_at ASC
+		LIMIT $1
+	`
+	rows, err := r.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var evaluations []*repositories.RiskEvaluation
+	for rows.Next() {
+		var e repositories.RiskEvaluation
+		if err := rows.Scan(&e.UserID, &e.Since, &e.QueuedAt); err != nil {
+			return nil, err
+		}
+		evaluations = append(evaluations, &e)
+	}
+	return evaluations, rows.Err()
+}
+
+func (r *RiskLimitRepositoryImpl) CompleteEvaluation(ctx context.Context, evaluation *repositories.RiskEvaluation, evaluatedAt time.Time) error {
+	query := `
+		UPDATE risk_limit_evaluations
+		SET pending_since = NULL, evaluated_at = $3
+		WHERE user_id = $1 AND queued_at <= $2
+	`
+	_, err := r.pool.Exec(ctx, query, evaluation.UserID, evaluation.QueuedAt, evaluatedAt)
+	return err
+}
+
+func (r *RiskLimitRepositoryImpl) CreateBreach(ctx context.Context, breach *entities.RiskLimitBreach) (bool, error) {
+	query := `
+		INSERT INTO risk_limit_breaches (
+			id, user_id, limit_type, trade_date, scope, symbol, observed, limit_value, price, triggered_at, created_at
+		) VALUES ($1, $2, $3, $4::date, $5, $6, $7::numeric, $8::numeric, $9::numeric, $10, $11)
+		ON CONFLICT (user_id, limit_type, trade_date, scope) DO NOTHING
+	`
+	if breach.ID == uuid.Nil {
+		breach.ID = uuid.New()
+	}
+	breach.CreatedAt = time.Now().UTC()
+	tag, err := r.pool.Exec(ctx, query,
+		breach.ID, breach.UserID, breach.LimitType, breach.TradeDate, breach.Scope, breach.Symbol,
+		breach.Observed, breach.Limit, breach.Price, breach.TriggeredAt, breach.CreatedAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *RiskLimitRepositoryImpl) SetBreachAlert(ctx context.Context, breachID, alertID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `UPDATE risk_limit_breaches SET alert_id = $1 WHERE id = $2`, alertID, breachID)
+	return err
+}
+
+func (r *RiskLimitRepositoryImpl) ListBreaches(ctx context.Context, userID uuid.UUID, fromDate, toDate string) ([]*entities.RiskLimitBreach, error) {
+	query := `
+		SELECT id, user_id, limit_type, trade_date::text, scope, symbol, observed::text, limit_value::text,
+			price::text, triggered_at, alert_id, created_at
+		FROM risk_limit_breaches
+		WHERE user_id = $1 AND trade_date BETWEEN $2::date AND $3::date
+		ORDER BY triggered_at DESC
+	`
+	rows, err := r.pool.Query(ctx, query, userID, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var breaches []*entities.RiskLimitBreach
+	for rows.Next() {
+		var b entities.RiskLimitBreach
+		if err := rows.Scan(&b.ID, &b.UserID, &b.LimitType, &b.TradeDate, &b.Scope, &b.Symbol, &b.Observed,
+			&b.Limit, &b.Price, &b.TriggeredAt, &b.AlertID, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		breaches = append(breaches, &b)
+	}
+	return breaches, rows.Err()
+}
+
+func (r *RiskLimitRepositoryImpl) UpsertDisciplineScore(ctx context.Context, score *entities.DisciplineScore) error {
+	query := `
+		INSERT INTO discipline_scores (user_id, trade_date, score, trade_count, breached_limits, computed_at)
+		VALUES ($1, $2::date, $3, $4, $5, $6)
+		ON CONFLICT (user_id, trade_date) DO UPDATE SET
+			score = EXCLUDED.score,
+			trade_count = EXCLUDED.trade_count,
+			breached_limits = EXCLUDED.breached_limits,
+			computed_at = EXCLUDED.computed_at
+	`
+	limits, err := json.Marshal(score.BreachedLimits)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx, query, score.UserID, score.TradeDate, score.Score, score.TradeCount, limits, score.ComputedAt)
+	return err
+}
+
+func (r *RiskLimitRepositoryImpl) ListDisciplineScores(ctx context.Context, userID uuid.UUID, fromDate, toDate string) ([]*entities.DisciplineScore, error) {
+	query := `
+		SELECT user_id, trade_date::text, score, trade_count, breached_limits, computed_at
+		FROM discipline_scores
+		WHERE user_id = $1 AND trade_date BETWEEN $2::date AND $3::date
+		ORDER BY trade_date DESC
+	`
+	rows, err := r.pool.Query(ctx, query, userID, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scores []*entities.DisciplineScore
+	for rows.Next() {
+		var s entities.DisciplineScore
+		var limits []byte
+		if err := rows.Scan(&s.UserID, &s.TradeDate, &s.Score, &s.TradeCount, &limits, &s.ComputedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(limits, &s.BreachedLimits); err != nil {
+			return nil, err
+		}
+		scores = append(scores, &s)
+	}
+	return scores, rows.Err()
+}
@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+type SearchRepositoryImpl struct {
+	pool *pgxpool.Pool
+}
+
+func NewSearchRepository(pool *pgxpool.Pool) repositories.SearchRepository {
+	return &SearchRepositoryImpl{pool: pool}
+}
+
+// Every source query binds the same parameters:
+// $1 user, $2 symbol (empty = any), $3 from, $4 to, $5 tags (NULL = any) and
+// $6 the tsquery text, kept inline so the GIN indexes stay usable.
+// Sources without tags drop out when a tag filter is set.
+var searchSourceQueries = map[entities.SearchSource]string{
+	entities.SearchSourceBubble: `
+		SELECT 'bubble' AS source, b.id, NULL::text AS title, coalesce(b.memo, '') AS body,
+			b.symbol, b.id AS bubble_id, NULL::uuid AS review_id, b.tags, b.created_at,
+			ts_rank_cd(b.search_tsv, to_tsquery('simple', $6), 32) AS rank
+		FROM bubbles b
+		WHERE b.user_id = $1 AND b.search_tsv @@ to_tsquery('simple', $6)
+			AND ($2 = '' OR b.symbol = $2)
+			AND ($3::timestamptz IS NULL OR b.created_at >= $3)
+			AND ($4::timestamptz IS NULL OR b.created_at <= $4)
+			AND ($5::text[] IS NULL OR b.tags && $5)`,
+	entities.SearchSourceNote: `
+		SELECT 'note', n.id, n.title, concat_ws(E'\n', n.lesson_learned, n.content),
+			b.symbol, n.bubble_id, NULL::uuid, n.tags, n.created_at,
+			ts_rank_cd(n.search_tsv, to_tsquery('simple', $6), 32)
+		FROM review_notes n
+		LEFT JOIN bubbles b ON b.id = n.bubble_id
+		WHERE n.user_id = $1 AND n.search_tsv @@ to_tsquery('simple', $6)
+			AND ($2 = '' OR b.symbol = $2)
+			AND ($3::timestamptz IS NULL OR n.created_at >= $3)
+			AND ($4::timestamptz IS NULL OR n.created_at <= $4)
+			AND ($5::text[] IS NULL OR n.tags && $5)`,
+	entities.SearchSourceGuidedReview: `
+		SELECT 'guided_review', gi.id, NULL::text, coalesce(gi.memo, ''),
+			gi.symbol, NULL::uuid, gi.review_id, NULL::text[], gi.created_at,
+			ts_rank_cd(gi.search_tsv, to_tsquery('simple', $6), 32)
+		FROM guided_review_items gi
+		JOIN guided_reviews gr ON gr.id = gi.review_id
+		WHERE gr.user_id = $1 AND gi.search_tsv @@ to_tsquery('simple', $6)
+			AND ($2 = '' OR gi.symbol = $2)
+			AND ($3::timestamptz IS NULL OR gi.created_at >= $3)
+			AND ($4::timestamptz IS NULL OR gi.created_at <= $4)
+			AND $5::text[] IS NULL`,
+	entities.SearchSourceAIOpinion: `
+		SELECT 'ai_opinion', o.id, o.provider || ' · ' || o.model, o.response,
+			b.symbol, o.bubble_id, NULL::uuid, b.tags, o.created_at,
+			ts_rank_cd(o.search_tsv, to_tsquery('simple', $6), 32)
+		FROM ai_opinions o
+		JOIN bubbles b ON b.id = o.bubble_id
+		WHERE b.user_id = $1 AND o.search_tsv @@ to_tsquery('simple', $6)
+			AND ($2 = '' OR b.symbol = $2)
+			AND ($3::timestamptz IS NULL OR o.created_at >= $3)
+			AND ($4::timestamptz IS NULL OR o.created_at <= $4)
+			AND ($5::text[] IS NULL OR b.tags && $5)`,
+}
+
+func (r *SearchRepositoryImpl) Search(ctx context.Context, userID uuid.UUID, filter repositories.SearchFilter) ([]*entities.SearchHit, int, error) {
+	parts := make([]string, 0, len(filter.Sources))
+	for _, source := range filter.Sources {
+		part, ok := searchSourceQueries[source]
+		if !ok {
+			return nil, 0, fmt.Errorf("unknown search source %q", source)
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return nil, 0, nil
+	}
+
+	hitsCTE := `WITH hits AS (` + strings.Join(parts, "\n\t\tUNION ALL") + `
+		)`
+
+	// Headlines are only built for the page being returned.
+	query := hitsCTE + `,
+		page AS (
+			SELECT hits.*, COUNT(*) OVER () AS total
+			FROM hits
+			ORDER BY rank DESC, created_at DESC
+			LIMIT $7 OFFSET $8
+		)
+		SELECT page.source, page.id, page.title,
+			ts_headline('simple', page.body, to_tsquery('simple', $6), $9),
+			page.rank, page.symbol, page.bubble_id, page.review_id, page.tags, page.created_at, page.total
+		FROM page
+		ORDER BY page.rank DESC, page.created_at DESC
+	`
+
+	var tags []string
+	if len(filter.Tags) > 0 {
+		tags = filter.Tags
+	}
+	options := fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxWords=35, MinWords=12, ShortWord=1, MaxFragments=2, FragmentDelimiter=" … "`,
+		filter.HighlightStart, filter.HighlightStop)
+
+	rows, err := r.pool.Query(ctx, query,
+		userID, filter.Symbol, filter.From, filter.To, tags,
+		filter.TSQuery, filter.Limit, filter.Offset, options)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var hits []*entities.SearchHit
+	total := 0
+	for rows.Next() {
+		var hit entities.SearchHit
+		var rank float32
+		if err := rows.Scan(&hit.Source, &hit.ID, &hit.Title, &hit.Snippet, &rank,
+			&hit.Symbol, &hit.BubbleID, &hit.ReviewID, &hit.Tags, &hit.CreatedAt, &total); err != nil {
+			return nil, 0, err
+		}
+		hit.Rank = float64(rank)
+		hits = append(hits, &hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// A page past the end has no rows to carry the window count, so count
+	// the matches on their own.
+	if len(hits) == 0 && filter.Offset > 0 {
+		countQuery := hitsCTE + `
+		SELECT COUNT(*) FROM hits
+	`
+		if err := r.pool.QueryRow(ctx, countQuery,
+			userID, filter.Symbol, filter.From, filter.To, tags, filter.TSQuery).Scan(&total); err != nil {
+			return nil, 0, err
+		}
+	}
+	return hits, total, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -24,10 +25,17 @@ func (r *SummaryPackRepositoryImpl) Create(ctx context.Context, pack *entities.S
 	query := `
 		INSERT INTO summary_packs (
 			pack_id, user_id, source_run_id, range, schema_version, calc_version, content_hash,
-			reconciliation_status, missing_suspects_count, duplicate_suspects_count, normalization_warnings, payload
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			reconciliation_status, missing_suspects_count, duplicate_suspects_count, normalization_warnings, payload,
+			evidence_trade_ids
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 	`
-	_, err := r.pool.Exec(ctx, query,
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, query,
 		pack.PackID,
 		pack.UserID,
 		pack.SourceRunID,
@@ -40,14 +48,29 @@ func (r *SummaryPackRepositoryImpl) Create(ctx context.Context, pack *entities.S
 		pack.DuplicateSuspectsCount,
 		pack.NormalizationWarnings,
 		pack.Payload,
+		pack.EvidenceTradeIDs,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if bundle := pack.EvidenceBundle; bundle != nil {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO summary_pack_evidence_bundles (pack_id, user_id, archive, sha256, size_bytes, manifest)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, pack.PackID, pack.UserID, bundle.Archive, bundle.SHA256, bundle.SizeBytes, bundle.Manifest)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
 }
 
 func (r *SummaryPackRepositoryImpl) GetByID(ctx context.Context, userID uuid.UUID, packID uuid.UUID) (*entities.SummaryPack, error) {
 	query := `
 		SELECT pack_id, user_id, source_run_id, range, schema_version, calc_version, content_hash,
-			reconciliation_status, missing_suspects_count, duplicate_suspects_count, normalization_warnings, payload, created_at
+			reconciliation_status, missing_suspects_count, duplicate_suspects_count, normalization_warnings, payload,
+			evidence_trade_ids, created_at
 		FROM summary_packs
 		WHERE pack_id = $1 AND user_id = $2
 	`
@@ -66,6 +89,7 @@ func (r *SummaryPackRepositoryImpl) GetByID(ctx context.Context, userID uuid.UUI
 		&row.DuplicateSuspectsCount,
 		&row.NormalizationWarnings,
 		&payload,
+		&row.EvidenceTradeIDs,
 		&row.CreatedAt,
 	)
 	if err != nil {
@@ -78,7 +102,8 @@ func (r *SummaryPackRepositoryImpl) GetByID(ctx context.Context, userID uuid.UUI
 func (r *SummaryPackRepositoryImpl) GetLatest(ctx context.Context, userID uuid.UUID, rangeValue string) (*entities.SummaryPack, error) {
 	query := `
 		SELECT pack_id, user_id, source_run_id, range, schema_version, calc_version, content_hash,
-			reconciliation_status, missing_suspects_count, duplicate_suspects_count, normalization_warnings, payload, created_at
+			reconciliation_status, missing_suspects_count, duplicate_suspects_count, normalization_warnings, payload,
+			evidence_trade_ids, created_at
 		FROM summary_packs
 		WHERE user_id = $1 AND range = $2
 		ORDER BY created_at DESC
@@ -99,6 +124,7 @@ func (r *SummaryPackRepositoryImpl) GetLatest(ctx context.Context, userID uuid.U
 		&row.DuplicateSuspectsCount,
 		&row.NormalizationWarnings,
 		&payload,
+		&row.EvidenceTradeIDs,
 		&row.CreatedAt,
 	)
 	if err != nil {
@@ -108,6 +134,57 @@ func (r *SummaryPackRepositoryImpl) GetLatest(ctx context.Context, userID uuid.U
 	return &row, nil
 }
 
+func (r *SummaryPackRepositoryImpl) GetEvidenceBundle(ctx context.Context, userID uuid.UUID, packID uuid.UUID) (*entities.SummaryPackEvidenceBundle, error) {
+	query := `
+		SELECT pack_id, user_id, archive, sha256, size_bytes, manifest, created_at
+		FROM summary_pack_evidence_bundles
+		WHERE pack_id = $1 AND user_id = $2
+	`
+	var bundle entities.SummaryPackEvidenceBundle
+	var manifest json.RawMessage
+	err := r.pool.QueryRow(ctx, query, packID, userID).Scan(
+		&bundle.PackID,
+		&bundle.UserID,
+		&bundle.Archive,
+		&bundle.SHA256,
+		&bundle.SizeBytes,
+		&manifest,
+		&bundle.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	bundle.Manifest = append([]byte(nil), manifest...)
+	return &bundle, nil
+}
+
+func (r *SummaryPackRepositoryImpl) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM summary_packs sp
+		WHERE sp.created_at < $1
+		  AND sp.pack_id <> (
+			SELECT latest.pack_id FROM summary_packs latest
+			WHERE latest.user_id = sp.user_id AND latest.range = sp.range
+			ORDER BY latest.created_at DESC
+			LIMIT 1
+		  )
+		  AND NOT EXISTS (
+			SELECT 1 FROM summary_pack_shares sh
+			WHERE sh.pack_id = sp.pack_id
+			  AND sh.revoked_at IS NULL
+			  AND sh.expires_at > NOW()
+		  )
+	`
+	tag, err := r.pool.Exec(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
 func NormalizeNoRows(err error) error {
 	if errors.Is(err, pgx.ErrNoRows) {
 		return pgx.ErrNoRows
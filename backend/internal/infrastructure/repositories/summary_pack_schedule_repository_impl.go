@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+type SummaryPackScheduleRepositoryImpl struct {
+	pool *pgxpool.Pool
+}
+
+func NewSummaryPackScheduleRepository(pool *pgxpool.Pool) repositories.SummaryPackScheduleRepository {
+	return &SummaryPackScheduleRepositoryImpl{pool: pool}
+}
+
+// Schedules have no timezone of their own; it is read from the user's profile.
+const summaryPackScheduleColumns = `s.id, s.user_id, s.frequency, s.range, u.timezone, s.send_hour, s.weekday, s.day_of_month,
+	s.channels, s.enabled, s.next_run_at, s.last_run_at, s.last_status, s.last_error, s.last_pack_id, s.created_at, s.updated_at`
+
+func (r *SummaryPackScheduleRepositoryImpl) ListByUser(ctx context.Context, userID uuid.UUID) ([]*entities.SummaryPackSchedule, error) {
+	query := `
+		SELECT ` + summaryPackScheduleColumns + `
+		FROM summary_pack_schedules s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.user_id = $1
+		ORDER BY CASE s.frequency WHEN 'daily' THEN 0 WHEN 'weekly' THEN 1 ELSE 2 END
+	`
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanSummaryPackSchedules(rows)
+}
+
+func (r *SummaryPackScheduleRepositoryImpl) Upsert(ctx context.Context, s *entities.SummaryPackSchedule) error {
+	query := `
+		INSERT INTO summary_pack_schedules (
+			id, user_id, frequency, range, send_hour, weekday, day_of_month,
+			channels, enabled, next_run_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11)
+		ON CONFLICT (user_id, frequency) DO UPDATE SET
+			range = EXCLUDED.range,
+			send_hour = EXCLUDED.send_hour,
+			weekday = EXCLUDED.weekday,
+			day_of_month = EXCLUDED.day_of_month,
+			channels = EXCLUDED.channels,
+			enabled = EXCLUDED.enabled,
+			next_run_at = EXCLUDED.next_run_at,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at
+	`
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	s.UpdatedAt = time.Now().UTC()
+	channels := make([]string, 0, len(s.Channels))
+	for _, channel := range s.Channels {
+		channels = append(channels, string(channel))
+	}
+	return r.pool.QueryRow(ctx, query,
+		s.ID, s.UserID, s.Frequency, s.Range, s.SendHour, s.Weekday, s.DayOfMonth,
+		channels, s.Enabled, s.NextRunAt, s.UpdatedAt,
+	).Scan(&s.ID, &s.CreatedAt)
+}
+
+func (r *SummaryPackScheduleRepositoryImpl) Delete(ctx context.Context, userID uuid.UUID, frequency entities.PackScheduleFrequency) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM summary_pack_schedules WHERE user_id = $1 AND frequency = $2`, userID, frequency)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *SummaryPackScheduleRepositoryImpl) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*entities.SummaryPackSchedule, error) {
+	query := `
+		UPDATE summary_pack_schedules s
+		SET next_run_at = $2
+		FROM users u
+		WHERE u.id = s.user_id AND s.id IN (
+			SELECT id FROM summary_pack_schedules
+			WHERE enabled AND next_run_at <= $1
+			ORDER BY next_run_at ASC
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + summaryPackScheduleColumns + `
+	`
+	rows, err := r.pool.Query(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanSummaryPackSchedules(rows)
+}
+
+func (r *SummaryPackScheduleRepositoryImpl) RecordRun(ctx context.Context, s *entities.SummaryPackSchedule) error {
+	query := `
+		UPDATE summary_pack_schedules
+		SET last_run_at = $2, last_status = $3, last_error = $4, last_pack_id = COALESCE($5, last_pack_id),
+			next_run_at = $6, updated_at = $7
+		WHERE id = $1
+	`
+	s.UpdatedAt = time.Now().UTC()
+	_, err := r.pool.Exec(ctx, query,
+		s.ID, s.LastRunAt, s.LastStatus, s.LastError, s.LastPackID, s.NextRunAt, s.UpdatedAt)
+	return err
+}
+
+func scanSummaryPackSchedules(rows pgx.Rows) ([]*entities.SummaryPackSchedule, error) {
+	var schedules []*entities.SummaryPackSchedule
+	for rows.Next() {
+		var s entities.SummaryPackSchedule
+		var channels []string
+		if err := rows.Scan(&s.ID, &s.UserID, &s.Frequency, &s.Range, &s.Timezone, &s.SendHour, &s.Weekday,
+			&s.DayOfMonth, &channels, &s.Enabled, &s.NextRunAt, &s.LastRunAt, &s.LastStatus, &s.LastError,
+			&s.LastPackID, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		for _, channel := range channels {
+			s.Channels = append(s.Channels, entities.ChannelType(channel))
+		}
+		schedules = append(schedules, &s)
+	}
+	return schedules, rows.Err()
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+type SummaryPackShareRepositoryImpl struct {
+	pool *pgxpool.Pool
+}
+
+func NewSummaryPackShareRepository(pool *pgxpool.Pool) repositories.SummaryPackShareRepository {
+	return &SummaryPackShareRepositoryImpl{pool: pool}
+}
+
+const summaryPackShareColumns = `id, user_id, pack_id, redactions, expires_at, revoked_at, view_count, last_viewed_at, created_at`
+
+func (r *SummaryPackShareRepositoryImpl) Create(ctx context.Context, share *entities.SummaryPackShare) error {
+	query := `
+		INSERT INTO summary_pack_shares (id, user_id, pack_id, redactions, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	if share.ID == uuid.Nil {
+		share.ID = uuid.New()
+	}
+	if share.Redactions == nil {
+		share.Redactions = []string{}
+	}
+	_, err := r.pool.Exec(ctx, query, share.ID, share.UserID, share.PackID, share.Redactions, share.ExpiresAt, share.CreatedAt)
+	return err
+}
+
+func (r *SummaryPackShareRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*entities.SummaryPackShare, error) {
+	query := `SELECT ` + summaryPackShareColumns + ` FROM summary_pack_shares WHERE id = $1`
+	share, err := scanSummaryPackShare(r.pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return share, err
+}
+
+func (r *SummaryPackShareRepositoryImpl) ListByPack(ctx context.Context, userID uuid.UUID, packID uuid.UUID) ([]*entities.SummaryPackShare, error) {
+	query := `
+		SELECT ` + summaryPackShareColumns + `
+		FROM summary_pack_shares
+		WHERE user_id = $1 AND pack_id = $2
+		ORDER BY created_at DESC
+	`
+	rows, err := r.pool.Query(ctx, query, userID, packID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shares := []*entities.SummaryPackShare{}
+	for rows.Next() {
+		share, err := scanSummaryPackShare(rows)
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+	return shares, rows.Err()
+}
+
+func (r *SummaryPackShareRepositoryImpl) Revoke(ctx context.Context, userID uuid.UUID, id uuid.UUID, at time.Time) (bool, error) {
+	query := `
+		UPDATE summary_pack_shares
+		SET revoked_at = COALESCE(revoked_at, $3)
+		WHERE id = $1 AND user_id = $2
+	`
+	tag, err := r.pool.Exec(ctx, query, id, userID, at)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *SummaryPackShareRepositoryImpl) RecordView(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	query := `
+		UPDATE summary_pack_shares
+		SET view_count = view_count + 1, last_viewed_at = $2
+		WHERE id = $1 AND revoked_at IS NULL AND expires_at > $2
+	`
+	tag, err := r.pool.Exec(ctx, query, id, at)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func scanSummaryPackShare(row pgx.Row) (*entities.SummaryPackShare, error) {
+	var share entities.SummaryPackShare
+	err := row.Scan(
+		&share.ID,
+		&share.UserID,
+		&share.PackID,
+		&share.Redactions,
+		&share.ExpiresAt,
+		&share.RevokedAt,
+		&share.ViewCount,
+		&share.LastViewedAt,
+		&share.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
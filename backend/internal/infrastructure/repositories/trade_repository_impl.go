@@ -24,23 +24,23 @@ func NewTradeRepository(pool *pgxpool.Pool) repositories.TradeRepository {
 
 func (r *TradeRepositoryImpl) Create(ctx context.Context, trade *entities.Trade) error {
 	query := `
-    INSERT INTO trades (id, user_id, bubble_id, binance_trade_id, exchange, symbol, side, position_side, open_close, reduce_only, quantity, price, realized_pnl, trade_time)
-    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+    INSERT INTO trades (id, user_id, bubble_id, binance_trade_id, exchange, symbol, side, position_side, open_close, reduce_only, order_id, quantity, price, realized_pnl, trade_time)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
   `
 	_, err := r.pool.Exec(ctx, query,
-		trade.ID, trade.UserID, trade.BubbleID, trade.BinanceTradeID, trade.Exchange, trade.Symbol, trade.Side, trade.PositionSide, trade.OpenClose, trade.ReduceOnly, trade.Quantity, trade.Price, trade.RealizedPnL, trade.TradeTime)
+		trade.ID, trade.UserID, trade.BubbleID, trade.BinanceTradeID, trade.Exchange, trade.Symbol, trade.Side, trade.PositionSide, trade.OpenClose, trade.ReduceOnly, trade.OrderID, trade.Quantity, trade.Price, trade.RealizedPnL, trade.TradeTime)
 	return err
 }
 
 func (r *TradeRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*entities.Trade, error) {
 	query := `
-    SELECT id, user_id, bubble_id, binance_trade_id, exchange, symbol, side, position_side, open_close, reduce_only, quantity, price, realized_pnl, trade_time
+    SELECT id, user_id, bubble_id, binance_trade_id, exchange, symbol, side, position_side, open_close, reduce_only, order_id, quantity, price, realized_pnl, trade_time
     FROM trades
     WHERE id = $1
   `
 	var trade entities.Trade
 	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&trade.ID, &trade.UserID, &trade.BubbleID, &trade.BinanceTradeID, &trade.Exchange, &trade.Symbol, &trade.Side, &trade.PositionSide, &trade.OpenClose, &trade.ReduceOnly, &trade.Quantity, &trade.Price, &trade.RealizedPnL, &trade.TradeTime)
+		&trade.ID, &trade.UserID, &trade.BubbleID, &trade.BinanceTradeID, &trade.Exchange, &trade.Symbol, &trade.Side, &trade.PositionSide, &trade.OpenClose, &trade.ReduceOnly, &trade.OrderID, &trade.Quantity, &trade.Price, &trade.RealizedPnL, &trade.TradeTime)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -52,7 +52,7 @@ func (r *TradeRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*entit
 
 func (r *TradeRepositoryImpl) ListByUserAndSymbol(ctx context.Context, userID uuid.UUID, symbol string) ([]*entities.Trade, error) {
 	query := `
-    SELECT id, user_id, bubble_id, binance_trade_id, exchange, symbol, side, position_side, open_close, reduce_only, quantity, price, realized_pnl, trade_time
+    SELECT id, user_id, bubble_id, binance_trade_id, exchange, symbol, side, position_side, open_close, reduce_only, order_id, quantity, price, realized_pnl, trade_time
     FROM trades
     WHERE user_id = $1 AND symbol = $2
     ORDER BY trade_time DESC
@@ -67,7 +67,7 @@ func (r *TradeRepositoryImpl) ListByUserAndSymbol(ctx context.Context, userID uu
 	for rows.Next() {
 		var trade entities.Trade
 		if err := rows.Scan(
-			&trade.ID, &trade.UserID, &trade.BubbleID, &trade.BinanceTradeID, &trade.Exchange, &trade.Symbol, &trade.Side, &trade.PositionSide, &trade.OpenClose, &trade.ReduceOnly, &trade.Quantity, &trade.Price, &trade.RealizedPnL, &trade.TradeTime); err != nil {
+			&trade.ID, &trade.UserID, &trade.BubbleID, &trade.BinanceTradeID, &trade.Exchange, &trade.Symbol, &trade.Side, &trade.PositionSide, &trade.OpenClose, &trade.ReduceOnly, &trade.OrderID, &trade.Quantity, &trade.Price, &trade.RealizedPnL, &trade.TradeTime); err != nil {
 			return nil, err
 		}
 		trades = append(trades, &trade)
@@ -80,7 +80,7 @@ func (r *TradeRepositoryImpl) ListByUserAndSymbol(ctx context.Context, userID uu
 
 func (r *TradeRepositoryImpl) ListByBubble(ctx context.Context, bubbleID uuid.UUID) ([]*entities.Trade, error) {
 	query := `
-    SELECT id, user_id, bubble_id, binance_trade_id, exchange, symbol, side, position_side, open_close, reduce_only, quantity, price, realized_pnl, trade_time
+    SELECT id, user_id, bubble_id, binance_trade_id, exchange, symbol, side, position_side, open_close, reduce_only, order_id, quantity, price, realized_pnl, trade_time
     FROM trades
     WHERE bubble_id = $1
     ORDER BY trade_time DESC
@@ -95,7 +95,7 @@ func (r *TradeRepositoryImpl) ListByBubble(ctx context.Context, bubbleID uuid.UU
 	for rows.Next() {
 		var trade entities.Trade
 		if err := rows.Scan(
-			&trade.ID, &trade.UserID, &trade.BubbleID, &trade.BinanceTradeID, &trade.Exchange, &trade.Symbol, &trade.Side, &trade.PositionSide, &trade.OpenClose, &trade.ReduceOnly, &trade.Quantity, &trade.Price, &trade.RealizedPnL, &trade.TradeTime); err != nil {
+			&trade.ID, &trade.UserID, &trade.BubbleID, &trade.BinanceTradeID, &trade.Exchange, &trade.Symbol, &trade.Side, &trade.PositionSide, &trade.OpenClose, &trade.ReduceOnly, &trade.OrderID, &trade.Quantity, &trade.Price, &trade.RealizedPnL, &trade.TradeTime); err != nil {
 			return nil, err
 		}
 		trades = append(trades, &trade)
@@ -126,7 +126,7 @@ func (r *TradeRepositoryImpl) List(ctx context.Context, userID uuid.UUID, filter
 	}
 
 	listQuery := fmt.Sprintf(`
-    SELECT id, user_id, bubble_id, binance_trade_id, exchange, symbol, side, position_side, open_close, reduce_only, quantity, price, realized_pnl, trade_time
+    SELECT id, user_id, bubble_id, binance_trade_id, exchange, symbol, side, position_side, open_close, reduce_only, order_id, quantity, price, realized_pnl, trade_time
     FROM trades
     %s
     %s
@@ -144,7 +144,7 @@ func (r *TradeRepositoryImpl) List(ctx context.Context, userID uuid.UUID, filter
 	for rows.Next() {
 		var trade entities.Trade
 		if err := rows.Scan(
-			&trade.ID, &trade.UserID, &trade.BubbleID, &trade.BinanceTradeID, &trade.Exchange, &trade.Symbol, &trade.Side, &trade.PositionSide, &trade.OpenClose, &trade.ReduceOnly, &trade.Quantity, &trade.Price, &trade.RealizedPnL, &trade.TradeTime); err != nil {
+			&trade.ID, &trade.UserID, &trade.BubbleID, &trade.BinanceTradeID, &trade.Exchange, &trade.Symbol, &trade.Side, &trade.PositionSide, &trade.OpenClose, &trade.ReduceOnly, &trade.OrderID, &trade.Quantity, &trade.Price, &trade.RealizedPnL, &trade.TradeTime); err != nil {
 			return nil, 0, err
 		}
 		trades = append(trades, &trade)
@@ -157,7 +157,7 @@ func (r *TradeRepositoryImpl) List(ctx context.Context, userID uuid.UUID, filter
 
 func (r *TradeRepositoryImpl) ListByTimeRange(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*entities.Trade, error) {
 	query := `
-    SELECT id, user_id, bubble_id, binance_trade_id, exchange, symbol, side, position_side, open_close, reduce_only, quantity, price, realized_pnl, trade_time
+    SELECT id, user_id, bubble_id, binance_trade_id, exchange, symbol, side, position_side, open_close, reduce_only, order_id, quantity, price, realized_pnl, trade_time
     FROM trades
     WHERE user_id = $1 AND trade_time >= $2 AND trade_time <= $3
     ORDER BY trade_time ASC
@@ -172,7 +172,7 @@ func (r *TradeRepositoryImpl) ListByTimeRange(ctx context.Context, userID uuid.U
 	for rows.Next() {
 		var trade entities.Trade
 		if err := rows.Scan(
-			&trade.ID, &trade.UserID, &trade.BubbleID, &trade.BinanceTradeID, &trade.Exchange, &trade.Symbol, &trade.Side, &trade.PositionSide, &trade.OpenClose, &trade.ReduceOnly, &trade.Quantity, &trade.Price, &trade.RealizedPnL, &trade.TradeTime); err != nil {
+			&trade.ID, &trade.UserID, &trade.BubbleID, &trade.BinanceTradeID, &trade.Exchange, &trade.Symbol, &trade.Side, &trade.PositionSide, &trade.OpenClose, &trade.ReduceOnly, &trade.OrderID, &trade.Quantity, &trade.Price, &trade.RealizedPnL, &trade.TradeTime); err != nil {
 			return nil, err
 		}
 		trades = append(trades, &trade)
@@ -376,7 +376,7 @@ func (r *TradeRepositoryImpl) ListUnlinked(ctx context.Context, userID uuid.UUID
 		limit = 500
 	}
 	query := `
-		SELECT id, user_id, bubble_id, binance_trade_id, exchange, symbol, side, position_side, open_close, reduce_only, quantity, price, realized_pnl, trade_time
+		SELECT id, user_id, bubble_id, binance_trade_id, exchange, symbol, side, position_side, open_close, reduce_only, order_id, quantity, price, realized_pnl, trade_time
 		FROM trades
 		WHERE user_id = $1 AND bubble_id IS NULL
 		ORDER BY trade_time ASC
@@ -392,7 +392,7 @@ func (r *TradeRepositoryImpl) ListUnlinked(ctx context.Context, userID uuid.UUID
 	for rows.Next() {
 		var trade entities.Trade
 		if err := rows.Scan(
-			&trade.ID, &trade.UserID, &trade.BubbleID, &trade.BinanceTradeID, &trade.Exchange, &trade.Symbol, &trade.Side, &trade.PositionSide, &trade.OpenClose, &trade.ReduceOnly, &trade.Quantity, &trade.Price, &trade.RealizedPnL, &trade.TradeTime); err != nil {
+			&trade.ID, &trade.UserID, &trade.BubbleID, &trade.BinanceTradeID, &trade.Exchange, &trade.Symbol, &trade.Side, &trade.PositionSide, &trade.OpenClose, &trade.ReduceOnly, &trade.OrderID, &trade.Quantity, &trade.Price, &trade.RealizedPnL, &trade.TradeTime); err != nil {
 			return nil, err
 		}
 		trades = append(trades, &trade)
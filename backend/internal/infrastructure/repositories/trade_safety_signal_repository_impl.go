@@ -0,0 +1,272 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+type TradeSafetySignalRepositoryImpl struct {
+	pool *pgxpool.Pool
+}
+
+func NewTradeSafetySignalRepository(pool *pgxpool.Pool) repositories.TradeSafetySignalRepository {
+	return &TradeSafetySignalRepositoryImpl{pool: pool}
+}
+
+// ListFills reads synced trades and imported trade events. Leverage comes from
+// the event metadata or, failing that, the manual position open on the
+// symbol at execution time.
+func (r *TradeSafetySignalRepositoryImpl) ListFills(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*repositories.SafetyFill, error) {
+	query := `
+		WITH fills AS (
+			SELECT 'trade'::text AS target_type, t.id, LOWER(COALESCE(NULLIF(t.exchange, ''), 'legacy')) AS venue,
+				upper(regexp_replace(t.symbol, '[^A-Za-z0-9]', '', 'g')) AS symbol_key, lower(t.side) AS side,
+				t.quantity::text AS qty, t.price::text AS price, NULL::text AS leverage,
+				t.trade_time AS executed_at, false AS mirrored
+			FROM trades t
+			WHERE t.user_id = $1 AND t.trade_time BETWEEN $2 AND $3
+			UNION ALL
+			SELECT 'trade_event', e.id, LOWER(COALESCE(v.code, 'unknown')), upper(i.base_asset || i.quote_asset), e.side,
+				e.qty::text, e.price::text,
+				CASE WHEN e.metadata->>'leverage' ~ '^[0-9]+(\.[0-9]+)?$' THEN e.metadata->>'leverage' END,
+				e.executed_at, COALESCE(e.metadata, '{}'::jsonb) ? 'trade_id'
+			FROM trade_events e
+			JOIN instruments i ON i.id = e.instrument_id
+			LEFT JOIN venues v ON v.id = e.venue_id
+			WHERE e.user_id = $1
+				AND e.executed_at BETWEEN $2 AND $3
+				AND e.event_type IN ('spot_trade', 'perp_trade', 'dex_swap')
+				AND e.side IS NOT NULL AND e.qty IS NOT NULL AND e.price IS NOT NULL
+		)
+		SELECT f.target_type, f.id, f.venue, f.symbol_key, f.side, f.qty, f.price,
+			COALESCE(f.leverage, (
+				SELECT mp.leverage::text
+				FROM manual_positions mp
+				WHERE mp.user_id = $1
+					AND upper(regexp_replace(mp.symbol, '[^A-Za-z0-9]', '', 'g')) = f.symbol_key
+					AND mp.leverage IS NOT NULL
+					AND mp.opened_at <= f.executed_at
+					AND (mp.closed_at IS NULL OR mp.closed_at >= f.executed_at)
+				ORDER BY mp.opened_at DESC
+				LIMIT 1
+			)),
+			f.executed_at, f.mirrored
+		FROM fills f
+		ORDER BY f.executed_at, f.id
+	`
+	rows, err := r.pool.Query(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fills []*repositories.SafetyFill
+	for rows.Next() {
+		var fill repositories.SafetyFill
+		if err := rows.Scan(&fill.TargetType, &fill.ID, &fill.Venue, &fill.SymbolKey, &fill.Side, &fill.Qty, &fill.Price,
+			&fill.Leverage, &fill.ExecutedAt, &fill.Mirrored); err != nil {
+			return nil, err
+		}
+		fills = append(fills, &fill)
+	}
+	return fills, rows.Err()
+}
+
+func (r *TradeSafetySignalRepositoryImpl) ListTradedSymbols(ctx context.Context, userID uuid.UUID, before time.Time) ([]string, error) {
+	query := `
+		SELECT upper(regexp_replace(symbol, '[^A-Za-z0-9]', '', 'g'))
+		FROM trades
+		WHERE user_id = $1 AND trade_time < $2
+		UNION
+		SELECT upper(i.base_asset || i.quote_asset)
+		FROM trade_events e
+		JOIN instruments i ON i.id = e.instrument_id
+		WHERE e.user_id = $1 AND e.executed_at < $2
+			AND e.event_type IN ('spot_trade', 'perp_trade', 'dex_swap')
+	`
+	rows, err := r.pool.Query(ctx, query, userID, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var symbols []string
+	for rows.Next() {
+		var symbol string
+		if err := rows.Scan(&symbol); err != nil {
+			return nil, err
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols, rows.Err()
+}
+
+func (r *TradeSafetySignalRepositoryImpl) ListUnscoredUsers(ctx context.Context, limit int) ([]*repositories.UnscoredSafetyUser, error) {
+	query := `
+		SELECT user_id, MIN(executed_at) AS oldest
+		FROM (
+			SELECT t.user_id, t.trade_time AS executed_at
+			FROM trades t
+			WHERE NOT EXISTS (
+				SELECT 1 FROM trade_safety_signals s WHERE s.user_id = t.user_id AND s.trade_id = t.id
+			)
+			UNION ALL
+			SELECT e.user_id, e.executed_at
+			FROM trade_events e
+			WHERE e.event_type IN ('spot_trade', 'perp_trade', 'dex_swap')
+				AND e.side IS NOT NULL AND e.qty IS NOT NULL AND e.price IS NOT NULL
+				AND NOT EXISTS (
+					SELECT 1 FROM trade_safety_signals s WHERE s.user_id = e.user_id AND s.trade_event_id = e.id
+				)
+		) unscored
+		GROUP BY user_id
+		ORDER BY oldest
+		LIMIT $1
+	`
+	rows, err := r.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*repositories.UnscoredSafetyUser
+	for rows.Next() {
+		var user repositories.UnscoredSafetyUser
+		if err := rows.Scan(&user.UserID, &user.From); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+	return users, rows.Err()
+}
+
+func (r *TradeSafetySignalRepositoryImpl) UpsertSignals(ctx context.Context, userID uuid.UUID, signals []*entities.TradeSafetySignal) error {
+	if len(signals) == 0 {
+		return nil
+	}
+	tradeQuery := `
+		INSERT INTO trade_safety_signals (user_id, trade_id, features, flags, score, computed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, trade_id) WHERE trade_id IS NOT NULL
+		DO UPDATE SET features = EXCLUDED.features, flags = EXCLUDED.flags, score = EXCLUDED.score,
+			computed_at = EXCLUDED.computed_at
+		WHERE NOT EXISTS (
+			SELECT 1 FROM trade_safety_reviews r
+			WHERE r.user_id = trade_safety_signals.user_id AND r.trade_id = trade_safety_signals.trade_id
+		)
+	`
+	eventQuery := `
+		INSERT INTO trade_safety_signals (user_id, trade_event_id, features, flags, score, computed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, trade_event_id) WHERE trade_event_id IS NOT NULL
+		DO UPDATE SET features = EXCLUDED.features, flags = EXCLUDED.flags, score = EXCLUDED.score,
+			computed_at = EXCLUDED.computed_at
+		WHERE NOT EXISTS (
+			SELECT 1 FROM trade_safety_reviews r
+			WHERE r.user_id = trade_safety_signals.user_id AND r.trade_event_id = trade_safety_signals.trade_event_id
+		)
+	`
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, signal := range signals {
+		features, err := json.Marshal(signal.Features)
+		if err != nil {
+			return err
+		}
+		flags, err := json.Marshal(signal.Flags)
+		if err != nil {
+			return err
+		}
+		query := tradeQuery
+		if signal.TargetType == "trade_event" {
+			query = eventQuery
+		}
+		if _, err := tx.Exec(ctx, query, userID, signal.TargetID, features, flags, signal.Score, signal.ComputedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *TradeSafetySignalRepositoryImpl) ListSignals(ctx context.Context, userID uuid.UUID, targetIDs []uuid.UUID) ([]*entities.TradeSafetySignal, error) {
+	if len(targetIDs) == 0 {
+		return nil, nil
+	}
+	query := `
+		SELECT CASE WHEN trade_id IS NOT NULL THEN 'trade' ELSE 'trade_event' END,
+			COALESCE(trade_id, trade_event_id), features, flags, score::float8, computed_at
+		FROM trade_safety_signals
+		WHERE user_id = $1 AND (trade_id = ANY($2) OR trade_event_id = ANY($2))
+	`
+	rows, err := r.pool.Query(ctx, query, userID, targetIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var signals []*entities.TradeSafetySignal
+	for rows.Next() {
+		var signal entities.TradeSafetySignal
+		var features, flags []byte
+		if err := rows.Scan(&signal.TargetType, &signal.TargetID, &features, &flags, &signal.Score, &signal.ComputedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(features, &signal.Features); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(flags, &signal.Flags); err != nil {
+			return nil, err
+		}
+		signals = append(signals, &signal)
+	}
+	return signals, rows.Err()
+}
+
+func (r *TradeSafetySignalRepositoryImpl) ListReviewedSignals(ctx context.Context, userID uuid.UUID, since time.Time) ([]*entities.TradeSafetySignal, error) {
+	query := `
+		SELECT CASE WHEN s.trade_id IS NOT NULL THEN 'trade' ELSE 'trade_event' END,
+			COALESCE(s.trade_id, s.trade_event_id), s.features, s.flags, s.score::float8, s.computed_at, r.verdict
+		FROM trade_safety_signals s
+		JOIN trade_safety_reviews r
+			ON r.user_id = s.user_id
+			AND (
+				(s.trade_id IS NOT NULL AND r.trade_id = s.trade_id)
+				OR (s.trade_event_id IS NOT NULL AND r.trade_event_id = s.trade_event_id)
+			)
+		WHERE s.user_id = $1 AND r.updated_at >= $2
+	`
+	rows, err := r.pool.Query(ctx, query, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var signals []*entities.TradeSafetySignal
+	for rows.Next() {
+		var signal entities.TradeSafetySignal
+		var features, flags []byte
+		var verdict entities.TradeSafetyVerdict
+		if err := rows.Scan(&signal.TargetType, &signal.TargetID, &features, &flags, &signal.Score, &signal.ComputedAt, &verdict); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(features, &signal.Features); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(flags, &signal.Flags); err != nil {
+			return nil, err
+		}
+		signal.Verdict = &verdict
+		signals = append(signals, &signal)
+	}
+	return signals, rows.Err()
+}
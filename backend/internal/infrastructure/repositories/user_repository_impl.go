@@ -22,23 +22,24 @@ func NewUserRepository(pool *pgxpool.Pool) repositories.UserRepository {
 
 func (r *UserRepositoryImpl) Create(ctx context.Context, user *entities.User) error {
 	query := `
-		INSERT INTO users (id, email, password_hash, name, ai_allowlisted, is_admin, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO users (id, email, password_hash, name, ai_allowlisted, is_admin, timezone, day_cutoff_hour, timezone_set_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, COALESCE(NULLIF($7, ''), $12), $8, $9, $10, $11)
 	`
 	_, err := r.pool.Exec(ctx, query,
-		user.ID, user.Email, user.PasswordHash, user.Name, user.AIAllowlisted, user.IsAdmin, user.CreatedAt, user.UpdatedAt)
+		user.ID, user.Email, user.PasswordHash, user.Name, user.AIAllowlisted, user.IsAdmin, user.Timezone, user.DayCutoffHour, user.TimezoneSetAt, user.CreatedAt, user.UpdatedAt,
+		entities.DefaultUserTimezone)
 	return err
 }
 
 func (r *UserRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*entities.User, error) {
 	query := `
-		SELECT id, email, password_hash, name, ai_allowlisted, is_admin, created_at, updated_at
+		SELECT id, email, password_hash, name, ai_allowlisted, is_admin, timezone, day_cutoff_hour, timezone_set_at, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
 	var user entities.User
 	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.AIAllowlisted, &user.IsAdmin, &user.CreatedAt, &user.UpdatedAt)
+		&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.AIAllowlisted, &user.IsAdmin, &user.Timezone, &user.DayCutoffHour, &user.TimezoneSetAt, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -50,13 +51,13 @@ func (r *UserRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*entiti
 
 func (r *UserRepositoryImpl) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
 	query := `
-		SELECT id, email, password_hash, name, ai_allowlisted, is_admin, created_at, updated_at
+		SELECT id, email, password_hash, name, ai_allowlisted, is_admin, timezone, day_cutoff_hour, timezone_set_at, created_at, updated_at
 		FROM users
 		WHERE LOWER(email) = LOWER($1)
 	`
 	var user entities.User
 	err := r.pool.QueryRow(ctx, query, email).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.AIAllowlisted, &user.IsAdmin, &user.CreatedAt, &user.UpdatedAt)
+		&user.ID, &user.Email, &user.PasswordHash, &user.Name, &user.AIAllowlisted, &user.IsAdmin, &user.Timezone, &user.DayCutoffHour, &user.TimezoneSetAt, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -79,7 +80,7 @@ func (r *UserRepositoryImpl) ListForAdmin(ctx context.Context, limit int, offset
 
 	needle := strings.TrimSpace(strings.ToLower(search))
 	baseQuery := `
-		SELECT id, email, password_hash, name, ai_allowlisted, is_admin, created_at, updated_at
+		SELECT id, email, password_hash, name, ai_allowlisted, is_admin, timezone, day_cutoff_hour, timezone_set_at, created_at, updated_at
 		FROM users
 	`
 	var rows pgx.Rows
@@ -115,6 +116,9 @@ func (r *UserRepositoryImpl) ListForAdmin(ctx context.Context, limit int, offset
 			&user.Name,
 			&user.AIAllowlisted,
 			&user.IsAdmin,
+			&user.Timezone,
+			&user.DayCutoffHour,
+			&user.TimezoneSetAt,
 			&user.CreatedAt,
 			&user.UpdatedAt,
 		); err != nil {
@@ -170,11 +174,13 @@ func (r *UserRepositoryImpl) SetAdmin(ctx context.Context, id uuid.UUID, isAdmin
 func (r *UserRepositoryImpl) Update(ctx context.Context, user *entities.User) error {
 	query := `
 		UPDATE users
-		SET email = $2, password_hash = $3, name = $4, ai_allowlisted = $5, is_admin = $6, updated_at = $7
+		SET email = $2, password_hash = $3, name = $4, ai_allowlisted = $5, is_admin = $6,
+			timezone = COALESCE(NULLIF($7, ''), $11), day_cutoff_hour = $8, timezone_set_at = $9, updated_at = $10
 		WHERE id = $1
 	`
 	_, err := r.pool.Exec(ctx, query,
-		user.ID, user.Email, user.PasswordHash, user.Name, user.AIAllowlisted, user.IsAdmin, user.UpdatedAt)
+		user.ID, user.Email, user.PasswordHash, user.Name, user.AIAllowlisted, user.IsAdmin, user.Timezone, user.DayCutoffHour, user.TimezoneSetAt, user.UpdatedAt,
+		entities.DefaultUserTimezone)
 	return err
 }
 
@@ -23,6 +23,7 @@ import (
 	"github.com/moneyvessel/kifu/internal/domain/entities"
 	"github.com/moneyvessel/kifu/internal/domain/repositories"
 	cryptoutil "github.com/moneyvessel/kifu/internal/infrastructure/crypto"
+	"github.com/moneyvessel/kifu/internal/services"
 )
 
 const (
@@ -43,6 +44,7 @@ type AIHandler struct {
 	userAIKeyRepo     repositories.UserAIKeyRepository
 	userRepo          repositories.UserRepository
 	subscriptionRepo  repositories.SubscriptionRepository
+	candleStore       *services.CandleStore
 	encryptionKey     []byte
 	client            *http.Client
 	oneShotCache      *oneShotCache
@@ -57,6 +59,7 @@ func NewAIHandler(
 	userAIKeyRepo repositories.UserAIKeyRepository,
 	userRepo repositories.UserRepository,
 	subscriptionRepo repositories.SubscriptionRepository,
+	candleStore *services.CandleStore,
 	encryptionKey []byte,
 ) *AIHandler {
 	requireAllowlist := envBoolWithDefault("AI_REQUIRE_ALLOWLIST", isProductionEnv())
@@ -72,6 +75,7 @@ func NewAIHandler(
 		userAIKeyRepo:    userAIKeyRepo,
 		userRepo:         userRepo,
 		subscriptionRepo: subscriptionRepo,
+		candleStore:      candleStore,
 		encryptionKey:    encryptionKey,
 		client: &http.Client{
 			Timeout: 20 * time.Second,
@@ -943,71 +947,20 @@ type klineItem struct {
 }
 
 func (h *AIHandler) fetchKlines(ctx context.Context, symbol string, interval string, candleTime time.Time) ([]klineItem, bool, error) {
-	params := url.Values{}
-	params.Set("symbol", symbol)
-	params.Set("interval", interval)
-	params.Set("limit", "50")
-	params.Set("endTime", fmt.Sprintf("%d", candleTime.UTC().UnixMilli()))
-
-	requestURL := fmt.Sprintf("https://fapi.binance.com/fapi/v1/klines?%s", params.Encode())
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	candles, err := h.candleStore.Latest(ctx, entities.CandleVenueBinance, symbol, interval, candleTime, 50)
 	if err != nil {
 		return nil, false, err
 	}
 
-	resp, err := h.client.Do(req)
-	if err != nil {
-		return nil, false, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		payload, _ := io.ReadAll(resp.Body)
-		return nil, false, fmt.Errorf("binance klines error %d: %s", resp.StatusCode, strings.TrimSpace(string(payload)))
-	}
-
-	var raw [][]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
-		return nil, false, err
-	}
-
-	items := make([]klineItem, 0, len(raw))
-	for _, row := range raw {
-		if len(row) < 6 {
-			continue
-		}
-		openTime, ok := asInt64(row[0])
-		if !ok {
-			continue
-		}
-		open, ok := asString(row[1])
-		if !ok {
-			continue
-		}
-		high, ok := asString(row[2])
-		if !ok {
-			continue
-		}
-		low, ok := asString(row[3])
-		if !ok {
-			continue
-		}
-		closeVal, ok := asString(row[4])
-		if !ok {
-			continue
-		}
-		volume, ok := asString(row[5])
-		if !ok {
-			continue
-		}
-
+	items := make([]klineItem, 0, len(candles))
+	for _, candle := range candles {
 		items = append(items, klineItem{
-			Time:   openTime / 1000,
-			Open:   open,
-			High:   high,
-			Low:    low,
-			Close:  closeVal,
-			Volume: volume,
+			Time:   candle.OpenTime.Unix(),
+			Open:   candle.Open,
+			High:   candle.High,
+			Low:    candle.Low,
+			Close:  candle.Close,
+			Volume: candle.Volume,
 		})
 	}
 
@@ -1,13 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/moneyvessel/kifu/internal/domain/entities"
 	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/services"
 )
 
 type AlertNotificationHandler struct {
@@ -15,6 +16,9 @@ type AlertNotificationHandler struct {
 	briefingRepo repositories.AlertBriefingRepository
 	decisionRepo repositories.AlertDecisionRepository
 	outcomeRepo  repositories.AlertOutcomeRepository
+	decisionSvc  *services.AlertDecisionService
+	qualitySvc   *services.DecisionQualityService
+	lifecycleSvc *services.AlertLifecycleService
 }
 
 func NewAlertNotificationHandler(
@@ -22,12 +26,18 @@ func NewAlertNotificationHandler(
 	briefingRepo repositories.AlertBriefingRepository,
 	decisionRepo repositories.AlertDecisionRepository,
 	outcomeRepo repositories.AlertOutcomeRepository,
+	decisionSvc *services.AlertDecisionService,
+	qualitySvc *services.DecisionQualityService,
+	lifecycleSvc *services.AlertLifecycleService,
 ) *AlertNotificationHandler {
 	return &AlertNotificationHandler{
 		alertRepo:    alertRepo,
 		briefingRepo: briefingRepo,
 		decisionRepo: decisionRepo,
 		outcomeRepo:  outcomeRepo,
+		decisionSvc:  decisionSvc,
+		qualitySvc:   qualitySvc,
+		lifecycleSvc: lifecycleSvc,
 	}
 }
 
@@ -36,6 +46,11 @@ type AlertDetailResponse struct {
 	Briefings []*entities.AlertBriefing `json:"briefings"`
 	Decision  *entities.AlertDecision   `json:"decision,omitempty"`
 	Outcomes  []*entities.AlertOutcome  `json:"outcomes,omitempty"`
+	Events    []*entities.AlertEvent    `json:"events"`
+}
+
+type SnoozeAlertRequest struct {
+	Minutes int `json:"minutes"`
 }
 
 type CreateDecisionRequest struct {
@@ -97,12 +112,17 @@ func (h *AlertNotificationHandler) GetAlert(c *fiber.Ctx) error {
 
 	decision, _ := h.decisionRepo.GetByAlert(c.Context(), id)
 	outcomes, _ := h.outcomeRepo.ListByAlert(c.Context(), id)
+	events, err := h.lifecycleSvc.Events(c.Context(), userID, id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
 
 	return c.JSON(AlertDetailResponse{
 		Alert:     alert,
 		Briefings: briefings,
 		Decision:  decision,
 		Outcomes:  outcomes,
+		Events:    events,
 	})
 }
 
@@ -117,53 +137,73 @@ func (h *AlertNotificationHandler) CreateDecision(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid id"})
 	}
 
-	alert, err := h.alertRepo.GetByID(c.Context(), alertID)
+	var req CreateDecisionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+	}
+
+	decision, err := h.decisionSvc.Record(c.Context(), userID, alertID, services.AlertDecisionInput{
+		Action:     req.Action,
+		Memo:       req.Memo,
+		Confidence: req.Confidence,
+	})
 	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAlertNotFound):
+			return c.Status(404).JSON(fiber.Map{"code": "NOT_FOUND", "message": "alert not found"})
+		case errors.Is(err, services.ErrInvalidDecisionAction), errors.Is(err, services.ErrInvalidDecisionConfidence):
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+		case errors.Is(err, services.ErrAlertAlreadyDecided):
+			return c.Status(409).JSON(fiber.Map{"code": "ALREADY_DECIDED", "message": err.Error()})
+		}
 		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
 	}
-	if alert == nil || alert.UserID != userID {
-		return c.Status(404).JSON(fiber.Map{"code": "NOT_FOUND", "message": "alert not found"})
-	}
 
-	var req CreateDecisionRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+	return c.Status(201).JSON(decision)
+}
+
+func (h *AlertNotificationHandler) DismissAlert(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
 	}
 
-	if !isValidAction(req.Action) {
-		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid action"})
+	alertID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid id"})
 	}
 
-	var confidence *entities.Confidence
-	if req.Confidence != nil {
-		conf := entities.Confidence(*req.Confidence)
-		confidence = &conf
+	if err := h.lifecycleSvc.Dismiss(c.Context(), userID, alertID); err != nil {
+		return alertLifecycleError(c, err)
 	}
 
-	now := time.Now().UTC()
-	decision := &entities.AlertDecision{
-		ID:         uuid.New(),
-		AlertID:    alertID,
-		UserID:     userID,
-		Action:     entities.DecisionAction(req.Action),
-		Memo:       req.Memo,
-		Confidence: confidence,
-		ExecutedAt: &now,
-		CreatedAt:  now,
+	return c.JSON(fiber.Map{"dismissed": true})
+}
+
+func (h *AlertNotificationHandler) SnoozeAlert(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
 	}
 
-	if err := h.decisionRepo.Create(c.Context(), decision); err != nil {
-		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	alertID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid id"})
 	}
 
-	if err := h.alertRepo.UpdateStatus(c.Context(), alertID, entities.AlertStatusDecided); err != nil {
-		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	var req SnoozeAlertRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
 	}
 
-	return c.Status(201).JSON(decision)
+	alert, err := h.lifecycleSvc.Snooze(c.Context(), userID, alertID, req.Minutes)
+	if err != nil {
+		return alertLifecycleError(c, err)
+	}
+	return c.JSON(alert)
 }
 
-func (h *AlertNotificationHandler) DismissAlert(c *fiber.Ctx) error {
+func (h *AlertNotificationHandler) AcknowledgeAlert(c *fiber.Ctx) error {
 	userID, err := ExtractUserID(c)
 	if err != nil {
 		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
@@ -174,19 +214,41 @@ func (h *AlertNotificationHandler) DismissAlert(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid id"})
 	}
 
-	alert, err := h.alertRepo.GetByID(c.Context(), alertID)
+	alert, err := h.lifecycleSvc.Acknowledge(c.Context(), userID, alertID)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+		return alertLifecycleError(c, err)
 	}
-	if alert == nil || alert.UserID != userID {
-		return c.Status(404).JSON(fiber.Map{"code": "NOT_FOUND", "message": "alert not found"})
+	return c.JSON(alert)
+}
+
+func (h *AlertNotificationHandler) ListEvents(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
 	}
 
-	if err := h.alertRepo.UpdateStatus(c.Context(), alertID, entities.AlertStatusExpired); err != nil {
-		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	alertID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid id"})
 	}
 
-	return c.JSON(fiber.Map{"dismissed": true})
+	events, err := h.lifecycleSvc.Events(c.Context(), userID, alertID)
+	if err != nil {
+		return alertLifecycleError(c, err)
+	}
+	return c.JSON(fiber.Map{"events": events})
+}
+
+func alertLifecycleError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrAlertNotFound):
+		return c.Status(404).JSON(fiber.Map{"code": "NOT_FOUND", "message": "alert not found"})
+	case errors.Is(err, services.ErrInvalidSnoozeMinutes):
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+	case errors.Is(err, services.ErrAlertClosed):
+		return c.Status(409).JSON(fiber.Map{"code": "ALERT_CLOSED", "message": err.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
 }
 
 func (h *AlertNotificationHandler) GetOutcome(c *fiber.Ctx) error {
@@ -220,11 +282,34 @@ func (h *AlertNotificationHandler) GetOutcome(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"outcomes": outcomes})
 }
 
-func isValidAction(action string) bool {
-	switch entities.DecisionAction(action) {
-	case entities.DecisionBuy, entities.DecisionSell, entities.DecisionHold,
-		entities.DecisionClose, entities.DecisionReduce, entities.DecisionAdd, entities.DecisionIgnore:
-		return true
+// DecisionQuality aggregates how the user's alert decisions played out over
+// one outcome period.
+func (h *AlertNotificationHandler) DecisionQuality(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	from, err := parseTimeQuery(c.Query("from"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "from is invalid"})
+	}
+	to, err := parseTimeQuery(c.Query("to"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "to is invalid"})
+	}
+
+	report, err := h.qualitySvc.Report(c.Context(), userID, services.DecisionQualityFilter{
+		Period: c.Query("period", "1d"),
+		From:   from,
+		To:     to,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidOutcomePeriod) {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
 	}
-	return false
+
+	return c.JSON(report)
 }
@@ -2,36 +2,46 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/moneyvessel/kifu/internal/domain/entities"
 	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/services"
 )
 
 type AlertRuleHandler struct {
-	ruleRepo repositories.AlertRuleRepository
+	ruleRepo    repositories.AlertRuleRepository
+	backtestSvc *services.AlertBacktestService
 }
 
-func NewAlertRuleHandler(ruleRepo repositories.AlertRuleRepository) *AlertRuleHandler {
-	return &AlertRuleHandler{ruleRepo: ruleRepo}
+func NewAlertRuleHandler(ruleRepo repositories.AlertRuleRepository, backtestSvc *services.AlertBacktestService) *AlertRuleHandler {
+	return &AlertRuleHandler{ruleRepo: ruleRepo, backtestSvc: backtestSvc}
 }
 
 type CreateAlertRuleRequest struct {
-	Name            string          `json:"name"`
-	Symbol          string          `json:"symbol"`
-	RuleType        string          `json:"rule_type"`
-	Config          json.RawMessage `json:"config"`
-	CooldownMinutes *int            `json:"cooldown_minutes,omitempty"`
+	Name                 string          `json:"name"`
+	Symbol               string          `json:"symbol"`
+	RuleType             string          `json:"rule_type"`
+	Config               json.RawMessage `json:"config"`
+	CooldownMinutes      *int            `json:"cooldown_minutes,omitempty"`
+	ExpiryMinutes        *int            `json:"expiry_minutes,omitempty"`
+	EscalateAfterMinutes *int            `json:"escalate_after_minutes,omitempty"`
 }
 
 type UpdateAlertRuleRequest struct {
-	Name            string          `json:"name"`
-	Symbol          string          `json:"symbol"`
-	RuleType        string          `json:"rule_type"`
-	Config          json.RawMessage `json:"config"`
-	CooldownMinutes *int            `json:"cooldown_minutes,omitempty"`
-	Enabled         *bool           `json:"enabled,omitempty"`
+	Name                 string          `json:"name"`
+	Symbol               string          `json:"symbol"`
+	RuleType             string          `json:"rule_type"`
+	Config               json.RawMessage `json:"config"`
+	CooldownMinutes      *int            `json:"cooldown_minutes,omitempty"`
+	ExpiryMinutes        *int            `json:"expiry_minutes,omitempty"`
+	EscalateAfterMinutes *int            `json:"escalate_after_minutes,omitempty"`
+	Enabled              *bool           `json:"enabled,omitempty"`
 }
 
 func (h *AlertRuleHandler) Create(c *fiber.Ctx) error {
@@ -57,15 +67,28 @@ func (h *AlertRuleHandler) Create(c *fiber.Ctx) error {
 	if req.CooldownMinutes != nil {
 		cooldown = *req.CooldownMinutes
 	}
+	if msg := validateAlertLifecycle(req.ExpiryMinutes, req.EscalateAfterMinutes); msg != "" {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": msg})
+	}
+	expiry := entities.DefaultAlertExpiryMinutes
+	if req.ExpiryMinutes != nil {
+		expiry = *req.ExpiryMinutes
+	}
+	escalateAfter := entities.DefaultEscalateAfterMinutes
+	if req.EscalateAfterMinutes != nil {
+		escalateAfter = *req.EscalateAfterMinutes
+	}
 
 	rule := &entities.AlertRule{
-		UserID:          userID,
-		Name:            req.Name,
-		Symbol:          req.Symbol,
-		RuleType:        entities.RuleType(req.RuleType),
-		Config:          req.Config,
-		CooldownMinutes: cooldown,
-		Enabled:         true,
+		UserID:               userID,
+		Name:                 req.Name,
+		Symbol:               req.Symbol,
+		RuleType:             entities.RuleType(req.RuleType),
+		Config:               req.Config,
+		CooldownMinutes:      cooldown,
+		ExpiryMinutes:        expiry,
+		EscalateAfterMinutes: escalateAfter,
+		Enabled:              true,
 	}
 
 	if err := h.ruleRepo.Create(c.Context(), rule); err != nil {
@@ -133,12 +156,19 @@ func (h *AlertRuleHandler) Update(c *fiber.Ctx) error {
 	if existing == nil || existing.UserID != userID {
 		return c.Status(404).JSON(fiber.Map{"code": "NOT_FOUND", "message": "rule not found"})
 	}
+	if existing.RuleType == entities.RuleTypeRiskLimit {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "risk limits are updated through /risk-limits"})
+	}
 
 	var req UpdateAlertRuleRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
 	}
 
+	if msg := validateAlertLifecycle(req.ExpiryMinutes, req.EscalateAfterMinutes); msg != "" {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": msg})
+	}
+
 	if req.Name != "" {
 		existing.Name = req.Name
 	}
@@ -154,6 +184,12 @@ func (h *AlertRuleHandler) Update(c *fiber.Ctx) error {
 	if req.CooldownMinutes != nil {
 		existing.CooldownMinutes = *req.CooldownMinutes
 	}
+	if req.ExpiryMinutes != nil {
+		existing.ExpiryMinutes = *req.ExpiryMinutes
+	}
+	if req.EscalateAfterMinutes != nil {
+		existing.EscalateAfterMinutes = *req.EscalateAfterMinutes
+	}
 	if req.Enabled != nil {
 		existing.Enabled = *req.Enabled
 	}
@@ -210,6 +246,122 @@ func (h *AlertRuleHandler) Toggle(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{"id": id, "enabled": newEnabled})
 }
 
+type AlertRuleBacktestRequest struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Interval string `json:"interval"`
+}
+
+type DraftAlertRuleBacktestRequest struct {
+	AlertRuleBacktestRequest
+	Symbol          string          `json:"symbol"`
+	RuleType        string          `json:"rule_type"`
+	Config          json.RawMessage `json:"config"`
+	CooldownMinutes *int            `json:"cooldown_minutes,omitempty"`
+}
+
+func (h *AlertRuleHandler) Backtest(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid id"})
+	}
+
+	var req AlertRuleBacktestRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+		}
+	}
+
+	rule, err := h.ruleRepo.GetByID(c.Context(), id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	if rule == nil || rule.UserID != userID {
+		return c.Status(404).JSON(fiber.Map{"code": "NOT_FOUND", "message": "rule not found"})
+	}
+
+	return h.runBacktest(c, rule, req)
+}
+
+func (h *AlertRuleHandler) BacktestDraft(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	var req DraftAlertRuleBacktestRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+	}
+
+	if req.Symbol == "" || req.RuleType == "" {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "symbol and rule_type are required"})
+	}
+	if !isValidRuleType(req.RuleType) {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid rule_type"})
+	}
+
+	cooldown := 60
+	if req.CooldownMinutes != nil {
+		cooldown = *req.CooldownMinutes
+	}
+
+	rule := &entities.AlertRule{
+		UserID:          userID,
+		Symbol:          req.Symbol,
+		RuleType:        entities.RuleType(req.RuleType),
+		Config:          req.Config,
+		CooldownMinutes: cooldown,
+	}
+
+	return h.runBacktest(c, rule, req.AlertRuleBacktestRequest)
+}
+
+func (h *AlertRuleHandler) runBacktest(c *fiber.Ctx, rule *entities.AlertRule, req AlertRuleBacktestRequest) error {
+	start, err := parseTimeQuery(req.Start)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "start is invalid"})
+	}
+	end, err := parseTimeQuery(req.End)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "end is invalid"})
+	}
+
+	backtestReq := services.AlertBacktestRequest{
+		Rule:     rule,
+		Interval: strings.TrimSpace(req.Interval),
+		End:      time.Now().UTC(),
+	}
+	if end != nil {
+		backtestReq.End = *end
+	}
+	backtestReq.Start = backtestReq.End.Add(-30 * 24 * time.Hour)
+	if start != nil {
+		backtestReq.Start = *start
+	}
+
+	result, err := h.backtestSvc.Run(c.Context(), backtestReq)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidBacktestWindow),
+			errors.Is(err, services.ErrBacktestTooManyBars),
+			errors.Is(err, services.ErrInvalidRuleConfig),
+			errors.Is(err, services.ErrUnsupportedCandleInterval):
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+		default:
+			return c.Status(502).JSON(fiber.Map{"code": "EXCHANGE_REQUEST_FAILED", "message": err.Error()})
+		}
+	}
+
+	return c.JSON(result)
+}
+
 func isValidRuleType(rt string) bool {
 	switch entities.RuleType(rt) {
 	case entities.RuleTypePriceChange, entities.RuleTypeMACross,
@@ -218,3 +370,15 @@ func isValidRuleType(rt string) bool {
 	}
 	return false
 }
+
+// validateAlertLifecycle checks the optional expiry and escalation settings
+// and returns an error message, or "" when they are acceptable.
+func validateAlertLifecycle(expiryMinutes, escalateAfterMinutes *int) string {
+	if expiryMinutes != nil && (*expiryMinutes < entities.MinAlertExpiryMinutes || *expiryMinutes > entities.MaxAlertExpiryMinutes) {
+		return fmt.Sprintf("expiry_minutes must be between %d and %d", entities.MinAlertExpiryMinutes, entities.MaxAlertExpiryMinutes)
+	}
+	if escalateAfterMinutes != nil && (*escalateAfterMinutes < 0 || *escalateAfterMinutes > entities.MaxEscalateAfterMinutes) {
+		return fmt.Sprintf("escalate_after_minutes must be between 0 and %d", entities.MaxEscalateAfterMinutes)
+	}
+	return ""
+}
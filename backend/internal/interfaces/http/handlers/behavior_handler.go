@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/moneyvessel/kifu/internal/services"
+)
+
+type BehaviorHandler struct {
+	svc *services.BehaviorPatternService
+}
+
+func NewBehaviorHandler(svc *services.BehaviorPatternService) *BehaviorHandler {
+	return &BehaviorHandler{svc: svc}
+}
+
+// Report lists detected behavior episodes between from and to (default: the
+// last 30 days) and compares them with the emotions picked in guided reviews.
+func (h *BehaviorHandler) Report(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	from, err := parseTimeQuery(c.Query("from"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "from is invalid"})
+	}
+	to, err := parseTimeQuery(c.Query("to"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "to is invalid"})
+	}
+	now := time.Now().UTC()
+	if to == nil {
+		to = &now
+	}
+	if from == nil {
+		since := to.AddDate(0, 0, -30)
+		from = &since
+	}
+	if from.After(*to) {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "from must be before to"})
+	}
+
+	report, err := h.svc.Report(c.Context(), userID, *from, *to)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	return c.Status(200).JSON(report)
+}
@@ -37,7 +37,7 @@ func (h *ExportHandler) ExportStats(c *fiber.Ctx) error {
 
 	period := c.Query("period", "30d")
 
-	stats, err := h.bubbleRepo.GetReviewStats(c.Context(), userID, period, "", "", "", "")
+	stats, err := h.bubbleRepo.GetReviewStats(c.Context(), userID, period, "", "", "", "", "")
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -2,20 +2,31 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
 	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/services"
 )
 
 type GuidedReviewHandler struct {
-	repo repositories.GuidedReviewRepository
+	repo      repositories.GuidedReviewRepository
+	userRepo  repositories.UserRepository
+	insights  *services.ReviewInsightService
+	templates *services.ReviewTemplateService
 }
 
-func NewGuidedReviewHandler(repo repositories.GuidedReviewRepository) *GuidedReviewHandler {
-	return &GuidedReviewHandler{repo: repo}
+func NewGuidedReviewHandler(repo repositories.GuidedReviewRepository, userRepo repositories.UserRepository, insights *services.ReviewInsightService, templates *services.ReviewTemplateService) *GuidedReviewHandler {
+	return &GuidedReviewHandler{repo: repo, userRepo: userRepo, insights: insights, templates: templates}
+}
+
+func reviewCadenceQuery(c *fiber.Ctx) (string, bool) {
+	cadence := strings.ToLower(strings.TrimSpace(c.Query("cadence", entities.ReviewCadenceDaily)))
+	return cadence, services.ValidReviewCadence(cadence)
 }
 
 func (h *GuidedReviewHandler) GetToday(c *fiber.Ctx) error {
@@ -24,43 +35,60 @@ func (h *GuidedReviewHandler) GetToday(c *fiber.Ctx) error {
 		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
 	}
 
-	timezone := strings.TrimSpace(c.Query("timezone"))
-	if timezone == "" {
-		timezone = "UTC"
-	}
-	location, err := time.LoadLocation(timezone)
+	// Days follow the profile's cutoff hour, in the client's ?timezone= until
+	// the user saves a timezone on the profile.
+	tradingDay, err := requestTradingDay(c, h.userRepo, userID)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "timezone is invalid"})
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+
+	cadence, ok := reviewCadenceQuery(c)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": services.ErrInvalidReviewCadence.Error()})
 	}
 
 	var date string
 	dateRaw := strings.TrimSpace(c.Query("date"))
 	if dateRaw == "" {
-		date = time.Now().In(location).Format("2006-01-02")
+		date = tradingDay.Date(time.Now())
 	} else {
-		_, parseErr := time.ParseInLocation("2006-01-02", dateRaw, location)
+		_, parseErr := time.Parse("2006-01-02", dateRaw)
 		if parseErr != nil {
 			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "date must be YYYY-MM-DD"})
 		}
 		date = dateRaw
 	}
 
-	review, items, err := h.repo.GetOrCreateToday(c.Context(), userID, date)
+	var review *entities.GuidedReview
+	var items []*entities.GuidedReviewItem
+	if cadence == entities.ReviewCadenceDaily {
+		review, items, err = h.repo.GetOrCreateToday(c.Context(), userID, date)
+	} else {
+		day, _ := time.Parse("2006-01-02", date)
+		start, end, _ := entities.ReviewPeriod(cadence, day)
+		review, items, err = h.repo.GetOrCreatePeriod(c.Context(), userID, cadence, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	template, err := h.templates.ForReview(c.Context(), review)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
 	}
 
 	return c.Status(200).JSON(fiber.Map{
-		"review": review,
-		"items":  items,
+		"review":   review,
+		"items":    items,
+		"template": template,
 	})
 }
 
 type SubmitItemRequest struct {
-	Intent       string   `json:"intent"`
-	Emotions     []string `json:"emotions"`
-	PatternMatch string   `json:"pattern_match"`
-	Memo         string   `json:"memo"`
+	Intent       string         `json:"intent"`
+	Emotions     []string       `json:"emotions"`
+	PatternMatch string         `json:"pattern_match"`
+	Memo         string         `json:"memo"`
+	Answers      map[string]any `json:"answers"`
 }
 
 func (h *GuidedReviewHandler) SubmitItem(c *fiber.Ctx) error {
@@ -89,11 +117,22 @@ func (h *GuidedReviewHandler) SubmitItem(c *fiber.Ctx) error {
 		emotionsJSON = b
 	}
 
+	// Answers to template questions are checked against the template version
+	// the review was created with.
+	answersJSON, err := h.templates.ValidateItemAnswers(c.Context(), userID, itemID, req.Answers)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidReviewAnswers) {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+
 	input := repositories.SubmitItemInput{
 		Intent:       req.Intent,
 		Emotions:     emotionsJSON,
 		PatternMatch: req.PatternMatch,
 		Memo:         req.Memo,
+		Answers:      answersJSON,
 	}
 
 	if err := h.repo.SubmitItem(c.Context(), userID, itemID, input); err != nil {
@@ -137,10 +176,123 @@ func (h *GuidedReviewHandler) GetStreak(c *fiber.Ctx) error {
 		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
 	}
 
-	streak, err := h.repo.GetStreak(c.Context(), userID)
+	cadence, ok := reviewCadenceQuery(c)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": services.ErrInvalidReviewCadence.Error()})
+	}
+
+	streak, err := h.repo.GetStreak(c.Context(), userID, cadence)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
 	}
 
 	return c.Status(200).JSON(streak)
 }
+
+// Insights breaks down answered review items by intent, emotion and emotion
+// combination between from and to (YYYY-MM-DD, default: the last 90 days),
+// with a weekly or monthly trend.
+func (h *GuidedReviewHandler) Insights(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	tradingDay, err := userTradingDay(c.Context(), h.userRepo, userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	to := tradingDay.Date(time.Now())
+	if raw := strings.TrimSpace(c.Query("to")); raw != "" {
+		if _, err := time.Parse("2006-01-02", raw); err != nil {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "to must be YYYY-MM-DD"})
+		}
+		to = raw
+	}
+	toDate, _ := time.Parse("2006-01-02", to)
+	from := toDate.AddDate(0, 0, -90).Format("2006-01-02")
+	if raw := strings.TrimSpace(c.Query("from")); raw != "" {
+		if _, err := time.Parse("2006-01-02", raw); err != nil {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "from must be YYYY-MM-DD"})
+		}
+		from = raw
+	}
+	if from > to {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "from must be before to"})
+	}
+
+	bucket := strings.ToLower(strings.TrimSpace(c.Query("bucket", services.ReviewInsightBucketWeek)))
+	report, err := h.insights.Insights(c.Context(), userID, from, to, bucket)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInsightBucket) {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "bucket must be week or month"})
+		}
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	return c.Status(200).JSON(report)
+}
+
+// ListTemplates returns every question template version for a cadence,
+// newest first.
+func (h *GuidedReviewHandler) ListTemplates(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+	cadence, ok := reviewCadenceQuery(c)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": services.ErrInvalidReviewCadence.Error()})
+	}
+
+	templates, err := h.templates.Versions(c.Context(), userID, cadence)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	return c.Status(200).JSON(fiber.Map{"cadence": cadence, "templates": templates})
+}
+
+// SaveTemplate stores a new template version for the cadence. Reviews that
+// already exist keep the version they were created with.
+func (h *GuidedReviewHandler) SaveTemplate(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	var input services.ReviewTemplateInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid json body"})
+	}
+
+	template, err := h.templates.Save(c.Context(), userID, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidReviewCadence), errors.Is(err, services.ErrInvalidReviewTemplate):
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+		case isUniqueViolation(err):
+			return c.Status(409).JSON(fiber.Map{"code": "CONFLICT", "message": "template was saved concurrently, retry"})
+		}
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	return c.Status(201).JSON(template)
+}
+
+func (h *GuidedReviewHandler) GetTemplate(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+	templateID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid template id"})
+	}
+
+	template, err := h.templates.Get(c.Context(), userID, templateID)
+	if err != nil {
+		if errors.Is(err, services.ErrReviewTemplateNotFound) {
+			return c.Status(404).JSON(fiber.Map{"code": "NOT_FOUND", "message": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	return c.Status(200).JSON(template)
+}
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"math/big"
 	"strings"
@@ -18,11 +19,13 @@ import (
 	"github.com/google/uuid"
 	"github.com/moneyvessel/kifu/internal/domain/entities"
 	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/services"
 )
 
 type ImportHandler struct {
 	portfolioRepo repositories.PortfolioRepository
 	runRepo       repositories.RunRepository
+	riskLimits    *services.RiskLimitService
 }
 
 type ImportResponse struct {
@@ -39,10 +42,11 @@ type ImportResponse struct {
 	RunID                  string        `json:"run_id"`
 }
 
-func NewImportHandler(portfolioRepo repositories.PortfolioRepository, runRepo repositories.RunRepository) *ImportHandler {
+func NewImportHandler(portfolioRepo repositories.PortfolioRepository, runRepo repositories.RunRepository, riskLimits *services.RiskLimitService) *ImportHandler {
 	return &ImportHandler{
 		portfolioRepo: portfolioRepo,
 		runRepo:       runRepo,
+		riskLimits:    riskLimits,
 	}
 }
 
@@ -216,6 +220,7 @@ func (h *ImportHandler) ImportTrades(c *fiber.Ctx) error {
 	duplicates := 0
 	rowNumber := 1
 	seen := make(map[string]struct{})
+	var earliestImported time.Time
 	issues := make([]importIssue, 0, 10)
 	issuesTruncated := false
 	addIssue := func(row int, reason string) {
@@ -313,6 +318,15 @@ func (h *ImportHandler) ImportTrades(c *fiber.Ctx) error {
 		}
 
 		imported += 1
+		if earliestImported.IsZero() || record.ExecutedAt.Before(earliestImported) {
+			earliestImported = record.ExecutedAt
+		}
+	}
+
+	if h.riskLimits != nil && !earliestImported.IsZero() {
+		if err := h.riskLimits.QueueEvaluation(c.Context(), userID, earliestImported); err != nil {
+			log.Printf("import: queue risk limit check failed (user=%s): %v", userID.String(), err)
+		}
 	}
 
 	report := strings.ToLower(strings.TrimSpace(c.Query("report")))
@@ -11,11 +11,12 @@ import (
 )
 
 type ManualPositionHandler struct {
-	repo repositories.ManualPositionRepository
+	repo          repositories.ManualPositionRepository
+	excursionRepo repositories.PositionExcursionRepository
 }
 
-func NewManualPositionHandler(repo repositories.ManualPositionRepository) *ManualPositionHandler {
-	return &ManualPositionHandler{repo: repo}
+func NewManualPositionHandler(repo repositories.ManualPositionRepository, excursionRepo repositories.PositionExcursionRepository) *ManualPositionHandler {
+	return &ManualPositionHandler{repo: repo, excursionRepo: excursionRepo}
 }
 
 type ManualPositionRequest struct {
@@ -53,6 +54,8 @@ type ManualPositionResponse struct {
 	ClosedAt     *string `json:"closed_at,omitempty"`
 	CreatedAt    string  `json:"created_at"`
 	UpdatedAt    string  `json:"updated_at"`
+
+	Excursion *entities.PositionExcursion `json:"excursion,omitempty"`
 }
 
 type ManualPositionsListResponse struct {
@@ -72,9 +75,22 @@ func (h *ManualPositionHandler) List(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
 	}
 
+	closedIDs := make([]uuid.UUID, 0)
+	for _, position := range positions {
+		if position.Status == "closed" {
+			closedIDs = append(closedIDs, position.ID)
+		}
+	}
+	excursions, err := h.excursionRepo.ListByPositions(c.Context(), userID, closedIDs)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+
 	items := make([]ManualPositionResponse, 0, len(positions))
 	for _, position := range positions {
-		items = append(items, manualPositionToResponse(position))
+		item := manualPositionToResponse(position)
+		item.Excursion = excursions[position.ID]
+		items = append(items, item)
 	}
 
 	return c.Status(200).JSON(ManualPositionsListResponse{Positions: items})
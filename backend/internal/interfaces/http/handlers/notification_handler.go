@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
+	"log"
 	"math/big"
+	"net/mail"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,29 +16,86 @@ import (
 	"github.com/moneyvessel/kifu/internal/domain/entities"
 	"github.com/moneyvessel/kifu/internal/domain/repositories"
 	"github.com/moneyvessel/kifu/internal/infrastructure/notification"
+	"github.com/moneyvessel/kifu/internal/services"
 )
 
 type NotificationHandler struct {
-	channelRepo    repositories.NotificationChannelRepository
-	verifyRepo     repositories.TelegramVerifyCodeRepository
-	tgSender       *notification.TelegramSender
-	tgBotUsername  string
+	channelRepo     repositories.NotificationChannelRepository
+	verifyRepo      repositories.TelegramVerifyCodeRepository
+	codeRepo        repositories.NotificationVerifyCodeRepository
+	deliveryRepo    repositories.NotificationDeliveryRepository
+	prefRepo        repositories.NotificationPreferenceRepository
+	dispatcher      *notification.Dispatcher
+	tgSender        *notification.TelegramSender
+	tgBot           *services.TelegramBotService
+	tgBotUsername   string
+	tgWebhookSecret string
 }
 
 func NewNotificationHandler(
 	channelRepo repositories.NotificationChannelRepository,
 	verifyRepo repositories.TelegramVerifyCodeRepository,
+	codeRepo repositories.NotificationVerifyCodeRepository,
+	deliveryRepo repositories.NotificationDeliveryRepository,
+	prefRepo repositories.NotificationPreferenceRepository,
+	dispatcher *notification.Dispatcher,
 	tgSender *notification.TelegramSender,
+	tgBot *services.TelegramBotService,
 	tgBotUsername string,
+	tgWebhookSecret string,
 ) *NotificationHandler {
 	return &NotificationHandler{
-		channelRepo:   channelRepo,
-		verifyRepo:    verifyRepo,
-		tgSender:      tgSender,
-		tgBotUsername: tgBotUsername,
+		channelRepo:     channelRepo,
+		verifyRepo:      verifyRepo,
+		codeRepo:        codeRepo,
+		deliveryRepo:    deliveryRepo,
+		prefRepo:        prefRepo,
+		dispatcher:      dispatcher,
+		tgSender:        tgSender,
+		tgBot:           tgBot,
+		tgBotUsername:   tgBotUsername,
+		tgWebhookSecret: tgWebhookSecret,
 	}
 }
 
+// notificationVerifyMaxAttempts is how many wrong codes a user may enter
+// before the outstanding verification code is burned.
+const notificationVerifyMaxAttempts = 5
+
+type ChannelConnectRequest struct {
+	Address    string `json:"address"`
+	WebhookURL string `json:"webhook_url"`
+}
+
+type ChannelVerifyRequest struct {
+	Code string `json:"code"`
+}
+
+type ChannelRoutingRequest struct {
+	Enabled    *bool    `json:"enabled,omitempty"`
+	Severities []string `json:"severities"`
+}
+
+type NotificationPreferenceRequest struct {
+	Timezone              string `json:"timezone"`
+	QuietHoursEnabled     bool   `json:"quiet_hours_enabled"`
+	QuietStart            string `json:"quiet_start"`
+	QuietEnd              string `json:"quiet_end"`
+	UrgentBypassQuiet     *bool  `json:"urgent_bypass_quiet,omitempty"`
+	DigestEnabled         bool   `json:"digest_enabled"`
+	DigestIntervalMinutes int    `json:"digest_interval_minutes"`
+}
+
+type NotificationPreferenceResponse struct {
+	Timezone              string `json:"timezone"`
+	QuietHoursEnabled     bool   `json:"quiet_hours_enabled"`
+	QuietStart            string `json:"quiet_start"`
+	QuietEnd              string `json:"quiet_end"`
+	UrgentBypassQuiet     bool   `json:"urgent_bypass_quiet"`
+	DigestEnabled         bool   `json:"digest_enabled"`
+	DigestIntervalMinutes int    `json:"digest_interval_minutes"`
+}
+
 type TelegramConnectResponse struct {
 	Code      string `json:"code"`
 	ExpiresIn int    `json:"expires_in"`
@@ -43,12 +104,23 @@ type TelegramConnectResponse struct {
 }
 
 type TelegramWebhookRequest struct {
-	Message *struct {
-		Chat struct {
-			ID int64 `json:"id"`
-		} `json:"chat"`
-		Text string `json:"text"`
-	} `json:"message"`
+	Message       *telegramMessage `json:"message"`
+	CallbackQuery *struct {
+		ID      string           `json:"id"`
+		Data    string           `json:"data"`
+		Message *telegramMessage `json:"message"`
+	} `json:"callback_query"`
+}
+
+type telegramMessage struct {
+	MessageID int64 `json:"message_id"`
+	Chat      struct {
+		ID int64 `json:"id"`
+	} `json:"chat"`
+	Text           string `json:"text"`
+	ReplyToMessage *struct {
+		MessageID int64 `json:"message_id"`
+	} `json:"reply_to_message"`
 }
 
 func (h *NotificationHandler) TelegramConnect(c *fiber.Ctx) error {
@@ -80,19 +152,42 @@ func (h *NotificationHandler) TelegramConnect(c *fiber.Ctx) error {
 	return c.JSON(resp)
 }
 
+// TelegramWebhook handles bot updates. The route sits outside JWT auth and
+// acts on the chat id in the body, so it only trusts requests carrying the
+// secret token the webhook was registered with.
 func (h *NotificationHandler) TelegramWebhook(c *fiber.Ctx) error {
+	if !h.validTelegramSecret(c.Get("X-Telegram-Bot-Api-Secret-Token")) {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid webhook secret"})
+	}
+
 	var req TelegramWebhookRequest
-	if err := c.BodyParser(&req); err != nil || req.Message == nil {
+	if err := c.BodyParser(&req); err != nil {
+		return c.SendStatus(200)
+	}
+
+	if cb := req.CallbackQuery; cb != nil && cb.Message != nil {
+		if h.tgBot != nil {
+			h.tgBot.HandleCallback(c.Context(), cb.ID, cb.Message.Chat.ID, cb.Message.MessageID, cb.Data)
+		}
+		return c.SendStatus(200)
+	}
+	if req.Message == nil {
 		return c.SendStatus(200)
 	}
 
 	text := req.Message.Text
 	chatID := req.Message.Chat.ID
 
-	// Parse /start {code}
+	// Parse /start {code}; anything else is a bot command or a prompt reply
 	if len(text) < 8 || text[:7] != "/start " {
-		if h.tgSender != nil {
-			_ = h.tgSender.SendToChatID(c.Context(), chatID, "사용법: /start <인증코드>")
+		if h.tgBot != nil {
+			var replyTo int64
+			if req.Message.ReplyToMessage != nil {
+				replyTo = req.Message.ReplyToMessage.MessageID
+			}
+			h.tgBot.HandleMessage(c.Context(), chatID, text, replyTo)
+		} else if h.tgSender != nil {
+			_ = h.tgSender.SendToChatID(c.Context(), chatID, "사용법: /start &lt;인증코드&gt;")
 		}
 		return c.SendStatus(200)
 	}
@@ -111,7 +206,7 @@ func (h *NotificationHandler) TelegramWebhook(c *fiber.Ctx) error {
 		return c.SendStatus(200)
 	}
 
-	// Save notification channel
+	// Save notification channel, keeping the severity routing of an earlier link
 	configJSON, _ := json.Marshal(entities.TelegramConfig{ChatID: chatID})
 	channel := &entities.NotificationChannel{
 		ID:          uuid.New(),
@@ -122,6 +217,9 @@ func (h *NotificationHandler) TelegramWebhook(c *fiber.Ctx) error {
 		Verified:    true,
 		CreatedAt:   time.Now().UTC(),
 	}
+	if existing, err := h.channelRepo.GetByUserAndType(c.Context(), verifyCode.UserID, entities.ChannelTelegram); err == nil && existing != nil {
+		channel.Severities = existing.Severities
+	}
 
 	if err := h.channelRepo.Upsert(c.Context(), channel); err != nil {
 		return c.SendStatus(200)
@@ -134,6 +232,13 @@ func (h *NotificationHandler) TelegramWebhook(c *fiber.Ctx) error {
 	return c.SendStatus(200)
 }
 
+func (h *NotificationHandler) validTelegramSecret(got string) bool {
+	if h.tgWebhookSecret == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(h.tgWebhookSecret)) == 1
+}
+
 func (h *NotificationHandler) TelegramDisconnect(c *fiber.Ctx) error {
 	userID, err := ExtractUserID(c)
 	if err != nil {
@@ -159,23 +264,397 @@ func (h *NotificationHandler) ListChannels(c *fiber.Ctx) error {
 	}
 
 	type channelItem struct {
-		Type     string `json:"type"`
-		Enabled  bool   `json:"enabled"`
-		Verified bool   `json:"verified"`
+		Type       string   `json:"type"`
+		Enabled    bool     `json:"enabled"`
+		Verified   bool     `json:"verified"`
+		Severities []string `json:"severities"`
 	}
 
 	items := make([]channelItem, 0, len(channels))
 	for _, ch := range channels {
 		items = append(items, channelItem{
-			Type:     string(ch.ChannelType),
-			Enabled:  ch.Enabled,
-			Verified: ch.Verified,
+			Type:       string(ch.ChannelType),
+			Enabled:    ch.Enabled,
+			Verified:   ch.Verified,
+			Severities: ch.Severities,
 		})
 	}
 
 	return c.JSON(fiber.Map{"channels": items})
 }
 
+// ConnectChannel registers an email, Discord or Slack channel and sends a
+// verification code through it. The user confirms with VerifyChannel.
+func (h *NotificationHandler) ConnectChannel(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	channelType, ok := parseVerifiableChannelType(c.Params("type"))
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_CHANNEL", "message": "unsupported channel type"})
+	}
+	if !h.dispatcher.Supports(channelType) {
+		return c.Status(503).JSON(fiber.Map{"code": "CHANNEL_UNAVAILABLE", "message": "channel is not configured on this server"})
+	}
+
+	var req ChannelConnectRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+	}
+
+	var config interface{}
+	switch channelType {
+	case entities.ChannelEmail:
+		addr, err := mail.ParseAddress(strings.TrimSpace(req.Address))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "address is invalid"})
+		}
+		config = entities.EmailConfig{Address: addr.Address}
+	default:
+		if err := notification.ValidateWebhookURL(channelType, req.WebhookURL); err != nil {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+		}
+		config = entities.WebhookConfig{WebhookURL: strings.TrimSpace(req.WebhookURL)}
+	}
+
+	existing, err := h.channelRepo.GetByUserAndType(c.Context(), userID, channelType)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+
+	configJSON, _ := json.Marshal(config)
+	channel := &entities.NotificationChannel{
+		ID:          uuid.New(),
+		UserID:      userID,
+		ChannelType: channelType,
+		Config:      configJSON,
+		Enabled:     true,
+		Verified:    false,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if existing != nil {
+		// Reconnecting changes the destination, not the user's severity routing.
+		channel.Severities = existing.Severities
+	}
+	if err := h.channelRepo.Upsert(c.Context(), channel); err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+
+	code := generateVerifyCode()
+	verifyCode := &entities.NotificationVerifyCode{
+		UserID:      userID,
+		ChannelType: channelType,
+		Code:        code,
+		ExpiresAt:   time.Now().UTC().Add(10 * time.Minute),
+	}
+	if err := h.codeRepo.Create(c.Context(), verifyCode); err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+
+	msg := notification.Message{
+		Title:    "kifu 알림 채널 인증",
+		Body:     fmt.Sprintf("인증코드: %s\n앱에서 이 코드를 입력하면 알림 연동이 완료됩니다. (10분 유효)", code),
+		Severity: string(entities.AlertSeverityNormal),
+	}
+	if err := h.dispatcher.SendDirect(c.Context(), channel, msg); err != nil {
+		log.Printf("notification: send %s verification failed: %v", channelType, err)
+		return c.Status(502).JSON(fiber.Map{"code": "CHANNEL_SEND_FAILED", "message": "failed to send verification code"})
+	}
+
+	return c.JSON(fiber.Map{
+		"channel_type": channelType,
+		"expires_in":   600,
+		"message":      "인증코드를 보냈습니다. 받은 코드를 입력하세요",
+	})
+}
+
+func (h *NotificationHandler) VerifyChannel(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	channelType, ok := parseVerifiableChannelType(c.Params("type"))
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_CHANNEL", "message": "unsupported channel type"})
+	}
+
+	var req ChannelVerifyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+	}
+
+	verifyCode, err := h.codeRepo.FindValidCode(c.Context(), userID, channelType, strings.TrimSpace(req.Code))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	if verifyCode == nil {
+		locked, err := h.codeRepo.RecordFailedAttempt(c.Context(), userID, channelType, notificationVerifyMaxAttempts)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+		}
+		if locked {
+			return c.Status(429).JSON(fiber.Map{"code": "TOO_MANY_ATTEMPTS", "message": "인증 시도 횟수를 초과했습니다. 인증코드를 다시 요청하세요."})
+		}
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_CODE", "message": "유효하지 않거나 만료된 인증코드입니다."})
+	}
+
+	channel, err := h.channelRepo.GetByUserAndType(c.Context(), userID, channelType)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	if channel == nil {
+		return c.Status(404).JSON(fiber.Map{"code": "NOT_FOUND", "message": "channel not found"})
+	}
+
+	if err := h.codeRepo.MarkUsed(c.Context(), verifyCode.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+
+	channel.Verified = true
+	if err := h.channelRepo.Upsert(c.Context(), channel); err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"channel_type": channelType, "verified": true})
+}
+
+func (h *NotificationHandler) DisconnectChannel(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	channelType, ok := parseChannelType(c.Params("type"))
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_CHANNEL", "message": "unsupported channel type"})
+	}
+
+	if err := h.channelRepo.DeleteByUserAndType(c.Context(), userID, channelType); err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"disconnected": true})
+}
+
+// UpdateRouting chooses which alert severities a channel receives.
+func (h *NotificationHandler) UpdateRouting(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	channelType, ok := parseChannelType(c.Params("type"))
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_CHANNEL", "message": "unsupported channel type"})
+	}
+
+	var req ChannelRoutingRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+	}
+
+	severities := make([]string, 0, len(req.Severities))
+	for _, severity := range req.Severities {
+		switch entities.AlertSeverity(severity) {
+		case entities.AlertSeverityNormal, entities.AlertSeverityUrgent:
+			severities = append(severities, severity)
+		default:
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid severity"})
+		}
+	}
+
+	channel, err := h.channelRepo.GetByUserAndType(c.Context(), userID, channelType)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	if channel == nil {
+		return c.Status(404).JSON(fiber.Map{"code": "NOT_FOUND", "message": "channel not found"})
+	}
+
+	enabled := channel.Enabled
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	if err := h.channelRepo.UpdateRouting(c.Context(), userID, channelType, enabled, severities); err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"type": channelType, "enabled": enabled, "severities": severities})
+}
+
+func (h *NotificationHandler) ListDeliveries(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	status := strings.TrimSpace(c.Query("status"))
+	switch entities.DeliveryStatus(status) {
+	case "", entities.DeliveryStatusPending, entities.DeliveryStatusSent, entities.DeliveryStatusFailed, entities.DeliveryStatusDead:
+	default:
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid status"})
+	}
+
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	deliveries, err := h.deliveryRepo.ListByUser(c.Context(), userID, status, limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	if deliveries == nil {
+		deliveries = []*entities.NotificationDelivery{}
+	}
+
+	return c.JSON(fiber.Map{"deliveries": deliveries})
+}
+
+// RetryDelivery puts a dead-lettered delivery back in the outbox.
+func (h *NotificationHandler) RetryDelivery(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	deliveryID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid delivery id"})
+	}
+
+	requeued, err := h.deliveryRepo.Requeue(c.Context(), userID, deliveryID, time.Now().UTC())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	if !requeued {
+		return c.Status(404).JSON(fiber.Map{"code": "NOT_FOUND", "message": "dead delivery not found"})
+	}
+
+	return c.JSON(fiber.Map{"requeued": true})
+}
+
+func (h *NotificationHandler) GetPreferences(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	pref, err := h.prefRepo.GetByUser(c.Context(), userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	if pref == nil {
+		pref = entities.DefaultNotificationPreference(userID)
+	}
+
+	return c.JSON(toNotificationPreferenceResponse(pref))
+}
+
+func (h *NotificationHandler) UpdatePreferences(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	var req NotificationPreferenceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+	}
+
+	// An empty timezone keeps the one on the profile.
+	pref := entities.DefaultNotificationPreference(userID)
+	pref.Timezone = strings.TrimSpace(req.Timezone)
+	if pref.Timezone != "" {
+		if _, err := time.LoadLocation(pref.Timezone); err != nil {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "timezone is invalid"})
+		}
+	}
+	pref.QuietHoursEnabled = req.QuietHoursEnabled
+	if req.QuietStart != "" {
+		minute, ok := parseClockMinute(req.QuietStart)
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "quiet_start must be HH:MM"})
+		}
+		pref.QuietStartMinute = minute
+	}
+	if req.QuietEnd != "" {
+		minute, ok := parseClockMinute(req.QuietEnd)
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "quiet_end must be HH:MM"})
+		}
+		pref.QuietEndMinute = minute
+	}
+	if req.UrgentBypassQuiet != nil {
+		pref.UrgentBypassQuiet = *req.UrgentBypassQuiet
+	}
+	pref.DigestEnabled = req.DigestEnabled
+	if req.DigestIntervalMinutes != 0 {
+		if req.DigestIntervalMinutes < 15 || req.DigestIntervalMinutes > 1440 {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "digest_interval_minutes must be between 15 and 1440"})
+		}
+		pref.DigestIntervalMinutes = req.DigestIntervalMinutes
+	}
+	pref.UpdatedAt = time.Now().UTC()
+
+	if err := h.prefRepo.Upsert(c.Context(), pref); err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	saved, err := h.prefRepo.GetByUser(c.Context(), userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	if saved != nil {
+		pref = saved
+	}
+
+	return c.JSON(toNotificationPreferenceResponse(pref))
+}
+
+func toNotificationPreferenceResponse(pref *entities.NotificationPreference) NotificationPreferenceResponse {
+	return NotificationPreferenceResponse{
+		Timezone:              pref.Timezone,
+		QuietHoursEnabled:     pref.QuietHoursEnabled,
+		QuietStart:            formatClockMinute(pref.QuietStartMinute),
+		QuietEnd:              formatClockMinute(pref.QuietEndMinute),
+		UrgentBypassQuiet:     pref.UrgentBypassQuiet,
+		DigestEnabled:         pref.DigestEnabled,
+		DigestIntervalMinutes: pref.DigestIntervalMinutes,
+	}
+}
+
+func parseClockMinute(raw string) (int, bool) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(raw))
+	if err != nil {
+		return 0, false
+	}
+	return parsed.Hour()*60 + parsed.Minute(), true
+}
+
+func formatClockMinute(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+func parseChannelType(raw string) (entities.ChannelType, bool) {
+	channelType := entities.ChannelType(strings.ToLower(strings.TrimSpace(raw)))
+	switch channelType {
+	case entities.ChannelTelegram, entities.ChannelEmail, entities.ChannelDiscord, entities.ChannelSlack:
+		return channelType, true
+	}
+	return "", false
+}
+
+func parseVerifiableChannelType(raw string) (entities.ChannelType, bool) {
+	channelType, ok := parseChannelType(raw)
+	if !ok || channelType == entities.ChannelTelegram {
+		return "", false
+	}
+	return channelType, true
+}
+
 func generateVerifyCode() string {
 	max := big.NewInt(999999)
 	n, err := rand.Int(rand.Reader, max)
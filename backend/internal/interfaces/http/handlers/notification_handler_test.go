@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestTelegramWebhookRejectsMissingOrWrongSecret(t *testing.T) {
+	t.Parallel()
+
+	handler := NewNotificationHandler(nil, nil, nil, nil, nil, nil, nil, nil, "", "webhook-secret")
+	app := fiber.New()
+	app.Post("/api/v1/webhook/telegram", handler.TelegramWebhook)
+
+	body := []byte(`{"message":{"message_id":1,"chat":{"id":42},"text":"/pnl"}}`)
+	cases := []struct {
+		name   string
+		secret string
+		want   int
+	}{
+		{name: "missing", secret: "", want: http.StatusUnauthorized},
+		{name: "wrong", secret: "guess", want: http.StatusUnauthorized},
+		{name: "valid", secret: "webhook-secret", want: http.StatusOK},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/webhook/telegram", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if tc.secret != "" {
+			req.Header.Set("X-Telegram-Bot-Api-Secret-Token", tc.secret)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", tc.name, err)
+		}
+		if resp.StatusCode != tc.want {
+			t.Fatalf("%s: expected status %d, got %d", tc.name, tc.want, resp.StatusCode)
+		}
+	}
+}
+
+func TestTelegramWebhookRejectsEverythingWithoutConfiguredSecret(t *testing.T) {
+	t.Parallel()
+
+	handler := NewNotificationHandler(nil, nil, nil, nil, nil, nil, nil, nil, "", "")
+	app := fiber.New()
+	app.Post("/api/v1/webhook/telegram", handler.TelegramWebhook)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhook/telegram", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Telegram-Bot-Api-Secret-Token", "")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d", resp.StatusCode)
+	}
+}
@@ -18,10 +18,13 @@ type OnchainHandler struct {
 	limiter *onchainIPRateLimiter
 }
 
+// OnchainQuickCheckRequest names one chain, or several in Chains to
+// aggregate them into one pack.
 type OnchainQuickCheckRequest struct {
-	Chain   string `json:"chain"`
-	Address string `json:"address"`
-	Range   string `json:"range"`
+	Chain   string   `json:"chain"`
+	Chains  []string `json:"chains"`
+	Address string   `json:"address"`
+	Range   string   `json:"range"`
 }
 
 func NewOnchainHandler(service *services.OnchainPackService) *OnchainHandler {
@@ -62,21 +65,23 @@ func (h *OnchainHandler) QuickCheck(c *fiber.Ctx) error {
 			"message": "invalid request body",
 		})
 	}
-	log.Printf("[incident:onchain] severity=info event=handler.request_received request_id=%s ip=%s chain=%s address=%s range=%s", requestID, clientIP, strings.ToLower(strings.TrimSpace(req.Chain)), strings.ToLower(strings.TrimSpace(req.Address)), strings.TrimSpace(req.Range))
+	requestedChains := strings.ToLower(strings.Join(append([]string{strings.TrimSpace(req.Chain)}, req.Chains...), ","))
+	log.Printf("[incident:onchain] severity=info event=handler.request_received request_id=%s ip=%s chain=%s address=%s range=%s", requestID, clientIP, requestedChains, strings.ToLower(strings.TrimSpace(req.Address)), strings.TrimSpace(req.Range))
 
 	start := time.Now()
 	response, err := h.service.BuildQuickCheck(c.Context(), services.OnchainQuickCheckRequest{
 		Chain:   req.Chain,
+		Chains:  req.Chains,
 		Address: req.Address,
 		Range:   req.Range,
 	})
 	elapsedMs := time.Since(start).Milliseconds()
 	if err != nil {
-		log.Printf("[incident:onchain] severity=error event=service.call_error request_id=%s ip=%s chain=%s address=%s range=%s elapsed_ms=%d err=%v", requestID, clientIP, strings.ToLower(strings.TrimSpace(req.Chain)), strings.ToLower(strings.TrimSpace(req.Address)), strings.TrimSpace(req.Range), elapsedMs, err)
+		log.Printf("[incident:onchain] severity=error event=service.call_error request_id=%s ip=%s chain=%s address=%s range=%s elapsed_ms=%d err=%v", requestID, clientIP, requestedChains, strings.ToLower(strings.TrimSpace(req.Address)), strings.TrimSpace(req.Range), elapsedMs, err)
 		switch {
 		case errors.Is(err, services.ErrInvalidChain):
 			log.Printf("[incident:onchain] severity=warning event=handler.validation_fail request_id=%s code=INVALID_CHAIN ip=%s", requestID, clientIP)
-			return c.Status(400).JSON(fiber.Map{"code": "INVALID_CHAIN", "message": "only base chain is supported"})
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_CHAIN", "message": "chain must be one of " + strings.Join(h.availableChainIDs(), ", ")})
 		case errors.Is(err, services.ErrInvalidAddress):
 			log.Printf("[incident:onchain] severity=warning event=handler.validation_fail request_id=%s code=INVALID_ADDRESS ip=%s address=%s", requestID, clientIP, strings.ToLower(strings.TrimSpace(req.Address)))
 			return c.Status(400).JSON(fiber.Map{"code": "INVALID_ADDRESS", "message": "address must be 0x + 40 hex"})
@@ -93,6 +98,32 @@ func (h *OnchainHandler) QuickCheck(c *fiber.Ctx) error {
 	return c.Status(200).JSON(response)
 }
 
+// Chains lists the registered chains. Available is false for chains
+// without a configured Alchemy URL, which quick check rejects.
+func (h *OnchainHandler) Chains(c *fiber.Ctx) error {
+	chains := make([]fiber.Map, 0, len(services.OnchainChains()))
+	for _, chain := range services.OnchainChains() {
+		chains = append(chains, fiber.Map{
+			"id":            chain.ID,
+			"name":          chain.Name,
+			"chain_id":      chain.ChainID,
+			"block_time_ms": chain.BlockTime.Milliseconds(),
+			"available":     h.service != nil && h.service.HasProvider(chain.ID),
+		})
+	}
+	return c.Status(200).JSON(fiber.Map{"chains": chains})
+}
+
+func (h *OnchainHandler) availableChainIDs() []string {
+	ids := []string{}
+	for _, chain := range services.OnchainChains() {
+		if h.service != nil && h.service.HasProvider(chain.ID) {
+			ids = append(ids, chain.ID)
+		}
+	}
+	return ids
+}
+
 type onchainIPRateLimiter struct {
 	mu       sync.Mutex
 	limiters map[string]*rate.Limiter
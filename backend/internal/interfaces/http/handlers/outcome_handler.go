@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/moneyvessel/kifu/internal/domain/entities"
 	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/services"
 )
 
 type OutcomeHandler struct {
@@ -24,6 +27,16 @@ type OutcomeResponse struct {
 	ReferencePrice string  `json:"reference_price"`
 	OutcomePrice   *string `json:"outcome_price"`
 	PnLPercent     *string `json:"pnl_percent"`
+	PriceSource    *string `json:"price_source,omitempty"`
+}
+
+type OutcomeHorizonsRequest struct {
+	Horizons []string `json:"horizons"`
+}
+
+type OutcomeHorizonsResponse struct {
+	Defaults []string `json:"defaults"`
+	Horizons []string `json:"horizons"`
 }
 
 func (h *OutcomeHandler) ListByBubble(c *fiber.Ctx) error {
@@ -58,7 +71,11 @@ func (h *OutcomeHandler) ListByBubble(c *fiber.Ctx) error {
 		outcomeMap[outcome.Period] = outcome
 	}
 
-	periods := []string{"1h", "4h", "1d"}
+	custom, err := h.outcomeRepo.ListUserHorizons(c.Context(), userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	periods := append(append([]string{}, services.DefaultOutcomePeriods...), custom...)
 	items := make([]OutcomeResponse, 0, len(periods))
 	for _, period := range periods {
 		if outcome, ok := outcomeMap[period]; ok {
@@ -69,6 +86,7 @@ func (h *OutcomeHandler) ListByBubble(c *fiber.Ctx) error {
 				ReferencePrice: bubble.Price,
 				OutcomePrice:   &outcomePrice,
 				PnLPercent:     &pnl,
+				PriceSource:    outcome.PriceSource,
 			})
 			continue
 		}
@@ -83,3 +101,45 @@ func (h *OutcomeHandler) ListByBubble(c *fiber.Ctx) error {
 
 	return c.Status(200).JSON(fiber.Map{"outcomes": items})
 }
+
+// GetHorizons lists the outcome horizons computed for the user: the defaults
+// plus their own.
+func (h *OutcomeHandler) GetHorizons(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	horizons, err := h.outcomeRepo.ListUserHorizons(c.Context(), userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	return c.Status(200).JSON(OutcomeHorizonsResponse{Defaults: services.DefaultOutcomePeriods, Horizons: horizons})
+}
+
+// UpdateHorizons replaces the user's own horizons, e.g. ["15m", "3d", "1w",
+// "next_bubble"]. Outcomes for a new horizon are backfilled by the outcome
+// job, oldest bubbles first.
+func (h *OutcomeHandler) UpdateHorizons(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	var req OutcomeHorizonsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+	}
+
+	horizons, err := services.NormalizeOutcomeHorizons(req.Horizons)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidOutcomeHorizon) || errors.Is(err, services.ErrTooManyOutcomeHorizons) {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_HORIZON", "message": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	if err := h.outcomeRepo.ReplaceUserHorizons(c.Context(), userID, horizons); err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	return c.Status(200).JSON(OutcomeHorizonsResponse{Defaults: services.DefaultOutcomePeriods, Horizons: horizons})
+}
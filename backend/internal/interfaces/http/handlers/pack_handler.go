@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -198,3 +199,121 @@ func (h *PackHandler) GetByID(c *fiber.Ctx) error {
 
 	return c.Status(200).JSON(pack)
 }
+
+// Compare aligns two of the user's packs and returns field-level deltas from
+// base to target, along with the evidence trades that were added or dropped.
+func (h *PackHandler) Compare(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	baseID, err := uuid.Parse(strings.TrimSpace(c.Query("base")))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "base is invalid"})
+	}
+	targetID, err := uuid.Parse(strings.TrimSpace(c.Query("target")))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "target is invalid"})
+	}
+
+	base, err := h.summaryPackRepo.GetByID(c.Context(), userID, baseID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	if base == nil {
+		return c.Status(404).JSON(fiber.Map{"code": "PACK_NOT_FOUND", "message": "base pack not found"})
+	}
+	target, err := h.summaryPackRepo.GetByID(c.Context(), userID, targetID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	if target == nil {
+		return c.Status(404).JSON(fiber.Map{"code": "PACK_NOT_FOUND", "message": "target pack not found"})
+	}
+
+	comparison, err := services.CompareSummaryPacks(base, target)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	return c.Status(200).JSON(comparison)
+}
+
+// Report renders a pack as a self-contained HTML document, or as a PDF with
+// format=pdf. The content hash is embedded in both and sent as the ETag.
+func (h *PackHandler) Report(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	packID, err := uuid.Parse(c.Params("pack_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "pack_id is invalid"})
+	}
+	format := strings.ToLower(strings.TrimSpace(c.Query("format", "html")))
+	if format != "html" && format != "pdf" {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "format must be html or pdf"})
+	}
+
+	pack, err := h.summaryPackRepo.GetByID(c.Context(), userID, packID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	if pack == nil {
+		return c.Status(404).JSON(fiber.Map{"code": "PACK_NOT_FOUND", "message": "pack not found"})
+	}
+	pack.EvidenceBundle, err = h.summaryPackRepo.GetEvidenceBundle(c.Context(), userID, packID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+
+	report, err := h.summaryPackSvc.BuildReport(c.Context(), pack)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+
+	var body []byte
+	if format == "pdf" {
+		body, err = services.RenderPackReportPDF(report)
+		c.Set(fiber.HeaderContentType, "application/pdf")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="kifu-pack-%s.pdf"`, pack.PackID))
+	} else {
+		body, err = services.RenderPackReportHTML(report)
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	c.Set(fiber.HeaderETag, `"`+pack.ContentHash+`"`)
+	c.Set("X-Content-Hash", pack.ContentHash)
+	return c.Status(200).Send(body)
+}
+
+// Evidence downloads the zip bundle the pack's evidence_pack_ref points at.
+// Packs generated before bundles were stored have none.
+func (h *PackHandler) Evidence(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	packID, err := uuid.Parse(c.Params("pack_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "pack_id is invalid"})
+	}
+
+	bundle, err := h.summaryPackRepo.GetEvidenceBundle(c.Context(), userID, packID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	if bundle == nil {
+		return c.Status(404).JSON(fiber.Map{"code": "EVIDENCE_NOT_FOUND", "message": "evidence bundle not found"})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="kifu-pack-%s-evidence.zip"`, packID))
+	c.Set(fiber.HeaderETag, `"`+bundle.SHA256+`"`)
+	c.Set("X-Evidence-Sha256", bundle.SHA256)
+	return c.Status(200).Send(bundle.Archive)
+}
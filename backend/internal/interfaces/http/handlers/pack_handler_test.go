@@ -48,6 +48,7 @@ func (f *fakeRunRepo) GetLatestCompletedRun(ctx context.Context, userID uuid.UUI
 
 type fakeSummaryPackRepo struct {
 	createErr error
+	bundle    *entities.SummaryPackEvidenceBundle
 }
 
 func (f *fakeSummaryPackRepo) Create(_ context.Context, _ *entities.SummaryPack) error {
@@ -62,6 +63,17 @@ func (f *fakeSummaryPackRepo) GetLatest(_ context.Context, _ uuid.UUID, _ string
 	return nil, nil
 }
 
+func (f *fakeSummaryPackRepo) GetEvidenceBundle(_ context.Context, userID uuid.UUID, packID uuid.UUID) (*entities.SummaryPackEvidenceBundle, error) {
+	if f.bundle == nil || f.bundle.UserID != userID || f.bundle.PackID != packID {
+		return nil, nil
+	}
+	return f.bundle, nil
+}
+
+func (f *fakeSummaryPackRepo) DeleteOlderThan(_ context.Context, _ time.Time) (int64, error) {
+	return 0, nil
+}
+
 type fakeTradeRepo struct{}
 
 func (f *fakeTradeRepo) ListByTimeRange(_ context.Context, _ uuid.UUID, _ time.Time, _ time.Time) ([]*entities.Trade, error) {
@@ -69,7 +81,7 @@ func (f *fakeTradeRepo) ListByTimeRange(_ context.Context, _ uuid.UUID, _ time.T
 }
 
 func newTestPackHandler(runRepo repositories.RunRepository, summaryPackRepo repositories.SummaryPackRepository) *PackHandler {
-	summaryPackSvc := services.NewSummaryPackService(&fakeTradeRepo{})
+	summaryPackSvc := services.NewSummaryPackService(&fakeTradeRepo{}, nil)
 	return NewPackHandler(runRepo, summaryPackRepo, summaryPackSvc)
 }
 
@@ -201,3 +213,40 @@ func TestPackGenerateLatestUsesCallerScopeOnly(t *testing.T) {
 }
 
 func ptrTime(v time.Time) *time.Time { return &v }
+
+func TestPackEvidenceDownload(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	bundle := &entities.SummaryPackEvidenceBundle{PackID: uuid.New(), UserID: userID, Archive: []byte("PK\x03\x04zip"), SHA256: "abc123"}
+	handler := newTestPackHandler(&fakeRunRepo{}, &fakeSummaryPackRepo{bundle: bundle})
+	app := fiber.New()
+	app.Get("/api/v1/packs/:pack_id/evidence", func(c *fiber.Ctx) error {
+		c.Locals("userID", userID)
+		c.Request().Header.Set("Authorization", "Bearer test-token")
+		return handler.Evidence(c)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/packs/"+bundle.PackID.String()+"/evidence", nil), -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body := new(bytes.Buffer)
+	_, _ = body.ReadFrom(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/zip" || body.String() != string(bundle.Archive) {
+		t.Fatalf("status=%d content-type=%q body=%q", resp.StatusCode, resp.Header.Get("Content-Type"), body.String())
+	}
+	if resp.Header.Get("X-Evidence-Sha256") != bundle.SHA256 {
+		t.Fatalf("X-Evidence-Sha256=%q want=%q", resp.Header.Get("X-Evidence-Sha256"), bundle.SHA256)
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/packs/"+uuid.NewString()+"/evidence", nil), -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("status=%d want=%d for a pack without a bundle", resp.StatusCode, http.StatusNotFound)
+	}
+}
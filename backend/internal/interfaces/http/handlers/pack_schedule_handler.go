@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/services"
+)
+
+type PackScheduleHandler struct {
+	svc *services.SummaryPackScheduleService
+}
+
+func NewPackScheduleHandler(svc *services.SummaryPackScheduleService) *PackScheduleHandler {
+	return &PackScheduleHandler{svc: svc}
+}
+
+// UpdatePackScheduleRequest configures one schedule. Times are in the
+// profile timezone. Omitted fields take their defaults: the frequency's
+// range, 09:00, Monday and the 1st of the month.
+type UpdatePackScheduleRequest struct {
+	Range      string                 `json:"range"`
+	SendHour   *int                   `json:"send_hour"`
+	Weekday    *int                   `json:"weekday"`
+	DayOfMonth *int                   `json:"day_of_month"`
+	Channels   []entities.ChannelType `json:"channels"`
+	Enabled    *bool                  `json:"enabled"`
+}
+
+func (h *PackScheduleHandler) List(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	schedules, err := h.svc.ListSchedules(c.Context(), userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	if schedules == nil {
+		schedules = []*entities.SummaryPackSchedule{}
+	}
+	return c.Status(200).JSON(fiber.Map{"schedules": schedules})
+}
+
+// Update creates or replaces the schedule for the frequency in the path.
+func (h *PackScheduleHandler) Update(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	var req UpdatePackScheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid request body"})
+	}
+
+	schedule := &entities.SummaryPackSchedule{
+		UserID:     userID,
+		Frequency:  entities.PackScheduleFrequency(strings.ToLower(c.Params("frequency"))),
+		Range:      strings.TrimSpace(req.Range),
+		SendHour:   9,
+		Weekday:    1,
+		DayOfMonth: 1,
+		Channels:   req.Channels,
+		Enabled:    true,
+	}
+	if req.SendHour != nil {
+		schedule.SendHour = *req.SendHour
+	}
+	if req.Weekday != nil {
+		schedule.Weekday = *req.Weekday
+	}
+	if req.DayOfMonth != nil {
+		schedule.DayOfMonth = *req.DayOfMonth
+	}
+	if req.Enabled != nil {
+		schedule.Enabled = *req.Enabled
+	}
+
+	if err := h.svc.SaveSchedule(c.Context(), schedule); err != nil {
+		if errors.Is(err, services.ErrInvalidPackSchedule) {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	return c.Status(200).JSON(schedule)
+}
+
+func (h *PackScheduleHandler) Delete(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	frequency := entities.PackScheduleFrequency(strings.ToLower(c.Params("frequency")))
+	deleted, err := h.svc.DeleteSchedule(c.Context(), userID, frequency)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	if !deleted {
+		return c.Status(404).JSON(fiber.Map{"code": "SCHEDULE_NOT_FOUND", "message": "pack schedule not found"})
+	}
+	return c.SendStatus(204)
+}
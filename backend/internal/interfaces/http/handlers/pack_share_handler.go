@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/services"
+)
+
+type PackShareHandler struct {
+	svc *services.SummaryPackShareService
+}
+
+func NewPackShareHandler(svc *services.SummaryPackShareService) *PackShareHandler {
+	return &PackShareHandler{svc: svc}
+}
+
+// CreatePackShareRequest configures a share link. ExpiresInHours defaults
+// to a week; Redact takes "amounts" and "evidence".
+type CreatePackShareRequest struct {
+	ExpiresInHours int      `json:"expires_in_hours"`
+	Redact         []string `json:"redact"`
+}
+
+func (h *PackShareHandler) Create(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	packID, err := uuid.Parse(c.Params("pack_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "pack_id is invalid"})
+	}
+	var req CreatePackShareRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid request body"})
+		}
+	}
+	if req.ExpiresInHours < 0 {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "expires_in_hours must be positive"})
+	}
+
+	created, err := h.svc.CreateShare(c.Context(), userID, packID, time.Duration(req.ExpiresInHours)*time.Hour, req.Redact)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidPackShare):
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+		case errors.Is(err, services.ErrPackShareNotFound):
+			return c.Status(404).JSON(fiber.Map{"code": "PACK_NOT_FOUND", "message": "pack not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	return c.Status(201).JSON(created)
+}
+
+// List returns the pack's share links, revoked and expired ones included,
+// with their view counts.
+func (h *PackShareHandler) List(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	packID, err := uuid.Parse(c.Params("pack_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "pack_id is invalid"})
+	}
+	shares, err := h.svc.ListShares(c.Context(), userID, packID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	return c.Status(200).JSON(fiber.Map{"shares": shares})
+}
+
+func (h *PackShareHandler) Revoke(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	shareID, err := uuid.Parse(c.Params("share_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "share_id is invalid"})
+	}
+	if err := h.svc.RevokeShare(c.Context(), userID, shareID); err != nil {
+		if errors.Is(err, services.ErrPackShareNotFound) {
+			return c.Status(404).JSON(fiber.Map{"code": "SHARE_NOT_FOUND", "message": "share not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	return c.SendStatus(204)
+}
+
+// PublicKey serves the key share tokens are signed with. It needs no login.
+func (h *PackShareHandler) PublicKey(c *fiber.Ctx) error {
+	key, err := h.svc.PublicKey()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	return c.Status(200).JSON(key)
+}
+
+// PublicPack serves the pack behind a share token. It needs no login.
+func (h *PackShareHandler) PublicPack(c *fiber.Ctx) error {
+	shared, err := h.svc.Open(c.Context(), c.Params("token"))
+	if err != nil {
+		return publicShareError(c, err)
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Status(200).JSON(shared)
+}
+
+// PublicReport is the HTML or PDF report for a share token, with the
+// share's redactions applied. It needs no login.
+func (h *PackShareHandler) PublicReport(c *fiber.Ctx) error {
+	format := strings.ToLower(strings.TrimSpace(c.Query("format", "html")))
+	if format != "html" && format != "pdf" {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "format must be html or pdf"})
+	}
+
+	report, err := h.svc.OpenReport(c.Context(), c.Params("token"))
+	if err != nil {
+		return publicShareError(c, err)
+	}
+
+	var body []byte
+	if format == "pdf" {
+		body, err = services.RenderPackReportPDF(report)
+		c.Set(fiber.HeaderContentType, "application/pdf")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="kifu-pack-%s.pdf"`, report.PackID))
+	} else {
+		body, err = services.RenderPackReportHTML(report)
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set("X-Content-Hash", report.ContentHash)
+	return c.Status(200).Send(body)
+}
+
+func publicShareError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrPackShareNotFound):
+		return c.Status(404).JSON(fiber.Map{"code": "SHARE_NOT_FOUND", "message": "share link is invalid"})
+	case errors.Is(err, services.ErrPackShareExpired):
+		return c.Status(410).JSON(fiber.Map{"code": "SHARE_EXPIRED", "message": "share link has expired"})
+	case errors.Is(err, services.ErrPackShareRevoked):
+		return c.Status(410).JSON(fiber.Map{"code": "SHARE_REVOKED", "message": "share link was revoked"})
+	}
+	return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": "failed to open share link"})
+}
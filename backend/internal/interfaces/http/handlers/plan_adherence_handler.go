@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/services"
+)
+
+type PlanAdherenceHandler struct {
+	svc *services.PlanAdherenceService
+}
+
+func NewPlanAdherenceHandler(svc *services.PlanAdherenceService) *PlanAdherenceHandler {
+	return &PlanAdherenceHandler{svc: svc}
+}
+
+// Report summarizes plan adherence for plans whose first fill falls between
+// from and to (default: the last 30 days). Plans with no fills yet are listed
+// as pending.
+func (h *PlanAdherenceHandler) Report(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	from, err := parseTimeQuery(c.Query("from"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "from is invalid"})
+	}
+	to, err := parseTimeQuery(c.Query("to"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "to is invalid"})
+	}
+	now := time.Now().UTC()
+	if to == nil {
+		to = &now
+	}
+	if from == nil {
+		since := to.AddDate(0, 0, -30)
+		from = &since
+	}
+	if from.After(*to) {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "from must be before to"})
+	}
+
+	report, err := h.svc.Report(c.Context(), userID, *from, *to)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	return c.Status(200).JSON(report)
+}
+
+// Get scores one plan against its fills right away.
+func (h *PlanAdherenceHandler) Get(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid id"})
+	}
+
+	execution, err := h.svc.Get(c.Context(), userID, id)
+	if err != nil {
+		if errors.Is(err, services.ErrPlanNotFound) {
+			return c.Status(404).JSON(fiber.Map{"code": "NOT_FOUND", "message": "position not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	return c.Status(200).JSON(execution)
+}
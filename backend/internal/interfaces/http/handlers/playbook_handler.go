@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/services"
+)
+
+type PlaybookHandler struct {
+	svc *services.PlaybookService
+}
+
+func NewPlaybookHandler(svc *services.PlaybookService) *PlaybookHandler {
+	return &PlaybookHandler{svc: svc}
+}
+
+type PlaybookSetupRequest struct {
+	Name          string   `json:"name"`
+	Description   *string  `json:"description,omitempty"`
+	Direction     string   `json:"direction"`
+	EntryCriteria *string  `json:"entry_criteria,omitempty"`
+	Invalidation  *string  `json:"invalidation,omitempty"`
+	TargetRR      *string  `json:"target_rr,omitempty"`
+	RiskPercent   *string  `json:"risk_percent,omitempty"`
+	Checklist     []string `json:"checklist"`
+	Archived      *bool    `json:"archived,omitempty"`
+}
+
+type PlaybookLinkRequest struct {
+	SubjectType string   `json:"subject_type"`
+	SubjectID   string   `json:"subject_id"`
+	SetupID     *string  `json:"setup_id"`
+	Checked     []string `json:"checked"`
+}
+
+func (r PlaybookSetupRequest) input() services.SetupInput {
+	return services.SetupInput{
+		Name:          r.Name,
+		Description:   r.Description,
+		Direction:     r.Direction,
+		EntryCriteria: r.EntryCriteria,
+		Invalidation:  r.Invalidation,
+		TargetRR:      r.TargetRR,
+		RiskPercent:   r.RiskPercent,
+		Checklist:     r.Checklist,
+		Archived:      r.Archived,
+	}
+}
+
+func (h *PlaybookHandler) List(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	setups, err := h.svc.List(c.Context(), userID, c.QueryBool("include_archived", false))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	return c.Status(200).JSON(fiber.Map{"setups": setups})
+}
+
+func (h *PlaybookHandler) Create(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	var req PlaybookSetupRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+	}
+
+	setup, err := h.svc.Create(c.Context(), userID, req.input())
+	if err != nil {
+		return playbookError(c, err)
+	}
+	return c.Status(201).JSON(setup)
+}
+
+func (h *PlaybookHandler) Get(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid id"})
+	}
+
+	setup, err := h.svc.Get(c.Context(), userID, id)
+	if err != nil {
+		return playbookError(c, err)
+	}
+	return c.Status(200).JSON(setup)
+}
+
+func (h *PlaybookHandler) Update(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid id"})
+	}
+
+	var req PlaybookSetupRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+	}
+
+	setup, err := h.svc.Update(c.Context(), userID, id, req.input())
+	if err != nil {
+		return playbookError(c, err)
+	}
+	return c.Status(200).JSON(setup)
+}
+
+func (h *PlaybookHandler) Delete(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid id"})
+	}
+
+	if err := h.svc.Delete(c.Context(), userID, id); err != nil {
+		return playbookError(c, err)
+	}
+	return c.SendStatus(204)
+}
+
+// Link attaches a bubble, trade or manual position to a setup. Sending a null
+// setup_id removes the link.
+func (h *PlaybookHandler) Link(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	var req PlaybookLinkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+	}
+	subjectID, err := uuid.Parse(strings.TrimSpace(req.SubjectID))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "subject_id is invalid"})
+	}
+	var setupID *uuid.UUID
+	if req.SetupID != nil && strings.TrimSpace(*req.SetupID) != "" {
+		parsed, err := uuid.Parse(strings.TrimSpace(*req.SetupID))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "setup_id is invalid"})
+		}
+		setupID = &parsed
+	}
+
+	subject := entities.PlaybookSubject(strings.ToLower(strings.TrimSpace(req.SubjectType)))
+	if err := h.svc.Link(c.Context(), userID, subject, subjectID, setupID, req.Checked); err != nil {
+		return playbookError(c, err)
+	}
+	return c.Status(200).JSON(fiber.Map{"subject_type": subject, "subject_id": subjectID, "setup_id": setupID})
+}
+
+// Stats scores setups with the same filters as /review/stats, plus
+// outcome_period for the bubble outcomes that win rate and R come from.
+func (h *PlaybookHandler) Stats(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	filter := services.PlaybookStatsFilter{
+		OutcomePeriod: normalizePeriod(c.Query("outcome_period")),
+		Symbol:        strings.ToUpper(strings.TrimSpace(c.Query("symbol"))),
+		Tag:           strings.TrimSpace(c.Query("tag")),
+		AssetClass:    strings.ToLower(strings.TrimSpace(c.Query("asset_class"))),
+		Venue:         strings.ToLower(strings.TrimSpace(c.Query("venue"))),
+	}
+	if filter.OutcomePeriod == "" {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "outcome_period is invalid"})
+	}
+
+	period := c.Query("period", "30d")
+	switch period {
+	case "7d":
+		since := time.Now().AddDate(0, 0, -7)
+		filter.Since = &since
+	case "30d":
+		since := time.Now().AddDate(0, 0, -30)
+		filter.Since = &since
+	}
+
+	if raw := c.Params("id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "invalid id"})
+		}
+		filter.SetupID = &id
+	}
+
+	stats, err := h.svc.Stats(c.Context(), userID, filter)
+	if err != nil {
+		return playbookError(c, err)
+	}
+	return c.Status(200).JSON(fiber.Map{
+		"period":         period,
+		"outcome_period": filter.OutcomePeriod,
+		"setups":         stats,
+	})
+}
+
+func playbookError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrSetupNotFound):
+		return c.Status(404).JSON(fiber.Map{"code": "SETUP_NOT_FOUND", "message": err.Error()})
+	case errors.Is(err, services.ErrPlaybookLinkNotFound):
+		return c.Status(404).JSON(fiber.Map{"code": "NOT_FOUND", "message": err.Error()})
+	case errors.Is(err, services.ErrSetupNameRequired),
+		errors.Is(err, services.ErrInvalidSetupDirection),
+		errors.Is(err, services.ErrInvalidSetupDecimal),
+		errors.Is(err, services.ErrInvalidSetupChecklist),
+		errors.Is(err, services.ErrUnknownChecklistItem),
+		errors.Is(err, services.ErrInvalidPlaybookSubject),
+		errors.Is(err, services.ErrInvalidOutcomePeriod):
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+	case isUniqueViolation(err):
+		return c.Status(409).JSON(fiber.Map{"code": "SETUP_NAME_TAKEN", "message": "a setup with this name already exists"})
+	}
+	return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+}
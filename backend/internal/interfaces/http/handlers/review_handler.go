@@ -16,17 +16,20 @@ type ReviewHandler struct {
 	bubbleRepo   repositories.BubbleRepository
 	outcomeRepo  repositories.OutcomeRepository
 	accuracyRepo repositories.AIOpinionAccuracyRepository
+	userRepo     repositories.UserRepository
 }
 
 func NewReviewHandler(
 	bubbleRepo repositories.BubbleRepository,
 	outcomeRepo repositories.OutcomeRepository,
 	accuracyRepo repositories.AIOpinionAccuracyRepository,
+	userRepo repositories.UserRepository,
 ) *ReviewHandler {
 	return &ReviewHandler{
 		bubbleRepo:   bubbleRepo,
 		outcomeRepo:  outcomeRepo,
 		accuracyRepo: accuracyRepo,
+		userRepo:     userRepo,
 	}
 }
 
@@ -78,8 +81,14 @@ func (h *ReviewHandler) GetStats(c *fiber.Ctx) error {
 	tag := c.Query("tag", "")
 	assetClass := strings.ToLower(strings.TrimSpace(c.Query("asset_class", "")))
 	venueName := strings.ToLower(strings.TrimSpace(c.Query("venue", "")))
+	setupID := strings.TrimSpace(c.Query("setup_id", ""))
+	if setupID != "" {
+		if _, err := uuid.Parse(setupID); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid setup_id"})
+		}
+	}
 
-	stats, err := h.bubbleRepo.GetReviewStats(c.Context(), userID, period, symbol, tag, assetClass, venueName)
+	stats, err := h.bubbleRepo.GetReviewStats(c.Context(), userID, period, symbol, tag, assetClass, venueName, setupID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -176,8 +185,15 @@ func (h *ReviewHandler) GetCalendar(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "unauthorized"})
 	}
 
-	fromStr := c.Query("from", time.Now().AddDate(0, -1, 0).Format("2006-01-02"))
-	toStr := c.Query("to", time.Now().Format("2006-01-02"))
+	// Days follow the timezone and cutoff hour stored on the profile.
+	tradingDay, err := userTradingDay(c.Context(), h.userRepo, userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+	today := tradingDay.Start(time.Now())
+
+	fromStr := c.Query("from", today.AddDate(0, -1, 0).Format("2006-01-02"))
+	toStr := c.Query("to", today.Format("2006-01-02"))
 	assetClass := strings.ToLower(strings.TrimSpace(c.Query("asset_class", "")))
 	venueName := strings.ToLower(strings.TrimSpace(c.Query("venue", "")))
 
@@ -190,7 +206,7 @@ func (h *ReviewHandler) GetCalendar(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid to date"})
 	}
 
-	calendarData, err := h.bubbleRepo.GetCalendarData(c.Context(), userID, from, to, assetClass, venueName)
+	calendarData, err := h.bubbleRepo.GetCalendarData(c.Context(), userID, from, to, tradingDay, assetClass, venueName)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
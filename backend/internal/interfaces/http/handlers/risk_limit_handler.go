@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/services"
+)
+
+type RiskLimitHandler struct {
+	svc      *services.RiskLimitService
+	userRepo repositories.UserRepository
+}
+
+func NewRiskLimitHandler(svc *services.RiskLimitService, userRepo repositories.UserRepository) *RiskLimitHandler {
+	return &RiskLimitHandler{svc: svc, userRepo: userRepo}
+}
+
+type RiskLimitsResponse struct {
+	entities.RiskLimitConfig
+	Enabled   bool       `json:"enabled"`
+	RuleID    *string    `json:"rule_id,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+type UpdateRiskLimitsRequest struct {
+	entities.RiskLimitConfig
+	Enabled *bool `json:"enabled"`
+}
+
+func riskLimitsResponse(rule *entities.AlertRule) (RiskLimitsResponse, error) {
+	if rule == nil {
+		return RiskLimitsResponse{}, nil
+	}
+	var cfg entities.RiskLimitConfig
+	if err := json.Unmarshal(rule.Config, &cfg); err != nil {
+		return RiskLimitsResponse{}, err
+	}
+	id := rule.ID.String()
+	return RiskLimitsResponse{RiskLimitConfig: cfg, Enabled: rule.Enabled, RuleID: &id, UpdatedAt: &rule.UpdatedAt}, nil
+}
+
+// Get returns the user's risk limits; limits that are not set are omitted.
+func (h *RiskLimitHandler) Get(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	rule, err := h.svc.GetLimits(c.Context(), userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	resp, err := riskLimitsResponse(rule)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	return c.Status(200).JSON(resp)
+}
+
+// Update replaces the user's risk limits. Omitted limits are cleared;
+// enabled defaults to true.
+func (h *RiskLimitHandler) Update(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	var req UpdateRiskLimitsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+	}
+	if err := services.ValidateRiskLimitConfig(req.RiskLimitConfig); err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	rule, err := h.svc.SaveLimits(c.Context(), userID, req.RiskLimitConfig, enabled)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	resp, err := riskLimitsResponse(rule)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	return c.Status(200).JSON(resp)
+}
+
+// Discipline lists daily discipline scores and the breaches behind them
+// between from and to (YYYY-MM-DD trading days, default: the last 30).
+func (h *RiskLimitHandler) Discipline(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	tradingDay, err := userTradingDay(c.Context(), h.userRepo, userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+
+	to := strings.TrimSpace(c.Query("to"))
+	if to == "" {
+		to = tradingDay.Date(time.Now())
+	}
+	toDay, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "to must be YYYY-MM-DD"})
+	}
+	from := strings.TrimSpace(c.Query("from"))
+	if from == "" {
+		from = toDay.AddDate(0, 0, -29).Format("2006-01-02")
+	}
+	fromDay, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "from must be YYYY-MM-DD"})
+	}
+	if fromDay.After(toDay) {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "from must be before to"})
+	}
+
+	scores, err := h.svc.ListDisciplineScores(c.Context(), userID, from, to)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	breaches, err := h.svc.ListBreaches(c.Context(), userID, from, to)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	if scores == nil {
+		scores = []*entities.DisciplineScore{}
+	}
+	if breaches == nil {
+		breaches = []*entities.RiskLimitBreach{}
+	}
+	return c.Status(200).JSON(fiber.Map{
+		"from":     from,
+		"to":       to,
+		"scores":   scores,
+		"breaches": breaches,
+	})
+}
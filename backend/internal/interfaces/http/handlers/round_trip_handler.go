@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+// RoundTripHandler lists synced round trips with their excursions.
+type RoundTripHandler struct {
+	excursionRepo repositories.PositionExcursionRepository
+}
+
+func NewRoundTripHandler(excursionRepo repositories.PositionExcursionRepository) *RoundTripHandler {
+	return &RoundTripHandler{excursionRepo: excursionRepo}
+}
+
+// List returns round trips closed in [from, to], the last 90 days by default.
+func (h *RoundTripHandler) List(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	limit := 50
+	if limitStr := strings.TrimSpace(c.Query("limit")); limitStr != "" {
+		parsed, err := parsePositiveInt(limitStr)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "limit is invalid"})
+		}
+		if parsed > 200 {
+			parsed = 200
+		}
+		limit = parsed
+	}
+
+	to := time.Now().UTC()
+	if toStr := strings.TrimSpace(c.Query("to")); toStr != "" {
+		parsed, ok := parseTime(toStr)
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "to is invalid"})
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -90)
+	if fromStr := strings.TrimSpace(c.Query("from")); fromStr != "" {
+		parsed, ok := parseTime(fromStr)
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "from is invalid"})
+		}
+		from = parsed
+	}
+
+	trips, err := h.excursionRepo.ListRoundTrips(c.Context(), userID, from, to, limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+	if trips == nil {
+		trips = []*entities.TradeRoundTrip{}
+	}
+	return c.Status(200).JSON(fiber.Map{
+		"round_trips": trips,
+		"count":       len(trips),
+	})
+}
@@ -13,34 +13,40 @@ import (
 	"github.com/google/uuid"
 	"github.com/moneyvessel/kifu/internal/domain/entities"
 	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/services"
 )
 
 type SafetyHandler struct {
 	reviewRepo repositories.TradeSafetyReviewRepository
+	userRepo   repositories.UserRepository
+	scoring    *services.TradeSafetyScoringService
 }
 
-func NewSafetyHandler(reviewRepo repositories.TradeSafetyReviewRepository) *SafetyHandler {
-	return &SafetyHandler{reviewRepo: reviewRepo}
+func NewSafetyHandler(reviewRepo repositories.TradeSafetyReviewRepository, userRepo repositories.UserRepository, scoring *services.TradeSafetyScoringService) *SafetyHandler {
+	return &SafetyHandler{reviewRepo: reviewRepo, userRepo: userRepo, scoring: scoring}
 }
 
 type SafetyTodayItem struct {
-	TargetType string  `json:"target_type"`
-	TargetID   string  `json:"target_id"`
-	ExecutedAt string  `json:"executed_at"`
-	AssetClass string  `json:"asset_class"`
-	Venue      string  `json:"venue"`
-	VenueName  string  `json:"venue_name"`
-	Symbol     string  `json:"symbol"`
-	Side       *string `json:"side,omitempty"`
-	Qty        *string `json:"qty,omitempty"`
-	Price      *string `json:"price,omitempty"`
-	Source     string  `json:"source"`
-	Reviewed   bool    `json:"reviewed"`
-	Verdict    *string `json:"verdict,omitempty"`
-	Note       *string `json:"note,omitempty"`
-	ReviewedAt *string `json:"reviewed_at,omitempty"`
-	GroupSize  int     `json:"group_size,omitempty"`
-	Members    []SafetyTargetMember `json:"member_targets,omitempty"`
+	TargetType string                     `json:"target_type"`
+	TargetID   string                     `json:"target_id"`
+	ExecutedAt string                     `json:"executed_at"`
+	AssetClass string                     `json:"asset_class"`
+	Venue      string                     `json:"venue"`
+	VenueName  string                     `json:"venue_name"`
+	Symbol     string                     `json:"symbol"`
+	Side       *string                    `json:"side,omitempty"`
+	Qty        *string                    `json:"qty,omitempty"`
+	Price      *string                    `json:"price,omitempty"`
+	Source     string                     `json:"source"`
+	Reviewed   bool                       `json:"reviewed"`
+	Verdict    *string                    `json:"verdict,omitempty"`
+	Note       *string                    `json:"note,omitempty"`
+	ReviewedAt *string                    `json:"reviewed_at,omitempty"`
+	GroupSize  int                        `json:"group_size,omitempty"`
+	Members    []SafetyTargetMember       `json:"member_targets,omitempty"`
+	Flags      []entities.TradeSafetyFlag `json:"flags"`
+	RiskScore  float64                    `json:"risk_score"`
+	Risk       string                     `json:"risk"`
 }
 
 type SafetyTargetMember struct {
@@ -51,12 +57,13 @@ type SafetyTargetMember struct {
 }
 
 type SafetyTodayResponse struct {
-	Date     string            `json:"date"`
-	Timezone string            `json:"timezone"`
-	Total    int               `json:"total"`
-	Reviewed int               `json:"reviewed"`
-	Pending  int               `json:"pending"`
-	Items    []SafetyTodayItem `json:"items"`
+	Date       string            `json:"date"`
+	Timezone   string            `json:"timezone"`
+	CutoffHour int               `json:"day_cutoff_hour"`
+	Total      int               `json:"total"`
+	Reviewed   int               `json:"reviewed"`
+	Pending    int               `json:"pending"`
+	Items      []SafetyTodayItem `json:"items"`
 }
 
 type UpsertSafetyReviewRequest struct {
@@ -82,28 +89,21 @@ func (h *SafetyHandler) ListDaily(c *fiber.Ctx) error {
 		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
 	}
 
-	timezone := strings.TrimSpace(c.Query("timezone"))
-	if timezone == "" {
-		timezone = "UTC"
-	}
-	location, err := time.LoadLocation(timezone)
+	// The day runs from the profile's cutoff hour, in the client's ?timezone=
+	// until the user saves a timezone on the profile.
+	tradingDay, err := requestTradingDay(c, h.userRepo, userID)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "timezone is invalid"})
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
 	}
 
-	var dayStart time.Time
-	dateRaw := strings.TrimSpace(c.Query("date"))
-	if dateRaw == "" {
-		now := time.Now().In(location)
-		dayStart = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
-	} else {
-		parsed, parseErr := time.ParseInLocation("2006-01-02", dateRaw, location)
-		if parseErr != nil {
-			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "date must be YYYY-MM-DD"})
-		}
-		dayStart = parsed
+	date := strings.TrimSpace(c.Query("date"))
+	if date == "" {
+		date = tradingDay.Date(time.Now())
+	}
+	dayStart, dayEnd, err := tradingDay.Bounds(date)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "date must be YYYY-MM-DD"})
 	}
-	dayEnd := dayStart.Add(24 * time.Hour)
 
 	assetClass := strings.ToLower(strings.TrimSpace(c.Query("asset_class")))
 	if assetClass != "" && assetClass != "crypto" && assetClass != "stock" {
@@ -123,8 +123,8 @@ func (h *SafetyHandler) ListDaily(c *fiber.Ctx) error {
 	}
 
 	filter := repositories.DailySafetyFilter{
-		From:        dayStart.UTC(),
-		To:          dayEnd.UTC(),
+		From:        dayStart,
+		To:          dayEnd,
 		AssetClass:  assetClass,
 		Venue:       strings.ToLower(strings.TrimSpace(c.Query("venue"))),
 		OnlyPending: parseBoolQuery(c.Query("only_pending")),
@@ -136,6 +136,18 @@ func (h *SafetyHandler) ListDaily(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
 	}
 
+	var signals map[uuid.UUID]*entities.TradeSafetySignal
+	if h.scoring != nil {
+		targetIDs := make([]uuid.UUID, 0, len(items))
+		for _, item := range items {
+			targetIDs = append(targetIDs, item.TargetID)
+		}
+		signals, err = h.scoring.Signals(c.Context(), userID, targetIDs)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+		}
+	}
+
 	responseItems := make([]SafetyTodayItem, 0, len(items))
 	for _, item := range items {
 		responseItem := SafetyTodayItem{
@@ -167,6 +179,12 @@ func (h *SafetyHandler) ListDaily(c *fiber.Ctx) error {
 			},
 		}
 		responseItem.GroupSize = 1
+		responseItem.Flags = []entities.TradeSafetyFlag{}
+		if signal, ok := signals[item.TargetID]; ok {
+			responseItem.Flags = signal.Flags
+			responseItem.RiskScore = signal.Score
+		}
+		responseItem.Risk = services.TradeSafetyRisk(responseItem.RiskScore)
 		responseItems = append(responseItems, responseItem)
 	}
 
@@ -184,12 +202,13 @@ func (h *SafetyHandler) ListDaily(c *fiber.Ctx) error {
 	}
 
 	return c.Status(200).JSON(SafetyTodayResponse{
-		Date:     dayStart.Format("2006-01-02"),
-		Timezone: timezone,
-		Total:    groupedTotal,
-		Reviewed: groupedReviewed,
-		Pending:  groupedPending,
-		Items:    groupedItems,
+		Date:       date,
+		Timezone:   tradingDay.Timezone(),
+		CutoffHour: tradingDay.CutoffHour,
+		Total:      groupedTotal,
+		Reviewed:   groupedReviewed,
+		Pending:    groupedPending,
+		Items:      groupedItems,
 	})
 }
 
@@ -235,6 +254,11 @@ func groupSafetyItems(items []SafetyTodayItem) []SafetyTodayItem {
 		mergedQty, mergedPrice := mergeQtyPrice(last.Qty, last.Price, item.Qty, item.Price)
 		last.Qty = mergedQty
 		last.Price = mergedPrice
+		last.Flags = mergeSafetyFlags(last.Flags, item.Flags)
+		if item.RiskScore > last.RiskScore {
+			last.RiskScore = item.RiskScore
+			last.Risk = item.Risk
+		}
 	}
 
 	for i := range groups {
@@ -407,3 +431,24 @@ func normalizeCasePtr(value *string, upper bool) *string {
 	normalized := strings.ToLower(trimmed)
 	return &normalized
 }
+
+// mergeSafetyFlags keeps one flag per code, preferring the heavier one.
+func mergeSafetyFlags(a, b []entities.TradeSafetyFlag) []entities.TradeSafetyFlag {
+	merged := append([]entities.TradeSafetyFlag{}, a...)
+	for _, flag := range b {
+		found := false
+		for i := range merged {
+			if merged[i].Code == flag.Code {
+				found = true
+				if flag.Weight > merged[i].Weight {
+					merged[i] = flag
+				}
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, flag)
+		}
+	}
+	return merged
+}
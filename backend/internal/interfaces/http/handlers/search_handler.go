@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/moneyvessel/kifu/internal/services"
+)
+
+type SearchHandler struct {
+	searchSvc *services.SearchService
+}
+
+func NewSearchHandler(searchSvc *services.SearchService) *SearchHandler {
+	return &SearchHandler{searchSvc: searchSvc}
+}
+
+// Search runs a full-text query over the user's journal: bubble memos, review
+// notes, guided review memos and AI opinions. Snippets are HTML with matches
+// wrapped in <mark>.
+func (h *SearchHandler) Search(c *fiber.Ctx) error {
+	userID, err := ExtractUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or missing JWT"})
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "q is required"})
+	}
+
+	page, limit, err := parsePageLimit(c.Query("page"), c.Query("limit"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+	}
+	if c.Query("limit") == "" {
+		limit = 20
+	}
+
+	tags, err := normalizeTags(splitTags(c.Query("tags")))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_TAGS", "message": err.Error()})
+	}
+
+	from, err := parseTimeQuery(c.Query("from"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "from is invalid"})
+	}
+	to, err := parseTimeQuery(c.Query("to"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "to is invalid"})
+	}
+
+	var sources []string
+	if raw := strings.TrimSpace(c.Query("sources")); raw != "" {
+		sources = strings.Split(raw, ",")
+	}
+
+	result, err := h.searchSvc.Search(c.Context(), userID, services.SearchInput{
+		Query:   query,
+		Sources: sources,
+		Symbol:  c.Query("symbol"),
+		Tags:    tags,
+		From:    from,
+		To:      to,
+		Limit:   limit,
+		Offset:  (page - 1) * limit,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrEmptySearchQuery) || errors.Is(err, services.ErrInvalidSearchSource) {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"query": result.Query,
+		"terms": result.Terms,
+		"total": result.Total,
+		"page":  page,
+		"limit": limit,
+		"hits":  result.Hits,
+	})
+}
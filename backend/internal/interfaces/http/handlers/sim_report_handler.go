@@ -491,7 +491,7 @@ func (h *SimReportHandler) Run(c *fiber.Ctx) error {
 	}
 
 	if finalStreak.Current == 0 && finalStreak.Longest == 0 {
-		streak, streakErr := h.guidedReviewRepo.GetStreak(c.Context(), executionUserID)
+		streak, streakErr := h.guidedReviewRepo.GetStreak(c.Context(), executionUserID, entities.ReviewCadenceDaily)
 		if streakErr == nil && streak != nil {
 			finalStreak = SimReportStreak{
 				Current:       streak.CurrentStreak,
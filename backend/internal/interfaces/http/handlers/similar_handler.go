@@ -1,20 +1,30 @@
 package handlers
 
 import (
+	"errors"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
 	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/services"
+)
+
+const (
+	defaultMarketSimilarK = 10
+	maxMarketSimilarK     = 50
 )
 
 type SimilarHandler struct {
 	bubbleRepo repositories.BubbleRepository
+	marketSvc  *services.MarketContextService
 }
 
-func NewSimilarHandler(bubbleRepo repositories.BubbleRepository) *SimilarHandler {
-	return &SimilarHandler{bubbleRepo: bubbleRepo}
+func NewSimilarHandler(bubbleRepo repositories.BubbleRepository, marketSvc *services.MarketContextService) *SimilarHandler {
+	return &SimilarHandler{bubbleRepo: bubbleRepo, marketSvc: marketSvc}
 }
 
 type SimilarBubbleItem struct {
@@ -29,6 +39,12 @@ type SimilarBubbleItem struct {
 	Outcome    *OutcomeItem `json:"outcome,omitempty"`
 }
 
+type MarketSimilarBubbleItem struct {
+	SimilarBubbleItem
+	Distance   float64 `json:"distance"`
+	Similarity float64 `json:"similarity"`
+}
+
 type OutcomeItem struct {
 	Period     string  `json:"period"`
 	PnLPercent *string `json:"pnl_percent"`
@@ -68,6 +84,14 @@ func (h *SimilarHandler) SimilarByBubble(c *fiber.Ctx) error {
 		return c.Status(403).JSON(fiber.Map{"code": "FORBIDDEN", "message": "access denied"})
 	}
 
+	switch strings.ToLower(strings.TrimSpace(c.Query("mode"))) {
+	case "", "tags":
+	case "market":
+		return h.similarByMarket(c, bubble, period)
+	default:
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "mode must be tags or market"})
+	}
+
 	if len(bubble.Tags) == 0 {
 		return c.Status(200).JSON(fiber.Map{
 			"similar_count": 0,
@@ -98,6 +122,55 @@ func (h *SimilarHandler) SimilarByBubble(c *fiber.Ctx) error {
 	})
 }
 
+// similarByMarket ranks the user's earlier bubbles, across symbols, by how
+// close the market before them looked to the market before this one.
+func (h *SimilarHandler) similarByMarket(c *fiber.Ctx, bubble *entities.Bubble, period string) error {
+	k := defaultMarketSimilarK
+	if raw := strings.TrimSpace(c.Query("k")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxMarketSimilarK {
+			return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "k must be between 1 and 50"})
+		}
+		k = parsed
+	}
+
+	result, err := h.marketSvc.Similar(c.Context(), bubble, period, k)
+	if err != nil {
+		if errors.Is(err, services.ErrMarketContextUnavailable) {
+			return c.Status(422).JSON(fiber.Map{"code": "MARKET_CONTEXT_UNAVAILABLE", "message": err.Error()})
+		}
+		if errors.Is(err, services.ErrMarketContextPending) {
+			return c.Status(503).JSON(fiber.Map{"code": "MARKET_CONTEXT_PENDING", "message": err.Error()})
+		}
+		var rateErr *services.CandleRateLimitError
+		if errors.As(err, &rateErr) {
+			return c.Status(503).JSON(fiber.Map{"code": "MARKET_DATA_RATE_LIMITED", "message": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
+	}
+
+	withOutcomes := make([]*repositories.BubbleWithOutcome, 0, len(result.Items))
+	for _, item := range result.Items {
+		withOutcomes = append(withOutcomes, &repositories.BubbleWithOutcome{Bubble: item.Bubble, Outcome: item.Outcome})
+	}
+	mapped := mapSimilarItems(withOutcomes)
+	bubbles := make([]MarketSimilarBubbleItem, 0, len(mapped))
+	for i, item := range mapped {
+		bubbles = append(bubbles, MarketSimilarBubbleItem{
+			SimilarBubbleItem: item,
+			Distance:          result.Items[i].Distance,
+			Similarity:        result.Items[i].Similarity,
+		})
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"mode":          "market",
+		"similar_count": len(bubbles),
+		"summary":       SimilarSummaryResponse{Period: period, Wins: result.Wins, Losses: result.Losses, AvgPnL: result.AvgPnL},
+		"bubbles":       bubbles,
+	})
+}
+
 func (h *SimilarHandler) Search(c *fiber.Ctx) error {
 	userID, err := ExtractUserID(c)
 	if err != nil {
@@ -172,13 +245,16 @@ func mapSimilarItems(items []*repositories.BubbleWithOutcome) []SimilarBubbleIte
 	return response
 }
 
+// normalizePeriod defaults to 1h and returns any other outcome horizon in
+// canonical form, or "" when it isn't one.
 func normalizePeriod(value string) string {
 	value = strings.ToLower(strings.TrimSpace(value))
 	if value == "" {
 		return "1h"
 	}
-	if value == "1h" || value == "4h" || value == "1d" {
-		return value
+	horizon, err := services.ParseOutcomeHorizon(value)
+	if err != nil {
+		return ""
 	}
-	return ""
+	return horizon.Period
 }
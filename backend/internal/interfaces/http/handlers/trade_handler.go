@@ -18,6 +18,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/moneyvessel/kifu/internal/domain/entities"
 	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/services"
 )
 
 const defaultExchange = "binance_futures"
@@ -33,6 +34,7 @@ type TradeHandler struct {
 	bubbleRepo     repositories.BubbleRepository
 	userSymbolRepo repositories.UserSymbolRepository
 	portfolioRepo  repositories.PortfolioRepository
+	riskLimits     *services.RiskLimitService
 }
 
 func NewTradeHandler(
@@ -40,12 +42,14 @@ func NewTradeHandler(
 	bubbleRepo repositories.BubbleRepository,
 	userSymbolRepo repositories.UserSymbolRepository,
 	portfolioRepo repositories.PortfolioRepository,
+	riskLimits *services.RiskLimitService,
 ) *TradeHandler {
 	return &TradeHandler{
 		tradeRepo:      tradeRepo,
 		bubbleRepo:     bubbleRepo,
 		userSymbolRepo: userSymbolRepo,
 		portfolioRepo:  portfolioRepo,
+		riskLimits:     riskLimits,
 	}
 }
 
@@ -264,6 +268,7 @@ func (h *TradeHandler) Import(c *fiber.Ctx) error {
 
 	imported := 0
 	skipped := 0
+	var earliestImported time.Time
 	for {
 		row, err := reader.Read()
 		if errors.Is(err, io.EOF) {
@@ -330,6 +335,15 @@ func (h *TradeHandler) Import(c *fiber.Ctx) error {
 		}
 
 		imported += 1
+		if earliestImported.IsZero() || payload.TradeTime.Before(earliestImported) {
+			earliestImported = payload.TradeTime
+		}
+	}
+
+	if h.riskLimits != nil && !earliestImported.IsZero() {
+		if err := h.riskLimits.QueueEvaluation(c.Context(), userID, earliestImported); err != nil {
+			fmt.Printf("trade import: queue risk limit check failed user=%s err=%v\n", userID.String(), err)
+		}
 	}
 
 	return c.Status(200).JSON(TradeImportResponse{Imported: imported, Skipped: skipped})
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"context"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
 	"github.com/moneyvessel/kifu/internal/domain/repositories"
 )
 
@@ -37,12 +40,16 @@ type UserProfileResponse struct {
 	Name          string            `json:"name"`
 	AIAllowlisted bool              `json:"ai_allowlisted"`
 	IsAdmin       bool              `json:"is_admin"`
+	Timezone      string            `json:"timezone"`
+	DayCutoffHour int               `json:"day_cutoff_hour"`
 	CreatedAt     time.Time         `json:"created_at"`
 	Subscription  *SubscriptionInfo `json:"subscription,omitempty"`
 }
 
 type UpdateProfileRequest struct {
-	Name string `json:"name"`
+	Name          string  `json:"name"`
+	Timezone      *string `json:"timezone"`
+	DayCutoffHour *int    `json:"day_cutoff_hour"`
 }
 
 type SubscriptionResponse struct {
@@ -90,6 +97,8 @@ func (h *UserHandler) GetProfile(c *fiber.Ctx) error {
 		Name:          user.Name,
 		AIAllowlisted: user.AIAllowlisted,
 		IsAdmin:       user.IsAdmin,
+		Timezone:      user.TradingDay().Timezone(),
+		DayCutoffHour: user.DayCutoffHour,
 		CreatedAt:     user.CreatedAt,
 		Subscription:  subInfo,
 	}
@@ -97,7 +106,8 @@ func (h *UserHandler) GetProfile(c *fiber.Ctx) error {
 	return c.Status(200).JSON(response)
 }
 
-// UpdateProfile updates the authenticated user's profile (name only)
+// UpdateProfile updates the authenticated user's name and trading-day
+// settings (timezone and day cutoff hour)
 func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 	userID, err := ExtractUserID(c)
 	if err != nil {
@@ -109,7 +119,7 @@ func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
 	}
 
-	if req.Name == "" {
+	if req.Name == "" && req.Timezone == nil && req.DayCutoffHour == nil {
 		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": "name is required"})
 	}
 
@@ -121,8 +131,26 @@ func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"code": "USER_NOT_FOUND", "message": "user not found"})
 	}
 
-	user.Name = req.Name
+	if req.Name != "" {
+		user.Name = req.Name
+	}
+	timezone, cutoff := user.TradingDay().Timezone(), user.DayCutoffHour
+	if req.Timezone != nil {
+		timezone = strings.TrimSpace(*req.Timezone)
+	}
+	if req.DayCutoffHour != nil {
+		cutoff = *req.DayCutoffHour
+	}
+	if err := entities.ValidTradingDay(timezone, cutoff); err != nil {
+		return c.Status(400).JSON(fiber.Map{"code": "INVALID_REQUEST", "message": err.Error()})
+	}
+	user.Timezone = timezone
+	user.DayCutoffHour = cutoff
 	user.UpdatedAt = time.Now()
+	if req.Timezone != nil {
+		setAt := user.UpdatedAt
+		user.TimezoneSetAt = &setAt
+	}
 
 	if err := h.userRepo.Update(c.Context(), user); err != nil {
 		return c.Status(500).JSON(fiber.Map{"code": "INTERNAL_ERROR", "message": err.Error()})
@@ -149,6 +177,8 @@ func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 		Name:          user.Name,
 		AIAllowlisted: user.AIAllowlisted,
 		IsAdmin:       user.IsAdmin,
+		Timezone:      user.TradingDay().Timezone(),
+		DayCutoffHour: user.DayCutoffHour,
 		CreatedAt:     user.CreatedAt,
 		Subscription:  subInfo,
 	}
@@ -181,3 +211,46 @@ func (h *UserHandler) GetSubscription(c *fiber.Ctx) error {
 
 	return c.Status(200).JSON(response)
 }
+
+// userTradingDay loads the trading-day settings from the user's profile.
+// Profiles default to entities.DefaultUserTimezone (Asia/Seoul) with days
+// starting at midnight, and so does a missing profile.
+func userTradingDay(ctx context.Context, userRepo repositories.UserRepository, userID uuid.UUID) (entities.TradingDay, error) {
+	user, err := loadTradingDayUser(ctx, userRepo, userID)
+	if err != nil {
+		return entities.TradingDay{}, err
+	}
+	if user == nil {
+		return entities.NewTradingDay(entities.DefaultUserTimezone, 0), nil
+	}
+	return user.TradingDay(), nil
+}
+
+// requestTradingDay is userTradingDay for endpoints whose clients send the
+// browser timezone as ?timezone=. That timezone wins until the user saves one
+// on the profile; the profile's cutoff hour applies either way.
+func requestTradingDay(c *fiber.Ctx, userRepo repositories.UserRepository, userID uuid.UUID) (entities.TradingDay, error) {
+	user, err := loadTradingDayUser(c.Context(), userRepo, userID)
+	if err != nil {
+		return entities.TradingDay{}, err
+	}
+	timezone, cutoff := entities.DefaultUserTimezone, 0
+	if user != nil {
+		timezone, cutoff = user.Timezone, user.DayCutoffHour
+	}
+	if user == nil || user.TimezoneSetAt == nil {
+		if requested := strings.TrimSpace(c.Query("timezone")); requested != "" {
+			if _, err := time.LoadLocation(requested); err == nil {
+				timezone = requested
+			}
+		}
+	}
+	return entities.NewTradingDay(timezone, cutoff), nil
+}
+
+func loadTradingDayUser(ctx context.Context, userRepo repositories.UserRepository, userID uuid.UUID) (*entities.User, error) {
+	if userRepo == nil {
+		return nil, nil
+	}
+	return userRepo.GetByID(ctx, userID)
+}
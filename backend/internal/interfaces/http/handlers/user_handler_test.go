@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+type tradingDayUserRepo struct {
+	repositories.UserRepository
+	user *entities.User
+}
+
+func (r *tradingDayUserRepo) GetByID(_ context.Context, _ uuid.UUID) (*entities.User, error) {
+	return r.user, nil
+}
+
+func TestRequestTradingDayHonorsQueryUntilProfileIsSet(t *testing.T) {
+	setAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name  string
+		user  *entities.User
+		query string
+		want  string
+	}{
+		{name: "backfilled profile", user: &entities.User{Timezone: "Asia/Seoul", DayCutoffHour: 9}, query: "?timezone=Europe/London", want: "Europe/London@9"},
+		{name: "unknown query", user: &entities.User{Timezone: "Asia/Seoul", DayCutoffHour: 9}, query: "?timezone=Mars/Olympus", want: "Asia/Seoul@9"},
+		{name: "saved profile", user: &entities.User{Timezone: "Asia/Seoul", DayCutoffHour: 9, TimezoneSetAt: &setAt}, query: "?timezone=Europe/London", want: "Asia/Seoul@9"},
+		{name: "no query", user: &entities.User{Timezone: "Asia/Tokyo"}, want: "Asia/Tokyo@0"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &tradingDayUserRepo{user: tc.user}
+			app := fiber.New()
+			app.Get("/day", func(c *fiber.Ctx) error {
+				day, err := requestTradingDay(c, repo, uuid.New())
+				if err != nil {
+					return err
+				}
+				return c.SendString(day.Timezone() + "@" + strconv.Itoa(day.CutoffHour))
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/day"+tc.query, nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tc.want {
+				t.Fatalf("expected %s, got %s", tc.want, body)
+			}
+		})
+	}
+}
@@ -1,10 +1,8 @@
 package http
 
 import (
-	"log"
 	"os"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -34,25 +32,44 @@ func RegisterRoutes(
 	outcomeRepo repositories.OutcomeRepository,
 	accuracyRepo repositories.AIOpinionAccuracyRepository,
 	noteRepo repositories.ReviewNoteRepository,
+	searchRepo repositories.SearchRepository,
 	alertRuleRepo repositories.AlertRuleRepository,
 	alertRepo repositories.AlertRepository,
 	alertBriefingRepo repositories.AlertBriefingRepository,
 	alertDecisionRepo repositories.AlertDecisionRepository,
 	alertOutcomeRepo repositories.AlertOutcomeRepository,
+	alertLifecycleSvc *services.AlertLifecycleService,
 	channelRepo repositories.NotificationChannelRepository,
 	verifyCodeRepo repositories.TelegramVerifyCodeRepository,
+	notifCodeRepo repositories.NotificationVerifyCodeRepository,
+	deliveryRepo repositories.NotificationDeliveryRepository,
+	notifPrefRepo repositories.NotificationPreferenceRepository,
+	dispatcher *notification.Dispatcher,
 	tgSender *notification.TelegramSender,
+	tgPromptRepo repositories.TelegramReplyPromptRepository,
 	tgBotUsername string,
+	tgWebhookSecret string,
 	portfolioRepo repositories.PortfolioRepository,
 	manualPositionRepo repositories.ManualPositionRepository,
 	safetyRepo repositories.TradeSafetyReviewRepository,
+	safetyScoringSvc *services.TradeSafetyScoringService,
 	guidedReviewRepo repositories.GuidedReviewRepository,
+	reviewTemplateRepo repositories.ReviewTemplateRepository,
+	playbookRepo repositories.PlaybookRepository,
+	planAdherenceSvc *services.PlanAdherenceService,
+	positionExcursionRepo repositories.PositionExcursionRepository,
+	behaviorSvc *services.BehaviorPatternService,
+	riskLimitSvc *services.RiskLimitService,
 	exchangeSyncer handlers.ExchangeSyncer,
 	encryptionKey []byte,
 	jwtSecret string,
 	runRepo repositories.RunRepository,
 	summaryPackRepo repositories.SummaryPackRepository,
 	summaryPackService *services.SummaryPackService,
+	packScheduleSvc *services.SummaryPackScheduleService,
+	packShareSvc *services.SummaryPackShareService,
+	candleStore *services.CandleStore,
+	marketContextSvc *services.MarketContextService,
 ) {
 	app.Get("/health", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{"status": "healthy"})
@@ -61,35 +78,42 @@ func RegisterRoutes(
 	authHandler := handlers.NewAuthHandler(userRepo, refreshTokenRepo, subscriptionRepo, jwtSecret)
 	userHandler := handlers.NewUserHandler(userRepo, subscriptionRepo)
 	exchangeHandler := handlers.NewExchangeHandler(exchangeRepo, tradeRepo, encryptionKey, exchangeSyncer, runRepo)
-	marketHandler := handlers.NewMarketHandler(userSymbolRepo)
+	marketHandler := handlers.NewMarketHandler(userSymbolRepo, candleStore)
 	bubbleHandler := handlers.NewBubbleHandler(bubbleRepo)
-	tradeHandler := handlers.NewTradeHandler(tradeRepo, bubbleRepo, userSymbolRepo, portfolioRepo)
-	aiHandler := handlers.NewAIHandler(bubbleRepo, aiOpinionRepo, aiProviderRepo, userAIKeyRepo, userRepo, subscriptionRepo, encryptionKey)
+	tradeHandler := handlers.NewTradeHandler(tradeRepo, bubbleRepo, userSymbolRepo, portfolioRepo, riskLimitSvc)
+	aiHandler := handlers.NewAIHandler(bubbleRepo, aiOpinionRepo, aiProviderRepo, userAIKeyRepo, userRepo, subscriptionRepo, candleStore, encryptionKey)
 	outcomeHandler := handlers.NewOutcomeHandler(bubbleRepo, outcomeRepo)
-	similarHandler := handlers.NewSimilarHandler(bubbleRepo)
-	reviewHandler := handlers.NewReviewHandler(bubbleRepo, outcomeRepo, accuracyRepo)
+	similarHandler := handlers.NewSimilarHandler(bubbleRepo, marketContextSvc)
+	playbookHandler := handlers.NewPlaybookHandler(services.NewPlaybookService(playbookRepo))
+	planAdherenceHandler := handlers.NewPlanAdherenceHandler(planAdherenceSvc)
+	behaviorHandler := handlers.NewBehaviorHandler(behaviorSvc)
+	riskLimitHandler := handlers.NewRiskLimitHandler(riskLimitSvc, userRepo)
+	reviewHandler := handlers.NewReviewHandler(bubbleRepo, outcomeRepo, accuracyRepo, userRepo)
 	noteHandler := handlers.NewNoteHandler(noteRepo)
+	searchHandler := handlers.NewSearchHandler(services.NewSearchService(searchRepo))
 	exportHandler := handlers.NewExportHandler(bubbleRepo, outcomeRepo, accuracyRepo)
-	alertRuleHandler := handlers.NewAlertRuleHandler(alertRuleRepo)
-	alertNotifHandler := handlers.NewAlertNotificationHandler(alertRepo, alertBriefingRepo, alertDecisionRepo, alertOutcomeRepo)
-	notificationHandler := handlers.NewNotificationHandler(channelRepo, verifyCodeRepo, tgSender, tgBotUsername)
+	alertRuleHandler := handlers.NewAlertRuleHandler(alertRuleRepo, services.NewAlertBacktestService(candleStore))
+	alertDecisionService := services.NewAlertDecisionService(alertRepo, alertDecisionRepo)
+	alertNotifHandler := handlers.NewAlertNotificationHandler(alertRepo, alertBriefingRepo, alertDecisionRepo, alertOutcomeRepo, alertDecisionService, services.NewDecisionQualityService(alertOutcomeRepo), alertLifecycleSvc)
+	var tgBot *services.TelegramBotService
+	if tgSender != nil {
+		tgBot = services.NewTelegramBotService(
+			channelRepo, tgPromptRepo, notifPrefRepo, alertRepo, portfolioRepo, tradeRepo, noteRepo,
+			alertDecisionService, tgSender,
+		)
+	}
+	notificationHandler := handlers.NewNotificationHandler(channelRepo, verifyCodeRepo, notifCodeRepo, deliveryRepo, notifPrefRepo, dispatcher, tgSender, tgBot, tgBotUsername, tgWebhookSecret)
 	portfolioHandler := handlers.NewPortfolioHandler(portfolioRepo, tradeRepo)
-	importHandler := handlers.NewImportHandler(portfolioRepo, runRepo)
+	importHandler := handlers.NewImportHandler(portfolioRepo, runRepo, riskLimitSvc)
 	connectionHandler := handlers.NewConnectionHandler()
-	safetyHandler := handlers.NewSafetyHandler(safetyRepo)
-	guidedReviewHandler := handlers.NewGuidedReviewHandler(guidedReviewRepo)
-	manualPositionHandler := handlers.NewManualPositionHandler(manualPositionRepo)
+	safetyHandler := handlers.NewSafetyHandler(safetyRepo, userRepo, safetyScoringSvc)
+	guidedReviewHandler := handlers.NewGuidedReviewHandler(guidedReviewRepo, userRepo, services.NewReviewInsightService(guidedReviewRepo), services.NewReviewTemplateService(reviewTemplateRepo))
+	manualPositionHandler := handlers.NewManualPositionHandler(manualPositionRepo, positionExcursionRepo)
+	roundTripHandler := handlers.NewRoundTripHandler(positionExcursionRepo)
 	packHandler := handlers.NewPackHandler(runRepo, summaryPackRepo, summaryPackService)
-	baseRPCURL := strings.TrimSpace(os.Getenv("BASE_RPC_URL"))
-	if baseRPCURL == "" {
-		log.Println("[onchain] WARNING: BASE_RPC_URL not set, falling back to public RPC")
-	} else {
-		// Log only the host portion for debugging, not the full key
-		parts := strings.SplitN(baseRPCURL, "/v2/", 2)
-		log.Printf("[onchain] BASE_RPC_URL configured: %s/v2/***", parts[0])
-	}
-	baseRPCClient := onchaininfra.NewBaseRPCClient(baseRPCURL)
-	onchainPackService := services.NewOnchainPackService(baseRPCClient)
+	packScheduleHandler := handlers.NewPackScheduleHandler(packScheduleSvc)
+	packShareHandler := handlers.NewPackShareHandler(packShareSvc)
+	onchainPackService := services.NewOnchainPackService(onchaininfra.NewProvidersFromEnv())
 	onchainHandler := handlers.NewOnchainHandler(onchainPackService)
 	simReportHandler := handlers.NewSimReportHandler(
 		pool,
@@ -141,6 +165,8 @@ func RegisterRoutes(
 	users.Get("/me/subscription", userHandler.GetSubscription)
 	users.Get("/me/symbols", marketHandler.GetUserSymbols)
 	users.Put("/me/symbols", marketHandler.UpdateUserSymbols)
+	users.Get("/me/outcome-horizons", outcomeHandler.GetHorizons)
+	users.Put("/me/outcome-horizons", outcomeHandler.UpdateHorizons)
 	users.Get("/me/ai-keys", aiHandler.GetUserAIKeys)
 	users.Put("/me/ai-keys", aiHandler.UpdateUserAIKeys)
 	users.Delete("/me/ai-keys/:provider", aiHandler.DeleteUserAIKey)
@@ -205,6 +231,9 @@ func RegisterRoutes(
 	// Notes by bubble
 	bubbles.Get("/:bubbleId/notes", noteHandler.ListNotesByBubble)
 
+	// Full-text search
+	api.Get("/search", searchHandler.Search)
+
 	// Export endpoints
 	export := api.Group("/export")
 	export.Get("/stats", exportHandler.ExportStats)
@@ -215,17 +244,23 @@ func RegisterRoutes(
 	alertRules := api.Group("/alert-rules")
 	alertRules.Post("/", alertRuleHandler.Create)
 	alertRules.Get("/", alertRuleHandler.List)
+	alertRules.Post("/backtest", alertRuleHandler.BacktestDraft)
 	alertRules.Get("/:id", alertRuleHandler.GetByID)
 	alertRules.Put("/:id", alertRuleHandler.Update)
 	alertRules.Delete("/:id", alertRuleHandler.Delete)
 	alertRules.Patch("/:id/toggle", alertRuleHandler.Toggle)
+	alertRules.Post("/:id/backtest", alertRuleHandler.Backtest)
 
 	// Alerts
 	alerts := api.Group("/alerts")
 	alerts.Get("/", alertNotifHandler.ListAlerts)
+	alerts.Get("/decision-quality", alertNotifHandler.DecisionQuality)
 	alerts.Get("/:id", alertNotifHandler.GetAlert)
 	alerts.Post("/:id/decision", alertNotifHandler.CreateDecision)
 	alerts.Patch("/:id/dismiss", alertNotifHandler.DismissAlert)
+	alerts.Post("/:id/snooze", alertNotifHandler.SnoozeAlert)
+	alerts.Post("/:id/acknowledge", alertNotifHandler.AcknowledgeAlert)
+	alerts.Get("/:id/events", alertNotifHandler.ListEvents)
 	alerts.Get("/:id/outcome", alertNotifHandler.GetOutcome)
 
 	// Notifications
@@ -233,6 +268,14 @@ func RegisterRoutes(
 	notif.Post("/telegram/connect", notificationHandler.TelegramConnect)
 	notif.Delete("/telegram", notificationHandler.TelegramDisconnect)
 	notif.Get("/channels", notificationHandler.ListChannels)
+	notif.Get("/deliveries", notificationHandler.ListDeliveries)
+	notif.Post("/deliveries/:id/retry", notificationHandler.RetryDelivery)
+	notif.Get("/preferences", notificationHandler.GetPreferences)
+	notif.Put("/preferences", notificationHandler.UpdatePreferences)
+	notif.Post("/:type/connect", notificationHandler.ConnectChannel)
+	notif.Post("/:type/verify", notificationHandler.VerifyChannel)
+	notif.Put("/:type/routing", notificationHandler.UpdateRouting)
+	notif.Delete("/:type", notificationHandler.DisconnectChannel)
 
 	// Telegram webhook (no auth)
 	app.Post("/api/v1/webhook/telegram", notificationHandler.TelegramWebhook)
@@ -241,6 +284,7 @@ func RegisterRoutes(
 	portfolio := api.Group("/portfolio")
 	portfolio.Get("/timeline", portfolioHandler.Timeline)
 	portfolio.Get("/positions", portfolioHandler.Positions)
+	portfolio.Get("/round-trips", roundTripHandler.List)
 	portfolio.Post("/backfill-bubbles", portfolioHandler.BackfillBubbles)
 	portfolio.Post("/backfill-events", portfolioHandler.BackfillEventsFromTrades)
 
@@ -249,9 +293,22 @@ func RegisterRoutes(
 	manualPositions := api.Group("/manual-positions")
 	manualPositions.Get("/", manualPositionHandler.List)
 	manualPositions.Post("/", manualPositionHandler.Create)
+	manualPositions.Get("/adherence", planAdherenceHandler.Report)
+	manualPositions.Get("/:id/adherence", planAdherenceHandler.Get)
 	manualPositions.Put("/:id", manualPositionHandler.Update)
 	manualPositions.Delete("/:id", manualPositionHandler.Delete)
 
+	// Playbook setups
+	playbook := api.Group("/playbook")
+	playbook.Get("/setups", playbookHandler.List)
+	playbook.Post("/setups", playbookHandler.Create)
+	playbook.Get("/setups/:id", playbookHandler.Get)
+	playbook.Put("/setups/:id", playbookHandler.Update)
+	playbook.Delete("/setups/:id", playbookHandler.Delete)
+	playbook.Get("/setups/:id/stats", playbookHandler.Stats)
+	playbook.Get("/stats", playbookHandler.Stats)
+	playbook.Post("/links", playbookHandler.Link)
+
 	imports := api.Group("/imports")
 	imports.Post("/trades", importHandler.ImportTrades)
 
@@ -259,9 +316,25 @@ func RegisterRoutes(
 	packs.Post("/generate", packHandler.Generate)
 	packs.Post("/generate-latest", packHandler.GenerateLatest)
 	packs.Get("/latest", packHandler.GetLatest)
+	packs.Get("/compare", packHandler.Compare)
+	packs.Get("/schedules", packScheduleHandler.List)
+	packs.Put("/schedules/:frequency", packScheduleHandler.Update)
+	packs.Delete("/schedules/:frequency", packScheduleHandler.Delete)
+	packs.Delete("/shares/:share_id", packShareHandler.Revoke)
 	packs.Get("/:pack_id", packHandler.GetByID)
+	packs.Get("/:pack_id/report", packHandler.Report)
+	packs.Get("/:pack_id/evidence", packHandler.Evidence)
+	packs.Get("/:pack_id/shares", packShareHandler.List)
+	packs.Post("/:pack_id/shares", packShareHandler.Create)
+
+	// Share links open without a login; the signed token is the credential.
+	public := api.Group("/public")
+	public.Get("/packs/signing-key", packShareHandler.PublicKey)
+	public.Get("/packs/:token", packShareHandler.PublicPack)
+	public.Get("/packs/:token/report", packShareHandler.PublicReport)
 
 	onchain := api.Group("/onchain")
+	onchain.Get("/chains", onchainHandler.Chains)
 	onchain.Post("/quick-check", onchainHandler.QuickCheck)
 
 	connections := api.Group("/connections")
@@ -277,6 +350,20 @@ func RegisterRoutes(
 	guidedReviews.Post("/items/:id/submit", guidedReviewHandler.SubmitItem)
 	guidedReviews.Post("/:id/complete", guidedReviewHandler.CompleteReview)
 	guidedReviews.Get("/streak", guidedReviewHandler.GetStreak)
+	guidedReviews.Get("/insights", guidedReviewHandler.Insights)
+	guidedReviews.Get("/templates", guidedReviewHandler.ListTemplates)
+	guidedReviews.Post("/templates", guidedReviewHandler.SaveTemplate)
+	guidedReviews.Get("/templates/:id", guidedReviewHandler.GetTemplate)
+
+	// Behavior patterns detected from trades
+	insights := api.Group("/insights")
+	insights.Get("/behavior", behaviorHandler.Report)
+
+	// Personal risk limits and daily discipline scores
+	riskLimits := api.Group("/risk-limits")
+	riskLimits.Get("/", riskLimitHandler.Get)
+	riskLimits.Put("/", riskLimitHandler.Update)
+	riskLimits.Get("/discipline", riskLimitHandler.Discipline)
 
 	// Admin sim report (dev/operator diagnostic utility)
 	admin := api.Group("/admin", middleware.RequireAdmin(userRepo))
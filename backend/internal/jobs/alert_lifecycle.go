@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// AlertLifecycleProcessor expires, re-notifies and escalates alerts that are due.
+type AlertLifecycleProcessor interface {
+	ProcessDue(ctx context.Context) error
+}
+
+type AlertLifecycleJob struct {
+	processor AlertLifecycleProcessor
+	interval  time.Duration
+}
+
+func NewAlertLifecycleJob(processor AlertLifecycleProcessor) *AlertLifecycleJob {
+	return &AlertLifecycleJob{
+		processor: processor,
+		interval:  30 * time.Second,
+	}
+}
+
+func (j *AlertLifecycleJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.runOnce(ctx)
+			}
+		}
+	}()
+}
+
+func (j *AlertLifecycleJob) runOnce(ctx context.Context) {
+	if err := j.processor.ProcessDue(ctx); err != nil {
+		log.Printf("alert lifecycle: %v", err)
+	}
+}
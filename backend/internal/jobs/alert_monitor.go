@@ -16,6 +16,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/moneyvessel/kifu/internal/domain/entities"
 	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/services"
 )
 
 type AlertMonitor struct {
@@ -104,6 +105,7 @@ func (m *AlertMonitor) runOnce(ctx context.Context) {
 				continue
 			}
 
+			now := time.Now().UTC()
 			alert := &entities.Alert{
 				ID:            uuid.New(),
 				UserID:        rule.UserID,
@@ -113,7 +115,8 @@ func (m *AlertMonitor) runOnce(ctx context.Context) {
 				TriggerReason: reason,
 				Severity:      severity,
 				Status:        entities.AlertStatusPending,
-				CreatedAt:     time.Now().UTC(),
+				CreatedAt:     now,
+				ExpiresAt:     now.Add(rule.AlertExpiry()),
 			}
 
 			if err := m.alertRepo.Create(ctx, alert); err != nil {
@@ -134,14 +137,6 @@ func (m *AlertMonitor) runOnce(ctx context.Context) {
 			}
 		}
 	}
-
-	// Expire old alerts
-	cutoff := time.Now().UTC().Add(-24 * time.Hour)
-	if expired, err := m.alertRepo.ExpireOlderThan(ctx, cutoff); err != nil {
-		log.Printf("alert monitor: expire failed: %v", err)
-	} else if expired > 0 {
-		log.Printf("alert monitor: expired %d old alerts", expired)
-	}
 }
 
 func (m *AlertMonitor) evaluate(ctx context.Context, rule *entities.AlertRule, currentPrice string, symbol string) (bool, string, entities.AlertSeverity) {
@@ -171,62 +166,7 @@ func (m *AlertMonitor) evalPriceChange(ctx context.Context, rule *entities.Alert
 		return false, "", entities.AlertSeverityNormal
 	}
 
-	cur, ok := parseDecimal(currentPrice)
-	if !ok {
-		return false, "", entities.AlertSeverityNormal
-	}
-	ref, ok := parseDecimal(refPrice)
-	if !ok {
-		return false, "", entities.AlertSeverityNormal
-	}
-
-	diff := new(big.Rat).Sub(cur, ref)
-	absDiff := new(big.Rat).Abs(diff)
-
-	threshold, ok := parseDecimal(cfg.ThresholdValue)
-	if !ok {
-		return false, "", entities.AlertSeverityNormal
-	}
-
-	if cfg.ThresholdType == "percent" {
-		// Convert threshold to absolute: ref * threshold / 100
-		pctThreshold := new(big.Rat).Mul(ref, threshold)
-		pctThreshold.Quo(pctThreshold, big.NewRat(100, 1))
-		threshold = new(big.Rat).Abs(pctThreshold)
-	}
-
-	if absDiff.Cmp(threshold) < 0 {
-		return false, "", entities.AlertSeverityNormal
-	}
-
-	// Check direction
-	isDown := diff.Sign() < 0
-	if cfg.Direction == "drop" && !isDown {
-		return false, "", entities.AlertSeverityNormal
-	}
-	if cfg.Direction == "rise" && isDown {
-		return false, "", entities.AlertSeverityNormal
-	}
-
-	pctChange := new(big.Rat).Quo(diff, ref)
-	pctChange.Mul(pctChange, big.NewRat(100, 1))
-
-	direction := "상승"
-	if isDown {
-		direction = "하락"
-	}
-
-	reason := fmt.Sprintf("%s %s $%s (%s 대비 %s%%)",
-		symbol, direction, formatDecimal(absDiff, 2), cfg.Reference, formatDecimal(pctChange, 2))
-
-	severity := entities.AlertSeverityNormal
-	pctAbs := new(big.Rat).Abs(pctChange)
-	fivePct := big.NewRat(5, 1)
-	if pctAbs.Cmp(fivePct) >= 0 {
-		severity = entities.AlertSeverityUrgent
-	}
-
-	return true, reason, severity
+	return services.EvaluatePriceChange(cfg, symbol, currentPrice, refPrice)
 }
 
 func (m *AlertMonitor) evalPriceLevel(rule *entities.AlertRule, currentPrice string) (bool, string, entities.AlertSeverity) {
@@ -235,64 +175,14 @@ func (m *AlertMonitor) evalPriceLevel(rule *entities.AlertRule, currentPrice str
 		return false, "", entities.AlertSeverityNormal
 	}
 
-	cur, ok := parseDecimal(currentPrice)
-	if !ok {
-		return false, "", entities.AlertSeverityNormal
-	}
-	target, ok := parseDecimal(cfg.Price)
-	if !ok {
-		return false, "", entities.AlertSeverityNormal
-	}
-
-	// Check previous state to detect crossing
+	// Previous state is needed to detect above/below crossings
 	var prevState entities.CheckState
 	if len(rule.LastCheckState) > 0 {
 		_ = json.Unmarshal(rule.LastCheckState, &prevState)
 	}
 
-	isAbove := cur.Cmp(target) >= 0
-
-	// Simple threshold check (gte/lte) - no crossing detection needed
-	if cfg.Direction == "gte" {
-		if !isAbove {
-			return false, "", entities.AlertSeverityNormal
-		}
-		reason := fmt.Sprintf("%s $%s 이상 도달 (현재 $%s)", rule.Symbol, cfg.Price, currentPrice)
-		return true, reason, entities.AlertSeverityNormal
-	}
-	if cfg.Direction == "lte" {
-		if isAbove {
-			return false, "", entities.AlertSeverityNormal
-		}
-		reason := fmt.Sprintf("%s $%s 이하 도달 (현재 $%s)", rule.Symbol, cfg.Price, currentPrice)
-		return true, reason, entities.AlertSeverityNormal
-	}
-
-	// Crossing detection (above/below)
-	if prevState.WasAboveLevel == nil {
-		// First check, just record state
-		return false, "", entities.AlertSeverityNormal
-	}
-
-	crossed := false
-	if cfg.Direction == "above" && !*prevState.WasAboveLevel && isAbove {
-		crossed = true
-	}
-	if cfg.Direction == "below" && *prevState.WasAboveLevel && !isAbove {
-		crossed = true
-	}
-
-	if !crossed {
-		return false, "", entities.AlertSeverityNormal
-	}
-
-	action := "돌파"
-	if cfg.Direction == "below" {
-		action = "이탈"
-	}
-	reason := fmt.Sprintf("%s $%s %s (현재 $%s)", rule.Symbol, cfg.Price, action, currentPrice)
-
-	return true, reason, entities.AlertSeverityNormal
+	triggered, reason, severity, _ := services.EvaluatePriceLevel(cfg, rule.Symbol, currentPrice, prevState.WasAboveLevel)
+	return triggered, reason, severity
 }
 
 func (m *AlertMonitor) evalMACross(ctx context.Context, rule *entities.AlertRule, currentPrice string, symbol string) (bool, string, entities.AlertSeverity) {
@@ -302,50 +192,17 @@ func (m *AlertMonitor) evalMACross(ctx context.Context, rule *entities.AlertRule
 	}
 
 	ma, err := m.calculateSMA(ctx, symbol, cfg.MATimeframe, cfg.MAPeriod)
-	if err != nil || ma == "" {
-		return false, "", entities.AlertSeverityNormal
-	}
-
-	cur, ok := parseDecimal(currentPrice)
-	if !ok {
-		return false, "", entities.AlertSeverityNormal
-	}
-	maVal, ok := parseDecimal(ma)
-	if !ok {
+	if err != nil || ma == nil {
 		return false, "", entities.AlertSeverityNormal
 	}
 
-	isAbove := cur.Cmp(maVal) >= 0
-
 	var prevState entities.CheckState
 	if len(rule.LastCheckState) > 0 {
 		_ = json.Unmarshal(rule.LastCheckState, &prevState)
 	}
 
-	if prevState.WasAboveMA == nil {
-		return false, "", entities.AlertSeverityNormal
-	}
-
-	crossed := false
-	if cfg.Direction == "below" && *prevState.WasAboveMA && !isAbove {
-		crossed = true
-	}
-	if cfg.Direction == "above" && !*prevState.WasAboveMA && isAbove {
-		crossed = true
-	}
-
-	if !crossed {
-		return false, "", entities.AlertSeverityNormal
-	}
-
-	action := "하향 돌파"
-	if cfg.Direction == "above" {
-		action = "상향 돌파"
-	}
-	reason := fmt.Sprintf("%s %d일 이평선 %s (MA: $%s, 현재: $%s)",
-		symbol, cfg.MAPeriod, action, ma, currentPrice)
-
-	return true, reason, entities.AlertSeverityUrgent
+	triggered, reason, severity, _ := services.EvaluateMACross(cfg, symbol, currentPrice, ma, prevState.WasAboveMA)
+	return triggered, reason, severity
 }
 
 func (m *AlertMonitor) evalVolatilitySpike(ctx context.Context, rule *entities.AlertRule, currentPrice string, symbol string) (bool, string, entities.AlertSeverity) {
@@ -364,120 +221,14 @@ func (m *AlertMonitor) evalVolatilitySpike(ctx context.Context, rule *entities.A
 		return false, "", entities.AlertSeverityNormal
 	}
 
-	// Fetch 20 recent klines to calculate stddev
+	// Fetch 20 recent klines for the baseline plus the current candle
 	const klineCount = 20
-	params := url.Values{}
-	params.Set("symbol", symbol)
-	params.Set("interval", timeframe)
-	params.Set("limit", fmt.Sprintf("%d", klineCount+1)) // +1 for current candle
-
-	reqURL := fmt.Sprintf("https://fapi.binance.com/fapi/v1/klines?%s", params.Encode())
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
-	if err != nil {
+	window, err := m.fetchKlines(ctx, symbol, timeframe, klineCount+1)
+	if err != nil || len(window) < klineCount+1 {
 		return false, "", entities.AlertSeverityNormal
 	}
 
-	resp, err := m.client.Do(req)
-	if err != nil {
-		return false, "", entities.AlertSeverityNormal
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return false, "", entities.AlertSeverityNormal
-	}
-
-	var raw [][]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
-		return false, "", entities.AlertSeverityNormal
-	}
-
-	if len(raw) < klineCount+1 {
-		return false, "", entities.AlertSeverityNormal
-	}
-
-	// Use all but last candle for stddev baseline, last candle is current
-	historical := raw[:len(raw)-1]
-	latest := raw[len(raw)-1]
-
-	// Calculate close price changes (absolute) for historical candles
-	var changes []*big.Rat
-	for _, row := range historical {
-		if len(row) < 5 {
-			continue
-		}
-		highStr, ok1 := asString(row[2])
-		lowStr, ok2 := asString(row[3])
-		if !ok1 || !ok2 {
-			continue
-		}
-		high, ok1 := parseDecimal(highStr)
-		low, ok2 := parseDecimal(lowStr)
-		if !ok1 || !ok2 {
-			continue
-		}
-		rng := new(big.Rat).Sub(high, low)
-		changes = append(changes, rng)
-	}
-
-	if len(changes) < 2 {
-		return false, "", entities.AlertSeverityNormal
-	}
-
-	// Mean of ranges
-	sum := new(big.Rat)
-	for _, c := range changes {
-		sum.Add(sum, c)
-	}
-	n := int64(len(changes))
-	mean := new(big.Rat).Quo(sum, big.NewRat(n, 1))
-
-	// Variance = sum((x - mean)^2) / n
-	varSum := new(big.Rat)
-	for _, c := range changes {
-		diff := new(big.Rat).Sub(c, mean)
-		diff2 := new(big.Rat).Mul(diff, diff)
-		varSum.Add(varSum, diff2)
-	}
-	variance := new(big.Rat).Quo(varSum, big.NewRat(n, 1))
-
-	// stddev approximation: use variance comparison instead of sqrt
-	// Trigger if (currentRange - mean)^2 > (multiplier * stddev)^2 = multiplier^2 * variance
-	if len(latest) < 5 {
-		return false, "", entities.AlertSeverityNormal
-	}
-	latestHighStr, ok1 := asString(latest[2])
-	latestLowStr, ok2 := asString(latest[3])
-	if !ok1 || !ok2 {
-		return false, "", entities.AlertSeverityNormal
-	}
-	latestHigh, ok1 := parseDecimal(latestHighStr)
-	latestLow, ok2 := parseDecimal(latestLowStr)
-	if !ok1 || !ok2 {
-		return false, "", entities.AlertSeverityNormal
-	}
-
-	latestRange := new(big.Rat).Sub(latestHigh, latestLow)
-	excess := new(big.Rat).Sub(latestRange, mean)
-
-	// Only trigger if range exceeds mean (i.e., positive excess)
-	if excess.Sign() <= 0 {
-		return false, "", entities.AlertSeverityNormal
-	}
-
-	// Compare excess^2 vs multiplier^2 * variance
-	excessSq := new(big.Rat).Mul(excess, excess)
-	multSq := new(big.Rat).Mul(multiplier, multiplier)
-	threshold := new(big.Rat).Mul(multSq, variance)
-
-	if excessSq.Cmp(threshold) < 0 {
-		return false, "", entities.AlertSeverityNormal
-	}
-
-	reason := fmt.Sprintf("%s 변동성 급등 감지 (%s 기준, 현재 범위: $%s, 평균: $%s)",
-		symbol, timeframe, formatDecimal(latestRange, 2), formatDecimal(mean, 2))
-
-	return true, reason, entities.AlertSeverityUrgent
+	return services.EvaluateVolatilitySpike(window, multiplier, symbol, timeframe)
 }
 
 func (m *AlertMonitor) buildCheckState(ctx context.Context, currentPrice string, rule *entities.AlertRule, symbol string) entities.CheckState {
@@ -498,11 +249,9 @@ func (m *AlertMonitor) buildCheckState(ctx context.Context, currentPrice string,
 		var cfg entities.MACrossConfig
 		if err := json.Unmarshal(rule.Config, &cfg); err == nil {
 			ma, err := m.calculateSMA(ctx, symbol, cfg.MATimeframe, cfg.MAPeriod)
-			if err == nil && ma != "" {
-				cur, ok1 := parseDecimal(currentPrice)
-				maVal, ok2 := parseDecimal(ma)
-				if ok1 && ok2 {
-					above := cur.Cmp(maVal) >= 0
+			if err == nil && ma != nil {
+				if cur, ok := parseDecimal(currentPrice); ok {
+					above := cur.Cmp(ma) >= 0
 					state.WasAboveMA = &above
 				}
 			}
@@ -604,55 +353,74 @@ func (m *AlertMonitor) fetchHistoricalPrice(ctx context.Context, symbol string,
 	return closeVal, nil
 }
 
-func (m *AlertMonitor) calculateSMA(ctx context.Context, symbol string, timeframe string, period int) (string, error) {
+func (m *AlertMonitor) calculateSMA(ctx context.Context, symbol string, timeframe string, period int) (*big.Rat, error) {
+	candles, err := m.fetchKlines(ctx, symbol, timeframe, period)
+	if err != nil {
+		return nil, err
+	}
+	if len(candles) < period {
+		return nil, nil
+	}
+
+	sum := new(big.Rat)
+	count := 0
+	for _, candle := range candles {
+		val, ok := parseDecimal(candle.Close)
+		if !ok {
+			continue
+		}
+		sum.Add(sum, val)
+		count++
+	}
+	if count == 0 {
+		return nil, nil
+	}
+
+	return sum.Quo(sum, big.NewRat(int64(count), 1)), nil
+}
+
+// fetchKlines returns the latest limit futures klines, the last one still forming.
+func (m *AlertMonitor) fetchKlines(ctx context.Context, symbol string, timeframe string, limit int) ([]*entities.Candle, error) {
 	params := url.Values{}
 	params.Set("symbol", symbol)
 	params.Set("interval", timeframe)
-	params.Set("limit", fmt.Sprintf("%d", period))
+	params.Set("limit", fmt.Sprintf("%d", limit))
 
 	reqURL := fmt.Sprintf("https://fapi.binance.com/fapi/v1/klines?%s", params.Encode())
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	resp, err := m.client.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("binance klines error %d", resp.StatusCode)
+		return nil, fmt.Errorf("binance klines error %d", resp.StatusCode)
 	}
 
 	var raw [][]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
-		return "", err
+		return nil, err
 	}
 
-	if len(raw) < period {
-		return "", nil
-	}
-
-	sum := new(big.Rat)
+	candles := make([]*entities.Candle, 0, len(raw))
 	for _, row := range raw {
 		if len(row) < 5 {
 			continue
 		}
-		closeStr, ok := asString(row[4])
-		if !ok {
-			continue
-		}
-		val, ok := parseDecimal(closeStr)
-		if !ok {
+		high, ok1 := asString(row[2])
+		low, ok2 := asString(row[3])
+		closeVal, ok3 := asString(row[4])
+		if !ok1 || !ok2 || !ok3 {
 			continue
 		}
-		sum.Add(sum, val)
+		candles = append(candles, &entities.Candle{High: high, Low: low, Close: closeVal})
 	}
-
-	avg := new(big.Rat).Quo(sum, big.NewRat(int64(len(raw)), 1))
-	return formatDecimal(avg, 2), nil
+	return candles, nil
 }
 
 func parseDuration(ref string) time.Duration {
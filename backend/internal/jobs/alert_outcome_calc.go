@@ -2,25 +2,20 @@ package jobs
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"io"
 	"log"
 	"math/big"
-	"net/http"
-	"net/url"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/moneyvessel/kifu/internal/domain/entities"
 	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/services"
 )
 
 type AlertOutcomeCalculator struct {
 	outcomeRepo repositories.AlertOutcomeRepository
-	client      *http.Client
+	candles     *services.CandleStore
 	intervals   []alertOutcomeInterval
 }
 
@@ -29,10 +24,10 @@ type alertOutcomeInterval struct {
 	Duration time.Duration
 }
 
-func NewAlertOutcomeCalculator(outcomeRepo repositories.AlertOutcomeRepository) *AlertOutcomeCalculator {
+func NewAlertOutcomeCalculator(outcomeRepo repositories.AlertOutcomeRepository, candles *services.CandleStore) *AlertOutcomeCalculator {
 	return &AlertOutcomeCalculator{
 		outcomeRepo: outcomeRepo,
-		client:      &http.Client{Timeout: 12 * time.Second},
+		candles:     candles,
 		intervals: []alertOutcomeInterval{
 			{Period: "1h", Duration: time.Hour},
 			{Period: "4h", Duration: 4 * time.Hour},
@@ -106,42 +101,11 @@ func (c *AlertOutcomeCalculator) calculateForDecision(ctx context.Context, inter
 }
 
 func (c *AlertOutcomeCalculator) fetchPrice(ctx context.Context, symbol string, target time.Time) (string, error) {
-	params := url.Values{}
-	params.Set("symbol", symbol)
-	params.Set("interval", "1m")
-	params.Set("startTime", fmt.Sprintf("%d", target.UnixMilli()))
-	params.Set("limit", "1")
-
-	reqURL := fmt.Sprintf("https://fapi.binance.com/fapi/v1/klines?%s", params.Encode())
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
-	if err != nil {
-		return "", err
-	}
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("binance klines error %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
-	}
-
-	var raw [][]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+	price, found, err := c.candles.CloseAt(ctx, entities.CandleVenueBinance, symbol, "1m", target)
+	if err != nil || !found {
 		return "", err
 	}
-	if len(raw) == 0 || len(raw[0]) < 5 {
-		return "", nil
-	}
-
-	closeVal, ok := asString(raw[0][4])
-	if !ok {
-		return "", nil
-	}
-	return closeVal, nil
+	return price, nil
 }
 
 func (c *AlertOutcomeCalculator) calculatePnL(reference, outcome string) (string, error) {
@@ -0,0 +1,53 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// BehaviorScanner detects revenge trading, overtrading and similar episodes
+// for recently active users.
+type BehaviorScanner interface {
+	ScanActive(ctx context.Context, limit int) (int, error)
+}
+
+type BehaviorPatternJob struct {
+	scanner   BehaviorScanner
+	interval  time.Duration
+	batchSize int
+}
+
+func NewBehaviorPatternJob(scanner BehaviorScanner) *BehaviorPatternJob {
+	return &BehaviorPatternJob{
+		scanner:   scanner,
+		interval:  30 * time.Minute,
+		batchSize: 500,
+	}
+}
+
+func (j *BehaviorPatternJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	go func() {
+		defer ticker.Stop()
+		j.runOnce(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.runOnce(ctx)
+			}
+		}
+	}()
+}
+
+func (j *BehaviorPatternJob) runOnce(ctx context.Context) {
+	stored, err := j.scanner.ScanActive(ctx, j.batchSize)
+	if err != nil {
+		log.Printf("behavior patterns: %v", err)
+	}
+	if stored > 0 {
+		log.Printf("behavior patterns: stored %d episodes", stored)
+	}
+}
@@ -0,0 +1,53 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// FingerprintBackfiller computes market-context fingerprints for bubbles
+// that don't have one yet.
+type FingerprintBackfiller interface {
+	BackfillFingerprints(ctx context.Context, limit int) (int, error)
+}
+
+type BubbleFingerprintJob struct {
+	backfiller FingerprintBackfiller
+	interval   time.Duration
+	batchSize  int
+}
+
+func NewBubbleFingerprintJob(backfiller FingerprintBackfiller) *BubbleFingerprintJob {
+	return &BubbleFingerprintJob{
+		backfiller: backfiller,
+		interval:   5 * time.Minute,
+		batchSize:  100,
+	}
+}
+
+func (j *BubbleFingerprintJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	go func() {
+		defer ticker.Stop()
+		j.runOnce(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.runOnce(ctx)
+			}
+		}
+	}()
+}
+
+func (j *BubbleFingerprintJob) runOnce(ctx context.Context) {
+	stored, err := j.backfiller.BackfillFingerprints(ctx, j.batchSize)
+	if err != nil {
+		log.Printf("bubble fingerprints: %v", err)
+	}
+	if stored > 0 {
+		log.Printf("bubble fingerprints: stored %d", stored)
+	}
+}
@@ -0,0 +1,54 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// OutboxFlusher delivers notification outbox rows that are due.
+type OutboxFlusher interface {
+	FlushDue(ctx context.Context) (int, error)
+}
+
+type NotificationOutboxJob struct {
+	flusher  OutboxFlusher
+	interval time.Duration
+}
+
+func NewNotificationOutboxJob(flusher OutboxFlusher) *NotificationOutboxJob {
+	return &NotificationOutboxJob{
+		flusher:  flusher,
+		interval: 15 * time.Second,
+	}
+}
+
+func (j *NotificationOutboxJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.runOnce(ctx)
+			}
+		}
+	}()
+}
+
+func (j *NotificationOutboxJob) runOnce(ctx context.Context) {
+	// Claimed rows are leased, so keep draining until nothing is due; a digest
+	// boundary with many queued rows shouldn't take several ticks.
+	for i := 0; i < 10; i++ {
+		processed, err := j.flusher.FlushDue(ctx)
+		if err != nil {
+			log.Printf("notification outbox: flush failed: %v", err)
+			return
+		}
+		if processed == 0 {
+			return
+		}
+	}
+}
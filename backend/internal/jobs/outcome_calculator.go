@@ -2,14 +2,10 @@ package jobs
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"io"
 	"log"
 	"math/big"
 	"net/http"
-	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -19,14 +15,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/moneyvessel/kifu/internal/domain/entities"
 	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/services"
 )
 
-const (
-	outcomeKlineBaseURL = "https://fapi.binance.com"
-)
-
-var outcomeUpbitCandleBaseURL = upbitAPIBaseURL
-
 type outcomePriceSource string
 
 const (
@@ -34,26 +25,36 @@ const (
 	outcomePriceSourceUpbit   outcomePriceSource = "upbit"
 )
 
-type OutcomeCalculator struct {
-	outcomeRepo repositories.OutcomeRepository
-	client      *http.Client
-	intervals   []outcomeInterval
-	mu                 sync.Mutex
-	upbitCooldownUntil time.Time
+// OutcomePriceSource prices bubbles for the outcome calculator. Resolve
+// reports whether the source covers a bubble and the symbol it knows it by;
+// PriceAt returns the last price at or before target.
+type OutcomePriceSource interface {
+	Name() string
+	Resolve(bubble *repositories.PendingOutcomeBubble) (string, bool)
+	PriceAt(ctx context.Context, symbol string, target time.Time) (string, bool, error)
 }
 
-type outcomeInterval struct {
-	Period   string
-	Duration time.Duration
+type OutcomeCalculator struct {
+	outcomeRepo repositories.OutcomeRepository
+	sources     []OutcomePriceSource
+	horizons    []services.OutcomeHorizon
+	mu          sync.Mutex
+	cooldowns   map[string]time.Time
 }
 
-func NewOutcomeCalculator(outcomeRepo repositories.OutcomeRepository) *OutcomeCalculator {
+// NewOutcomeCalculator prices crypto bubbles from candles. Extra sources are
+// tried first, in order, for the bubbles they resolve.
+func NewOutcomeCalculator(outcomeRepo repositories.OutcomeRepository, candles *services.CandleStore, extra ...OutcomePriceSource) *OutcomeCalculator {
+	sources := append([]OutcomePriceSource{}, extra...)
+	sources = append(sources,
+		&candleOutcomeSource{source: outcomePriceSourceUpbit, venue: entities.CandleVenueUpbit, candles: candles},
+		&candleOutcomeSource{source: outcomePriceSourceBinance, venue: entities.CandleVenueBinance, candles: candles},
+	)
 	return &OutcomeCalculator{
 		outcomeRepo: outcomeRepo,
-		client: &http.Client{
-			Timeout: 12 * time.Second,
-		},
-		intervals: parseOutcomeIntervals(),
+		sources:     sources,
+		horizons:    parseOutcomeHorizons(os.Getenv("OUTCOME_INTERVALS")),
+		cooldowns:   make(map[string]time.Time),
 	}
 }
 
@@ -74,30 +75,67 @@ func (c *OutcomeCalculator) Start(ctx context.Context) {
 
 func (c *OutcomeCalculator) runOnce(ctx context.Context) {
 	now := time.Now().UTC()
-	for _, interval := range c.intervals {
-		cutoff := now.Add(-interval.Duration)
-		pending, err := c.outcomeRepo.ListPending(ctx, interval.Period, cutoff, 200)
+	global := make(map[string]bool, len(c.horizons))
+	for _, horizon := range c.horizons {
+		global[horizon.Period] = true
+		pending, err := c.outcomeRepo.ListPending(ctx, horizon.Period, now.Add(-horizon.Duration), 200)
 		if err != nil {
 			log.Printf("outcome calc: list pending failed: %v", err)
 			continue
 		}
+		c.calculatePending(ctx, horizon, pending)
+	}
 
-		for _, item := range pending {
-			if item == nil {
-				continue
-			}
-			if err := c.calculateForBubble(ctx, interval, item); err != nil {
-				log.Printf("outcome calc: bubble %s error: %v", item.BubbleID.String(), err)
-			}
+	custom, err := c.outcomeRepo.ListCustomHorizons(ctx)
+	if err != nil {
+		log.Printf("outcome calc: list custom horizons failed: %v", err)
+		return
+	}
+	for _, period := range custom {
+		if global[period] {
+			continue
+		}
+		horizon, err := services.ParseOutcomeHorizon(period)
+		if err != nil {
+			log.Printf("outcome calc: skipping horizon %q: %v", period, err)
+			continue
+		}
+		var pending []*repositories.PendingOutcomeBubble
+		if horizon.UntilNextBubble() {
+			pending, err = c.outcomeRepo.ListPendingUntilNext(ctx, now, 200)
+		} else {
+			pending, err = c.outcomeRepo.ListPendingForHorizon(ctx, horizon.Period, now.Add(-horizon.Duration), 200)
+		}
+		if err != nil {
+			log.Printf("outcome calc: list pending %s failed: %v", horizon.Period, err)
+			continue
+		}
+		c.calculatePending(ctx, horizon, pending)
+	}
+}
+
+func (c *OutcomeCalculator) calculatePending(ctx context.Context, horizon services.OutcomeHorizon, pending []*repositories.PendingOutcomeBubble) {
+	for _, item := range pending {
+		if item == nil {
+			continue
+		}
+		if err := c.calculateForBubble(ctx, horizon, item); err != nil {
+			log.Printf("outcome calc: bubble %s error: %v", item.BubbleID.String(), err)
 		}
 	}
 }
 
-func (c *OutcomeCalculator) calculateForBubble(ctx context.Context, interval outcomeInterval, bubble *repositories.PendingOutcomeBubble) error {
-	targetTime := bubble.CandleTime.UTC().Add(interval.Duration)
+func (c *OutcomeCalculator) calculateForBubble(ctx context.Context, horizon services.OutcomeHorizon, bubble *repositories.PendingOutcomeBubble) error {
+	targetTime := bubble.CandleTime.UTC().Add(horizon.Duration)
+	if horizon.UntilNextBubble() {
+		if bubble.TargetTime == nil {
+			return nil
+		}
+		targetTime = bubble.TargetTime.UTC()
+	}
 	targetTime = floorToMinute(targetTime)
 
-	outcomePrice, ok, err := c.fetchOutcomePrice(ctx, bubble.Symbol, targetTime)
+	outcomePrice, source, ok, err := c.fetchOutcomePrice(ctx, bubble, targetTime)
 	if err != nil {
 		return err
 	}
@@ -113,10 +151,12 @@ func (c *OutcomeCalculator) calculateForBubble(ctx context.Context, interval out
 	outcome := &entities.Outcome{
 		ID:             uuid.New(),
 		BubbleID:       bubble.BubbleID,
-		Period:         interval.Period,
+		Period:         horizon.Period,
 		ReferencePrice: bubble.Price,
 		OutcomePrice:   outcomePrice,
 		PnLPercent:     pnl,
+		PriceSource:    &source,
+		TargetTime:     &targetTime,
 		CalculatedAt:   time.Now().UTC(),
 	}
 
@@ -124,130 +164,77 @@ func (c *OutcomeCalculator) calculateForBubble(ctx context.Context, interval out
 	return err
 }
 
-func (c *OutcomeCalculator) fetchOutcomePrice(ctx context.Context, symbol string, target time.Time) (string, bool, error) {
-	normalizedSymbol, source, ok := resolveOutcomeSymbolSource(symbol)
-	if !ok {
-		// Unsupported symbols should not fail the calculator loop.
-		return "", false, nil
-	}
-
-	if source == outcomePriceSourceUpbit {
-		if c.isUpbitCoolingDown() {
-			return "", false, nil
-		}
-
-		price, found, err := c.requestUpbitCandleClose(ctx, normalizedSymbol, target.Add(1*time.Minute), 1)
-		if err != nil {
-			return "", false, err
+// fetchOutcomePrice asks the first source that resolves the bubble. A source
+// that was rate limited is skipped until its cooldown ends.
+func (c *OutcomeCalculator) fetchOutcomePrice(ctx context.Context, bubble *repositories.PendingOutcomeBubble, target time.Time) (string, string, bool, error) {
+	for _, source := range c.sources {
+		symbol, ok := source.Resolve(bubble)
+		if !ok {
+			continue
 		}
-		if found {
-			return price, true, nil
+		name := source.Name()
+		if c.isCoolingDown(name) {
+			return "", name, false, nil
 		}
 
-		fallbackTo := target
-		if fallbackTo.IsZero() {
-			fallbackTo = time.Now().UTC()
-		}
-		price, found, err = c.requestUpbitCandleClose(ctx, normalizedSymbol, fallbackTo, 5)
+		price, found, err := source.PriceAt(ctx, symbol, target)
 		if err != nil {
-			return "", false, err
-		}
-		if found {
-			return price, true, nil
+			var rateErr *services.CandleRateLimitError
+			if errors.As(err, &rateErr) {
+				c.applyCooldown(name, rateErr.RetryAfter)
+				return "", name, false, nil
+			}
+			return "", name, false, err
 		}
-		return "", false, nil
-	}
-
-	price, ok, err := c.requestKlineClose(ctx, normalizedSymbol, target, target.Add(1*time.Minute), 1)
-	if err != nil {
-		return "", false, err
-	}
-	if ok {
-		return price, true, nil
-	}
-
-	fallbackStart := target.Add(-5 * time.Minute)
-	price, ok, err = c.requestKlineClose(ctx, normalizedSymbol, fallbackStart, target, 5)
-	if err != nil {
-		return "", false, err
-	}
-	if ok {
-		return price, true, nil
+		return price, name, found, nil
 	}
-
-	return "", false, nil
+	// Unsupported symbols should not fail the calculator loop.
+	return "", "", false, nil
 }
 
-func (c *OutcomeCalculator) requestUpbitCandleClose(ctx context.Context, market string, to time.Time, count int) (string, bool, error) {
-	params := url.Values{}
-	params.Set("market", market)
-	params.Set("to", to.UTC().Format(time.RFC3339))
-	params.Set("count", fmt.Sprintf("%d", count))
-
-	requestURL := fmt.Sprintf("%s/v1/candles/minutes/1?%s", outcomeUpbitCandleBaseURL, params.Encode())
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
-	if err != nil {
-		return "", false, err
-	}
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return "", false, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusTooManyRequests {
-		c.applyUpbitCooldown(resp.Header.Get("Retry-After"))
-		return "", false, nil
-	}
-
-	if resp.StatusCode == http.StatusNotFound {
-		// Upbit returns 404 "Code not found" for unsupported/delisted markets.
-		// Treat it as non-fatal so the calculator loop can continue.
-		return "", false, nil
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		payload, _ := io.ReadAll(resp.Body)
-		return "", false, fmt.Errorf("upbit candles error %d: %s", resp.StatusCode, strings.TrimSpace(string(payload)))
-	}
-
-	type upbitMinuteCandle struct {
-		TradePrice float64 `json:"trade_price"`
-	}
-
-	var candles []upbitMinuteCandle
-	if err := json.NewDecoder(resp.Body).Decode(&candles); err != nil {
-		return "", false, err
-	}
-	if len(candles) == 0 {
-		return "", false, nil
-	}
-
-	price := strings.TrimRight(strings.TrimRight(strconv.FormatFloat(candles[0].TradePrice, 'f', 8, 64), "0"), ".")
-	if price == "" {
-		price = "0"
-	}
-	return price, true, nil
-}
-
-func (c *OutcomeCalculator) isUpbitCoolingDown() bool {
+func (c *OutcomeCalculator) isCoolingDown(source string) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	return time.Now().UTC().Before(c.upbitCooldownUntil)
+	return time.Now().UTC().Before(c.cooldowns[source])
 }
 
-func (c *OutcomeCalculator) applyUpbitCooldown(retryAfterHeader string) {
+func (c *OutcomeCalculator) applyCooldown(source string, retryAfterHeader string) {
 	cooldown := parseRetryAfter(retryAfterHeader, 60*time.Second)
 	until := time.Now().UTC().Add(cooldown)
 
 	c.mu.Lock()
-	if until.After(c.upbitCooldownUntil) {
-		c.upbitCooldownUntil = until
+	if until.After(c.cooldowns[source]) {
+		c.cooldowns[source] = until
 	}
 	c.mu.Unlock()
 }
 
+// candleOutcomeSource prices crypto bubbles from the shared 1m candle store.
+type candleOutcomeSource struct {
+	source  outcomePriceSource
+	venue   string
+	candles *services.CandleStore
+}
+
+func (s *candleOutcomeSource) Name() string {
+	return string(s.source)
+}
+
+func (s *candleOutcomeSource) Resolve(bubble *repositories.PendingOutcomeBubble) (string, bool) {
+	if strings.EqualFold(bubble.AssetClass, "stock") {
+		return "", false
+	}
+	symbol, source, ok := resolveOutcomeSymbolSource(bubble.Symbol)
+	if !ok || source != s.source {
+		return "", false
+	}
+	return symbol, true
+}
+
+func (s *candleOutcomeSource) PriceAt(ctx context.Context, symbol string, target time.Time) (string, bool, error) {
+	return s.candles.CloseAt(ctx, s.venue, symbol, "1m", target)
+}
+
 func parseRetryAfter(headerValue string, fallback time.Duration) time.Duration {
 	trimmed := strings.TrimSpace(headerValue)
 	if trimmed == "" {
@@ -289,83 +276,35 @@ func resolveOutcomeSymbolSource(symbol string) (string, outcomePriceSource, bool
 	return "", "", false
 }
 
-func (c *OutcomeCalculator) requestKlineClose(ctx context.Context, symbol string, start time.Time, end time.Time, limit int) (string, bool, error) {
-	params := url.Values{}
-	params.Set("symbol", symbol)
-	params.Set("interval", "1m")
-	params.Set("startTime", fmt.Sprintf("%d", start.UTC().UnixMilli()))
-	params.Set("endTime", fmt.Sprintf("%d", end.UTC().UnixMilli()))
-	params.Set("limit", fmt.Sprintf("%d", limit))
-
-	requestURL := fmt.Sprintf("%s/fapi/v1/klines?%s", outcomeKlineBaseURL, params.Encode())
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
-	if err != nil {
-		return "", false, err
-	}
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return "", false, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		payload, _ := io.ReadAll(resp.Body)
-		return "", false, fmt.Errorf("binance klines error %d: %s", resp.StatusCode, strings.TrimSpace(string(payload)))
-	}
-
-	var raw [][]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
-		return "", false, err
-	}
-	if len(raw) == 0 {
-		return "", false, nil
-	}
-
-	row := raw[len(raw)-1]
-	if len(row) < 5 {
-		return "", false, nil
-	}
-	closeVal, ok := asString(row[4])
-	if !ok {
-		return "", false, nil
-	}
-	return closeVal, true, nil
-}
-
-func parseOutcomeIntervals() []outcomeInterval {
-	env := strings.TrimSpace(os.Getenv("OUTCOME_INTERVALS"))
-	if env == "" {
-		return []outcomeInterval{
-			{Period: "1h", Duration: time.Hour},
-			{Period: "4h", Duration: 4 * time.Hour},
-			{Period: "1d", Duration: 24 * time.Hour},
+// parseOutcomeHorizons reads the global horizons from a comma-separated list
+// such as "1h,4h,1d,15m". next_bubble and invalid entries are ignored, and an
+// empty result falls back to services.DefaultOutcomePeriods.
+func parseOutcomeHorizons(env string) []services.OutcomeHorizon {
+	horizons := make([]services.OutcomeHorizon, 0)
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(env, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
 		}
-	}
-
-	parts := strings.Split(env, ",")
-	intervals := make([]outcomeInterval, 0, len(parts))
-	for _, part := range parts {
-		trimmed := strings.TrimSpace(part)
-		switch trimmed {
-		case "1m":
-			intervals = append(intervals, outcomeInterval{Period: "1h", Duration: time.Minute})
-		case "5m":
-			intervals = append(intervals, outcomeInterval{Period: "4h", Duration: 5 * time.Minute})
-		case "15m":
-			intervals = append(intervals, outcomeInterval{Period: "1d", Duration: 15 * time.Minute})
+		horizon, err := services.ParseOutcomeHorizon(part)
+		if err != nil || horizon.UntilNextBubble() {
+			log.Printf("outcome calc: ignoring OUTCOME_INTERVALS entry %q", strings.TrimSpace(part))
+			continue
 		}
+		if seen[horizon.Period] {
+			continue
+		}
+		seen[horizon.Period] = true
+		horizons = append(horizons, horizon)
 	}
 
-	if len(intervals) == 0 {
-		return []outcomeInterval{
-			{Period: "1h", Duration: time.Hour},
-			{Period: "4h", Duration: 4 * time.Hour},
-			{Period: "1d", Duration: 24 * time.Hour},
+	if len(horizons) == 0 {
+		for _, period := range services.DefaultOutcomePeriods {
+			horizon, _ := services.ParseOutcomeHorizon(period)
+			horizons = append(horizons, horizon)
 		}
 	}
-
-	return intervals
+	return horizons
 }
 
 func calculatePnLPercent(reference string, outcome string) (string, error) {
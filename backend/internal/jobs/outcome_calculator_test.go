@@ -1,11 +1,13 @@
 package jobs
 
 import (
-	"fmt"
-	"net/http/httptest"
+	"context"
 	"net/http"
 	"testing"
 	"time"
+
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/services"
 )
 
 func TestResolveOutcomeSymbolSource(t *testing.T) {
@@ -89,28 +91,73 @@ func TestParseRetryAfter(t *testing.T) {
 	}
 }
 
-func TestRequestUpbitCandleCloseNotFoundSkips(t *testing.T) {
+func TestParseOutcomeHorizons(t *testing.T) {
 	t.Parallel()
 
-	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusNotFound)
-		_, _ = fmt.Fprint(w, `{"error":{"name":404,"message":"Code not found"}}`)
-	}))
-	defer srv.Close()
+	horizons := parseOutcomeHorizons("1m, 15m,60m,1h,next_bubble,bogus")
+	want := []string{"1m", "15m", "1h"}
+	if len(horizons) != len(want) {
+		t.Fatalf("expected %v, got %+v", want, horizons)
+	}
+	for i, period := range want {
+		if horizons[i].Period != period {
+			t.Fatalf("expected %v, got %+v", want, horizons)
+		}
+	}
+	if horizons[0].Duration != time.Minute {
+		t.Fatalf("1m should be stored as its own period, got %s", horizons[0].Duration)
+	}
+
+	defaults := parseOutcomeHorizons("")
+	if len(defaults) != 3 || defaults[2].Period != "1d" || defaults[2].Duration != 24*time.Hour {
+		t.Fatalf("expected default horizons, got %+v", defaults)
+	}
+}
+
+type fakeOutcomeSource struct {
+	name   string
+	assets string
+	calls  int
+	err    error
+}
+
+func (s *fakeOutcomeSource) Name() string { return s.name }
 
-	prev := outcomeUpbitCandleBaseURL
-	outcomeUpbitCandleBaseURL = srv.URL
-	defer func() { outcomeUpbitCandleBaseURL = prev }()
+func (s *fakeOutcomeSource) Resolve(bubble *repositories.PendingOutcomeBubble) (string, bool) {
+	return bubble.Symbol, bubble.AssetClass == s.assets
+}
+
+func (s *fakeOutcomeSource) PriceAt(_ context.Context, _ string, _ time.Time) (string, bool, error) {
+	s.calls++
+	if s.err != nil {
+		return "", false, s.err
+	}
+	return "123", true, nil
+}
+
+func TestFetchOutcomePriceUsesResolvingSourceAndCooldown(t *testing.T) {
+	t.Parallel()
+
+	stocks := &fakeOutcomeSource{name: "stocks", assets: "stock"}
+	calc := &OutcomeCalculator{sources: []OutcomePriceSource{stocks}, cooldowns: map[string]time.Time{}}
+	bubble := &repositories.PendingOutcomeBubble{Symbol: "005930/KRW", AssetClass: "stock"}
+
+	price, source, ok, err := calc.fetchOutcomePrice(context.Background(), bubble, time.Now())
+	if err != nil || !ok || price != "123" || source != "stocks" {
+		t.Fatalf("unexpected result %q %q %v %v", price, source, ok, err)
+	}
 
-	calc := &OutcomeCalculator{
-		client: &http.Client{Timeout: 2 * time.Second},
+	if _, _, ok, _ := calc.fetchOutcomePrice(context.Background(), &repositories.PendingOutcomeBubble{Symbol: "XYZ", AssetClass: "crypto"}, time.Now()); ok {
+		t.Fatalf("expected unresolved bubble to be skipped")
 	}
 
-	price, ok, err := calc.requestUpbitCandleClose(t.Context(), "KRW-UNKNOWN", time.Now().UTC(), 1)
-	if err != nil {
-		t.Fatalf("expected no error, got %v", err)
+	stocks.err = &services.CandleRateLimitError{Venue: "stocks", RetryAfter: "120"}
+	if _, _, ok, err := calc.fetchOutcomePrice(context.Background(), bubble, time.Now()); ok || err != nil {
+		t.Fatalf("expected rate limit to be swallowed, got %v %v", ok, err)
 	}
-	if ok {
-		t.Fatalf("expected not found to skip, got ok=true with price=%q", price)
+	calls := stocks.calls
+	calc.fetchOutcomePrice(context.Background(), bubble, time.Now())
+	if stocks.calls != calls {
+		t.Fatalf("expected source to be skipped while cooling down")
 	}
 }
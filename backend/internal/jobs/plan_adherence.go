@@ -0,0 +1,45 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// PlanEvaluator rescores manual position plans against their synced fills.
+type PlanEvaluator interface {
+	EvaluatePlans(ctx context.Context) error
+}
+
+type PlanAdherenceJob struct {
+	evaluator PlanEvaluator
+	interval  time.Duration
+}
+
+func NewPlanAdherenceJob(evaluator PlanEvaluator) *PlanAdherenceJob {
+	return &PlanAdherenceJob{
+		evaluator: evaluator,
+		interval:  5 * time.Minute,
+	}
+}
+
+func (j *PlanAdherenceJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.runOnce(ctx)
+			}
+		}
+	}()
+}
+
+func (j *PlanAdherenceJob) runOnce(ctx context.Context) {
+	if err := j.evaluator.EvaluatePlans(ctx); err != nil {
+		log.Printf("plan adherence: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// ExcursionCalculator computes MAE/MFE for closed manual positions and synced
+// round trips that don't have a current one yet.
+type ExcursionCalculator interface {
+	SyncRoundTrips(ctx context.Context, users int) error
+	ComputePending(ctx context.Context, limit int) (int, error)
+}
+
+type PositionExcursionJob struct {
+	calculator ExcursionCalculator
+	interval   time.Duration
+	batchSize  int
+	userBatch  int
+}
+
+func NewPositionExcursionJob(calculator ExcursionCalculator) *PositionExcursionJob {
+	return &PositionExcursionJob{
+		calculator: calculator,
+		interval:   10 * time.Minute,
+		batchSize:  100,
+		userBatch:  20,
+	}
+}
+
+func (j *PositionExcursionJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	go func() {
+		defer ticker.Stop()
+		j.runOnce(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.runOnce(ctx)
+			}
+		}
+	}()
+}
+
+func (j *PositionExcursionJob) runOnce(ctx context.Context) {
+	if err := j.calculator.SyncRoundTrips(ctx, j.userBatch); err != nil {
+		log.Printf("position excursions: round trips: %v", err)
+	}
+	stored, err := j.calculator.ComputePending(ctx, j.batchSize)
+	if err != nil {
+		log.Printf("position excursions: %v", err)
+	}
+	if stored > 0 {
+		log.Printf("position excursions: stored %d", stored)
+	}
+}
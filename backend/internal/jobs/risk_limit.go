@@ -0,0 +1,48 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RiskLimitEvaluator re-checks risk limits for users whose trades changed.
+type RiskLimitEvaluator interface {
+	EvaluatePending(ctx context.Context, limit int) (int, error)
+}
+
+type RiskLimitJob struct {
+	evaluator RiskLimitEvaluator
+	interval  time.Duration
+	batchSize int
+}
+
+func NewRiskLimitJob(evaluator RiskLimitEvaluator) *RiskLimitJob {
+	return &RiskLimitJob{
+		evaluator: evaluator,
+		interval:  time.Minute,
+		batchSize: 200,
+	}
+}
+
+func (j *RiskLimitJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	go func() {
+		defer ticker.Stop()
+		j.runOnce(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.runOnce(ctx)
+			}
+		}
+	}()
+}
+
+func (j *RiskLimitJob) runOnce(ctx context.Context) {
+	if _, err := j.evaluator.EvaluatePending(ctx, j.batchSize); err != nil {
+		log.Printf("risk limits: %v", err)
+	}
+}
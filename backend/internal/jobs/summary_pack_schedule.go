@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// SummaryPackScheduler runs due pack schedules and prunes expired packs.
+type SummaryPackScheduler interface {
+	RunDue(ctx context.Context) error
+	PruneExpired(ctx context.Context) (int64, error)
+}
+
+type SummaryPackScheduleJob struct {
+	scheduler     SummaryPackScheduler
+	interval      time.Duration
+	pruneInterval time.Duration
+	lastPrune     time.Time
+}
+
+func NewSummaryPackScheduleJob(scheduler SummaryPackScheduler) *SummaryPackScheduleJob {
+	return &SummaryPackScheduleJob{
+		scheduler:     scheduler,
+		interval:      time.Minute,
+		pruneInterval: 24 * time.Hour,
+	}
+}
+
+func (j *SummaryPackScheduleJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.runOnce(ctx)
+			}
+		}
+	}()
+}
+
+func (j *SummaryPackScheduleJob) runOnce(ctx context.Context) {
+	if err := j.scheduler.RunDue(ctx); err != nil {
+		log.Printf("summary pack schedule: %v", err)
+	}
+
+	if time.Since(j.lastPrune) < j.pruneInterval {
+		return
+	}
+	j.lastPrune = time.Now()
+	deleted, err := j.scheduler.PruneExpired(ctx)
+	if err != nil {
+		log.Printf("summary pack retention: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("summary pack retention: deleted %d packs", deleted)
+	}
+}
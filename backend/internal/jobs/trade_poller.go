@@ -28,6 +28,7 @@ import (
 	"github.com/moneyvessel/kifu/internal/domain/entities"
 	"github.com/moneyvessel/kifu/internal/domain/repositories"
 	cryptoutil "github.com/moneyvessel/kifu/internal/infrastructure/crypto"
+	"github.com/moneyvessel/kifu/internal/services"
 )
 
 const (
@@ -47,6 +48,7 @@ type TradePoller struct {
 	userSymbolRepo repositories.UserSymbolRepository
 	syncStateRepo  repositories.TradeSyncStateRepository
 	portfolioRepo  repositories.PortfolioRepository
+	riskLimits     *services.RiskLimitService
 	encryptionKey  []byte
 	pollInterval   time.Duration
 	client         *http.Client
@@ -63,6 +65,7 @@ type SyncOptions struct {
 
 type normalizedTrade struct {
 	ID           int64
+	OrderID      string
 	Symbol       string
 	Side         string
 	PositionSide *string
@@ -76,6 +79,7 @@ type normalizedTrade struct {
 
 type binanceFuturesTrade struct {
 	ID           int64  `json:"id"`
+	OrderID      int64  `json:"orderId"`
 	Symbol       string `json:"symbol"`
 	Side         string `json:"side"`
 	Quantity     string `json:"qty"`
@@ -88,6 +92,7 @@ type binanceFuturesTrade struct {
 
 type binanceSpotTrade struct {
 	ID        int64  `json:"id"`
+	OrderID   int64  `json:"orderId"`
 	Symbol    string `json:"symbol"`
 	Price     string `json:"price"`
 	Quantity  string `json:"qty"`
@@ -131,6 +136,7 @@ func NewTradePoller(
 	userSymbolRepo repositories.UserSymbolRepository,
 	syncStateRepo repositories.TradeSyncStateRepository,
 	portfolioRepo repositories.PortfolioRepository,
+	riskLimits *services.RiskLimitService,
 	encryptionKey []byte,
 ) *TradePoller {
 	useMock := strings.EqualFold(os.Getenv("MOCK_BINANCE_TRADES"), "true")
@@ -145,6 +151,7 @@ func NewTradePoller(
 		userSymbolRepo: userSymbolRepo,
 		syncStateRepo:  syncStateRepo,
 		portfolioRepo:  portfolioRepo,
+		riskLimits:     riskLimits,
 		encryptionKey:  encryptionKey,
 		pollInterval:   defaultPollInterval,
 		client: &http.Client{
@@ -473,6 +480,7 @@ func (p *TradePoller) requestFuturesTrades(ctx context.Context, apiKey string, a
 		}
 		trades = append(trades, normalizedTrade{
 			ID:           trade.ID,
+			OrderID:      strconv.FormatInt(trade.OrderID, 10),
 			Symbol:       trade.Symbol,
 			Side:         strings.ToUpper(trade.Side),
 			PositionSide: normalizePositionSide(trade.PositionSide),
@@ -538,6 +546,7 @@ func (p *TradePoller) requestSpotTrades(ctx context.Context, apiKey string, apiS
 		}
 		trades = append(trades, normalizedTrade{
 			ID:        trade.ID,
+			OrderID:   strconv.FormatInt(trade.OrderID, 10),
 			Symbol:    trade.Symbol,
 			Side:      side,
 			Quantity:  trade.Quantity,
@@ -811,6 +820,7 @@ func (p *TradePoller) requestUpbitTrades(ctx context.Context, apiKey string, api
 
 					trades = append(trades, normalizedTrade{
 						ID:        tradeID,
+						OrderID:   order.UUID,
 						Symbol:    toInternalSymbol(order.Market),
 						Side:      side,
 						Quantity:  qty,
@@ -893,6 +903,7 @@ func (p *TradePoller) persistTrades(ctx context.Context, userID uuid.UUID, excha
 		}
 	}
 
+	var earliestNew time.Time
 	for _, trade := range trades {
 		tradeTime := time.UnixMilli(trade.TradeTime).UTC()
 		candleTime := floorToTimeframe(tradeTime, symbol.TimeframeDefault)
@@ -932,6 +943,10 @@ func (p *TradePoller) persistTrades(ctx context.Context, userID uuid.UUID, excha
 			realized := trade.RealizedPnL
 			tradeRecord.RealizedPnL = &realized
 		}
+		if trade.OrderID != "" && trade.OrderID != "0" {
+			orderID := trade.OrderID
+			tradeRecord.OrderID = &orderID
+		}
 
 		if err := p.insertBubbleTradeTx(ctx, bubble, tradeRecord); err != nil {
 			if errors.Is(err, errDuplicateTrade) {
@@ -945,6 +960,15 @@ func (p *TradePoller) persistTrades(ctx context.Context, userID uuid.UUID, excha
 				log.Printf("trade poller: trade_event sync failed (user=%s exchange=%s trade=%s): %v", userID.String(), exchange, tradeRecord.ID.String(), err)
 			}
 		}
+		if earliestNew.IsZero() || tradeTime.Before(earliestNew) {
+			earliestNew = tradeTime
+		}
+	}
+
+	if p.riskLimits != nil && !earliestNew.IsZero() {
+		if err := p.riskLimits.QueueEvaluation(ctx, userID, earliestNew); err != nil {
+			log.Printf("trade poller: queue risk limit check failed (user=%s): %v", userID.String(), err)
+		}
 	}
 
 	return nil
@@ -963,12 +987,12 @@ func (p *TradePoller) insertBubbleTradeTx(ctx context.Context, bubble *entities.
 	}()
 
 	tradeInsert := `
-		INSERT INTO trades (id, user_id, bubble_id, binance_trade_id, exchange, symbol, side, quantity, price, realized_pnl, trade_time)
-		VALUES ($1, $2, NULL, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO trades (id, user_id, bubble_id, binance_trade_id, exchange, symbol, side, order_id, quantity, price, realized_pnl, trade_time)
+		VALUES ($1, $2, NULL, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		ON CONFLICT (user_id, exchange, symbol, binance_trade_id) DO NOTHING
 	`
 	result, err := tx.Exec(ctx, tradeInsert,
-		trade.ID, trade.UserID, trade.BinanceTradeID, trade.Exchange, trade.Symbol, trade.Side, trade.Quantity, trade.Price, trade.RealizedPnL, trade.TradeTime)
+		trade.ID, trade.UserID, trade.BinanceTradeID, trade.Exchange, trade.Symbol, trade.Side, trade.OrderID, trade.Quantity, trade.Price, trade.RealizedPnL, trade.TradeTime)
 	if err != nil {
 		return err
 	}
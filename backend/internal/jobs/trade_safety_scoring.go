@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// TradeSafetyScorer scores stored fills that have no safety signal yet.
+type TradeSafetyScorer interface {
+	ScorePending(ctx context.Context, limit int) (int, error)
+}
+
+type TradeSafetyScoringJob struct {
+	scorer    TradeSafetyScorer
+	interval  time.Duration
+	batchSize int
+}
+
+func NewTradeSafetyScoringJob(scorer TradeSafetyScorer) *TradeSafetyScoringJob {
+	return &TradeSafetyScoringJob{
+		scorer:    scorer,
+		interval:  2 * time.Minute,
+		batchSize: 50,
+	}
+}
+
+func (j *TradeSafetyScoringJob) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	go func() {
+		defer ticker.Stop()
+		j.runOnce(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.runOnce(ctx)
+			}
+		}
+	}()
+}
+
+func (j *TradeSafetyScoringJob) runOnce(ctx context.Context) {
+	stored, err := j.scorer.ScorePending(ctx, j.batchSize)
+	if err != nil {
+		log.Printf("trade safety: %v", err)
+	}
+	if stored > 0 {
+		log.Printf("trade safety: stored %d signals", stored)
+	}
+}
@@ -0,0 +1,416 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+const (
+	alertBacktestMaxWindow = 90 * 24 * time.Hour
+	alertBacktestMaxBars   = 20000
+	alertBacktestVolBars   = 20
+)
+
+var (
+	ErrInvalidBacktestWindow = errors.New("invalid backtest window")
+	ErrBacktestTooManyBars   = errors.New("backtest window too large for interval")
+	ErrInvalidRuleConfig     = errors.New("invalid rule config")
+)
+
+var alertBacktestHorizons = []struct {
+	Period   string
+	Duration time.Duration
+}{
+	{Period: "1h", Duration: time.Hour},
+	{Period: "4h", Duration: 4 * time.Hour},
+	{Period: "1d", Duration: 24 * time.Hour},
+}
+
+type AlertBacktestRequest struct {
+	Rule     *entities.AlertRule
+	Start    time.Time
+	End      time.Time
+	Interval string
+}
+
+type AlertBacktestForwardReturn struct {
+	Period        string  `json:"period"`
+	Price         *string `json:"price"`
+	ReturnPercent *string `json:"return_percent"`
+}
+
+type AlertBacktestFire struct {
+	FiredAt        time.Time                    `json:"fired_at"`
+	Price          string                       `json:"price"`
+	Reason         string                       `json:"reason"`
+	Severity       entities.AlertSeverity       `json:"severity"`
+	ForwardReturns []AlertBacktestForwardReturn `json:"forward_returns"`
+}
+
+type AlertBacktestHorizonSummary struct {
+	Period           string  `json:"period"`
+	Samples          int     `json:"samples"`
+	AvgReturnPercent *string `json:"avg_return_percent"`
+	PositiveRate     *string `json:"positive_rate"`
+}
+
+type AlertBacktestResult struct {
+	Symbol          string                        `json:"symbol"`
+	RuleType        entities.RuleType             `json:"rule_type"`
+	Interval        string                        `json:"interval"`
+	Start           time.Time                     `json:"start"`
+	End             time.Time                     `json:"end"`
+	CooldownMinutes int                           `json:"cooldown_minutes"`
+	BarsEvaluated   int                           `json:"bars_evaluated"`
+	FireCount       int                           `json:"fire_count"`
+	Fires           []AlertBacktestFire           `json:"fires"`
+	Summary         []AlertBacktestHorizonSummary `json:"summary"`
+}
+
+// AlertBacktestService replays an alert rule over stored candles, mirroring
+// the checks AlertMonitor runs live, so users can tune thresholds up front.
+type AlertBacktestService struct {
+	candles *CandleStore
+	now     func() time.Time
+}
+
+func NewAlertBacktestService(candles *CandleStore) *AlertBacktestService {
+	return &AlertBacktestService{
+		candles: candles,
+		now:     time.Now,
+	}
+}
+
+// backtestEvalFunc evaluates the rule at the close of series[i].
+type backtestEvalFunc func(series *candleSeries, i int) (bool, string, entities.AlertSeverity)
+
+func (s *AlertBacktestService) Run(ctx context.Context, req AlertBacktestRequest) (*AlertBacktestResult, error) {
+	rule := req.Rule
+	if rule == nil {
+		return nil, ErrInvalidRuleConfig
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(rule.Symbol))
+	if symbol == "" {
+		return nil, ErrInvalidRuleConfig
+	}
+
+	now := s.now().UTC()
+	start := req.Start.UTC()
+	end := req.End.UTC()
+	if end.IsZero() || end.After(now) {
+		end = now
+	}
+	if start.IsZero() || !start.Before(end) || end.Sub(start) > alertBacktestMaxWindow {
+		return nil, ErrInvalidBacktestWindow
+	}
+
+	interval := strings.ToLower(strings.TrimSpace(req.Interval))
+	if rule.RuleType == entities.RuleTypeVolatilitySpike {
+		// Volatility is measured per bar of the configured timeframe.
+		var cfg entities.VolatilitySpikeConfig
+		if err := json.Unmarshal(rule.Config, &cfg); err != nil {
+			return nil, ErrInvalidRuleConfig
+		}
+		interval = cfg.Timeframe
+		if interval == "" {
+			interval = "1h"
+		}
+	}
+	if interval == "" {
+		interval = defaultBacktestInterval(end.Sub(start))
+	}
+	step, ok := CandleIntervalDuration(interval)
+	if !ok {
+		return nil, ErrUnsupportedCandleInterval
+	}
+	if int(end.Sub(start)/step) > alertBacktestMaxBars {
+		return nil, ErrBacktestTooManyBars
+	}
+
+	eval, lookback, err := s.buildEvaluator(ctx, rule, symbol, start, end, step)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extend past end so fires near the end still get forward returns.
+	seriesEnd := end.Add(24 * time.Hour)
+	if seriesEnd.After(now) {
+		seriesEnd = now
+	}
+	candles, err := s.candles.Range(ctx, entities.CandleVenueBinance, symbol, interval, start.Add(-lookback), seriesEnd)
+	if err != nil {
+		return nil, err
+	}
+	series := newCandleSeries(candles, step)
+
+	forward := series
+	if step > time.Hour {
+		hourly, err := s.candles.Range(ctx, entities.CandleVenueBinance, symbol, "1h", start, seriesEnd)
+		if err != nil {
+			return nil, err
+		}
+		forward = newCandleSeries(hourly, time.Hour)
+	}
+
+	result := &AlertBacktestResult{
+		Symbol:          symbol,
+		RuleType:        rule.RuleType,
+		Interval:        interval,
+		Start:           start,
+		End:             end,
+		CooldownMinutes: rule.CooldownMinutes,
+		Fires:           []AlertBacktestFire{},
+	}
+
+	cooldown := time.Duration(rule.CooldownMinutes) * time.Minute
+	var lastFired *time.Time
+	for i, candle := range series.candles {
+		at := candle.OpenTime.Add(step)
+		if at.Before(start) || at.After(end) {
+			continue
+		}
+		result.BarsEvaluated++
+		if lastFired != nil && !at.After(lastFired.Add(cooldown)) {
+			continue
+		}
+
+		triggered, reason, severity := eval(series, i)
+		if !triggered {
+			continue
+		}
+
+		fired := at
+		lastFired = &fired
+		result.Fires = append(result.Fires, AlertBacktestFire{
+			FiredAt:        at,
+			Price:          candle.Close,
+			Reason:         reason,
+			Severity:       severity,
+			ForwardReturns: forwardReturns(forward, at, candle.Close, now),
+		})
+	}
+
+	result.FireCount = len(result.Fires)
+	result.Summary = summarizeBacktestFires(result.Fires)
+	return result, nil
+}
+
+func (s *AlertBacktestService) buildEvaluator(ctx context.Context, rule *entities.AlertRule, symbol string, start, end time.Time, step time.Duration) (backtestEvalFunc, time.Duration, error) {
+	switch rule.RuleType {
+	case entities.RuleTypePriceChange:
+		var cfg entities.PriceChangeConfig
+		if err := json.Unmarshal(rule.Config, &cfg); err != nil {
+			return nil, 0, ErrInvalidRuleConfig
+		}
+		if parseDecimal(cfg.ThresholdValue) == nil {
+			return nil, 0, ErrInvalidRuleConfig
+		}
+		ref := backtestReferenceDuration(cfg.Reference)
+		return func(series *candleSeries, i int) (bool, string, entities.AlertSeverity) {
+			at := series.closeTime(i)
+			refPrice, ok := series.closeAtOrBefore(at.Add(-ref))
+			if !ok {
+				return false, "", entities.AlertSeverityNormal
+			}
+			return EvaluatePriceChange(cfg, symbol, series.candles[i].Close, refPrice)
+		}, ref + step, nil
+
+	case entities.RuleTypePriceLevel:
+		var cfg entities.PriceLevelConfig
+		if err := json.Unmarshal(rule.Config, &cfg); err != nil {
+			return nil, 0, ErrInvalidRuleConfig
+		}
+		if parseDecimal(cfg.Price) == nil {
+			return nil, 0, ErrInvalidRuleConfig
+		}
+		var wasAbove *bool
+		return func(series *candleSeries, i int) (bool, string, entities.AlertSeverity) {
+			triggered, reason, severity, isAbove := EvaluatePriceLevel(cfg, symbol, series.candles[i].Close, wasAbove)
+			if isAbove != nil {
+				wasAbove = isAbove
+			}
+			return triggered, reason, severity
+		}, 0, nil
+
+	case entities.RuleTypeMACross:
+		var cfg entities.MACrossConfig
+		if err := json.Unmarshal(rule.Config, &cfg); err != nil || cfg.MAPeriod <= 0 {
+			return nil, 0, ErrInvalidRuleConfig
+		}
+		maStep, ok := CandleIntervalDuration(cfg.MATimeframe)
+		if !ok {
+			return nil, 0, ErrInvalidRuleConfig
+		}
+		maCandles, err := s.candles.Range(ctx, entities.CandleVenueBinance, symbol, cfg.MATimeframe, start.Add(-time.Duration(cfg.MAPeriod+1)*maStep), end)
+		if err != nil {
+			return nil, 0, err
+		}
+		maSeries := newCandleSeries(maCandles, maStep)
+		var wasAbove *bool
+		return func(series *candleSeries, i int) (bool, string, entities.AlertSeverity) {
+			current := series.candles[i].Close
+			ma := maSeries.movingAverage(series.closeTime(i), cfg.MAPeriod, parseDecimal(current))
+			triggered, reason, severity, isAbove := EvaluateMACross(cfg, symbol, current, ma, wasAbove)
+			wasAbove = isAbove
+			return triggered, reason, severity
+		}, 0, nil
+
+	case entities.RuleTypeVolatilitySpike:
+		var cfg entities.VolatilitySpikeConfig
+		if err := json.Unmarshal(rule.Config, &cfg); err != nil {
+			return nil, 0, ErrInvalidRuleConfig
+		}
+		multiplier := parseDecimal(cfg.Multiplier)
+		if multiplier == nil {
+			return nil, 0, ErrInvalidRuleConfig
+		}
+		timeframe := cfg.Timeframe
+		if timeframe == "" {
+			timeframe = "1h"
+		}
+		return func(series *candleSeries, i int) (bool, string, entities.AlertSeverity) {
+			if i < alertBacktestVolBars {
+				return false, "", entities.AlertSeverityNormal
+			}
+			return EvaluateVolatilitySpike(series.candles[i-alertBacktestVolBars:i+1], multiplier, symbol, timeframe)
+		}, time.Duration(alertBacktestVolBars) * step, nil
+
+	default:
+		return nil, 0, ErrInvalidRuleConfig
+	}
+}
+
+func forwardReturns(series *candleSeries, firedAt time.Time, price string, now time.Time) []AlertBacktestForwardReturn {
+	base := parseDecimal(price)
+	results := make([]AlertBacktestForwardReturn, 0, len(alertBacktestHorizons))
+	for _, horizon := range alertBacktestHorizons {
+		item := AlertBacktestForwardReturn{Period: horizon.Period}
+		target := firedAt.Add(horizon.Duration)
+		if !target.After(now) && base != nil && base.Sign() != 0 {
+			if closeVal, ok := series.closeAtOrBefore(target); ok {
+				if out := parseDecimal(closeVal); out != nil {
+					ret := new(big.Rat).Sub(out, base)
+					ret.Quo(ret, base)
+					ret.Mul(ret, big.NewRat(100, 1))
+					item.Price = ptr(closeVal)
+					item.ReturnPercent = normalizeDecimal(ret)
+				}
+			}
+		}
+		results = append(results, item)
+	}
+	return results
+}
+
+func summarizeBacktestFires(fires []AlertBacktestFire) []AlertBacktestHorizonSummary {
+	summary := make([]AlertBacktestHorizonSummary, 0, len(alertBacktestHorizons))
+	for idx, horizon := range alertBacktestHorizons {
+		item := AlertBacktestHorizonSummary{Period: horizon.Period}
+		sum := new(big.Rat)
+		positive := 0
+		for _, fire := range fires {
+			if idx >= len(fire.ForwardReturns) || fire.ForwardReturns[idx].ReturnPercent == nil {
+				continue
+			}
+			ret := parseDecimal(*fire.ForwardReturns[idx].ReturnPercent)
+			if ret == nil {
+				continue
+			}
+			item.Samples++
+			sum.Add(sum, ret)
+			if ret.Sign() > 0 {
+				positive++
+			}
+		}
+		if item.Samples > 0 {
+			n := big.NewRat(int64(item.Samples), 1)
+			item.AvgReturnPercent = normalizeDecimal(new(big.Rat).Quo(sum, n))
+			item.PositiveRate = normalizeDecimal(new(big.Rat).Quo(big.NewRat(int64(positive), 1), n))
+		}
+		summary = append(summary, item)
+	}
+	return summary
+}
+
+func defaultBacktestInterval(window time.Duration) string {
+	switch {
+	case window <= 3*24*time.Hour:
+		return "1m"
+	case window <= 14*24*time.Hour:
+		return "5m"
+	case window <= 30*24*time.Hour:
+		return "15m"
+	default:
+		return "1h"
+	}
+}
+
+func backtestReferenceDuration(ref string) time.Duration {
+	switch ref {
+	case "1h":
+		return time.Hour
+	case "4h":
+		return 4 * time.Hour
+	default:
+		return 24 * time.Hour
+	}
+}
+
+// candleSeries is an ascending run of bars with close-time lookups.
+type candleSeries struct {
+	candles []*entities.Candle
+	step    time.Duration
+}
+
+func newCandleSeries(candles []*entities.Candle, step time.Duration) *candleSeries {
+	sort.Slice(candles, func(i, j int) bool { return candles[i].OpenTime.Before(candles[j].OpenTime) })
+	return &candleSeries{candles: candles, step: step}
+}
+
+func (s *candleSeries) closeTime(i int) time.Time {
+	return s.candles[i].OpenTime.Add(s.step)
+}
+
+// lastClosedIndex returns the index of the latest bar closed at or before t, or -1.
+func (s *candleSeries) lastClosedIndex(t time.Time) int {
+	idx := sort.Search(len(s.candles), func(i int) bool {
+		return s.closeTime(i).After(t)
+	})
+	return idx - 1
+}
+
+func (s *candleSeries) closeAtOrBefore(t time.Time) (string, bool) {
+	idx := s.lastClosedIndex(t)
+	if idx < 0 {
+		return "", false
+	}
+	return s.candles[idx].Close, true
+}
+
+// movingAverage averages the last period-1 closed bars plus the current
+// price, matching the live SMA that includes the forming bar.
+func (s *candleSeries) movingAverage(at time.Time, period int, current *big.Rat) *big.Rat {
+	if current == nil {
+		return nil
+	}
+	idx := s.lastClosedIndex(at)
+	if idx+1 < period-1 {
+		return nil
+	}
+	sum := new(big.Rat).Set(current)
+	for i := idx - (period - 2); i <= idx; i++ {
+		val := parseDecimal(s.candles[i].Close)
+		if val == nil {
+			return nil
+		}
+		sum.Add(sum, val)
+	}
+	return sum.Quo(sum, big.NewRat(int64(period), 1))
+}
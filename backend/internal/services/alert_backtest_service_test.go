@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+func newTestBacktestService(t *testing.T, start time.Time, closes []float64, now time.Time) *AlertBacktestService {
+	t.Helper()
+
+	repo := newFakeCandleRepo()
+	candles := make([]*entities.Candle, 0, len(closes))
+	for i, closeVal := range closes {
+		price := strconv.FormatFloat(closeVal, 'f', -1, 64)
+		candles = append(candles, &entities.Candle{
+			OpenTime:  start.Add(time.Duration(i) * time.Minute),
+			Open:      price,
+			High:      price,
+			Low:       price,
+			Close:     price,
+			Volume:    "1",
+			FetchedAt: now,
+		})
+	}
+	_ = repo.UpsertMany(context.Background(), candles)
+
+	store := NewCandleStore(repo, &fakeCandleFetcher{})
+	store.now = func() time.Time { return now }
+	svc := NewAlertBacktestService(store)
+	svc.now = func() time.Time { return now }
+	return svc
+}
+
+func TestAlertBacktestPriceLevelRespectsCooldown(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	closes := make([]float64, 180)
+	for i := range closes {
+		closes[i] = 100
+		if i%25 == 10 {
+			closes[i] = 120
+		}
+	}
+	now := start.Add(3 * time.Hour)
+	svc := newTestBacktestService(t, start, closes, now)
+
+	cfg, _ := json.Marshal(entities.PriceLevelConfig{Price: "110", Direction: "gte"})
+	result, err := svc.Run(context.Background(), AlertBacktestRequest{
+		Rule: &entities.AlertRule{
+			Symbol:          "BTCUSDT",
+			RuleType:        entities.RuleTypePriceLevel,
+			Config:          cfg,
+			CooldownMinutes: 30,
+		},
+		Start:    start,
+		End:      start.Add(2 * time.Hour),
+		Interval: "1m",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Spikes every 25 minutes, but the 30 minute cooldown skips every other one.
+	if result.FireCount != 3 {
+		t.Fatalf("expected 3 fires, got %d", result.FireCount)
+	}
+	first := result.Fires[0]
+	if !first.FiredAt.Equal(start.Add(11 * time.Minute)) {
+		t.Fatalf("unexpected first fire time: %v", first.FiredAt)
+	}
+	if first.ForwardReturns[0].ReturnPercent == nil || *first.ForwardReturns[0].ReturnPercent != "-16.66666667" {
+		t.Fatalf("unexpected 1h forward return: %v", first.ForwardReturns[0].ReturnPercent)
+	}
+	if first.ForwardReturns[2].ReturnPercent != nil {
+		t.Fatalf("1d forward return should be unavailable")
+	}
+}
+
+func TestAlertBacktestRejectsInvalidWindow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+	svc := newTestBacktestService(t, now, nil, now)
+	cfg, _ := json.Marshal(entities.PriceLevelConfig{Price: "1", Direction: "gte"})
+	rule := &entities.AlertRule{Symbol: "BTCUSDT", RuleType: entities.RuleTypePriceLevel, Config: cfg}
+
+	if _, err := svc.Run(context.Background(), AlertBacktestRequest{Rule: rule, Start: now, End: now.Add(-time.Hour)}); err != ErrInvalidBacktestWindow {
+		t.Fatalf("expected ErrInvalidBacktestWindow, got %v", err)
+	}
+	if _, err := svc.Run(context.Background(), AlertBacktestRequest{Rule: rule, Start: now.Add(-60 * 24 * time.Hour), End: now, Interval: "1m"}); err != ErrBacktestTooManyBars {
+		t.Fatalf("expected ErrBacktestTooManyBars, got %v", err)
+	}
+}
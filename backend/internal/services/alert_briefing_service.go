@@ -28,6 +28,7 @@ type AlertBriefingService struct {
 	userKeyRepo  repositories.UserAIKeyRepository
 	channelRepo  repositories.NotificationChannelRepository
 	tradeRepo    repositories.TradeRepository
+	candles      *CandleStore
 	encKey       []byte
 	sender       notification.Sender
 	client       *http.Client
@@ -41,6 +42,7 @@ func NewAlertBriefingService(
 	userKeyRepo repositories.UserAIKeyRepository,
 	channelRepo repositories.NotificationChannelRepository,
 	tradeRepo repositories.TradeRepository,
+	candles *CandleStore,
 	encKey []byte,
 	sender notification.Sender,
 ) *AlertBriefingService {
@@ -55,6 +57,7 @@ func NewAlertBriefingService(
 		userKeyRepo:  userKeyRepo,
 		channelRepo:  channelRepo,
 		tradeRepo:    tradeRepo,
+		candles:      candles,
 		encKey:       encKey,
 		sender:       sender,
 		client:       &http.Client{Timeout: 30 * time.Second},
@@ -147,6 +150,7 @@ func (s *AlertBriefingService) HandleTrigger(ctx context.Context, alert *entitie
 		Body:     body,
 		Severity: string(alert.Severity),
 		DeepLink: fmt.Sprintf("%s/alerts/%s", s.appBaseURL, alert.ID.String()),
+		AlertID:  &alert.ID,
 	}
 
 	if err := s.sender.Send(ctx, alert.UserID, msg); err != nil {
@@ -198,52 +202,20 @@ type klineItem struct {
 }
 
 func (s *AlertBriefingService) fetchKlines(ctx context.Context, symbol string, interval string, limit int) ([]klineItem, error) {
-	params := url.Values{}
-	params.Set("symbol", symbol)
-	params.Set("interval", interval)
-	params.Set("limit", fmt.Sprintf("%d", limit))
-
-	reqURL := fmt.Sprintf("https://fapi.binance.com/fapi/v1/klines?%s", params.Encode())
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := s.client.Do(req)
+	candles, err := s.candles.Latest(ctx, entities.CandleVenueBinance, symbol, interval, time.Time{}, limit)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("klines error %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
-	}
-
-	var raw [][]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
-		return nil, err
-	}
-
-	items := make([]klineItem, 0, len(raw))
-	for _, row := range raw {
-		if len(row) < 6 {
-			continue
-		}
-		openTime, _ := row[0].(float64)
-		open, _ := row[1].(string)
-		high, _ := row[2].(string)
-		low, _ := row[3].(string)
-		closeVal, _ := row[4].(string)
-		volume, _ := row[5].(string)
 
+	items := make([]klineItem, 0, len(candles))
+	for _, candle := range candles {
 		items = append(items, klineItem{
-			Time:   int64(openTime) / 1000,
-			Open:   open,
-			High:   high,
-			Low:    low,
-			Close:  closeVal,
-			Volume: volume,
+			Time:   candle.OpenTime.Unix(),
+			Open:   candle.Open,
+			High:   candle.High,
+			Low:    candle.Low,
+			Close:  candle.Close,
+			Volume: candle.Volume,
 		})
 	}
 
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+var (
+	ErrAlertNotFound             = errors.New("alert not found")
+	ErrAlertDecisionNotFound     = errors.New("alert decision not found")
+	ErrAlertAlreadyDecided       = errors.New("alert already has a decision")
+	ErrInvalidDecisionAction     = errors.New("invalid action")
+	ErrInvalidDecisionConfidence = errors.New("invalid confidence")
+)
+
+type AlertDecisionInput struct {
+	Action     string
+	Memo       *string
+	Confidence *string
+}
+
+// AlertDecisionService records a user's decision on an alert. The HTTP API and
+// the Telegram bot both go through it so the rules stay in one place.
+type AlertDecisionService struct {
+	alertRepo    repositories.AlertRepository
+	decisionRepo repositories.AlertDecisionRepository
+	now          func() time.Time
+}
+
+func NewAlertDecisionService(alertRepo repositories.AlertRepository, decisionRepo repositories.AlertDecisionRepository) *AlertDecisionService {
+	return &AlertDecisionService{
+		alertRepo:    alertRepo,
+		decisionRepo: decisionRepo,
+		now:          time.Now,
+	}
+}
+
+func (s *AlertDecisionService) Record(ctx context.Context, userID, alertID uuid.UUID, input AlertDecisionInput) (*entities.AlertDecision, error) {
+	alert, err := s.alertRepo.GetByID(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+	if alert == nil || alert.UserID != userID {
+		return nil, ErrAlertNotFound
+	}
+
+	if !IsValidDecisionAction(input.Action) {
+		return nil, ErrInvalidDecisionAction
+	}
+	confidence, err := parseDecisionConfidence(input.Confidence)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.decisionRepo.GetByAlert(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrAlertAlreadyDecided
+	}
+
+	now := s.now().UTC()
+	decision := &entities.AlertDecision{
+		ID:         uuid.New(),
+		AlertID:    alertID,
+		UserID:     userID,
+		Action:     entities.DecisionAction(input.Action),
+		Memo:       input.Memo,
+		Confidence: confidence,
+		ExecutedAt: &now,
+		CreatedAt:  now,
+	}
+
+	if err := s.decisionRepo.Create(ctx, decision); err != nil {
+		return nil, err
+	}
+	if err := s.alertRepo.UpdateStatus(ctx, alertID, entities.AlertStatusDecided); err != nil {
+		return nil, err
+	}
+
+	return decision, nil
+}
+
+// Annotate adds a memo and/or confidence to an existing decision.
+func (s *AlertDecisionService) Annotate(ctx context.Context, userID, alertID uuid.UUID, memo *string, confidence *string) (*entities.AlertDecision, error) {
+	decision, err := s.decisionRepo.GetByAlert(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+	if decision == nil || decision.UserID != userID {
+		return nil, ErrAlertDecisionNotFound
+	}
+
+	conf, err := parseDecisionConfidence(confidence)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.decisionRepo.UpdateNote(ctx, decision.ID, memo, conf); err != nil {
+		return nil, err
+	}
+
+	if memo != nil {
+		decision.Memo = memo
+	}
+	if conf != nil {
+		decision.Confidence = conf
+	}
+	return decision, nil
+}
+
+func IsValidDecisionAction(action string) bool {
+	switch entities.DecisionAction(action) {
+	case entities.DecisionBuy, entities.DecisionSell, entities.DecisionHold,
+		entities.DecisionClose, entities.DecisionReduce, entities.DecisionAdd, entities.DecisionIgnore:
+		return true
+	}
+	return false
+}
+
+func parseDecisionConfidence(raw *string) (*entities.Confidence, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	conf := entities.Confidence(*raw)
+	switch conf {
+	case entities.ConfidenceHigh, entities.ConfidenceMedium, entities.ConfidenceLow:
+		return &conf, nil
+	}
+	return nil, ErrInvalidDecisionConfidence
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/infrastructure/notification"
+)
+
+const (
+	MinSnoozeMinutes = 1
+	MaxSnoozeMinutes = 1440
+
+	// snoozeExpiryGrace keeps a snoozed alert open for a while after it is
+	// re-sent, so the reminder isn't expired before the user can act on it.
+	snoozeExpiryGrace = 30 * time.Minute
+	lifecycleBatch    = 100
+)
+
+var (
+	ErrInvalidSnoozeMinutes = fmt.Errorf("minutes must be between %d and %d", MinSnoozeMinutes, MaxSnoozeMinutes)
+	ErrAlertClosed          = errors.New("alert is already decided or expired")
+)
+
+// AlertEscalator re-sends an alert on channels that haven't carried it yet.
+type AlertEscalator interface {
+	notification.Sender
+	SendEscalation(ctx context.Context, userID uuid.UUID, msg notification.Message) ([]entities.ChannelType, error)
+}
+
+// AlertLifecycleService owns what happens to an alert after it is raised:
+// snooze, acknowledge, dismiss, expiry, re-notification and escalation.
+type AlertLifecycleService struct {
+	alertRepo  repositories.AlertRepository
+	eventRepo  repositories.AlertEventRepository
+	sender     AlertEscalator
+	appBaseURL string
+	now        func() time.Time
+}
+
+func NewAlertLifecycleService(
+	alertRepo repositories.AlertRepository,
+	eventRepo repositories.AlertEventRepository,
+	sender AlertEscalator,
+) *AlertLifecycleService {
+	appURL := os.Getenv("APP_BASE_URL")
+	if appURL == "" {
+		appURL = "http://localhost:5173"
+	}
+	return &AlertLifecycleService{
+		alertRepo:  alertRepo,
+		eventRepo:  eventRepo,
+		sender:     sender,
+		appBaseURL: appURL,
+		now:        time.Now,
+	}
+}
+
+// Snooze hides an open alert for minutes and re-notifies once the time is up.
+func (s *AlertLifecycleService) Snooze(ctx context.Context, userID, alertID uuid.UUID, minutes int) (*entities.Alert, error) {
+	if minutes < MinSnoozeMinutes || minutes > MaxSnoozeMinutes {
+		return nil, ErrInvalidSnoozeMinutes
+	}
+	alert, err := s.ownedAlert(ctx, userID, alertID)
+	if err != nil {
+		return nil, err
+	}
+
+	until := s.now().UTC().Add(time.Duration(minutes) * time.Minute)
+	expiresAt := alert.ExpiresAt
+	if floor := until.Add(snoozeExpiryGrace); expiresAt.Before(floor) {
+		expiresAt = floor
+	}
+	changed, err := s.alertRepo.Snooze(ctx, alertID, until, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, ErrAlertClosed
+	}
+	return s.alertRepo.GetByID(ctx, alertID)
+}
+
+// Acknowledge marks an open alert as seen, which stops escalation.
+func (s *AlertLifecycleService) Acknowledge(ctx context.Context, userID, alertID uuid.UUID) (*entities.Alert, error) {
+	if _, err := s.ownedAlert(ctx, userID, alertID); err != nil {
+		return nil, err
+	}
+	changed, err := s.alertRepo.Acknowledge(ctx, alertID, s.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, ErrAlertClosed
+	}
+	return s.alertRepo.GetByID(ctx, alertID)
+}
+
+func (s *AlertLifecycleService) Dismiss(ctx context.Context, userID, alertID uuid.UUID) error {
+	if _, err := s.ownedAlert(ctx, userID, alertID); err != nil {
+		return err
+	}
+	changed, err := s.alertRepo.Dismiss(ctx, alertID)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return ErrAlertClosed
+	}
+	return nil
+}
+
+func (s *AlertLifecycleService) Events(ctx context.Context, userID, alertID uuid.UUID) ([]*entities.AlertEvent, error) {
+	if _, err := s.ownedAlert(ctx, userID, alertID); err != nil {
+		return nil, err
+	}
+	events, err := s.eventRepo.ListByAlert(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		events = []*entities.AlertEvent{}
+	}
+	return events, nil
+}
+
+// ProcessDue expires alerts past their rule's window, re-notifies alerts
+// whose snooze has ended and escalates unacknowledged urgent alerts.
+func (s *AlertLifecycleService) ProcessDue(ctx context.Context) error {
+	now := s.now().UTC()
+
+	if expired, err := s.alertRepo.ExpireDue(ctx, now); err != nil {
+		return fmt.Errorf("expire: %w", err)
+	} else if expired > 0 {
+		log.Printf("alert lifecycle: expired %d alerts", expired)
+	}
+
+	snoozed, err := s.alertRepo.ClaimDueSnoozed(ctx, now, lifecycleBatch)
+	if err != nil {
+		return fmt.Errorf("claim snoozed: %w", err)
+	}
+	for _, alert := range snoozed {
+		if s.sender == nil {
+			break
+		}
+		msg := s.message(alert, "⏰ 다시 알림: ")
+		if err := s.sender.Send(ctx, alert.UserID, msg); err != nil {
+			log.Printf("alert lifecycle: re-notify %s failed: %v", alert.ID, err)
+		}
+	}
+
+	escalations, err := s.alertRepo.ClaimEscalations(ctx, now, lifecycleBatch)
+	if err != nil {
+		return fmt.Errorf("claim escalations: %w", err)
+	}
+	for _, alert := range escalations {
+		s.escalate(ctx, alert, now)
+	}
+	return nil
+}
+
+func (s *AlertLifecycleService) escalate(ctx context.Context, alert *entities.Alert, now time.Time) {
+	detail := map[string]interface{}{}
+	if s.sender != nil {
+		channels, err := s.sender.SendEscalation(ctx, alert.UserID, s.message(alert, "🚨 미확인 긴급 알림: "))
+		if err != nil {
+			log.Printf("alert lifecycle: escalate %s failed: %v", alert.ID, err)
+			detail["error"] = err.Error()
+		}
+		if channels == nil {
+			channels = []entities.ChannelType{}
+		}
+		detail["channels"] = channels
+		if err == nil && len(channels) == 0 {
+			detail["reason"] = "no other verified channel"
+		}
+	}
+	detailJSON, _ := json.Marshal(detail)
+
+	status := alert.Status
+	event := &entities.AlertEvent{
+		ID:         uuid.New(),
+		AlertID:    alert.ID,
+		UserID:     alert.UserID,
+		EventType:  entities.AlertEventEscalated,
+		FromStatus: &status,
+		ToStatus:   &status,
+		Detail:     detailJSON,
+		CreatedAt:  now,
+	}
+	if err := s.eventRepo.Create(ctx, event); err != nil {
+		log.Printf("alert lifecycle: record escalation for %s failed: %v", alert.ID, err)
+	}
+}
+
+func (s *AlertLifecycleService) message(alert *entities.Alert, prefix string) notification.Message {
+	return notification.Message{
+		Title:    prefix + alert.TriggerReason,
+		Body:     fmt.Sprintf("트리거 가격: $%s\n발생: %s", alert.TriggerPrice, alert.CreatedAt.UTC().Format("2006-01-02 15:04 UTC")),
+		Severity: string(alert.Severity),
+		DeepLink: fmt.Sprintf("%s/alerts/%s", s.appBaseURL, alert.ID.String()),
+		AlertID:  &alert.ID,
+	}
+}
+
+func (s *AlertLifecycleService) ownedAlert(ctx context.Context, userID, alertID uuid.UUID) (*entities.Alert, error) {
+	alert, err := s.alertRepo.GetByID(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+	if alert == nil || alert.UserID != userID {
+		return nil, ErrAlertNotFound
+	}
+	return alert, nil
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/infrastructure/notification"
+)
+
+type fakeLifecycleAlertRepo struct {
+	repositories.AlertRepository
+	alerts      map[uuid.UUID]*entities.Alert
+	escalations []*entities.Alert
+}
+
+func (r *fakeLifecycleAlertRepo) GetByID(_ context.Context, id uuid.UUID) (*entities.Alert, error) {
+	return r.alerts[id], nil
+}
+
+func (r *fakeLifecycleAlertRepo) Snooze(_ context.Context, id uuid.UUID, until, expiresAt time.Time) (bool, error) {
+	alert := r.alerts[id]
+	if !alert.Status.IsOpen() {
+		return false, nil
+	}
+	alert.Status = entities.AlertStatusSnoozed
+	alert.SnoozedUntil = &until
+	alert.ExpiresAt = expiresAt
+	return true, nil
+}
+
+func (r *fakeLifecycleAlertRepo) ExpireDue(context.Context, time.Time) (int, error) { return 0, nil }
+
+func (r *fakeLifecycleAlertRepo) ClaimDueSnoozed(context.Context, time.Time, int) ([]*entities.Alert, error) {
+	return nil, nil
+}
+
+func (r *fakeLifecycleAlertRepo) ClaimEscalations(context.Context, time.Time, int) ([]*entities.Alert, error) {
+	claimed := r.escalations
+	r.escalations = nil
+	return claimed, nil
+}
+
+type fakeAlertEventRepo struct {
+	events []*entities.AlertEvent
+}
+
+func (r *fakeAlertEventRepo) Create(_ context.Context, event *entities.AlertEvent) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *fakeAlertEventRepo) ListByAlert(context.Context, uuid.UUID) ([]*entities.AlertEvent, error) {
+	return r.events, nil
+}
+
+type fakeEscalator struct {
+	channels  []entities.ChannelType
+	escalated []notification.Message
+}
+
+func (e *fakeEscalator) Send(context.Context, uuid.UUID, notification.Message) error { return nil }
+
+func (e *fakeEscalator) SendEscalation(_ context.Context, _ uuid.UUID, msg notification.Message) ([]entities.ChannelType, error) {
+	e.escalated = append(e.escalated, msg)
+	return e.channels, nil
+}
+
+func TestAlertLifecycleSnoozeKeepsAlertOpenPastReminder(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+	alertID := uuid.New()
+	repo := &fakeLifecycleAlertRepo{alerts: map[uuid.UUID]*entities.Alert{
+		alertID: {ID: alertID, UserID: userID, Status: entities.AlertStatusBriefed, ExpiresAt: now.Add(10 * time.Minute)},
+	}}
+	svc := NewAlertLifecycleService(repo, &fakeAlertEventRepo{}, nil)
+	svc.now = func() time.Time { return now }
+
+	if _, err := svc.Snooze(context.Background(), userID, alertID, 0); !errors.Is(err, ErrInvalidSnoozeMinutes) {
+		t.Fatalf("expected invalid minutes, got %v", err)
+	}
+	if _, err := svc.Snooze(context.Background(), uuid.New(), alertID, 60); !errors.Is(err, ErrAlertNotFound) {
+		t.Fatalf("expected not found for another user, got %v", err)
+	}
+
+	alert, err := svc.Snooze(context.Background(), userID, alertID, 60)
+	if err != nil {
+		t.Fatalf("snooze: %v", err)
+	}
+	if alert.SnoozedUntil == nil || !alert.SnoozedUntil.Equal(now.Add(time.Hour)) {
+		t.Fatalf("unexpected snoozed_until: %v", alert.SnoozedUntil)
+	}
+	if want := now.Add(time.Hour + snoozeExpiryGrace); !alert.ExpiresAt.Equal(want) {
+		t.Fatalf("expected expiry pushed to %v, got %v", want, alert.ExpiresAt)
+	}
+
+	repo.alerts[alertID].Status = entities.AlertStatusDecided
+	if _, err := svc.Snooze(context.Background(), userID, alertID, 60); !errors.Is(err, ErrAlertClosed) {
+		t.Fatalf("expected closed alert, got %v", err)
+	}
+}
+
+func TestAlertLifecycleEscalationRecordsChannels(t *testing.T) {
+	t.Parallel()
+
+	alert := &entities.Alert{
+		ID:            uuid.New(),
+		UserID:        uuid.New(),
+		Status:        entities.AlertStatusBriefed,
+		Severity:      entities.AlertSeverityUrgent,
+		TriggerReason: "BTC 5% 급락",
+		TriggerPrice:  "60000",
+	}
+	repo := &fakeLifecycleAlertRepo{escalations: []*entities.Alert{alert}}
+	events := &fakeAlertEventRepo{}
+	escalator := &fakeEscalator{channels: []entities.ChannelType{entities.ChannelDiscord}}
+	svc := NewAlertLifecycleService(repo, events, escalator)
+
+	if err := svc.ProcessDue(context.Background()); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	if len(escalator.escalated) != 1 || escalator.escalated[0].AlertID == nil || *escalator.escalated[0].AlertID != alert.ID {
+		t.Fatalf("expected one escalation for the alert, got %+v", escalator.escalated)
+	}
+	if len(events.events) != 1 || events.events[0].EventType != entities.AlertEventEscalated {
+		t.Fatalf("expected one escalated event, got %+v", events.events)
+	}
+	var detail struct {
+		Channels []string `json:"channels"`
+	}
+	if err := json.Unmarshal(events.events[0].Detail, &detail); err != nil {
+		t.Fatalf("detail: %v", err)
+	}
+	if len(detail.Channels) != 1 || detail.Channels[0] != "discord" {
+		t.Fatalf("unexpected channels: %v", detail.Channels)
+	}
+}
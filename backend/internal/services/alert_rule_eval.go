@@ -0,0 +1,180 @@
+package services
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+// The evaluators below hold the trigger logic for each alert rule type. Both
+// AlertMonitor (live prices) and AlertBacktestService (stored candles) call
+// them, so a backtest fires exactly when the live rule would.
+
+// EvaluatePriceChange fires when current moved at least the configured
+// threshold away from the reference price in the configured direction.
+func EvaluatePriceChange(cfg entities.PriceChangeConfig, symbol, currentPrice, refPrice string) (bool, string, entities.AlertSeverity) {
+	threshold := parseDecimal(cfg.ThresholdValue)
+	cur := parseDecimal(currentPrice)
+	ref := parseDecimal(refPrice)
+	if threshold == nil || cur == nil || ref == nil || ref.Sign() == 0 {
+		return false, "", entities.AlertSeverityNormal
+	}
+
+	diff := new(big.Rat).Sub(cur, ref)
+	absDiff := new(big.Rat).Abs(diff)
+
+	limit := threshold
+	if cfg.ThresholdType == "percent" {
+		limit = new(big.Rat).Mul(ref, threshold)
+		limit.Quo(limit, big.NewRat(100, 1))
+		limit.Abs(limit)
+	}
+	if absDiff.Cmp(limit) < 0 {
+		return false, "", entities.AlertSeverityNormal
+	}
+
+	isDown := diff.Sign() < 0
+	if cfg.Direction == "drop" && !isDown {
+		return false, "", entities.AlertSeverityNormal
+	}
+	if cfg.Direction == "rise" && isDown {
+		return false, "", entities.AlertSeverityNormal
+	}
+
+	pctChange := new(big.Rat).Quo(diff, ref)
+	pctChange.Mul(pctChange, big.NewRat(100, 1))
+
+	direction := "상승"
+	if isDown {
+		direction = "하락"
+	}
+	reason := fmt.Sprintf("%s %s $%s (%s 대비 %s%%)",
+		symbol, direction, formatDecimal(absDiff, 2), cfg.Reference, formatDecimal(pctChange, 2))
+
+	severity := entities.AlertSeverityNormal
+	if new(big.Rat).Abs(pctChange).Cmp(big.NewRat(5, 1)) >= 0 {
+		severity = entities.AlertSeverityUrgent
+	}
+	return true, reason, severity
+}
+
+// EvaluatePriceLevel checks a price level rule. gte/lte fire whenever the
+// price is on that side; above/below fire only on a crossing relative to
+// wasAbove. isAbove is the state to remember for the next check and is nil
+// when the price could not be parsed.
+func EvaluatePriceLevel(cfg entities.PriceLevelConfig, symbol, currentPrice string, wasAbove *bool) (triggered bool, reason string, severity entities.AlertSeverity, isAbove *bool) {
+	severity = entities.AlertSeverityNormal
+	cur := parseDecimal(currentPrice)
+	target := parseDecimal(cfg.Price)
+	if cur == nil || target == nil {
+		return false, "", severity, nil
+	}
+	above := cur.Cmp(target) >= 0
+	isAbove = &above
+
+	switch cfg.Direction {
+	case "gte":
+		if !above {
+			return false, "", severity, isAbove
+		}
+		return true, fmt.Sprintf("%s $%s 이상 도달 (현재 $%s)", symbol, cfg.Price, currentPrice), severity, isAbove
+	case "lte":
+		if above {
+			return false, "", severity, isAbove
+		}
+		return true, fmt.Sprintf("%s $%s 이하 도달 (현재 $%s)", symbol, cfg.Price, currentPrice), severity, isAbove
+	}
+
+	if wasAbove == nil {
+		return false, "", severity, isAbove
+	}
+	if cfg.Direction == "above" && !*wasAbove && above {
+		return true, fmt.Sprintf("%s $%s 돌파 (현재 $%s)", symbol, cfg.Price, currentPrice), severity, isAbove
+	}
+	if cfg.Direction == "below" && *wasAbove && !above {
+		return true, fmt.Sprintf("%s $%s 이탈 (현재 $%s)", symbol, cfg.Price, currentPrice), severity, isAbove
+	}
+	return false, "", severity, isAbove
+}
+
+// EvaluateMACross fires when the price crosses the moving average in the
+// configured direction relative to wasAbove. isAbove is nil when either
+// value is missing.
+func EvaluateMACross(cfg entities.MACrossConfig, symbol, currentPrice string, ma *big.Rat, wasAbove *bool) (triggered bool, reason string, severity entities.AlertSeverity, isAbove *bool) {
+	severity = entities.AlertSeverityNormal
+	cur := parseDecimal(currentPrice)
+	if cur == nil || ma == nil {
+		return false, "", severity, nil
+	}
+	above := cur.Cmp(ma) >= 0
+	isAbove = &above
+	if wasAbove == nil {
+		return false, "", severity, isAbove
+	}
+
+	crossed := (cfg.Direction == "below" && *wasAbove && !above) ||
+		(cfg.Direction == "above" && !*wasAbove && above)
+	if !crossed {
+		return false, "", severity, isAbove
+	}
+	action := "하향 돌파"
+	if cfg.Direction == "above" {
+		action = "상향 돌파"
+	}
+	reason = fmt.Sprintf("%s %d일 이평선 %s (MA: $%s, 현재: $%s)",
+		symbol, cfg.MAPeriod, action, formatDecimal(ma, 2), currentPrice)
+	return true, reason, entities.AlertSeverityUrgent, isAbove
+}
+
+// EvaluateVolatilitySpike compares the range of the last bar in window with
+// the mean and variance of the preceding bars and fires when it exceeds the
+// mean by more than multiplier standard deviations.
+func EvaluateVolatilitySpike(window []*entities.Candle, multiplier *big.Rat, symbol, timeframe string) (bool, string, entities.AlertSeverity) {
+	if multiplier == nil {
+		return false, "", entities.AlertSeverityNormal
+	}
+	ranges := make([]*big.Rat, 0, len(window))
+	for _, candle := range window {
+		high := parseDecimal(candle.High)
+		low := parseDecimal(candle.Low)
+		if high == nil || low == nil {
+			return false, "", entities.AlertSeverityNormal
+		}
+		ranges = append(ranges, new(big.Rat).Sub(high, low))
+	}
+	if len(ranges) < 3 {
+		return false, "", entities.AlertSeverityNormal
+	}
+	historical := ranges[:len(ranges)-1]
+	latest := ranges[len(ranges)-1]
+
+	sum := new(big.Rat)
+	for _, r := range historical {
+		sum.Add(sum, r)
+	}
+	n := big.NewRat(int64(len(historical)), 1)
+	mean := new(big.Rat).Quo(sum, n)
+
+	varSum := new(big.Rat)
+	for _, r := range historical {
+		diff := new(big.Rat).Sub(r, mean)
+		varSum.Add(varSum, new(big.Rat).Mul(diff, diff))
+	}
+	variance := new(big.Rat).Quo(varSum, n)
+
+	// Compare squares to avoid a square root: (latest-mean)^2 >= m^2 * var.
+	excess := new(big.Rat).Sub(latest, mean)
+	if excess.Sign() <= 0 {
+		return false, "", entities.AlertSeverityNormal
+	}
+	excessSq := new(big.Rat).Mul(excess, excess)
+	threshold := new(big.Rat).Mul(new(big.Rat).Mul(multiplier, multiplier), variance)
+	if excessSq.Cmp(threshold) < 0 {
+		return false, "", entities.AlertSeverityNormal
+	}
+
+	reason := fmt.Sprintf("%s 변동성 급등 감지 (%s 기준, 현재 범위: $%s, 평균: $%s)",
+		symbol, timeframe, formatDecimal(latest, 2), formatDecimal(mean, 2))
+	return true, reason, entities.AlertSeverityUrgent
+}
@@ -0,0 +1,535 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+const (
+	// BehaviorScanWindow is how far back each scan re-detects episodes.
+	// Older episodes are left as stored.
+	BehaviorScanWindow = 14 * 24 * time.Hour
+
+	// Fills on the same symbol and side this close together are one order,
+	// the same bundling guided reviews use.
+	behaviorBundleGap = 90 * time.Second
+
+	behaviorRevengeWindow = 15 * time.Minute
+
+	behaviorLossStreak      = 2
+	behaviorSizeLookback    = 20
+	behaviorSizeMinSamples  = 5
+	behaviorBaselineDays    = 30
+	behaviorBaselineMinDays = 5
+	behaviorSpikeMinOrders  = 5
+
+	// Orders placed from midnight until this local hour are off-hours.
+	behaviorOffHoursEndHour = 6
+)
+
+var behaviorEscalationFactor = big.NewRat(3, 2)
+
+// behaviorPatternEmotions maps a detected pattern to the guided review
+// emotion a user would pick for it. Off-hours trading has no counterpart.
+var behaviorPatternEmotions = map[entities.BehaviorPattern]string{
+	entities.BehaviorRevengeReentry: entities.EmotionGRRevengeTrade,
+	entities.BehaviorSizeEscalation: entities.EmotionGRRevengeTrade,
+	entities.BehaviorOvertrading:    entities.EmotionGRFomo,
+}
+
+var behaviorComparedEmotions = []string{entities.EmotionGRRevengeTrade, entities.EmotionGRFomo}
+
+type BehaviorPatternService struct {
+	repo     repositories.BehaviorRepository
+	userRepo repositories.UserRepository
+	now      func() time.Time
+}
+
+func NewBehaviorPatternService(repo repositories.BehaviorRepository, userRepo repositories.UserRepository) *BehaviorPatternService {
+	return &BehaviorPatternService{repo: repo, userRepo: userRepo, now: time.Now}
+}
+
+type BehaviorReport struct {
+	From       time.Time                        `json:"from"`
+	To         time.Time                        `json:"to"`
+	Counts     map[entities.BehaviorPattern]int `json:"counts"`
+	Episodes   []*entities.BehaviorEpisode      `json:"episodes"`
+	SelfReport BehaviorSelfReport               `json:"self_report"`
+}
+
+// BehaviorSelfReport compares detected patterns with the emotions picked in
+// guided reviews. Matched counts items where a detection and the matching
+// emotion agree; Unacknowledged counts detections the user didn't report;
+// SelfReportedOnly counts emotions without a detection.
+type BehaviorSelfReport struct {
+	ReviewedItems    int                `json:"reviewed_items"`
+	Matched          int                `json:"matched"`
+	Unacknowledged   int                `json:"unacknowledged"`
+	SelfReportedOnly int                `json:"self_reported_only"`
+	Mismatches       []BehaviorMismatch `json:"mismatches"`
+}
+
+type BehaviorMismatch struct {
+	ItemID           uuid.UUID `json:"item_id"`
+	ReviewDate       string    `json:"review_date"`
+	Symbol           string    `json:"symbol"`
+	Emotion          string    `json:"emotion"`
+	DetectedPatterns []string  `json:"detected_patterns"`
+	Status           string    `json:"status"`
+}
+
+// ScanActive re-detects episodes for up to limit users who traded within the
+// scan window, least recently scanned first, and reports how many episodes it
+// stored. A failure for one user is logged and the rest still run.
+func (s *BehaviorPatternService) ScanActive(ctx context.Context, limit int) (int, error) {
+	userIDs, err := s.repo.ListActiveUsers(ctx, s.now().Add(-BehaviorScanWindow), limit)
+	if err != nil {
+		return 0, err
+	}
+	stored := 0
+	for _, userID := range userIDs {
+		count, err := s.ScanUser(ctx, userID)
+		if err != nil {
+			log.Printf("behavior patterns: user %s: %v", userID, err)
+			continue
+		}
+		stored += count
+	}
+	return stored, nil
+}
+
+// ScanUser detects episodes started within the scan window. Fills from the
+// preceding baseline period are read too, so sizes and daily counts have
+// something to be compared against.
+func (s *BehaviorPatternService) ScanUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	now := s.now().UTC()
+	from := now.Add(-BehaviorScanWindow)
+	fills, err := s.repo.ListFills(ctx, userID, from.AddDate(0, 0, -behaviorBaselineDays-1), now)
+	if err != nil {
+		return 0, err
+	}
+
+	day := entities.NewTradingDay("UTC", 0)
+	if s.userRepo != nil {
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return 0, err
+		}
+		if user != nil {
+			day = user.TradingDay()
+		}
+	}
+
+	episodes := DetectBehaviorEpisodes(fills, day, from)
+	for _, episode := range episodes {
+		episode.ID = uuid.New()
+		episode.UserID = userID
+		episode.DetectedAt = now
+	}
+	if err := s.repo.ReplaceEpisodes(ctx, userID, from, episodes, now); err != nil {
+		return 0, err
+	}
+	return len(episodes), nil
+}
+
+func (s *BehaviorPatternService) Report(ctx context.Context, userID uuid.UUID, from, to time.Time) (*BehaviorReport, error) {
+	episodes, err := s.repo.ListEpisodes(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	signals, err := s.repo.ListReviewSignals(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[entities.BehaviorPattern]int)
+	for _, episode := range episodes {
+		counts[episode.Pattern]++
+	}
+	if episodes == nil {
+		episodes = []*entities.BehaviorEpisode{}
+	}
+	return &BehaviorReport{
+		From:       from,
+		To:         to,
+		Counts:     counts,
+		Episodes:   episodes,
+		SelfReport: CompareBehaviorSelfReport(signals),
+	}, nil
+}
+
+// CompareBehaviorSelfReport checks each answered review item for the
+// emotions that have a detectable counterpart.
+func CompareBehaviorSelfReport(signals []*repositories.BehaviorReviewSignal) BehaviorSelfReport {
+	report := BehaviorSelfReport{Mismatches: []BehaviorMismatch{}}
+	for _, signal := range signals {
+		report.ReviewedItems++
+
+		var emotions []string
+		if len(signal.Emotions) > 0 {
+			_ = json.Unmarshal(signal.Emotions, &emotions)
+		}
+		reported := make(map[string]bool, len(emotions))
+		for _, emotion := range emotions {
+			reported[emotion] = true
+		}
+		expected := make(map[string]bool)
+		for _, pattern := range signal.Patterns {
+			if emotion, ok := behaviorPatternEmotions[entities.BehaviorPattern(pattern)]; ok {
+				expected[emotion] = true
+			}
+		}
+
+		for _, emotion := range behaviorComparedEmotions {
+			status := ""
+			switch {
+			case expected[emotion] && reported[emotion]:
+				report.Matched++
+			case expected[emotion]:
+				report.Unacknowledged++
+				status = "unacknowledged"
+			case reported[emotion]:
+				report.SelfReportedOnly++
+				status = "self_reported_only"
+			}
+			if status == "" {
+				continue
+			}
+			patterns := signal.Patterns
+			if patterns == nil {
+				patterns = []string{}
+			}
+			report.Mismatches = append(report.Mismatches, BehaviorMismatch{
+				ItemID:           signal.ItemID,
+				ReviewDate:       signal.ReviewDate,
+				Symbol:           signal.Symbol,
+				Emotion:          emotion,
+				DetectedPatterns: patterns,
+				Status:           status,
+			})
+		}
+	}
+	return report
+}
+
+// behaviorOrder is a bundle of fills on one symbol and side. pnl is nil when
+// none of its fills reported realized pnl; an order without a loss or gain
+// counts as an entry. imported orders come from trade_events, which carry no
+// pnl, so closes can't be told from entries.
+type behaviorOrder struct {
+	imported bool
+	symbol   string
+	side     string
+	first    time.Time
+	last     time.Time
+	fills    int
+	notional *big.Rat
+	pnl      *big.Rat
+}
+
+func (o *behaviorOrder) isEntry() bool { return o.pnl == nil || o.pnl.Sign() == 0 }
+func (o *behaviorOrder) isLoss() bool  { return o.pnl != nil && o.pnl.Sign() < 0 }
+func (o *behaviorOrder) isWin() bool   { return o.pnl != nil && o.pnl.Sign() > 0 }
+
+// DetectBehaviorEpisodes finds revenge re-entries, size escalation after
+// losses, daily overtrading spikes and off-hours trading in fills ordered by
+// execution time. Revenge and size escalation need realized pnl to find
+// losses, so they only look at synced trades; imported trade events count
+// toward overtrading and off-hours only. Only episodes starting at or after from are returned;
+// earlier fills serve as the baseline. Daily counts and trade dates follow
+// the user's trading day like guided reviews; off-hours uses the local clock
+// in its timezone.
+func DetectBehaviorEpisodes(fills []*repositories.BehaviorFill, day entities.TradingDay, from time.Time) []*entities.BehaviorEpisode {
+	orders := bundleBehaviorFills(fills)
+
+	var episodes []*entities.BehaviorEpisode
+	var synced []*behaviorOrder
+	for _, order := range orders {
+		if !order.imported {
+			synced = append(synced, order)
+		}
+	}
+	episodes = append(episodes, detectRevengeReentries(synced)...)
+	episodes = append(episodes, detectSizeEscalations(synced)...)
+	episodes = append(episodes, detectOvertrading(orders, day, from)...)
+	episodes = append(episodes, detectOffHours(orders, day.Location)...)
+
+	kept := episodes[:0]
+	for _, episode := range episodes {
+		if !episode.StartedAt.Before(from) {
+			episode.TradeDate = day.Date(episode.StartedAt)
+			kept = append(kept, episode)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].StartedAt.Before(kept[j].StartedAt) })
+	return kept
+}
+
+func bundleBehaviorFills(fills []*repositories.BehaviorFill) []*behaviorOrder {
+	var orders []*behaviorOrder
+	open := make(map[string]*behaviorOrder)
+	for _, fill := range fills {
+		qty := parseDecimal(fill.Qty)
+		price := parseDecimal(fill.Price)
+		if qty == nil || price == nil || fill.SymbolKey == "" {
+			continue
+		}
+		notional := new(big.Rat).Mul(new(big.Rat).Abs(qty), price)
+		at := fill.ExecutedAt.UTC()
+
+		imported := fill.Source == "trade_event"
+		key := fill.Source + ":" + fill.SymbolKey + ":" + fill.Side
+		order := open[key]
+		if order == nil || at.Sub(order.last) > behaviorBundleGap {
+			order = &behaviorOrder{imported: imported, symbol: fill.SymbolKey, side: fill.Side, first: at, notional: new(big.Rat)}
+			open[key] = order
+			orders = append(orders, order)
+		}
+		order.last = at
+		order.fills++
+		order.notional.Add(order.notional, notional)
+		if fill.RealizedPnL != nil {
+			if pnl := parseDecimal(*fill.RealizedPnL); pnl != nil {
+				if order.pnl == nil {
+					order.pnl = new(big.Rat)
+				}
+				order.pnl.Add(order.pnl, pnl)
+			}
+		}
+	}
+	return orders
+}
+
+// detectRevengeReentries flags the first entry on any symbol within the
+// revenge window after a losing close.
+func detectRevengeReentries(orders []*behaviorOrder) []*entities.BehaviorEpisode {
+	var episodes []*entities.BehaviorEpisode
+	for i, loss := range orders {
+		if !loss.isLoss() {
+			continue
+		}
+		for _, next := range orders[i+1:] {
+			gap := next.first.Sub(loss.last)
+			if gap > behaviorRevengeWindow {
+				break
+			}
+			if gap < 0 || !next.isEntry() {
+				continue
+			}
+			severity := entities.BehaviorSeverityLow
+			switch {
+			case gap <= 3*time.Minute:
+				severity = entities.BehaviorSeverityHigh
+			case gap <= 10*time.Minute:
+				severity = entities.BehaviorSeverityMedium
+			}
+			episodes = append(episodes, newBehaviorEpisode(entities.BehaviorRevengeReentry, next.symbol, severity,
+				loss.last, next.first, 2, map[string]any{
+					"loss_symbol": loss.symbol,
+					"loss_pnl":    formatDecimal(loss.pnl, 8),
+					"gap_minutes": formatDecimal(big.NewRat(int64(gap/time.Second), 60), 1),
+					"same_symbol": loss.symbol == next.symbol,
+				}))
+			break
+		}
+	}
+	return episodes
+}
+
+// detectSizeEscalations flags entries at least 1.5x the average recent entry
+// size while the user is on a losing streak. A winning close ends the streak.
+func detectSizeEscalations(orders []*behaviorOrder) []*entities.BehaviorEpisode {
+	var episodes []*entities.BehaviorEpisode
+	var entrySizes []*big.Rat
+	streak := 0
+	var streakStart time.Time
+	for _, order := range orders {
+		switch {
+		case order.isLoss():
+			if streak == 0 {
+				streakStart = order.last
+			}
+			streak++
+		case order.isWin():
+			streak = 0
+		default:
+			if streak >= behaviorLossStreak && len(entrySizes) >= behaviorSizeMinSamples {
+				baseline := averageRat(sumRats(entrySizes), len(entrySizes))
+				if baseline.Sign() > 0 {
+					ratio := new(big.Rat).Quo(order.notional, baseline)
+					if ratio.Cmp(behaviorEscalationFactor) >= 0 {
+						severity := entities.BehaviorSeverityLow
+						switch {
+						case ratio.Cmp(big.NewRat(3, 1)) >= 0:
+							severity = entities.BehaviorSeverityHigh
+						case ratio.Cmp(big.NewRat(2, 1)) >= 0:
+							severity = entities.BehaviorSeverityMedium
+						}
+						episodes = append(episodes, newBehaviorEpisode(entities.BehaviorSizeEscalation, order.symbol, severity,
+							streakStart, order.first, streak+1, map[string]any{
+								"loss_streak":       streak,
+								"notional":          formatDecimal(order.notional, 8),
+								"baseline_notional": formatDecimal(baseline, 8),
+								"ratio":             formatDecimal(ratio, 2),
+							}))
+					}
+				}
+			}
+			entrySizes = append(entrySizes, order.notional)
+			if len(entrySizes) > behaviorSizeLookback {
+				entrySizes = entrySizes[1:]
+			}
+		}
+	}
+	return episodes
+}
+
+// detectOvertrading flags UTC days with at least twice the usual number of
+// orders and two standard deviations above it, measured over the active days
+// of the preceding 30.
+func detectOvertrading(orders []*behaviorOrder, tradingDay entities.TradingDay, from time.Time) []*entities.BehaviorEpisode {
+	type day struct {
+		count       int
+		first, last time.Time
+	}
+	days := make(map[string]*day)
+	var keys []string
+	for _, order := range orders {
+		key := tradingDay.Date(order.first)
+		d := days[key]
+		if d == nil {
+			d = &day{first: order.first}
+			days[key] = d
+			keys = append(keys, key)
+		}
+		d.count++
+		d.last = order.first
+	}
+	sort.Strings(keys)
+
+	fromDay := tradingDay.Date(from)
+	var episodes []*entities.BehaviorEpisode
+	for _, key := range keys {
+		if key < fromDay {
+			continue
+		}
+		current := days[key]
+		if current.count < behaviorSpikeMinOrders {
+			continue
+		}
+		date, _ := time.Parse("2006-01-02", key)
+		windowStart := date.AddDate(0, 0, -behaviorBaselineDays).Format("2006-01-02")
+
+		var counts []float64
+		for _, other := range keys {
+			if other >= windowStart && other < key {
+				counts = append(counts, float64(days[other].count))
+			}
+		}
+		if len(counts) < behaviorBaselineMinDays {
+			continue
+		}
+		mean, std := meanStd(counts)
+		count := float64(current.count)
+		if count < 2*mean || count < mean+2*std {
+			continue
+		}
+		ratio := count / mean
+		severity := entities.BehaviorSeverityLow
+		switch {
+		case ratio >= 4:
+			severity = entities.BehaviorSeverityHigh
+		case ratio >= 3:
+			severity = entities.BehaviorSeverityMedium
+		}
+		episodes = append(episodes, newBehaviorEpisode(entities.BehaviorOvertrading, "", severity,
+			current.first, current.last, current.count, map[string]any{
+				"baseline_mean": math.Round(mean*100) / 100,
+				"baseline_std":  math.Round(std*100) / 100,
+				"baseline_days": len(counts),
+				"ratio":         math.Round(ratio*100) / 100,
+			}))
+	}
+	return episodes
+}
+
+// detectOffHours groups orders placed between local midnight and 6am by
+// local date and symbol.
+func detectOffHours(orders []*behaviorOrder, loc *time.Location) []*entities.BehaviorEpisode {
+	if loc == nil {
+		loc = time.UTC
+	}
+	type group struct {
+		symbol      string
+		localDate   string
+		count       int
+		first, last time.Time
+	}
+	groups := make(map[string]*group)
+	var keys []string
+	for _, order := range orders {
+		local := order.first.In(loc)
+		if local.Hour() >= behaviorOffHoursEndHour {
+			continue
+		}
+		localDate := local.Format("2006-01-02")
+		key := localDate + ":" + order.symbol
+		g := groups[key]
+		if g == nil {
+			g = &group{symbol: order.symbol, localDate: localDate, first: order.first}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.count++
+		g.last = order.first
+	}
+
+	var episodes []*entities.BehaviorEpisode
+	for _, key := range keys {
+		g := groups[key]
+		severity := entities.BehaviorSeverityLow
+		switch {
+		case g.count >= 5:
+			severity = entities.BehaviorSeverityHigh
+		case g.count >= 3:
+			severity = entities.BehaviorSeverityMedium
+		}
+		episodes = append(episodes, newBehaviorEpisode(entities.BehaviorOffHours, g.symbol, severity,
+			g.first, g.last, g.count, map[string]any{
+				"timezone":    loc.String(),
+				"local_date":  g.localDate,
+				"local_start": g.first.In(loc).Format("15:04"),
+			}))
+	}
+	return episodes
+}
+
+func newBehaviorEpisode(pattern entities.BehaviorPattern, symbol string, severity entities.BehaviorSeverity, started, ended time.Time, count int, details map[string]any) *entities.BehaviorEpisode {
+	raw, _ := json.Marshal(details)
+	return &entities.BehaviorEpisode{
+		Pattern:    pattern,
+		Symbol:     symbol,
+		Severity:   severity,
+		StartedAt:  started,
+		EndedAt:    ended,
+		TradeCount: count,
+		Details:    raw,
+	}
+}
+
+func sumRats(values []*big.Rat) *big.Rat {
+	sum := new(big.Rat)
+	for _, value := range values {
+		sum.Add(sum, value)
+	}
+	return sum
+}
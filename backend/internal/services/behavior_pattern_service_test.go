@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+func behaviorFill(symbol, side, qty, price string, pnl *string, at time.Time) *repositories.BehaviorFill {
+	return &repositories.BehaviorFill{Source: "trade", ID: uuid.New(), SymbolKey: symbol, Side: side, Qty: qty, Price: price, RealizedPnL: pnl, ExecutedAt: at}
+}
+
+var utcTradingDay = entities.NewTradingDay("UTC", 0)
+
+func episodesByPattern(episodes []*entities.BehaviorEpisode, pattern entities.BehaviorPattern) []*entities.BehaviorEpisode {
+	var out []*entities.BehaviorEpisode
+	for _, episode := range episodes {
+		if episode.Pattern == pattern {
+			out = append(out, episode)
+		}
+	}
+	return out
+}
+
+func TestDetectBehaviorEpisodesRevengeAndEscalation(t *testing.T) {
+	base := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	var fills []*repositories.BehaviorFill
+	// Five ordinary entries an hour apart set the size baseline at 100.
+	for i := 0; i < 5; i++ {
+		fills = append(fills, behaviorFill("BTCUSDT", "buy", "1", "100", strPtr("0"), base.Add(time.Duration(i)*time.Hour)))
+	}
+	// Two losing closes, then a 3x entry two minutes after the second one.
+	fills = append(fills,
+		behaviorFill("BTCUSDT", "sell", "1", "90", strPtr("-10"), base.Add(6*time.Hour)),
+		behaviorFill("BTCUSDT", "sell", "1", "80", strPtr("-20"), base.Add(7*time.Hour)),
+		behaviorFill("ETHUSDT", "buy", "3", "100", strPtr("0"), base.Add(7*time.Hour+2*time.Minute)),
+	)
+
+	episodes := DetectBehaviorEpisodes(fills, utcTradingDay, base)
+
+	revenge := episodesByPattern(episodes, entities.BehaviorRevengeReentry)
+	if len(revenge) != 1 {
+		t.Fatalf("expected one revenge re-entry, got %d", len(revenge))
+	}
+	if revenge[0].Symbol != "ETHUSDT" || revenge[0].Severity != entities.BehaviorSeverityHigh {
+		t.Fatalf("unexpected revenge episode: %s %s", revenge[0].Symbol, revenge[0].Severity)
+	}
+
+	escalation := episodesByPattern(episodes, entities.BehaviorSizeEscalation)
+	if len(escalation) != 1 {
+		t.Fatalf("expected one size escalation, got %d", len(escalation))
+	}
+	var details map[string]any
+	if err := json.Unmarshal(escalation[0].Details, &details); err != nil {
+		t.Fatalf("details: %v", err)
+	}
+	if details["ratio"] != "3" || details["baseline_notional"] != "100" {
+		t.Fatalf("unexpected escalation details: %v", details)
+	}
+	if !escalation[0].StartedAt.Equal(base.Add(6*time.Hour)) || escalation[0].TradeCount != 3 {
+		t.Fatalf("expected escalation from the first loss over 3 orders, got %s/%d", escalation[0].StartedAt, escalation[0].TradeCount)
+	}
+}
+
+func TestDetectBehaviorEpisodesSkipsImportedForLossPatterns(t *testing.T) {
+	base := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	var fills []*repositories.BehaviorFill
+	for i := 0; i < 5; i++ {
+		fills = append(fills, behaviorFill("BTCUSDT", "buy", "1", "100", strPtr("0"), base.Add(time.Duration(i)*time.Hour)))
+	}
+	fills = append(fills,
+		behaviorFill("BTCUSDT", "sell", "1", "90", strPtr("-10"), base.Add(6*time.Hour)),
+		behaviorFill("BTCUSDT", "sell", "1", "80", strPtr("-20"), base.Add(7*time.Hour)),
+	)
+	// An imported sell right after the losses has no pnl and would otherwise
+	// read as a 3x entry.
+	imported := behaviorFill("ETHUSDT", "sell", "3", "100", nil, base.Add(7*time.Hour+2*time.Minute))
+	imported.Source = "trade_event"
+	fills = append(fills, imported)
+
+	episodes := DetectBehaviorEpisodes(fills, utcTradingDay, base)
+	if n := len(episodesByPattern(episodes, entities.BehaviorRevengeReentry)); n != 0 {
+		t.Fatalf("expected no revenge re-entry from an imported fill, got %d", n)
+	}
+	if n := len(episodesByPattern(episodes, entities.BehaviorSizeEscalation)); n != 0 {
+		t.Fatalf("expected no size escalation from an imported fill, got %d", n)
+	}
+}
+
+type fakeBehaviorRepo struct {
+	repositories.BehaviorRepository
+	users    []uuid.UUID
+	failFor  uuid.UUID
+	replaced []uuid.UUID
+}
+
+func (f *fakeBehaviorRepo) ListActiveUsers(context.Context, time.Time, int) ([]uuid.UUID, error) {
+	return f.users, nil
+}
+
+func (f *fakeBehaviorRepo) ListFills(_ context.Context, userID uuid.UUID, _, _ time.Time) ([]*repositories.BehaviorFill, error) {
+	if userID == f.failFor {
+		return nil, errors.New("query canceled")
+	}
+	return nil, nil
+}
+
+func (f *fakeBehaviorRepo) ReplaceEpisodes(_ context.Context, userID uuid.UUID, _ time.Time, _ []*entities.BehaviorEpisode, _ time.Time) error {
+	f.replaced = append(f.replaced, userID)
+	return nil
+}
+
+func TestScanActiveContinuesAfterUserError(t *testing.T) {
+	failing, next := uuid.New(), uuid.New()
+	repo := &fakeBehaviorRepo{users: []uuid.UUID{failing, next}, failFor: failing}
+	svc := NewBehaviorPatternService(repo, nil)
+
+	if _, err := svc.ScanActive(context.Background(), 10); err != nil {
+		t.Fatalf("expected per-user errors to be logged, got %v", err)
+	}
+	if len(repo.replaced) != 1 || repo.replaced[0] != next {
+		t.Fatalf("expected the second user to be scanned, got %v", repo.replaced)
+	}
+}
+
+func TestDetectBehaviorEpisodesBundlesFillsAndIgnoresWins(t *testing.T) {
+	base := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	fills := []*repositories.BehaviorFill{
+		// Losing close split over two fills, followed by a winning close.
+		behaviorFill("BTCUSDT", "sell", "1", "90", strPtr("-5"), base),
+		behaviorFill("BTCUSDT", "sell", "1", "90", strPtr("-5"), base.Add(30*time.Second)),
+		behaviorFill("ETHUSDT", "sell", "1", "110", strPtr("10"), base.Add(5*time.Minute)),
+		// Re-entry after the window has passed.
+		behaviorFill("BTCUSDT", "buy", "1", "90", nil, base.Add(20*time.Minute)),
+	}
+
+	episodes := DetectBehaviorEpisodes(fills, utcTradingDay, base)
+	if len(episodesByPattern(episodes, entities.BehaviorRevengeReentry)) != 0 {
+		t.Fatalf("expected no revenge re-entry outside the window")
+	}
+	if len(episodesByPattern(episodes, entities.BehaviorSizeEscalation)) != 0 {
+		t.Fatalf("expected no escalation without a baseline")
+	}
+}
+
+func TestDetectBehaviorEpisodesOvertrading(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	var fills []*repositories.BehaviorFill
+	// Two orders a day for ten days, then twelve on the eleventh.
+	for day := 0; day < 10; day++ {
+		at := start.AddDate(0, 0, day)
+		fills = append(fills,
+			behaviorFill("BTCUSDT", "buy", "1", "100", nil, at),
+			behaviorFill("BTCUSDT", "sell", "1", "100", nil, at.Add(time.Hour)),
+		)
+	}
+	spike := start.AddDate(0, 0, 10)
+	for i := 0; i < 12; i++ {
+		fills = append(fills, behaviorFill("BTCUSDT", "buy", "1", "100", nil, spike.Add(time.Duration(i)*5*time.Minute)))
+	}
+
+	episodes := DetectBehaviorEpisodes(fills, utcTradingDay, start.AddDate(0, 0, 5))
+	overtrading := episodesByPattern(episodes, entities.BehaviorOvertrading)
+	if len(overtrading) != 1 {
+		t.Fatalf("expected one overtrading day, got %d", len(overtrading))
+	}
+	if overtrading[0].TradeDate != "2026-03-11" || overtrading[0].TradeCount != 12 || overtrading[0].Symbol != "" {
+		t.Fatalf("unexpected overtrading episode: %s %d %q", overtrading[0].TradeDate, overtrading[0].TradeCount, overtrading[0].Symbol)
+	}
+	if overtrading[0].Severity != entities.BehaviorSeverityHigh {
+		t.Fatalf("expected high severity at 6x baseline, got %s", overtrading[0].Severity)
+	}
+}
+
+func TestDetectBehaviorEpisodesOffHoursUsesTimezone(t *testing.T) {
+	seoul, err := time.LoadLocation("Asia/Seoul")
+	if err != nil {
+		t.Skip("timezone data unavailable")
+	}
+	// 17:30 UTC is 02:30 in Seoul; 09:00 UTC is 18:00 there.
+	fills := []*repositories.BehaviorFill{
+		behaviorFill("BTCKRW", "buy", "1", "100", nil, time.Date(2026, 3, 2, 17, 30, 0, 0, time.UTC)),
+		behaviorFill("BTCKRW", "sell", "1", "100", nil, time.Date(2026, 3, 2, 17, 45, 0, 0, time.UTC)),
+		behaviorFill("BTCKRW", "buy", "1", "100", nil, time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC)),
+	}
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	offHours := episodesByPattern(DetectBehaviorEpisodes(fills, entities.TradingDay{Location: seoul}, from), entities.BehaviorOffHours)
+	if len(offHours) != 1 || offHours[0].TradeCount != 2 {
+		t.Fatalf("expected one off-hours episode with 2 orders, got %d", len(offHours))
+	}
+	if len(episodesByPattern(DetectBehaviorEpisodes(fills, utcTradingDay, from), entities.BehaviorOffHours)) != 0 {
+		t.Fatalf("expected no off-hours episode in UTC")
+	}
+}
+
+func TestDetectBehaviorEpisodesUsesTradingDayCutoff(t *testing.T) {
+	seoul, err := time.LoadLocation("Asia/Seoul")
+	if err != nil {
+		t.Skip("timezone data unavailable")
+	}
+	// 17:30 UTC on the 2nd is 02:30 KST on the 3rd, which still belongs to
+	// the 2nd's trading day with a 09:00 cutoff.
+	fills := []*repositories.BehaviorFill{
+		behaviorFill("BTCKRW", "buy", "1", "100", nil, time.Date(2026, 3, 2, 17, 30, 0, 0, time.UTC)),
+	}
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	episodes := DetectBehaviorEpisodes(fills, entities.TradingDay{Location: seoul, CutoffHour: 9}, from)
+	offHours := episodesByPattern(episodes, entities.BehaviorOffHours)
+	if len(offHours) != 1 || offHours[0].TradeDate != "2026-03-02" {
+		t.Fatalf("expected the 02:30 KST episode on the 2nd's trading day, got %+v", offHours)
+	}
+}
+
+func TestCompareBehaviorSelfReport(t *testing.T) {
+	signals := []*repositories.BehaviorReviewSignal{
+		{ItemID: uuid.New(), Symbol: "BTCUSDT", Emotions: json.RawMessage(`["revenge_trade"]`), Patterns: []string{"revenge_reentry"}},
+		{ItemID: uuid.New(), Symbol: "ETHUSDT", Emotions: json.RawMessage(`["calm"]`), Patterns: []string{"overtrading", "off_hours"}},
+		{ItemID: uuid.New(), Symbol: "XRPUSDT", Emotions: json.RawMessage(`["fomo"]`)},
+	}
+
+	report := CompareBehaviorSelfReport(signals)
+	if report.ReviewedItems != 3 || report.Matched != 1 || report.Unacknowledged != 1 || report.SelfReportedOnly != 1 {
+		t.Fatalf("unexpected comparison: %+v", report)
+	}
+	if len(report.Mismatches) != 2 || report.Mismatches[0].Emotion != entities.EmotionGRFomo || report.Mismatches[0].Status != "unacknowledged" {
+		t.Fatalf("unexpected mismatches: %+v", report.Mismatches)
+	}
+}
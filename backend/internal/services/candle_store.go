@@ -0,0 +1,301 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+const (
+	candleFetchPageSize = 1000
+	candleMaxFetchPages = 200
+	candleOpenBarTTL    = 30 * time.Second
+	// candleEmptySettle is how long after a bar closes the venue gets to
+	// publish it before a missing bar is recorded as known-empty.
+	candleEmptySettle = 10 * time.Minute
+)
+
+var (
+	ErrUnsupportedCandleInterval = errors.New("unsupported candle interval")
+	ErrUnsupportedCandleVenue    = errors.New("unsupported candle venue")
+)
+
+// candleVenuePageSizes overrides candleFetchPageSize for venues that serve
+// fewer bars per request. An empty page only vouches for the bars it covered.
+var candleVenuePageSizes = map[string]int{
+	entities.CandleVenueUpbit: 200,
+}
+
+var candleIntervals = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"1h":  time.Hour,
+	"4h":  4 * time.Hour,
+	"1d":  24 * time.Hour,
+}
+
+// CandleRateLimitError is returned by a CandleFetcher when the upstream venue
+// asks us to back off. RetryAfter carries the raw Retry-After header value.
+type CandleRateLimitError struct {
+	Venue      string
+	RetryAfter string
+}
+
+func (e *CandleRateLimitError) Error() string {
+	return fmt.Sprintf("%s candles rate limited", e.Venue)
+}
+
+// CandleFetcher pulls candles from an exchange. Implementations return candles
+// with open time in [start, end], ascending, at most limit items.
+type CandleFetcher interface {
+	FetchCandles(ctx context.Context, venue, symbol, interval string, start, end time.Time, limit int) ([]*entities.Candle, error)
+}
+
+// CandleStore serves candles from Postgres and backfills missing or stale
+// bars from the exchange on demand, so every feature reads the same history.
+type CandleStore struct {
+	repo    repositories.CandleRepository
+	fetcher CandleFetcher
+	now     func() time.Time
+}
+
+func NewCandleStore(repo repositories.CandleRepository, fetcher CandleFetcher) *CandleStore {
+	return &CandleStore{
+		repo:    repo,
+		fetcher: fetcher,
+		now:     time.Now,
+	}
+}
+
+// CandleIntervalDuration returns the bar size for a supported interval.
+func CandleIntervalDuration(interval string) (time.Duration, bool) {
+	d, ok := candleIntervals[interval]
+	return d, ok
+}
+
+// Range returns candles whose open time falls in [start, end].
+func (s *CandleStore) Range(ctx context.Context, venue, symbol, interval string, start, end time.Time) ([]*entities.Candle, error) {
+	venue, symbol, interval, err := normalizeCandleKey(venue, symbol, interval)
+	if err != nil {
+		return nil, err
+	}
+	step := candleIntervals[interval]
+
+	now := s.now().UTC()
+	start = start.UTC().Truncate(step)
+	end = end.UTC().Truncate(step)
+	if current := now.Truncate(step); end.After(current) {
+		end = current
+	}
+	if start.After(end) {
+		return []*entities.Candle{}, nil
+	}
+
+	stored, err := s.repo.ListRange(ctx, venue, symbol, interval, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	empty, err := s.repo.ListEmpty(ctx, venue, symbol, interval, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	gaps := findCandleGaps(stored, empty, start, end, step, now)
+	if len(gaps) == 0 {
+		return stored, nil
+	}
+
+	for _, gap := range gaps {
+		if err := s.backfill(ctx, venue, symbol, interval, gap[0], gap[1], step); err != nil {
+			var rateErr *CandleRateLimitError
+			if errors.As(err, &rateErr) || len(stored) == 0 {
+				return nil, err
+			}
+			// Serve what we already have rather than failing the whole read.
+			log.Printf("candle store: backfill %s %s %s failed: %v", venue, symbol, interval, err)
+			return stored, nil
+		}
+	}
+
+	return s.repo.ListRange(ctx, venue, symbol, interval, start, end)
+}
+
+// Latest returns up to limit candles ending at end (or now when end is zero).
+func (s *CandleStore) Latest(ctx context.Context, venue, symbol, interval string, end time.Time, limit int) ([]*entities.Candle, error) {
+	step, ok := candleIntervals[strings.ToLower(strings.TrimSpace(interval))]
+	if !ok {
+		return nil, ErrUnsupportedCandleInterval
+	}
+	if limit <= 0 {
+		return []*entities.Candle{}, nil
+	}
+	if end.IsZero() {
+		end = s.now()
+	}
+	end = end.UTC().Truncate(step)
+	start := end.Add(-time.Duration(limit-1) * step)
+
+	candles, err := s.Range(ctx, venue, symbol, interval, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if len(candles) > limit {
+		candles = candles[len(candles)-limit:]
+	}
+	return candles, nil
+}
+
+// CloseAt returns the close of the most recent bar at or before target,
+// looking back at most five bars.
+func (s *CandleStore) CloseAt(ctx context.Context, venue, symbol, interval string, target time.Time) (string, bool, error) {
+	candles, err := s.Latest(ctx, venue, symbol, interval, target, 5)
+	if err != nil {
+		return "", false, err
+	}
+	if len(candles) == 0 {
+		return "", false, nil
+	}
+	return candles[len(candles)-1].Close, true, nil
+}
+
+func (s *CandleStore) backfill(ctx context.Context, venue, symbol, interval string, start, end time.Time, step time.Duration) error {
+	pageSize, ok := candleVenuePageSizes[venue]
+	if !ok {
+		pageSize = candleFetchPageSize
+	}
+	returned := make(map[int64]struct{})
+	scannedTo := end
+	cursor := start
+	for page := 0; !cursor.After(end); page++ {
+		if page == candleMaxFetchPages {
+			scannedTo = cursor.Add(-step)
+			break
+		}
+		fetched, err := s.fetcher.FetchCandles(ctx, venue, symbol, interval, cursor, end, pageSize)
+		if err != nil {
+			return err
+		}
+		if len(fetched) == 0 {
+			// Nothing in this page's window, e.g. before a listing; the
+			// next window may still have bars.
+			cursor = cursor.Add(time.Duration(pageSize) * step)
+			continue
+		}
+
+		fetchedAt := s.now().UTC()
+		for _, candle := range fetched {
+			candle.Venue = venue
+			candle.Symbol = symbol
+			candle.Interval = interval
+			candle.FetchedAt = fetchedAt
+			returned[candle.OpenTime.UTC().Unix()] = struct{}{}
+		}
+		if err := s.repo.UpsertMany(ctx, fetched); err != nil {
+			return err
+		}
+
+		next := fetched[len(fetched)-1].OpenTime.Add(step)
+		if !next.After(cursor) {
+			scannedTo = cursor.Add(-step)
+			break
+		}
+		cursor = next
+	}
+
+	settled := s.now().UTC().Add(-candleEmptySettle)
+	empty := findUnreturnedCandleRanges(returned, start, scannedTo, step, settled)
+	for _, item := range empty {
+		item.Venue = venue
+		item.Symbol = symbol
+		item.Interval = interval
+		item.CheckedAt = s.now().UTC()
+	}
+	return s.repo.MarkEmpty(ctx, empty)
+}
+
+// findUnreturnedCandleRanges groups the slots in [start, end] the venue did
+// not return into ranges, ignoring bars that closed after settled since the
+// venue may still publish them.
+func findUnreturnedCandleRanges(returned map[int64]struct{}, start, end time.Time, step time.Duration, settled time.Time) []*entities.CandleEmptyRange {
+	var ranges []*entities.CandleEmptyRange
+	var current *entities.CandleEmptyRange
+	for slot := start; !slot.After(end) && !slot.Add(step).After(settled); slot = slot.Add(step) {
+		if _, ok := returned[slot.Unix()]; ok {
+			current = nil
+			continue
+		}
+		if current == nil {
+			current = &entities.CandleEmptyRange{StartTime: slot}
+			ranges = append(ranges, current)
+		}
+		current.EndTime = slot
+	}
+	return ranges
+}
+
+// findCandleGaps returns [start, end] open-time ranges that are missing from
+// stored, plus bars that were still forming when last fetched. Slots inside a
+// known-empty range are not gaps.
+func findCandleGaps(stored []*entities.Candle, empty []*entities.CandleEmptyRange, start, end time.Time, step time.Duration, now time.Time) [][2]time.Time {
+	have := make(map[int64]*entities.Candle, len(stored))
+	for _, candle := range stored {
+		have[candle.OpenTime.UTC().Unix()] = candle
+	}
+	knownEmpty := func(slot time.Time) bool {
+		for _, item := range empty {
+			if !slot.Before(item.StartTime) && !slot.After(item.EndTime) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var gaps [][2]time.Time
+	var gapStart *time.Time
+	for slot := start; !slot.After(end); slot = slot.Add(step) {
+		candle, ok := have[slot.Unix()]
+		missing := !ok && !knownEmpty(slot)
+		if ok && candle.FetchedAt.Before(slot.Add(step)) && now.Sub(candle.FetchedAt) > candleOpenBarTTL {
+			missing = true
+		}
+
+		if missing {
+			if gapStart == nil {
+				s := slot
+				gapStart = &s
+			}
+			continue
+		}
+		if gapStart != nil {
+			gaps = append(gaps, [2]time.Time{*gapStart, slot.Add(-step)})
+			gapStart = nil
+		}
+	}
+	if gapStart != nil {
+		gaps = append(gaps, [2]time.Time{*gapStart, end})
+	}
+	return gaps
+}
+
+func normalizeCandleKey(venue, symbol, interval string) (string, string, string, error) {
+	venue = strings.ToLower(strings.TrimSpace(venue))
+	if venue == "" {
+		venue = entities.CandleVenueBinance
+	}
+	if venue != entities.CandleVenueBinance && venue != entities.CandleVenueUpbit {
+		return "", "", "", ErrUnsupportedCandleVenue
+	}
+	interval = strings.ToLower(strings.TrimSpace(interval))
+	if _, ok := candleIntervals[interval]; !ok {
+		return "", "", "", ErrUnsupportedCandleInterval
+	}
+	return venue, strings.ToUpper(strings.TrimSpace(symbol)), interval, nil
+}
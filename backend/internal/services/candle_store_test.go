@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+type fakeCandleRepo struct {
+	items map[int64]*entities.Candle
+	empty []*entities.CandleEmptyRange
+}
+
+func newFakeCandleRepo() *fakeCandleRepo {
+	return &fakeCandleRepo{items: make(map[int64]*entities.Candle)}
+}
+
+func (r *fakeCandleRepo) MarkEmpty(_ context.Context, ranges []*entities.CandleEmptyRange) error {
+	r.empty = append(r.empty, ranges...)
+	return nil
+}
+
+func (r *fakeCandleRepo) ListEmpty(_ context.Context, _, _, _ string, start, end time.Time) ([]*entities.CandleEmptyRange, error) {
+	var out []*entities.CandleEmptyRange
+	for _, item := range r.empty {
+		if !item.StartTime.After(end) && !item.EndTime.Before(start) {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeCandleRepo) UpsertMany(_ context.Context, candles []*entities.Candle) error {
+	for _, candle := range candles {
+		copied := *candle
+		r.items[candle.OpenTime.Unix()] = &copied
+	}
+	return nil
+}
+
+func (r *fakeCandleRepo) ListRange(_ context.Context, _, _, _ string, start, end time.Time) ([]*entities.Candle, error) {
+	var out []*entities.Candle
+	for slot := start; !slot.After(end); slot = slot.Add(time.Minute) {
+		if candle, ok := r.items[slot.Unix()]; ok {
+			copied := *candle
+			out = append(out, &copied)
+		}
+	}
+	return out, nil
+}
+
+// fakeCandleFetcher serves one bar per slot from listedAt. With windowed
+// set it answers like Upbit: at most 200 bars from start are covered.
+type fakeCandleFetcher struct {
+	calls    int
+	listedAt time.Time
+	windowed bool
+}
+
+func (f *fakeCandleFetcher) FetchCandles(_ context.Context, _, _, interval string, start, end time.Time, limit int) ([]*entities.Candle, error) {
+	f.calls++
+	step, _ := CandleIntervalDuration(interval)
+	if f.windowed && limit > 200 {
+		limit = 200
+	}
+	if windowEnd := start.Add(time.Duration(limit-1) * step); f.windowed && windowEnd.Before(end) {
+		end = windowEnd
+	}
+	var out []*entities.Candle
+	for slot := start; !slot.After(end) && len(out) < limit; slot = slot.Add(step) {
+		if slot.Before(f.listedAt) {
+			continue
+		}
+		price := strconv.FormatInt(slot.Unix()/60, 10)
+		out = append(out, &entities.Candle{OpenTime: slot, Open: price, High: price, Low: price, Close: price, Volume: "1"})
+	}
+	return out, nil
+}
+
+func TestCandleStoreBackfillsOnceAndServesFromRepo(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 10, 12, 0, 30, 0, time.UTC)
+	repo := newFakeCandleRepo()
+	fetcher := &fakeCandleFetcher{}
+	store := NewCandleStore(repo, fetcher)
+	store.now = func() time.Time { return now }
+
+	end := now.Add(-10 * time.Minute)
+	candles, err := store.Latest(context.Background(), "binance", "btcusdt", "1m", end, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(candles) != 5 {
+		t.Fatalf("expected 5 candles, got %d", len(candles))
+	}
+	if fetcher.calls != 1 {
+		t.Fatalf("expected 1 fetch, got %d", fetcher.calls)
+	}
+
+	if _, err := store.Latest(context.Background(), "binance", "BTCUSDT", "1m", end, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fetcher.calls != 1 {
+		t.Fatalf("expected cached read, got %d fetches", fetcher.calls)
+	}
+}
+
+func TestFindCandleGapsRefreshesStaleOpenBar(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	end := start.Add(3 * time.Minute)
+	now := end.Add(2 * time.Minute)
+	stored := []*entities.Candle{
+		{OpenTime: start, FetchedAt: now},
+		{OpenTime: start.Add(2 * time.Minute), FetchedAt: start.Add(2*time.Minute + 10*time.Second)},
+		{OpenTime: end, FetchedAt: now},
+	}
+
+	gaps := findCandleGaps(stored, nil, start, end, time.Minute, now)
+	if len(gaps) != 1 {
+		t.Fatalf("expected 1 gap, got %d", len(gaps))
+	}
+	if !gaps[0][0].Equal(start.Add(time.Minute)) || !gaps[0][1].Equal(start.Add(2*time.Minute)) {
+		t.Fatalf("unexpected gap: %v - %v", gaps[0][0], gaps[0][1])
+	}
+}
+
+func TestCandleStoreRemembersRangesBeforeListing(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 10, 12, 0, 30, 0, time.UTC)
+	repo := newFakeCandleRepo()
+	fetcher := &fakeCandleFetcher{listedAt: now.Add(-30 * time.Minute).Truncate(time.Minute)}
+	store := NewCandleStore(repo, fetcher)
+	store.now = func() time.Time { return now }
+
+	start := now.Add(-2 * time.Hour)
+	end := now.Add(-20 * time.Minute)
+	candles, err := store.Range(context.Background(), "binance", "NEWUSDT", "1m", start, end)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(candles) != 11 {
+		t.Fatalf("expected 11 candles after listing, got %d", len(candles))
+	}
+	if len(repo.empty) != 1 || !repo.empty[0].EndTime.Equal(fetcher.listedAt.Add(-time.Minute)) {
+		t.Fatalf("expected one empty range ending before listing, got %+v", repo.empty)
+	}
+
+	calls := fetcher.calls
+	if _, err := store.Range(context.Background(), "binance", "NEWUSDT", "1m", start, end); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fetcher.calls != calls {
+		t.Fatalf("expected known-empty range to be served without fetching, got %d extra fetches", fetcher.calls-calls)
+	}
+}
+
+func TestCandleStoreKeepsScanningAfterEmptyWindow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 10, 12, 0, 30, 0, time.UTC)
+	start := now.Add(-6 * time.Hour).Truncate(time.Minute)
+	end := now.Add(-20 * time.Minute).Truncate(time.Minute)
+	// Listed after the first 200-bar window, which comes back empty.
+	fetcher := &fakeCandleFetcher{listedAt: start.Add(250 * time.Minute), windowed: true}
+	repo := newFakeCandleRepo()
+	store := NewCandleStore(repo, fetcher)
+	store.now = func() time.Time { return now }
+
+	candles, err := store.Range(context.Background(), entities.CandleVenueUpbit, "KRW-NEW", "1m", start, end)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := int(end.Sub(fetcher.listedAt)/time.Minute) + 1; len(candles) != want {
+		t.Fatalf("expected %d candles after listing, got %d", want, len(candles))
+	}
+	if len(repo.empty) != 1 || !repo.empty[0].EndTime.Equal(fetcher.listedAt.Add(-time.Minute)) {
+		t.Fatalf("expected one empty range ending before listing, got %+v", repo.empty)
+	}
+}
+
+func TestCandleStoreRejectsUnknownInterval(t *testing.T) {
+	t.Parallel()
+
+	store := NewCandleStore(newFakeCandleRepo(), &fakeCandleFetcher{})
+	if _, err := store.Latest(context.Background(), "binance", "BTCUSDT", "7m", time.Time{}, 10); err != ErrUnsupportedCandleInterval {
+		t.Fatalf("expected ErrUnsupportedCandleInterval, got %v", err)
+	}
+}
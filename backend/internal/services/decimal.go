@@ -0,0 +1,18 @@
+package services
+
+import (
+	"math/big"
+	"strings"
+)
+
+// formatDecimal renders value with at most scale fractional digits and no
+// trailing zeros.
+func formatDecimal(value *big.Rat, scale int) string {
+	formatted := value.FloatString(scale)
+	formatted = strings.TrimRight(formatted, "0")
+	formatted = strings.TrimRight(formatted, ".")
+	if formatted == "" || formatted == "-" || formatted == "-0" {
+		return "0"
+	}
+	return formatted
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+var ErrInvalidOutcomePeriod = errors.New("period must be 1h, 4h or 1d")
+
+type decisionOutcomeLister interface {
+	ListDecisionOutcomes(ctx context.Context, userID uuid.UUID, period string, from, to *time.Time) ([]*repositories.AlertDecisionOutcome, error)
+}
+
+type DecisionQualityFilter struct {
+	Period string
+	From   *time.Time
+	To     *time.Time
+}
+
+// DecisionQualityBucket aggregates decisions sharing one key. A decision is a
+// hit when its stance matched the move (see DetermineActualDirection), and
+// its PnL is the price move signed by the stance: buy/add ride the move,
+// sell/close/reduce take the opposite side, hold/ignore stay flat.
+type DecisionQualityBucket struct {
+	Key     string `json:"key"`
+	Count   int    `json:"count"`
+	Hits    int    `json:"hits"`
+	HitRate string `json:"hit_rate"`
+	AvgPnL  string `json:"avg_pnl_percent"`
+}
+
+// DecisionIgnoreBaseline is what the same alerts would have scored had every
+// one of them been ignored.
+type DecisionIgnoreBaseline struct {
+	HitRate     string `json:"hit_rate"`
+	AvgPnL      string `json:"avg_pnl_percent"`
+	EdgeHitRate string `json:"edge_hit_rate"`
+	EdgeAvgPnL  string `json:"edge_avg_pnl_percent"`
+}
+
+// DecisionAIComparison compares the user's decision against the direction
+// recommended by the first AI briefing on the same alert.
+type DecisionAIComparison struct {
+	Compared         int    `json:"compared"`
+	Agreed           int    `json:"agreed"`
+	UserHitRate      string `json:"user_hit_rate"`
+	AIHitRate        string `json:"ai_hit_rate"`
+	UserAvgPnL       string `json:"user_avg_pnl_percent"`
+	AIAvgPnL         string `json:"ai_avg_pnl_percent"`
+	DisagreedUserWon int    `json:"disagreed_user_won"`
+	DisagreedAIWon   int    `json:"disagreed_ai_won"`
+}
+
+type DecisionQualityReport struct {
+	Period         string                  `json:"period"`
+	Overall        DecisionQualityBucket   `json:"overall"`
+	ByAction       []DecisionQualityBucket `json:"by_action"`
+	ByConfidence   []DecisionQualityBucket `json:"by_confidence"`
+	ByRuleType     []DecisionQualityBucket `json:"by_rule_type"`
+	BySeverity     []DecisionQualityBucket `json:"by_severity"`
+	IgnoreBaseline DecisionIgnoreBaseline  `json:"ignore_baseline"`
+	AIComparison   DecisionAIComparison    `json:"ai_comparison"`
+}
+
+type DecisionQualityService struct {
+	outcomes  decisionOutcomeLister
+	extractor *DirectionExtractor
+}
+
+func NewDecisionQualityService(outcomes decisionOutcomeLister) *DecisionQualityService {
+	return &DecisionQualityService{
+		outcomes:  outcomes,
+		extractor: NewDirectionExtractor(),
+	}
+}
+
+func (s *DecisionQualityService) Report(ctx context.Context, userID uuid.UUID, filter DecisionQualityFilter) (*DecisionQualityReport, error) {
+	switch filter.Period {
+	case "1h", "4h", "1d":
+	default:
+		return nil, ErrInvalidOutcomePeriod
+	}
+
+	items, err := s.outcomes.ListDecisionOutcomes(ctx, userID, filter.Period, filter.From, filter.To)
+	if err != nil {
+		return nil, err
+	}
+
+	overall := &qualityAccumulator{}
+	ignore := &qualityAccumulator{}
+	byAction := map[string]*qualityAccumulator{}
+	byConfidence := map[string]*qualityAccumulator{}
+	byRuleType := map[string]*qualityAccumulator{}
+	bySeverity := map[string]*qualityAccumulator{}
+
+	var aiUser, aiModel qualityAccumulator
+	comparison := DecisionAIComparison{}
+
+	for _, item := range items {
+		move := parseDecimal(item.PnLPercent)
+		if move == nil {
+			continue
+		}
+		actual := DetermineActualDirection(item.PnLPercent)
+
+		stance := decisionStance(item.Action)
+		hit := IsCorrect(stance, actual)
+		pnl := stancePnL(stance, move)
+
+		overall.add(hit, pnl)
+		ignore.add(IsCorrect(entities.DirectionHold, actual), new(big.Rat))
+		bucketFor(byAction, string(item.Action)).add(hit, pnl)
+		confidenceKey := "unset"
+		if item.Confidence != nil {
+			confidenceKey = string(*item.Confidence)
+		}
+		bucketFor(byConfidence, confidenceKey).add(hit, pnl)
+		bucketFor(byRuleType, string(item.RuleType)).add(hit, pnl)
+		bucketFor(bySeverity, string(item.Severity)).add(hit, pnl)
+
+		if item.Briefing == nil || *item.Briefing == "" {
+			continue
+		}
+		recommended := s.extractor.Extract(*item.Briefing)
+		aiHit := IsCorrect(recommended, actual)
+		aiPnL := stancePnL(recommended, move)
+		comparison.Compared++
+		aiUser.add(hit, pnl)
+		aiModel.add(aiHit, aiPnL)
+		if recommended == stance {
+			comparison.Agreed++
+			continue
+		}
+		switch pnl.Cmp(aiPnL) {
+		case 1:
+			comparison.DisagreedUserWon++
+		case -1:
+			comparison.DisagreedAIWon++
+		}
+	}
+
+	overallBucket := overall.bucket("all")
+	ignoreBucket := ignore.bucket("ignore")
+	report := &DecisionQualityReport{
+		Period:       filter.Period,
+		Overall:      overallBucket,
+		ByAction:     sortedBuckets(byAction),
+		ByConfidence: sortedBuckets(byConfidence),
+		ByRuleType:   sortedBuckets(byRuleType),
+		BySeverity:   sortedBuckets(bySeverity),
+		IgnoreBaseline: DecisionIgnoreBaseline{
+			HitRate:     ignoreBucket.HitRate,
+			AvgPnL:      ignoreBucket.AvgPnL,
+			EdgeHitRate: formatDecimal(new(big.Rat).Sub(overall.hitRate(), ignore.hitRate()), 4),
+			EdgeAvgPnL:  formatDecimal(new(big.Rat).Sub(overall.avgPnL(), ignore.avgPnL()), 4),
+		},
+	}
+
+	comparison.UserHitRate = formatDecimal(aiUser.hitRate(), 4)
+	comparison.AIHitRate = formatDecimal(aiModel.hitRate(), 4)
+	comparison.UserAvgPnL = formatDecimal(aiUser.avgPnL(), 4)
+	comparison.AIAvgPnL = formatDecimal(aiModel.avgPnL(), 4)
+	report.AIComparison = comparison
+
+	return report, nil
+}
+
+// decisionStance maps a decision to the direction it bets on, in the same
+// vocabulary the AI accuracy tracking uses.
+func decisionStance(action entities.DecisionAction) entities.Direction {
+	switch action {
+	case entities.DecisionBuy, entities.DecisionAdd:
+		return entities.DirectionBuy
+	case entities.DecisionSell, entities.DecisionClose, entities.DecisionReduce:
+		return entities.DirectionSell
+	}
+	return entities.DirectionHold
+}
+
+func stancePnL(stance entities.Direction, move *big.Rat) *big.Rat {
+	switch stance {
+	case entities.DirectionBuy:
+		return new(big.Rat).Set(move)
+	case entities.DirectionSell:
+		return new(big.Rat).Neg(move)
+	}
+	return new(big.Rat)
+}
+
+type qualityAccumulator struct {
+	count int
+	hits  int
+	pnl   big.Rat
+}
+
+func (a *qualityAccumulator) add(hit bool, pnl *big.Rat) {
+	a.count++
+	if hit {
+		a.hits++
+	}
+	a.pnl.Add(&a.pnl, pnl)
+}
+
+func (a *qualityAccumulator) hitRate() *big.Rat {
+	if a.count == 0 {
+		return new(big.Rat)
+	}
+	return big.NewRat(int64(a.hits), int64(a.count))
+}
+
+func (a *qualityAccumulator) avgPnL() *big.Rat {
+	if a.count == 0 {
+		return new(big.Rat)
+	}
+	return new(big.Rat).Quo(&a.pnl, new(big.Rat).SetInt64(int64(a.count)))
+}
+
+func (a *qualityAccumulator) bucket(key string) DecisionQualityBucket {
+	return DecisionQualityBucket{
+		Key:     key,
+		Count:   a.count,
+		Hits:    a.hits,
+		HitRate: formatDecimal(a.hitRate(), 4),
+		AvgPnL:  formatDecimal(a.avgPnL(), 4),
+	}
+}
+
+func bucketFor(buckets map[string]*qualityAccumulator, key string) *qualityAccumulator {
+	acc, ok := buckets[key]
+	if !ok {
+		acc = &qualityAccumulator{}
+		buckets[key] = acc
+	}
+	return acc
+}
+
+func sortedBuckets(buckets map[string]*qualityAccumulator) []DecisionQualityBucket {
+	out := make([]DecisionQualityBucket, 0, len(buckets))
+	for key, acc := range buckets {
+		out = append(out, acc.bucket(key))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
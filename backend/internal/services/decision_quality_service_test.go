@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+type fakeDecisionOutcomes struct {
+	items []*repositories.AlertDecisionOutcome
+}
+
+func (f *fakeDecisionOutcomes) ListDecisionOutcomes(context.Context, uuid.UUID, string, *time.Time, *time.Time) ([]*repositories.AlertDecisionOutcome, error) {
+	return f.items, nil
+}
+
+func TestDecisionQualityReport(t *testing.T) {
+	t.Parallel()
+
+	high := entities.ConfidenceHigh
+	bullish := "상승 추세 지속, 매수 추천"
+	bearish := "하락 전망, 매도 고려"
+	outcomes := &fakeDecisionOutcomes{items: []*repositories.AlertDecisionOutcome{
+		// bought, price up 2%: hit, +2
+		{Action: entities.DecisionBuy, Confidence: &high, RuleType: entities.RuleTypePriceChange, Severity: entities.AlertSeverityUrgent, PnLPercent: "2", Briefing: &bullish},
+		// sold, price up 1%: miss, -1; AI said buy and was right
+		{Action: entities.DecisionSell, RuleType: entities.RuleTypePriceChange, Severity: entities.AlertSeverityNormal, PnLPercent: "1", Briefing: &bullish},
+		// ignored, price flat: hit, 0; AI said sell and was wrong
+		{Action: entities.DecisionIgnore, RuleType: entities.RuleTypeMACross, Severity: entities.AlertSeverityNormal, PnLPercent: "0.1", Briefing: &bearish},
+		// closed, price down 3%: hit, +3
+		{Action: entities.DecisionClose, RuleType: entities.RuleTypeMACross, Severity: entities.AlertSeverityUrgent, PnLPercent: "-3"},
+	}}
+
+	report, err := NewDecisionQualityService(outcomes).Report(context.Background(), uuid.New(), DecisionQualityFilter{Period: "1d"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.Overall.Count != 4 || report.Overall.Hits != 3 || report.Overall.HitRate != "0.75" || report.Overall.AvgPnL != "1" {
+		t.Fatalf("unexpected overall: %+v", report.Overall)
+	}
+	if report.IgnoreBaseline.HitRate != "0.25" || report.IgnoreBaseline.AvgPnL != "0" || report.IgnoreBaseline.EdgeAvgPnL != "1" {
+		t.Fatalf("unexpected ignore baseline: %+v", report.IgnoreBaseline)
+	}
+
+	byConfidence := map[string]DecisionQualityBucket{}
+	for _, bucket := range report.ByConfidence {
+		byConfidence[bucket.Key] = bucket
+	}
+	if byConfidence["high"].Count != 1 || byConfidence["unset"].Count != 3 {
+		t.Fatalf("unexpected confidence buckets: %+v", report.ByConfidence)
+	}
+
+	ai := report.AIComparison
+	if ai.Compared != 3 || ai.Agreed != 1 || ai.DisagreedAIWon != 1 || ai.DisagreedUserWon != 1 {
+		t.Fatalf("unexpected ai comparison: %+v", ai)
+	}
+}
+
+func TestDecisionQualityRejectsUnknownPeriod(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewDecisionQualityService(&fakeDecisionOutcomes{}).Report(context.Background(), uuid.New(), DecisionQualityFilter{Period: "2d"})
+	if err != ErrInvalidOutcomePeriod {
+		t.Fatalf("expected ErrInvalidOutcomePeriod, got %v", err)
+	}
+}
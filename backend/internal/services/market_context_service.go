@@ -0,0 +1,377 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+const (
+	// MarketFingerprintVersion changes whenever the vector layout does, so old
+	// vectors are recomputed instead of compared against new ones.
+	MarketFingerprintVersion = "v1"
+
+	marketFingerprintInterval = "1h"
+	marketFingerprintWindow   = 48
+	marketFingerprintShape    = 24
+	// Shape points are scaled down so the 24 of them together don't drown out
+	// the six indicator features.
+	marketFingerprintShapeWeight = 0.5
+
+	// Transient candle errors are retried after 15m, 30m, 1h, ... up to
+	// marketFingerprintMaxAttempts tries before the market counts as unavailable.
+	marketFingerprintRetryBase   = 15 * time.Minute
+	marketFingerprintMaxAttempts = 6
+)
+
+var (
+	ErrMarketContextUnavailable = errors.New("no candle history for this bubble's market")
+	ErrMarketContextPending     = errors.New("candle history for this bubble's market could not be loaded yet")
+)
+
+var binanceCandleSymbolPattern = regexp.MustCompile(`^[A-Z0-9]{5,20}$`)
+
+// MarketCandleReader is the slice of CandleStore the fingerprints need.
+type MarketCandleReader interface {
+	Latest(ctx context.Context, venue, symbol, interval string, end time.Time, limit int) ([]*entities.Candle, error)
+}
+
+type MarketSimilarItem struct {
+	Bubble     *entities.Bubble
+	Outcome    *entities.Outcome
+	Distance   float64
+	Similarity float64
+}
+
+type MarketSimilarResult struct {
+	Period string
+	Wins   int
+	Losses int
+	AvgPnL *string
+	Items  []*MarketSimilarItem
+}
+
+// MarketContextService finds past bubbles taken in a similar market, across
+// symbols, by comparing fingerprints of the candles leading up to each bubble.
+type MarketContextService struct {
+	repo    repositories.BubbleFingerprintRepository
+	candles MarketCandleReader
+	now     func() time.Time
+}
+
+func NewMarketContextService(repo repositories.BubbleFingerprintRepository, candles MarketCandleReader) *MarketContextService {
+	return &MarketContextService{repo: repo, candles: candles, now: time.Now}
+}
+
+// Similar returns the k nearest earlier bubbles of the same user, with their
+// outcomes for period. The bubble's own fingerprint is computed on demand if
+// the backfill hasn't reached it yet.
+func (s *MarketContextService) Similar(ctx context.Context, bubble *entities.Bubble, period string, k int) (*MarketSimilarResult, error) {
+	fp, err := s.repo.GetByBubble(ctx, bubble.ID)
+	if err != nil {
+		return nil, err
+	}
+	if fp == nil || fp.Version != MarketFingerprintVersion || fingerprintRetryDue(fp, s.now()) {
+		if fp, err = s.compute(ctx, bubble, fp); err != nil {
+			return nil, err
+		}
+	}
+	switch fp.Status {
+	case entities.FingerprintReady:
+	case entities.FingerprintRetry:
+		return nil, ErrMarketContextPending
+	default:
+		return nil, ErrMarketContextUnavailable
+	}
+
+	candidates, err := s.repo.ListCandidates(ctx, bubble.UserID, MarketFingerprintVersion, bubble.CandleTime, period)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*MarketSimilarItem, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.Bubble == nil || candidate.Bubble.ID == bubble.ID || len(candidate.Vector) != len(fp.Vector) {
+			continue
+		}
+		distance := fingerprintDistance(fp.Vector, candidate.Vector)
+		items = append(items, &MarketSimilarItem{
+			Bubble:     candidate.Bubble,
+			Outcome:    candidate.Outcome,
+			Distance:   distance,
+			Similarity: 1 / (1 + distance),
+		})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Distance < items[j].Distance
+	})
+	if len(items) > k {
+		items = items[:k]
+	}
+
+	result := &MarketSimilarResult{Period: period, Items: items}
+	var sum float64
+	counted := 0
+	for _, item := range items {
+		if item.Outcome == nil {
+			continue
+		}
+		pnl, err := strconv.ParseFloat(strings.TrimSpace(item.Outcome.PnLPercent), 64)
+		if err != nil {
+			continue
+		}
+		if pnl > 0 {
+			result.Wins++
+		} else if pnl < 0 {
+			result.Losses++
+		}
+		sum += pnl
+		counted++
+	}
+	if counted > 0 {
+		avg := fmt.Sprintf("%.4f", sum/float64(counted))
+		result.AvgPnL = &avg
+	}
+	return result, nil
+}
+
+// Compute builds and stores the fingerprint for one bubble. Markets without
+// enough candle history are stored as unavailable so they aren't retried;
+// candle errors other than rate limits are stored for a later retry.
+func (s *MarketContextService) Compute(ctx context.Context, bubble *entities.Bubble) (*entities.BubbleFingerprint, error) {
+	prev, err := s.repo.GetByBubble(ctx, bubble.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.compute(ctx, bubble, prev)
+}
+
+func (s *MarketContextService) compute(ctx context.Context, bubble *entities.Bubble, prev *entities.BubbleFingerprint) (*entities.BubbleFingerprint, error) {
+	step, _ := CandleIntervalDuration(marketFingerprintInterval)
+	// The window ends with the last bar that closed before the bubble's candle.
+	windowEnd := bubble.CandleTime.UTC().Truncate(step).Add(-step)
+	fp := &entities.BubbleFingerprint{
+		BubbleID:       bubble.ID,
+		UserID:         bubble.UserID,
+		Version:        MarketFingerprintVersion,
+		Status:         entities.FingerprintUnavailable,
+		CandleInterval: marketFingerprintInterval,
+		WindowEnd:      windowEnd,
+		ComputedAt:     s.now().UTC(),
+	}
+
+	if venue, symbol, ok := marketCandleSource(bubble.Symbol); ok {
+		candles, err := s.candles.Latest(ctx, venue, symbol, marketFingerprintInterval, windowEnd, marketFingerprintWindow)
+		if err != nil {
+			var rateErr *CandleRateLimitError
+			if errors.As(err, &rateErr) {
+				return nil, err
+			}
+			log.Printf("market context: candles for %s %s: %v", venue, symbol, err)
+			scheduleFingerprintRetry(fp, prev)
+		} else if vector, ok := MarketFingerprintVector(candles); ok {
+			fp.Status = entities.FingerprintReady
+			fp.Vector = vector
+		}
+	}
+
+	if err := s.repo.Upsert(ctx, fp); err != nil {
+		return nil, err
+	}
+	return fp, nil
+}
+
+// scheduleFingerprintRetry marks fp for another try with exponential backoff,
+// carrying over the attempts of prev. Once the attempts run out fp stays
+// unavailable.
+func scheduleFingerprintRetry(fp, prev *entities.BubbleFingerprint) {
+	attempts := 1
+	if prev != nil && prev.Version == fp.Version && prev.Status == entities.FingerprintRetry {
+		attempts = prev.Attempts + 1
+	}
+	fp.Attempts = attempts
+	if attempts >= marketFingerprintMaxAttempts {
+		return
+	}
+	retryAfter := fp.ComputedAt.Add(marketFingerprintRetryBase << (attempts - 1))
+	fp.Status = entities.FingerprintRetry
+	fp.RetryAfter = &retryAfter
+}
+
+func fingerprintRetryDue(fp *entities.BubbleFingerprint, now time.Time) bool {
+	return fp.Status == entities.FingerprintRetry && fp.RetryAfter != nil && !fp.RetryAfter.After(now)
+}
+
+// BackfillFingerprints computes fingerprints for up to limit bubbles that
+// don't have a current one yet, or whose retry is due, and reports how many
+// it stored.
+func (s *MarketContextService) BackfillFingerprints(ctx context.Context, limit int) (int, error) {
+	bubbles, err := s.repo.ListMissing(ctx, MarketFingerprintVersion, s.now().UTC(), limit)
+	if err != nil {
+		return 0, err
+	}
+	stored := 0
+	for _, bubble := range bubbles {
+		if _, err := s.Compute(ctx, bubble); err != nil {
+			var rateErr *CandleRateLimitError
+			if errors.As(err, &rateErr) {
+				// Pick the rest up on the next pass.
+				return stored, nil
+			}
+			return stored, err
+		}
+		stored++
+	}
+	return stored, nil
+}
+
+// MarketFingerprintVector turns the window of candles into a fixed-length
+// vector: the z-normalized shape of the last 24 closes followed by RSI, the
+// 24-bar return, volatility, distance from SMA20, relative volume and the
+// close's position in the window's range. Every feature is squashed into
+// roughly [-1, 1] so markets with different prices compare directly.
+func MarketFingerprintVector(candles []*entities.Candle) ([]float64, bool) {
+	if len(candles) < marketFingerprintWindow {
+		return nil, false
+	}
+	candles = candles[len(candles)-marketFingerprintWindow:]
+
+	n := len(candles)
+	closes := make([]float64, n)
+	highs := make([]float64, n)
+	lows := make([]float64, n)
+	volumes := make([]float64, n)
+	for i, candle := range candles {
+		values := [4]float64{}
+		for j, raw := range []string{candle.Close, candle.High, candle.Low, candle.Volume} {
+			v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+			if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+				return nil, false
+			}
+			values[j] = v
+		}
+		if values[0] <= 0 {
+			return nil, false
+		}
+		closes[i], highs[i], lows[i], volumes[i] = values[0], values[1], values[2], values[3]
+	}
+
+	vector := make([]float64, 0, marketFingerprintShape+6)
+
+	shape := closes[n-marketFingerprintShape:]
+	mean, std := meanStd(shape)
+	for _, c := range shape {
+		z := 0.0
+		if std > 0 {
+			z = (c - mean) / std
+		}
+		vector = append(vector, z*marketFingerprintShapeWeight)
+	}
+
+	last := closes[n-1]
+
+	vector = append(vector, (relativeStrength(closes, 14)-50)/50)
+	vector = append(vector, math.Tanh((last/closes[n-marketFingerprintShape]-1)*10))
+
+	returns := make([]float64, 0, n-1)
+	for i := 1; i < n; i++ {
+		returns = append(returns, math.Log(closes[i]/closes[i-1]))
+	}
+	_, volatility := meanStd(returns)
+	vector = append(vector, math.Tanh(volatility*50))
+
+	sma, _ := meanStd(closes[n-20:])
+	vector = append(vector, math.Tanh((last/sma-1)*20))
+
+	recentVolume, _ := meanStd(volumes[n-6:])
+	windowVolume, _ := meanStd(volumes)
+	volumeFeature := 0.0
+	if recentVolume > 0 && windowVolume > 0 {
+		volumeFeature = math.Tanh(math.Log(recentVolume / windowVolume))
+	}
+	vector = append(vector, volumeFeature)
+
+	low, high := lows[0], highs[0]
+	for i := range candles {
+		low = math.Min(low, lows[i])
+		high = math.Max(high, highs[i])
+	}
+	position := 0.0
+	if high > low {
+		position = (last-low)/(high-low)*2 - 1
+	}
+	vector = append(vector, position)
+
+	return vector, true
+}
+
+// marketCandleSource maps a bubble symbol onto the venue we read its candles
+// from: KRW markets come from Upbit, everything else from Binance.
+func marketCandleSource(raw string) (string, string, bool) {
+	symbol := strings.ToUpper(strings.TrimSpace(raw))
+	if strings.HasPrefix(symbol, "KRW-") && len(symbol) > 4 {
+		return entities.CandleVenueUpbit, symbol, true
+	}
+	if strings.HasSuffix(symbol, "KRW") && len(symbol) > 3 && !strings.Contains(symbol, "-") {
+		return entities.CandleVenueUpbit, "KRW-" + strings.TrimSuffix(symbol, "KRW"), true
+	}
+	if binanceCandleSymbolPattern.MatchString(symbol) {
+		return entities.CandleVenueBinance, symbol, true
+	}
+	return "", "", false
+}
+
+func fingerprintDistance(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+func meanStd(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
+
+// relativeStrength is a simple-average RSI over the last period changes.
+func relativeStrength(closes []float64, period int) float64 {
+	if len(closes) <= period {
+		return 50
+	}
+	var gains, losses float64
+	for i := len(closes) - period; i < len(closes); i++ {
+		change := closes[i] - closes[i-1]
+		if change > 0 {
+			gains += change
+		} else {
+			losses -= change
+		}
+	}
+	if gains+losses == 0 {
+		return 50
+	}
+	return 100 * gains / (gains + losses)
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+type fakeFingerprintRepo struct {
+	stored     map[uuid.UUID]*entities.BubbleFingerprint
+	candidates []*repositories.FingerprintCandidate
+}
+
+func (r *fakeFingerprintRepo) Upsert(_ context.Context, fp *entities.BubbleFingerprint) error {
+	r.stored[fp.BubbleID] = fp
+	return nil
+}
+
+func (r *fakeFingerprintRepo) GetByBubble(_ context.Context, bubbleID uuid.UUID) (*entities.BubbleFingerprint, error) {
+	return r.stored[bubbleID], nil
+}
+
+func (r *fakeFingerprintRepo) ListMissing(context.Context, string, time.Time, int) ([]*entities.Bubble, error) {
+	return nil, nil
+}
+
+func (r *fakeFingerprintRepo) ListCandidates(context.Context, uuid.UUID, string, time.Time, string) ([]*repositories.FingerprintCandidate, error) {
+	return r.candidates, nil
+}
+
+type fakeMarketCandles struct {
+	candles map[string][]*entities.Candle
+	ends    []time.Time
+	err     error
+}
+
+func (f *fakeMarketCandles) Latest(_ context.Context, venue, symbol, _ string, end time.Time, _ int) ([]*entities.Candle, error) {
+	f.ends = append(f.ends, end)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.candles[venue+":"+symbol], nil
+}
+
+func trendCandles(n int, start, step float64) []*entities.Candle {
+	candles := make([]*entities.Candle, n)
+	for i := range candles {
+		price := start + step*float64(i)
+		candles[i] = &entities.Candle{
+			Open:   strconv.FormatFloat(price, 'f', 4, 64),
+			High:   strconv.FormatFloat(price*1.01, 'f', 4, 64),
+			Low:    strconv.FormatFloat(price*0.99, 'f', 4, 64),
+			Close:  strconv.FormatFloat(price, 'f', 4, 64),
+			Volume: "100",
+		}
+	}
+	return candles
+}
+
+func TestMarketFingerprintVectorIsScaleInvariant(t *testing.T) {
+	t.Parallel()
+
+	cheap, ok := MarketFingerprintVector(trendCandles(48, 1, 0.01))
+	if !ok {
+		t.Fatal("expected a vector")
+	}
+	dear, _ := MarketFingerprintVector(trendCandles(48, 50000, 500))
+	if len(cheap) != marketFingerprintShape+6 {
+		t.Fatalf("vector length = %d", len(cheap))
+	}
+	if d := fingerprintDistance(cheap, dear); d > 1e-6 {
+		t.Fatalf("same shape at different prices should match, distance %f", d)
+	}
+	// A steady uptrend has RSI 100 and closes at the top of its range.
+	if math.Abs(cheap[marketFingerprintShape]-1) > 1e-9 || cheap[len(cheap)-1] < 0.9 {
+		t.Fatalf("unexpected indicators: %v", cheap[marketFingerprintShape:])
+	}
+
+	if _, ok := MarketFingerprintVector(trendCandles(30, 1, 0.01)); ok {
+		t.Fatal("short windows should not produce a vector")
+	}
+}
+
+func TestMarketContextSimilarRanksAcrossSymbols(t *testing.T) {
+	t.Parallel()
+
+	up, _ := MarketFingerprintVector(trendCandles(48, 100, 1))
+	down, _ := MarketFingerprintVector(trendCandles(48, 100, -1))
+	userID := uuid.New()
+	near := &entities.Bubble{ID: uuid.New(), UserID: userID, Symbol: "ETHUSDT"}
+	far := &entities.Bubble{ID: uuid.New(), UserID: userID, Symbol: "KRW-XRP"}
+
+	repo := &fakeFingerprintRepo{
+		stored: map[uuid.UUID]*entities.BubbleFingerprint{},
+		candidates: []*repositories.FingerprintCandidate{
+			{Bubble: far, Vector: down, Outcome: &entities.Outcome{PnLPercent: "-2"}},
+			{Bubble: near, Vector: up, Outcome: &entities.Outcome{PnLPercent: "3"}},
+		},
+	}
+	candles := &fakeMarketCandles{candles: map[string][]*entities.Candle{
+		"binance:BTCUSDT": trendCandles(48, 30000, 300),
+	}}
+	svc := NewMarketContextService(repo, candles)
+
+	bubble := &entities.Bubble{ID: uuid.New(), UserID: userID, Symbol: "btcusdt",
+		CandleTime: time.Date(2026, 3, 1, 10, 30, 0, 0, time.UTC)}
+	result, err := svc.Similar(context.Background(), bubble, "1h", 1)
+	if err != nil {
+		t.Fatalf("similar: %v", err)
+	}
+	if len(result.Items) != 1 || result.Items[0].Bubble.ID != near.ID {
+		t.Fatalf("expected the uptrend bubble first, got %+v", result.Items)
+	}
+	if result.Items[0].Similarity < 0.99 || result.Wins != 1 || result.Losses != 0 || *result.AvgPnL != "3.0000" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if want := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC); !candles.ends[0].Equal(want) {
+		t.Fatalf("window end = %v, want %v", candles.ends[0], want)
+	}
+	if repo.stored[bubble.ID].Status != entities.FingerprintReady {
+		t.Fatal("expected the fingerprint to be stored")
+	}
+
+	unknown := &entities.Bubble{ID: uuid.New(), UserID: userID, Symbol: "SOLUSDT", CandleTime: bubble.CandleTime}
+	if _, err := svc.Similar(context.Background(), unknown, "1h", 5); !errors.Is(err, ErrMarketContextUnavailable) {
+		t.Fatalf("expected unavailable, got %v", err)
+	}
+	if repo.stored[unknown.ID].Status != entities.FingerprintUnavailable {
+		t.Fatal("expected an unavailable fingerprint to be stored")
+	}
+}
+
+func TestMarketContextRetriesAfterTransientCandleError(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	repo := &fakeFingerprintRepo{stored: map[uuid.UUID]*entities.BubbleFingerprint{}}
+	candles := &fakeMarketCandles{
+		candles: map[string][]*entities.Candle{"binance:BTCUSDT": trendCandles(48, 30000, 300)},
+		err:     errors.New("binance klines error 502"),
+	}
+	svc := NewMarketContextService(repo, candles)
+	svc.now = func() time.Time { return now }
+
+	bubble := &entities.Bubble{ID: uuid.New(), UserID: uuid.New(), Symbol: "BTCUSDT",
+		CandleTime: time.Date(2026, 3, 1, 10, 30, 0, 0, time.UTC)}
+	if _, err := svc.Similar(context.Background(), bubble, "1h", 5); !errors.Is(err, ErrMarketContextPending) {
+		t.Fatalf("expected pending, got %v", err)
+	}
+	stored := repo.stored[bubble.ID]
+	if stored.Status != entities.FingerprintRetry || stored.Attempts != 1 || !stored.RetryAfter.Equal(now.Add(15*time.Minute)) {
+		t.Fatalf("expected a scheduled retry, got %+v", stored)
+	}
+
+	// Before the retry is due the stored row is served without hitting the exchange.
+	calls := len(candles.ends)
+	if _, err := svc.Similar(context.Background(), bubble, "1h", 5); !errors.Is(err, ErrMarketContextPending) {
+		t.Fatalf("expected pending, got %v", err)
+	}
+	if len(candles.ends) != calls {
+		t.Fatal("expected no candle read before the retry is due")
+	}
+
+	candles.err = nil
+	now = now.Add(time.Hour)
+	if _, err := svc.Similar(context.Background(), bubble, "1h", 5); err != nil {
+		t.Fatalf("expected the retry to succeed, got %v", err)
+	}
+	if repo.stored[bubble.ID].Status != entities.FingerprintReady {
+		t.Fatalf("expected ready after the outage, got %s", repo.stored[bubble.ID].Status)
+	}
+}
+
+func TestMarketCandleSource(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"KRW-BTC": "upbit:KRW-BTC",
+		"btckrw":  "upbit:KRW-BTC",
+		"BTCUSDT": "binance:BTCUSDT",
+		"BTC/USD": "",
+	}
+	for symbol, want := range cases {
+		venue, market, ok := marketCandleSource(symbol)
+		got := ""
+		if ok {
+			got = venue + ":" + market
+		}
+		if got != want {
+			t.Fatalf("marketCandleSource(%q) = %q, want %q", symbol, got, want)
+		}
+	}
+}
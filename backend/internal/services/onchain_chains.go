@@ -0,0 +1,56 @@
+package services
+
+import (
+	"strings"
+	"time"
+)
+
+// OnchainChain is an EVM chain the quick check can read. BlockTime is an
+// average, used to turn the requested time range into a block range. A chain
+// is only served when RPCEnv names an Alchemy URL: public RPCs do not offer
+// alchemy_getAssetTransfers.
+type OnchainChain struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name"`
+	ChainID   int64         `json:"chain_id"`
+	BlockTime time.Duration `json:"-"`
+	RPCEnv    string        `json:"-"`
+}
+
+// onchainChains is the chain registry, in the order chains are reported.
+var onchainChains = []OnchainChain{
+	{ID: "base", Name: "Base", ChainID: 8453, BlockTime: 2 * time.Second, RPCEnv: "BASE_RPC_URL"},
+	{ID: "ethereum", Name: "Ethereum", ChainID: 1, BlockTime: 12 * time.Second, RPCEnv: "ETHEREUM_RPC_URL"},
+	{ID: "arbitrum", Name: "Arbitrum One", ChainID: 42161, BlockTime: 250 * time.Millisecond, RPCEnv: "ARBITRUM_RPC_URL"},
+	{ID: "optimism", Name: "OP Mainnet", ChainID: 10, BlockTime: 2 * time.Second, RPCEnv: "OPTIMISM_RPC_URL"},
+	{ID: "polygon", Name: "Polygon PoS", ChainID: 137, BlockTime: 2 * time.Second, RPCEnv: "POLYGON_RPC_URL"},
+	// BNB Chain blocks have been sub-second since the 2025 hard forks.
+	{ID: "bnb", Name: "BNB Chain", ChainID: 56, BlockTime: 750 * time.Millisecond, RPCEnv: "BNB_RPC_URL"},
+}
+
+var onchainChainAliases = map[string]string{
+	"eth":   "ethereum",
+	"arb":   "arbitrum",
+	"op":    "optimism",
+	"matic": "polygon",
+	"bsc":   "bnb",
+}
+
+// OnchainChains lists the supported chains.
+func OnchainChains() []OnchainChain {
+	return append([]OnchainChain(nil), onchainChains...)
+}
+
+// LookupOnchainChain finds a chain by ID or common alias, ignoring case.
+func LookupOnchainChain(id string) (OnchainChain, bool) {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if alias, ok := onchainChainAliases[id]; ok {
+		id = alias
+	}
+	for _, chain := range onchainChains {
+		if chain.ID == id {
+			return chain, true
+		}
+	}
+	return OnchainChain{}, false
+}
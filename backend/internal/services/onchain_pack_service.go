@@ -16,6 +16,7 @@ import (
 const (
 	onchainSchemaVersion = "onchain_pack_v0"
 	defaultOnchainRange  = "30d"
+	onchainMultiChain    = "multi"
 )
 
 var (
@@ -43,17 +44,24 @@ type TransferEvent struct {
 	Timestamp    time.Time
 }
 
+// OnchainProvider reads one chain; the service holds one per chain ID.
 type OnchainProvider interface {
 	ListERC20Transfers(ctx context.Context, address string, startTime, endTime time.Time) ([]TransferEvent, error)
 }
 
+// OnchainQuickCheckRequest checks Chain, or every chain in Chains for one
+// aggregated pack.
 type OnchainQuickCheckRequest struct {
-	Chain   string `json:"chain"`
-	Address string `json:"address"`
-	Range   string `json:"range"`
+	Chain   string   `json:"chain"`
+	Chains  []string `json:"chains,omitempty"`
+	Address string   `json:"address"`
+	Range   string   `json:"range"`
 }
 
+// OnchainTokenFlow names its chain in aggregated packs only, since the same
+// token address means different tokens on different chains.
 type OnchainTokenFlow struct {
+	Chain  string `json:"chain,omitempty"`
 	Token  string `json:"token"`
 	Amount string `json:"amount"`
 }
@@ -71,15 +79,28 @@ type OnchainWarning struct {
 	Detail   string `json:"detail"`
 }
 
+// OnchainChainResult is one chain's part of an aggregated pack.
+type OnchainChainResult struct {
+	Chain    string           `json:"chain"`
+	Summary  OnchainSummary   `json:"summary"`
+	Warnings []OnchainWarning `json:"warnings"`
+	Status   string           `json:"status"`
+}
+
+// OnchainQuickCheckResponse is a single-chain pack, or with Chain set to
+// onchainMultiChain, the aggregate of the chains listed in Chains with a
+// breakdown in ChainResults.
 type OnchainQuickCheckResponse struct {
-	SchemaVersion string           `json:"schema_version"`
-	Chain         string           `json:"chain"`
-	Address       string           `json:"address"`
-	AnchorTs      string           `json:"anchor_ts"`
-	Range         string           `json:"range"`
-	Summary       OnchainSummary   `json:"summary"`
-	Warnings      []OnchainWarning `json:"warnings"`
-	Status        string           `json:"status"`
+	SchemaVersion string               `json:"schema_version"`
+	Chain         string               `json:"chain"`
+	Chains        []string             `json:"chains,omitempty"`
+	Address       string               `json:"address"`
+	AnchorTs      string               `json:"anchor_ts"`
+	Range         string               `json:"range"`
+	Summary       OnchainSummary       `json:"summary"`
+	Warnings      []OnchainWarning     `json:"warnings"`
+	Status        string               `json:"status"`
+	ChainResults  []OnchainChainResult `json:"chain_results,omitempty"`
 }
 
 type onchainCacheEntry struct {
@@ -88,7 +109,7 @@ type onchainCacheEntry struct {
 }
 
 type OnchainPackService struct {
-	provider        OnchainProvider
+	providers       map[string]OnchainProvider
 	now             func() time.Time
 	providerTimeout time.Duration
 	cacheTTL        time.Duration
@@ -97,9 +118,11 @@ type OnchainPackService struct {
 	cache   map[string]onchainCacheEntry
 }
 
-func NewOnchainPackService(provider OnchainProvider) *OnchainPackService {
+// NewOnchainPackService takes a provider per chain ID; chains without one
+// are rejected as unsupported.
+func NewOnchainPackService(providers map[string]OnchainProvider) *OnchainPackService {
 	return &OnchainPackService{
-		provider:        provider,
+		providers:       providers,
 		now:             time.Now,
 		providerTimeout: onchainProviderLimit,
 		cacheTTL:        onchainCacheTTL,
@@ -107,21 +130,49 @@ func NewOnchainPackService(provider OnchainProvider) *OnchainPackService {
 	}
 }
 
+// HasProvider reports whether quick check can read the chain.
+func (s *OnchainPackService) HasProvider(chainID string) bool {
+	return s.providers[chainID] != nil
+}
+
 func ValidateEVMAddress(address string) bool {
 	return evmAddressRegex.MatchString(strings.TrimSpace(address))
 }
 
 type normalizedQuickCheckRequest struct {
 	chain   string
+	chains  []OnchainChain
 	address string
 	rng     string
 }
 
 func normalizeQuickCheckRequest(req OnchainQuickCheckRequest) (normalizedQuickCheckRequest, error) {
-	chain := strings.ToLower(strings.TrimSpace(req.Chain))
-	if chain != "base" {
+	requested := req.Chains
+	if strings.TrimSpace(req.Chain) != "" {
+		requested = append([]string{req.Chain}, requested...)
+	}
+	wanted := map[string]bool{}
+	for _, id := range requested {
+		chain, ok := LookupOnchainChain(id)
+		if !ok {
+			return normalizedQuickCheckRequest{}, ErrInvalidChain
+		}
+		wanted[chain.ID] = true
+	}
+	// Registry order keeps the cache key and the breakdown stable.
+	var chains []OnchainChain
+	for _, chain := range onchainChains {
+		if wanted[chain.ID] {
+			chains = append(chains, chain)
+		}
+	}
+	if len(chains) == 0 {
 		return normalizedQuickCheckRequest{}, ErrInvalidChain
 	}
+	chain := chains[0].ID
+	if len(chains) > 1 {
+		chain = onchainMultiChain
+	}
 
 	address := strings.ToLower(strings.TrimSpace(req.Address))
 	if !ValidateEVMAddress(address) {
@@ -138,6 +189,7 @@ func normalizeQuickCheckRequest(req OnchainQuickCheckRequest) (normalizedQuickCh
 
 	return normalizedQuickCheckRequest{
 		chain:   chain,
+		chains:  chains,
 		address: address,
 		rng:     rng,
 	}, nil
@@ -151,60 +203,41 @@ func onchainRangeDuration(rng string) time.Duration {
 }
 
 func (s *OnchainPackService) BuildQuickCheck(ctx context.Context, req OnchainQuickCheckRequest) (OnchainQuickCheckResponse, error) {
-	if s.provider == nil {
+	if len(s.providers) == 0 {
 		log.Printf("[incident:onchain] severity=error event=service.provider_missing")
 		return OnchainQuickCheckResponse{}, errors.New("onchain provider unavailable")
 	}
 
 	normalized, err := normalizeQuickCheckRequest(req)
 	if err != nil {
-		log.Printf("[incident:onchain] severity=warning event=service.validation_reject chain=%s address=%s range=%s reason=%v", strings.ToLower(strings.TrimSpace(req.Chain)), strings.ToLower(strings.TrimSpace(req.Address)), strings.TrimSpace(req.Range), err)
+		requestedChains := strings.ToLower(strings.Join(append([]string{strings.TrimSpace(req.Chain)}, req.Chains...), ","))
+		log.Printf("[incident:onchain] severity=warning event=service.validation_reject chain=%s address=%s range=%s reason=%v", requestedChains, strings.ToLower(strings.TrimSpace(req.Address)), strings.TrimSpace(req.Range), err)
 		return OnchainQuickCheckResponse{}, err
 	}
+	chainIDs := make([]string, 0, len(normalized.chains))
+	for _, chain := range normalized.chains {
+		if s.providers[chain.ID] == nil {
+			log.Printf("[incident:onchain] severity=warning event=service.validation_reject chain=%s address=%s range=%s reason=provider_not_configured", chain.ID, normalized.address, normalized.rng)
+			return OnchainQuickCheckResponse{}, ErrInvalidChain
+		}
+		chainIDs = append(chainIDs, chain.ID)
+	}
+	chainKey := strings.Join(chainIDs, "+")
 
 	now := s.now().UTC()
 	bucket := now.Truncate(onchainBucketSize)
-	cacheKey := fmt.Sprintf("%s:%s:%s:%d", normalized.chain, normalized.address, normalized.rng, bucket.Unix())
+	cacheKey := fmt.Sprintf("%s:%s:%s:%d", chainKey, normalized.address, normalized.rng, bucket.Unix())
 
 	if cached, ok := s.getCached(cacheKey, now); ok {
-		log.Printf("[incident:onchain] severity=info event=service.cache_hit chain=%s address=%s range=%s", normalized.chain, normalized.address, normalized.rng)
+		log.Printf("[incident:onchain] severity=info event=service.cache_hit chain=%s address=%s range=%s", chainKey, normalized.address, normalized.rng)
 		return cached, nil
 	}
-	log.Printf("[incident:onchain] severity=info event=service.cache_miss chain=%s address=%s range=%s bucket=%d", normalized.chain, normalized.address, normalized.rng, bucket.Unix())
+	log.Printf("[incident:onchain] severity=info event=service.cache_miss chain=%s address=%s range=%s bucket=%d", chainKey, normalized.address, normalized.rng, bucket.Unix())
 
 	startTime := now.Add(-onchainRangeDuration(normalized.rng))
 	providerCtx, cancel := context.WithTimeout(ctx, s.providerTimeout)
 	defer cancel()
-
-	providerStart := time.Now()
-	events, providerErr := s.provider.ListERC20Transfers(providerCtx, normalized.address, startTime, now)
-	if providerErr != nil {
-		providerElapsedMs := time.Since(providerStart).Milliseconds()
-		log.Printf("[incident:onchain] severity=error event=service.provider_error chain=%s address=%s range=%s elapsed_ms=%d err=%v", normalized.chain, normalized.address, normalized.rng, providerElapsedMs, providerErr)
-		return OnchainQuickCheckResponse{
-			SchemaVersion: onchainSchemaVersion,
-			Chain:         normalized.chain,
-			Address:       normalized.address,
-			AnchorTs:      now.Format(time.RFC3339),
-			Range:         normalized.rng,
-			Summary:       emptyOnchainSummary(),
-			Warnings: []OnchainWarning{
-				{
-					Code:     "PROVIDER_UNAVAILABLE",
-					Severity: "error",
-					Detail:   "onchain provider timeout or unavailable",
-				},
-			},
-			Status: "error",
-		}, nil
-	}
-
-	summary, totalIn, top1In := buildOnchainSummary(normalized.address, events)
-	warnings := evaluateOnchainWarnings(summary, totalIn, top1In)
-	status := "ok"
-	if len(warnings) > 0 {
-		status = "warning"
-	}
+	fetches := s.fetchTransfers(providerCtx, normalized, startTime, now)
 
 	response := OnchainQuickCheckResponse{
 		SchemaVersion: onchainSchemaVersion,
@@ -212,15 +245,141 @@ func (s *OnchainPackService) BuildQuickCheck(ctx context.Context, req OnchainQui
 		Address:       normalized.address,
 		AnchorTs:      now.Format(time.RFC3339),
 		Range:         normalized.rng,
-		Summary:       summary,
-		Warnings:      warnings,
-		Status:        status,
+	}
+	if len(normalized.chains) > 1 {
+		response.Chains = chainIDs
+		if !aggregateOnchainChains(&response, normalized.chains, fetches) {
+			return response, nil
+		}
+		s.setCached(cacheKey, response, now)
+		return response, nil
 	}
 
+	if fetches[0].err != nil {
+		response.Summary = emptyOnchainSummary()
+		response.Warnings = []OnchainWarning{providerUnavailableWarning()}
+		response.Status = "error"
+		return response, nil
+	}
+
+	summary, totalIn, top1In := buildOnchainSummary(normalized.address, fetches[0].events)
+	response.Summary = summary
+	response.Warnings = evaluateOnchainWarnings(summary, totalIn, top1In)
+	response.Status = onchainStatus(response.Warnings)
+
 	s.setCached(cacheKey, response, now)
 	return response, nil
 }
 
+type onchainFetch struct {
+	events []TransferEvent
+	err    error
+}
+
+// fetchTransfers asks each requested chain's provider in parallel.
+func (s *OnchainPackService) fetchTransfers(ctx context.Context, normalized normalizedQuickCheckRequest, startTime, endTime time.Time) []onchainFetch {
+	fetches := make([]onchainFetch, len(normalized.chains))
+	var wg sync.WaitGroup
+	for i, chain := range normalized.chains {
+		wg.Add(1)
+		go func(i int, chain OnchainChain) {
+			defer wg.Done()
+			providerStart := time.Now()
+			events, err := s.providers[chain.ID].ListERC20Transfers(ctx, normalized.address, startTime, endTime)
+			if err != nil {
+				log.Printf("[incident:onchain] severity=error event=service.provider_error chain=%s address=%s range=%s elapsed_ms=%d err=%v", chain.ID, normalized.address, normalized.rng, time.Since(providerStart).Milliseconds(), err)
+			}
+			fetches[i] = onchainFetch{events: events, err: err}
+		}(i, chain)
+	}
+	wg.Wait()
+	return fetches
+}
+
+// aggregateOnchainChains fills an aggregated pack from per-chain transfers.
+// Tokens are keyed by chain, and the warnings are evaluated again on the
+// combined flows. It reports whether every chain answered, since partial
+// packs are not cached.
+func aggregateOnchainChains(response *OnchainQuickCheckResponse, chains []OnchainChain, fetches []onchainFetch) bool {
+	var combined []TransferEvent
+	var failed []string
+	for i, chain := range chains {
+		if fetches[i].err != nil {
+			failed = append(failed, chain.ID)
+			response.ChainResults = append(response.ChainResults, OnchainChainResult{
+				Chain:    chain.ID,
+				Summary:  emptyOnchainSummary(),
+				Warnings: []OnchainWarning{providerUnavailableWarning()},
+				Status:   "error",
+			})
+			continue
+		}
+
+		summary, totalIn, top1In := buildOnchainSummary(response.Address, fetches[i].events)
+		warnings := evaluateOnchainWarnings(summary, totalIn, top1In)
+		response.ChainResults = append(response.ChainResults, OnchainChainResult{
+			Chain:    chain.ID,
+			Summary:  summary,
+			Warnings: warnings,
+			Status:   onchainStatus(warnings),
+		})
+		for _, event := range fetches[i].events {
+			if token := strings.ToLower(strings.TrimSpace(event.TokenAddress)); token != "" {
+				event.TokenAddress = chain.ID + ":" + token
+			}
+			combined = append(combined, event)
+		}
+	}
+
+	if len(failed) == len(chains) {
+		response.Summary = emptyOnchainSummary()
+		response.Warnings = []OnchainWarning{providerUnavailableWarning()}
+		response.Status = "error"
+		return false
+	}
+
+	summary, totalIn, top1In := buildOnchainSummary(response.Address, combined)
+	summary.TopIn = splitChainTokenFlows(summary.TopIn)
+	summary.TopOut = splitChainTokenFlows(summary.TopOut)
+	response.Summary = summary
+	response.Warnings = evaluateOnchainWarnings(summary, totalIn, top1In)
+	if len(failed) > 0 {
+		response.Warnings = append(response.Warnings, OnchainWarning{
+			Code:     "PARTIAL_PROVIDER_FAILURE",
+			Severity: "warn",
+			Detail:   "onchain provider timeout or unavailable for " + strings.Join(failed, ", "),
+		})
+	}
+	response.Status = onchainStatus(response.Warnings)
+	return len(failed) == 0
+}
+
+// splitChainTokenFlows turns the chain:token keys of an aggregated summary
+// back into separate fields.
+func splitChainTokenFlows(flows []OnchainTokenFlow) []OnchainTokenFlow {
+	for i, flow := range flows {
+		if chain, token, ok := strings.Cut(flow.Token, ":"); ok {
+			flows[i].Chain, flows[i].Token = chain, token
+		}
+	}
+	return flows
+}
+
+func providerUnavailableWarning() OnchainWarning {
+	return OnchainWarning{
+		Code:     "PROVIDER_UNAVAILABLE",
+		Severity: "error",
+		Detail:   "onchain provider timeout or unavailable",
+	}
+}
+
+func onchainStatus(warnings []OnchainWarning) string {
+	if len(warnings) > 0 {
+		return "warning"
+	}
+	return "ok"
+}
+
 func emptyOnchainSummary() OnchainSummary {
 	return OnchainSummary{
 		TokenTransferCount: 0,
@@ -21,7 +21,7 @@ func (f *fakeOnchainProvider) ListERC20Transfers(_ context.Context, _ string, _,
 }
 
 func newTestOnchainService(provider OnchainProvider, now time.Time) *OnchainPackService {
-	svc := NewOnchainPackService(provider)
+	svc := NewOnchainPackService(map[string]OnchainProvider{"base": provider})
 	svc.now = func() time.Time { return now }
 	return svc
 }
@@ -232,3 +232,64 @@ func TestOnchainSummaryDeterministicWithProviderMock(t *testing.T) {
 		t.Fatalf("status=%s want=ok", resp.Status)
 	}
 }
+
+func TestOnchainQuickCheckAggregatesChains(t *testing.T) {
+	t.Parallel()
+
+	target := "0x5555555555555555555555555555555555555555"
+	token := "0x1111111111111111111111111111111111111111"
+	source := "0x6666666666666666666666666666666666666666"
+	base := &fakeOnchainProvider{events: []TransferEvent{
+		{TokenAddress: token, From: source, To: target, AmountRaw: "100"},
+	}}
+	arbitrum := &fakeOnchainProvider{events: []TransferEvent{
+		{TokenAddress: token, From: source, To: target, AmountRaw: "300"},
+		{TokenAddress: token, From: target, To: source, AmountRaw: "50"},
+	}}
+	polygon := &fakeOnchainProvider{err: errors.New("rpc down")}
+	svc := NewOnchainPackService(map[string]OnchainProvider{"base": base, "arbitrum": arbitrum, "polygon": polygon})
+	svc.now = func() time.Time { return time.Date(2026, 2, 18, 12, 0, 0, 0, time.UTC) }
+
+	if svc.HasProvider("bnb") || !svc.HasProvider("base") {
+		t.Fatalf("HasProvider should follow the configured providers")
+	}
+	if _, err := svc.BuildQuickCheck(context.Background(), OnchainQuickCheckRequest{Chain: "bsc", Address: target}); !errors.Is(err, ErrInvalidChain) {
+		t.Fatalf("chain without a provider: err=%v", err)
+	}
+	if _, err := svc.BuildQuickCheck(context.Background(), OnchainQuickCheckRequest{Chains: []string{"base", "solana"}, Address: target}); !errors.Is(err, ErrInvalidChain) {
+		t.Fatalf("unknown chain: err=%v", err)
+	}
+
+	resp, err := svc.BuildQuickCheck(context.Background(), OnchainQuickCheckRequest{
+		Chains:  []string{"ARB", "base", "arbitrum"},
+		Address: target,
+		Range:   "7d",
+	})
+	if err != nil {
+		t.Fatalf("BuildQuickCheck failed: %v", err)
+	}
+	if resp.Chain != "multi" || len(resp.Chains) != 2 || resp.Chains[0] != "base" || resp.Chains[1] != "arbitrum" {
+		t.Fatalf("chain=%s chains=%v want multi over base and arbitrum", resp.Chain, resp.Chains)
+	}
+	// The same token address counts once per chain.
+	if resp.Summary.TokenTransferCount != 3 || resp.Summary.UniqueTokenCount != 2 {
+		t.Fatalf("summary=%+v want 3 transfers of 2 chain tokens", resp.Summary)
+	}
+	if got := resp.Summary.TopIn[0]; got.Chain != "arbitrum" || got.Token != token || got.Amount != "300" {
+		t.Fatalf("top_in[0]=%+v want arbitrum token 300", got)
+	}
+	if len(resp.ChainResults) != 2 || resp.ChainResults[0].Summary.TopIn[0].Chain != "" {
+		t.Fatalf("per-chain results should be plain single-chain summaries: %+v", resp.ChainResults)
+	}
+
+	resp, err = svc.BuildQuickCheck(context.Background(), OnchainQuickCheckRequest{
+		Chains:  []string{"base", "polygon"},
+		Address: target,
+	})
+	if err != nil {
+		t.Fatalf("BuildQuickCheck failed: %v", err)
+	}
+	if resp.Status != "warning" || !warningExists(resp.Warnings, "PARTIAL_PROVIDER_FAILURE") || resp.ChainResults[1].Status != "error" {
+		t.Fatalf("partial failure: status=%s warnings=%+v results=%+v", resp.Status, resp.Warnings, resp.ChainResults)
+	}
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OutcomePeriodNextBubble prices a bubble at the user's next bubble on the
+// same symbol instead of after a fixed duration.
+const OutcomePeriodNextBubble = "next_bubble"
+
+const (
+	outcomeHorizonMin     = time.Minute
+	outcomeHorizonMax     = 52 * 7 * 24 * time.Hour
+	MaxUserOutcomeHorizon = 10
+)
+
+var (
+	ErrInvalidOutcomeHorizon  = errors.New("horizon must be like 15m, 4h, 3d, 1w (1m to 52w) or next_bubble")
+	ErrTooManyOutcomeHorizons = errors.New("too many outcome horizons")
+)
+
+// DefaultOutcomePeriods are computed for every user.
+var DefaultOutcomePeriods = []string{"1h", "4h", "1d"}
+
+var outcomeHorizonPattern = regexp.MustCompile(`^([0-9]{1,6})([mhdw])$`)
+
+var outcomeHorizonUnits = []struct {
+	suffix string
+	size   time.Duration
+}{
+	{"w", 7 * 24 * time.Hour},
+	{"d", 24 * time.Hour},
+	{"h", time.Hour},
+	{"m", time.Minute},
+}
+
+// OutcomeHorizon is a parsed outcome period. Duration is zero for
+// next_bubble.
+type OutcomeHorizon struct {
+	Period   string
+	Duration time.Duration
+}
+
+func (h OutcomeHorizon) UntilNextBubble() bool {
+	return h.Period == OutcomePeriodNextBubble
+}
+
+// ParseOutcomeHorizon accepts a count and unit (m, h, d, w) or next_bubble.
+// Periods are stored in the largest unit that divides them evenly, so 60m
+// and 1h are the same horizon.
+func ParseOutcomeHorizon(raw string) (OutcomeHorizon, error) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	if value == OutcomePeriodNextBubble {
+		return OutcomeHorizon{Period: OutcomePeriodNextBubble}, nil
+	}
+	match := outcomeHorizonPattern.FindStringSubmatch(value)
+	if match == nil {
+		return OutcomeHorizon{}, ErrInvalidOutcomeHorizon
+	}
+	count, _ := strconv.Atoi(match[1])
+	var duration time.Duration
+	for _, unit := range outcomeHorizonUnits {
+		if unit.suffix == match[2] {
+			duration = time.Duration(count) * unit.size
+		}
+	}
+	if duration < outcomeHorizonMin || duration > outcomeHorizonMax {
+		return OutcomeHorizon{}, ErrInvalidOutcomeHorizon
+	}
+	for _, unit := range outcomeHorizonUnits {
+		if duration%unit.size == 0 {
+			return OutcomeHorizon{
+				Period:   strconv.FormatInt(int64(duration/unit.size), 10) + unit.suffix,
+				Duration: duration,
+			}, nil
+		}
+	}
+	return OutcomeHorizon{}, ErrInvalidOutcomeHorizon
+}
+
+// NormalizeOutcomeHorizons parses, dedupes and bounds a user's horizon list.
+// The default periods are always computed and are dropped from the list.
+func NormalizeOutcomeHorizons(raw []string) ([]string, error) {
+	seen := make(map[string]bool, len(raw))
+	for _, period := range DefaultOutcomePeriods {
+		seen[period] = true
+	}
+	periods := make([]string, 0, len(raw))
+	for _, item := range raw {
+		horizon, err := ParseOutcomeHorizon(item)
+		if err != nil {
+			return nil, err
+		}
+		if seen[horizon.Period] {
+			continue
+		}
+		seen[horizon.Period] = true
+		periods = append(periods, horizon.Period)
+	}
+	if len(periods) > MaxUserOutcomeHorizon {
+		return nil, ErrTooManyOutcomeHorizons
+	}
+	return periods, nil
+}
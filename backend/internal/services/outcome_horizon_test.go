@@ -0,0 +1,56 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseOutcomeHorizon(t *testing.T) {
+	cases := []struct {
+		raw      string
+		period   string
+		duration time.Duration
+	}{
+		{"15m", "15m", 15 * time.Minute},
+		{" 60M ", "1h", time.Hour},
+		{"72h", "3d", 72 * time.Hour},
+		{"14d", "2w", 14 * 24 * time.Hour},
+		{"90m", "90m", 90 * time.Minute},
+		{"next_bubble", OutcomePeriodNextBubble, 0},
+	}
+	for _, tc := range cases {
+		horizon, err := ParseOutcomeHorizon(tc.raw)
+		if err != nil {
+			t.Fatalf("ParseOutcomeHorizon(%q): %v", tc.raw, err)
+		}
+		if horizon.Period != tc.period || horizon.Duration != tc.duration {
+			t.Fatalf("ParseOutcomeHorizon(%q) = %+v, want %s/%s", tc.raw, horizon, tc.period, tc.duration)
+		}
+	}
+
+	for _, raw := range []string{"", "0m", "1y", "53w", "1.5h", "next"} {
+		if _, err := ParseOutcomeHorizon(raw); err != ErrInvalidOutcomeHorizon {
+			t.Fatalf("expected %q to be rejected, got %v", raw, err)
+		}
+	}
+}
+
+func TestNormalizeOutcomeHorizons(t *testing.T) {
+	periods, err := NormalizeOutcomeHorizons([]string{"3d", "60m", "72h", "next_bubble", "15m"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"3d", "next_bubble", "15m"}
+	if len(periods) != len(want) {
+		t.Fatalf("expected %v, got %v", want, periods)
+	}
+	for i := range want {
+		if periods[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, periods)
+		}
+	}
+
+	if _, err := NormalizeOutcomeHorizons([]string{"2m", "3m", "5m", "6m", "7m", "8m", "9m", "10m", "11m", "12m", "13m"}); err != ErrTooManyOutcomeHorizons {
+		t.Fatalf("expected too many horizons, got %v", err)
+	}
+}
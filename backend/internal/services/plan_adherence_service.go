@@ -0,0 +1,417 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+const (
+	// Plans are often written a few minutes after the order goes in.
+	planFillLeadTime    = 10 * time.Minute
+	planClosedLookback  = 7 * 24 * time.Hour
+	planEvaluationBatch = 500
+	// A plan with no entry fill a week after it was written has expired.
+	planEntryExpiry = 7 * 24 * time.Hour
+)
+
+// Fills within half a percent of the stop or target count as at the level.
+var planPriceTolerance = big.NewRat(5, 1000)
+
+var ErrPlanNotFound = errors.New("plan not found")
+
+type planPositionReader interface {
+	GetByID(ctx context.Context, id, userID uuid.UUID) (*entities.ManualPosition, error)
+}
+
+// PlanAdherenceReport summarizes how closely fills followed their plans.
+type PlanAdherenceReport struct {
+	From             time.Time                 `json:"from"`
+	To               time.Time                 `json:"to"`
+	Plans            int                       `json:"plans"`
+	Executed         int                       `json:"executed"`
+	AvgScore         *string                   `json:"avg_score,omitempty"`
+	AvgEntrySlippage *string                   `json:"avg_entry_slippage_percent,omitempty"`
+	AvgSizeDeviation *string                   `json:"avg_abs_size_deviation_percent,omitempty"`
+	StopStatuses     map[string]int            `json:"stop_statuses"`
+	EarlyExits       int                       `json:"early_exits"`
+	ExitsScored      int                       `json:"exits_scored"`
+	Pending          int                       `json:"pending"`
+	Executions       []*entities.PlanExecution `json:"executions"`
+}
+
+// PlanAdherenceService matches synced fills to manual positions, which act as
+// pre-trade plans, and scores how well each plan was followed.
+type PlanAdherenceService struct {
+	repo      repositories.PlanExecutionRepository
+	positions planPositionReader
+	now       func() time.Time
+}
+
+func NewPlanAdherenceService(repo repositories.PlanExecutionRepository, positions planPositionReader) *PlanAdherenceService {
+	return &PlanAdherenceService{repo: repo, positions: positions, now: time.Now}
+}
+
+// EvaluatePlans rescores open plans and those closed in the last week, least
+// recently evaluated first so every plan gets a turn. Within a batch older
+// plans go first and so get first claim on shared fills.
+func (s *PlanAdherenceService) EvaluatePlans(ctx context.Context) error {
+	now := s.now().UTC()
+	plans, err := s.repo.ListPlansToEvaluate(ctx, now.Add(-planClosedLookback), planEvaluationBatch)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(plans, func(i, j int) bool { return plans[i].CreatedAt.Before(plans[j].CreatedAt) })
+	for _, plan := range plans {
+		if _, err := s.evaluate(ctx, plan, now); err != nil {
+			log.Printf("plan adherence: position %s: %v", plan.ID, err)
+		}
+	}
+	return nil
+}
+
+// Get returns the current evaluation of one plan, scoring it on demand.
+func (s *PlanAdherenceService) Get(ctx context.Context, userID, positionID uuid.UUID) (*entities.PlanExecution, error) {
+	plan, err := s.positions.GetByID(ctx, positionID, userID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && plan == nil) {
+		return nil, ErrPlanNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.evaluate(ctx, plan, s.now().UTC())
+}
+
+func (s *PlanAdherenceService) Report(ctx context.Context, userID uuid.UUID, from, to time.Time) (*PlanAdherenceReport, error) {
+	executions, err := s.repo.ListByUser(ctx, userID, from, to, true)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &PlanAdherenceReport{
+		From:         from,
+		To:           to,
+		StopStatuses: map[string]int{},
+		Executions:   []*entities.PlanExecution{},
+	}
+	var scoreSum, slippageSum, sizeSum big.Rat
+	scored, slipped, sized := 0, 0, 0
+	for _, execution := range executions {
+		report.Plans++
+		report.Executions = append(report.Executions, execution)
+		if execution.Status == entities.PlanExecutionPending {
+			report.Pending++
+			continue
+		}
+		report.Executed++
+		report.StopStatuses[string(execution.StopStatus)]++
+		if execution.Score != nil {
+			scoreSum.Add(&scoreSum, new(big.Rat).SetInt64(int64(*execution.Score)))
+			scored++
+		}
+		if execution.EntrySlippagePercent != nil {
+			if v := parseDecimal(*execution.EntrySlippagePercent); v != nil {
+				slippageSum.Add(&slippageSum, v)
+				slipped++
+			}
+		}
+		if execution.SizeDeviationPercent != nil {
+			if v := parseDecimal(*execution.SizeDeviationPercent); v != nil {
+				sizeSum.Add(&sizeSum, v.Abs(v))
+				sized++
+			}
+		}
+		if execution.EarlyExit != nil {
+			report.ExitsScored++
+			if *execution.EarlyExit {
+				report.EarlyExits++
+			}
+		}
+	}
+	report.AvgScore = optionalAverage(&scoreSum, scored, 2)
+	report.AvgEntrySlippage = optionalAverage(&slippageSum, slipped, 4)
+	report.AvgSizeDeviation = optionalAverage(&sizeSum, sized, 4)
+	return report, nil
+}
+
+func (s *PlanAdherenceService) evaluate(ctx context.Context, plan *entities.ManualPosition, now time.Time) (*entities.PlanExecution, error) {
+	previous, err := s.repo.GetByPosition(ctx, plan.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	symbolKey := PlanSymbolKey(plan.Symbol)
+	from := plan.CreatedAt
+	if plan.OpenedAt != nil && plan.OpenedAt.Before(from) {
+		from = *plan.OpenedAt
+	}
+	to := now
+	if plan.ClosedAt != nil && plan.ClosedAt.Add(planFillLeadTime).Before(to) {
+		to = plan.ClosedAt.Add(planFillLeadTime)
+	}
+
+	fills, err := s.repo.ListFills(ctx, plan.UserID, symbolKey, from.Add(-planFillLeadTime), to)
+	if err != nil {
+		return nil, err
+	}
+	claimed, err := s.repo.ListClaimedFillIDs(ctx, plan.UserID, symbolKey, plan.ID)
+	if err != nil {
+		return nil, err
+	}
+	fills = unclaimedFills(fills, claimed)
+
+	execution := EvaluatePlanExecution(plan, fills, previous, now)
+	execution.SymbolKey = symbolKey
+	if err := s.repo.Upsert(ctx, execution); err != nil {
+		return nil, err
+	}
+	return execution, nil
+}
+
+func unclaimedFills(fills []*repositories.PlanFill, claimed []uuid.UUID) []*repositories.PlanFill {
+	if len(claimed) == 0 {
+		return fills
+	}
+	taken := make(map[uuid.UUID]struct{}, len(claimed))
+	for _, id := range claimed {
+		taken[id] = struct{}{}
+	}
+	kept := make([]*repositories.PlanFill, 0, len(fills))
+	for _, fill := range fills {
+		if _, ok := taken[fill.ID]; !ok {
+			kept = append(kept, fill)
+		}
+	}
+	return kept
+}
+
+// EvaluatePlanExecution walks the fills in time order. Fills on the plan's
+// entry side build the position; fills on the other side close it, and any
+// excess beyond the open size is ignored. The plan's window closes once the
+// position is flat again, or when no entry has filled by planEntryExpiry, so
+// later round trips are left for other plans. The score starts at 100 and loses
+// points for adverse slippage, size deviation, a widened or violated stop and
+// an early exit.
+func EvaluatePlanExecution(plan *entities.ManualPosition, fills []*repositories.PlanFill, previous *entities.PlanExecution, now time.Time) *entities.PlanExecution {
+	execution := &entities.PlanExecution{
+		PositionID:  plan.ID,
+		UserID:      plan.UserID,
+		Status:      entities.PlanExecutionPending,
+		StopStatus:  entities.PlanStopNone,
+		EvaluatedAt: now,
+	}
+
+	short := strings.EqualFold(plan.PositionSide, "short")
+	entrySide, exitSide := "buy", "sell"
+	if short {
+		entrySide, exitSide = "sell", "buy"
+	}
+
+	var entryQty, entryNotional, exitQty, exitNotional big.Rat
+	var worstExit *big.Rat
+	expiresAt := plan.CreatedAt.Add(planEntryExpiry)
+	for _, fill := range fills {
+		if entryQty.Sign() > 0 && exitQty.Cmp(&entryQty) >= 0 {
+			break
+		}
+		if entryQty.Sign() == 0 && !plan.CreatedAt.IsZero() && fill.ExecutedAt.After(expiresAt) {
+			break
+		}
+		qty := parseDecimal(fill.Qty)
+		price := parseDecimal(fill.Price)
+		if qty == nil || price == nil || qty.Sign() <= 0 || price.Sign() <= 0 {
+			continue
+		}
+		switch strings.ToLower(fill.Side) {
+		case entrySide:
+			entryQty.Add(&entryQty, qty)
+			entryNotional.Add(&entryNotional, new(big.Rat).Mul(qty, price))
+		case exitSide:
+			open := new(big.Rat).Sub(&entryQty, &exitQty)
+			if open.Sign() <= 0 {
+				continue
+			}
+			if qty.Cmp(open) > 0 {
+				qty = open
+			}
+			exitQty.Add(&exitQty, qty)
+			exitNotional.Add(&exitNotional, new(big.Rat).Mul(qty, price))
+			if worstExit == nil || (short && price.Cmp(worstExit) > 0) || (!short && price.Cmp(worstExit) < 0) {
+				worstExit = price
+			}
+		default:
+			continue
+		}
+		execution.FillCount++
+		execution.FillIDs = append(execution.FillIDs, fill.ID)
+		executedAt := fill.ExecutedAt
+		if execution.FirstFillAt == nil {
+			execution.FirstFillAt = &executedAt
+		}
+		execution.LastFillAt = &executedAt
+	}
+
+	if entryQty.Sign() == 0 {
+		execution.FillCount = 0
+		execution.FillIDs = nil
+		execution.FirstFillAt = nil
+		execution.LastFillAt = nil
+		return execution
+	}
+
+	execution.Status = entities.PlanExecutionOpen
+	if exitQty.Cmp(&entryQty) >= 0 {
+		execution.Status = entities.PlanExecutionClosed
+	}
+	entryPrice := new(big.Rat).Quo(&entryNotional, &entryQty)
+	execution.EntryQty = decimalPtr(&entryQty, 8)
+	execution.EntryPrice = decimalPtr(entryPrice, 8)
+	var exitPrice *big.Rat
+	if exitQty.Sign() > 0 {
+		exitPrice = new(big.Rat).Quo(&exitNotional, &exitQty)
+		execution.ExitQty = decimalPtr(&exitQty, 8)
+		execution.ExitPrice = decimalPtr(exitPrice, 8)
+	}
+
+	score := big.NewRat(100, 1)
+	hundred := big.NewRat(100, 1)
+
+	if planned := optionalDecimal(plan.EntryPrice); planned != nil && planned.Sign() > 0 {
+		slippage := new(big.Rat).Quo(new(big.Rat).Sub(entryPrice, planned), planned)
+		if short {
+			slippage.Neg(slippage)
+		}
+		slippage.Mul(slippage, hundred)
+		execution.EntrySlippagePercent = decimalPtr(slippage, 4)
+		if slippage.Sign() > 0 {
+			// 10 points per percent of adverse slippage, at most 30.
+			score.Sub(score, minRat(new(big.Rat).Mul(slippage, big.NewRat(10, 1)), big.NewRat(30, 1)))
+		}
+	}
+
+	if planned := optionalDecimal(plan.Size); planned != nil && planned.Sign() > 0 {
+		deviation := new(big.Rat).Quo(new(big.Rat).Sub(&entryQty, planned), planned)
+		deviation.Mul(deviation, hundred)
+		execution.SizeDeviationPercent = decimalPtr(deviation, 4)
+		// Half a point per percent either way, at most 20.
+		penalty := new(big.Rat).Abs(deviation)
+		score.Sub(score, minRat(penalty.Mul(penalty, big.NewRat(1, 2)), big.NewRat(20, 1)))
+	}
+
+	stopAtEntry := optionalDecimal(plan.StopLoss)
+	if previous != nil && previous.StopAtEntry != nil {
+		stopAtEntry = parseDecimal(*previous.StopAtEntry)
+	}
+	if stopAtEntry != nil {
+		execution.StopAtEntry = decimalPtr(stopAtEntry, 8)
+		execution.StopStatus = entities.PlanStopHonored
+		if worstExit != nil && worseThan(worstExit, stopAtEntry, short) {
+			execution.StopStatus = entities.PlanStopViolated
+		} else if current := optionalDecimal(plan.StopLoss); current == nil {
+			execution.StopStatus = entities.PlanStopMoved
+		} else if cmp := current.Cmp(stopAtEntry); cmp != 0 {
+			widened := (cmp < 0 && !short) || (cmp > 0 && short)
+			execution.StopStatus = entities.PlanStopTrailed
+			if widened {
+				execution.StopStatus = entities.PlanStopMoved
+			}
+		}
+		switch execution.StopStatus {
+		case entities.PlanStopViolated:
+			score.Sub(score, big.NewRat(30, 1))
+		case entities.PlanStopMoved:
+			score.Sub(score, big.NewRat(25, 1))
+		}
+	}
+
+	if target := optionalDecimal(plan.TakeProfit); target != nil && exitPrice != nil {
+		// An exit short of the target that wasn't a stop-out, i.e. the stop
+		// sat clearly beyond the exit.
+		early := worseThan(exitPrice, target, short)
+		if early && stopAtEntry != nil && !worseThan(stopAtEntry, exitPrice, short) {
+			early = false
+		}
+		execution.EarlyExit = &early
+		if early {
+			score.Sub(score, big.NewRat(15, 1))
+		}
+	}
+
+	if score.Sign() < 0 {
+		score.SetInt64(0)
+	}
+	whole, _ := new(big.Float).SetRat(score).Int64()
+	points := int(whole)
+	execution.Score = &points
+	return execution
+}
+
+// PlanSymbolKey normalizes a symbol the way fills are matched: letters and
+// digits only, uppercase, with quote-first markets like KRW-BTC flipped to
+// BTCKRW.
+func PlanSymbolKey(raw string) string {
+	symbol := strings.ToUpper(strings.TrimSpace(raw))
+	if parts := strings.Split(symbol, "-"); len(parts) == 2 && isQuoteAsset(parts[0]) {
+		symbol = parts[1] + parts[0]
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, symbol)
+}
+
+func isQuoteAsset(asset string) bool {
+	switch asset {
+	case "KRW", "USDT", "USDC", "BTC", "ETH", "USD":
+		return true
+	}
+	return false
+}
+
+// worseThan reports whether price is past level by more than the tolerance
+// on the losing side for the position: below it for a long, above for a short.
+func worseThan(price, level *big.Rat, short bool) bool {
+	band := new(big.Rat).Mul(level, planPriceTolerance)
+	if short {
+		return price.Cmp(new(big.Rat).Add(level, band)) > 0
+	}
+	return price.Cmp(new(big.Rat).Sub(level, band)) < 0
+}
+
+func optionalDecimal(raw *string) *big.Rat {
+	if raw == nil {
+		return nil
+	}
+	return parseDecimal(*raw)
+}
+
+func decimalPtr(value *big.Rat, scale int) *string {
+	formatted := formatDecimal(value, scale)
+	return &formatted
+}
+
+func optionalAverage(sum *big.Rat, count, scale int) *string {
+	if count == 0 {
+		return nil
+	}
+	return decimalPtr(averageRat(sum, count), scale)
+}
+
+func minRat(a, b *big.Rat) *big.Rat {
+	if a.Cmp(b) < 0 {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+func planFill(side, qty, price string, at time.Time) *repositories.PlanFill {
+	return &repositories.PlanFill{Source: "trade", ID: uuid.New(), Side: side, Qty: qty, Price: price, ExecutedAt: at}
+}
+
+func TestEvaluatePlanExecutionFollowedPlan(t *testing.T) {
+	now := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	plan := &entities.ManualPosition{
+		ID: uuid.New(), PositionSide: "long",
+		EntryPrice: strPtr("100"), Size: strPtr("1"), StopLoss: strPtr("95"), TakeProfit: strPtr("110"),
+	}
+	fills := []*repositories.PlanFill{
+		planFill("buy", "1", "101", now.Add(-2*time.Hour)),
+		planFill("sell", "1", "110", now.Add(-time.Hour)),
+	}
+
+	execution := EvaluatePlanExecution(plan, fills, nil, now)
+	if execution.Status != entities.PlanExecutionClosed || execution.FillCount != 2 {
+		t.Fatalf("expected closed with 2 fills, got %s/%d", execution.Status, execution.FillCount)
+	}
+	if *execution.EntrySlippagePercent != "1" || *execution.SizeDeviationPercent != "0" {
+		t.Fatalf("unexpected slippage/size: %s %s", *execution.EntrySlippagePercent, *execution.SizeDeviationPercent)
+	}
+	if execution.StopStatus != entities.PlanStopHonored || *execution.EarlyExit {
+		t.Fatalf("expected honored stop without early exit, got %s %v", execution.StopStatus, *execution.EarlyExit)
+	}
+	if *execution.Score != 90 {
+		t.Fatalf("expected score 90, got %d", *execution.Score)
+	}
+}
+
+func TestEvaluatePlanExecutionMovedStopAndEarlyExit(t *testing.T) {
+	now := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	plan := &entities.ManualPosition{
+		ID: uuid.New(), PositionSide: "long",
+		EntryPrice: strPtr("100"), Size: strPtr("2"), StopLoss: strPtr("90"), TakeProfit: strPtr("110"),
+	}
+	previous := &entities.PlanExecution{StopAtEntry: strPtr("95")}
+	fills := []*repositories.PlanFill{
+		planFill("buy", "1", "100", now.Add(-2*time.Hour)),
+		planFill("sell", "3", "100", now.Add(-time.Hour)),
+	}
+
+	execution := EvaluatePlanExecution(plan, fills, previous, now)
+	if *execution.ExitQty != "1" {
+		t.Fatalf("expected exit capped at open size, got %s", *execution.ExitQty)
+	}
+	if execution.StopStatus != entities.PlanStopMoved || *execution.StopAtEntry != "95" {
+		t.Fatalf("expected moved stop from 95, got %s", execution.StopStatus)
+	}
+	if !*execution.EarlyExit {
+		t.Fatalf("expected early exit")
+	}
+	// 100 - 20 (size -50%) - 25 (moved) - 15 (early)
+	if *execution.Score != 40 {
+		t.Fatalf("expected score 40, got %d", *execution.Score)
+	}
+}
+
+func TestEvaluatePlanExecutionShortStopViolated(t *testing.T) {
+	now := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	plan := &entities.ManualPosition{
+		ID: uuid.New(), PositionSide: "short",
+		EntryPrice: strPtr("100"), StopLoss: strPtr("105"), TakeProfit: strPtr("90"),
+	}
+	fills := []*repositories.PlanFill{
+		planFill("sell", "1", "99", now.Add(-2*time.Hour)),
+		planFill("buy", "1", "110", now.Add(-time.Hour)),
+	}
+
+	execution := EvaluatePlanExecution(plan, fills, nil, now)
+	if *execution.EntrySlippagePercent != "1" {
+		t.Fatalf("expected selling 1%% under plan to count as adverse, got %s", *execution.EntrySlippagePercent)
+	}
+	if execution.StopStatus != entities.PlanStopViolated || *execution.EarlyExit {
+		t.Fatalf("expected violated stop and no early exit, got %s %v", execution.StopStatus, *execution.EarlyExit)
+	}
+	// 100 - 10 (1% slippage) - 30 (violated)
+	if *execution.Score != 60 {
+		t.Fatalf("expected score 60, got %d", *execution.Score)
+	}
+}
+
+func TestEvaluatePlanExecutionPending(t *testing.T) {
+	plan := &entities.ManualPosition{ID: uuid.New(), PositionSide: "long", EntryPrice: strPtr("100")}
+	fills := []*repositories.PlanFill{planFill("sell", "1", "100", time.Now())}
+
+	execution := EvaluatePlanExecution(plan, fills, nil, time.Now())
+	if execution.Status != entities.PlanExecutionPending || execution.FillCount != 0 || execution.Score != nil {
+		t.Fatalf("expected pending without score, got %+v", execution)
+	}
+}
+
+func TestEvaluatePlanExecutionWindowClosesWhenFlat(t *testing.T) {
+	now := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	plan := &entities.ManualPosition{ID: uuid.New(), PositionSide: "long", EntryPrice: strPtr("100")}
+	fills := []*repositories.PlanFill{
+		planFill("buy", "1", "100", now.Add(-4*time.Hour)),
+		planFill("sell", "1", "105", now.Add(-3*time.Hour)),
+		planFill("buy", "2", "120", now.Add(-2*time.Hour)),
+		planFill("sell", "2", "90", now.Add(-time.Hour)),
+	}
+
+	execution := EvaluatePlanExecution(plan, fills, nil, now)
+	if execution.Status != entities.PlanExecutionClosed || execution.FillCount != 2 || len(execution.FillIDs) != 2 {
+		t.Fatalf("expected only the first round trip, got %s/%d", execution.Status, execution.FillCount)
+	}
+	if *execution.EntryQty != "1" || *execution.ExitPrice != "105" {
+		t.Fatalf("later round trip leaked in: entry %s exit %s", *execution.EntryQty, *execution.ExitPrice)
+	}
+}
+
+func TestEvaluatePlanExecutionExpiresWithoutEntry(t *testing.T) {
+	created := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	plan := &entities.ManualPosition{ID: uuid.New(), PositionSide: "long", CreatedAt: created}
+	fills := []*repositories.PlanFill{planFill("buy", "1", "100", created.Add(planEntryExpiry+time.Hour))}
+
+	execution := EvaluatePlanExecution(plan, fills, nil, created.Add(planEntryExpiry+2*time.Hour))
+	if execution.Status != entities.PlanExecutionPending || execution.FillCount != 0 {
+		t.Fatalf("expected an expired plan to stay pending, got %s/%d", execution.Status, execution.FillCount)
+	}
+}
+
+type fakePlanExecutionRepo struct {
+	fills      []*repositories.PlanFill
+	executions map[uuid.UUID]*entities.PlanExecution
+}
+
+func (f *fakePlanExecutionRepo) ListPlansToEvaluate(context.Context, time.Time, int) ([]*entities.ManualPosition, error) {
+	return nil, nil
+}
+
+func (f *fakePlanExecutionRepo) ListFills(context.Context, uuid.UUID, string, time.Time, time.Time) ([]*repositories.PlanFill, error) {
+	return f.fills, nil
+}
+
+func (f *fakePlanExecutionRepo) ListClaimedFillIDs(_ context.Context, _ uuid.UUID, _ string, exclude uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for positionID, execution := range f.executions {
+		if positionID != exclude {
+			ids = append(ids, execution.FillIDs...)
+		}
+	}
+	return ids, nil
+}
+
+func (f *fakePlanExecutionRepo) Upsert(_ context.Context, execution *entities.PlanExecution) error {
+	f.executions[execution.PositionID] = execution
+	return nil
+}
+
+func (f *fakePlanExecutionRepo) GetByPosition(_ context.Context, positionID uuid.UUID) (*entities.PlanExecution, error) {
+	return f.executions[positionID], nil
+}
+
+func (f *fakePlanExecutionRepo) ListByUser(context.Context, uuid.UUID, time.Time, time.Time, bool) ([]*entities.PlanExecution, error) {
+	return nil, nil
+}
+
+type fakePlanPositions struct {
+	plans map[uuid.UUID]*entities.ManualPosition
+	err   error
+}
+
+func (f *fakePlanPositions) GetByID(_ context.Context, id, _ uuid.UUID) (*entities.ManualPosition, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	plan, ok := f.plans[id]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+	return plan, nil
+}
+
+func TestPlanAdherenceFillClaimedOnce(t *testing.T) {
+	now := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+	first := &entities.ManualPosition{ID: uuid.New(), UserID: userID, Symbol: "BTCUSDT", PositionSide: "long", CreatedAt: now.Add(-5 * time.Hour)}
+	second := &entities.ManualPosition{ID: uuid.New(), UserID: userID, Symbol: "BTCUSDT", PositionSide: "long", CreatedAt: now.Add(-5 * time.Hour)}
+	repo := &fakePlanExecutionRepo{
+		fills:      []*repositories.PlanFill{planFill("buy", "1", "100", now.Add(-4*time.Hour))},
+		executions: map[uuid.UUID]*entities.PlanExecution{},
+	}
+	svc := NewPlanAdherenceService(repo, &fakePlanPositions{plans: map[uuid.UUID]*entities.ManualPosition{first.ID: first, second.ID: second}})
+	svc.now = func() time.Time { return now }
+
+	a, err := svc.Get(context.Background(), userID, first.ID)
+	if err != nil || a.FillCount != 1 {
+		t.Fatalf("expected the first plan to claim the fill, got %+v %v", a, err)
+	}
+	b, err := svc.Get(context.Background(), userID, second.ID)
+	if err != nil || b.FillCount != 0 || b.Status != entities.PlanExecutionPending {
+		t.Fatalf("expected the second plan to skip the claimed fill, got %+v %v", b, err)
+	}
+}
+
+func TestPlanAdherenceGetErrors(t *testing.T) {
+	svc := NewPlanAdherenceService(&fakePlanExecutionRepo{}, &fakePlanPositions{})
+	if _, err := svc.Get(context.Background(), uuid.New(), uuid.New()); !errors.Is(err, ErrPlanNotFound) {
+		t.Fatalf("expected ErrPlanNotFound for a missing plan, got %v", err)
+	}
+
+	dbErr := errors.New("connection reset")
+	svc = NewPlanAdherenceService(&fakePlanExecutionRepo{}, &fakePlanPositions{err: dbErr})
+	if _, err := svc.Get(context.Background(), uuid.New(), uuid.New()); !errors.Is(err, dbErr) {
+		t.Fatalf("expected the database error to pass through, got %v", err)
+	}
+}
+
+func TestPlanSymbolKey(t *testing.T) {
+	cases := map[string]string{
+		"KRW-BTC":   "BTCKRW",
+		"btc/usdt":  "BTCUSDT",
+		" ETHUSDT ": "ETHUSDT",
+		"SOL-PERP":  "SOLPERP",
+	}
+	for raw, want := range cases {
+		if got := PlanSymbolKey(raw); got != want {
+			t.Fatalf("PlanSymbolKey(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
@@ -0,0 +1,448 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+const (
+	maxSetupNameLength     = 80
+	maxSetupChecklistItems = 20
+	maxChecklistItemLength = 200
+)
+
+var (
+	ErrSetupNotFound          = errors.New("setup not found")
+	ErrSetupNameRequired      = errors.New("name is required and must be at most 80 characters")
+	ErrInvalidSetupDirection  = errors.New("direction must be long or short")
+	ErrInvalidSetupDecimal    = errors.New("target_rr and risk_percent must be positive numbers")
+	ErrInvalidSetupChecklist  = errors.New("checklist allows up to 20 items of at most 200 characters")
+	ErrUnknownChecklistItem   = errors.New("checked items must come from the setup checklist")
+	ErrInvalidPlaybookSubject = errors.New("subject_type must be bubble, trade or manual_position")
+	ErrPlaybookLinkNotFound   = errors.New("linked record not found")
+)
+
+type SetupInput struct {
+	Name          string
+	Description   *string
+	Direction     string
+	EntryCriteria *string
+	Invalidation  *string
+	TargetRR      *string
+	RiskPercent   *string
+	Checklist     []string
+	Archived      *bool
+}
+
+type PlaybookStatsFilter struct {
+	SetupID       *uuid.UUID
+	OutcomePeriod string
+	Since         *time.Time
+	Symbol        string
+	Tag           string
+	AssetClass    string
+	Venue         string
+}
+
+// SetupAdherence measures how much of the checklist was confirmed when
+// records were linked, and whether following it paid off. Rates are 0–1.
+type SetupAdherence struct {
+	Checked         int    `json:"checked"`
+	Rate            string `json:"rate"`
+	FullyFollowed   int    `json:"fully_followed"`
+	FollowedWinRate string `json:"followed_win_rate"`
+	BrokenWinRate   string `json:"broken_win_rate"`
+}
+
+// SetupStats scores one setup. Win rate, expectancy and R come from bubble
+// outcomes signed by the setup direction, in percent; trades only add their
+// realized PnL since it is in quote currency rather than percent.
+type SetupStats struct {
+	SetupID         uuid.UUID      `json:"setup_id"`
+	Name            string         `json:"name"`
+	Direction       string         `json:"direction"`
+	Archived        bool           `json:"archived"`
+	Bubbles         int            `json:"bubbles"`
+	Trades          int            `json:"trades"`
+	ManualPositions int            `json:"manual_positions"`
+	Evaluated       int            `json:"evaluated"`
+	Wins            int            `json:"wins"`
+	Losses          int            `json:"losses"`
+	WinRate         string         `json:"win_rate"`
+	AvgWin          string         `json:"avg_win_percent"`
+	AvgLoss         string         `json:"avg_loss_percent"`
+	Expectancy      string         `json:"expectancy_percent"`
+	AvgR            *string        `json:"avg_r,omitempty"`
+	TargetRR        *string        `json:"target_rr,omitempty"`
+	TradeWins       int            `json:"trade_wins"`
+	TradeLosses     int            `json:"trade_losses"`
+	RealizedPnL     string         `json:"realized_pnl"`
+	Adherence       SetupAdherence `json:"adherence"`
+}
+
+// PlaybookService manages a user's trade setups, links journal records to
+// them and scores each setup.
+type PlaybookService struct {
+	repo repositories.PlaybookRepository
+}
+
+func NewPlaybookService(repo repositories.PlaybookRepository) *PlaybookService {
+	return &PlaybookService{repo: repo}
+}
+
+func (s *PlaybookService) List(ctx context.Context, userID uuid.UUID, includeArchived bool) ([]*entities.PlaybookSetup, error) {
+	return s.repo.List(ctx, userID, includeArchived)
+}
+
+func (s *PlaybookService) Get(ctx context.Context, userID, id uuid.UUID) (*entities.PlaybookSetup, error) {
+	setup, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if setup == nil {
+		return nil, ErrSetupNotFound
+	}
+	return setup, nil
+}
+
+func (s *PlaybookService) Create(ctx context.Context, userID uuid.UUID, input SetupInput) (*entities.PlaybookSetup, error) {
+	setup := &entities.PlaybookSetup{UserID: userID, Direction: entities.SetupDirectionLong}
+	if err := applySetupInput(setup, input, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, setup); err != nil {
+		return nil, err
+	}
+	return setup, nil
+}
+
+func (s *PlaybookService) Update(ctx context.Context, userID, id uuid.UUID, input SetupInput) (*entities.PlaybookSetup, error) {
+	setup, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applySetupInput(setup, input, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, setup); err != nil {
+		return nil, err
+	}
+	return setup, nil
+}
+
+func (s *PlaybookService) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	deleted, err := s.repo.Delete(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrSetupNotFound
+	}
+	return nil
+}
+
+// Link points a bubble, trade or manual position at a setup, recording which
+// checklist items the user confirmed. A nil setupID removes the link.
+func (s *PlaybookService) Link(ctx context.Context, userID uuid.UUID, subject entities.PlaybookSubject, subjectID uuid.UUID, setupID *uuid.UUID, checked []string) error {
+	switch subject {
+	case entities.PlaybookSubjectBubble, entities.PlaybookSubjectTrade, entities.PlaybookSubjectManualPosition:
+	default:
+		return ErrInvalidPlaybookSubject
+	}
+
+	var items []string
+	if setupID != nil {
+		setup, err := s.Get(ctx, userID, *setupID)
+		if err != nil {
+			return err
+		}
+		allowed := make(map[string]bool, len(setup.Checklist))
+		for _, item := range setup.Checklist {
+			allowed[item] = true
+		}
+		seen := map[string]bool{}
+		items = []string{}
+		for _, item := range checked {
+			item = strings.TrimSpace(item)
+			if !allowed[item] {
+				return ErrUnknownChecklistItem
+			}
+			if !seen[item] {
+				seen[item] = true
+				items = append(items, item)
+			}
+		}
+	}
+
+	found, err := s.repo.SetLink(ctx, userID, subject, subjectID, setupID, items)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrPlaybookLinkNotFound
+	}
+	return nil
+}
+
+// Stats scores every setup (or the one in filter.SetupID), including archived
+// ones, ordered by the number of evaluated bubbles.
+func (s *PlaybookService) Stats(ctx context.Context, userID uuid.UUID, filter PlaybookStatsFilter) ([]SetupStats, error) {
+	if _, err := ParseOutcomeHorizon(filter.OutcomePeriod); err != nil {
+		return nil, ErrInvalidOutcomePeriod
+	}
+
+	var setups []*entities.PlaybookSetup
+	if filter.SetupID != nil {
+		setup, err := s.Get(ctx, userID, *filter.SetupID)
+		if err != nil {
+			return nil, err
+		}
+		setups = []*entities.PlaybookSetup{setup}
+	} else {
+		var err error
+		if setups, err = s.repo.List(ctx, userID, true); err != nil {
+			return nil, err
+		}
+	}
+
+	samples, err := s.repo.ListSamples(ctx, userID, repositories.PlaybookSampleFilter{
+		SetupID:       filter.SetupID,
+		OutcomePeriod: filter.OutcomePeriod,
+		Since:         filter.Since,
+		Symbol:        filter.Symbol,
+		Tag:           filter.Tag,
+		AssetClass:    filter.AssetClass,
+		Venue:         filter.Venue,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bySetup := make(map[uuid.UUID][]*repositories.PlaybookSample, len(setups))
+	for _, sample := range samples {
+		bySetup[sample.SetupID] = append(bySetup[sample.SetupID], sample)
+	}
+
+	stats := make([]SetupStats, 0, len(setups))
+	for _, setup := range setups {
+		stats = append(stats, scoreSetup(setup, bySetup[setup.ID]))
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].Evaluated > stats[j].Evaluated
+	})
+	return stats, nil
+}
+
+func scoreSetup(setup *entities.PlaybookSetup, samples []*repositories.PlaybookSample) SetupStats {
+	stats := SetupStats{
+		SetupID:   setup.ID,
+		Name:      setup.Name,
+		Direction: string(setup.Direction),
+		Archived:  setup.ArchivedAt != nil,
+		TargetRR:  setup.TargetRR,
+	}
+
+	var winSum, lossSum, pnlSum, realized, adherenceSum big.Rat
+	var followed, broken qualityAccumulator
+	for _, sample := range samples {
+		switch sample.Subject {
+		case entities.PlaybookSubjectBubble:
+			stats.Bubbles++
+		case entities.PlaybookSubjectTrade:
+			stats.Trades++
+		case entities.PlaybookSubjectManualPosition:
+			stats.ManualPositions++
+		}
+
+		fraction, full, scored := checklistFraction(setup.Checklist, sample.Checked)
+		if scored {
+			stats.Adherence.Checked++
+			adherenceSum.Add(&adherenceSum, fraction)
+			if full {
+				stats.Adherence.FullyFollowed++
+			}
+		}
+
+		if sample.RealizedPnL != nil {
+			if pnl := parseDecimal(*sample.RealizedPnL); pnl != nil {
+				realized.Add(&realized, pnl)
+				switch pnl.Sign() {
+				case 1:
+					stats.TradeWins++
+				case -1:
+					stats.TradeLosses++
+				}
+			}
+		}
+
+		if sample.PnLPercent == nil {
+			continue
+		}
+		move := parseDecimal(*sample.PnLPercent)
+		if move == nil {
+			continue
+		}
+		if setup.Direction == entities.SetupDirectionShort {
+			move.Neg(move)
+		}
+		stats.Evaluated++
+		pnlSum.Add(&pnlSum, move)
+		win := move.Sign() > 0
+		if win {
+			stats.Wins++
+			winSum.Add(&winSum, move)
+		} else {
+			stats.Losses++
+			lossSum.Add(&lossSum, move)
+		}
+		if scored {
+			if full {
+				followed.add(win, move)
+			} else {
+				broken.add(win, move)
+			}
+		}
+	}
+
+	stats.WinRate = formatDecimal(ratioRat(stats.Wins, stats.Evaluated), 4)
+	stats.AvgWin = formatDecimal(averageRat(&winSum, stats.Wins), 4)
+	stats.AvgLoss = formatDecimal(averageRat(&lossSum, stats.Losses), 4)
+	// Expectancy is the average signed move per evaluated bubble, which is
+	// the same as win rate × avg win + loss rate × avg loss.
+	expectancy := averageRat(&pnlSum, stats.Evaluated)
+	stats.Expectancy = formatDecimal(expectancy, 4)
+	if setup.RiskPercent != nil && stats.Evaluated > 0 {
+		if risk := parseDecimal(*setup.RiskPercent); risk != nil && risk.Sign() > 0 {
+			avgR := formatDecimal(new(big.Rat).Quo(expectancy, risk), 4)
+			stats.AvgR = &avgR
+		}
+	}
+	stats.RealizedPnL = formatDecimal(&realized, 8)
+
+	stats.Adherence.Rate = formatDecimal(averageRat(&adherenceSum, stats.Adherence.Checked), 4)
+	stats.Adherence.FollowedWinRate = formatDecimal(followed.hitRate(), 4)
+	stats.Adherence.BrokenWinRate = formatDecimal(broken.hitRate(), 4)
+	return stats
+}
+
+// checklistFraction reports the share of the setup's current checklist that
+// was confirmed. Records without a checklist to follow aren't scored.
+func checklistFraction(checklist, checked []string) (*big.Rat, bool, bool) {
+	if len(checklist) == 0 || checked == nil {
+		return nil, false, false
+	}
+	confirmed := make(map[string]bool, len(checked))
+	for _, item := range checked {
+		confirmed[item] = true
+	}
+	hits := 0
+	for _, item := range checklist {
+		if confirmed[item] {
+			hits++
+		}
+	}
+	return big.NewRat(int64(hits), int64(len(checklist))), hits == len(checklist), true
+}
+
+func ratioRat(part, total int) *big.Rat {
+	if total == 0 {
+		return new(big.Rat)
+	}
+	return big.NewRat(int64(part), int64(total))
+}
+
+func averageRat(sum *big.Rat, count int) *big.Rat {
+	if count == 0 {
+		return new(big.Rat)
+	}
+	return new(big.Rat).Quo(sum, new(big.Rat).SetInt64(int64(count)))
+}
+
+func applySetupInput(setup *entities.PlaybookSetup, input SetupInput, now time.Time) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len([]rune(name)) > maxSetupNameLength {
+		return ErrSetupNameRequired
+	}
+
+	direction := setup.Direction
+	if raw := strings.ToLower(strings.TrimSpace(input.Direction)); raw != "" {
+		direction = entities.SetupDirection(raw)
+	}
+	if direction != entities.SetupDirectionLong && direction != entities.SetupDirectionShort {
+		return ErrInvalidSetupDirection
+	}
+
+	targetRR, err := positiveDecimal(input.TargetRR)
+	if err != nil {
+		return err
+	}
+	riskPercent, err := positiveDecimal(input.RiskPercent)
+	if err != nil {
+		return err
+	}
+
+	if len(input.Checklist) > maxSetupChecklistItems {
+		return ErrInvalidSetupChecklist
+	}
+	checklist := make([]string, 0, len(input.Checklist))
+	seen := map[string]bool{}
+	for _, item := range input.Checklist {
+		item = strings.TrimSpace(item)
+		if item == "" || len([]rune(item)) > maxChecklistItemLength {
+			return ErrInvalidSetupChecklist
+		}
+		if !seen[item] {
+			seen[item] = true
+			checklist = append(checklist, item)
+		}
+	}
+
+	setup.Name = name
+	setup.Direction = direction
+	setup.Description = trimmedOrNil(input.Description)
+	setup.EntryCriteria = trimmedOrNil(input.EntryCriteria)
+	setup.Invalidation = trimmedOrNil(input.Invalidation)
+	setup.TargetRR = targetRR
+	setup.RiskPercent = riskPercent
+	setup.Checklist = checklist
+	if input.Archived != nil {
+		switch {
+		case *input.Archived && setup.ArchivedAt == nil:
+			setup.ArchivedAt = &now
+		case !*input.Archived:
+			setup.ArchivedAt = nil
+		}
+	}
+	return nil
+}
+
+func positiveDecimal(raw *string) (*string, error) {
+	if raw == nil || strings.TrimSpace(*raw) == "" {
+		return nil, nil
+	}
+	value := strings.TrimSpace(*raw)
+	rat := parseDecimal(value)
+	if rat == nil || rat.Sign() <= 0 {
+		return nil, ErrInvalidSetupDecimal
+	}
+	return &value, nil
+}
+
+func trimmedOrNil(raw *string) *string {
+	if raw == nil {
+		return nil
+	}
+	value := strings.TrimSpace(*raw)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
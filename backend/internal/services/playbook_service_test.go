@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+type fakePlaybookRepo struct {
+	repositories.PlaybookRepository
+	setups  map[uuid.UUID]*entities.PlaybookSetup
+	samples []*repositories.PlaybookSample
+	linked  []string
+}
+
+func (r *fakePlaybookRepo) GetByID(_ context.Context, id, userID uuid.UUID) (*entities.PlaybookSetup, error) {
+	setup := r.setups[id]
+	if setup == nil || setup.UserID != userID {
+		return nil, nil
+	}
+	return setup, nil
+}
+
+func (r *fakePlaybookRepo) List(_ context.Context, userID uuid.UUID, _ bool) ([]*entities.PlaybookSetup, error) {
+	var out []*entities.PlaybookSetup
+	for _, setup := range r.setups {
+		if setup.UserID == userID {
+			out = append(out, setup)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakePlaybookRepo) SetLink(_ context.Context, _ uuid.UUID, _ entities.PlaybookSubject, _ uuid.UUID, _ *uuid.UUID, checked []string) (bool, error) {
+	r.linked = checked
+	return true, nil
+}
+
+func (r *fakePlaybookRepo) ListSamples(context.Context, uuid.UUID, repositories.PlaybookSampleFilter) ([]*repositories.PlaybookSample, error) {
+	return r.samples, nil
+}
+
+func strPtr(value string) *string { return &value }
+
+func TestPlaybookStatsScoresShortSetupAndAdherence(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	setup := &entities.PlaybookSetup{
+		ID: uuid.New(), UserID: userID, Name: "Failed breakout", Direction: entities.SetupDirectionShort,
+		RiskPercent: strPtr("2"), Checklist: []string{"volume spike", "wick above range"},
+	}
+	all := []string{"volume spike", "wick above range"}
+	repo := &fakePlaybookRepo{
+		setups: map[uuid.UUID]*entities.PlaybookSetup{setup.ID: setup},
+		samples: []*repositories.PlaybookSample{
+			// Price fell 4% on a short setup: a win of +4.
+			{SetupID: setup.ID, Subject: entities.PlaybookSubjectBubble, Checked: all, PnLPercent: strPtr("-4")},
+			{SetupID: setup.ID, Subject: entities.PlaybookSubjectBubble, Checked: []string{"volume spike"}, PnLPercent: strPtr("2")},
+			{SetupID: setup.ID, Subject: entities.PlaybookSubjectBubble, Checked: nil, PnLPercent: strPtr("-1")},
+			{SetupID: setup.ID, Subject: entities.PlaybookSubjectTrade, Checked: all, RealizedPnL: strPtr("-12.5")},
+			{SetupID: setup.ID, Subject: entities.PlaybookSubjectManualPosition},
+		},
+	}
+	svc := NewPlaybookService(repo)
+
+	stats, err := svc.Stats(context.Background(), userID, PlaybookStatsFilter{OutcomePeriod: "1h"})
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	got := stats[0]
+	if got.Bubbles != 3 || got.Trades != 1 || got.ManualPositions != 1 || got.Evaluated != 3 {
+		t.Fatalf("unexpected counts: %+v", got)
+	}
+	if got.Wins != 2 || got.Losses != 1 || got.WinRate != "0.6667" {
+		t.Fatalf("unexpected win rate: %+v", got)
+	}
+	// (4 + 1 - 2) / 3 = 1% per bubble, half of the 2% risk.
+	if got.Expectancy != "1" || got.AvgR == nil || *got.AvgR != "0.5" || got.AvgWin != "2.5" || got.AvgLoss != "-2" {
+		t.Fatalf("unexpected expectancy: %+v", got)
+	}
+	if got.TradeLosses != 1 || got.RealizedPnL != "-12.5" {
+		t.Fatalf("unexpected trade stats: %+v", got)
+	}
+	a := got.Adherence
+	if a.Checked != 3 || a.FullyFollowed != 2 || a.Rate != "0.8333" || a.FollowedWinRate != "1" || a.BrokenWinRate != "0" {
+		t.Fatalf("unexpected adherence: %+v", a)
+	}
+}
+
+func TestPlaybookLinkValidatesChecklistAndInput(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	setup := &entities.PlaybookSetup{ID: uuid.New(), UserID: userID, Name: "Trend pullback", Checklist: []string{"HTF trend up"}}
+	repo := &fakePlaybookRepo{setups: map[uuid.UUID]*entities.PlaybookSetup{setup.ID: setup}}
+	svc := NewPlaybookService(repo)
+
+	if err := svc.Link(context.Background(), userID, entities.PlaybookSubjectBubble, uuid.New(), &setup.ID, []string{" HTF trend up ", "HTF trend up"}); err != nil {
+		t.Fatalf("link: %v", err)
+	}
+	if len(repo.linked) != 1 {
+		t.Fatalf("expected one deduplicated checked item, got %v", repo.linked)
+	}
+	if err := svc.Link(context.Background(), userID, entities.PlaybookSubjectTrade, uuid.New(), &setup.ID, []string{"made up"}); !errors.Is(err, ErrUnknownChecklistItem) {
+		t.Fatalf("expected unknown checklist item, got %v", err)
+	}
+	other := uuid.New()
+	if err := svc.Link(context.Background(), other, entities.PlaybookSubjectTrade, uuid.New(), &setup.ID, nil); !errors.Is(err, ErrSetupNotFound) {
+		t.Fatalf("expected setup not found for another user, got %v", err)
+	}
+	if err := svc.Link(context.Background(), userID, "alert", uuid.New(), nil, nil); !errors.Is(err, ErrInvalidPlaybookSubject) {
+		t.Fatalf("expected invalid subject, got %v", err)
+	}
+
+	setupInput := SetupInput{Name: "x", TargetRR: strPtr("-1")}
+	if _, err := svc.Create(context.Background(), userID, setupInput); !errors.Is(err, ErrInvalidSetupDecimal) {
+		t.Fatalf("expected invalid decimal, got %v", err)
+	}
+	setupInput = SetupInput{Name: "x", Direction: "sideways"}
+	if _, err := svc.Create(context.Background(), userID, setupInput); !errors.Is(err, ErrInvalidSetupDirection) {
+		t.Fatalf("expected invalid direction, got %v", err)
+	}
+}
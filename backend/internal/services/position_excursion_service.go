@@ -0,0 +1,430 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+// Candles are read in one page: the finest interval that covers the holding
+// period in at most this many bars.
+const excursionMaxBars = 1000
+
+// Transient candle errors are retried after 15m, 30m, 1h, ... up to
+// excursionMaxAttempts tries before the position counts as unavailable.
+const (
+	excursionRetryBase   = 15 * time.Minute
+	excursionMaxAttempts = 6
+)
+
+var excursionIntervals = []string{"1m", "5m", "15m", "1h", "4h", "1d"}
+
+// ExcursionCandleReader is the slice of CandleStore excursions need.
+type ExcursionCandleReader interface {
+	Range(ctx context.Context, venue, symbol, interval string, start, end time.Time) ([]*entities.Candle, error)
+}
+
+type PositionExcursionService struct {
+	repo    repositories.PositionExcursionRepository
+	candles ExcursionCandleReader
+	now     func() time.Time
+}
+
+func NewPositionExcursionService(repo repositories.PositionExcursionRepository, candles ExcursionCandleReader) *PositionExcursionService {
+	return &PositionExcursionService{repo: repo, candles: candles, now: time.Now}
+}
+
+// ComputePending stores excursions for up to limit closed manual positions
+// and up to limit round trips that don't have a current one, and reports how
+// many it stored. A rate limit from the candle source ends the batch early;
+// other candle errors are stored for a later retry.
+func (s *PositionExcursionService) ComputePending(ctx context.Context, limit int) (int, error) {
+	now := s.now().UTC()
+	candidates, err := s.repo.ListPending(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	stored := 0
+	for _, candidate := range candidates {
+		excursion, err := s.compute(ctx, candidate)
+		if err != nil {
+			return stored, err
+		}
+		if err := s.repo.Upsert(ctx, excursion); err != nil {
+			return stored, err
+		}
+		stored++
+	}
+
+	trips, err := s.repo.ListPendingRoundTrips(ctx, now, limit)
+	if err != nil {
+		return stored, err
+	}
+	for _, trip := range trips {
+		excursion, err := s.computeRoundTrip(ctx, trip)
+		if err != nil {
+			return stored, err
+		}
+		if err := s.repo.SaveRoundTripExcursion(ctx, trip.ID, excursion); err != nil {
+			return stored, err
+		}
+		stored++
+	}
+	return stored, nil
+}
+
+// SyncRoundTrips rebuilds the round trips of up to users users from their
+// synced trades, least recently rebuilt first.
+func (s *PositionExcursionService) SyncRoundTrips(ctx context.Context, users int) error {
+	userIDs, err := s.repo.ListRoundTripUsers(ctx, users)
+	if err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		fills, err := s.repo.ListRoundTripFills(ctx, userID)
+		if err != nil {
+			log.Printf("position excursions: round trips for user %s: %v", userID, err)
+			continue
+		}
+		trips := BuildRoundTrips(userID, fills)
+		if err := s.repo.ReplaceRoundTrips(ctx, userID, trips, s.now().UTC()); err != nil {
+			log.Printf("position excursions: round trips for user %s: %v", userID, err)
+		}
+	}
+	return nil
+}
+
+func (s *PositionExcursionService) compute(ctx context.Context, candidate *repositories.ExcursionCandidate) (*entities.PositionExcursion, error) {
+	position := candidate.Position
+	start := position.CreatedAt
+	if position.OpenedAt != nil {
+		start = *position.OpenedAt
+	}
+	var end time.Time
+	if position.ClosedAt != nil {
+		end = *position.ClosedAt
+	}
+	excursion, err := s.measure(ctx, excursionRequest{
+		symbol:   position.Symbol,
+		short:    strings.EqualFold(position.PositionSide, "short"),
+		start:    start,
+		end:      end,
+		entry:    firstDecimal(candidate.FillEntry, position.EntryPrice),
+		exit:     firstDecimal(candidate.FillExit),
+		stop:     firstDecimal(candidate.StopAtEntry, position.StopLoss),
+		attempts: candidate.Attempts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	excursion.PositionID = position.ID
+	excursion.UserID = position.UserID
+	excursion.FillSignature = candidate.FillSignature
+	excursion.SourceUpdatedAt = position.UpdatedAt
+	return excursion, nil
+}
+
+func (s *PositionExcursionService) computeRoundTrip(ctx context.Context, trip *entities.TradeRoundTrip) (*entities.PositionExcursion, error) {
+	attempts := 0
+	if trip.Excursion != nil && trip.Excursion.Status == entities.ExcursionRetry {
+		attempts = trip.Excursion.Attempts
+	}
+	excursion, err := s.measure(ctx, excursionRequest{
+		symbol:   trip.Symbol,
+		short:    trip.PositionSide == "short",
+		start:    trip.OpenedAt,
+		end:      trip.ClosedAt,
+		entry:    parseDecimal(trip.EntryPrice),
+		exit:     parseDecimal(trip.ExitPrice),
+		attempts: attempts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	excursion.PositionID = trip.ID
+	excursion.UserID = trip.UserID
+	return excursion, nil
+}
+
+// excursionRequest is a holding period to measure. A nil entry or exit falls
+// back to the first open or last close of the candles; attempts counts
+// earlier transient failures.
+type excursionRequest struct {
+	symbol     string
+	short      bool
+	start, end time.Time
+	entry      *big.Rat
+	exit       *big.Rat
+	stop       *big.Rat
+	attempts   int
+}
+
+// measure reads candles for the holding period and computes the excursion.
+// Only a rate limit comes back as an error; other candle errors schedule a
+// retry.
+func (s *PositionExcursionService) measure(ctx context.Context, req excursionRequest) (*entities.PositionExcursion, error) {
+	excursion := &entities.PositionExcursion{
+		Status:     entities.ExcursionUnavailable,
+		ComputedAt: s.now().UTC(),
+	}
+	if !req.end.After(req.start) {
+		return excursion, nil
+	}
+	venue, symbol, ok := marketCandleSource(PlanSymbolKey(req.symbol))
+	if !ok {
+		return excursion, nil
+	}
+	interval := excursionInterval(req.end.Sub(req.start))
+	candles, err := s.candles.Range(ctx, venue, symbol, interval, req.start, req.end)
+	if err != nil {
+		var rateErr *CandleRateLimitError
+		if errors.As(err, &rateErr) {
+			return nil, err
+		}
+		log.Printf("position excursions: candles for %s %s: %v", venue, symbol, err)
+		scheduleExcursionRetry(excursion, req.attempts)
+		return excursion, nil
+	}
+	if len(candles) == 0 {
+		return excursion, nil
+	}
+
+	entry := req.entry
+	if entry == nil {
+		entry = parseDecimal(candles[0].Open)
+	}
+	exit := req.exit
+	if exit == nil {
+		exit = parseDecimal(candles[len(candles)-1].Close)
+	}
+	computed, ok := ComputeExcursion(req.short, entry, exit, req.stop, candles)
+	if !ok {
+		return excursion, nil
+	}
+	computed.CandleInterval = &interval
+	computed.ComputedAt = excursion.ComputedAt
+	return computed, nil
+}
+
+// scheduleExcursionRetry marks x for another try with exponential backoff
+// after attempts earlier failures. Once the attempts run out x stays
+// unavailable until its position or fills change.
+func scheduleExcursionRetry(x *entities.PositionExcursion, attempts int) {
+	x.Attempts = attempts + 1
+	if x.Attempts >= excursionMaxAttempts {
+		return
+	}
+	retryAfter := x.ComputedAt.Add(excursionRetryBase << (x.Attempts - 1))
+	x.Status = entities.ExcursionRetry
+	x.RetryAfter = &retryAfter
+}
+
+// BuildRoundTrips walks each exchange and symbol's fills in time order and
+// cuts a round trip every time the net position returns to flat. On futures
+// an exit larger than the open size flips the position and the excess opens
+// the next trip. Spot can't be short, so sells while flat are holdings from
+// before the sync and are skipped, as is any excess beyond the open size.
+// The trip still open at the end is left out.
+func BuildRoundTrips(userID uuid.UUID, fills []*repositories.RoundTripFill) []*entities.TradeRoundTrip {
+	var trips []*entities.TradeRoundTrip
+	var current *roundTripBuilder
+	var market string
+	for _, fill := range fills {
+		if key := fill.Exchange + "|" + fill.Symbol; key != market {
+			market, current = key, nil
+		}
+		qty := parseDecimal(fill.Qty)
+		price := parseDecimal(fill.Price)
+		if qty == nil || price == nil || qty.Sign() <= 0 || price.Sign() <= 0 {
+			continue
+		}
+		if fill.Side != "buy" && fill.Side != "sell" {
+			continue
+		}
+		shortable := strings.HasSuffix(fill.Exchange, "_futures")
+		for qty.Sign() > 0 {
+			if current == nil {
+				if fill.Side == "sell" && !shortable {
+					break
+				}
+				current = newRoundTripBuilder(fill)
+			}
+			qty = current.add(fill, qty, price)
+			if current.flat() {
+				trips = append(trips, current.build(userID))
+				current = nil
+			}
+			if !shortable {
+				break
+			}
+		}
+	}
+	return trips
+}
+
+// roundTripDust is the share of the entry size still counted as flat, for
+// spot fees taken from the base asset.
+var roundTripDust = big.NewRat(1, 1000)
+
+type roundTripBuilder struct {
+	exchange, symbol                               string
+	entrySide                                      string
+	openedAt, closedAt                             time.Time
+	entryQty, entryNotional, exitQty, exitNotional big.Rat
+	tradeIDs                                       []uuid.UUID
+	signature                                      strings.Builder
+}
+
+func newRoundTripBuilder(fill *repositories.RoundTripFill) *roundTripBuilder {
+	return &roundTripBuilder{exchange: fill.Exchange, symbol: fill.Symbol, entrySide: fill.Side, openedAt: fill.ExecutedAt}
+}
+
+// add applies up to qty of fill and returns the quantity left over.
+func (b *roundTripBuilder) add(fill *repositories.RoundTripFill, qty, price *big.Rat) *big.Rat {
+	used := qty
+	left := new(big.Rat)
+	if fill.Side == b.entrySide {
+		b.entryQty.Add(&b.entryQty, used)
+		b.entryNotional.Add(&b.entryNotional, new(big.Rat).Mul(used, price))
+	} else {
+		open := new(big.Rat).Sub(&b.entryQty, &b.exitQty)
+		if used.Cmp(open) > 0 {
+			left.Sub(used, open)
+			used = open
+		}
+		b.exitQty.Add(&b.exitQty, used)
+		b.exitNotional.Add(&b.exitNotional, new(big.Rat).Mul(used, price))
+		b.closedAt = fill.ExecutedAt
+	}
+	if len(b.tradeIDs) == 0 || b.tradeIDs[len(b.tradeIDs)-1] != fill.ID {
+		b.tradeIDs = append(b.tradeIDs, fill.ID)
+	}
+	b.signature.WriteString(fill.ID.String() + ":" + fill.Side + ":" + formatDecimal(used, 8) + ":" + fill.Price + ";")
+	return left
+}
+
+func (b *roundTripBuilder) flat() bool {
+	if b.exitQty.Sign() == 0 {
+		return false
+	}
+	open := new(big.Rat).Sub(&b.entryQty, &b.exitQty)
+	return open.Cmp(new(big.Rat).Mul(&b.entryQty, roundTripDust)) <= 0
+}
+
+func (b *roundTripBuilder) build(userID uuid.UUID) *entities.TradeRoundTrip {
+	side := "long"
+	if b.entrySide == "sell" {
+		side = "short"
+	}
+	sum := sha256.Sum256([]byte(b.signature.String()))
+	return &entities.TradeRoundTrip{
+		ID:            uuid.New(),
+		UserID:        userID,
+		Exchange:      b.exchange,
+		Symbol:        b.symbol,
+		PositionSide:  side,
+		OpenedAt:      b.openedAt,
+		ClosedAt:      b.closedAt,
+		Qty:           formatDecimal(&b.exitQty, 8),
+		EntryPrice:    formatDecimal(new(big.Rat).Quo(&b.entryNotional, &b.entryQty), 8),
+		ExitPrice:     formatDecimal(new(big.Rat).Quo(&b.exitNotional, &b.exitQty), 8),
+		TradeIDs:      b.tradeIDs,
+		FillSignature: hex.EncodeToString(sum[:]),
+	}
+}
+
+// ComputeExcursion measures the extremes of candles against entry. The exit
+// price counts as a traded price too, so a fill beyond the bar range still
+// shows up. The R fields stay nil unless stop is on the losing side of entry.
+func ComputeExcursion(short bool, entry, exit, stop *big.Rat, candles []*entities.Candle) (*entities.PositionExcursion, bool) {
+	if entry == nil || exit == nil || entry.Sign() <= 0 || exit.Sign() <= 0 {
+		return nil, false
+	}
+
+	low, high := new(big.Rat).Set(exit), new(big.Rat).Set(exit)
+	for _, candle := range candles {
+		if value := parseDecimal(candle.Low); value != nil && value.Sign() > 0 && value.Cmp(low) < 0 {
+			low = value
+		}
+		if value := parseDecimal(candle.High); value != nil && value.Sign() > 0 && value.Cmp(high) > 0 {
+			high = value
+		}
+	}
+	worst, best := low, high
+	if short {
+		worst, best = high, low
+	}
+
+	// signedMove is the favorable move from entry to price.
+	signedMove := func(price *big.Rat) *big.Rat {
+		move := new(big.Rat).Sub(price, entry)
+		if short {
+			move.Neg(move)
+		}
+		return move
+	}
+	percentOf := func(move *big.Rat) *big.Rat {
+		return new(big.Rat).Mul(new(big.Rat).Quo(move, entry), big.NewRat(100, 1))
+	}
+
+	adverse := signedMove(worst)
+	if adverse.Sign() > 0 {
+		adverse.SetInt64(0)
+	}
+	favorable := signedMove(best)
+	if favorable.Sign() < 0 {
+		favorable.SetInt64(0)
+	}
+	realized := signedMove(exit)
+
+	excursion := &entities.PositionExcursion{
+		Status:     entities.ExcursionReady,
+		EntryPrice: decimalPtr(entry, 8),
+		ExitPrice:  decimalPtr(exit, 8),
+		PnLPercent: decimalPtr(percentOf(realized), 4),
+		MAEPercent: decimalPtr(percentOf(adverse), 4),
+		MFEPercent: decimalPtr(percentOf(favorable), 4),
+		MAEPrice:   decimalPtr(worst, 8),
+		MFEPrice:   decimalPtr(best, 8),
+	}
+
+	if stop != nil && stop.Sign() > 0 {
+		excursion.StopPrice = decimalPtr(stop, 8)
+		risk := new(big.Rat).Neg(signedMove(stop))
+		if risk.Sign() > 0 {
+			excursion.RMultiple = decimalPtr(new(big.Rat).Quo(realized, risk), 4)
+			excursion.MAER = decimalPtr(new(big.Rat).Quo(adverse, risk), 4)
+			excursion.MFER = decimalPtr(new(big.Rat).Quo(favorable, risk), 4)
+		}
+	}
+	return excursion, true
+}
+
+func excursionInterval(holding time.Duration) string {
+	for _, interval := range excursionIntervals {
+		step, _ := CandleIntervalDuration(interval)
+		if holding/step < excursionMaxBars {
+			return interval
+		}
+	}
+	return excursionIntervals[len(excursionIntervals)-1]
+}
+
+func firstDecimal(values ...*string) *big.Rat {
+	for _, raw := range values {
+		if value := optionalDecimal(raw); value != nil && value.Sign() > 0 {
+			return value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+type fakeExcursionRepo struct {
+	repositories.PositionExcursionRepository
+	pending []*repositories.ExcursionCandidate
+	stored  []*entities.PositionExcursion
+}
+
+func (r *fakeExcursionRepo) ListPending(_ context.Context, _ time.Time, _ int) ([]*repositories.ExcursionCandidate, error) {
+	return r.pending, nil
+}
+
+func (r *fakeExcursionRepo) ListPendingRoundTrips(context.Context, time.Time, int) ([]*entities.TradeRoundTrip, error) {
+	return nil, nil
+}
+
+func (r *fakeExcursionRepo) Upsert(_ context.Context, excursion *entities.PositionExcursion) error {
+	r.stored = append(r.stored, excursion)
+	return nil
+}
+
+type fakeExcursionCandles struct {
+	candles  []*entities.Candle
+	err      error
+	interval string
+}
+
+func (f *fakeExcursionCandles) Range(_ context.Context, _, _, interval string, _, _ time.Time) ([]*entities.Candle, error) {
+	f.interval = interval
+	return f.candles, f.err
+}
+
+func excursionCandle(open, high, low, close string) *entities.Candle {
+	return &entities.Candle{Open: open, High: high, Low: low, Close: close}
+}
+
+func TestComputeExcursionLong(t *testing.T) {
+	candles := []*entities.Candle{
+		excursionCandle("100", "103", "97", "102"),
+		excursionCandle("102", "108", "99", "104"),
+	}
+	x, ok := ComputeExcursion(false, parseDecimal("100"), parseDecimal("104"), parseDecimal("95"), candles)
+	if !ok {
+		t.Fatalf("expected excursion")
+	}
+	checks := map[string]*string{
+		"-3": x.MAEPercent, "8": x.MFEPercent, "4": x.PnLPercent,
+		"0.8": x.RMultiple, "-0.6": x.MAER, "1.6": x.MFER,
+	}
+	for want, got := range checks {
+		if got == nil || *got != want {
+			t.Fatalf("expected %s, got %v", want, got)
+		}
+	}
+}
+
+func TestComputeExcursionShort(t *testing.T) {
+	candles := []*entities.Candle{excursionCandle("100", "105", "96", "98")}
+	x, ok := ComputeExcursion(true, parseDecimal("100"), parseDecimal("98"), parseDecimal("110"), candles)
+	if !ok {
+		t.Fatalf("expected excursion")
+	}
+	if *x.MAEPercent != "-5" || *x.MFEPercent != "4" || *x.MAEPrice != "105" || *x.MFEPrice != "96" {
+		t.Fatalf("unexpected excursion: mae %s mfe %s", *x.MAEPercent, *x.MFEPercent)
+	}
+	if *x.RMultiple != "0.2" || *x.MAER != "-0.5" || *x.MFER != "0.4" {
+		t.Fatalf("unexpected R: %s %s %s", *x.RMultiple, *x.MAER, *x.MFER)
+	}
+}
+
+func TestComputeExcursionStopOnWrongSideAndExitBeyondRange(t *testing.T) {
+	candles := []*entities.Candle{excursionCandle("100", "101", "95", "96")}
+	x, ok := ComputeExcursion(false, parseDecimal("100"), parseDecimal("90"), parseDecimal("101"), candles)
+	if !ok {
+		t.Fatalf("expected excursion")
+	}
+	if *x.MAEPercent != "-10" || *x.MFEPercent != "1" {
+		t.Fatalf("expected exit to set MAE, got %s / %s", *x.MAEPercent, *x.MFEPercent)
+	}
+	if x.StopPrice == nil || x.RMultiple != nil || x.MAER != nil {
+		t.Fatalf("expected stop recorded without R")
+	}
+}
+
+func TestExcursionInterval(t *testing.T) {
+	cases := map[time.Duration]string{
+		10 * time.Hour:       "1m",
+		48 * time.Hour:       "5m",
+		30 * 24 * time.Hour:  "1h",
+		365 * 24 * time.Hour: "1d",
+	}
+	for holding, want := range cases {
+		if got := excursionInterval(holding); got != want {
+			t.Fatalf("excursionInterval(%s) = %s, want %s", holding, got, want)
+		}
+	}
+}
+
+func TestComputePendingFallsBackToCandlePrices(t *testing.T) {
+	opened := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	closed := opened.Add(6 * time.Hour)
+	repo := &fakeExcursionRepo{pending: []*repositories.ExcursionCandidate{
+		{Position: &entities.ManualPosition{
+			ID: uuid.New(), UserID: uuid.New(), Symbol: "KRW-BTC", PositionSide: "long",
+			StopLoss: strPtr("90"), OpenedAt: &opened, ClosedAt: &closed, UpdatedAt: closed,
+		}},
+		{Position: &entities.ManualPosition{
+			ID: uuid.New(), UserID: uuid.New(), Symbol: "??", PositionSide: "long",
+			OpenedAt: &opened, ClosedAt: &closed,
+		}},
+	}}
+	candles := &fakeExcursionCandles{candles: []*entities.Candle{
+		excursionCandle("100", "112", "95", "110"),
+	}}
+	svc := NewPositionExcursionService(repo, candles)
+
+	stored, err := svc.ComputePending(context.Background(), 10)
+	if err != nil || stored != 2 {
+		t.Fatalf("expected 2 stored, got %d (%v)", stored, err)
+	}
+	ready := repo.stored[0]
+	if ready.Status != entities.ExcursionReady || *ready.EntryPrice != "100" || *ready.ExitPrice != "110" {
+		t.Fatalf("expected candle open/close fallback, got %+v", ready)
+	}
+	if *ready.RMultiple != "1" || *ready.CandleInterval != "1m" || candles.interval != "1m" {
+		t.Fatalf("unexpected R %s / interval %s", *ready.RMultiple, *ready.CandleInterval)
+	}
+	if !ready.SourceUpdatedAt.Equal(closed) {
+		t.Fatalf("expected source_updated_at from the position")
+	}
+	if repo.stored[1].Status != entities.ExcursionUnavailable {
+		t.Fatalf("expected unknown market to be unavailable")
+	}
+}
+
+func TestComputePendingRetriesTransientCandleErrors(t *testing.T) {
+	opened := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	closed := opened.Add(6 * time.Hour)
+	now := closed.Add(time.Hour)
+	repo := &fakeExcursionRepo{pending: []*repositories.ExcursionCandidate{
+		{Position: &entities.ManualPosition{
+			ID: uuid.New(), Symbol: "BTCUSDT", PositionSide: "long", OpenedAt: &opened, ClosedAt: &closed,
+		}, Attempts: 1},
+		{Position: &entities.ManualPosition{
+			ID: uuid.New(), Symbol: "BTCUSDT", PositionSide: "long", OpenedAt: &opened, ClosedAt: &closed,
+		}, Attempts: excursionMaxAttempts - 1},
+	}}
+	svc := NewPositionExcursionService(repo, &fakeExcursionCandles{err: errors.New("upstream 502")})
+	svc.now = func() time.Time { return now }
+
+	if _, err := svc.ComputePending(context.Background(), 10); err != nil {
+		t.Fatalf("expected transient errors to be stored, got %v", err)
+	}
+	retry := repo.stored[0]
+	if retry.Status != entities.ExcursionRetry || retry.Attempts != 2 || !retry.RetryAfter.Equal(now.Add(2*excursionRetryBase)) {
+		t.Fatalf("expected second retry with backoff, got %+v", retry)
+	}
+	if repo.stored[1].Status != entities.ExcursionUnavailable {
+		t.Fatalf("expected unavailable once attempts run out, got %s", repo.stored[1].Status)
+	}
+}
+
+func roundTripFill(exchange, side, qty, price string, at time.Time) *repositories.RoundTripFill {
+	return &repositories.RoundTripFill{ID: uuid.New(), Exchange: exchange, Symbol: "BTCUSDT", Side: side, Qty: qty, Price: price, ExecutedAt: at}
+}
+
+func TestBuildRoundTripsFuturesFlip(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	fills := []*repositories.RoundTripFill{
+		roundTripFill("binance_futures", "buy", "1", "100", start),
+		roundTripFill("binance_futures", "buy", "1", "110", start.Add(time.Hour)),
+		roundTripFill("binance_futures", "sell", "3", "120", start.Add(2*time.Hour)),
+		roundTripFill("binance_futures", "buy", "1", "115", start.Add(3*time.Hour)),
+	}
+
+	trips := BuildRoundTrips(uuid.New(), fills)
+	if len(trips) != 2 {
+		t.Fatalf("expected a long then a short, got %d trips", len(trips))
+	}
+	long, short := trips[0], trips[1]
+	if long.PositionSide != "long" || long.Qty != "2" || long.EntryPrice != "105" || long.ExitPrice != "120" || len(long.TradeIDs) != 3 {
+		t.Fatalf("unexpected long trip %+v", long)
+	}
+	if short.PositionSide != "short" || short.Qty != "1" || !short.OpenedAt.Equal(start.Add(2*time.Hour)) || short.ExitPrice != "115" {
+		t.Fatalf("unexpected short trip %+v", short)
+	}
+	if long.FillSignature == short.FillSignature {
+		t.Fatalf("expected distinct fill signatures")
+	}
+}
+
+func TestBuildRoundTripsSpot(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	fills := []*repositories.RoundTripFill{
+		roundTripFill("binance_spot", "sell", "1", "100", start),
+		roundTripFill("binance_spot", "buy", "1", "100", start.Add(time.Hour)),
+		roundTripFill("binance_spot", "sell", "0.9995", "110", start.Add(2*time.Hour)),
+		roundTripFill("binance_spot", "buy", "1", "105", start.Add(3*time.Hour)),
+	}
+
+	trips := BuildRoundTrips(uuid.New(), fills)
+	if len(trips) != 1 {
+		t.Fatalf("expected one closed spot trip, got %d", len(trips))
+	}
+	if trips[0].PositionSide != "long" || !trips[0].OpenedAt.Equal(start.Add(time.Hour)) || trips[0].ExitPrice != "110" {
+		t.Fatalf("expected the fee dust to count as flat, got %+v", trips[0])
+	}
+
+	again := BuildRoundTrips(uuid.New(), fills)
+	if again[0].FillSignature != trips[0].FillSignature {
+		t.Fatalf("expected a stable fill signature")
+	}
+}
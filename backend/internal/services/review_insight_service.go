@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+var ErrInvalidInsightBucket = errors.New("invalid insight bucket")
+
+const (
+	ReviewInsightBucketWeek  = "week"
+	ReviewInsightBucketMonth = "month"
+
+	// Below this many items with PnL a figure is reported as insufficient.
+	reviewInsightMinSample = 5
+	// From this many items on, a significant average counts as high confidence.
+	reviewInsightLargeSample = 30
+
+	reviewInsightZ = 1.96
+)
+
+// ReviewInsightPoint is one trend period. Period is the Monday of the week
+// (YYYY-MM-DD) or the month (YYYY-MM).
+type ReviewInsightPoint struct {
+	Period  string `json:"period"`
+	Count   int    `json:"count"`
+	WinRate string `json:"win_rate"`
+	AvgPnL  string `json:"avg_pnl"`
+}
+
+// ReviewInsightBucket aggregates answered review items sharing one key. Win
+// rate and average PnL only count items with PnL. The low/high bounds are
+// 95% intervals: Wilson for the win rate, normal approximation for the
+// average. Confidence says whether the average is distinguishable from zero:
+// insufficient (fewer than 5 items), low (interval spans zero), medium
+// (excludes zero) or high (excludes zero with 30+ items).
+type ReviewInsightBucket struct {
+	Key         string               `json:"key"`
+	Count       int                  `json:"count"`
+	WithPnL     int                  `json:"with_pnl"`
+	Wins        int                  `json:"wins"`
+	WinRate     string               `json:"win_rate"`
+	WinRateLow  string               `json:"win_rate_low"`
+	WinRateHigh string               `json:"win_rate_high"`
+	AvgPnL      string               `json:"avg_pnl"`
+	AvgPnLLow   string               `json:"avg_pnl_low"`
+	AvgPnLHigh  string               `json:"avg_pnl_high"`
+	TotalPnL    string               `json:"total_pnl"`
+	Confidence  string               `json:"confidence"`
+	Trend       []ReviewInsightPoint `json:"trend"`
+}
+
+type ReviewInsightReport struct {
+	From          string                `json:"from"`
+	To            string                `json:"to"`
+	Bucket        string                `json:"bucket"`
+	Overall       ReviewInsightBucket   `json:"overall"`
+	ByIntent      []ReviewInsightBucket `json:"by_intent"`
+	ByEmotion     []ReviewInsightBucket `json:"by_emotion"`
+	ByCombination []ReviewInsightBucket `json:"by_combination"`
+}
+
+type ReviewInsightService struct {
+	repo repositories.GuidedReviewRepository
+}
+
+func NewReviewInsightService(repo repositories.GuidedReviewRepository) *ReviewInsightService {
+	return &ReviewInsightService{repo: repo}
+}
+
+// Insights aggregates answered review items dated between fromDate and
+// toDate (YYYY-MM-DD, inclusive).
+func (s *ReviewInsightService) Insights(ctx context.Context, userID uuid.UUID, fromDate, toDate, bucket string) (*ReviewInsightReport, error) {
+	if bucket != ReviewInsightBucketWeek && bucket != ReviewInsightBucketMonth {
+		return nil, ErrInvalidInsightBucket
+	}
+	items, err := s.repo.ListAnsweredItems(ctx, userID, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	report := BuildReviewInsights(items, bucket)
+	report.From = fromDate
+	report.To = toDate
+	return report, nil
+}
+
+// BuildReviewInsights groups items by intent, by each emotion and by the full
+// emotion set together with the intent (e.g. "fomo + technical_signal").
+func BuildReviewInsights(items []*repositories.ReviewInsightItem, bucket string) *ReviewInsightReport {
+	overall := newInsightAccumulator()
+	byIntent := map[string]*insightAccumulator{}
+	byEmotion := map[string]*insightAccumulator{}
+	byCombination := map[string]*insightAccumulator{}
+
+	for _, item := range items {
+		period := insightPeriod(item.ReviewDate, bucket)
+		var pnl *big.Rat
+		if item.PnL != nil {
+			pnl = parseDecimal(*item.PnL)
+		}
+
+		emotions := insightEmotions(item.Emotions)
+		overall.add(period, pnl)
+		insightAccumulatorFor(byIntent, item.Intent).add(period, pnl)
+		for _, emotion := range emotions {
+			insightAccumulatorFor(byEmotion, emotion).add(period, pnl)
+		}
+		combination := strings.Join(append(emotions, item.Intent), " + ")
+		insightAccumulatorFor(byCombination, combination).add(period, pnl)
+	}
+
+	return &ReviewInsightReport{
+		Bucket:        bucket,
+		Overall:       overall.bucket("all"),
+		ByIntent:      sortedInsightBuckets(byIntent),
+		ByEmotion:     sortedInsightBuckets(byEmotion),
+		ByCombination: sortedInsightBuckets(byCombination),
+	}
+}
+
+// insightEmotions decodes the stored JSON array into a sorted, deduplicated
+// list.
+func insightEmotions(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var values []string
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		out = append(out, value)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func insightPeriod(reviewDate, bucket string) string {
+	date, err := time.Parse("2006-01-02", reviewDate)
+	if err != nil {
+		return reviewDate
+	}
+	if bucket == ReviewInsightBucketMonth {
+		return date.Format("2006-01")
+	}
+	offset := (int(date.Weekday()) + 6) % 7
+	return date.AddDate(0, 0, -offset).Format("2006-01-02")
+}
+
+type insightAccumulator struct {
+	stats   insightStats
+	periods map[string]*insightStats
+}
+
+type insightStats struct {
+	count int
+	pnls  []*big.Rat
+	wins  int
+	total big.Rat
+}
+
+func newInsightAccumulator() *insightAccumulator {
+	return &insightAccumulator{periods: map[string]*insightStats{}}
+}
+
+func (a *insightAccumulator) add(period string, pnl *big.Rat) {
+	a.stats.add(pnl)
+	stats, ok := a.periods[period]
+	if !ok {
+		stats = &insightStats{}
+		a.periods[period] = stats
+	}
+	stats.add(pnl)
+}
+
+func (s *insightStats) add(pnl *big.Rat) {
+	s.count++
+	if pnl == nil {
+		return
+	}
+	s.pnls = append(s.pnls, pnl)
+	if pnl.Sign() > 0 {
+		s.wins++
+	}
+	s.total.Add(&s.total, pnl)
+}
+
+func (s *insightStats) winRate() *big.Rat {
+	return ratioRat(s.wins, len(s.pnls))
+}
+
+func (s *insightStats) avgPnL() *big.Rat {
+	return averageRat(&s.total, len(s.pnls))
+}
+
+func (a *insightAccumulator) bucket(key string) ReviewInsightBucket {
+	s := &a.stats
+	n := len(s.pnls)
+	out := ReviewInsightBucket{
+		Key:      key,
+		Count:    s.count,
+		WithPnL:  n,
+		Wins:     s.wins,
+		WinRate:  formatDecimal(s.winRate(), 4),
+		AvgPnL:   formatDecimal(s.avgPnL(), 4),
+		TotalPnL: formatDecimal(&s.total, 4),
+		Trend:    []ReviewInsightPoint{},
+	}
+
+	winLow, winHigh := wilsonInterval(s.wins, n)
+	out.WinRateLow = formatInsightFloat(winLow)
+	out.WinRateHigh = formatInsightFloat(winHigh)
+
+	mean, _ := s.avgPnL().Float64()
+	margin := reviewInsightZ * sampleStdDev(s.pnls, mean) / math.Sqrt(math.Max(float64(n), 1))
+	out.AvgPnLLow = formatInsightFloat(mean - margin)
+	out.AvgPnLHigh = formatInsightFloat(mean + margin)
+
+	switch {
+	case n < reviewInsightMinSample:
+		out.Confidence = "insufficient"
+	case mean-margin <= 0 && mean+margin >= 0:
+		out.Confidence = "low"
+	case n < reviewInsightLargeSample:
+		out.Confidence = "medium"
+	default:
+		out.Confidence = "high"
+	}
+
+	periods := make([]string, 0, len(a.periods))
+	for period := range a.periods {
+		periods = append(periods, period)
+	}
+	sort.Strings(periods)
+	for _, period := range periods {
+		stats := a.periods[period]
+		out.Trend = append(out.Trend, ReviewInsightPoint{
+			Period:  period,
+			Count:   stats.count,
+			WinRate: formatDecimal(stats.winRate(), 4),
+			AvgPnL:  formatDecimal(stats.avgPnL(), 4),
+		})
+	}
+	return out
+}
+
+func insightAccumulatorFor(buckets map[string]*insightAccumulator, key string) *insightAccumulator {
+	acc, ok := buckets[key]
+	if !ok {
+		acc = newInsightAccumulator()
+		buckets[key] = acc
+	}
+	return acc
+}
+
+func sortedInsightBuckets(buckets map[string]*insightAccumulator) []ReviewInsightBucket {
+	out := make([]ReviewInsightBucket, 0, len(buckets))
+	for key, acc := range buckets {
+		out = append(out, acc.bucket(key))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+// wilsonInterval is the 95% Wilson score interval for wins out of n.
+func wilsonInterval(wins, n int) (float64, float64) {
+	if n == 0 {
+		return 0, 0
+	}
+	p := float64(wins) / float64(n)
+	nf := float64(n)
+	z2 := reviewInsightZ * reviewInsightZ
+	denom := 1 + z2/nf
+	center := (p + z2/(2*nf)) / denom
+	half := reviewInsightZ * math.Sqrt(p*(1-p)/nf+z2/(4*nf*nf)) / denom
+	return math.Max(0, center-half), math.Min(1, center+half)
+}
+
+func sampleStdDev(values []*big.Rat, mean float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	var sum float64
+	for _, value := range values {
+		v, _ := value.Float64()
+		sum += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
+
+func formatInsightFloat(value float64) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return "0"
+	}
+	return formatDecimal(new(big.Rat).SetFloat64(value), 4)
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+func insightItem(date, intent, emotions string, pnl *string) *repositories.ReviewInsightItem {
+	return &repositories.ReviewInsightItem{ReviewDate: date, Symbol: "BTCUSDT", Intent: intent, Emotions: json.RawMessage(emotions), PnL: pnl}
+}
+
+func findInsightBucket(buckets []ReviewInsightBucket, key string) *ReviewInsightBucket {
+	for i := range buckets {
+		if buckets[i].Key == key {
+			return &buckets[i]
+		}
+	}
+	return nil
+}
+
+func TestBuildReviewInsightsGroupsAndCombinations(t *testing.T) {
+	items := []*repositories.ReviewInsightItem{
+		insightItem("2026-03-02", "technical_signal", `["fomo"]`, strPtr("-10")),
+		insightItem("2026-03-03", "technical_signal", `["fomo","fomo"]`, strPtr("-20")),
+		insightItem("2026-03-10", "technical_signal", `["calm"]`, strPtr("30")),
+		insightItem("2026-03-11", "planned_regular", `["calm","as_planned"]`, strPtr("10")),
+		insightItem("2026-03-12", "planned_regular", ``, nil),
+	}
+
+	report := BuildReviewInsights(items, ReviewInsightBucketWeek)
+	if report.Overall.Count != 5 || report.Overall.WithPnL != 4 || report.Overall.WinRate != "0.5" || report.Overall.AvgPnL != "2.5" {
+		t.Fatalf("unexpected overall: %+v", report.Overall)
+	}
+
+	combo := findInsightBucket(report.ByCombination, "fomo + technical_signal")
+	if combo == nil || combo.Count != 2 || combo.WinRate != "0" || combo.AvgPnL != "-15" {
+		t.Fatalf("unexpected fomo combination: %+v", combo)
+	}
+	if findInsightBucket(report.ByCombination, "as_planned + calm + planned_regular") == nil {
+		t.Fatalf("expected sorted emotion combination with intent, got %+v", report.ByCombination)
+	}
+	if findInsightBucket(report.ByCombination, "planned_regular") == nil {
+		t.Fatalf("expected intent-only combination for items without emotions")
+	}
+
+	calm := findInsightBucket(report.ByEmotion, "calm")
+	if calm == nil || calm.Count != 2 || calm.WinRate != "1" {
+		t.Fatalf("unexpected calm bucket: %+v", calm)
+	}
+	if calm.Confidence != "insufficient" {
+		t.Fatalf("expected insufficient confidence for 2 items, got %s", calm.Confidence)
+	}
+
+	technical := findInsightBucket(report.ByIntent, "technical_signal")
+	if technical == nil || len(technical.Trend) != 2 {
+		t.Fatalf("expected two weekly trend points, got %+v", technical)
+	}
+	if technical.Trend[0].Period != "2026-03-02" || technical.Trend[0].AvgPnL != "-15" || technical.Trend[1].Period != "2026-03-09" {
+		t.Fatalf("unexpected trend: %+v", technical.Trend)
+	}
+}
+
+func TestBuildReviewInsightsConfidence(t *testing.T) {
+	var losing, mixed []*repositories.ReviewInsightItem
+	for i := 0; i < 6; i++ {
+		losing = append(losing, insightItem("2026-03-02", "emotional", `["revenge_trade"]`, strPtr("-10")))
+		pnl := "10"
+		if i%2 == 0 {
+			pnl = "-10"
+		}
+		mixed = append(mixed, insightItem("2026-03-02", "emotional", `["revenge_trade"]`, strPtr(pnl)))
+	}
+
+	report := BuildReviewInsights(losing, ReviewInsightBucketMonth)
+	if report.Overall.Confidence != "medium" || report.Overall.AvgPnLHigh != "-10" {
+		t.Fatalf("expected a consistent loss to be significant, got %+v", report.Overall)
+	}
+	if report.Overall.WinRateLow != "0" || report.Overall.WinRateHigh == "0" {
+		t.Fatalf("expected a Wilson interval above zero, got %s..%s", report.Overall.WinRateLow, report.Overall.WinRateHigh)
+	}
+	if report.Overall.Trend[0].Period != "2026-03" {
+		t.Fatalf("expected monthly period, got %s", report.Overall.Trend[0].Period)
+	}
+
+	report = BuildReviewInsights(mixed, ReviewInsightBucketWeek)
+	if report.Overall.Confidence != "low" {
+		t.Fatalf("expected low confidence when the interval spans zero, got %s", report.Overall.Confidence)
+	}
+}
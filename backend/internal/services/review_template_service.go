@@ -0,0 +1,311 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+var (
+	ErrInvalidReviewCadence   = errors.New("cadence must be daily, weekly or monthly")
+	ErrInvalidReviewTemplate  = errors.New("invalid review template")
+	ErrInvalidReviewAnswers   = errors.New("invalid review answers")
+	ErrReviewTemplateNotFound = errors.New("review template not found")
+)
+
+const (
+	maxReviewQuestions       = 20
+	maxReviewQuestionOptions = 20
+	defaultReviewTextLength  = 1000
+	maxReviewTextLength      = 4000
+)
+
+var reviewQuestionKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// The built-in layers keep their own columns, so templates can't reuse their
+// keys.
+var reservedReviewQuestionKeys = map[string]bool{
+	"intent":        true,
+	"emotions":      true,
+	"pattern_match": true,
+	"memo":          true,
+}
+
+type ReviewTemplateInput struct {
+	Cadence   string                    `json:"cadence"`
+	Name      string                    `json:"name"`
+	Questions []entities.ReviewQuestion `json:"questions"`
+}
+
+type ReviewTemplateService struct {
+	repo repositories.ReviewTemplateRepository
+	now  func() time.Time
+}
+
+func NewReviewTemplateService(repo repositories.ReviewTemplateRepository) *ReviewTemplateService {
+	return &ReviewTemplateService{repo: repo, now: time.Now}
+}
+
+// ValidReviewCadence reports whether cadence is one of the review cadences.
+func ValidReviewCadence(cadence string) bool {
+	switch cadence {
+	case entities.ReviewCadenceDaily, entities.ReviewCadenceWeekly, entities.ReviewCadenceMonthly:
+		return true
+	}
+	return false
+}
+
+// Save validates the questions and stores them as the next template version
+// for the cadence. Reviews created from then on use the new version.
+func (s *ReviewTemplateService) Save(ctx context.Context, userID uuid.UUID, input ReviewTemplateInput) (*entities.ReviewQuestionTemplate, error) {
+	cadence := strings.ToLower(strings.TrimSpace(input.Cadence))
+	if !ValidReviewCadence(cadence) {
+		return nil, ErrInvalidReviewCadence
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" || utf8.RuneCountInString(name) > 80 {
+		return nil, fmt.Errorf("%w: name is required and must be at most 80 characters", ErrInvalidReviewTemplate)
+	}
+	questions, err := NormalizeReviewQuestions(input.Questions)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &entities.ReviewQuestionTemplate{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Cadence:   cadence,
+		Name:      name,
+		Questions: questions,
+		CreatedAt: s.now().UTC(),
+	}
+	if err := s.repo.Create(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (s *ReviewTemplateService) Get(ctx context.Context, userID, id uuid.UUID) (*entities.ReviewQuestionTemplate, error) {
+	template, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return nil, ErrReviewTemplateNotFound
+	}
+	return template, nil
+}
+
+// Versions lists every version for the cadence, newest first.
+func (s *ReviewTemplateService) Versions(ctx context.Context, userID uuid.UUID, cadence string) ([]*entities.ReviewQuestionTemplate, error) {
+	if !ValidReviewCadence(cadence) {
+		return nil, ErrInvalidReviewCadence
+	}
+	templates, err := s.repo.ListVersions(ctx, userID, cadence)
+	if err != nil {
+		return nil, err
+	}
+	if templates == nil {
+		templates = []*entities.ReviewQuestionTemplate{}
+	}
+	return templates, nil
+}
+
+// ForReview returns the template version a review was created with, or nil
+// when it uses only the built-in questions.
+func (s *ReviewTemplateService) ForReview(ctx context.Context, review *entities.GuidedReview) (*entities.ReviewQuestionTemplate, error) {
+	if review == nil || review.TemplateID == nil {
+		return nil, nil
+	}
+	return s.repo.GetByID(ctx, review.UserID, *review.TemplateID)
+}
+
+// ValidateItemAnswers checks answers against the template of the item's
+// review and returns them normalized for storage.
+func (s *ReviewTemplateService) ValidateItemAnswers(ctx context.Context, userID, itemID uuid.UUID, answers map[string]any) (json.RawMessage, error) {
+	template, err := s.repo.GetForItem(ctx, userID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	var questions []entities.ReviewQuestion
+	if template != nil {
+		questions = template.Questions
+	}
+	normalized, err := ValidateReviewAnswers(questions, answers)
+	if err != nil {
+		return nil, err
+	}
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(normalized)
+}
+
+// NormalizeReviewQuestions trims labels and options and checks keys, types
+// and option lists.
+func NormalizeReviewQuestions(questions []entities.ReviewQuestion) ([]entities.ReviewQuestion, error) {
+	if len(questions) == 0 || len(questions) > maxReviewQuestions {
+		return nil, fmt.Errorf("%w: between 1 and %d questions are required", ErrInvalidReviewTemplate, maxReviewQuestions)
+	}
+	seen := make(map[string]bool, len(questions))
+	out := make([]entities.ReviewQuestion, 0, len(questions))
+	for _, question := range questions {
+		question.Key = strings.TrimSpace(question.Key)
+		question.Label = strings.TrimSpace(question.Label)
+		if !reviewQuestionKeyPattern.MatchString(question.Key) {
+			return nil, fmt.Errorf("%w: question key %q must be lowercase letters, digits or underscores", ErrInvalidReviewTemplate, question.Key)
+		}
+		if reservedReviewQuestionKeys[question.Key] {
+			return nil, fmt.Errorf("%w: question key %q is reserved", ErrInvalidReviewTemplate, question.Key)
+		}
+		if seen[question.Key] {
+			return nil, fmt.Errorf("%w: duplicate question key %q", ErrInvalidReviewTemplate, question.Key)
+		}
+		seen[question.Key] = true
+		if question.Label == "" || utf8.RuneCountInString(question.Label) > 200 {
+			return nil, fmt.Errorf("%w: question %q needs a label of at most 200 characters", ErrInvalidReviewTemplate, question.Key)
+		}
+
+		switch question.Type {
+		case entities.ReviewQuestionSingleChoice, entities.ReviewQuestionMultiChoice:
+			options, err := normalizeReviewOptions(question)
+			if err != nil {
+				return nil, err
+			}
+			question.Options = options
+			question.MaxLength = 0
+		case entities.ReviewQuestionText:
+			if len(question.Options) > 0 {
+				return nil, fmt.Errorf("%w: text question %q can't have options", ErrInvalidReviewTemplate, question.Key)
+			}
+			if question.MaxLength <= 0 {
+				question.MaxLength = defaultReviewTextLength
+			}
+			if question.MaxLength > maxReviewTextLength {
+				return nil, fmt.Errorf("%w: max_length is at most %d", ErrInvalidReviewTemplate, maxReviewTextLength)
+			}
+		default:
+			return nil, fmt.Errorf("%w: question type must be single_choice, multi_choice or text", ErrInvalidReviewTemplate)
+		}
+		out = append(out, question)
+	}
+	return out, nil
+}
+
+func normalizeReviewOptions(question entities.ReviewQuestion) ([]entities.ReviewQuestionOption, error) {
+	if len(question.Options) < 2 || len(question.Options) > maxReviewQuestionOptions {
+		return nil, fmt.Errorf("%w: question %q needs between 2 and %d options", ErrInvalidReviewTemplate, question.Key, maxReviewQuestionOptions)
+	}
+	seen := make(map[string]bool, len(question.Options))
+	options := make([]entities.ReviewQuestionOption, 0, len(question.Options))
+	for _, option := range question.Options {
+		option.Value = strings.TrimSpace(option.Value)
+		option.Label = strings.TrimSpace(option.Label)
+		if option.Value == "" || utf8.RuneCountInString(option.Value) > 50 || seen[option.Value] {
+			return nil, fmt.Errorf("%w: options of %q need unique values of at most 50 characters", ErrInvalidReviewTemplate, question.Key)
+		}
+		seen[option.Value] = true
+		if option.Label == "" {
+			option.Label = option.Value
+		}
+		options = append(options, option)
+	}
+	return options, nil
+}
+
+// ValidateReviewAnswers checks answers against the questions: choices must
+// be listed options, text is trimmed and length-limited, and required
+// questions must be answered. Empty answers are dropped.
+func ValidateReviewAnswers(questions []entities.ReviewQuestion, answers map[string]any) (map[string]any, error) {
+	byKey := make(map[string]entities.ReviewQuestion, len(questions))
+	for _, question := range questions {
+		byKey[question.Key] = question
+	}
+
+	normalized := make(map[string]any, len(answers))
+	for key, raw := range answers {
+		question, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown question %q", ErrInvalidReviewAnswers, key)
+		}
+		value, err := normalizeReviewAnswer(question, raw)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			normalized[key] = value
+		}
+	}
+	for _, question := range questions {
+		if _, ok := normalized[question.Key]; question.Required && !ok {
+			return nil, fmt.Errorf("%w: %q is required", ErrInvalidReviewAnswers, question.Key)
+		}
+	}
+	return normalized, nil
+}
+
+func normalizeReviewAnswer(question entities.ReviewQuestion, raw any) (any, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	allowed := make(map[string]bool, len(question.Options))
+	for _, option := range question.Options {
+		allowed[option.Value] = true
+	}
+
+	switch question.Type {
+	case entities.ReviewQuestionSingleChoice:
+		value, ok := raw.(string)
+		if !ok || (value != "" && !allowed[value]) {
+			return nil, fmt.Errorf("%w: %q must be one of its options", ErrInvalidReviewAnswers, question.Key)
+		}
+		if value == "" {
+			return nil, nil
+		}
+		return value, nil
+	case entities.ReviewQuestionMultiChoice:
+		list, ok := raw.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q must be a list of its options", ErrInvalidReviewAnswers, question.Key)
+		}
+		seen := make(map[string]bool, len(list))
+		values := make([]string, 0, len(list))
+		for _, item := range list {
+			value, ok := item.(string)
+			if !ok || !allowed[value] {
+				return nil, fmt.Errorf("%w: %q must be a list of its options", ErrInvalidReviewAnswers, question.Key)
+			}
+			if !seen[value] {
+				seen[value] = true
+				values = append(values, value)
+			}
+		}
+		if len(values) == 0 {
+			return nil, nil
+		}
+		return values, nil
+	case entities.ReviewQuestionText:
+		value, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q must be text", ErrInvalidReviewAnswers, question.Key)
+		}
+		value = strings.TrimSpace(value)
+		if utf8.RuneCountInString(value) > question.MaxLength {
+			return nil, fmt.Errorf("%w: %q is longer than %d characters", ErrInvalidReviewAnswers, question.Key, question.MaxLength)
+		}
+		if value == "" {
+			return nil, nil
+		}
+		return value, nil
+	}
+	return nil, fmt.Errorf("%w: %q has an unknown type", ErrInvalidReviewAnswers, question.Key)
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+func reviewQuestions() []entities.ReviewQuestion {
+	return []entities.ReviewQuestion{
+		{Key: "setup_quality", Label: " Setup quality ", Type: entities.ReviewQuestionSingleChoice, Required: true,
+			Options: []entities.ReviewQuestionOption{{Value: "a"}, {Value: "b", Label: "B grade"}}},
+		{Key: "mistakes", Label: "Mistakes", Type: entities.ReviewQuestionMultiChoice,
+			Options: []entities.ReviewQuestionOption{{Value: "late_entry"}, {Value: "no_stop"}}},
+		{Key: "lesson", Label: "Lesson", Type: entities.ReviewQuestionText},
+	}
+}
+
+func TestNormalizeReviewQuestions(t *testing.T) {
+	questions, err := NormalizeReviewQuestions(reviewQuestions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if questions[0].Label != "Setup quality" || questions[0].Options[0].Label != "a" {
+		t.Fatalf("expected trimmed label and defaulted option label, got %+v", questions[0])
+	}
+	if questions[2].MaxLength != defaultReviewTextLength {
+		t.Fatalf("expected default text length, got %d", questions[2].MaxLength)
+	}
+
+	invalid := map[string][]entities.ReviewQuestion{
+		"reserved key":    {{Key: "intent", Label: "Intent", Type: entities.ReviewQuestionText}},
+		"bad key":         {{Key: "Setup Quality", Label: "x", Type: entities.ReviewQuestionText}},
+		"duplicate key":   {{Key: "a", Label: "x", Type: entities.ReviewQuestionText}, {Key: "a", Label: "y", Type: entities.ReviewQuestionText}},
+		"one option":      {{Key: "a", Label: "x", Type: entities.ReviewQuestionSingleChoice, Options: []entities.ReviewQuestionOption{{Value: "only"}}}},
+		"text options":    {{Key: "a", Label: "x", Type: entities.ReviewQuestionText, Options: []entities.ReviewQuestionOption{{Value: "1"}, {Value: "2"}}}},
+		"unknown type":    {{Key: "a", Label: "x", Type: "scale"}},
+		"empty questions": nil,
+	}
+	for name, questions := range invalid {
+		if _, err := NormalizeReviewQuestions(questions); !errors.Is(err, ErrInvalidReviewTemplate) {
+			t.Errorf("%s: expected ErrInvalidReviewTemplate, got %v", name, err)
+		}
+	}
+}
+
+func TestValidateReviewAnswers(t *testing.T) {
+	questions, _ := NormalizeReviewQuestions(reviewQuestions())
+
+	answers, err := ValidateReviewAnswers(questions, map[string]any{
+		"setup_quality": "b",
+		"mistakes":      []any{"no_stop", "no_stop"},
+		"lesson":        "  wait for the close  ",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{"setup_quality": "b", "mistakes": []string{"no_stop"}, "lesson": "wait for the close"}
+	if !reflect.DeepEqual(answers, expected) {
+		t.Fatalf("unexpected answers: %#v", answers)
+	}
+
+	invalid := []map[string]any{
+		{"mistakes": []any{"late_entry"}},
+		{"setup_quality": "c"},
+		{"setup_quality": "a", "mistakes": "late_entry"},
+		{"setup_quality": "a", "unknown": "x"},
+	}
+	for _, input := range invalid {
+		if _, err := ValidateReviewAnswers(questions, input); !errors.Is(err, ErrInvalidReviewAnswers) {
+			t.Errorf("%v: expected ErrInvalidReviewAnswers, got %v", input, err)
+		}
+	}
+
+	if _, err := ValidateReviewAnswers(nil, map[string]any{"lesson": "x"}); !errors.Is(err, ErrInvalidReviewAnswers) {
+		t.Fatalf("expected answers to be rejected without a template, got %v", err)
+	}
+	if answers, err := ValidateReviewAnswers(nil, nil); err != nil || len(answers) != 0 {
+		t.Fatalf("expected no answers without a template, got %v %v", answers, err)
+	}
+}
+
+func TestReviewPeriod(t *testing.T) {
+	day := time.Date(2026, 3, 5, 15, 0, 0, 0, time.UTC) // Thursday
+	cases := map[string][2]string{
+		entities.ReviewCadenceDaily:   {"2026-03-05", "2026-03-05"},
+		entities.ReviewCadenceWeekly:  {"2026-03-02", "2026-03-08"},
+		entities.ReviewCadenceMonthly: {"2026-03-01", "2026-03-31"},
+	}
+	for cadence, want := range cases {
+		start, end, ok := entities.ReviewPeriod(cadence, day)
+		if !ok || start.Format("2006-01-02") != want[0] || end.Format("2006-01-02") != want[1] {
+			t.Errorf("%s: got %s..%s", cadence, start.Format("2006-01-02"), end.Format("2006-01-02"))
+		}
+	}
+	if _, _, ok := entities.ReviewPeriod("yearly", day); ok {
+		t.Fatalf("expected unknown cadence to be rejected")
+	}
+}
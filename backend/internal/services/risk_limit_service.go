@@ -0,0 +1,495 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/infrastructure/notification"
+)
+
+const (
+	// riskLimitBackfillDays bounds how far back an import of old trades is
+	// evaluated. Only breaches on the current trading day are notified.
+	riskLimitBackfillDays = 31
+	riskLimitRuleName     = "Risk limits"
+)
+
+// disciplinePenalties is what each breached limit costs the daily
+// discipline score, which starts at 100.
+var disciplinePenalties = []struct {
+	limit   entities.RiskLimitType
+	penalty int
+}{
+	{entities.RiskLimitDailyLoss, 30},
+	{entities.RiskLimitLossCooldown, 25},
+	{entities.RiskLimitTradesPerDay, 15},
+	{entities.RiskLimitPositionNotional, 15},
+	{entities.RiskLimitLeverage, 15},
+}
+
+// RiskLimitService checks trades against the user's risk limits, raises an
+// urgent alert for each new breach and records a daily discipline score.
+type RiskLimitService struct {
+	repo       repositories.RiskLimitRepository
+	userRepo   repositories.UserRepository
+	alertRepo  repositories.AlertRepository
+	sender     notification.Sender
+	appBaseURL string
+	now        func() time.Time
+}
+
+func NewRiskLimitService(
+	repo repositories.RiskLimitRepository,
+	userRepo repositories.UserRepository,
+	alertRepo repositories.AlertRepository,
+	sender notification.Sender,
+) *RiskLimitService {
+	appURL := os.Getenv("APP_BASE_URL")
+	if appURL == "" {
+		appURL = "http://localhost:5173"
+	}
+	return &RiskLimitService{
+		repo:       repo,
+		userRepo:   userRepo,
+		alertRepo:  alertRepo,
+		sender:     sender,
+		appBaseURL: appURL,
+		now:        time.Now,
+	}
+}
+
+// ValidateRiskLimitConfig checks that every limit that is set is positive
+// and that the loss cooldown is set as a pair.
+func ValidateRiskLimitConfig(cfg entities.RiskLimitConfig) error {
+	for name, raw := range map[string]*string{
+		"max_daily_loss":        cfg.MaxDailyLoss,
+		"max_position_notional": cfg.MaxPositionNotional,
+		"max_leverage":          cfg.MaxLeverage,
+	} {
+		if raw == nil {
+			continue
+		}
+		if riskLimitValue(raw) == nil {
+			return fmt.Errorf("%s must be a positive number", name)
+		}
+	}
+	if cfg.MaxTradesPerDay != nil && *cfg.MaxTradesPerDay <= 0 {
+		return fmt.Errorf("max_trades_per_day must be positive")
+	}
+	if (cfg.CooldownAfterLosses == nil) != (cfg.CooldownMinutes == nil) {
+		return fmt.Errorf("cooldown_after_losses and cooldown_minutes must be set together")
+	}
+	if cfg.CooldownAfterLosses != nil && (*cfg.CooldownAfterLosses <= 0 || *cfg.CooldownMinutes <= 0) {
+		return fmt.Errorf("cooldown_after_losses and cooldown_minutes must be positive")
+	}
+	if cfg.CooldownMinutes != nil && *cfg.CooldownMinutes > 1440 {
+		return fmt.Errorf("cooldown_minutes must be at most 1440")
+	}
+	return nil
+}
+
+// GetLimits returns the user's risk limit rule, or nil if none is set.
+func (s *RiskLimitService) GetLimits(ctx context.Context, userID uuid.UUID) (*entities.AlertRule, error) {
+	return s.repo.GetRule(ctx, userID)
+}
+
+// SaveLimits creates or replaces the user's risk limits.
+func (s *RiskLimitService) SaveLimits(ctx context.Context, userID uuid.UUID, cfg entities.RiskLimitConfig, enabled bool) (*entities.AlertRule, error) {
+	if err := ValidateRiskLimitConfig(cfg); err != nil {
+		return nil, err
+	}
+	config, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	rule := &entities.AlertRule{
+		UserID:               userID,
+		Name:                 riskLimitRuleName,
+		Symbol:               "*",
+		RuleType:             entities.RuleTypeRiskLimit,
+		Config:               config,
+		ExpiryMinutes:        entities.DefaultAlertExpiryMinutes,
+		EscalateAfterMinutes: entities.DefaultEscalateAfterMinutes,
+		Enabled:              enabled,
+	}
+	if err := s.repo.SaveRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *RiskLimitService) ListBreaches(ctx context.Context, userID uuid.UUID, fromDate, toDate string) ([]*entities.RiskLimitBreach, error) {
+	return s.repo.ListBreaches(ctx, userID, fromDate, toDate)
+}
+
+func (s *RiskLimitService) ListDisciplineScores(ctx context.Context, userID uuid.UUID, fromDate, toDate string) ([]*entities.DisciplineScore, error) {
+	return s.repo.ListDisciplineScores(ctx, userID, fromDate, toDate)
+}
+
+// QueueEvaluation asks the risk limit job to re-check the user's trading
+// days from the one containing since. It is called after new trades are
+// stored.
+func (s *RiskLimitService) QueueEvaluation(ctx context.Context, userID uuid.UUID, since time.Time) error {
+	return s.repo.QueueEvaluation(ctx, userID, since, s.now().UTC())
+}
+
+// EvaluatePending runs the queued checks of up to limit users and reports
+// how many it completed. A failed user stays queued and the rest still run.
+func (s *RiskLimitService) EvaluatePending(ctx context.Context, limit int) (int, error) {
+	evaluations, err := s.repo.ListPendingEvaluations(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+	done := 0
+	for _, evaluation := range evaluations {
+		if err := s.Evaluate(ctx, evaluation.UserID, evaluation.Since); err != nil {
+			log.Printf("risk limits: user %s: %v", evaluation.UserID, err)
+			continue
+		}
+		if err := s.repo.CompleteEvaluation(ctx, evaluation, s.now().UTC()); err != nil {
+			log.Printf("risk limits: complete user %s: %v", evaluation.UserID, err)
+			continue
+		}
+		done++
+	}
+	return done, nil
+}
+
+// Evaluate re-checks every trading day from the one containing since through
+// today. Positions are read once at the start and rolled forward through the
+// fills; the trading day before since is replayed first so a loss streak or
+// cooldown carried into it is not lost.
+func (s *RiskLimitService) Evaluate(ctx context.Context, userID uuid.UUID, since time.Time) error {
+	rule, err := s.repo.GetRule(ctx, userID)
+	if err != nil || rule == nil || !rule.Enabled {
+		return err
+	}
+	var cfg entities.RiskLimitConfig
+	if err := json.Unmarshal(rule.Config, &cfg); err != nil {
+		return fmt.Errorf("risk limit config: %w", err)
+	}
+
+	day := entities.NewTradingDay("UTC", 0)
+	if s.userRepo != nil {
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if user != nil {
+			day = user.TradingDay()
+		}
+	}
+
+	now := s.now().UTC()
+	if earliest := tradingDaysBack(day, now, riskLimitBackfillDays); since.Before(earliest) {
+		since = earliest
+	}
+	today := day.Date(now)
+	warmup := day.Start(since).AddDate(0, 0, -1).Format("2006-01-02")
+	from, _, err := day.Bounds(warmup)
+	if err != nil {
+		return err
+	}
+	_, to, err := day.Bounds(today)
+	if err != nil {
+		return err
+	}
+
+	opening, err := s.repo.NetPositions(ctx, userID, from)
+	if err != nil {
+		return err
+	}
+	fills, err := s.repo.ListFills(ctx, userID, from, to)
+	if err != nil {
+		return err
+	}
+	byDay := make(map[string][]*repositories.RiskFill)
+	for _, fill := range fills {
+		date := day.Date(fill.ExecutedAt)
+		byDay[date] = append(byDay[date], fill)
+	}
+
+	state := NewRiskLimitState(opening)
+	EvaluateRiskLimits(cfg, byDay[warmup], state)
+	for date := day.Start(since); date.Format("2006-01-02") <= today; date = date.AddDate(0, 0, 1) {
+		key := date.Format("2006-01-02")
+		dayFills := byDay[key]
+		if len(dayFills) == 0 {
+			continue
+		}
+		breaches := EvaluateRiskLimits(cfg, dayFills, state)
+		if err := s.recordDay(ctx, rule, key, dayFills, breaches, key == today); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *RiskLimitService) recordDay(ctx context.Context, rule *entities.AlertRule, date string, fills []*repositories.RiskFill, breaches []*entities.RiskLimitBreach, notify bool) error {
+	for _, breach := range breaches {
+		breach.UserID = rule.UserID
+		breach.TradeDate = date
+		created, err := s.repo.CreateBreach(ctx, breach)
+		if err != nil {
+			return err
+		}
+		if created && notify {
+			s.raiseAlert(ctx, rule, breach)
+		}
+	}
+
+	score, limits := DisciplineScoreFor(breaches)
+	return s.repo.UpsertDisciplineScore(ctx, &entities.DisciplineScore{
+		UserID:         rule.UserID,
+		TradeDate:      date,
+		Score:          score,
+		TradeCount:     riskOrderCount(fills),
+		BreachedLimits: limits,
+		ComputedAt:     s.now().UTC(),
+	})
+}
+
+func (s *RiskLimitService) raiseAlert(ctx context.Context, rule *entities.AlertRule, breach *entities.RiskLimitBreach) {
+	if s.alertRepo == nil {
+		return
+	}
+	now := s.now().UTC()
+	alert := &entities.Alert{
+		ID:            uuid.New(),
+		UserID:        rule.UserID,
+		RuleID:        rule.ID,
+		Symbol:        breach.Symbol,
+		TriggerPrice:  breach.Price,
+		TriggerReason: riskLimitReason(breach),
+		Severity:      entities.AlertSeverityUrgent,
+		Status:        entities.AlertStatusPending,
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(rule.AlertExpiry()),
+	}
+	if err := s.alertRepo.Create(ctx, alert); err != nil {
+		log.Printf("risk limits: create alert failed (user=%s): %v", rule.UserID, err)
+		return
+	}
+	if err := s.repo.SetBreachAlert(ctx, breach.ID, alert.ID); err != nil {
+		log.Printf("risk limits: link alert %s failed: %v", alert.ID, err)
+	}
+	log.Printf("risk limits: breached [%s] %s", breach.Symbol, alert.TriggerReason)
+
+	if s.sender == nil {
+		return
+	}
+	msg := notification.Message{
+		Title:    alert.TriggerReason,
+		Body:     fmt.Sprintf("거래일: %s\n발생: %s", breach.TradeDate, breach.TriggeredAt.UTC().Format("2006-01-02 15:04 UTC")),
+		Severity: string(alert.Severity),
+		DeepLink: fmt.Sprintf("%s/alerts/%s", s.appBaseURL, alert.ID.String()),
+		AlertID:  &alert.ID,
+	}
+	if err := s.sender.Send(ctx, alert.UserID, msg); err != nil {
+		log.Printf("risk limits: send notification failed: %v", err)
+	} else {
+		_ = s.alertRepo.SetNotified(ctx, alert.ID)
+	}
+}
+
+func riskLimitReason(breach *entities.RiskLimitBreach) string {
+	switch breach.LimitType {
+	case entities.RiskLimitDailyLoss:
+		return fmt.Sprintf("일일 손실 한도 초과: -%s (한도 %s)", breach.Observed, breach.Limit)
+	case entities.RiskLimitTradesPerDay:
+		return fmt.Sprintf("일일 거래 횟수 한도 초과: %s회 (한도 %s회)", breach.Observed, breach.Limit)
+	case entities.RiskLimitPositionNotional:
+		return fmt.Sprintf("%s 포지션 규모 한도 초과: %s (한도 %s)", breach.Symbol, breach.Observed, breach.Limit)
+	case entities.RiskLimitLeverage:
+		return fmt.Sprintf("%s 레버리지 한도 초과: %sx (한도 %sx)", breach.Symbol, breach.Observed, breach.Limit)
+	case entities.RiskLimitLossCooldown:
+		return fmt.Sprintf("연속 손실 후 쿨다운 중 거래: 손실 %s분 후 (쿨다운 %s분)", breach.Observed, breach.Limit)
+	default:
+		return fmt.Sprintf("리스크 한도 초과: %s", breach.LimitType)
+	}
+}
+
+// RiskLimitState is what one trading day hands to the next: open positions
+// keyed by repositories.RiskPositionKey, and the loss streak behind the
+// cooldown with the order that started it.
+type RiskLimitState struct {
+	Positions     map[string]*big.Rat
+	LossStreak    int
+	LastLossAt    time.Time
+	CooldownUntil time.Time
+	CooldownOrder string
+}
+
+// NewRiskLimitState starts from the given open positions, as decimal text.
+func NewRiskLimitState(opening map[string]string) *RiskLimitState {
+	positions := make(map[string]*big.Rat, len(opening))
+	for key, qty := range opening {
+		if value := parseDecimal(qty); value != nil {
+			positions[key] = value
+		}
+	}
+	return &RiskLimitState{Positions: positions}
+}
+
+// EvaluateRiskLimits checks one trading day's fills, oldest first, and rolls
+// state forward past them. Each limit is reported once per scope, at the fill
+// that first broke it. The daily trade limit and the loss streak count
+// orders, not fills: an order's realized PnL is summed over its fills and
+// scored at its last fill here. The loss streak and any cooldown carry over
+// from earlier days in state.
+func EvaluateRiskLimits(cfg entities.RiskLimitConfig, fills []*repositories.RiskFill, state *RiskLimitState) []*entities.RiskLimitBreach {
+	maxLoss := riskLimitValue(cfg.MaxDailyLoss)
+	maxNotional := riskLimitValue(cfg.MaxPositionNotional)
+	maxLeverage := riskLimitValue(cfg.MaxLeverage)
+	if state == nil {
+		state = NewRiskLimitState(nil)
+	}
+
+	seen := map[string]bool{}
+	var breaches []*entities.RiskLimitBreach
+	add := func(limit entities.RiskLimitType, scope string, fill *repositories.RiskFill, observed, threshold *big.Rat) {
+		key := string(limit) + "|" + scope
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		breaches = append(breaches, &entities.RiskLimitBreach{
+			LimitType:   limit,
+			Scope:       scope,
+			Symbol:      fill.SymbolKey,
+			Observed:    formatDecimal(observed, 8),
+			Limit:       formatDecimal(threshold, 8),
+			Price:       fill.Price,
+			TriggeredAt: fill.ExecutedAt,
+		})
+	}
+
+	lastFill := make(map[string]int, len(fills))
+	for i, fill := range fills {
+		lastFill[riskOrderKey(fill)] = i
+	}
+
+	realized := new(big.Rat)
+	orderPnL := map[string]*big.Rat{}
+	orders := map[string]bool{}
+	for i, fill := range fills {
+		order := riskOrderKey(fill)
+		qty := parseDecimal(fill.Qty)
+		if qty == nil {
+			qty = new(big.Rat)
+		}
+		price := parseDecimal(fill.Price)
+		if price == nil {
+			price = new(big.Rat)
+		}
+
+		if cfg.CooldownMinutes != nil && fill.ExecutedAt.Before(state.CooldownUntil) && order != state.CooldownOrder {
+			since := big.NewRat(int64(fill.ExecutedAt.Sub(state.LastLossAt)/time.Second), 60)
+			add(entities.RiskLimitLossCooldown, "", fill, since, big.NewRat(int64(*cfg.CooldownMinutes), 1))
+		}
+		orders[order] = true
+		if cfg.MaxTradesPerDay != nil && len(orders) > *cfg.MaxTradesPerDay {
+			add(entities.RiskLimitTradesPerDay, "", fill, big.NewRat(int64(len(orders)), 1), big.NewRat(int64(*cfg.MaxTradesPerDay), 1))
+		}
+
+		if fill.RealizedPnL != nil {
+			if pnl := parseDecimal(*fill.RealizedPnL); pnl != nil {
+				realized.Add(realized, pnl)
+				if orderPnL[order] == nil {
+					orderPnL[order] = new(big.Rat)
+				}
+				orderPnL[order].Add(orderPnL[order], pnl)
+			}
+		}
+		if pnl := orderPnL[order]; pnl != nil && lastFill[order] == i {
+			switch pnl.Sign() {
+			case -1:
+				state.LossStreak++
+				state.LastLossAt = fill.ExecutedAt
+				if cfg.CooldownAfterLosses != nil && cfg.CooldownMinutes != nil && state.LossStreak >= *cfg.CooldownAfterLosses {
+					state.CooldownUntil = fill.ExecutedAt.Add(time.Duration(*cfg.CooldownMinutes) * time.Minute)
+					state.CooldownOrder = order
+				}
+			case 1:
+				state.LossStreak = 0
+			}
+		}
+		if loss := new(big.Rat).Neg(realized); maxLoss != nil && loss.Cmp(maxLoss) > 0 {
+			add(entities.RiskLimitDailyLoss, "", fill, loss, maxLoss)
+		}
+
+		position := repositories.RiskPositionKey(fill.Market, fill.SymbolKey)
+		net := state.Positions[position]
+		if net == nil {
+			net = new(big.Rat)
+			state.Positions[position] = net
+		}
+		if fill.Side == "buy" {
+			net.Add(net, qty)
+		} else {
+			net.Sub(net, qty)
+		}
+		notional := new(big.Rat).Mul(new(big.Rat).Abs(net), price)
+		if maxNotional != nil && notional.Cmp(maxNotional) > 0 {
+			add(entities.RiskLimitPositionNotional, position, fill, notional, maxNotional)
+		}
+		if leverage := riskLimitValue(fill.Leverage); maxLeverage != nil && leverage != nil && leverage.Cmp(maxLeverage) > 0 {
+			add(entities.RiskLimitLeverage, fill.SymbolKey, fill, leverage, maxLeverage)
+		}
+	}
+	return breaches
+}
+
+// riskOrderCount is how many orders the fills belong to.
+func riskOrderCount(fills []*repositories.RiskFill) int {
+	orders := make(map[string]bool, len(fills))
+	for _, fill := range fills {
+		orders[riskOrderKey(fill)] = true
+	}
+	return len(orders)
+}
+
+// riskOrderKey keeps order ids of different symbols apart; exchanges only
+// make them unique per symbol.
+func riskOrderKey(fill *repositories.RiskFill) string {
+	return fill.Market + ":" + fill.SymbolKey + ":" + fill.OrderID
+}
+
+// DisciplineScoreFor scores a trading day from its breaches and lists the
+// limits that were broken.
+func DisciplineScoreFor(breaches []*entities.RiskLimitBreach) (int, []entities.RiskLimitType) {
+	broken := map[entities.RiskLimitType]bool{}
+	for _, breach := range breaches {
+		broken[breach.LimitType] = true
+	}
+	score := 100
+	limits := []entities.RiskLimitType{}
+	for _, p := range disciplinePenalties {
+		if broken[p.limit] {
+			score -= p.penalty
+			limits = append(limits, p.limit)
+		}
+	}
+	if score < 0 {
+		score = 0
+	}
+	return score, limits
+}
+
+// riskLimitValue parses an optional limit or leverage, returning nil when it
+// is missing or not positive.
+func riskLimitValue(raw *string) *big.Rat {
+	value := optionalDecimal(raw)
+	if value == nil || value.Sign() <= 0 {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func riskFill(symbol, side, qty, price string, pnl *string, at time.Time) *repositories.RiskFill {
+	return &repositories.RiskFill{OrderID: uuid.NewString(), Market: "perp", SymbolKey: symbol, Side: side, Qty: qty, Price: price, RealizedPnL: pnl, ExecutedAt: at}
+}
+
+func breachesByType(breaches []*entities.RiskLimitBreach) map[entities.RiskLimitType]*entities.RiskLimitBreach {
+	byType := map[entities.RiskLimitType]*entities.RiskLimitBreach{}
+	for _, breach := range breaches {
+		byType[breach.LimitType] = breach
+	}
+	return byType
+}
+
+func TestEvaluateRiskLimits(t *testing.T) {
+	start := time.Date(2026, 3, 10, 1, 0, 0, 0, time.UTC)
+	cfg := entities.RiskLimitConfig{
+		MaxDailyLoss:        strPtr("100"),
+		MaxTradesPerDay:     intPtr(4),
+		MaxPositionNotional: strPtr("5000"),
+		MaxLeverage:         strPtr("10"),
+		CooldownAfterLosses: intPtr(2),
+		CooldownMinutes:     intPtr(30),
+	}
+	highLeverage := riskFill("ETHUSDT", "buy", "1", "2000", nil, start.Add(40*time.Minute))
+	highLeverage.Leverage = strPtr("20")
+	fills := []*repositories.RiskFill{
+		riskFill("BTCUSDT", "sell", "0.01", "60000", strPtr("-40"), start),
+		riskFill("BTCUSDT", "sell", "0.01", "60000", strPtr("-70"), start.Add(5*time.Minute)),
+		// Ten minutes after the second loss in a row, inside the cooldown.
+		riskFill("BTCUSDT", "buy", "0.06", "60000", nil, start.Add(15*time.Minute)),
+		highLeverage,
+		riskFill("BTCUSDT", "buy", "0.01", "60000", nil, start.Add(2*time.Hour)),
+	}
+	// 0.05 BTC carried in from the previous day.
+	breaches := EvaluateRiskLimits(cfg, fills, NewRiskLimitState(map[string]string{"perp:BTCUSDT": "0.05"}))
+	byType := breachesByType(breaches)
+	if len(breaches) != 5 {
+		t.Fatalf("expected every limit to be breached once, got %d: %+v", len(breaches), breaches)
+	}
+
+	if b := byType[entities.RiskLimitDailyLoss]; b.Observed != "110" || b.Limit != "100" || !b.TriggeredAt.Equal(start.Add(5*time.Minute)) {
+		t.Errorf("unexpected daily loss breach: %+v", b)
+	}
+	if b := byType[entities.RiskLimitLossCooldown]; b.Observed != "10" || b.Limit != "30" {
+		t.Errorf("unexpected cooldown breach: %+v", b)
+	}
+	// 0.05 - 0.02 + 0.06 = 0.09 BTC at 60000.
+	if b := byType[entities.RiskLimitPositionNotional]; b.Scope != "perp:BTCUSDT" || b.Observed != "5400" {
+		t.Errorf("unexpected notional breach: %+v", b)
+	}
+	if b := byType[entities.RiskLimitLeverage]; b.Scope != "ETHUSDT" || b.Observed != "20" {
+		t.Errorf("unexpected leverage breach: %+v", b)
+	}
+	if b := byType[entities.RiskLimitTradesPerDay]; b.Observed != "5" || !b.TriggeredAt.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("unexpected trade count breach: %+v", b)
+	}
+
+	score, limits := DisciplineScoreFor(breaches)
+	if score != 0 || len(limits) != 5 {
+		t.Fatalf("expected score 0 with five limits broken, got %d %v", score, limits)
+	}
+}
+
+func TestEvaluateRiskLimitsWinResetsLossStreak(t *testing.T) {
+	start := time.Date(2026, 3, 10, 1, 0, 0, 0, time.UTC)
+	cfg := entities.RiskLimitConfig{CooldownAfterLosses: intPtr(2), CooldownMinutes: intPtr(30)}
+	fills := []*repositories.RiskFill{
+		riskFill("BTCUSDT", "sell", "0.01", "60000", strPtr("-40"), start),
+		riskFill("BTCUSDT", "sell", "0.01", "60000", strPtr("25"), start.Add(time.Minute)),
+		riskFill("BTCUSDT", "sell", "0.01", "60000", strPtr("-10"), start.Add(2*time.Minute)),
+		riskFill("BTCUSDT", "buy", "0.03", "60000", strPtr("0"), start.Add(3*time.Minute)),
+	}
+	if breaches := EvaluateRiskLimits(cfg, fills, nil); len(breaches) != 0 {
+		t.Fatalf("expected no breach when a win splits the losses, got %+v", breaches)
+	}
+
+	score, limits := DisciplineScoreFor(nil)
+	if score != 100 || len(limits) != 0 {
+		t.Fatalf("expected a clean day to score 100, got %d %v", score, limits)
+	}
+}
+
+func TestEvaluateRiskLimitsCountsOrdersAndSplitsMarkets(t *testing.T) {
+	start := time.Date(2026, 3, 10, 1, 0, 0, 0, time.UTC)
+	cfg := entities.RiskLimitConfig{MaxTradesPerDay: intPtr(2), MaxPositionNotional: strPtr("5000")}
+	// One order filled in three parts, then a spot buy of the symbol the perp
+	// position is short in.
+	var fills []*repositories.RiskFill
+	for i := 0; i < 3; i++ {
+		fill := riskFill("BTCUSDT", "sell", "0.01", "60000", nil, start.Add(time.Duration(i)*time.Second))
+		fill.OrderID = "42"
+		fills = append(fills, fill)
+	}
+	spot := riskFill("BTCUSDT", "buy", "0.05", "60000", nil, start.Add(time.Minute))
+	spot.Market = "spot"
+	fills = append(fills, spot)
+
+	state := NewRiskLimitState(map[string]string{"perp:BTCUSDT": "-0.05"})
+	if breaches := EvaluateRiskLimits(cfg, fills, state); len(breaches) != 0 {
+		t.Fatalf("expected two orders and no netting across markets, got %+v", breaches)
+	}
+	if got := riskOrderCount(fills); got != 2 {
+		t.Fatalf("expected 2 orders, got %d", got)
+	}
+	// 0.08 short on perp is 4800; the spot buy must not offset it.
+	if state.Positions["perp:BTCUSDT"].Cmp(big.NewRat(-8, 100)) != 0 || state.Positions["spot:BTCUSDT"].Cmp(big.NewRat(5, 100)) != 0 {
+		t.Fatalf("unexpected positions: %+v", state.Positions)
+	}
+}
+
+func TestEvaluateRiskLimitsScoresLossStreakPerOrder(t *testing.T) {
+	start := time.Date(2026, 3, 10, 1, 0, 0, 0, time.UTC)
+	cfg := entities.RiskLimitConfig{CooldownAfterLosses: intPtr(2), CooldownMinutes: intPtr(30)}
+	fills := []*repositories.RiskFill{
+		riskFill("BTCUSDT", "sell", "0.01", "60000", strPtr("-40"), start),
+	}
+	// One losing close filled in three parts is one loss, not three.
+	for i := 1; i <= 3; i++ {
+		fill := riskFill("BTCUSDT", "sell", "0.01", "60000", strPtr("-5"), start.Add(time.Duration(i)*time.Minute))
+		fill.OrderID = "77"
+		fills = append(fills, fill)
+	}
+	state := NewRiskLimitState(nil)
+	if breaches := EvaluateRiskLimits(cfg, fills, state); len(breaches) != 0 {
+		t.Fatalf("the order that started the cooldown must not breach it, got %+v", breaches)
+	}
+	if state.LossStreak != 2 || !state.CooldownUntil.Equal(start.Add(33*time.Minute)) {
+		t.Fatalf("expected a 2-order streak with cooldown from the last part, got %d until %s", state.LossStreak, state.CooldownUntil)
+	}
+
+	next := []*repositories.RiskFill{riskFill("ETHUSDT", "buy", "1", "2000", nil, start.Add(10*time.Minute))}
+	breaches := EvaluateRiskLimits(cfg, next, state)
+	if b := breachesByType(breaches)[entities.RiskLimitLossCooldown]; b == nil || b.Observed != "7" {
+		t.Fatalf("expected a new order inside the cooldown to breach it, got %+v", breaches)
+	}
+}
+
+func TestEvaluateRiskLimitsDailyLossAtLimitIsExact(t *testing.T) {
+	start := time.Date(2026, 3, 10, 1, 0, 0, 0, time.UTC)
+	cfg := entities.RiskLimitConfig{MaxDailyLoss: strPtr("0.3")}
+	fills := []*repositories.RiskFill{
+		riskFill("BTCUSDT", "sell", "0.01", "60000", strPtr("-0.1"), start),
+		riskFill("BTCUSDT", "sell", "0.01", "60000", strPtr("-0.2"), start.Add(time.Minute)),
+	}
+	if breaches := EvaluateRiskLimits(cfg, fills, nil); len(breaches) != 0 {
+		t.Fatalf("a loss exactly at the limit is not a breach, got %+v", breaches)
+	}
+}
+
+type fakeRiskLimitRepo struct {
+	repositories.RiskLimitRepository
+	rule        *entities.AlertRule
+	fills       []*repositories.RiskFill
+	netCalls    int
+	breaches    []*entities.RiskLimitBreach
+	scores      []*entities.DisciplineScore
+	completed   []uuid.UUID
+	evaluations []*repositories.RiskEvaluation
+}
+
+func (f *fakeRiskLimitRepo) GetRule(_ context.Context, _ uuid.UUID) (*entities.AlertRule, error) {
+	return f.rule, nil
+}
+
+func (f *fakeRiskLimitRepo) NetPositions(_ context.Context, _ uuid.UUID, _ time.Time) (map[string]string, error) {
+	f.netCalls++
+	return nil, nil
+}
+
+func (f *fakeRiskLimitRepo) ListFills(_ context.Context, _ uuid.UUID, from, to time.Time) ([]*repositories.RiskFill, error) {
+	var out []*repositories.RiskFill
+	for _, fill := range f.fills {
+		if !fill.ExecutedAt.Before(from) && fill.ExecutedAt.Before(to) {
+			out = append(out, fill)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRiskLimitRepo) CreateBreach(_ context.Context, breach *entities.RiskLimitBreach) (bool, error) {
+	f.breaches = append(f.breaches, breach)
+	return true, nil
+}
+
+func (f *fakeRiskLimitRepo) UpsertDisciplineScore(_ context.Context, score *entities.DisciplineScore) error {
+	f.scores = append(f.scores, score)
+	return nil
+}
+
+func (f *fakeRiskLimitRepo) ListPendingEvaluations(_ context.Context, _ int) ([]*repositories.RiskEvaluation, error) {
+	return f.evaluations, nil
+}
+
+func (f *fakeRiskLimitRepo) CompleteEvaluation(_ context.Context, evaluation *repositories.RiskEvaluation, _ time.Time) error {
+	f.completed = append(f.completed, evaluation.UserID)
+	return nil
+}
+
+func TestEvaluatePendingCarriesLossStreakAcrossDays(t *testing.T) {
+	userID := uuid.New()
+	// Two losses just before midnight UTC, then a trade ten minutes into the
+	// next day while the cooldown still runs.
+	midnight := time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)
+	fills := []*repositories.RiskFill{
+		riskFill("BTCUSDT", "sell", "0.01", "60000", strPtr("-40"), midnight.Add(-10*time.Minute)),
+		riskFill("BTCUSDT", "sell", "0.01", "60000", strPtr("-70"), midnight.Add(-5*time.Minute)),
+		riskFill("BTCUSDT", "buy", "0.01", "60000", nil, midnight.Add(10*time.Minute)),
+	}
+	repo := &fakeRiskLimitRepo{
+		rule:        &entities.AlertRule{UserID: userID, Enabled: true, Config: []byte(`{"cooldown_after_losses":2,"cooldown_minutes":30}`)},
+		fills:       fills,
+		evaluations: []*repositories.RiskEvaluation{{UserID: userID, Since: midnight.Add(10 * time.Minute)}},
+	}
+	svc := NewRiskLimitService(repo, nil, nil, nil)
+	svc.now = func() time.Time { return midnight.Add(2 * time.Hour) }
+
+	done, err := svc.EvaluatePending(context.Background(), 10)
+	if err != nil || done != 1 || len(repo.completed) != 1 {
+		t.Fatalf("expected the queued user to be completed, got %d %v", done, err)
+	}
+	if repo.netCalls != 1 {
+		t.Fatalf("expected positions to be read once, got %d", repo.netCalls)
+	}
+	if len(repo.breaches) != 1 || repo.breaches[0].LimitType != entities.RiskLimitLossCooldown || repo.breaches[0].TradeDate != "2026-03-11" {
+		t.Fatalf("expected a cooldown breach carried into the new day, got %+v", repo.breaches)
+	}
+	if len(repo.scores) != 1 || repo.scores[0].TradeDate != "2026-03-11" {
+		t.Fatalf("expected only the queued day to be scored, got %+v", repo.scores)
+	}
+}
+
+func TestValidateRiskLimitConfig(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  entities.RiskLimitConfig
+		ok   bool
+	}{
+		{"empty", entities.RiskLimitConfig{}, true},
+		{"all set", entities.RiskLimitConfig{MaxDailyLoss: strPtr("250.5"), MaxTradesPerDay: intPtr(10), CooldownAfterLosses: intPtr(3), CooldownMinutes: intPtr(60)}, true},
+		{"negative loss", entities.RiskLimitConfig{MaxDailyLoss: strPtr("-1")}, false},
+		{"bad leverage", entities.RiskLimitConfig{MaxLeverage: strPtr("ten")}, false},
+		{"zero trades", entities.RiskLimitConfig{MaxTradesPerDay: intPtr(0)}, false},
+		{"cooldown without minutes", entities.RiskLimitConfig{CooldownAfterLosses: intPtr(3)}, false},
+	}
+	for _, tc := range cases {
+		if err := ValidateRiskLimitConfig(tc.cfg); (err == nil) != tc.ok {
+			t.Errorf("%s: unexpected result %v", tc.name, err)
+		}
+	}
+}
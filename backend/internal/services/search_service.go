@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"html"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+const (
+	maxSearchTerms = 8
+	// Highlight markers are control characters that can't appear in journal
+	// text, so the snippet can be HTML-escaped before <mark> tags go in.
+	searchHighlightStart = "\x02"
+	searchHighlightStop  = "\x03"
+)
+
+var (
+	ErrEmptySearchQuery    = errors.New("q must contain at least one word")
+	ErrInvalidSearchSource = errors.New("sources must be bubble, note, guided_review or ai_opinion")
+)
+
+// Common Korean particles, longest first. They are trimmed from query terms so
+// '손절을' also finds '손절' and '손절했다' via prefix matching.
+var koreanParticles = []string{
+	"에서는", "으로는", "에게서",
+	"에서", "으로", "에게", "까지", "부터", "처럼", "보다", "하고", "이랑",
+	"은", "는", "이", "가", "을", "를", "에", "의", "도", "로", "와", "과", "만", "랑",
+}
+
+type SearchInput struct {
+	Query   string
+	Sources []string
+	Symbol  string
+	Tags    []string
+	From    *time.Time
+	To      *time.Time
+	Limit   int
+	Offset  int
+}
+
+type SearchResult struct {
+	Query string                `json:"query"`
+	Terms []string              `json:"terms"`
+	Total int                   `json:"total"`
+	Hits  []*entities.SearchHit `json:"hits"`
+}
+
+// SearchService runs full-text search across bubble memos, review notes,
+// guided review memos and AI opinions.
+type SearchService struct {
+	repo repositories.SearchRepository
+}
+
+func NewSearchService(repo repositories.SearchRepository) *SearchService {
+	return &SearchService{repo: repo}
+}
+
+func (s *SearchService) Search(ctx context.Context, userID uuid.UUID, input SearchInput) (*SearchResult, error) {
+	terms := SearchTerms(input.Query)
+	if len(terms) == 0 {
+		return nil, ErrEmptySearchQuery
+	}
+	sources, err := parseSearchSources(input.Sources)
+	if err != nil {
+		return nil, err
+	}
+
+	hits, total, err := s.repo.Search(ctx, userID, repositories.SearchFilter{
+		TSQuery:        SearchTSQuery(terms),
+		Sources:        sources,
+		Symbol:         strings.ToUpper(strings.TrimSpace(input.Symbol)),
+		Tags:           input.Tags,
+		From:           input.From,
+		To:             input.To,
+		HighlightStart: searchHighlightStart,
+		HighlightStop:  searchHighlightStop,
+		Limit:          input.Limit,
+		Offset:         input.Offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, hit := range hits {
+		hit.Snippet = renderSearchSnippet(hit.Snippet)
+	}
+	if hits == nil {
+		hits = []*entities.SearchHit{}
+	}
+
+	return &SearchResult{Query: input.Query, Terms: terms, Total: total, Hits: hits}, nil
+}
+
+// SearchTerms splits a query into lowercase words of letters and digits, the
+// same way the 'simple' text search parser splits stored text. Korean words
+// lose a trailing particle as long as at least two syllables remain.
+func SearchTerms(raw string) []string {
+	words := strings.FieldsFunc(strings.ToLower(raw), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	seen := make(map[string]bool, len(words))
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		word = trimKoreanParticle(word)
+		if seen[word] {
+			continue
+		}
+		seen[word] = true
+		terms = append(terms, word)
+		if len(terms) == maxSearchTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// SearchTSQuery ANDs the terms together as prefix matches. Terms only contain
+// letters and digits, so they need no quoting.
+func SearchTSQuery(terms []string) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = term + ":*"
+	}
+	return strings.Join(parts, " & ")
+}
+
+func trimKoreanParticle(word string) string {
+	runes := []rune(word)
+	if len(runes) < 3 || !unicode.Is(unicode.Hangul, runes[len(runes)-1]) {
+		return word
+	}
+	for _, particle := range koreanParticles {
+		stem := strings.TrimSuffix(word, particle)
+		if stem != word && len([]rune(stem)) >= 2 {
+			return stem
+		}
+	}
+	return word
+}
+
+func parseSearchSources(raw []string) ([]entities.SearchSource, error) {
+	if len(raw) == 0 {
+		return entities.SearchSources, nil
+	}
+	seen := map[entities.SearchSource]bool{}
+	var sources []entities.SearchSource
+	for _, value := range raw {
+		source := entities.SearchSource(strings.TrimSpace(value))
+		valid := false
+		for _, known := range entities.SearchSources {
+			if source == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, ErrInvalidSearchSource
+		}
+		if !seen[source] {
+			seen[source] = true
+			sources = append(sources, source)
+		}
+	}
+	return sources, nil
+}
+
+// renderSearchSnippet escapes the headline and turns the highlight markers
+// into <mark> tags, so clients can render it as HTML safely.
+func renderSearchSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, searchHighlightStart, "<mark>")
+	return strings.ReplaceAll(escaped, searchHighlightStop, "</mark>")
+}
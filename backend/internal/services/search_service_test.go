@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+type fakeSearchRepo struct {
+	filter repositories.SearchFilter
+	hits   []*entities.SearchHit
+}
+
+func (r *fakeSearchRepo) Search(_ context.Context, _ uuid.UUID, filter repositories.SearchFilter) ([]*entities.SearchHit, int, error) {
+	r.filter = filter
+	return r.hits, len(r.hits), nil
+}
+
+func TestSearchTermsHandlesMixedKoreanAndEnglish(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string][]string{
+		"BTC 손절을 너무 늦게":     {"btc", "손절", "너무", "늦게"},
+		"stop-loss, FOMO!!": {"stop", "loss", "fomo"},
+		"매도 매도":             {"매도"},
+		"비트코인에서는 4h":        {"비트코인", "4h"},
+		"  ...  ":           {},
+	}
+	for query, want := range cases {
+		if got := SearchTerms(query); !reflect.DeepEqual(got, want) {
+			t.Fatalf("SearchTerms(%q) = %v, want %v", query, got, want)
+		}
+	}
+
+	if got := SearchTSQuery([]string{"btc", "손절"}); got != "btc:* & 손절:*" {
+		t.Fatalf("unexpected tsquery %q", got)
+	}
+}
+
+func TestSearchEscapesSnippetsAndValidatesSources(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeSearchRepo{hits: []*entities.SearchHit{
+		{Source: entities.SearchSourceNote, Snippet: "<b>" + searchHighlightStart + "손절을" + searchHighlightStop + " 미룸"},
+	}}
+	svc := NewSearchService(repo)
+
+	result, err := svc.Search(context.Background(), uuid.New(), SearchInput{Query: "손절", Symbol: " btcusdt ", Limit: 20})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if want := "&lt;b&gt;<mark>손절을</mark> 미룸"; result.Hits[0].Snippet != want {
+		t.Fatalf("snippet = %q, want %q", result.Hits[0].Snippet, want)
+	}
+	if repo.filter.Symbol != "BTCUSDT" || len(repo.filter.Sources) != len(entities.SearchSources) {
+		t.Fatalf("unexpected filter: %+v", repo.filter)
+	}
+
+	if _, err := svc.Search(context.Background(), uuid.New(), SearchInput{Query: "손절", Sources: []string{"trade"}}); !errors.Is(err, ErrInvalidSearchSource) {
+		t.Fatalf("expected invalid source, got %v", err)
+	}
+	if _, err := svc.Search(context.Background(), uuid.New(), SearchInput{Query: "!!"}); !errors.Is(err, ErrEmptySearchQuery) {
+		t.Fatalf("expected empty query, got %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+// SummaryPackFieldDelta is one compared payload field. Delta is target minus
+// base and is only set when both sides are numbers.
+type SummaryPackFieldDelta struct {
+	Field   string  `json:"field"`
+	Base    *string `json:"base"`
+	Target  *string `json:"target"`
+	Delta   *string `json:"delta"`
+	Changed bool    `json:"changed"`
+}
+
+type SummaryPackCompareSide struct {
+	PackID        string `json:"pack_id"`
+	SourceRunID   string `json:"source_run_id"`
+	Range         string `json:"range"`
+	SchemaVersion string `json:"schema_version"`
+	CalcVersion   string `json:"calc_version"`
+	ContentHash   string `json:"content_hash"`
+	StartTs       string `json:"start_ts"`
+	EndTs         string `json:"end_ts"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// SummaryPackAlignment says how far the two packs can be compared like for
+// like. Warnings name every difference that makes deltas less meaningful.
+type SummaryPackAlignment struct {
+	SameRange       bool     `json:"same_range"`
+	SameCalcVersion bool     `json:"same_calc_version"`
+	OverlapStartTs  *string  `json:"overlap_start_ts"`
+	OverlapEndTs    *string  `json:"overlap_end_ts"`
+	Warnings        []string `json:"warnings"`
+}
+
+// SummaryPackEvidenceDiff lists trades that appear in only one of the packs.
+// Available is false when either pack predates full evidence tracking.
+type SummaryPackEvidenceDiff struct {
+	Available       bool     `json:"available"`
+	AddedTradeIDs   []string `json:"added_trade_ids"`
+	RemovedTradeIDs []string `json:"removed_trade_ids"`
+	UnchangedCount  int      `json:"unchanged_count"`
+}
+
+type SummaryPackComparison struct {
+	Base            SummaryPackCompareSide  `json:"base"`
+	Target          SummaryPackCompareSide  `json:"target"`
+	Alignment       SummaryPackAlignment    `json:"alignment"`
+	Changed         bool                    `json:"changed"`
+	PnL             []SummaryPackFieldDelta `json:"pnl"`
+	Activity        []SummaryPackFieldDelta `json:"activity"`
+	Flows           []SummaryPackFieldDelta `json:"flows"`
+	Reconciliation  []SummaryPackFieldDelta `json:"reconciliation"`
+	WarningsAdded   []string                `json:"normalization_warnings_added"`
+	WarningsRemoved []string                `json:"normalization_warnings_removed"`
+	Evidence        SummaryPackEvidenceDiff `json:"evidence"`
+}
+
+// CompareSummaryPacks aligns two packs of the same user and reports
+// field-level deltas from base to target.
+func CompareSummaryPacks(base, target *entities.SummaryPack) (*SummaryPackComparison, error) {
+	var basePayload, targetPayload summaryPackPayloadV1
+	if err := json.Unmarshal(base.Payload, &basePayload); err != nil {
+		return nil, fmt.Errorf("base pack payload: %w", err)
+	}
+	if err := json.Unmarshal(target.Payload, &targetPayload); err != nil {
+		return nil, fmt.Errorf("target pack payload: %w", err)
+	}
+
+	result := &SummaryPackComparison{
+		Base:      compareSide(base, basePayload),
+		Target:    compareSide(target, targetPayload),
+		Alignment: alignSummaryPacks(base, target, basePayload, targetPayload),
+	}
+
+	bp, tp := basePayload.PnLSummary, targetPayload.PnLSummary
+	result.PnL = []SummaryPackFieldDelta{
+		decimalDelta("realized_pnl_total", bp.RealizedPnLTotal, tp.RealizedPnLTotal),
+		decimalDelta("unrealized_pnl_snapshot", bp.UnrealizedPnLSnapshot, tp.UnrealizedPnLSnapshot),
+		decimalDelta("fees_total", bp.FeesTotal, tp.FeesTotal),
+		decimalDelta("funding_total", bp.FundingTotal, tp.FundingTotal),
+	}
+	ba, ta := basePayload.ActivitySummary, targetPayload.ActivitySummary
+	result.Activity = []SummaryPackFieldDelta{
+		countDelta("trade_count", ba.TradeCount, ta.TradeCount),
+		decimalDelta("notional_volume_total", ba.NotionalVolumeTotal, ta.NotionalVolumeTotal),
+		decimalDelta("long_short_ratio", ba.LongShortRatio, ta.LongShortRatio),
+		decimalDelta("leverage_summary", ba.LeverageSummary, ta.LeverageSummary),
+		decimalDelta("max_drawdown_est", ba.MaxDrawdownEst, ta.MaxDrawdownEst),
+	}
+	bf, tf := basePayload.FlowSummary, targetPayload.FlowSummary
+	result.Flows = []SummaryPackFieldDelta{
+		decimalDelta("net_exchange_flow", bf.NetExchangeFlow, tf.NetExchangeFlow),
+		decimalDelta("net_wallet_flow", bf.NetWalletFlow, tf.NetWalletFlow),
+	}
+	br, tr := basePayload.Reconciliation, targetPayload.Reconciliation
+	result.Reconciliation = []SummaryPackFieldDelta{
+		decimalDelta("reconciliation_status", &br.ReconciliationStatus, &tr.ReconciliationStatus),
+		countDelta("missing_suspects_count", br.MissingSuspectsCount, tr.MissingSuspectsCount),
+		countDelta("duplicate_suspects_count", br.DuplicateSuspectsCount, tr.DuplicateSuspectsCount),
+	}
+	result.WarningsAdded, result.WarningsRemoved, _ = diffStringSets(br.NormalizationWarnings, tr.NormalizationWarnings)
+
+	// Packs from before evidence IDs named the exchange and symbol can't be
+	// matched trade by trade against newer ones.
+	if base.EvidenceTradeIDs != nil && target.EvidenceTradeIDs != nil &&
+		hasLegacyEvidenceIDs(base.EvidenceTradeIDs) == hasLegacyEvidenceIDs(target.EvidenceTradeIDs) {
+		added, removed, unchanged := diffStringSets(base.EvidenceTradeIDs, target.EvidenceTradeIDs)
+		result.Evidence = SummaryPackEvidenceDiff{
+			Available:       true,
+			AddedTradeIDs:   added,
+			RemovedTradeIDs: removed,
+			UnchangedCount:  unchanged,
+		}
+	} else {
+		result.Evidence = SummaryPackEvidenceDiff{AddedTradeIDs: []string{}, RemovedTradeIDs: []string{}}
+		result.Alignment.Warnings = append(result.Alignment.Warnings, "evidence_unavailable")
+	}
+
+	result.Changed = len(result.WarningsAdded) > 0 || len(result.WarningsRemoved) > 0 ||
+		len(result.Evidence.AddedTradeIDs) > 0 || len(result.Evidence.RemovedTradeIDs) > 0
+	for _, group := range [][]SummaryPackFieldDelta{result.PnL, result.Activity, result.Flows, result.Reconciliation} {
+		for _, delta := range group {
+			result.Changed = result.Changed || delta.Changed
+		}
+	}
+	return result, nil
+}
+
+func compareSide(pack *entities.SummaryPack, payload summaryPackPayloadV1) SummaryPackCompareSide {
+	return SummaryPackCompareSide{
+		PackID:        pack.PackID.String(),
+		SourceRunID:   pack.SourceRunID.String(),
+		Range:         pack.Range,
+		SchemaVersion: pack.SchemaVersion,
+		CalcVersion:   pack.CalcVersion,
+		ContentHash:   pack.ContentHash,
+		StartTs:       payload.TimeRange.StartTs,
+		EndTs:         payload.TimeRange.EndTs,
+		CreatedAt:     pack.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func alignSummaryPacks(base, target *entities.SummaryPack, basePayload, targetPayload summaryPackPayloadV1) SummaryPackAlignment {
+	alignment := SummaryPackAlignment{
+		SameRange:       base.Range == target.Range,
+		SameCalcVersion: base.CalcVersion == target.CalcVersion,
+		Warnings:        []string{},
+	}
+	if !alignment.SameRange {
+		alignment.Warnings = append(alignment.Warnings, "range_mismatch")
+	}
+	if !alignment.SameCalcVersion {
+		alignment.Warnings = append(alignment.Warnings, "calc_version_mismatch")
+	}
+	if base.SchemaVersion != target.SchemaVersion {
+		alignment.Warnings = append(alignment.Warnings, "schema_version_mismatch")
+	}
+
+	baseStart, err1 := time.Parse(time.RFC3339, basePayload.TimeRange.StartTs)
+	baseEnd, err2 := time.Parse(time.RFC3339, basePayload.TimeRange.EndTs)
+	targetStart, err3 := time.Parse(time.RFC3339, targetPayload.TimeRange.StartTs)
+	targetEnd, err4 := time.Parse(time.RFC3339, targetPayload.TimeRange.EndTs)
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+		alignment.Warnings = append(alignment.Warnings, "time_range_unknown")
+		return alignment
+	}
+	start, end := baseStart, baseEnd
+	if targetStart.After(start) {
+		start = targetStart
+	}
+	if targetEnd.Before(end) {
+		end = targetEnd
+	}
+	if !start.Before(end) {
+		alignment.Warnings = append(alignment.Warnings, "no_time_overlap")
+		return alignment
+	}
+	alignment.OverlapStartTs = ptr(start.UTC().Format(time.RFC3339))
+	alignment.OverlapEndTs = ptr(end.UTC().Format(time.RFC3339))
+	return alignment
+}
+
+// decimalDelta compares two optional values; non-numeric values such as a
+// status are compared as text.
+func decimalDelta(field string, base, target *string) SummaryPackFieldDelta {
+	delta := SummaryPackFieldDelta{Field: field, Base: base, Target: target}
+	switch {
+	case base == nil && target == nil:
+		return delta
+	case base == nil || target == nil:
+		delta.Changed = true
+		return delta
+	}
+	baseValue, targetValue := parseDecimal(*base), parseDecimal(*target)
+	if baseValue == nil || targetValue == nil {
+		delta.Changed = *base != *target
+		return delta
+	}
+	diff := new(big.Rat).Sub(targetValue, baseValue)
+	delta.Delta = normalizeDecimal(diff)
+	delta.Changed = diff.Sign() != 0
+	return delta
+}
+
+func countDelta(field string, base, target int) SummaryPackFieldDelta {
+	return decimalDelta(field, ptr(strconv.Itoa(base)), ptr(strconv.Itoa(target)))
+}
+
+// diffStringSets returns the values only in target, the values only in base,
+// and how many are in both. The input order is kept.
+func diffStringSets(base, target []string) ([]string, []string, int) {
+	inBase := make(map[string]struct{}, len(base))
+	for _, value := range base {
+		inBase[value] = struct{}{}
+	}
+	inTarget := make(map[string]struct{}, len(target))
+	for _, value := range target {
+		inTarget[value] = struct{}{}
+	}
+
+	added, removed := []string{}, []string{}
+	common := 0
+	for _, value := range target {
+		if _, ok := inBase[value]; !ok {
+			added = append(added, value)
+		} else {
+			common++
+		}
+	}
+	for _, value := range base {
+		if _, ok := inTarget[value]; !ok {
+			removed = append(removed, value)
+		}
+	}
+	return added, removed, common
+}
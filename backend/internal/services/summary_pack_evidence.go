@@ -0,0 +1,275 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+const summaryPackEvidenceBundleV1 = "evidence_bundle_v1"
+
+// Suspect kinds written to suspects.csv.
+const (
+	evidenceSuspectDuplicate  = "duplicate"
+	evidenceSuspectMissing    = "missing"
+	evidenceSuspectSymbolGap  = "symbol_mapping_gap"
+	evidenceSuspectTimeSkewed = "time_skew"
+)
+
+var evidenceTradeColumns = []string{
+	"evidence_id", "trade_uuid", "exchange", "exchange_trade_id", "symbol", "symbol_normalized", "side",
+	"position_side", "quantity", "price", "realized_pnl", "trade_time", "notional", "exchange_flow", "dedupe_key",
+	"duplicate_of",
+}
+
+var evidenceSuspectColumns = []string{"kind", "evidence_id", "trade_time", "reason", "related_evidence_id"}
+
+type evidenceSuspect struct {
+	kind       string
+	evidenceID string
+	tradeTime  string
+	reason     string
+	related    string
+}
+
+type evidenceManifestFile struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+	Bytes  int    `json:"bytes"`
+	Rows   int    `json:"rows,omitempty"`
+}
+
+// evidenceManifest describes a bundle. Results are the numbers the pack
+// reports, to be matched by recomputing them from trades.csv.
+type evidenceManifest struct {
+	BundleVersion  string                      `json:"bundle_version"`
+	PackID         string                      `json:"pack_id"`
+	SourceRunID    string                      `json:"source_run_id"`
+	Range          string                      `json:"range"`
+	SchemaVersion  string                      `json:"schema_version"`
+	CalcVersion    string                      `json:"calc_version"`
+	GeneratedAt    string                      `json:"generated_at"`
+	TimeRange      summaryPackTimeRangeV1      `json:"time_range"`
+	DataSources    summaryPackDataSourcesV1    `json:"data_sources"`
+	Results        evidenceManifestResults     `json:"results"`
+	Reconciliation summaryPackReconciliationV1 `json:"reconciliation"`
+	Files          []evidenceManifestFile      `json:"files"`
+}
+
+type evidenceManifestResults struct {
+	TradeCount          int     `json:"trade_count"`
+	BuyCount            int     `json:"buy_count"`
+	SellCount           int     `json:"sell_count"`
+	RealizedPnLTotal    *string `json:"realized_pnl_total"`
+	FeesTotal           *string `json:"fees_total"`
+	NotionalVolumeTotal *string `json:"notional_volume_total"`
+	NetExchangeFlow     *string `json:"net_exchange_flow"`
+	LongShortRatio      *string `json:"long_short_ratio"`
+}
+
+// evidenceNormalization documents the rules calc v1 applies, so the numbers
+// can be recomputed without this code.
+type evidenceNormalization struct {
+	CalcVersion    string            `json:"calc_version"`
+	Rules          map[string]string `json:"rules"`
+	SymbolMappings map[string]string `json:"symbol_mappings"`
+}
+
+// evidenceBundleBuilder collects the rows of a pack's evidence bundle while
+// the pack is calculated.
+type evidenceBundleBuilder struct {
+	trades   [][]string
+	suspects []evidenceSuspect
+	symbols  map[string]string
+}
+
+func newEvidenceBundleBuilder() *evidenceBundleBuilder {
+	return &evidenceBundleBuilder{symbols: map[string]string{}}
+}
+
+// addTrade records one trade as counted, with duplicateOf naming the
+// evidence ID of the first trade sharing its dedupe key.
+func (b *evidenceBundleBuilder) addTrade(trade *entities.Trade, normalizedSymbol, dedupeKey, duplicateOf string, notional, flow *string) {
+	evidenceID := summaryPackEvidenceID(trade)
+	tradeTime := trade.TradeTime.UTC().Format(time.RFC3339Nano)
+	exchangeTradeID := ""
+	if trade.BinanceTradeID != 0 {
+		exchangeTradeID = strconv.FormatInt(trade.BinanceTradeID, 10)
+	}
+	b.trades = append(b.trades, []string{
+		evidenceID, trade.ID.String(), trade.Exchange, exchangeTradeID, trade.Symbol, normalizedSymbol, trade.Side,
+		stringOrEmpty(trade.PositionSide), trade.Quantity, trade.Price, stringOrEmpty(trade.RealizedPnL), tradeTime,
+		stringOrEmpty(notional), stringOrEmpty(flow), dedupeKey, duplicateOf,
+	})
+	b.symbols[trade.Symbol] = normalizedSymbol
+
+	if duplicateOf != "" {
+		b.addSuspect(evidenceSuspectDuplicate, trade, "dedupe key "+dedupeKey+" already seen", duplicateOf)
+	}
+	if normalizedSymbol == "unknown" || normalizedSymbol == "invalid" {
+		b.addSuspect(evidenceSuspectSymbolGap, trade, fmt.Sprintf("symbol %q normalizes to %s", trade.Symbol, normalizedSymbol), "")
+	}
+}
+
+// addSuspect records a suspect; trade is nil for pack-level suspects.
+func (b *evidenceBundleBuilder) addSuspect(kind string, trade *entities.Trade, reason, related string) {
+	suspect := evidenceSuspect{kind: kind, reason: reason, related: related}
+	if trade != nil {
+		suspect.evidenceID = summaryPackEvidenceID(trade)
+		suspect.tradeTime = trade.TradeTime.UTC().Format(time.RFC3339Nano)
+	}
+	b.suspects = append(b.suspects, suspect)
+}
+
+// build writes the archive. Entries carry the generation time so the same
+// inputs always produce the same bytes.
+func (b *evidenceBundleBuilder) build(manifest evidenceManifest) (*entities.SummaryPackEvidenceBundle, error) {
+	tradesCSV, err := writeEvidenceCSV(evidenceTradeColumns, b.trades)
+	if err != nil {
+		return nil, err
+	}
+	suspectRows := make([][]string, 0, len(b.suspects))
+	for _, suspect := range b.suspects {
+		suspectRows = append(suspectRows, []string{suspect.kind, suspect.evidenceID, suspect.tradeTime, suspect.reason, suspect.related})
+	}
+	suspectsCSV, err := writeEvidenceCSV(evidenceSuspectColumns, suspectRows)
+	if err != nil {
+		return nil, err
+	}
+	normalization, err := json.MarshalIndent(evidenceNormalization{
+		CalcVersion:    manifest.CalcVersion,
+		Rules:          evidenceNormalizationRules,
+		SymbolMappings: b.symbols,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name string
+		body []byte
+		rows int
+	}{
+		{"trades.csv", tradesCSV, len(b.trades)},
+		{"suspects.csv", suspectsCSV, len(b.suspects)},
+		{"normalization.json", normalization, 0},
+		{"README.txt", []byte(evidenceReadme), 0},
+	}
+	for _, file := range files {
+		sum := sha256.Sum256(file.body)
+		manifest.Files = append(manifest.Files, evidenceManifestFile{
+			Name: file.name, SHA256: hex.EncodeToString(sum[:]), Bytes: len(file.body), Rows: file.rows,
+		})
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	modified, err := time.Parse(time.RFC3339, manifest.GeneratedAt)
+	if err != nil {
+		return nil, err
+	}
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	entries := append([]struct {
+		name string
+		body []byte
+		rows int
+	}{{"manifest.json", manifestJSON, 0}}, files...)
+	for _, entry := range entries {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: entry.name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(entry.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(archive.Bytes())
+	return &entities.SummaryPackEvidenceBundle{
+		Archive:   archive.Bytes(),
+		SHA256:    hex.EncodeToString(sum[:]),
+		SizeBytes: archive.Len(),
+		Manifest:  manifestJSON,
+	}, nil
+}
+
+func writeEvidenceCSV(header []string, rows [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func stringOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// evidenceDecimal prints a per-row amount without the 8 decimal rounding
+// of the totals, so the rows sum to the exact total.
+func evidenceDecimal(value *big.Rat) *string {
+	s := strings.TrimRight(value.FloatString(18), "0")
+	s = strings.TrimRight(s, ".")
+	if s == "" || s == "-0" {
+		s = "0"
+	}
+	return &s
+}
+
+var evidenceNormalizationRules = map[string]string{
+	"symbol":        "upper-cased and trimmed; empty is 'unknown'; spaces, '/' or '_' or anything other than A-Z0-9 with one optional '-' is 'invalid'",
+	"side":          "compared as is: only 'BUY' and 'SELL' count towards buy_count and sell_count",
+	"dedupe_key":    "'id:<exchange_trade_id>' when the exchange gave an ID, else 'fallback:<exchange>|<symbol>|<side>|<price>|<quantity>'",
+	"duplicates":    "a row whose dedupe key was already seen is a duplicate suspect but still counts in every total",
+	"notional":      "quantity * price for rows where both parse as decimals",
+	"exchange_flow": "notional, negated for SELL rows",
+	"realized_pnl":  "sum of realized_pnl over rows where it parses as a decimal",
+	"fees":          "not reported by calc v1; always 0",
+	"missing":       "one suspect when 10 or more trades report no fees, one more when funding is expected from a futures exchange but not reported",
+	"time_skew":     "rows more than 6 hours from the median trade time",
+	"rounding":      "totals are exact rationals printed with up to 8 decimals, trailing zeros removed",
+}
+
+const evidenceReadme = `Summary pack evidence bundle (evidence_bundle_v1)
+
+manifest.json       pack identity, the numbers the pack reports (results),
+                    reconciliation counts and the SHA-256 of every other file.
+trades.csv          every trade the pack was calculated from, including
+                    duplicate suspects, with the normalized symbol, notional,
+                    signed exchange flow and dedupe key per row.
+suspects.csv        reconciliation suspects with a reason per row. Rows
+                    without evidence_id are pack-level.
+normalization.json  the rules applied, and raw -> normalized symbols.
+
+To recompute the results from trades.csv:
+  trade_count          number of rows
+  buy_count/sell_count rows with side BUY / SELL
+  realized_pnl_total   sum of realized_pnl
+  notional_volume_total sum of notional
+  net_exchange_flow    sum of exchange_flow
+  long_short_ratio     buy_count / sell_count when both are non-zero
+Totals are exact decimals rounded to 8 places with trailing zeros removed.
+`
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+func TestSummaryPackEvidenceBundle(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 13, 9, 0, 0, 0, time.UTC)
+	var trades []*entities.Trade
+	for i := 0; i < 10; i++ {
+		side := "BUY"
+		if i%3 == 0 {
+			side = "SELL"
+		}
+		trade := newTrade(int64(5000+i), "binance_futures", "btcusdt", side, "0.125", "40000.123456789", now.Add(-time.Duration(i)*time.Minute))
+		trade.RealizedPnL = tradePtr("1.000000001")
+		trades = append(trades, trade)
+	}
+	trades = append(trades,
+		newTrade(5003, "binance_futures", "BTCUSDT", "BUY", "1", "100", now.Add(-2*time.Minute)),
+		newTrade(0, "binance_futures", "ETH/USDT", "SELL", "2", "2000", now.Add(-30*time.Hour)),
+	)
+
+	svc := baseService(now)
+	svc.tradeRepo = &summaryPackTestTradeRepo{trades: trades}
+	run := &entities.Run{RunID: uuid.New(), RunType: "exchange_sync", Meta: mustJSON(map[string]any{"exchange": "binance_futures"})}
+	pack, _, err := svc.GeneratePack(context.Background(), uuid.New(), run, "7d")
+	if err != nil {
+		t.Fatalf("GeneratePack failed: %v", err)
+	}
+	bundle := pack.EvidenceBundle
+	if bundle == nil {
+		t.Fatalf("no evidence bundle generated")
+	}
+	sum := sha256.Sum256(bundle.Archive)
+	if hex.EncodeToString(sum[:]) != bundle.SHA256 || bundle.SizeBytes != len(bundle.Archive) || bundle.PackID != pack.PackID {
+		t.Fatalf("bundle metadata does not match its archive")
+	}
+
+	var payload summaryPackPayloadV1
+	if err := json.Unmarshal(pack.Payload, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if want := "evidence_pack://" + pack.PackID.String() + "/" + bundle.SHA256; payload.EvidenceIndex.EvidencePackRef != want {
+		t.Fatalf("evidence_pack_ref=%q want=%q", payload.EvidenceIndex.EvidencePackRef, want)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(bundle.Archive), int64(len(bundle.Archive)))
+	if err != nil {
+		t.Fatalf("open archive: %v", err)
+	}
+	files := map[string][]byte{}
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", file.Name, err)
+		}
+		files[file.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+
+	var manifest evidenceManifest
+	if err := json.Unmarshal(files["manifest.json"], &manifest); err != nil {
+		t.Fatalf("decode manifest: %v", err)
+	}
+	if manifest.PackID != pack.PackID.String() || len(manifest.Files) != 4 {
+		t.Fatalf("unexpected manifest: %+v", manifest)
+	}
+	for _, file := range manifest.Files {
+		sum := sha256.Sum256(files[file.Name])
+		if hex.EncodeToString(sum[:]) != file.SHA256 {
+			t.Fatalf("%s does not match its manifest hash", file.Name)
+		}
+	}
+
+	// Recompute the pack's numbers from trades.csv alone.
+	rows, err := csv.NewReader(bytes.NewReader(files["trades.csv"])).ReadAll()
+	if err != nil {
+		t.Fatalf("read trades.csv: %v", err)
+	}
+	column := map[string]int{}
+	for i, name := range rows[0] {
+		column[name] = i
+	}
+	rows = rows[1:]
+	realized, notional, flow := new(big.Rat), new(big.Rat), new(big.Rat)
+	buys, sells := 0, 0
+	for _, row := range rows {
+		for target, name := range map[*big.Rat]string{realized: "realized_pnl", notional: "notional", flow: "exchange_flow"} {
+			if value := row[column[name]]; value != "" {
+				part, _ := new(big.Rat).SetString(value)
+				target.Add(target, part)
+			}
+		}
+		switch row[column["side"]] {
+		case "BUY":
+			buys++
+		case "SELL":
+			sells++
+		}
+	}
+	results := manifest.Results
+	if len(rows) != results.TradeCount || len(rows) != payload.ActivitySummary.TradeCount || buys != results.BuyCount || sells != results.SellCount {
+		t.Fatalf("counts: rows=%d buys=%d sells=%d, manifest %+v", len(rows), buys, sells, results)
+	}
+	for name, pair := range map[string][2]*string{
+		"realized_pnl_total":    {normalizeDecimal(realized), payload.PnLSummary.RealizedPnLTotal},
+		"notional_volume_total": {normalizeDecimal(notional), payload.ActivitySummary.NotionalVolumeTotal},
+		"net_exchange_flow":     {normalizeDecimal(flow), payload.FlowSummary.NetExchangeFlow},
+	} {
+		if *pair[0] != *pair[1] {
+			t.Fatalf("%s recomputed as %s, pack says %s", name, *pair[0], *pair[1])
+		}
+	}
+
+	suspects := string(files["suspects.csv"])
+	for _, want := range []string{
+		"duplicate,binance_futures:BTCUSDT:5003,", ",binance_futures:BTCUSDT:5003\n",
+		"time_skew," + trades[11].ID.String() + ",",
+		"symbol_mapping_gap," + trades[11].ID.String() + ",",
+		"missing,,,no fees reported for any trade,",
+	} {
+		if !strings.Contains(suspects, want) {
+			t.Fatalf("suspects.csv missing %q:\n%s", want, suspects)
+		}
+	}
+	if got := strings.Count(suspects, "duplicate,"); got != pack.DuplicateSuspectsCount {
+		t.Fatalf("duplicate rows=%d want=%d", got, pack.DuplicateSuspectsCount)
+	}
+}
@@ -0,0 +1,407 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+// packReportEvidenceLimit caps the rows in the evidence appendix; the
+// report states how many were left out.
+const packReportEvidenceLimit = 500
+
+type PackReportMetric struct {
+	Label string
+	Value string
+}
+
+type PackReportDay struct {
+	Date        string
+	RealizedPnL float64
+	Cumulative  float64
+	TradeCount  int
+}
+
+type PackReportTrade struct {
+	EvidenceID  string
+	Time        string
+	Exchange    string
+	Symbol      string
+	Side        string
+	Quantity    string
+	Price       string
+	RealizedPnL string
+}
+
+// PackReport is a summary pack laid out for people: headline metrics, daily
+// series for the charts and the trades behind the numbers.
+type PackReport struct {
+	PackID        string
+	SourceRunID   string
+	Range         string
+	SchemaVersion string
+	CalcVersion   string
+	ContentHash   string
+	CreatedAt     string
+	Timezone      string
+	Start         string
+	End           string
+
+	PnL      []PackReportMetric
+	Activity []PackReportMetric
+	Flows    []PackReportMetric
+
+	ReconciliationStatus  string
+	MissingSuspects       int
+	DuplicateSuspects     int
+	NormalizationWarnings []string
+
+	Days []PackReportDay
+
+	// Evidence holds at most packReportEvidenceLimit trades of
+	// EvidenceTotal, read from the pack's evidence bundle when it has one.
+	// EvidenceExact is false for packs that predate evidence tracking, whose
+	// appendix lists every trade now in the range. EvidenceMissing lists
+	// evidence trades of bundle-less packs that are no longer stored.
+	Evidence        []PackReportTrade
+	EvidenceTotal   int
+	EvidenceExact   bool
+	EvidenceMissing []string
+
+	// Redactions is set on reports opened through a share link; see
+	// entities.PackShareRedactAmounts and PackShareRedactEvidence.
+	Redactions []string
+}
+
+func (r *PackReport) Redacts(field string) bool {
+	for _, redaction := range r.Redactions {
+		if redaction == field {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildReport collects what the HTML and PDF reports show for a pack. The
+// evidence appendix comes from pack.EvidenceBundle when the caller loaded it,
+// and is rebuilt from stored trades only for packs without a bundle.
+func (s *SummaryPackService) BuildReport(ctx context.Context, pack *entities.SummaryPack) (*PackReport, error) {
+	var payload summaryPackPayloadV1
+	if err := json.Unmarshal(pack.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("pack payload: %w", err)
+	}
+	loc, err := time.LoadLocation(payload.TimeRange.Timezone)
+	if payload.TimeRange.Timezone == "" || err != nil {
+		loc = time.UTC
+	}
+
+	report := &PackReport{
+		PackID:                pack.PackID.String(),
+		SourceRunID:           pack.SourceRunID.String(),
+		Range:                 pack.Range,
+		SchemaVersion:         pack.SchemaVersion,
+		CalcVersion:           pack.CalcVersion,
+		ContentHash:           pack.ContentHash,
+		CreatedAt:             pack.CreatedAt.In(loc).Format("2006-01-02 15:04"),
+		Timezone:              loc.String(),
+		Start:                 packReportTime(payload.TimeRange.StartTs, loc),
+		End:                   packReportTime(payload.TimeRange.EndTs, loc),
+		ReconciliationStatus:  payload.Reconciliation.ReconciliationStatus,
+		MissingSuspects:       payload.Reconciliation.MissingSuspectsCount,
+		DuplicateSuspects:     payload.Reconciliation.DuplicateSuspectsCount,
+		NormalizationWarnings: payload.Reconciliation.NormalizationWarnings,
+		PnL: []PackReportMetric{
+			{"Realized PnL", packDigestValue(payload.PnLSummary.RealizedPnLTotal)},
+			{"Unrealized PnL", packDigestValue(payload.PnLSummary.UnrealizedPnLSnapshot)},
+			{"Fees", packDigestValue(payload.PnLSummary.FeesTotal)},
+			{"Funding", packDigestValue(payload.PnLSummary.FundingTotal)},
+		},
+		Activity: []PackReportMetric{
+			{"Trades", strconv.Itoa(payload.ActivitySummary.TradeCount)},
+			{"Notional volume", packDigestValue(payload.ActivitySummary.NotionalVolumeTotal)},
+			{"Long/short ratio", packDigestValue(payload.ActivitySummary.LongShortRatio)},
+			{"Leverage", packDigestValue(payload.ActivitySummary.LeverageSummary)},
+			{"Max drawdown (est.)", packDigestValue(payload.ActivitySummary.MaxDrawdownEst)},
+		},
+		Flows: []PackReportMetric{
+			{"Net exchange flow", packDigestValue(payload.FlowSummary.NetExchangeFlow)},
+			{"Net wallet flow", packDigestValue(payload.FlowSummary.NetWalletFlow)},
+		},
+	}
+
+	var evidence []packEvidenceRow
+	if pack.EvidenceBundle != nil {
+		evidence, err = readEvidenceBundleTrades(pack.EvidenceBundle.Archive)
+		if err != nil {
+			return nil, fmt.Errorf("evidence bundle: %w", err)
+		}
+		report.EvidenceExact = true
+		report.EvidenceMissing = []string{}
+	} else {
+		start, errStart := time.Parse(time.RFC3339, payload.TimeRange.StartTs)
+		end, errEnd := time.Parse(time.RFC3339, payload.TimeRange.EndTs)
+		if errStart != nil || errEnd != nil {
+			return report, nil
+		}
+		evidence, report.EvidenceMissing, err = s.liveEvidenceTrades(ctx, pack, start, end)
+		if err != nil {
+			return nil, err
+		}
+		report.EvidenceExact = pack.EvidenceTradeIDs != nil
+	}
+	sort.SliceStable(evidence, func(i, j int) bool { return evidence[i].trade.TradeTime.Before(evidence[j].trade.TradeTime) })
+
+	trades := make([]*entities.Trade, len(evidence))
+	for i, row := range evidence {
+		trades[i] = row.trade
+	}
+	report.Days = packReportDays(trades, loc)
+	report.EvidenceTotal = len(evidence)
+	for i, row := range evidence {
+		if i == packReportEvidenceLimit {
+			break
+		}
+		trade := row.trade
+		pnl := "-"
+		if trade.RealizedPnL != nil {
+			pnl = *trade.RealizedPnL
+		}
+		report.Evidence = append(report.Evidence, PackReportTrade{
+			EvidenceID:  row.id,
+			Time:        trade.TradeTime.In(loc).Format("2006-01-02 15:04:05"),
+			Exchange:    trade.Exchange,
+			Symbol:      trade.Symbol,
+			Side:        strings.ToUpper(trade.Side),
+			Quantity:    trade.Quantity,
+			Price:       trade.Price,
+			RealizedPnL: pnl,
+		})
+	}
+	return report, nil
+}
+
+// packEvidenceRow is a trade in the evidence appendix with its evidence ID.
+type packEvidenceRow struct {
+	id    string
+	trade *entities.Trade
+}
+
+// liveEvidenceTrades rebuilds the evidence of a pack stored without a
+// bundle from the trades now in its range. It returns every trade for packs
+// that predate evidence tracking, and otherwise the pack's evidence trades
+// plus the IDs of those no longer stored.
+func (s *SummaryPackService) liveEvidenceTrades(ctx context.Context, pack *entities.SummaryPack, start, end time.Time) ([]packEvidenceRow, []string, error) {
+	trades, err := s.tradeRepo.ListByTimeRange(ctx, pack.UserID, start, end)
+	if err != nil {
+		return nil, nil, err
+	}
+	evidenceID := summaryPackEvidenceID
+	if hasLegacyEvidenceIDs(pack.EvidenceTradeIDs) {
+		evidenceID = legacySummaryPackEvidenceID
+	}
+
+	var rows []packEvidenceRow
+	if pack.EvidenceTradeIDs == nil {
+		for _, trade := range trades {
+			rows = append(rows, packEvidenceRow{id: evidenceID(trade), trade: trade})
+		}
+		return rows, nil, nil
+	}
+
+	wanted := make(map[string]bool, len(pack.EvidenceTradeIDs))
+	for _, id := range pack.EvidenceTradeIDs {
+		wanted[id] = true
+	}
+	found := map[string]bool{}
+	for _, trade := range trades {
+		id := evidenceID(trade)
+		if wanted[id] {
+			rows = append(rows, packEvidenceRow{id: id, trade: trade})
+			found[id] = true
+		}
+	}
+	missing := []string{}
+	for _, id := range pack.EvidenceTradeIDs {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	return rows, missing, nil
+}
+
+// readEvidenceBundleTrades reads the rows of trades.csv in an evidence
+// bundle, so the report shows the trades the pack was hashed over.
+func readEvidenceBundleTrades(archive []byte) ([]packEvidenceRow, error) {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, err
+	}
+	var file *zip.File
+	for _, entry := range zr.File {
+		if entry.Name == "trades.csv" {
+			file = entry
+			break
+		}
+	}
+	if file == nil {
+		return nil, fmt.Errorf("trades.csv not found")
+	}
+	body, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	records, err := csv.NewReader(body).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("trades.csv: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("trades.csv: missing header")
+	}
+	columns := make(map[string]int, len(records[0]))
+	for i, name := range records[0] {
+		columns[name] = i
+	}
+	for _, name := range []string{"evidence_id", "exchange", "symbol", "side", "quantity", "price", "realized_pnl", "trade_time"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("trades.csv: missing column %s", name)
+		}
+	}
+
+	rows := make([]packEvidenceRow, 0, len(records)-1)
+	for _, record := range records[1:] {
+		tradeTime, err := time.Parse(time.RFC3339Nano, record[columns["trade_time"]])
+		if err != nil {
+			return nil, fmt.Errorf("trades.csv: %w", err)
+		}
+		trade := &entities.Trade{
+			Exchange:  record[columns["exchange"]],
+			Symbol:    record[columns["symbol"]],
+			Side:      record[columns["side"]],
+			Quantity:  record[columns["quantity"]],
+			Price:     record[columns["price"]],
+			TradeTime: tradeTime,
+		}
+		if pnl := record[columns["realized_pnl"]]; pnl != "" {
+			trade.RealizedPnL = &pnl
+		}
+		rows = append(rows, packEvidenceRow{id: record[columns["evidence_id"]], trade: trade})
+	}
+	return rows, nil
+}
+
+// packReportDays buckets trades by calendar day in loc with a running
+// realized PnL total.
+func packReportDays(trades []*entities.Trade, loc *time.Location) []PackReportDay {
+	var days []PackReportDay
+	cumulative := 0.0
+	for _, trade := range trades {
+		date := trade.TradeTime.In(loc).Format("2006-01-02")
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, PackReportDay{Date: date, Cumulative: cumulative})
+		}
+		day := &days[len(days)-1]
+		day.TradeCount++
+		if trade.RealizedPnL != nil {
+			if pnl, err := strconv.ParseFloat(*trade.RealizedPnL, 64); err == nil {
+				day.RealizedPnL += pnl
+				cumulative += pnl
+				day.Cumulative = cumulative
+			}
+		}
+	}
+	return days
+}
+
+func packReportTime(raw string, loc *time.Location) string {
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return raw
+	}
+	return t.In(loc).Format("2006-01-02 15:04")
+}
+
+type packReportBar struct {
+	X, Y, W, H float64
+	Negative   bool
+}
+
+// packReportChart is chart geometry in a width x height box with y growing
+// downwards, shared by the SVG and the PDF renderers.
+type packReportChart struct {
+	Width, Height float64
+	Bars          []packReportBar
+	// Line holds x, y pairs.
+	Line     []float64
+	ZeroY    float64
+	MaxLabel string
+	MinLabel string
+}
+
+// pnlChart draws daily realized PnL as bars and the running total as a line
+// on a shared scale.
+func pnlChart(days []PackReportDay, width, height float64) packReportChart {
+	chart := packReportChart{Width: width, Height: height}
+	if len(days) == 0 {
+		chart.ZeroY = height / 2
+		return chart
+	}
+	maxValue, minValue := 0.0, 0.0
+	for _, day := range days {
+		maxValue = math.Max(maxValue, math.Max(day.RealizedPnL, day.Cumulative))
+		minValue = math.Min(minValue, math.Min(day.RealizedPnL, day.Cumulative))
+	}
+	if maxValue == minValue {
+		maxValue = 1
+	}
+	scale := height / (maxValue - minValue)
+	chart.ZeroY = maxValue * scale
+	chart.MaxLabel = strconv.FormatFloat(maxValue, 'f', 2, 64)
+	chart.MinLabel = strconv.FormatFloat(minValue, 'f', 2, 64)
+
+	slot := width / float64(len(days))
+	for i, day := range days {
+		x := float64(i)*slot + slot*0.15
+		barHeight := math.Abs(day.RealizedPnL) * scale
+		bar := packReportBar{X: x, Y: chart.ZeroY - barHeight, W: slot * 0.7, H: barHeight}
+		if day.RealizedPnL < 0 {
+			bar.Y = chart.ZeroY
+			bar.Negative = true
+		}
+		chart.Bars = append(chart.Bars, bar)
+		chart.Line = append(chart.Line, float64(i)*slot+slot/2, chart.ZeroY-day.Cumulative*scale)
+	}
+	return chart
+}
+
+// tradeCountChart draws the number of trades per day.
+func tradeCountChart(days []PackReportDay, width, height float64) packReportChart {
+	chart := packReportChart{Width: width, Height: height, ZeroY: height, MinLabel: "0"}
+	maxCount := 0
+	for _, day := range days {
+		if day.TradeCount > maxCount {
+			maxCount = day.TradeCount
+		}
+	}
+	if maxCount == 0 {
+		return chart
+	}
+	chart.MaxLabel = strconv.Itoa(maxCount)
+	slot := width / float64(len(days))
+	for i, day := range days {
+		barHeight := float64(day.TradeCount) / float64(maxCount) * height
+		chart.Bars = append(chart.Bars, packReportBar{X: float64(i)*slot + slot*0.15, Y: height - barHeight, W: slot * 0.7, H: barHeight})
+	}
+	return chart
+}
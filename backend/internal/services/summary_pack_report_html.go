@@ -0,0 +1,130 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+var packReportTemplate = template.Must(template.New("pack_report").Funcs(template.FuncMap{
+	"points": func(line []float64) string {
+		parts := make([]string, 0, len(line)/2)
+		for i := 0; i+1 < len(line); i += 2 {
+			parts = append(parts, fmt.Sprintf("%.1f,%.1f", line[i], line[i+1]))
+		}
+		return strings.Join(parts, " ")
+	},
+	"px":   func(v float64) string { return fmt.Sprintf("%.1f", v) },
+	"join": strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="kifu-pack-id" content="{{.Report.PackID}}">
+<meta name="kifu-content-hash" content="{{.Report.ContentHash}}">
+<title>Summary pack {{.Report.Range}} · {{.Report.Start}} – {{.Report.End}}</title>
+<style>
+body{font-family:-apple-system,"Segoe UI",Roboto,"Noto Sans KR",sans-serif;color:#1f2328;margin:0;background:#f6f8fa}
+main{max-width:960px;margin:0 auto;padding:32px 24px;background:#fff}
+h1{font-size:24px;margin:0 0 4px}h2{font-size:18px;margin:32px 0 12px;border-bottom:1px solid #d0d7de;padding-bottom:6px}
+.muted{color:#656d76;font-size:13px}
+.grid{display:grid;grid-template-columns:repeat(auto-fill,minmax(200px,1fr));gap:12px}
+.metric{border:1px solid #d0d7de;border-radius:6px;padding:10px 12px}.metric b{display:block;font-size:18px;margin-top:4px}
+.status-ok{color:#1a7f37}.status-warning{color:#9a6700}.status-error{color:#cf222e}
+.callout{border-left:4px solid #bf8700;background:#fff8c5;padding:10px 14px;margin:12px 0}
+table{border-collapse:collapse;width:100%;font-size:12px}th,td{border-bottom:1px solid #eaeef2;padding:4px 6px;text-align:left}
+td.num{text-align:right;font-variant-numeric:tabular-nums}
+code{font-family:ui-monospace,SFMono-Regular,Menlo,monospace;word-break:break-all}
+svg{display:block;width:100%;height:auto}
+</style>
+</head>
+<body>
+<main>
+<h1>Summary pack · {{.Report.Range}}</h1>
+<p class="muted">{{.Report.Start}} – {{.Report.End}} ({{.Report.Timezone}}) · generated {{.Report.CreatedAt}}</p>
+{{if .Report.Redactions}}<p class="callout">Shared view. Hidden: {{join .Report.Redactions ", "}}.{{if .Report.Redacts "amounts"}} Amounts are shown as a percentage of notional volume.{{end}}</p>{{end}}
+
+<h2>Profit and loss</h2>
+<div class="grid">{{range .Report.PnL}}<div class="metric"><span class="muted">{{.Label}}</span><b>{{.Value}}</b></div>{{end}}</div>
+
+<h2>Activity</h2>
+<div class="grid">{{range .Report.Activity}}<div class="metric"><span class="muted">{{.Label}}</span><b>{{.Value}}</b></div>{{end}}{{range .Report.Flows}}<div class="metric"><span class="muted">{{.Label}}</span><b>{{.Value}}</b></div>{{end}}</div>
+
+<h2>Daily realized PnL</h2>
+{{if .Report.Days}}
+<svg viewBox="-60 -10 760 240" role="img" aria-label="Daily realized PnL and running total">
+<line x1="0" y1="{{px .PnL.ZeroY}}" x2="{{px .PnL.Width}}" y2="{{px .PnL.ZeroY}}" stroke="#8c959f" stroke-width="1"/>
+<text x="-8" y="8" text-anchor="end" font-size="11" fill="#656d76">{{.PnL.MaxLabel}}</text>
+<text x="-8" y="{{px .PnL.Height}}" text-anchor="end" font-size="11" fill="#656d76">{{.PnL.MinLabel}}</text>
+{{range .PnL.Bars}}<rect x="{{px .X}}" y="{{px .Y}}" width="{{px .W}}" height="{{px .H}}" fill="{{if .Negative}}#cf222e{{else}}#1a7f37{{end}}"/>{{end}}
+<polyline points="{{points .PnL.Line}}" fill="none" stroke="#0969da" stroke-width="2"/>
+</svg>
+<p class="muted">Bars: realized PnL per day{{if .Report.Redacts "amounts"}} as % of notional volume{{end}}. Line: running total.</p>
+
+<h2>Trades per day</h2>
+<svg viewBox="-60 -10 760 160" role="img" aria-label="Trades per day">
+<line x1="0" y1="{{px .Trades.ZeroY}}" x2="{{px .Trades.Width}}" y2="{{px .Trades.ZeroY}}" stroke="#8c959f" stroke-width="1"/>
+<text x="-8" y="8" text-anchor="end" font-size="11" fill="#656d76">{{.Trades.MaxLabel}}</text>
+{{range .Trades.Bars}}<rect x="{{px .X}}" y="{{px .Y}}" width="{{px .W}}" height="{{px .H}}" fill="#0969da"/>{{end}}
+</svg>
+<p class="muted">{{.FirstDay}} – {{.LastDay}}</p>
+{{else}}
+<p class="muted">No trades in this range.</p>
+{{end}}
+
+<h2>Reconciliation</h2>
+<p>Status: <b class="status-{{.Report.ReconciliationStatus}}">{{.Report.ReconciliationStatus}}</b> · missing suspects {{.Report.MissingSuspects}} · duplicate suspects {{.Report.DuplicateSuspects}}</p>
+{{if .Report.NormalizationWarnings}}<div class="callout">Normalization warnings:<ul>{{range .Report.NormalizationWarnings}}<li><code>{{.}}</code></li>{{end}}</ul></div>{{end}}
+
+<h2>Evidence appendix</h2>
+{{if .Report.Redacts "evidence"}}<p class="muted">{{.Report.EvidenceTotal}} trade(s); the trade list is hidden in this shared view.</p>
+{{else}}
+{{if not .Report.EvidenceExact}}<p class="callout">This pack predates evidence tracking; the trades below are those currently stored for its range.</p>{{end}}
+{{if .Report.EvidenceMissing}}<p class="callout">{{len .Report.EvidenceMissing}} evidence trade(s) are no longer stored: <code>{{join .Report.EvidenceMissing ", "}}</code></p>{{end}}
+<p class="muted">{{.Report.EvidenceTotal}} trade(s){{if gt .Report.EvidenceTotal (len .Report.Evidence)}}, first {{len .Report.Evidence}} shown{{end}}.</p>
+<table>
+<thead><tr><th>Time</th><th>Trade ID</th><th>Exchange</th><th>Symbol</th><th>Side</th><th>Qty</th><th>Price</th><th>Realized PnL</th></tr></thead>
+<tbody>{{range .Report.Evidence}}<tr><td>{{.Time}}</td><td><code>{{.EvidenceID}}</code></td><td>{{.Exchange}}</td><td>{{.Symbol}}</td><td>{{.Side}}</td><td class="num">{{.Quantity}}</td><td class="num">{{.Price}}</td><td class="num">{{.RealizedPnL}}</td></tr>{{end}}</tbody>
+</table>
+{{end}}
+
+<h2>Verification</h2>
+<p class="muted">Compare the content hash with the one returned by the API for this pack.</p>
+<table>
+<tr><th>Pack ID</th><td><code>{{.Report.PackID}}</code></td></tr>
+<tr><th>Source run</th><td><code>{{.Report.SourceRunID}}</code></td></tr>
+<tr><th>Schema / calc version</th><td><code>{{.Report.SchemaVersion}}</code> / <code>{{.Report.CalcVersion}}</code></td></tr>
+<tr><th>Content hash (SHA-256)</th><td><code id="content-hash">{{.Report.ContentHash}}</code></td></tr>
+</table>
+</main>
+</body>
+</html>
+`))
+
+// RenderPackReportHTML renders the report as a single HTML document with
+// inline styles and SVG charts, so it can be mailed or saved as is.
+func RenderPackReportHTML(report *PackReport) ([]byte, error) {
+	data := struct {
+		Report            *PackReport
+		PnL               packReportChart
+		Trades            packReportChart
+		FirstDay, LastDay string
+	}{
+		Report: report,
+		PnL:    pnlChart(report.Days, 700, 220),
+		Trades: tradeCountChart(report.Days, 700, 140),
+	}
+	if len(report.Days) > 0 {
+		data.FirstDay = report.Days[0].Date
+		data.LastDay = report.Days[len(report.Days)-1].Date
+	}
+
+	var buf bytes.Buffer
+	err := packReportTemplate.Execute(&buf, data)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
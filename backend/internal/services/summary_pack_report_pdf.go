@@ -0,0 +1,231 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/infrastructure/report"
+)
+
+const (
+	pdfMargin     = 40.0
+	pdfContentEnd = report.PageHeight - 50
+)
+
+var (
+	pdfTextColor  = report.Color{R: 0.12, G: 0.14, B: 0.16}
+	pdfMutedColor = report.Color{R: 0.4, G: 0.43, B: 0.46}
+	pdfRuleColor  = report.Color{R: 0.82, G: 0.84, B: 0.87}
+	pdfGainColor  = report.Color{R: 0.1, G: 0.5, B: 0.22}
+	pdfLossColor  = report.Color{R: 0.81, G: 0.13, B: 0.18}
+	pdfLineColor  = report.Color{R: 0.04, G: 0.41, B: 0.85}
+	pdfWarnColor  = report.Color{R: 0.6, G: 0.4, B: 0}
+)
+
+// pdfReportWriter lays the report out top to bottom and starts a new page,
+// with the pack ID and content hash in its footer, when one fills up.
+type pdfReportWriter struct {
+	doc    *report.PDF
+	report *PackReport
+	y      float64
+}
+
+// RenderPackReportPDF renders the report as a PDF. The standard PDF fonts
+// only cover Latin text, so the layout mirrors the HTML report in English.
+func RenderPackReportPDF(r *PackReport) ([]byte, error) {
+	w := &pdfReportWriter{
+		doc: report.NewPDF(map[string]string{
+			"Title":       fmt.Sprintf("Summary pack %s (%s)", r.PackID, r.Range),
+			"Subject":     "content_hash " + r.ContentHash,
+			"PackID":      r.PackID,
+			"ContentHash": r.ContentHash,
+		}),
+		report: r,
+	}
+	w.newPage()
+
+	w.text(20, true, pdfTextColor, "Summary pack - "+r.Range)
+	w.text(9, false, pdfMutedColor, fmt.Sprintf("%s - %s (%s), generated %s", r.Start, r.End, r.Timezone, r.CreatedAt))
+	if len(r.Redactions) > 0 {
+		note := "Shared view. Hidden: " + strings.Join(r.Redactions, ", ") + "."
+		if r.Redacts(entities.PackShareRedactAmounts) {
+			note += " Amounts are shown as a percentage of notional volume."
+		}
+		w.text(9, false, pdfWarnColor, note)
+	}
+	w.y += 6
+
+	w.heading("Profit and loss")
+	w.metrics(r.PnL)
+	w.heading("Activity")
+	w.metrics(append(append([]PackReportMetric{}, r.Activity...), r.Flows...))
+
+	w.heading("Daily realized PnL")
+	if len(r.Days) == 0 {
+		w.text(9, false, pdfMutedColor, "No trades in this range.")
+	} else {
+		w.chart(pnlChart(r.Days, report.PageWidth-2*pdfMargin-50, 150), true)
+		unit := ""
+		if r.Redacts(entities.PackShareRedactAmounts) {
+			unit = " as % of notional volume"
+		}
+		w.text(8, false, pdfMutedColor, fmt.Sprintf("Bars: realized PnL per day%s. Line: running total. %s - %s", unit, r.Days[0].Date, r.Days[len(r.Days)-1].Date))
+		w.heading("Trades per day")
+		w.chart(tradeCountChart(r.Days, report.PageWidth-2*pdfMargin-50, 80), false)
+	}
+
+	w.heading("Reconciliation")
+	statusColor := pdfGainColor
+	if r.ReconciliationStatus != "ok" {
+		statusColor = pdfWarnColor
+	}
+	w.text(10, true, statusColor, fmt.Sprintf("Status: %s - missing suspects %d, duplicate suspects %d",
+		r.ReconciliationStatus, r.MissingSuspects, r.DuplicateSuspects))
+	for _, warning := range r.NormalizationWarnings {
+		w.text(9, false, pdfWarnColor, "Warning: "+warning)
+	}
+
+	w.evidence()
+
+	w.heading("Verification")
+	w.text(9, false, pdfMutedColor, "Compare the content hash with the one returned by the API for this pack.")
+	w.text(9, false, pdfTextColor, "Pack ID: "+r.PackID)
+	w.text(9, false, pdfTextColor, "Source run: "+r.SourceRunID)
+	w.text(9, false, pdfTextColor, fmt.Sprintf("Schema / calc version: %s / %s", r.SchemaVersion, r.CalcVersion))
+	w.text(9, true, pdfTextColor, "Content hash (SHA-256): "+r.ContentHash)
+
+	return w.doc.Bytes()
+}
+
+func (w *pdfReportWriter) newPage() {
+	w.doc.AddPage()
+	w.y = pdfMargin
+	footer := fmt.Sprintf("Pack %s - content hash %s - page %d", w.report.PackID, w.report.ContentHash, w.doc.PageCount())
+	w.doc.Text(pdfMargin, report.PageHeight-24, 6.5, false, pdfMutedColor, footer)
+}
+
+// ensure starts a new page unless height more points fit on this one.
+func (w *pdfReportWriter) ensure(height float64) {
+	if w.y+height > pdfContentEnd {
+		w.newPage()
+	}
+}
+
+func (w *pdfReportWriter) text(size float64, bold bool, color report.Color, s string) {
+	w.ensure(size * 1.5)
+	w.y += size * 1.2
+	w.doc.Text(pdfMargin, w.y, size, bold, color, s)
+	w.y += size * 0.4
+}
+
+func (w *pdfReportWriter) heading(s string) {
+	w.ensure(40)
+	w.y += 14
+	w.text(13, true, pdfTextColor, s)
+	w.doc.Line(0.5, pdfRuleColor, pdfMargin, w.y, report.PageWidth-pdfMargin, w.y)
+	w.y += 6
+}
+
+// metrics lays label/value pairs out in three columns.
+func (w *pdfReportWriter) metrics(metrics []PackReportMetric) {
+	column := (report.PageWidth - 2*pdfMargin) / 3
+	for i := 0; i < len(metrics); i += 3 {
+		w.ensure(30)
+		for j := i; j < i+3 && j < len(metrics); j++ {
+			x := pdfMargin + float64(j-i)*column
+			w.doc.Text(x, w.y+9, 8, false, pdfMutedColor, metrics[j].Label)
+			w.doc.Text(x, w.y+22, 11, true, pdfTextColor, pdfTruncate(metrics[j].Value, 28))
+		}
+		w.y += 30
+	}
+}
+
+// chart draws chart geometry offset to the current position, with the
+// scale labels in a 50 point gutter on the left.
+func (w *pdfReportWriter) chart(chart packReportChart, withLine bool) {
+	w.ensure(chart.Height + 16)
+	left, top := pdfMargin+50, w.y+6
+	w.doc.Text(pdfMargin, top+7, 7, false, pdfMutedColor, chart.MaxLabel)
+	if withLine {
+		w.doc.Text(pdfMargin, top+chart.Height, 7, false, pdfMutedColor, chart.MinLabel)
+	}
+	for _, bar := range chart.Bars {
+		color := pdfLineColor
+		if withLine {
+			color = pdfGainColor
+			if bar.Negative {
+				color = pdfLossColor
+			}
+		}
+		w.doc.Rect(left+bar.X, top+bar.Y, bar.W, bar.H, color)
+	}
+	w.doc.Line(0.5, pdfMutedColor, left, top+chart.ZeroY, left+chart.Width, top+chart.ZeroY)
+	if withLine && len(chart.Line) >= 4 {
+		points := make([]float64, len(chart.Line))
+		for i := 0; i < len(chart.Line); i += 2 {
+			points[i], points[i+1] = left+chart.Line[i], top+chart.Line[i+1]
+		}
+		w.doc.Line(1.5, pdfLineColor, points...)
+	}
+	w.y = top + chart.Height + 10
+}
+
+var pdfEvidenceColumns = []struct {
+	title string
+	x     float64
+	width int
+}{
+	{"Time", 0, 20}, {"Trade ID", 78, 22}, {"Exchange", 168, 16}, {"Symbol", 233, 12},
+	{"Side", 285, 5}, {"Qty", 312, 14}, {"Price", 372, 14}, {"Realized PnL", 432, 16},
+}
+
+func (w *pdfReportWriter) evidence() {
+	r := w.report
+	w.heading("Evidence appendix")
+	if r.Redacts(entities.PackShareRedactEvidence) {
+		w.text(8, false, pdfMutedColor, fmt.Sprintf("%d trade(s); the trade list is hidden in this shared view.", r.EvidenceTotal))
+		return
+	}
+	if !r.EvidenceExact {
+		w.text(8, false, pdfWarnColor, "This pack predates evidence tracking; the trades below are those currently stored for its range.")
+	}
+	if len(r.EvidenceMissing) > 0 {
+		w.text(8, false, pdfWarnColor, fmt.Sprintf("%d evidence trade(s) are no longer stored.", len(r.EvidenceMissing)))
+	}
+	summary := strconv.Itoa(r.EvidenceTotal) + " trade(s)"
+	if r.EvidenceTotal > len(r.Evidence) {
+		summary += fmt.Sprintf(", first %d shown", len(r.Evidence))
+	}
+	w.text(8, false, pdfMutedColor, summary+".")
+
+	header := func() {
+		w.y += 4
+		for _, column := range pdfEvidenceColumns {
+			w.doc.Text(pdfMargin+column.x, w.y+8, 7, true, pdfTextColor, column.title)
+		}
+		w.y += 12
+	}
+	header()
+	for _, trade := range r.Evidence {
+		if w.y+10 > pdfContentEnd {
+			w.newPage()
+			header()
+		}
+		values := []string{trade.Time, trade.EvidenceID, trade.Exchange, trade.Symbol, trade.Side, trade.Quantity, trade.Price, trade.RealizedPnL}
+		for i, column := range pdfEvidenceColumns {
+			w.doc.Text(pdfMargin+column.x, w.y+7, 6.5, false, pdfTextColor, pdfTruncate(values[i], column.width))
+		}
+		w.y += 10
+	}
+}
+
+// pdfTruncate shortens s to at most n characters.
+func pdfTruncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "~"
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+func TestSummaryPackReport(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 13, 9, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+	winning := newTrade(4001, "binance_futures", "BTCUSDT", "SELL", "1", "10000", now.Add(-26*time.Hour))
+	winning.RealizedPnL = tradePtr("150")
+	losing := newTrade(4002, "binance_futures", "<b>ETHUSDT</b>", "SELL", "2", "2000", now.Add(-time.Hour))
+	losing.RealizedPnL = tradePtr("-40")
+	trades := []*entities.Trade{winning, losing}
+
+	svc := baseService(now)
+	svc.tradeRepo = &summaryPackTestTradeRepo{trades: trades}
+	run := &entities.Run{RunID: uuid.New(), RunType: "exchange_sync", Meta: mustJSON(map[string]any{"exchange": "binance_futures"})}
+	pack, _, err := svc.GeneratePack(context.Background(), userID, run, "7d")
+	if err != nil {
+		t.Fatalf("GeneratePack failed: %v", err)
+	}
+	pack.CreatedAt = now
+
+	// A trade imported after the pack was generated is not evidence, and a
+	// trade deleted since is still listed from the bundle.
+	late := newTrade(4003, "binance_futures", "BTCUSDT", "BUY", "1", "10000", now.Add(-30*time.Minute))
+	svc.tradeRepo = &summaryPackTestTradeRepo{trades: []*entities.Trade{losing, late}}
+	if pack.EvidenceBundle == nil {
+		t.Fatalf("expected the generated pack to carry its evidence bundle")
+	}
+
+	report, err := svc.BuildReport(context.Background(), pack)
+	if err != nil {
+		t.Fatalf("BuildReport failed: %v", err)
+	}
+	if !report.EvidenceExact || report.EvidenceTotal != 2 || len(report.EvidenceMissing) != 0 {
+		t.Fatalf("unexpected evidence: exact=%v total=%d missing=%v", report.EvidenceExact, report.EvidenceTotal, report.EvidenceMissing)
+	}
+	if len(report.Days) != 2 || report.Days[1].RealizedPnL != -40 || report.Days[1].Cumulative != 110 {
+		t.Fatalf("unexpected daily series: %+v", report.Days)
+	}
+	if report.Evidence[0].EvidenceID != "binance_futures:BTCUSDT:4001" || report.Evidence[0].RealizedPnL != "150" {
+		t.Fatalf("unexpected bundle evidence row: %+v", report.Evidence[0])
+	}
+
+	html, err := RenderPackReportHTML(report)
+	if err != nil {
+		t.Fatalf("RenderPackReportHTML failed: %v", err)
+	}
+	page := string(html)
+	if !strings.Contains(page, `<meta name="kifu-content-hash" content="`+pack.ContentHash+`">`) {
+		t.Fatalf("content hash not embedded in the HTML report")
+	}
+	if strings.Contains(page, "<b>ETHUSDT</b>") || !strings.Contains(page, "&lt;b&gt;ETHUSDT&lt;/b&gt;") {
+		t.Fatalf("trade fields must be escaped")
+	}
+	if strings.Contains(page, "4003") {
+		t.Fatalf("trade outside the pack evidence was listed")
+	}
+
+	pdf, err := RenderPackReportPDF(report)
+	if err != nil {
+		t.Fatalf("RenderPackReportPDF failed: %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) || !bytes.Contains(pdf, []byte("/ContentHash ("+pack.ContentHash+")")) {
+		t.Fatalf("content hash not embedded in the PDF report")
+	}
+}
+
+func TestSummaryPackReportWithoutBundleUsesStoredTrades(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 13, 9, 0, 0, 0, time.UTC)
+	kept := newTrade(4101, "binance_futures", "BTCUSDT", "SELL", "1", "10000", now.Add(-2*time.Hour))
+	deleted := newTrade(4102, "binance_futures", "ETHUSDT", "SELL", "1", "2000", now.Add(-time.Hour))
+
+	svc := baseService(now)
+	svc.tradeRepo = &summaryPackTestTradeRepo{trades: []*entities.Trade{kept, deleted}}
+	run := &entities.Run{RunID: uuid.New(), RunType: "exchange_sync", Meta: mustJSON(map[string]any{"exchange": "binance_futures"})}
+	pack, _, err := svc.GeneratePack(context.Background(), uuid.New(), run, "7d")
+	if err != nil {
+		t.Fatalf("GeneratePack failed: %v", err)
+	}
+	pack.EvidenceBundle = nil
+	svc.tradeRepo = &summaryPackTestTradeRepo{trades: []*entities.Trade{kept}}
+
+	report, err := svc.BuildReport(context.Background(), pack)
+	if err != nil {
+		t.Fatalf("BuildReport failed: %v", err)
+	}
+	if report.EvidenceTotal != 1 || len(report.EvidenceMissing) != 1 || report.EvidenceMissing[0] != "binance_futures:ETHUSDT:4102" {
+		t.Fatalf("unexpected evidence: total=%d missing=%v", report.EvidenceTotal, report.EvidenceMissing)
+	}
+}
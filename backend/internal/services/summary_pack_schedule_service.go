@@ -0,0 +1,333 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/infrastructure/notification"
+)
+
+const (
+	packScheduleClaimLease = 10 * time.Minute
+	packScheduleClaimLimit = 50
+)
+
+var ErrInvalidPackSchedule = errors.New("invalid pack schedule")
+
+// defaultPackScheduleRanges is the pack range used when a schedule does not
+// name one.
+var defaultPackScheduleRanges = map[entities.PackScheduleFrequency]string{
+	entities.PackScheduleDaily:   "7d",
+	entities.PackScheduleWeekly:  "7d",
+	entities.PackScheduleMonthly: "30d",
+}
+
+var packScheduleChannels = map[entities.ChannelType]bool{
+	entities.ChannelTelegram: true,
+	entities.ChannelEmail:    true,
+}
+
+// packWarningLabels describes normalization warnings in the digest.
+var packWarningLabels = map[string]string{
+	"symbol_mapping_gap": "심볼 매핑 누락",
+	"time_skew":          "거래 시각 불일치",
+}
+
+type packScheduleSender interface {
+	SendToChannels(ctx context.Context, userID uuid.UUID, msg notification.Message, types []entities.ChannelType) ([]entities.ChannelType, error)
+}
+
+// SummaryPackScheduleService generates summary packs on each user's schedule,
+// delivers a digest over the chosen channels and prunes old packs.
+type SummaryPackScheduleService struct {
+	scheduleRepo repositories.SummaryPackScheduleRepository
+	packRepo     repositories.SummaryPackRepository
+	runRepo      repositories.RunRepository
+	userRepo     repositories.UserRepository
+	packs        *SummaryPackService
+	sender       packScheduleSender
+	retention    time.Duration
+	appBaseURL   string
+	now          func() time.Time
+}
+
+func NewSummaryPackScheduleService(
+	scheduleRepo repositories.SummaryPackScheduleRepository,
+	packRepo repositories.SummaryPackRepository,
+	runRepo repositories.RunRepository,
+	userRepo repositories.UserRepository,
+	packs *SummaryPackService,
+	sender packScheduleSender,
+) *SummaryPackScheduleService {
+	appURL := os.Getenv("APP_BASE_URL")
+	if appURL == "" {
+		appURL = "http://localhost:5173"
+	}
+	// Packs are kept forever unless SUMMARY_PACK_RETENTION_DAYS opts in.
+	retentionDays := 0
+	if raw := strings.TrimSpace(os.Getenv("SUMMARY_PACK_RETENTION_DAYS")); raw != "" {
+		if days, err := strconv.Atoi(raw); err == nil && days >= 0 {
+			retentionDays = days
+		} else {
+			log.Printf("summary pack schedule: ignoring invalid SUMMARY_PACK_RETENTION_DAYS %q", raw)
+		}
+	}
+	return &SummaryPackScheduleService{
+		scheduleRepo: scheduleRepo,
+		packRepo:     packRepo,
+		runRepo:      runRepo,
+		userRepo:     userRepo,
+		packs:        packs,
+		sender:       sender,
+		retention:    time.Duration(retentionDays) * 24 * time.Hour,
+		appBaseURL:   appURL,
+		now:          time.Now,
+	}
+}
+
+// ValidatePackSchedule checks a schedule after defaults have been applied.
+func ValidatePackSchedule(schedule *entities.SummaryPackSchedule) error {
+	if _, ok := defaultPackScheduleRanges[schedule.Frequency]; !ok {
+		return fmt.Errorf("frequency must be daily, weekly or monthly")
+	}
+	if _, err := ParseSummaryRange(schedule.Range); err != nil {
+		return err
+	}
+	if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+		return fmt.Errorf("unknown timezone: %s", schedule.Timezone)
+	}
+	if schedule.SendHour < 0 || schedule.SendHour > 23 {
+		return fmt.Errorf("send_hour must be between 0 and 23")
+	}
+	if schedule.Weekday < 0 || schedule.Weekday > 6 {
+		return fmt.Errorf("weekday must be between 0 (Sunday) and 6")
+	}
+	if schedule.DayOfMonth < 1 || schedule.DayOfMonth > 28 {
+		return fmt.Errorf("day_of_month must be between 1 and 28")
+	}
+	if len(schedule.Channels) == 0 {
+		return fmt.Errorf("at least one channel is required")
+	}
+	seen := map[entities.ChannelType]bool{}
+	for _, channel := range schedule.Channels {
+		if !packScheduleChannels[channel] {
+			return fmt.Errorf("unsupported channel: %s", channel)
+		}
+		if seen[channel] {
+			return fmt.Errorf("duplicate channel: %s", channel)
+		}
+		seen[channel] = true
+	}
+	return nil
+}
+
+func (s *SummaryPackScheduleService) ListSchedules(ctx context.Context, userID uuid.UUID) ([]*entities.SummaryPackSchedule, error) {
+	return s.scheduleRepo.ListByUser(ctx, userID)
+}
+
+// SaveSchedule fills in the default range and the user's profile timezone,
+// validates the schedule and stores it with its next run time.
+func (s *SummaryPackScheduleService) SaveSchedule(ctx context.Context, schedule *entities.SummaryPackSchedule) error {
+	if schedule.Range == "" {
+		schedule.Range = defaultPackScheduleRanges[schedule.Frequency]
+	}
+	schedule.Timezone = "UTC"
+	if s.userRepo != nil {
+		user, err := s.userRepo.GetByID(ctx, schedule.UserID)
+		if err != nil {
+			return err
+		}
+		if user != nil {
+			schedule.Timezone = user.TradingDay().Timezone()
+		}
+	}
+	if err := ValidatePackSchedule(schedule); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPackSchedule, err)
+	}
+	schedule.NextRunAt = schedule.NextRunAfter(s.now())
+	return s.scheduleRepo.Upsert(ctx, schedule)
+}
+
+func (s *SummaryPackScheduleService) DeleteSchedule(ctx context.Context, userID uuid.UUID, frequency entities.PackScheduleFrequency) (bool, error) {
+	return s.scheduleRepo.Delete(ctx, userID, frequency)
+}
+
+// RunDue runs every schedule that is due. A failed schedule is recorded on
+// the schedule and retried at its next regular time; when no newer run has
+// completed by then, the undelivered pack is sent again.
+func (s *SummaryPackScheduleService) RunDue(ctx context.Context) error {
+	now := s.now().UTC()
+	schedules, err := s.scheduleRepo.ClaimDue(ctx, now, packScheduleClaimLease, packScheduleClaimLimit)
+	if err != nil {
+		return err
+	}
+	for _, schedule := range schedules {
+		status, packID, runErr := s.runSchedule(ctx, schedule)
+		schedule.LastRunAt = &now
+		schedule.LastStatus = &status
+		schedule.LastError = nil
+		if runErr != nil {
+			message := runErr.Error()
+			schedule.LastError = &message
+			log.Printf("summary pack schedule %s: %v", schedule.ID, runErr)
+		}
+		schedule.LastPackID = packID
+		schedule.NextRunAt = schedule.NextRunAfter(now)
+		if err := s.scheduleRepo.RecordRun(ctx, schedule); err != nil {
+			log.Printf("summary pack schedule %s: record run failed: %v", schedule.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *SummaryPackScheduleService) runSchedule(ctx context.Context, schedule *entities.SummaryPackSchedule) (string, *uuid.UUID, error) {
+	run, err := s.runRepo.GetLatestCompletedRun(ctx, schedule.UserID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return entities.PackScheduleStatusFailed, nil, err
+	}
+	if run == nil {
+		return entities.PackScheduleStatusNoRun, nil, nil
+	}
+	if schedule.LastPackID != nil {
+		last, err := s.packRepo.GetByID(ctx, schedule.UserID, *schedule.LastPackID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return entities.PackScheduleStatusFailed, schedule.LastPackID, err
+		}
+		if last != nil && last.SourceRunID == run.RunID {
+			if packScheduleDelivered(schedule.LastStatus) {
+				return entities.PackScheduleStatusNoNewRun, schedule.LastPackID, nil
+			}
+			// The last digest never went out; send that pack again.
+			return s.deliver(ctx, schedule, last)
+		}
+	}
+
+	pack, _, err := s.packs.GeneratePack(ctx, schedule.UserID, run, schedule.Range)
+	if err != nil {
+		return entities.PackScheduleStatusFailed, nil, err
+	}
+	if err := s.packRepo.Create(ctx, pack); err != nil {
+		return entities.PackScheduleStatusFailed, nil, err
+	}
+	return s.deliver(ctx, schedule, pack)
+}
+
+// packScheduleDelivered reports whether the last run left the digest of the
+// schedule's last pack delivered.
+func packScheduleDelivered(status *string) bool {
+	return status != nil && (*status == entities.PackScheduleStatusSent || *status == entities.PackScheduleStatusNoNewRun)
+}
+
+func (s *SummaryPackScheduleService) deliver(ctx context.Context, schedule *entities.SummaryPackSchedule, pack *entities.SummaryPack) (string, *uuid.UUID, error) {
+	msg, err := packDigestMessage(schedule, pack, s.appBaseURL)
+	if err != nil {
+		return entities.PackScheduleStatusFailed, &pack.PackID, err
+	}
+	used, err := s.sender.SendToChannels(ctx, schedule.UserID, msg, schedule.Channels)
+	if err != nil {
+		return entities.PackScheduleStatusFailed, &pack.PackID, err
+	}
+	if len(used) == 0 {
+		return entities.PackScheduleStatusNoChannel, &pack.PackID, nil
+	}
+	return entities.PackScheduleStatusSent, &pack.PackID, nil
+}
+
+// PruneExpired deletes packs older than the retention period, keeping each
+// user's latest pack per range and any pack with a live share link. It does
+// nothing unless a retention period is configured.
+func (s *SummaryPackScheduleService) PruneExpired(ctx context.Context) (int64, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+	return s.packRepo.DeleteOlderThan(ctx, s.now().UTC().Add(-s.retention))
+}
+
+// packDigestMessage formats a pack for Telegram or email. Reconciliation
+// warnings lead the message and are flagged in the title.
+func packDigestMessage(schedule *entities.SummaryPackSchedule, pack *entities.SummaryPack, appBaseURL string) (notification.Message, error) {
+	var payload summaryPackPayloadV1
+	if err := json.Unmarshal(pack.Payload, &payload); err != nil {
+		return notification.Message{}, fmt.Errorf("pack payload: %w", err)
+	}
+
+	frequency := map[entities.PackScheduleFrequency]string{
+		entities.PackScheduleDaily:   "일간",
+		entities.PackScheduleWeekly:  "주간",
+		entities.PackScheduleMonthly: "월간",
+	}[schedule.Frequency]
+	msg := notification.Message{
+		Title:    fmt.Sprintf("%s 요약 팩 (%s)", frequency, pack.Range),
+		Severity: "normal",
+		DeepLink: fmt.Sprintf("%s/packs/%s", appBaseURL, pack.PackID.String()),
+	}
+
+	var lines []string
+	reconciliation := payload.Reconciliation
+	if reconciliation.ReconciliationStatus != "ok" {
+		msg.Title = "⚠️ " + msg.Title
+		lines = append(lines, fmt.Sprintf("정합성 %s: 누락 의심 %d건, 중복 의심 %d건",
+			packStatusLabel(reconciliation.ReconciliationStatus),
+			reconciliation.MissingSuspectsCount, reconciliation.DuplicateSuspectsCount))
+		for _, warning := range reconciliation.NormalizationWarnings {
+			label, ok := packWarningLabels[warning]
+			if !ok {
+				label = warning
+			}
+			lines = append(lines, "- "+label)
+		}
+		lines = append(lines, "")
+	}
+
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	lines = append(lines, fmt.Sprintf("기간: %s ~ %s (%s)",
+		packDigestTime(payload.TimeRange.StartTs, loc), packDigestTime(payload.TimeRange.EndTs, loc), loc.String()))
+	lines = append(lines,
+		"실현 손익: "+packDigestValue(payload.PnLSummary.RealizedPnLTotal),
+		"수수료: "+packDigestValue(payload.PnLSummary.FeesTotal),
+		"펀딩: "+packDigestValue(payload.PnLSummary.FundingTotal),
+		fmt.Sprintf("거래 수: %d", payload.ActivitySummary.TradeCount),
+		"거래대금: "+packDigestValue(payload.ActivitySummary.NotionalVolumeTotal),
+		"",
+		"팩 ID: "+pack.PackID.String(),
+		"콘텐츠 해시: "+pack.ContentHash,
+	)
+	msg.Body = strings.Join(lines, "\n")
+	return msg, nil
+}
+
+func packStatusLabel(status string) string {
+	if status == "error" {
+		return "오류"
+	}
+	return "경고"
+}
+
+func packDigestValue(value *string) string {
+	if value == nil {
+		return "-"
+	}
+	return *value
+}
+
+func packDigestTime(raw string, loc *time.Location) string {
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return raw
+	}
+	return t.In(loc).Format("2006-01-02 15:04")
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/infrastructure/notification"
+)
+
+type packScheduleTestRepo struct {
+	due      []*entities.SummaryPackSchedule
+	recorded []*entities.SummaryPackSchedule
+}
+
+func (r *packScheduleTestRepo) ListByUser(context.Context, uuid.UUID) ([]*entities.SummaryPackSchedule, error) {
+	return r.due, nil
+}
+func (r *packScheduleTestRepo) Upsert(context.Context, *entities.SummaryPackSchedule) error {
+	return nil
+}
+func (r *packScheduleTestRepo) Delete(context.Context, uuid.UUID, entities.PackScheduleFrequency) (bool, error) {
+	return false, nil
+}
+func (r *packScheduleTestRepo) ClaimDue(context.Context, time.Time, time.Duration, int) ([]*entities.SummaryPackSchedule, error) {
+	due := r.due
+	r.due = nil
+	return due, nil
+}
+func (r *packScheduleTestRepo) RecordRun(_ context.Context, schedule *entities.SummaryPackSchedule) error {
+	r.recorded = append(r.recorded, schedule)
+	return nil
+}
+
+type packScheduleTestRunRepo struct {
+	run *entities.Run
+}
+
+func (r *packScheduleTestRunRepo) Create(context.Context, uuid.UUID, string, string, time.Time, json.RawMessage) (*entities.Run, error) {
+	return nil, nil
+}
+func (r *packScheduleTestRunRepo) GetByID(context.Context, uuid.UUID, uuid.UUID) (*entities.Run, error) {
+	return r.run, nil
+}
+func (r *packScheduleTestRunRepo) UpdateStatus(context.Context, uuid.UUID, string, *time.Time, json.RawMessage) error {
+	return nil
+}
+func (r *packScheduleTestRunRepo) GetLatestCompletedRun(context.Context, uuid.UUID) (*entities.Run, error) {
+	if r.run == nil {
+		return nil, pgx.ErrNoRows
+	}
+	return r.run, nil
+}
+
+type packScheduleTestPackRepo struct {
+	created []*entities.SummaryPack
+}
+
+func (r *packScheduleTestPackRepo) Create(_ context.Context, pack *entities.SummaryPack) error {
+	r.created = append(r.created, pack)
+	return nil
+}
+func (r *packScheduleTestPackRepo) GetByID(_ context.Context, _ uuid.UUID, packID uuid.UUID) (*entities.SummaryPack, error) {
+	for _, pack := range r.created {
+		if pack.PackID == packID {
+			return pack, nil
+		}
+	}
+	return nil, nil
+}
+func (r *packScheduleTestPackRepo) GetLatest(context.Context, uuid.UUID, string) (*entities.SummaryPack, error) {
+	return nil, nil
+}
+func (r *packScheduleTestPackRepo) GetEvidenceBundle(context.Context, uuid.UUID, uuid.UUID) (*entities.SummaryPackEvidenceBundle, error) {
+	return nil, nil
+}
+func (r *packScheduleTestPackRepo) DeleteOlderThan(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+type packScheduleTestSender struct {
+	verified []entities.ChannelType
+	messages []notification.Message
+}
+
+func (s *packScheduleTestSender) SendToChannels(_ context.Context, _ uuid.UUID, msg notification.Message, types []entities.ChannelType) ([]entities.ChannelType, error) {
+	var used []entities.ChannelType
+	for _, channelType := range types {
+		for _, verified := range s.verified {
+			if channelType == verified {
+				used = append(used, channelType)
+			}
+		}
+	}
+	if len(used) > 0 {
+		s.messages = append(s.messages, msg)
+	}
+	return used, nil
+}
+
+func TestPackScheduleNextRunAfter(t *testing.T) {
+	t.Parallel()
+
+	// 2026-03-10 is a Tuesday; 23:30 UTC is 08:30 on the 11th in Seoul.
+	now := time.Date(2026, 3, 10, 23, 30, 0, 0, time.UTC)
+	cases := []struct {
+		name     string
+		schedule entities.SummaryPackSchedule
+		want     time.Time
+	}{
+		{
+			name:     "daily later today",
+			schedule: entities.SummaryPackSchedule{Frequency: entities.PackScheduleDaily, Timezone: "Asia/Seoul", SendHour: 9},
+			want:     time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "daily already sent today",
+			schedule: entities.SummaryPackSchedule{Frequency: entities.PackScheduleDaily, Timezone: "Asia/Seoul", SendHour: 8},
+			want:     time.Date(2026, 3, 11, 23, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "weekly on monday",
+			schedule: entities.SummaryPackSchedule{Frequency: entities.PackScheduleWeekly, Timezone: "UTC", SendHour: 9, Weekday: 1},
+			want:     time.Date(2026, 3, 16, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "monthly rolls into next month",
+			schedule: entities.SummaryPackSchedule{Frequency: entities.PackScheduleMonthly, Timezone: "UTC", SendHour: 6, DayOfMonth: 1},
+			want:     time.Date(2026, 4, 1, 6, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tc := range cases {
+		if got := tc.schedule.NextRunAfter(now); !got.Equal(tc.want) {
+			t.Errorf("%s: next run = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestPackScheduleRunDueDeliversDigest(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 13, 9, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+	packs := baseService(now)
+	packs.tradeRepo = &summaryPackTestTradeRepo{trades: []*entities.Trade{
+		newTrade(1001, "binance_futures", "BTCUSDT", "BUY", "1", "10000", now.Add(-time.Hour)),
+		newTrade(1001, "binance_futures", "BTCUSDT", "BUY", "1", "10000", now.Add(-time.Minute)),
+	}}
+
+	schedules := &packScheduleTestRepo{due: []*entities.SummaryPackSchedule{
+		{ID: uuid.New(), UserID: userID, Frequency: entities.PackScheduleDaily, Range: "7d", Timezone: "UTC", SendHour: 9,
+			Channels: []entities.ChannelType{entities.ChannelTelegram, entities.ChannelEmail}, Enabled: true},
+	}}
+	packRepo := &packScheduleTestPackRepo{}
+	sender := &packScheduleTestSender{verified: []entities.ChannelType{entities.ChannelTelegram}}
+	run := &entities.Run{RunID: uuid.New(), RunType: "exchange_sync", Meta: mustJSON(map[string]any{"exchange": "binance_futures"})}
+
+	svc := NewSummaryPackScheduleService(schedules, packRepo, &packScheduleTestRunRepo{run: run}, nil, packs, sender)
+	svc.now = func() time.Time { return now }
+	if err := svc.RunDue(context.Background()); err != nil {
+		t.Fatalf("RunDue failed: %v", err)
+	}
+
+	if len(packRepo.created) != 1 || len(schedules.recorded) != 1 {
+		t.Fatalf("expected one pack and one recorded run, got %d and %d", len(packRepo.created), len(schedules.recorded))
+	}
+	recorded := schedules.recorded[0]
+	if recorded.LastStatus == nil || *recorded.LastStatus != entities.PackScheduleStatusSent {
+		t.Fatalf("status = %v, want sent", recorded.LastStatus)
+	}
+	if recorded.LastPackID == nil || *recorded.LastPackID != packRepo.created[0].PackID {
+		t.Fatalf("last pack id not recorded: %v", recorded.LastPackID)
+	}
+	if want := time.Date(2026, 2, 14, 9, 0, 0, 0, time.UTC); !recorded.NextRunAt.Equal(want) {
+		t.Fatalf("next run = %s, want %s", recorded.NextRunAt, want)
+	}
+
+	if len(sender.messages) != 1 {
+		t.Fatalf("expected one digest, got %d", len(sender.messages))
+	}
+	digest := sender.messages[0]
+	if want := "http://localhost:5173/packs/" + packRepo.created[0].PackID.String(); digest.DeepLink != want {
+		t.Fatalf("deep link = %q, want %q", digest.DeepLink, want)
+	}
+	if !strings.HasPrefix(digest.Title, "⚠️") || !strings.Contains(digest.Body, "중복 의심 1건") {
+		t.Fatalf("expected the duplicate suspect to be highlighted, got %q / %q", digest.Title, digest.Body)
+	}
+}
+
+func TestPackScheduleRunDueSkipsAlreadyPackedRun(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 13, 9, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+	packs := baseService(now)
+	packs.tradeRepo = &summaryPackTestTradeRepo{trades: []*entities.Trade{
+		newTrade(1001, "binance_futures", "BTCUSDT", "BUY", "1", "10000", now.Add(-time.Hour)),
+	}}
+	schedule := &entities.SummaryPackSchedule{ID: uuid.New(), UserID: userID, Frequency: entities.PackScheduleDaily, Range: "7d",
+		Timezone: "UTC", SendHour: 9, Channels: []entities.ChannelType{entities.ChannelTelegram}, Enabled: true}
+	schedules := &packScheduleTestRepo{due: []*entities.SummaryPackSchedule{schedule}}
+	packRepo := &packScheduleTestPackRepo{}
+	sender := &packScheduleTestSender{verified: []entities.ChannelType{entities.ChannelTelegram}}
+	run := &entities.Run{RunID: uuid.New(), RunType: "exchange_sync", Meta: mustJSON(map[string]any{"exchange": "binance_futures"})}
+
+	svc := NewSummaryPackScheduleService(schedules, packRepo, &packScheduleTestRunRepo{run: run}, nil, packs, sender)
+	svc.now = func() time.Time { return now }
+	if err := svc.RunDue(context.Background()); err != nil {
+		t.Fatalf("RunDue failed: %v", err)
+	}
+
+	// The next day there is still no newer run.
+	now = now.Add(24 * time.Hour)
+	schedules.due = []*entities.SummaryPackSchedule{schedule}
+	if err := svc.RunDue(context.Background()); err != nil {
+		t.Fatalf("second RunDue failed: %v", err)
+	}
+
+	if len(packRepo.created) != 1 || len(sender.messages) != 1 {
+		t.Fatalf("expected one pack and one digest, got %d and %d", len(packRepo.created), len(sender.messages))
+	}
+	recorded := schedules.recorded[1]
+	if recorded.LastStatus == nil || *recorded.LastStatus != entities.PackScheduleStatusNoNewRun {
+		t.Fatalf("status = %v, want no_new_run", recorded.LastStatus)
+	}
+	if recorded.LastPackID == nil || *recorded.LastPackID != packRepo.created[0].PackID {
+		t.Fatalf("last pack id should be kept, got %v", recorded.LastPackID)
+	}
+}
+
+func TestPackScheduleRunDueResendsUndeliveredPack(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 13, 9, 0, 0, 0, time.UTC)
+	packs := baseService(now)
+	packs.tradeRepo = &summaryPackTestTradeRepo{trades: []*entities.Trade{
+		newTrade(1001, "binance_futures", "BTCUSDT", "BUY", "1", "10000", now.Add(-time.Hour)),
+	}}
+	schedule := &entities.SummaryPackSchedule{ID: uuid.New(), UserID: uuid.New(), Frequency: entities.PackScheduleDaily, Range: "7d",
+		Timezone: "UTC", SendHour: 9, Channels: []entities.ChannelType{entities.ChannelTelegram}, Enabled: true}
+	schedules := &packScheduleTestRepo{due: []*entities.SummaryPackSchedule{schedule}}
+	packRepo := &packScheduleTestPackRepo{}
+	// Telegram is not linked yet.
+	sender := &packScheduleTestSender{}
+	run := &entities.Run{RunID: uuid.New(), RunType: "exchange_sync", Meta: mustJSON(map[string]any{"exchange": "binance_futures"})}
+
+	svc := NewSummaryPackScheduleService(schedules, packRepo, &packScheduleTestRunRepo{run: run}, nil, packs, sender)
+	svc.now = func() time.Time { return now }
+	wantStatuses := []string{entities.PackScheduleStatusNoChannel, entities.PackScheduleStatusSent, entities.PackScheduleStatusNoNewRun}
+	for i, want := range wantStatuses {
+		if i == 1 {
+			sender.verified = []entities.ChannelType{entities.ChannelTelegram}
+		}
+		schedules.due = []*entities.SummaryPackSchedule{schedule}
+		if err := svc.RunDue(context.Background()); err != nil {
+			t.Fatalf("RunDue %d failed: %v", i, err)
+		}
+		if got := schedules.recorded[i].LastStatus; got == nil || *got != want {
+			t.Fatalf("run %d: status = %v, want %s", i, got, want)
+		}
+		now = now.Add(24 * time.Hour)
+	}
+
+	if len(packRepo.created) != 1 || len(sender.messages) != 1 {
+		t.Fatalf("expected the one pack to be delivered once, got %d packs and %d digests", len(packRepo.created), len(sender.messages))
+	}
+	if !strings.Contains(sender.messages[0].Body, packRepo.created[0].PackID.String()) {
+		t.Fatalf("expected the resent digest to carry the first pack")
+	}
+}
+
+func TestPackScheduleRunDueWithoutCompletedRun(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 13, 9, 0, 0, 0, time.UTC)
+	schedules := &packScheduleTestRepo{due: []*entities.SummaryPackSchedule{
+		{ID: uuid.New(), UserID: uuid.New(), Frequency: entities.PackScheduleWeekly, Range: "7d", Timezone: "UTC",
+			SendHour: 9, Weekday: 5, Channels: []entities.ChannelType{entities.ChannelEmail}, Enabled: true},
+	}}
+	sender := &packScheduleTestSender{}
+
+	svc := NewSummaryPackScheduleService(schedules, &packScheduleTestPackRepo{}, &packScheduleTestRunRepo{}, nil, baseService(now), sender)
+	svc.now = func() time.Time { return now }
+	if err := svc.RunDue(context.Background()); err != nil {
+		t.Fatalf("RunDue failed: %v", err)
+	}
+
+	recorded := schedules.recorded[0]
+	if recorded.LastStatus == nil || *recorded.LastStatus != entities.PackScheduleStatusNoRun || len(sender.messages) != 0 {
+		t.Fatalf("expected no_run without a digest, got %v and %d messages", recorded.LastStatus, len(sender.messages))
+	}
+	if want := time.Date(2026, 2, 20, 9, 0, 0, 0, time.UTC); !recorded.NextRunAt.Equal(want) {
+		t.Fatalf("next run = %s, want %s", recorded.NextRunAt, want)
+	}
+}
+
+func TestValidatePackSchedule(t *testing.T) {
+	t.Parallel()
+
+	valid := func() *entities.SummaryPackSchedule {
+		return &entities.SummaryPackSchedule{Frequency: entities.PackScheduleMonthly, Range: "30d", Timezone: "Asia/Seoul",
+			SendHour: 9, Weekday: 1, DayOfMonth: 1, Channels: []entities.ChannelType{entities.ChannelEmail}}
+	}
+	if err := ValidatePackSchedule(valid()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := map[string]func(*entities.SummaryPackSchedule){
+		"frequency": func(s *entities.SummaryPackSchedule) { s.Frequency = "hourly" },
+		"range":     func(s *entities.SummaryPackSchedule) { s.Range = "90d" },
+		"timezone":  func(s *entities.SummaryPackSchedule) { s.Timezone = "Mars/Olympus" },
+		"day":       func(s *entities.SummaryPackSchedule) { s.DayOfMonth = 31 },
+		"channel":   func(s *entities.SummaryPackSchedule) { s.Channels = []entities.ChannelType{entities.ChannelSlack} },
+		"none":      func(s *entities.SummaryPackSchedule) { s.Channels = nil },
+	}
+	for name, mutate := range cases {
+		schedule := valid()
+		mutate(schedule)
+		if err := ValidatePackSchedule(schedule); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
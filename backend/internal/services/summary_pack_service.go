@@ -10,11 +10,13 @@ import (
 	"math/big"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
 )
 
 const (
@@ -37,12 +39,16 @@ type tradeRangeQuerier interface {
 
 type SummaryPackService struct {
 	tradeRepo tradeRangeQuerier
+	userRepo  repositories.UserRepository
 	now       func() time.Time
 }
 
-func NewSummaryPackService(tradeRepo tradeRangeQuerier) *SummaryPackService {
+// NewSummaryPackService builds the service. userRepo supplies the trading-day
+// settings ranges are aligned to; with nil they default to UTC midnight.
+func NewSummaryPackService(tradeRepo tradeRangeQuerier, userRepo repositories.UserRepository) *SummaryPackService {
 	return &SummaryPackService{
 		tradeRepo: tradeRepo,
+		userRepo:  userRepo,
 		now:       time.Now,
 	}
 }
@@ -52,17 +58,19 @@ type summaryPackRange struct {
 	end   time.Time
 }
 
-func resolveRange(rangeValue string, now time.Time) (summaryPackRange, error) {
+// resolveRange aligns the start of the 30d and 7d ranges to the user's
+// trading days, so "7d" is today plus the six trading days before it.
+func resolveRange(rangeValue string, now time.Time, day entities.TradingDay) (summaryPackRange, error) {
 	now = now.UTC()
 	switch strings.TrimSpace(rangeValue) {
 	case "", "30d":
 		return summaryPackRange{
-			start: now.Add(-range30d),
+			start: tradingDaysBack(day, now, 30),
 			end:   now,
 		}, nil
 	case "7d":
 		return summaryPackRange{
-			start: now.Add(-range7d),
+			start: tradingDaysBack(day, now, 7),
 			end:   now,
 		}, nil
 	case "all":
@@ -75,6 +83,25 @@ func resolveRange(rangeValue string, now time.Time) (summaryPackRange, error) {
 	}
 }
 
+// tradingDaysBack returns the start of the trading day days-1 days before the
+// one containing now.
+func tradingDaysBack(day entities.TradingDay, now time.Time, days int) time.Time {
+	first := day.Start(now).AddDate(0, 0, -(days - 1))
+	start, _, _ := day.Bounds(first.Format("2006-01-02"))
+	return start
+}
+
+func (s *SummaryPackService) tradingDay(ctx context.Context, userID uuid.UUID) (entities.TradingDay, error) {
+	if s.userRepo == nil {
+		return entities.NewTradingDay("UTC", 0), nil
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return entities.NewTradingDay("UTC", 0), err
+	}
+	return user.TradingDay(), nil
+}
+
 func normalizeDecimal(value *big.Rat) *string {
 	if value == nil {
 		return nil
@@ -221,7 +248,11 @@ func (s *SummaryPackService) GeneratePack(ctx context.Context, userID uuid.UUID,
 		return nil, "", errors.New("source run is required")
 	}
 
-	resolvedRange, err := resolveRange(rangeValue, s.now())
+	tradingDay, err := s.tradingDay(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	resolvedRange, err := resolveRange(rangeValue, s.now(), tradingDay)
 	if err != nil {
 		return nil, "", err
 	}
@@ -233,7 +264,8 @@ func (s *SummaryPackService) GeneratePack(ctx context.Context, userID uuid.UUID,
 
 	var (
 		exchanges            = map[string]struct{}{}
-		seenTradeKeys        = map[string]struct{}{}
+		seenTradeKeys        = map[string]string{}
+		counted              = make([]*entities.Trade, 0, len(trades))
 		timeStamps           = make([]int64, 0, len(trades))
 		realizedPnL          = new(big.Rat)
 		feesTotal            = new(big.Rat)
@@ -244,6 +276,8 @@ func (s *SummaryPackService) GeneratePack(ctx context.Context, userID uuid.UUID,
 		sellCount            int
 		warnings             []string
 		samples              []string
+		evidenceIDs          = map[string]struct{}{}
+		evidence             = newEvidenceBundleBuilder()
 		runCtx               = buildRunInfo(sourceRun)
 		fundingModuleEnabled bool
 		modules              = map[string]struct{}{"trades": {}}
@@ -293,19 +327,22 @@ func (s *SummaryPackService) GeneratePack(ctx context.Context, userID uuid.UUID,
 
 		key := fmt.Sprintf("fallback:%s|%s|%s|%s|%s", trade.Exchange, trade.Symbol, trade.Side, trade.Price, trade.Quantity)
 		if trade.BinanceTradeID != 0 {
-			key = fmt.Sprintf("id:%d", trade.BinanceTradeID)
+			key = "id:" + summaryPackEvidenceID(trade)
 		}
-		if _, exists := seenTradeKeys[key]; exists {
+		duplicateOf, exists := seenTradeKeys[key]
+		if exists {
 			duplicateCount += 1
 		} else {
-			seenTradeKeys[key] = struct{}{}
+			seenTradeKeys[key] = summaryPackEvidenceID(trade)
 		}
 
+		counted = append(counted, trade)
 		timeStamps = append(timeStamps, trade.TradeTime.Unix())
 
 		if len(samples) < 10 && trade.BinanceTradeID != 0 {
 			samples = append(samples, fmt.Sprintf("%d", trade.BinanceTradeID))
 		}
+		evidenceIDs[summaryPackEvidenceID(trade)] = struct{}{}
 
 		if trade.Side == "BUY" {
 			buyCount += 1
@@ -319,6 +356,7 @@ func (s *SummaryPackService) GeneratePack(ctx context.Context, userID uuid.UUID,
 			}
 		}
 
+		var notionalRow, flowRow *string
 		qtyRat := parseDecimal(trade.Quantity)
 		priceRat := parseDecimal(trade.Price)
 		if qtyRat != nil && priceRat != nil {
@@ -329,18 +367,21 @@ func (s *SummaryPackService) GeneratePack(ctx context.Context, userID uuid.UUID,
 				tmp.Neg(tmp)
 			}
 			flowExchange.Add(flowExchange, tmp)
+			notionalRow, flowRow = evidenceDecimal(notionalPart), evidenceDecimal(tmp)
 		}
+		evidence.addTrade(trade, normalized, key, duplicateOf, notionalRow, flowRow)
 	}
 
 	if len(timeStamps) >= 2 {
 		sorted := append([]int64{}, timeStamps...)
 		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
 		median := sorted[len(sorted)/2]
-		for _, unixTs := range timeStamps {
+		for i, unixTs := range timeStamps {
 			diff := time.Duration(absInt64(unixTs-median)) * time.Second
 			if diff > fixRange6h {
 				addUniqueWarning(&warnings, "time_skew")
-				break
+				evidence.addSuspect(evidenceSuspectTimeSkewed, counted[i],
+					fmt.Sprintf("%s from the median trade time", diff), "")
 			}
 		}
 	}
@@ -361,9 +402,11 @@ func (s *SummaryPackService) GeneratePack(ctx context.Context, userID uuid.UUID,
 	missingCount := 0
 	if len(trades) >= minMissingTradeThreshold && feesTotal.Sign() == 0 {
 		missingCount += 1
+		evidence.addSuspect(evidenceSuspectMissing, nil, "no fees reported for any trade", "")
 	}
 	if isFundingData && len(trades) >= minMissingTradeThreshold && isZeroRatOrNil(nil) {
 		missingCount += 1
+		evidence.addSuspect(evidenceSuspectMissing, nil, "funding module enabled for a futures exchange but no funding reported", "")
 	}
 
 	var fundingTotal *string
@@ -394,7 +437,7 @@ func (s *SummaryPackService) GeneratePack(ctx context.Context, userID uuid.UUID,
 		CalcVersion:   summaryPackCalcV1,
 		ContentHash:   "",
 		TimeRange: summaryPackTimeRangeV1{
-			Timezone: "Asia/Seoul",
+			Timezone: tradingDay.Timezone(),
 			StartTs:  resolvedRange.start.UTC().Format(time.RFC3339),
 			EndTs:    resolvedRange.end.UTC().Format(time.RFC3339),
 		},
@@ -460,14 +503,48 @@ func (s *SummaryPackService) GeneratePack(ctx context.Context, userID uuid.UUID,
 		DuplicateSuspectsCount: duplicateCount,
 		NormalizationWarnings:  warnings,
 		Payload:                packed,
+		EvidenceTradeIDs:       make([]string, 0, len(evidenceIDs)),
+	}
+	for id := range evidenceIDs {
+		pack.EvidenceTradeIDs = append(pack.EvidenceTradeIDs, id)
 	}
+	sort.Strings(pack.EvidenceTradeIDs)
 
 	if pack.Range == "" {
 		pack.Range = "30d"
 	}
 
-	// Pack-level evidence ref after payload creation.
-	payload.EvidenceIndex.EvidencePackRef = fmt.Sprintf("evidence_pack://%s", pack.PackID)
+	// Pack-level evidence ref after payload creation. It names the bundle
+	// hash, so the content hash below also commits to the evidence.
+	bundle, err := evidence.build(evidenceManifest{
+		BundleVersion:  summaryPackEvidenceBundleV1,
+		PackID:         payload.PackID,
+		SourceRunID:    sourceRun.RunID.String(),
+		Range:          pack.Range,
+		SchemaVersion:  payload.SchemaVersion,
+		CalcVersion:    payload.CalcVersion,
+		GeneratedAt:    s.now().UTC().Format(time.RFC3339),
+		TimeRange:      payload.TimeRange,
+		DataSources:    payload.DataSources,
+		Reconciliation: payload.Reconciliation,
+		Results: evidenceManifestResults{
+			TradeCount:          payload.ActivitySummary.TradeCount,
+			BuyCount:            buyCount,
+			SellCount:           sellCount,
+			RealizedPnLTotal:    payload.PnLSummary.RealizedPnLTotal,
+			FeesTotal:           payload.PnLSummary.FeesTotal,
+			NotionalVolumeTotal: payload.ActivitySummary.NotionalVolumeTotal,
+			NetExchangeFlow:     payload.FlowSummary.NetExchangeFlow,
+			LongShortRatio:      payload.ActivitySummary.LongShortRatio,
+		},
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("evidence bundle: %w", err)
+	}
+	bundle.PackID = pack.PackID
+	bundle.UserID = userID
+	pack.EvidenceBundle = bundle
+	payload.EvidenceIndex.EvidencePackRef = fmt.Sprintf("evidence_pack://%s/%s", pack.PackID, bundle.SHA256)
 	// Recompute hash with evidence ref included.
 	payloadHashInput, err := json.Marshal(payload)
 	if err != nil {
@@ -483,6 +560,36 @@ func (s *SummaryPackService) GeneratePack(ctx context.Context, userID uuid.UUID,
 	return pack, pack.ContentHash, nil
 }
 
+// summaryPackEvidenceID identifies a trade in the pack evidence as
+// exchange:SYMBOL:trade_id, since exchanges only number trades per symbol. A
+// trade the exchange gave no ID keeps its stored trade ID.
+func summaryPackEvidenceID(trade *entities.Trade) string {
+	if trade.BinanceTradeID != 0 {
+		return fmt.Sprintf("%s:%s:%d", trade.Exchange, strings.ToUpper(trade.Symbol), trade.BinanceTradeID)
+	}
+	return trade.ID.String()
+}
+
+// legacySummaryPackEvidenceID is the bare exchange trade ID that packs
+// generated before evidence IDs carried the exchange and symbol used.
+func legacySummaryPackEvidenceID(trade *entities.Trade) string {
+	if trade.BinanceTradeID != 0 {
+		return fmt.Sprintf("%d", trade.BinanceTradeID)
+	}
+	return trade.ID.String()
+}
+
+// hasLegacyEvidenceIDs reports whether ids use the bare exchange trade IDs of
+// older packs.
+func hasLegacyEvidenceIDs(ids []string) bool {
+	for _, id := range ids {
+		if _, err := strconv.ParseInt(id, 10, 64); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
 func absInt64(value int64) int64 {
 	if value < 0 {
 		return -value
@@ -244,3 +244,140 @@ func TestSummaryPackSymbolMappingGapWarning(t *testing.T) {
 		t.Fatalf("expected symbol_mapping_gap warning in %v", summaryWarnings)
 	}
 }
+
+func TestResolveRangeAlignsToTradingDay(t *testing.T) {
+	t.Parallel()
+
+	seoul, err := time.LoadLocation("Asia/Seoul")
+	if err != nil {
+		t.Skip("timezone data unavailable")
+	}
+	day := entities.TradingDay{Location: seoul, CutoffHour: 9}
+	// 23:00 UTC on Feb 12 is 08:00 KST on Feb 13, still the Feb 12 trading day.
+	now := time.Date(2026, 2, 12, 23, 0, 0, 0, time.UTC)
+	if got := day.Date(now); got != "2026-02-12" {
+		t.Fatalf("trading day = %s, want 2026-02-12", got)
+	}
+
+	resolved, err := resolveRange("7d", now, day)
+	if err != nil {
+		t.Fatalf("resolveRange failed: %v", err)
+	}
+	// Feb 6 09:00 KST.
+	if want := time.Date(2026, 2, 6, 0, 0, 0, 0, time.UTC); !resolved.start.Equal(want) {
+		t.Fatalf("start = %s, want %s", resolved.start, want)
+	}
+	if !resolved.end.Equal(now) {
+		t.Fatalf("end = %s, want %s", resolved.end, now)
+	}
+}
+
+func TestCompareSummaryPacks(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 2, 13, 9, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+	run := &entities.Run{
+		RunID:   uuid.New(),
+		RunType: "exchange_sync",
+		Meta:    mustJSON(map[string]any{"exchange": "binance_futures"}),
+	}
+
+	svc := baseService(now)
+	svc.tradeRepo = &summaryPackTestTradeRepo{trades: []*entities.Trade{
+		newTrade(3001, "binance_futures", "BTCUSDT", "BUY", "1", "10000", now.Add(-2*time.Hour)),
+		newTrade(3002, "binance_futures", "BTCUSDT", "SELL", "1", "10000", now.Add(-time.Hour)),
+	}}
+	base, _, err := svc.GeneratePack(context.Background(), userID, run, "7d")
+	if err != nil {
+		t.Fatalf("GeneratePack base failed: %v", err)
+	}
+
+	later := now.Add(time.Hour)
+	svc.now = func() time.Time { return later }
+	svc.tradeRepo = &summaryPackTestTradeRepo{trades: []*entities.Trade{
+		newTrade(3002, "binance_futures", "BTCUSDT", "SELL", "1", "10000", now.Add(-time.Hour)),
+		newTrade(3003, "binance_futures", "BTCUSDT", "BUY", "2", "10000", now.Add(30*time.Minute)),
+		newTrade(3004, "binance_futures", "BTCUSDT", "SELL", "2", "10000", now.Add(40*time.Minute)),
+	}}
+	target, _, err := svc.GeneratePack(context.Background(), userID, run, "30d")
+	if err != nil {
+		t.Fatalf("GeneratePack target failed: %v", err)
+	}
+
+	diff, err := CompareSummaryPacks(base, target)
+	if err != nil {
+		t.Fatalf("CompareSummaryPacks failed: %v", err)
+	}
+	if !diff.Changed || diff.Alignment.SameRange || diff.Alignment.OverlapStartTs == nil {
+		t.Fatalf("unexpected alignment: changed=%v %+v", diff.Changed, diff.Alignment)
+	}
+	if len(diff.Alignment.Warnings) != 1 || diff.Alignment.Warnings[0] != "range_mismatch" {
+		t.Fatalf("warnings = %v, want [range_mismatch]", diff.Alignment.Warnings)
+	}
+
+	tradeCount := diff.Activity[0]
+	if tradeCount.Field != "trade_count" || tradeCount.Delta == nil || *tradeCount.Delta != "1" {
+		t.Fatalf("unexpected trade_count delta: %+v", tradeCount)
+	}
+	notional := diff.Activity[1]
+	if notional.Delta == nil || *notional.Delta != "30000" {
+		t.Fatalf("unexpected notional delta: %+v", notional)
+	}
+	if status := diff.Reconciliation[0]; status.Delta != nil || status.Changed {
+		t.Fatalf("unexpected status delta: %+v", status)
+	}
+
+	evidence := diff.Evidence
+	if !evidence.Available || evidence.UnchangedCount != 1 {
+		t.Fatalf("unexpected evidence diff: %+v", evidence)
+	}
+	if len(evidence.AddedTradeIDs) != 2 || evidence.AddedTradeIDs[0] != "binance_futures:BTCUSDT:3003" || evidence.AddedTradeIDs[1] != "binance_futures:BTCUSDT:3004" {
+		t.Fatalf("added = %v, want [binance_futures:BTCUSDT:3003 binance_futures:BTCUSDT:3004]", evidence.AddedTradeIDs)
+	}
+	if len(evidence.RemovedTradeIDs) != 1 || evidence.RemovedTradeIDs[0] != "binance_futures:BTCUSDT:3001" {
+		t.Fatalf("removed = %v, want [binance_futures:BTCUSDT:3001]", evidence.RemovedTradeIDs)
+	}
+
+	// A pack from before evidence IDs named the exchange and symbol.
+	base.EvidenceTradeIDs = []string{"3001", "3002"}
+	diff, err = CompareSummaryPacks(base, target)
+	if err != nil {
+		t.Fatalf("CompareSummaryPacks failed: %v", err)
+	}
+	if diff.Evidence.Available {
+		t.Fatalf("expected bare trade IDs not to be matched against keyed ones: %+v", diff.Evidence)
+	}
+
+	base.EvidenceTradeIDs = nil
+	diff, err = CompareSummaryPacks(base, target)
+	if err != nil {
+		t.Fatalf("CompareSummaryPacks failed: %v", err)
+	}
+	if diff.Evidence.Available || diff.Alignment.Warnings[len(diff.Alignment.Warnings)-1] != "evidence_unavailable" {
+		t.Fatalf("expected evidence to be unavailable for an older pack: %+v", diff.Evidence)
+	}
+}
+
+func TestSummaryPackEvidenceIDsKeepSymbolsApart(t *testing.T) {
+	now := time.Date(2026, 2, 13, 12, 0, 0, 0, time.UTC)
+	svc := baseService(now)
+	// Exchanges number trades per symbol, so two symbols can share an ID.
+	svc.tradeRepo = &summaryPackTestTradeRepo{trades: []*entities.Trade{
+		newTrade(7001, "binance_futures", "BTCUSDT", "BUY", "1", "10000", now.Add(-2*time.Hour)),
+		newTrade(7001, "binance_futures", "ETHUSDT", "BUY", "1", "2000", now.Add(-time.Hour)),
+	}}
+	run := &entities.Run{RunID: uuid.New(), RunType: "exchange_sync", Meta: mustJSON(map[string]any{"exchange": "binance_futures"})}
+
+	pack, _, err := svc.GeneratePack(context.Background(), uuid.New(), run, "7d")
+	if err != nil {
+		t.Fatalf("GeneratePack failed: %v", err)
+	}
+	want := []string{"binance_futures:BTCUSDT:7001", "binance_futures:ETHUSDT:7001"}
+	if len(pack.EvidenceTradeIDs) != 2 || pack.EvidenceTradeIDs[0] != want[0] || pack.EvidenceTradeIDs[1] != want[1] {
+		t.Fatalf("evidence IDs = %v, want %v", pack.EvidenceTradeIDs, want)
+	}
+	if pack.DuplicateSuspectsCount != 0 {
+		t.Fatalf("expected no duplicate suspects, got %d", pack.DuplicateSuspectsCount)
+	}
+}
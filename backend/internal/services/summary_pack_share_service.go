@@ -0,0 +1,459 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+const (
+	packShareTokenVersion = 1
+	defaultPackShareTTL   = 7 * 24 * time.Hour
+	maxPackShareTTL       = 90 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidPackShare  = errors.New("invalid pack share")
+	ErrPackShareNotFound = errors.New("pack share not found")
+	ErrPackShareExpired  = errors.New("pack share expired")
+	ErrPackShareRevoked  = errors.New("pack share revoked")
+)
+
+var packShareRedactions = map[string]bool{
+	entities.PackShareRedactAmounts:  true,
+	entities.PackShareRedactEvidence: true,
+}
+
+// packShareClaims is the signed part of a share token. ContentHash pins the
+// link to the pack as it was when shared.
+type packShareClaims struct {
+	Version     int      `json:"v"`
+	KeyID       string   `json:"kid"`
+	ShareID     string   `json:"sid"`
+	PackID      string   `json:"pid"`
+	ContentHash string   `json:"hash"`
+	Redactions  []string `json:"red,omitempty"`
+	ExpiresAt   int64    `json:"exp"`
+}
+
+// PackShareKey is the public half of the signing key, for verifying tokens
+// outside the server.
+type PackShareKey struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"key_id"`
+	PublicKey string `json:"public_key"`
+	PEM       string `json:"pem"`
+	TokenSpec string `json:"token_spec"`
+}
+
+type CreatedPackShare struct {
+	Share *entities.SummaryPackShare `json:"share"`
+	Token string                     `json:"token"`
+	Path  string                     `json:"path"`
+}
+
+// PackSharePercentages restates the pack's amounts relative to its notional
+// volume, which is all a share redacting amounts shows of them.
+type PackSharePercentages struct {
+	RealizedPnLPctOfVolume     *string `json:"realized_pnl_pct_of_volume"`
+	FeesPctOfVolume            *string `json:"fees_pct_of_volume"`
+	NetExchangeFlowPctOfVolume *string `json:"net_exchange_flow_pct_of_volume"`
+}
+
+// SharedSummaryPack is what a share link serves: the pack without its owner,
+// with the share's redactions applied to the payload. ContentHash is that of
+// the full pack, so a redacted payload does not hash to it.
+type SharedSummaryPack struct {
+	PackID                 uuid.UUID            `json:"pack_id"`
+	Range                  string               `json:"range"`
+	SchemaVersion          string               `json:"schema_version"`
+	CalcVersion            string               `json:"calc_version"`
+	ContentHash            string               `json:"content_hash"`
+	ReconciliationStatus   string               `json:"reconciliation_status"`
+	MissingSuspectsCount   int                  `json:"missing_suspects_count"`
+	DuplicateSuspectsCount int                  `json:"duplicate_suspects_count"`
+	NormalizationWarnings  []string             `json:"normalization_warnings"`
+	Payload                json.RawMessage      `json:"payload"`
+	Percentages            PackSharePercentages `json:"percentages"`
+	Redactions             []string             `json:"redactions"`
+	ExpiresAt              time.Time            `json:"expires_at"`
+	CreatedAt              time.Time            `json:"created_at"`
+}
+
+// SummaryPackShareService issues signed read-only links to packs and opens
+// them for anyone holding one.
+type SummaryPackShareService struct {
+	shareRepo repositories.SummaryPackShareRepository
+	packRepo  repositories.SummaryPackRepository
+	packs     *SummaryPackService
+	key       ed25519.PrivateKey
+	keyID     string
+	now       func() time.Time
+}
+
+func NewSummaryPackShareService(
+	shareRepo repositories.SummaryPackShareRepository,
+	packRepo repositories.SummaryPackRepository,
+	packs *SummaryPackService,
+) *SummaryPackShareService {
+	key := loadPackShareKey()
+	sum := sha256.Sum256(key.Public().(ed25519.PublicKey))
+	return &SummaryPackShareService{
+		shareRepo: shareRepo,
+		packRepo:  packRepo,
+		packs:     packs,
+		key:       key,
+		keyID:     hex.EncodeToString(sum[:8]),
+		now:       time.Now,
+	}
+}
+
+// loadPackShareKey reads the Ed25519 seed from SUMMARY_PACK_SHARE_KEY
+// (base64). Without it the key is derived from JWT_SECRET so links survive
+// restarts, and failing that a random key is used.
+func loadPackShareKey() ed25519.PrivateKey {
+	if raw := strings.TrimSpace(os.Getenv("SUMMARY_PACK_SHARE_KEY")); raw != "" {
+		seed, err := base64.StdEncoding.DecodeString(raw)
+		if err == nil && len(seed) == ed25519.SeedSize {
+			return ed25519.NewKeyFromSeed(seed)
+		}
+		log.Printf("summary pack share: ignoring SUMMARY_PACK_SHARE_KEY, want a base64 %d byte seed", ed25519.SeedSize)
+	}
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		seed := sha256.Sum256([]byte("kifu summary pack share\x00" + secret))
+		return ed25519.NewKeyFromSeed(seed[:])
+	}
+	log.Println("summary pack share: WARNING no signing key configured, share links will not survive a restart")
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func (s *SummaryPackShareService) PublicKey() (*PackShareKey, error) {
+	public := s.key.Public().(ed25519.PublicKey)
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, err
+	}
+	return &PackShareKey{
+		Algorithm: "Ed25519",
+		KeyID:     s.keyID,
+		PublicKey: base64.StdEncoding.EncodeToString(public),
+		PEM:       string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+		TokenSpec: "token = base64url(claims JSON) + \".\" + base64url(Ed25519 signature of the first part), unpadded",
+	}, nil
+}
+
+// CreateShare issues a link to one of the user's packs. A ttl of 0 uses the
+// default of seven days.
+func (s *SummaryPackShareService) CreateShare(ctx context.Context, userID, packID uuid.UUID, ttl time.Duration, redactions []string) (*CreatedPackShare, error) {
+	if ttl == 0 {
+		ttl = defaultPackShareTTL
+	}
+	if ttl < time.Hour || ttl > maxPackShareTTL {
+		return nil, fmt.Errorf("%w: expiry must be between 1 hour and %d days", ErrInvalidPackShare, int(maxPackShareTTL.Hours()/24))
+	}
+	normalized := []string{}
+	for _, redaction := range redactions {
+		redaction = strings.ToLower(strings.TrimSpace(redaction))
+		if !packShareRedactions[redaction] {
+			return nil, fmt.Errorf("%w: unknown redaction %q", ErrInvalidPackShare, redaction)
+		}
+		addUniqueWarning(&normalized, redaction)
+	}
+
+	pack, err := s.packRepo.GetByID(ctx, userID, packID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if pack == nil {
+		return nil, ErrPackShareNotFound
+	}
+
+	now := s.now().UTC()
+	share := &entities.SummaryPackShare{
+		ID:         uuid.New(),
+		UserID:     userID,
+		PackID:     packID,
+		Redactions: normalized,
+		ExpiresAt:  now.Add(ttl).Truncate(time.Second),
+		CreatedAt:  now,
+	}
+	if err := s.shareRepo.Create(ctx, share); err != nil {
+		return nil, err
+	}
+	token, err := s.sign(packShareClaims{
+		Version:     packShareTokenVersion,
+		KeyID:       s.keyID,
+		ShareID:     share.ID.String(),
+		PackID:      packID.String(),
+		ContentHash: pack.ContentHash,
+		Redactions:  normalized,
+		ExpiresAt:   share.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &CreatedPackShare{Share: share, Token: token, Path: "/api/v1/public/packs/" + token}, nil
+}
+
+func (s *SummaryPackShareService) ListShares(ctx context.Context, userID, packID uuid.UUID) ([]*entities.SummaryPackShare, error) {
+	return s.shareRepo.ListByPack(ctx, userID, packID)
+}
+
+func (s *SummaryPackShareService) RevokeShare(ctx context.Context, userID, shareID uuid.UUID) error {
+	ok, err := s.shareRepo.Revoke(ctx, userID, shareID, s.now().UTC())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPackShareNotFound
+	}
+	return nil
+}
+
+// Open verifies a token, counts the view and returns the shared pack with
+// the share's redactions applied.
+func (s *SummaryPackShareService) Open(ctx context.Context, token string) (*SharedSummaryPack, error) {
+	share, pack, err := s.open(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload summaryPackPayloadV1
+	if err := json.Unmarshal(pack.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("pack payload: %w", err)
+	}
+	shared := &SharedSummaryPack{
+		PackID:                 pack.PackID,
+		Range:                  pack.Range,
+		SchemaVersion:          pack.SchemaVersion,
+		CalcVersion:            pack.CalcVersion,
+		ContentHash:            pack.ContentHash,
+		ReconciliationStatus:   pack.ReconciliationStatus,
+		MissingSuspectsCount:   pack.MissingSuspectsCount,
+		DuplicateSuspectsCount: pack.DuplicateSuspectsCount,
+		NormalizationWarnings:  pack.NormalizationWarnings,
+		Payload:                pack.Payload,
+		Percentages:            packSharePercentages(payload),
+		Redactions:             share.Redactions,
+		ExpiresAt:              share.ExpiresAt,
+		CreatedAt:              pack.CreatedAt,
+	}
+	if len(share.Redactions) == 0 {
+		return shared, nil
+	}
+
+	if share.Redacts(entities.PackShareRedactAmounts) {
+		payload.PnLSummary = summaryPackPnLV1{}
+		payload.FlowSummary = summaryPackFlowV1{}
+		payload.ActivitySummary.NotionalVolumeTotal = nil
+		payload.ActivitySummary.MaxDrawdownEst = nil
+	}
+	if share.Redacts(entities.PackShareRedactEvidence) {
+		payload.EvidenceIndex = summaryPackEvidenceV1{ExchangeTradeIDsSample: []string{}}
+	}
+	shared.Payload, err = json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return shared, nil
+}
+
+// OpenReport is Open for the HTML and PDF reports.
+func (s *SummaryPackShareService) OpenReport(ctx context.Context, token string) (*PackReport, error) {
+	share, pack, err := s.open(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	pack.EvidenceBundle, err = s.packRepo.GetEvidenceBundle(ctx, pack.UserID, pack.PackID)
+	if err != nil {
+		return nil, err
+	}
+	report, err := s.packs.BuildReport(ctx, pack)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload summaryPackPayloadV1
+	if err := json.Unmarshal(pack.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("pack payload: %w", err)
+	}
+	redactPackReport(report, share, payload)
+	return report, nil
+}
+
+// open checks the token signature, then the share it names, and counts the
+// view.
+func (s *SummaryPackShareService) open(ctx context.Context, token string) (*entities.SummaryPackShare, *entities.SummaryPack, error) {
+	claims, err := s.verify(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := s.now().UTC()
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, nil, ErrPackShareExpired
+	}
+	shareID, err := uuid.Parse(claims.ShareID)
+	if err != nil {
+		return nil, nil, ErrPackShareNotFound
+	}
+	share, err := s.shareRepo.GetByID(ctx, shareID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if share == nil || share.PackID.String() != claims.PackID {
+		return nil, nil, ErrPackShareNotFound
+	}
+	if share.RevokedAt != nil {
+		return nil, nil, ErrPackShareRevoked
+	}
+	if !share.Active(now) {
+		return nil, nil, ErrPackShareExpired
+	}
+
+	pack, err := s.packRepo.GetByID(ctx, share.UserID, share.PackID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, err
+	}
+	if pack == nil || pack.ContentHash != claims.ContentHash {
+		return nil, nil, ErrPackShareNotFound
+	}
+
+	counted, err := s.shareRepo.RecordView(ctx, share.ID, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !counted {
+		return nil, nil, ErrPackShareRevoked
+	}
+	share.ViewCount++
+	return share, pack, nil
+}
+
+func (s *SummaryPackShareService) sign(claims packShareClaims) (string, error) {
+	body, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(body)
+	signature := ed25519.Sign(s.key, []byte(encoded))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func (s *SummaryPackShareService) verify(token string) (*packShareClaims, error) {
+	encoded, encodedSignature, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok {
+		return nil, ErrPackShareNotFound
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !ed25519.Verify(s.key.Public().(ed25519.PublicKey), []byte(encoded), signature) {
+		return nil, ErrPackShareNotFound
+	}
+	body, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrPackShareNotFound
+	}
+	var claims packShareClaims
+	if err := json.Unmarshal(body, &claims); err != nil || claims.Version != packShareTokenVersion {
+		return nil, ErrPackShareNotFound
+	}
+	return &claims, nil
+}
+
+func packSharePercentages(payload summaryPackPayloadV1) PackSharePercentages {
+	return PackSharePercentages{
+		RealizedPnLPctOfVolume:     pctOfVolume(payload.PnLSummary.RealizedPnLTotal, payload.ActivitySummary.NotionalVolumeTotal),
+		FeesPctOfVolume:            pctOfVolume(payload.PnLSummary.FeesTotal, payload.ActivitySummary.NotionalVolumeTotal),
+		NetExchangeFlowPctOfVolume: pctOfVolume(payload.FlowSummary.NetExchangeFlow, payload.ActivitySummary.NotionalVolumeTotal),
+	}
+}
+
+// pctOfVolume returns value as a percentage of volume, or nil when either
+// is missing or volume is zero.
+func pctOfVolume(value, volume *string) *string {
+	if value == nil || volume == nil {
+		return nil
+	}
+	v, vol := parseDecimal(*value), parseDecimal(*volume)
+	if v == nil || vol == nil || vol.Sign() == 0 {
+		return nil
+	}
+	pct := new(big.Rat).Mul(v, big.NewRat(100, 1))
+	return normalizeDecimal(pct.Quo(pct, vol))
+}
+
+// packReportAmountMetrics are the activity metrics a share redacting
+// amounts leaves out.
+var packReportAmountMetrics = map[string]bool{
+	"Notional volume":     true,
+	"Max drawdown (est.)": true,
+}
+
+// redactPackReport applies a share's redactions to a report built for the
+// owner. Daily PnL is rescaled to a percentage of notional volume.
+func redactPackReport(report *PackReport, share *entities.SummaryPackShare, payload summaryPackPayloadV1) {
+	report.Redactions = share.Redactions
+	if share.Redacts(entities.PackShareRedactAmounts) {
+		pct := packSharePercentages(payload)
+		report.PnL = []PackReportMetric{
+			{"Realized PnL / volume", packSharePct(pct.RealizedPnLPctOfVolume)},
+			{"Fees / volume", packSharePct(pct.FeesPctOfVolume)},
+		}
+		report.Flows = []PackReportMetric{
+			{"Net exchange flow / volume", packSharePct(pct.NetExchangeFlowPctOfVolume)},
+		}
+		activity := report.Activity[:0]
+		for _, metric := range report.Activity {
+			if !packReportAmountMetrics[metric.Label] {
+				activity = append(activity, metric)
+			}
+		}
+		report.Activity = activity
+
+		scale := 0.0
+		if volume := parseDecimal(packDigestValue(payload.ActivitySummary.NotionalVolumeTotal)); volume != nil && volume.Sign() > 0 {
+			f, _ := volume.Float64()
+			scale = 100 / f
+		}
+		for i := range report.Days {
+			report.Days[i].RealizedPnL *= scale
+			report.Days[i].Cumulative *= scale
+		}
+		for i := range report.Evidence {
+			report.Evidence[i].Quantity = "hidden"
+			report.Evidence[i].RealizedPnL = "hidden"
+		}
+	}
+	if share.Redacts(entities.PackShareRedactEvidence) {
+		report.Evidence = nil
+		report.EvidenceMissing = nil
+	}
+}
+
+func packSharePct(value *string) string {
+	if value == nil {
+		return "-"
+	}
+	return *value + "%"
+}
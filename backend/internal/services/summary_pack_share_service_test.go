@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+)
+
+type packShareTestPackRepo struct {
+	packScheduleTestPackRepo
+	packs map[uuid.UUID]*entities.SummaryPack
+}
+
+func (r *packShareTestPackRepo) GetByID(_ context.Context, userID, packID uuid.UUID) (*entities.SummaryPack, error) {
+	pack, ok := r.packs[packID]
+	if !ok || pack.UserID != userID {
+		return nil, pgx.ErrNoRows
+	}
+	return pack, nil
+}
+
+type packShareTestShareRepo struct {
+	shares map[uuid.UUID]*entities.SummaryPackShare
+}
+
+func (r *packShareTestShareRepo) Create(_ context.Context, share *entities.SummaryPackShare) error {
+	r.shares[share.ID] = share
+	return nil
+}
+
+func (r *packShareTestShareRepo) GetByID(_ context.Context, id uuid.UUID) (*entities.SummaryPackShare, error) {
+	share, ok := r.shares[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *share
+	return &copied, nil
+}
+
+func (r *packShareTestShareRepo) ListByPack(_ context.Context, userID, packID uuid.UUID) ([]*entities.SummaryPackShare, error) {
+	var shares []*entities.SummaryPackShare
+	for _, share := range r.shares {
+		if share.UserID == userID && share.PackID == packID {
+			shares = append(shares, share)
+		}
+	}
+	return shares, nil
+}
+
+func (r *packShareTestShareRepo) Revoke(_ context.Context, userID, id uuid.UUID, at time.Time) (bool, error) {
+	share, ok := r.shares[id]
+	if !ok || share.UserID != userID {
+		return false, nil
+	}
+	share.RevokedAt = &at
+	return true, nil
+}
+
+func (r *packShareTestShareRepo) RecordView(_ context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	share, ok := r.shares[id]
+	if !ok || !share.Active(at) {
+		return false, nil
+	}
+	share.ViewCount++
+	share.LastViewedAt = &at
+	return true, nil
+}
+
+func TestSummaryPackShareLinks(t *testing.T) {
+	t.Setenv("SUMMARY_PACK_SHARE_KEY", "")
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	now := time.Date(2026, 2, 13, 9, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+	buy := newTrade(6001, "binance_futures", "BTCUSDT", "BUY", "1", "1000", now.Add(-2*time.Hour))
+	sell := newTrade(6002, "binance_futures", "BTCUSDT", "SELL", "1", "1000", now.Add(-time.Hour))
+	sell.RealizedPnL = tradePtr("50")
+	packs := baseService(now)
+	packs.tradeRepo = &summaryPackTestTradeRepo{trades: []*entities.Trade{buy, sell}}
+	run := &entities.Run{RunID: uuid.New(), RunType: "exchange_sync", Meta: mustJSON(map[string]any{"exchange": "binance_futures"})}
+	pack, _, err := packs.GeneratePack(context.Background(), userID, run, "7d")
+	if err != nil {
+		t.Fatalf("GeneratePack failed: %v", err)
+	}
+	pack.CreatedAt = now
+
+	shareRepo := &packShareTestShareRepo{shares: map[uuid.UUID]*entities.SummaryPackShare{}}
+	svc := NewSummaryPackShareService(shareRepo, &packShareTestPackRepo{packs: map[uuid.UUID]*entities.SummaryPack{pack.PackID: pack}}, packs)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := svc.CreateShare(ctx, userID, pack.PackID, 0, []string{"balances"}); !errors.Is(err, ErrInvalidPackShare) {
+		t.Fatalf("unknown redaction: err=%v", err)
+	}
+	if _, err := svc.CreateShare(ctx, uuid.New(), pack.PackID, 0, nil); !errors.Is(err, ErrPackShareNotFound) {
+		t.Fatalf("another user's pack: err=%v", err)
+	}
+
+	full, err := svc.CreateShare(ctx, userID, pack.PackID, 0, nil)
+	if err != nil {
+		t.Fatalf("CreateShare failed: %v", err)
+	}
+	shared, err := svc.Open(ctx, full.Token)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if string(shared.Payload) != string(pack.Payload) || shared.ContentHash != pack.ContentHash {
+		t.Fatalf("unredacted share should serve the pack payload as is")
+	}
+	if got := *shared.Percentages.RealizedPnLPctOfVolume; got != "2.5" {
+		t.Fatalf("realized_pnl_pct_of_volume=%s want=2.5", got)
+	}
+	if shareRepo.shares[full.Share.ID].ViewCount != 1 {
+		t.Fatalf("view not counted")
+	}
+
+	// The token verifies against the published key alone.
+	key, err := svc.PublicKey()
+	if err != nil {
+		t.Fatalf("PublicKey failed: %v", err)
+	}
+	block, _ := pem.Decode([]byte(key.PEM))
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("parse PEM: %v", err)
+	}
+	claims, signature, _ := strings.Cut(full.Token, ".")
+	rawSignature, _ := base64.RawURLEncoding.DecodeString(signature)
+	if !ed25519.Verify(parsed.(ed25519.PublicKey), []byte(claims), rawSignature) {
+		t.Fatalf("token signature does not verify against the public key")
+	}
+
+	// Tampering with the claims breaks the signature.
+	rawClaims, _ := base64.RawURLEncoding.DecodeString(claims)
+	forged := strings.Replace(string(rawClaims), `"exp":`, `"exp":9`, 1)
+	if _, err := svc.Open(ctx, base64.RawURLEncoding.EncodeToString([]byte(forged))+"."+signature); !errors.Is(err, ErrPackShareNotFound) {
+		t.Fatalf("forged token: err=%v", err)
+	}
+
+	redacted, err := svc.CreateShare(ctx, userID, pack.PackID, 2*time.Hour, []string{"amounts", "evidence", "amounts"})
+	if err != nil {
+		t.Fatalf("CreateShare failed: %v", err)
+	}
+	if len(redacted.Share.Redactions) != 2 {
+		t.Fatalf("redactions=%v want amounts and evidence once each", redacted.Share.Redactions)
+	}
+	shared, err = svc.Open(ctx, redacted.Token)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	var payload summaryPackPayloadV1
+	if err := json.Unmarshal(shared.Payload, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.PnLSummary.RealizedPnLTotal != nil || payload.ActivitySummary.NotionalVolumeTotal != nil || len(payload.EvidenceIndex.ExchangeTradeIDsSample) != 0 {
+		t.Fatalf("redacted payload leaks amounts or evidence: %s", shared.Payload)
+	}
+	if shared.Percentages.RealizedPnLPctOfVolume == nil {
+		t.Fatalf("percentages missing from a redacted share")
+	}
+
+	report, err := svc.OpenReport(ctx, redacted.Token)
+	if err != nil {
+		t.Fatalf("OpenReport failed: %v", err)
+	}
+	html, err := RenderPackReportHTML(report)
+	if err != nil {
+		t.Fatalf("RenderPackReportHTML failed: %v", err)
+	}
+	if strings.Contains(string(html), "6001") || !strings.Contains(string(html), "2.5%") {
+		t.Fatalf("redacted report shows trade IDs or lacks percentages")
+	}
+
+	svc.now = func() time.Time { return now.Add(3 * time.Hour) }
+	if _, err := svc.Open(ctx, redacted.Token); !errors.Is(err, ErrPackShareExpired) {
+		t.Fatalf("expired share: err=%v", err)
+	}
+	if err := svc.RevokeShare(ctx, userID, full.Share.ID); err != nil {
+		t.Fatalf("RevokeShare failed: %v", err)
+	}
+	if _, err := svc.Open(ctx, full.Token); !errors.Is(err, ErrPackShareRevoked) {
+		t.Fatalf("revoked share: err=%v", err)
+	}
+	if err := svc.RevokeShare(ctx, uuid.New(), full.Share.ID); !errors.Is(err, ErrPackShareNotFound) {
+		t.Fatalf("revoking another user's share: err=%v", err)
+	}
+}
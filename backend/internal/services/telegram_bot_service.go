@@ -0,0 +1,382 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/infrastructure/notification"
+)
+
+const (
+	telegramPromptTTL     = 24 * time.Hour
+	telegramListLimit     = 10
+	telegramNoteTitleRune = 40
+)
+
+// Replies are sent with parse_mode HTML, so literal angle brackets are escaped.
+const telegramBotHelp = "사용 가능한 명령어:\n" +
+	"/positions - 보유 포지션\n" +
+	"/alerts - 최근 알림\n" +
+	"/pnl today - 오늘 실현 손익\n" +
+	"/note &lt;내용&gt; - 복기 메모 남기기"
+
+// TelegramBotClient is the subset of the Telegram Bot API the bot uses.
+type TelegramBotClient interface {
+	SendToChatID(ctx context.Context, chatID int64, text string) error
+	SendPrompt(ctx context.Context, chatID int64, text string) (int64, error)
+	AnswerCallback(ctx context.Context, callbackID string, text string) error
+	ClearButtons(ctx context.Context, chatID int64, messageID int64) error
+}
+
+// TelegramBotService answers bot commands and inline decision buttons from a
+// linked Telegram chat, so users can journal without opening the app.
+type TelegramBotService struct {
+	channelRepo   repositories.NotificationChannelRepository
+	promptRepo    repositories.TelegramReplyPromptRepository
+	prefRepo      repositories.NotificationPreferenceRepository
+	alertRepo     repositories.AlertRepository
+	portfolioRepo repositories.PortfolioRepository
+	tradeRepo     repositories.TradeRepository
+	noteRepo      repositories.ReviewNoteRepository
+	decisions     *AlertDecisionService
+	client        TelegramBotClient
+	now           func() time.Time
+}
+
+func NewTelegramBotService(
+	channelRepo repositories.NotificationChannelRepository,
+	promptRepo repositories.TelegramReplyPromptRepository,
+	prefRepo repositories.NotificationPreferenceRepository,
+	alertRepo repositories.AlertRepository,
+	portfolioRepo repositories.PortfolioRepository,
+	tradeRepo repositories.TradeRepository,
+	noteRepo repositories.ReviewNoteRepository,
+	decisions *AlertDecisionService,
+	client TelegramBotClient,
+) *TelegramBotService {
+	return &TelegramBotService{
+		channelRepo:   channelRepo,
+		promptRepo:    promptRepo,
+		prefRepo:      prefRepo,
+		alertRepo:     alertRepo,
+		portfolioRepo: portfolioRepo,
+		tradeRepo:     tradeRepo,
+		noteRepo:      noteRepo,
+		decisions:     decisions,
+		client:        client,
+		now:           time.Now,
+	}
+}
+
+// HandleMessage handles a text message from a chat. replyToMessageID is the
+// id of the bot message being replied to, or 0.
+func (s *TelegramBotService) HandleMessage(ctx context.Context, chatID int64, text string, replyToMessageID int64) {
+	userID, ok := s.resolveUser(ctx, chatID)
+	if !ok {
+		s.reply(ctx, chatID, "연동된 계정이 없습니다. 앱에서 텔레그램 연동 후 /start &lt;인증코드&gt; 를 보내세요.")
+		return
+	}
+
+	text = strings.TrimSpace(text)
+	if replyToMessageID != 0 && !strings.HasPrefix(text, "/") {
+		s.handlePromptReply(ctx, chatID, userID, replyToMessageID, text)
+		return
+	}
+
+	command, args := splitTelegramCommand(text)
+	var response string
+	var err error
+	switch command {
+	case "/positions":
+		response, err = s.positions(ctx, userID)
+	case "/alerts":
+		response, err = s.alerts(ctx, userID)
+	case "/pnl":
+		if args != "" && args != "today" {
+			response = "사용법: /pnl today"
+			break
+		}
+		response, err = s.pnlToday(ctx, userID)
+	case "/note":
+		response, err = s.note(ctx, userID, args)
+	default:
+		response = telegramBotHelp
+	}
+	if err != nil {
+		log.Printf("telegram bot: %s failed: %v", command, err)
+		response = "요청을 처리하지 못했습니다. 잠시 후 다시 시도하세요."
+	}
+	s.reply(ctx, chatID, response)
+}
+
+// HandleCallback handles an inline keyboard press on an alert message.
+func (s *TelegramBotService) HandleCallback(ctx context.Context, callbackID string, chatID, messageID int64, data string) {
+	action, alertID, ok := notification.ParseTelegramDecisionCallback(data)
+	if !ok {
+		s.answer(ctx, callbackID, "알 수 없는 버튼입니다.")
+		return
+	}
+	userID, ok := s.resolveUser(ctx, chatID)
+	if !ok {
+		s.answer(ctx, callbackID, "연동된 계정이 없습니다.")
+		return
+	}
+
+	_, err := s.decisions.Record(ctx, userID, alertID, AlertDecisionInput{Action: string(action)})
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrAlertAlreadyDecided):
+		s.answer(ctx, callbackID, "이미 결정을 기록한 알림입니다.")
+		return
+	case errors.Is(err, ErrAlertNotFound), errors.Is(err, ErrInvalidDecisionAction):
+		s.answer(ctx, callbackID, "결정을 기록할 수 없는 알림입니다.")
+		return
+	default:
+		log.Printf("telegram bot: record decision failed: %v", err)
+		s.answer(ctx, callbackID, "결정 기록에 실패했습니다.")
+		return
+	}
+
+	s.answer(ctx, callbackID, "결정을 기록했습니다.")
+	if messageID != 0 {
+		if err := s.client.ClearButtons(ctx, chatID, messageID); err != nil {
+			log.Printf("telegram bot: clear buttons failed: %v", err)
+		}
+	}
+
+	prompt := fmt.Sprintf("<b>%s</b> 결정을 기록했습니다.\n이 메시지에 답장으로 메모를 남겨주세요.\n확신도는 맨 앞에 높음/보통/낮음 을 붙이면 함께 저장됩니다.\n예) 높음 지지선 확인 후 진입", decisionActionLabel(action))
+	promptID, err := s.client.SendPrompt(ctx, chatID, prompt)
+	if err != nil {
+		log.Printf("telegram bot: send prompt failed: %v", err)
+		return
+	}
+	now := s.now().UTC()
+	if err := s.promptRepo.Create(ctx, &entities.TelegramReplyPrompt{
+		ChatID:    chatID,
+		MessageID: promptID,
+		UserID:    userID,
+		AlertID:   alertID,
+		ExpiresAt: now.Add(telegramPromptTTL),
+		CreatedAt: now,
+	}); err != nil {
+		log.Printf("telegram bot: save prompt failed: %v", err)
+	}
+}
+
+func (s *TelegramBotService) handlePromptReply(ctx context.Context, chatID int64, userID uuid.UUID, replyToMessageID int64, text string) {
+	prompt, err := s.promptRepo.FindValid(ctx, chatID, replyToMessageID, s.now().UTC())
+	if err != nil {
+		log.Printf("telegram bot: find prompt failed: %v", err)
+		s.reply(ctx, chatID, "요청을 처리하지 못했습니다. 잠시 후 다시 시도하세요.")
+		return
+	}
+	if prompt == nil || prompt.UserID != userID {
+		s.reply(ctx, chatID, telegramBotHelp)
+		return
+	}
+
+	confidence, memo := parseTelegramDecisionNote(text)
+	var memoPtr *string
+	if memo != "" {
+		memoPtr = &memo
+	}
+	if memoPtr == nil && confidence == nil {
+		s.reply(ctx, chatID, "메모 내용이 비어 있습니다.")
+		return
+	}
+
+	if _, err := s.decisions.Annotate(ctx, userID, prompt.AlertID, memoPtr, confidence); err != nil {
+		log.Printf("telegram bot: annotate decision failed: %v", err)
+		s.reply(ctx, chatID, "메모를 저장하지 못했습니다.")
+		return
+	}
+	s.reply(ctx, chatID, "메모를 저장했습니다.")
+}
+
+func (s *TelegramBotService) positions(ctx context.Context, userID uuid.UUID) (string, error) {
+	positions, err := s.portfolioRepo.ListPositions(ctx, userID, repositories.PositionFilter{
+		Status: "open",
+		Limit:  telegramListLimit,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(positions) == 0 {
+		return "보유 중인 포지션이 없습니다.", nil
+	}
+
+	var b strings.Builder
+	b.WriteString("<b>보유 포지션</b>\n")
+	for _, position := range positions {
+		b.WriteString(fmt.Sprintf("• %s (%s) %s @ %s\n",
+			html.EscapeString(position.Instrument), html.EscapeString(position.VenueCode),
+			html.EscapeString(position.NetQty), html.EscapeString(position.AvgEntry)))
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+func (s *TelegramBotService) alerts(ctx context.Context, userID uuid.UUID) (string, error) {
+	alerts, _, err := s.alertRepo.ListByUser(ctx, userID, nil, telegramListLimit, 0)
+	if err != nil {
+		return "", err
+	}
+	if len(alerts) == 0 {
+		return "최근 알림이 없습니다.", nil
+	}
+
+	loc := s.location(ctx, userID)
+	var b strings.Builder
+	b.WriteString("<b>최근 알림</b>\n")
+	for _, alert := range alerts {
+		b.WriteString(fmt.Sprintf("• %s %s %s [%s]\n",
+			alert.CreatedAt.In(loc).Format("01/02 15:04"), html.EscapeString(alert.Symbol), html.EscapeString(alert.TriggerReason), html.EscapeString(string(alert.Status))))
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+func (s *TelegramBotService) pnlToday(ctx context.Context, userID uuid.UUID) (string, error) {
+	loc := s.location(ctx, userID)
+	now := s.now().In(loc)
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc).UTC()
+	to := now.UTC()
+
+	summaries, err := s.tradeRepo.SummaryByExchange(ctx, userID, repositories.TradeFilter{From: &from, To: &to})
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("<b>오늘 실현 손익</b> (%s)\n", now.Format("2006-01-02")))
+	traded := false
+	for _, summary := range summaries {
+		if summary.TotalTrades == 0 {
+			continue
+		}
+		traded = true
+		pnl := "-"
+		if summary.RealizedPnLTotal != nil {
+			pnl = *summary.RealizedPnLTotal
+		}
+		b.WriteString(fmt.Sprintf("• %s: %s (%d건)\n", html.EscapeString(summary.Exchange), html.EscapeString(pnl), summary.TotalTrades))
+	}
+	if !traded {
+		return "오늘 체결된 거래가 없습니다.", nil
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+func (s *TelegramBotService) note(ctx context.Context, userID uuid.UUID, text string) (string, error) {
+	if text == "" {
+		return "사용법: /note &lt;내용&gt;", nil
+	}
+
+	now := s.now().UTC()
+	note := &entities.ReviewNote{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Title:     telegramNoteTitle(text),
+		Content:   text,
+		Tags:      []string{"telegram"},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.noteRepo.Create(ctx, note); err != nil {
+		return "", err
+	}
+	return "복기 메모를 저장했습니다.", nil
+}
+
+func (s *TelegramBotService) resolveUser(ctx context.Context, chatID int64) (uuid.UUID, bool) {
+	channel, err := s.channelRepo.GetTelegramByChatID(ctx, chatID)
+	if err != nil {
+		log.Printf("telegram bot: resolve chat failed: %v", err)
+		return uuid.Nil, false
+	}
+	if channel == nil {
+		return uuid.Nil, false
+	}
+	return channel.UserID, true
+}
+
+func (s *TelegramBotService) location(ctx context.Context, userID uuid.UUID) *time.Location {
+	if s.prefRepo != nil {
+		if pref, err := s.prefRepo.GetByUser(ctx, userID); err == nil && pref != nil {
+			return pref.Location()
+		}
+	}
+	return time.UTC
+}
+
+func (s *TelegramBotService) reply(ctx context.Context, chatID int64, text string) {
+	if err := s.client.SendToChatID(ctx, chatID, text); err != nil {
+		log.Printf("telegram bot: reply failed: %v", err)
+	}
+}
+
+func (s *TelegramBotService) answer(ctx context.Context, callbackID string, text string) {
+	if err := s.client.AnswerCallback(ctx, callbackID, text); err != nil {
+		log.Printf("telegram bot: answer callback failed: %v", err)
+	}
+}
+
+// splitTelegramCommand splits "/cmd@bot args" into "/cmd" and "args".
+func splitTelegramCommand(text string) (string, string) {
+	command, args, _ := strings.Cut(text, " ")
+	if at := strings.Index(command, "@"); at > 0 {
+		command = command[:at]
+	}
+	return strings.ToLower(command), strings.TrimSpace(args)
+}
+
+// parseTelegramDecisionNote reads an optional leading confidence word
+// (high/medium/low or 높음/보통/낮음) followed by the memo.
+func parseTelegramDecisionNote(text string) (*string, string) {
+	text = strings.TrimSpace(text)
+	first, rest, _ := strings.Cut(text, " ")
+	var confidence entities.Confidence
+	switch strings.ToLower(first) {
+	case "high", "높음":
+		confidence = entities.ConfidenceHigh
+	case "medium", "보통":
+		confidence = entities.ConfidenceMedium
+	case "low", "낮음":
+		confidence = entities.ConfidenceLow
+	default:
+		return nil, text
+	}
+	value := string(confidence)
+	return &value, strings.TrimSpace(rest)
+}
+
+func telegramNoteTitle(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	if utf8.RuneCountInString(line) <= telegramNoteTitleRune {
+		return line
+	}
+	return string([]rune(line)[:telegramNoteTitleRune]) + "…"
+}
+
+func decisionActionLabel(action entities.DecisionAction) string {
+	switch action {
+	case entities.DecisionBuy:
+		return "매수"
+	case entities.DecisionSell:
+		return "매도"
+	case entities.DecisionHold:
+		return "홀드"
+	case entities.DecisionClose:
+		return "청산"
+	case entities.DecisionIgnore:
+		return "무시"
+	}
+	return string(action)
+}
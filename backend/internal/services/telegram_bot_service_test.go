@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+	"github.com/moneyvessel/kifu/internal/infrastructure/notification"
+)
+
+type fakeBotAlertRepo struct {
+	repositories.AlertRepository
+	alerts map[uuid.UUID]*entities.Alert
+}
+
+func (r *fakeBotAlertRepo) GetByID(_ context.Context, id uuid.UUID) (*entities.Alert, error) {
+	return r.alerts[id], nil
+}
+
+func (r *fakeBotAlertRepo) UpdateStatus(_ context.Context, id uuid.UUID, status entities.AlertStatus) error {
+	r.alerts[id].Status = status
+	return nil
+}
+
+type fakeBotDecisionRepo struct {
+	decisions map[uuid.UUID]*entities.AlertDecision
+}
+
+func (r *fakeBotDecisionRepo) Create(_ context.Context, decision *entities.AlertDecision) error {
+	r.decisions[decision.AlertID] = decision
+	return nil
+}
+
+func (r *fakeBotDecisionRepo) GetByAlert(_ context.Context, alertID uuid.UUID) (*entities.AlertDecision, error) {
+	return r.decisions[alertID], nil
+}
+
+func (r *fakeBotDecisionRepo) UpdateNote(_ context.Context, id uuid.UUID, memo *string, confidence *entities.Confidence) error {
+	for _, decision := range r.decisions {
+		if decision.ID != id {
+			continue
+		}
+		if memo != nil {
+			decision.Memo = memo
+		}
+		if confidence != nil {
+			decision.Confidence = confidence
+		}
+	}
+	return nil
+}
+
+type fakeBotChannelRepo struct {
+	repositories.NotificationChannelRepository
+	chatID int64
+	userID uuid.UUID
+}
+
+func (r *fakeBotChannelRepo) GetTelegramByChatID(_ context.Context, chatID int64) (*entities.NotificationChannel, error) {
+	if chatID != r.chatID {
+		return nil, nil
+	}
+	return &entities.NotificationChannel{UserID: r.userID, ChannelType: entities.ChannelTelegram, Verified: true}, nil
+}
+
+type fakePromptRepo struct {
+	prompts map[string]*entities.TelegramReplyPrompt
+}
+
+func (r *fakePromptRepo) Create(_ context.Context, prompt *entities.TelegramReplyPrompt) error {
+	r.prompts[promptKey(prompt.ChatID, prompt.MessageID)] = prompt
+	return nil
+}
+
+func (r *fakePromptRepo) FindValid(_ context.Context, chatID, messageID int64, now time.Time) (*entities.TelegramReplyPrompt, error) {
+	prompt := r.prompts[promptKey(chatID, messageID)]
+	if prompt == nil || !prompt.ExpiresAt.After(now) {
+		return nil, nil
+	}
+	return prompt, nil
+}
+
+func promptKey(chatID, messageID int64) string {
+	return strconv.FormatInt(chatID, 10) + ":" + strconv.FormatInt(messageID, 10)
+}
+
+type fakeBotClient struct {
+	replies []string
+	answers []string
+	prompts int
+	cleared int
+}
+
+func (c *fakeBotClient) SendToChatID(_ context.Context, _ int64, text string) error {
+	c.replies = append(c.replies, text)
+	return nil
+}
+
+func (c *fakeBotClient) SendPrompt(context.Context, int64, string) (int64, error) {
+	c.prompts++
+	return int64(100 + c.prompts), nil
+}
+
+func (c *fakeBotClient) AnswerCallback(_ context.Context, _ string, text string) error {
+	c.answers = append(c.answers, text)
+	return nil
+}
+
+func (c *fakeBotClient) ClearButtons(context.Context, int64, int64) error {
+	c.cleared++
+	return nil
+}
+
+func TestTelegramBotDecisionButtonAndReply(t *testing.T) {
+	t.Parallel()
+
+	const chatID = int64(42)
+	userID := uuid.New()
+	alertID := uuid.New()
+	alertRepo := &fakeBotAlertRepo{alerts: map[uuid.UUID]*entities.Alert{
+		alertID: {ID: alertID, UserID: userID, Status: entities.AlertStatusBriefed},
+	}}
+	decisionRepo := &fakeBotDecisionRepo{decisions: map[uuid.UUID]*entities.AlertDecision{}}
+	promptRepo := &fakePromptRepo{prompts: map[string]*entities.TelegramReplyPrompt{}}
+	client := &fakeBotClient{}
+
+	bot := NewTelegramBotService(
+		&fakeBotChannelRepo{chatID: chatID, userID: userID}, promptRepo, nil,
+		alertRepo, nil, nil, nil,
+		NewAlertDecisionService(alertRepo, decisionRepo), client,
+	)
+
+	data := notification.TelegramDecisionCallback(entities.DecisionHold, alertID)
+	bot.HandleCallback(context.Background(), "cb1", chatID, 7, data)
+
+	decision := decisionRepo.decisions[alertID]
+	if decision == nil || decision.Action != entities.DecisionHold {
+		t.Fatalf("expected hold decision, got %+v", decision)
+	}
+	if alertRepo.alerts[alertID].Status != entities.AlertStatusDecided {
+		t.Fatalf("expected alert decided, got %s", alertRepo.alerts[alertID].Status)
+	}
+	if client.cleared != 1 || client.prompts != 1 {
+		t.Fatalf("expected buttons cleared and one prompt, got %d/%d", client.cleared, client.prompts)
+	}
+
+	bot.HandleMessage(context.Background(), chatID, "높음 지지선 확인", 101)
+	if decision.Confidence == nil || *decision.Confidence != entities.ConfidenceHigh {
+		t.Fatalf("expected high confidence, got %v", decision.Confidence)
+	}
+	if decision.Memo == nil || *decision.Memo != "지지선 확인" {
+		t.Fatalf("unexpected memo: %v", decision.Memo)
+	}
+
+	bot.HandleCallback(context.Background(), "cb2", chatID, 7, data)
+	if last := client.answers[len(client.answers)-1]; !strings.Contains(last, "이미") {
+		t.Fatalf("expected already-decided answer, got %q", last)
+	}
+}
+
+func TestTelegramBotRejectsUnlinkedChat(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeBotClient{}
+	bot := NewTelegramBotService(
+		&fakeBotChannelRepo{chatID: 1, userID: uuid.New()}, nil, nil,
+		nil, nil, nil, nil, nil, client,
+	)
+
+	bot.HandleMessage(context.Background(), 2, "/positions", 0)
+	if len(client.replies) != 1 || !strings.Contains(client.replies[0], "연동된 계정이 없습니다") {
+		t.Fatalf("unexpected replies: %v", client.replies)
+	}
+}
+
+type fakeBotPortfolioRepo struct {
+	repositories.PortfolioRepository
+	positions []repositories.PositionSummary
+}
+
+func (r *fakeBotPortfolioRepo) ListPositions(context.Context, uuid.UUID, repositories.PositionFilter) ([]repositories.PositionSummary, error) {
+	return r.positions, nil
+}
+
+func TestTelegramBotEscapesPositions(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeBotClient{}
+	portfolio := &fakeBotPortfolioRepo{positions: []repositories.PositionSummary{
+		{Instrument: "<BTC&USDT>", VenueCode: "binance", NetQty: "1", AvgEntry: "100"},
+	}}
+	bot := NewTelegramBotService(
+		&fakeBotChannelRepo{chatID: 1, userID: uuid.New()}, nil, nil,
+		nil, portfolio, nil, nil, nil, client,
+	)
+
+	bot.HandleMessage(context.Background(), 1, "/positions", 0)
+	if len(client.replies) != 1 || !strings.Contains(client.replies[0], "&lt;BTC&amp;USDT&gt;") {
+		t.Fatalf("expected an escaped instrument, got %v", client.replies)
+	}
+}
+
+func TestParseTelegramDecisionNote(t *testing.T) {
+	t.Parallel()
+
+	confidence, memo := parseTelegramDecisionNote("low 추격 매수")
+	if confidence == nil || *confidence != "low" || memo != "추격 매수" {
+		t.Fatalf("unexpected parse: %v %q", confidence, memo)
+	}
+
+	confidence, memo = parseTelegramDecisionNote("그냥 메모")
+	if confidence != nil || memo != "그냥 메모" {
+		t.Fatalf("unexpected parse: %v %q", confidence, memo)
+	}
+}
@@ -0,0 +1,456 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+const (
+	safetyBaselineDays       = 90
+	safetyBaselineMaxOrders  = 50
+	safetySizeMinSamples     = 5
+	safetyLeverageMinSamples = 3
+	safetyReversalWindow     = 2 * time.Minute
+	// An opposite fill reverses a trade when its quantity is within 20%.
+	safetyReversalQtyTolerance = 0.2
+	safetyCalibrationDays      = 365
+	// Mistakes below a default threshold needed before it's lowered.
+	safetyCalibrationMinMistakes = 2
+	// How many verdicts the default weight of a check is worth.
+	safetyPriorStrength = 2.0
+	// How much of a user's unscored history one ScorePending run covers.
+	safetyScoreSpan = 7 * 24 * time.Hour
+)
+
+// tradeSafetyCheck describes one check. Threshold checks flag a feature at or
+// above threshold; calibration can lower the threshold down to floor. Weight
+// is the default probability that a flagged trade is a mistake.
+type tradeSafetyCheck struct {
+	threshold float64
+	floor     float64
+	weight    float64
+	feature   func(entities.TradeSafetyFeatures) *float64
+}
+
+var tradeSafetyChecks = map[entities.TradeSafetyFlagCode]tradeSafetyCheck{
+	entities.TradeSafetyFlagOversized: {
+		threshold: 5, floor: 2, weight: 0.5,
+		feature: func(f entities.TradeSafetyFeatures) *float64 { return f.SizeRatio },
+	},
+	entities.TradeSafetyFlagPriceOffMarket: {
+		threshold: 0.02, floor: 0.005, weight: 0.6,
+		feature: func(f entities.TradeSafetyFeatures) *float64 { return f.PriceDeviation },
+	},
+	entities.TradeSafetyFlagLeverageJump: {
+		threshold: 2, floor: 1.25, weight: 0.5,
+		feature: func(f entities.TradeSafetyFeatures) *float64 { return f.LeverageRatio },
+	},
+	entities.TradeSafetyFlagQuickReversal: {weight: 0.6},
+	entities.TradeSafetyFlagNewInstrument: {weight: 0.3},
+}
+
+// SafetyPriceReader provides the market close at a point in time.
+type SafetyPriceReader interface {
+	CloseAt(ctx context.Context, venue, symbol, interval string, target time.Time) (string, bool, error)
+}
+
+// TradeSafetyScoringService flags trades that look like mistakes before the
+// user reviews them, and recalibrates the checks from past verdicts. Trades
+// are scored in the background once they are stored; a trade's signal is
+// frozen once it has a verdict, so calibration learns from what the user saw.
+type TradeSafetyScoringService struct {
+	repo   repositories.TradeSafetySignalRepository
+	prices SafetyPriceReader
+	now    func() time.Time
+}
+
+func NewTradeSafetyScoringService(repo repositories.TradeSafetySignalRepository, prices SafetyPriceReader) *TradeSafetyScoringService {
+	return &TradeSafetyScoringService{repo: repo, prices: prices, now: time.Now}
+}
+
+// TradeSafetyCalibration holds the thresholds and weights in effect for a
+// user.
+type TradeSafetyCalibration struct {
+	Thresholds map[entities.TradeSafetyFlagCode]float64
+	Weights    map[entities.TradeSafetyFlagCode]float64
+}
+
+// ScorePending scores fills that have no signal yet for up to limit users,
+// a week of each user's history per run starting at their oldest unscored
+// fill, and reports how many signals it stored. Fills just before that are
+// rescored too, since the new fills may reverse them. A failure for one user
+// is logged and the rest still run.
+func (s *TradeSafetyScoringService) ScorePending(ctx context.Context, limit int) (int, error) {
+	users, err := s.repo.ListUnscoredUsers(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+	stored := 0
+	for _, user := range users {
+		from := user.From.Add(-safetyReversalWindow)
+		count, err := s.score(ctx, user.UserID, from, user.From.Add(safetyScoreSpan))
+		if err != nil {
+			log.Printf("trade safety: user %s: %v", user.UserID, err)
+			continue
+		}
+		stored += count
+	}
+	return stored, nil
+}
+
+// Signals returns the stored signals of the given targets by target id.
+// Targets the scoring job hasn't reached yet are missing from the map.
+func (s *TradeSafetyScoringService) Signals(ctx context.Context, userID uuid.UUID, targetIDs []uuid.UUID) (map[uuid.UUID]*entities.TradeSafetySignal, error) {
+	signals, err := s.repo.ListSignals(ctx, userID, targetIDs)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[uuid.UUID]*entities.TradeSafetySignal, len(signals))
+	for _, signal := range signals {
+		out[signal.TargetID] = signal
+	}
+	return out, nil
+}
+
+// score scores the fills executed in [from, to) and stores the signals.
+func (s *TradeSafetyScoringService) score(ctx context.Context, userID uuid.UUID, from, to time.Time) (int, error) {
+	now := s.now().UTC()
+	historyFrom := from.AddDate(0, 0, -safetyBaselineDays)
+	fills, err := s.repo.ListFills(ctx, userID, historyFrom, to.Add(safetyReversalWindow))
+	if err != nil {
+		return 0, err
+	}
+	symbols, err := s.repo.ListTradedSymbols(ctx, userID, historyFrom)
+	if err != nil {
+		return 0, err
+	}
+	reviewed, err := s.repo.ListReviewedSignals(ctx, userID, now.AddDate(0, 0, -safetyCalibrationDays))
+	if err != nil {
+		return 0, err
+	}
+
+	known := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		known[symbol] = true
+	}
+	prices := s.marketPrices(ctx, fills, from, to)
+	signals := ScoreTradeSafety(fills, known, prices, CalibrateTradeSafety(reviewed), from, to)
+	for _, signal := range signals {
+		signal.ComputedAt = now
+	}
+	if err := s.repo.UpsertSignals(ctx, userID, signals); err != nil {
+		return 0, err
+	}
+	return len(signals), nil
+}
+
+// marketPrices looks up the 1m close at execution for fills in [from, to).
+// Prices that can't be read are skipped, leaving the check unmeasured.
+func (s *TradeSafetyScoringService) marketPrices(ctx context.Context, fills []*repositories.SafetyFill, from, to time.Time) map[uuid.UUID]float64 {
+	prices := make(map[uuid.UUID]float64)
+	if s.prices == nil {
+		return prices
+	}
+	cache := make(map[string]float64)
+	for _, fill := range fills {
+		if fill.ExecutedAt.Before(from) || !fill.ExecutedAt.Before(to) {
+			continue
+		}
+		venue, symbol, ok := marketCandleSource(fill.SymbolKey)
+		if !ok {
+			continue
+		}
+		key := venue + ":" + symbol + ":" + fill.ExecutedAt.Truncate(time.Minute).Format(time.RFC3339)
+		if price, ok := cache[key]; ok {
+			prices[fill.ID] = price
+			continue
+		}
+		raw, ok, err := s.prices.CloseAt(ctx, venue, symbol, "1m", fill.ExecutedAt)
+		if err != nil {
+			log.Printf("trade safety: price for %s %s: %v", venue, symbol, err)
+			continue
+		}
+		price, parseErr := strconv.ParseFloat(raw, 64)
+		if !ok || parseErr != nil || price <= 0 {
+			continue
+		}
+		cache[key] = price
+		prices[fill.ID] = price
+	}
+	return prices
+}
+
+// CalibrateTradeSafety adjusts the checks to the user's verdicts. A check's
+// weight moves from its default toward the share of flagged trades marked as
+// mistakes. When at least two mistakes fell below a default threshold and
+// they outnumber intended trades in that band, the threshold drops to their
+// median (never below the check's floor).
+func CalibrateTradeSafety(reviewed []*entities.TradeSafetySignal) TradeSafetyCalibration {
+	calibration := TradeSafetyCalibration{
+		Thresholds: make(map[entities.TradeSafetyFlagCode]float64),
+		Weights:    make(map[entities.TradeSafetyFlagCode]float64),
+	}
+	for code, check := range tradeSafetyChecks {
+		var mistakes, intended float64
+		for _, signal := range reviewed {
+			if signal.Verdict == nil || !hasTradeSafetyFlag(signal, code) {
+				continue
+			}
+			switch *signal.Verdict {
+			case entities.TradeSafetyVerdictMistake:
+				mistakes++
+			case entities.TradeSafetyVerdictIntended:
+				intended++
+			}
+		}
+		calibration.Weights[code] = (mistakes + check.weight*safetyPriorStrength) / (mistakes + intended + safetyPriorStrength)
+
+		if check.feature == nil {
+			continue
+		}
+		calibration.Thresholds[code] = check.threshold
+		var below []float64
+		for _, signal := range reviewed {
+			value := check.feature(signal.Features)
+			if signal.Verdict == nil || *signal.Verdict != entities.TradeSafetyVerdictMistake || value == nil {
+				continue
+			}
+			if *value >= check.floor && *value < check.threshold {
+				below = append(below, *value)
+			}
+		}
+		if len(below) < safetyCalibrationMinMistakes {
+			continue
+		}
+		candidate := math.Max(check.floor, median(below))
+		inBand := 0
+		for _, value := range below {
+			if value >= candidate {
+				inBand++
+			}
+		}
+		intendedInBand := 0
+		for _, signal := range reviewed {
+			value := check.feature(signal.Features)
+			if signal.Verdict != nil && *signal.Verdict == entities.TradeSafetyVerdictIntended &&
+				value != nil && *value >= candidate && *value < check.threshold {
+				intendedInBand++
+			}
+		}
+		if inBand > intendedInBand {
+			calibration.Thresholds[code] = candidate
+		}
+	}
+	return calibration
+}
+
+// ScoreTradeSafety measures and flags the fills executed in [from, to).
+// Earlier fills serve as the size and leverage baseline, later ones to detect
+// reversals. known holds symbols traded before the first fill.
+func ScoreTradeSafety(
+	fills []*repositories.SafetyFill,
+	known map[string]bool,
+	prices map[uuid.UUID]float64,
+	calibration TradeSafetyCalibration,
+	from, to time.Time,
+) []*entities.TradeSafetySignal {
+	seen := make(map[string]bool, len(known))
+	for symbol := range known {
+		seen[symbol] = true
+	}
+	notionals := make(map[string][]float64)
+	var leverages []float64
+
+	var signals []*entities.TradeSafetySignal
+	for i, fill := range fills {
+		qty, _ := strconv.ParseFloat(fill.Qty, 64)
+		price, _ := strconv.ParseFloat(fill.Price, 64)
+		notional := math.Abs(qty * price)
+		leverage := parseSafetyLeverage(fill.Leverage)
+
+		if !fill.ExecutedAt.Before(from) && fill.ExecutedAt.Before(to) {
+			features := entities.TradeSafetyFeatures{NewInstrument: !seen[fill.SymbolKey]}
+			if history := notionals[fill.SymbolKey]; len(history) >= safetySizeMinSamples && notional > 0 {
+				if typical := median(history); typical > 0 {
+					features.SizeRatio = floatPtr(notional / typical)
+				}
+			}
+			if market, ok := prices[fill.ID]; ok && price > 0 {
+				features.PriceDeviation = floatPtr(math.Abs(price-market) / market)
+			}
+			features.ReversalSeconds = safetyReversal(fills, i, qty)
+			if leverage > 0 && len(leverages) >= safetyLeverageMinSamples {
+				if typical := median(leverages); typical > 0 {
+					features.LeverageRatio = floatPtr(leverage / typical)
+				}
+			}
+			signals = append(signals, newTradeSafetySignal(fill, features, calibration, notional, leverage, prices[fill.ID]))
+		}
+
+		if fill.Mirrored {
+			continue
+		}
+		seen[fill.SymbolKey] = true
+		if notional > 0 {
+			history := append(notionals[fill.SymbolKey], notional)
+			if len(history) > safetyBaselineMaxOrders {
+				history = history[len(history)-safetyBaselineMaxOrders:]
+			}
+			notionals[fill.SymbolKey] = history
+		}
+		if leverage > 0 {
+			leverages = append(leverages, leverage)
+			if len(leverages) > safetyBaselineMaxOrders {
+				leverages = leverages[len(leverages)-safetyBaselineMaxOrders:]
+			}
+		}
+	}
+	return signals
+}
+
+// safetyReversal returns the delay until an opposite fill of similar size on
+// the same symbol, within the reversal window.
+func safetyReversal(fills []*repositories.SafetyFill, i int, qty float64) *float64 {
+	fill := fills[i]
+	for _, next := range fills[i+1:] {
+		delay := next.ExecutedAt.Sub(fill.ExecutedAt)
+		if delay > safetyReversalWindow {
+			break
+		}
+		if next.SymbolKey != fill.SymbolKey || next.Side == fill.Side || next.Mirrored != fill.Mirrored {
+			continue
+		}
+		nextQty, _ := strconv.ParseFloat(next.Qty, 64)
+		if qty > 0 && math.Abs(math.Abs(nextQty)-math.Abs(qty)) <= math.Abs(qty)*safetyReversalQtyTolerance {
+			return floatPtr(delay.Seconds())
+		}
+	}
+	return nil
+}
+
+func newTradeSafetySignal(
+	fill *repositories.SafetyFill,
+	features entities.TradeSafetyFeatures,
+	calibration TradeSafetyCalibration,
+	notional, leverage, market float64,
+) *entities.TradeSafetySignal {
+	signal := &entities.TradeSafetySignal{
+		TargetType: fill.TargetType,
+		TargetID:   fill.ID,
+		Features:   features,
+		Flags:      []entities.TradeSafetyFlag{},
+	}
+	flag := func(code entities.TradeSafetyFlagCode, reason string) {
+		signal.Flags = append(signal.Flags, entities.TradeSafetyFlag{
+			Code:   code,
+			Reason: reason,
+			Weight: roundSafety(calibration.weight(code)),
+		})
+	}
+
+	if v := features.SizeRatio; v != nil && *v >= calibration.threshold(entities.TradeSafetyFlagOversized) {
+		flag(entities.TradeSafetyFlagOversized,
+			fmt.Sprintf("order size is %.1fx your typical %s order", *v, fill.SymbolKey))
+	}
+	if v := features.PriceDeviation; v != nil && *v >= calibration.threshold(entities.TradeSafetyFlagPriceOffMarket) {
+		flag(entities.TradeSafetyFlagPriceOffMarket,
+			fmt.Sprintf("price %s is %.1f%% away from the market at %s", fill.Price, *v*100, strconv.FormatFloat(market, 'f', -1, 64)))
+	}
+	if v := features.ReversalSeconds; v != nil {
+		flag(entities.TradeSafetyFlagQuickReversal,
+			fmt.Sprintf("reversed by an opposite fill %.0fs later", *v))
+	}
+	if features.NewInstrument {
+		flag(entities.TradeSafetyFlagNewInstrument,
+			fmt.Sprintf("first trade on %s", fill.SymbolKey))
+	}
+	if v := features.LeverageRatio; v != nil && *v >= calibration.threshold(entities.TradeSafetyFlagLeverageJump) {
+		flag(entities.TradeSafetyFlagLeverageJump,
+			fmt.Sprintf("leverage %sx is %.1fx your usual", strconv.FormatFloat(leverage, 'f', -1, 64), *v))
+	}
+
+	// Flags are treated as independent chances of a mistake.
+	clean := 1.0
+	for _, f := range signal.Flags {
+		clean *= 1 - f.Weight
+	}
+	signal.Score = roundSafety(1 - clean)
+	return signal
+}
+
+// TradeSafetyRisk buckets a score for display.
+func TradeSafetyRisk(score float64) string {
+	switch {
+	case score >= 0.7:
+		return "high"
+	case score >= 0.4:
+		return "medium"
+	case score > 0:
+		return "low"
+	}
+	return "none"
+}
+
+func (c TradeSafetyCalibration) threshold(code entities.TradeSafetyFlagCode) float64 {
+	if value, ok := c.Thresholds[code]; ok {
+		return value
+	}
+	return tradeSafetyChecks[code].threshold
+}
+
+func (c TradeSafetyCalibration) weight(code entities.TradeSafetyFlagCode) float64 {
+	if value, ok := c.Weights[code]; ok {
+		return value
+	}
+	return tradeSafetyChecks[code].weight
+}
+
+func hasTradeSafetyFlag(signal *entities.TradeSafetySignal, code entities.TradeSafetyFlagCode) bool {
+	for _, flag := range signal.Flags {
+		if flag.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func parseSafetyLeverage(raw *string) float64 {
+	if raw == nil {
+		return 0
+	}
+	value, err := strconv.ParseFloat(*raw, 64)
+	if err != nil || value <= 0 {
+		return 0
+	}
+	return value
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+func roundSafety(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
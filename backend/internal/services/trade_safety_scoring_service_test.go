@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moneyvessel/kifu/internal/domain/entities"
+	"github.com/moneyvessel/kifu/internal/domain/repositories"
+)
+
+func safetyFill(symbol, side, qty, price string, at time.Time) *repositories.SafetyFill {
+	return &repositories.SafetyFill{TargetType: "trade", ID: uuid.New(), Venue: "binance_futures", SymbolKey: symbol, Side: side, Qty: qty, Price: price, ExecutedAt: at}
+}
+
+func safetyFlagCodes(signal *entities.TradeSafetySignal) map[entities.TradeSafetyFlagCode]bool {
+	codes := map[entities.TradeSafetyFlagCode]bool{}
+	for _, flag := range signal.Flags {
+		codes[flag.Code] = true
+	}
+	return codes
+}
+
+func TestScoreTradeSafetyFlags(t *testing.T) {
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	var fills []*repositories.SafetyFill
+	// Five earlier 1 BTC orders at 100 set the typical size; leverage 5x.
+	for i := 0; i < 5; i++ {
+		fill := safetyFill("BTCUSDT", "buy", "1", "100", day.AddDate(0, 0, -5+i))
+		fill.Leverage = strPtr("5")
+		fills = append(fills, fill)
+	}
+	oversized := safetyFill("BTCUSDT", "buy", "8", "100", day.Add(time.Hour))
+	oversized.Leverage = strPtr("20")
+	reversal := safetyFill("BTCUSDT", "sell", "8", "99", day.Add(time.Hour+30*time.Second))
+	newSymbol := safetyFill("PEPEUSDT", "buy", "1000", "0.01", day.Add(2*time.Hour))
+	fills = append(fills, oversized, reversal, newSymbol)
+
+	prices := map[uuid.UUID]float64{oversized.ID: 100, newSymbol.ID: 0.0125}
+	signals := ScoreTradeSafety(fills, map[string]bool{"BTCUSDT": true}, prices, CalibrateTradeSafety(nil), day, day.AddDate(0, 0, 1))
+	if len(signals) != 3 {
+		t.Fatalf("expected the three fills of the day to be scored, got %d", len(signals))
+	}
+
+	first := safetyFlagCodes(signals[0])
+	for _, code := range []entities.TradeSafetyFlagCode{entities.TradeSafetyFlagOversized, entities.TradeSafetyFlagQuickReversal, entities.TradeSafetyFlagLeverageJump} {
+		if !first[code] {
+			t.Errorf("expected %s on the oversized order, got %+v", code, signals[0].Flags)
+		}
+	}
+	if first[entities.TradeSafetyFlagPriceOffMarket] || first[entities.TradeSafetyFlagNewInstrument] {
+		t.Errorf("unexpected flags on the oversized order: %+v", signals[0].Flags)
+	}
+	if *signals[0].Features.SizeRatio != 8 || *signals[0].Features.ReversalSeconds != 30 {
+		t.Errorf("unexpected features: %+v", signals[0].Features)
+	}
+	if TradeSafetyRisk(signals[0].Score) != "high" {
+		t.Errorf("expected high risk with three flags, got %v", signals[0].Score)
+	}
+
+	last := safetyFlagCodes(signals[2])
+	if !last[entities.TradeSafetyFlagNewInstrument] || !last[entities.TradeSafetyFlagPriceOffMarket] {
+		t.Errorf("expected new instrument and off-market price, got %+v", signals[2].Flags)
+	}
+}
+
+func TestCalibrateTradeSafetyLearnsFromMistakes(t *testing.T) {
+	mistake := entities.TradeSafetyVerdictMistake
+	intended := entities.TradeSafetyVerdictIntended
+	reviewed := []*entities.TradeSafetySignal{
+		// Two mistakes at 3x and 4x size, below the default 5x threshold.
+		{Features: entities.TradeSafetyFeatures{SizeRatio: floatPtr(3)}, Flags: []entities.TradeSafetyFlag{}, Verdict: &mistake},
+		{Features: entities.TradeSafetyFeatures{SizeRatio: floatPtr(4)}, Flags: []entities.TradeSafetyFlag{}, Verdict: &mistake},
+		// New instruments keep turning out intended.
+		{Flags: []entities.TradeSafetyFlag{{Code: entities.TradeSafetyFlagNewInstrument}}, Verdict: &intended},
+		{Flags: []entities.TradeSafetyFlag{{Code: entities.TradeSafetyFlagNewInstrument}}, Verdict: &intended},
+	}
+
+	calibration := CalibrateTradeSafety(reviewed)
+	if got := calibration.Thresholds[entities.TradeSafetyFlagOversized]; got != 3.5 {
+		t.Fatalf("expected the size threshold to drop to 3.5, got %v", got)
+	}
+	if got := calibration.Weights[entities.TradeSafetyFlagNewInstrument]; got >= tradeSafetyChecks[entities.TradeSafetyFlagNewInstrument].weight {
+		t.Fatalf("expected the new instrument weight to drop, got %v", got)
+	}
+	if got := calibration.Thresholds[entities.TradeSafetyFlagPriceOffMarket]; got != 0.02 {
+		t.Fatalf("expected the price threshold to stay at its default, got %v", got)
+	}
+
+	// Intended trades in the band keep the default threshold.
+	reviewed = append(reviewed,
+		&entities.TradeSafetySignal{Features: entities.TradeSafetyFeatures{SizeRatio: floatPtr(3.6)}, Verdict: &intended},
+		&entities.TradeSafetySignal{Features: entities.TradeSafetyFeatures{SizeRatio: floatPtr(4.5)}, Verdict: &intended},
+	)
+	if got := CalibrateTradeSafety(reviewed).Thresholds[entities.TradeSafetyFlagOversized]; got != 5 {
+		t.Fatalf("expected the default threshold when intended trades dominate, got %v", got)
+	}
+}
+
+type fakeSafetyRepo struct {
+	repositories.TradeSafetySignalRepository
+	users    []*repositories.UnscoredSafetyUser
+	fills    []*repositories.SafetyFill
+	upserted []*entities.TradeSafetySignal
+}
+
+func (f *fakeSafetyRepo) ListUnscoredUsers(_ context.Context, _ int) ([]*repositories.UnscoredSafetyUser, error) {
+	return f.users, nil
+}
+
+func (f *fakeSafetyRepo) ListFills(_ context.Context, _ uuid.UUID, from, to time.Time) ([]*repositories.SafetyFill, error) {
+	var out []*repositories.SafetyFill
+	for _, fill := range f.fills {
+		if !fill.ExecutedAt.Before(from) && !fill.ExecutedAt.After(to) {
+			out = append(out, fill)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSafetyRepo) ListTradedSymbols(_ context.Context, _ uuid.UUID, _ time.Time) ([]string, error) {
+	return []string{"BTCUSDT"}, nil
+}
+
+func (f *fakeSafetyRepo) ListReviewedSignals(_ context.Context, _ uuid.UUID, _ time.Time) ([]*entities.TradeSafetySignal, error) {
+	return nil, nil
+}
+
+func (f *fakeSafetyRepo) UpsertSignals(_ context.Context, _ uuid.UUID, signals []*entities.TradeSafetySignal) error {
+	f.upserted = append(f.upserted, signals...)
+	return nil
+}
+
+func TestScorePendingRescoresReversedFillAndStopsAtSpan(t *testing.T) {
+	day := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	// The buy was scored on its own; the sell that reverses it arrives later.
+	buy := safetyFill("BTCUSDT", "buy", "1", "100", day)
+	sell := safetyFill("BTCUSDT", "sell", "1", "100", day.Add(time.Minute))
+	later := safetyFill("BTCUSDT", "buy", "1", "100", day.Add(safetyScoreSpan+time.Hour))
+	repo := &fakeSafetyRepo{
+		users: []*repositories.UnscoredSafetyUser{{UserID: uuid.New(), From: sell.ExecutedAt}},
+		fills: []*repositories.SafetyFill{buy, sell, later},
+	}
+	svc := NewTradeSafetyScoringService(repo, nil)
+	svc.now = func() time.Time { return day.AddDate(0, 0, 30) }
+
+	stored, err := svc.ScorePending(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("ScorePending: %v", err)
+	}
+	if stored != 2 || len(repo.upserted) != 2 {
+		t.Fatalf("expected the buy and the sell to be scored, got %d", stored)
+	}
+	if repo.upserted[0].TargetID != buy.ID || repo.upserted[0].Features.ReversalSeconds == nil {
+		t.Fatalf("expected the buy to be rescored as reversed, got %+v", repo.upserted[0])
+	}
+	for _, signal := range repo.upserted {
+		if signal.TargetID == later.ID {
+			t.Fatalf("expected fills past the span to wait for the next run")
+		}
+	}
+}
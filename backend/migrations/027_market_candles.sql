@@ -0,0 +1,16 @@
+-- Shared OHLCV candle store keyed by venue, symbol and interval
+CREATE TABLE IF NOT EXISTS market_candles (
+    venue VARCHAR(20) NOT NULL,
+    symbol VARCHAR(30) NOT NULL,
+    interval VARCHAR(10) NOT NULL,
+    open_time TIMESTAMPTZ NOT NULL,
+    open NUMERIC NOT NULL,
+    high NUMERIC NOT NULL,
+    low NUMERIC NOT NULL,
+    close NUMERIC NOT NULL,
+    volume NUMERIC NOT NULL,
+    fetched_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (venue, symbol, interval, open_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_market_candles_fetched_at ON market_candles(fetched_at DESC);
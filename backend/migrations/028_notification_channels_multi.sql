@@ -0,0 +1,37 @@
+-- Email, Discord and Slack channels with per-severity routing
+ALTER TABLE notification_channels DROP CONSTRAINT IF EXISTS notification_channels_channel_type_check;
+ALTER TABLE notification_channels ADD CONSTRAINT notification_channels_channel_type_check
+    CHECK (channel_type IN ('telegram', 'web_push', 'email', 'discord', 'slack'));
+
+ALTER TABLE notification_channels
+    ADD COLUMN IF NOT EXISTS severities TEXT[] NOT NULL DEFAULT ARRAY['normal', 'urgent']::TEXT[];
+
+-- Verification codes for channels that are confirmed from inside the app
+CREATE TABLE IF NOT EXISTS notification_verify_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel_type VARCHAR(20) NOT NULL,
+    code VARCHAR(6) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_verify_codes_user_type
+    ON notification_verify_codes(user_id, channel_type) WHERE used = false;
+
+-- Per-channel delivery log
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel_type VARCHAR(20) NOT NULL,
+    alert_id UUID REFERENCES alerts(id) ON DELETE SET NULL,
+    title TEXT NOT NULL,
+    severity VARCHAR(10) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('sent', 'failed')),
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_user_created
+    ON notification_deliveries(user_id, created_at DESC);
@@ -0,0 +1,30 @@
+-- Turn the delivery log into an outbox: every message is queued per channel,
+-- retried with backoff and dead-lettered after too many failures.
+ALTER TABLE notification_deliveries
+    ADD COLUMN IF NOT EXISTS body TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS deep_link TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS digest BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS sent_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+ALTER TABLE notification_deliveries DROP CONSTRAINT IF EXISTS notification_deliveries_status_check;
+ALTER TABLE notification_deliveries ADD CONSTRAINT notification_deliveries_status_check
+    CHECK (status IN ('pending', 'sent', 'failed', 'dead'));
+
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_due
+    ON notification_deliveries(next_attempt_at) WHERE status IN ('pending', 'failed');
+
+-- Per-user delivery preferences: quiet hours are minutes from local midnight
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    quiet_hours_enabled BOOLEAN NOT NULL DEFAULT false,
+    quiet_start_minute INT NOT NULL DEFAULT 1380 CHECK (quiet_start_minute BETWEEN 0 AND 1439),
+    quiet_end_minute INT NOT NULL DEFAULT 420 CHECK (quiet_end_minute BETWEEN 0 AND 1439),
+    urgent_bypass_quiet BOOLEAN NOT NULL DEFAULT true,
+    digest_enabled BOOLEAN NOT NULL DEFAULT false,
+    digest_interval_minutes INT NOT NULL DEFAULT 60 CHECK (digest_interval_minutes BETWEEN 15 AND 1440),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,14 @@
+-- Bot messages that ask the user to reply with a memo/confidence for a decision
+CREATE TABLE IF NOT EXISTS telegram_reply_prompts (
+    chat_id BIGINT NOT NULL,
+    message_id BIGINT NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    alert_id UUID NOT NULL REFERENCES alerts(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (chat_id, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_channels_telegram_chat
+    ON notification_channels(((config->>'chat_id')))
+    WHERE channel_type = 'telegram';
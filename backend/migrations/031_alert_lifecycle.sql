@@ -0,0 +1,44 @@
+-- Alert lifecycle: per-rule expiry, snooze, acknowledge, escalation and an
+-- event history for every alert state change.
+
+ALTER TABLE alert_rules ADD COLUMN IF NOT EXISTS expiry_minutes INT NOT NULL DEFAULT 1440
+    CHECK (expiry_minutes BETWEEN 5 AND 10080);
+-- 0 disables escalation for the rule.
+ALTER TABLE alert_rules ADD COLUMN IF NOT EXISTS escalate_after_minutes INT NOT NULL DEFAULT 15
+    CHECK (escalate_after_minutes BETWEEN 0 AND 1440);
+
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS snoozed_until TIMESTAMPTZ;
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS acknowledged_at TIMESTAMPTZ;
+ALTER TABLE alerts ADD COLUMN IF NOT EXISTS escalated_at TIMESTAMPTZ;
+
+-- Existing alerts keep the old fixed 24h window.
+UPDATE alerts SET expires_at = created_at + INTERVAL '24 hours' WHERE expires_at IS NULL;
+ALTER TABLE alerts ALTER COLUMN expires_at SET NOT NULL;
+
+ALTER TABLE alerts DROP CONSTRAINT IF EXISTS alerts_status_check;
+ALTER TABLE alerts ADD CONSTRAINT alerts_status_check
+    CHECK (status IN ('pending', 'briefed', 'acknowledged', 'snoozed', 'decided', 'expired'));
+
+CREATE INDEX IF NOT EXISTS idx_alerts_expires ON alerts(expires_at)
+    WHERE status IN ('pending', 'briefed', 'acknowledged', 'snoozed');
+CREATE INDEX IF NOT EXISTS idx_alerts_snoozed ON alerts(snoozed_until) WHERE status = 'snoozed';
+
+CREATE TABLE IF NOT EXISTS alert_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    alert_id UUID NOT NULL REFERENCES alerts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_type VARCHAR(20) NOT NULL,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20),
+    detail JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_events_alert ON alert_events(alert_id, created_at);
+
+-- Give existing alerts a starting point in their history.
+INSERT INTO alert_events (alert_id, user_id, event_type, to_status, created_at)
+SELECT a.id, a.user_id, 'created', 'pending', a.created_at
+FROM alerts a
+WHERE NOT EXISTS (SELECT 1 FROM alert_events e WHERE e.alert_id = a.id);
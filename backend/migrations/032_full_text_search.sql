@@ -0,0 +1,26 @@
+-- Full-text search over journal text.
+--
+-- The 'simple' configuration lowercases and splits on whitespace and
+-- punctuation without stemming, which keeps Korean tokens intact (a Korean
+-- word with its particle attached, e.g. '손절을', stays one token). Queries
+-- use prefix matching so a bare stem ('손절:*', 'stop:*') still matches.
+
+ALTER TABLE bubbles ADD COLUMN IF NOT EXISTS search_tsv tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(memo, ''))) STORED;
+CREATE INDEX IF NOT EXISTS idx_bubbles_search ON bubbles USING GIN(search_tsv);
+
+ALTER TABLE review_notes ADD COLUMN IF NOT EXISTS search_tsv tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(lesson_learned, '')), 'B') ||
+        to_tsvector('simple', coalesce(content, ''))
+    ) STORED;
+CREATE INDEX IF NOT EXISTS idx_review_notes_search ON review_notes USING GIN(search_tsv);
+
+ALTER TABLE guided_review_items ADD COLUMN IF NOT EXISTS search_tsv tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(memo, ''))) STORED;
+CREATE INDEX IF NOT EXISTS idx_guided_review_items_search ON guided_review_items USING GIN(search_tsv);
+
+ALTER TABLE ai_opinions ADD COLUMN IF NOT EXISTS search_tsv tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(response, ''))) STORED;
+CREATE INDEX IF NOT EXISTS idx_ai_opinions_search ON ai_opinions USING GIN(search_tsv);
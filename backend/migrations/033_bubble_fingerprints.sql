@@ -0,0 +1,15 @@
+-- Market-context fingerprints: a normalized candle-shape and indicator
+-- vector for the window before each bubble, used to find bubbles taken in
+-- similar markets across symbols.
+CREATE TABLE IF NOT EXISTS bubble_fingerprints (
+    bubble_id UUID PRIMARY KEY REFERENCES bubbles(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    version VARCHAR(10) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('ready', 'unavailable')),
+    candle_interval VARCHAR(5) NOT NULL,
+    window_end TIMESTAMPTZ NOT NULL,
+    vector DOUBLE PRECISION[],
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bubble_fingerprints_user ON bubble_fingerprints(user_id, version) WHERE status = 'ready';
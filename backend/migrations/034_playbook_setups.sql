@@ -0,0 +1,48 @@
+-- Playbook: named trade setups with their rules, linked from bubbles, trades
+-- and manual positions. setup_checked holds the checklist items the user
+-- confirmed when linking, which is what rule adherence is measured from.
+CREATE TABLE IF NOT EXISTS playbook_setups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(80) NOT NULL,
+    description TEXT,
+    direction VARCHAR(5) NOT NULL DEFAULT 'long' CHECK (direction IN ('long', 'short')),
+    entry_criteria TEXT,
+    invalidation TEXT,
+    target_rr NUMERIC(10, 2),
+    risk_percent NUMERIC(10, 4),
+    checklist TEXT[] NOT NULL DEFAULT '{}',
+    archived_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_playbook_setups_user_name ON playbook_setups(user_id, lower(name));
+
+ALTER TABLE bubbles ADD COLUMN IF NOT EXISTS setup_id UUID REFERENCES playbook_setups(id) ON DELETE SET NULL;
+ALTER TABLE bubbles ADD COLUMN IF NOT EXISTS setup_checked TEXT[];
+CREATE INDEX IF NOT EXISTS idx_bubbles_setup ON bubbles(setup_id) WHERE setup_id IS NOT NULL;
+
+ALTER TABLE trades ADD COLUMN IF NOT EXISTS setup_id UUID REFERENCES playbook_setups(id) ON DELETE SET NULL;
+ALTER TABLE trades ADD COLUMN IF NOT EXISTS setup_checked TEXT[];
+CREATE INDEX IF NOT EXISTS idx_trades_setup ON trades(setup_id) WHERE setup_id IS NOT NULL;
+
+ALTER TABLE manual_positions ADD COLUMN IF NOT EXISTS setup_id UUID REFERENCES playbook_setups(id) ON DELETE SET NULL;
+ALTER TABLE manual_positions ADD COLUMN IF NOT EXISTS setup_checked TEXT[];
+CREATE INDEX IF NOT EXISTS idx_manual_positions_setup ON manual_positions(setup_id) WHERE setup_id IS NOT NULL;
+
+-- Existing free-text strategies become setups so their positions keep a link.
+INSERT INTO playbook_setups (user_id, name, direction)
+SELECT DISTINCT ON (user_id, lower(btrim(strategy)))
+       user_id, left(btrim(strategy), 80), 'long'
+FROM manual_positions
+WHERE strategy IS NOT NULL AND btrim(strategy) <> ''
+ORDER BY user_id, lower(btrim(strategy)), created_at
+ON CONFLICT DO NOTHING;
+
+UPDATE manual_positions mp
+SET setup_id = s.id
+FROM playbook_setups s
+WHERE mp.setup_id IS NULL
+  AND s.user_id = mp.user_id
+  AND lower(s.name) = lower(left(btrim(mp.strategy), 80));
@@ -0,0 +1,28 @@
+-- Plan vs execution: how the synced fills for a manual position (the
+-- pre-trade plan) compared with its planned entry, size, stop and target.
+-- One row per plan, rewritten on every evaluation. stop_at_entry is the stop
+-- the plan had when the first entry fill arrived, so later edits show up as
+-- a moved stop.
+CREATE TABLE IF NOT EXISTS plan_executions (
+    position_id UUID PRIMARY KEY REFERENCES manual_positions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    symbol_key VARCHAR(40) NOT NULL,
+    status VARCHAR(10) NOT NULL CHECK (status IN ('pending', 'open', 'closed')),
+    fill_count INT NOT NULL DEFAULT 0,
+    entry_qty NUMERIC,
+    entry_price NUMERIC,
+    exit_qty NUMERIC,
+    exit_price NUMERIC,
+    first_fill_at TIMESTAMPTZ,
+    last_fill_at TIMESTAMPTZ,
+    stop_at_entry NUMERIC,
+    entry_slippage_percent NUMERIC,
+    size_deviation_percent NUMERIC,
+    stop_status VARCHAR(12) NOT NULL CHECK (stop_status IN ('no_stop', 'honored', 'trailed', 'moved', 'violated')),
+    early_exit BOOLEAN,
+    score INT,
+    evaluated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_plan_executions_user_fill ON plan_executions(user_id, first_fill_at DESC);
+CREATE INDEX IF NOT EXISTS idx_plan_executions_user_symbol ON plan_executions(user_id, symbol_key);
@@ -0,0 +1,28 @@
+-- Maximum adverse and favorable excursion for closed manual positions, read
+-- from candle highs and lows between opened_at and closed_at. Percentages are
+-- relative to the entry price and signed from the position's side, so MAE is
+-- zero or negative and MFE zero or positive. The R columns are only set when
+-- the position had a stop on the losing side of entry.
+-- source_updated_at is the position's updated_at at computation time; an
+-- edited position is picked up again.
+CREATE TABLE IF NOT EXISTS position_excursions (
+    position_id UUID PRIMARY KEY REFERENCES manual_positions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(12) NOT NULL CHECK (status IN ('ready', 'unavailable')),
+    candle_interval VARCHAR(5),
+    entry_price NUMERIC,
+    exit_price NUMERIC,
+    stop_price NUMERIC,
+    pnl_percent NUMERIC,
+    mae_percent NUMERIC,
+    mfe_percent NUMERIC,
+    mae_price NUMERIC,
+    mfe_price NUMERIC,
+    r_multiple NUMERIC,
+    mae_r NUMERIC,
+    mfe_r NUMERIC,
+    source_updated_at TIMESTAMPTZ NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_position_excursions_user ON position_excursions(user_id);
@@ -0,0 +1,24 @@
+-- Per-user outcome horizons on top of the global ones (OUTCOME_INTERVALS,
+-- default 1h/4h/1d). A period is a count plus a unit (m/h/d/w), e.g. 15m or
+-- 3d, or next_bubble for "until the user's next bubble on the same symbol".
+CREATE TABLE IF NOT EXISTS user_outcome_horizons (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    period VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, period)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_outcome_horizons_period ON user_outcome_horizons(period);
+
+ALTER TABLE outcomes DROP CONSTRAINT IF EXISTS outcomes_period_check;
+ALTER TABLE outcomes ALTER COLUMN period TYPE VARCHAR(20);
+ALTER TABLE ai_opinion_accuracies ALTER COLUMN period TYPE VARCHAR(20);
+
+-- Where the outcome price came from and the moment it was priced at; NULL on
+-- rows written before this migration.
+ALTER TABLE outcomes ADD COLUMN IF NOT EXISTS price_source VARCHAR(20);
+ALTER TABLE outcomes ADD COLUMN IF NOT EXISTS target_time TIMESTAMPTZ;
+
+ALTER TABLE outcomes DROP CONSTRAINT IF EXISTS outcomes_period_format;
+ALTER TABLE outcomes ADD CONSTRAINT outcomes_period_format
+    CHECK (period ~ '^[1-9][0-9]{0,5}[mhdw]$' OR period = 'next_bubble');
@@ -0,0 +1,20 @@
+-- Behavioral patterns detected from synced trades and imported trade events.
+-- symbol is the normalized symbol key (letters and digits, uppercase) and is
+-- empty for day-level patterns such as overtrading. trade_date is the UTC day
+-- the episode started, matching how guided reviews bucket trades.
+CREATE TABLE IF NOT EXISTS behavior_episodes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pattern VARCHAR(20) NOT NULL CHECK (pattern IN ('revenge_reentry', 'size_escalation', 'overtrading', 'off_hours')),
+    symbol VARCHAR(40) NOT NULL DEFAULT '',
+    severity VARCHAR(10) NOT NULL CHECK (severity IN ('low', 'medium', 'high')),
+    started_at TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ NOT NULL,
+    trade_date DATE NOT NULL,
+    trade_count INT NOT NULL DEFAULT 0,
+    details JSONB NOT NULL DEFAULT '{}'::jsonb,
+    detected_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, pattern, symbol, started_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_behavior_episodes_user_date ON behavior_episodes(user_id, trade_date DESC);
@@ -0,0 +1,38 @@
+-- Weekly and monthly guided reviews next to the daily one. review_date is the
+-- first day of the period and period_end the last (equal for daily reviews).
+ALTER TABLE guided_reviews ADD COLUMN IF NOT EXISTS cadence VARCHAR(10) NOT NULL DEFAULT 'daily';
+ALTER TABLE guided_reviews ADD COLUMN IF NOT EXISTS period_end DATE;
+UPDATE guided_reviews SET period_end = review_date WHERE period_end IS NULL;
+ALTER TABLE guided_reviews ALTER COLUMN period_end SET NOT NULL;
+
+ALTER TABLE guided_reviews DROP CONSTRAINT IF EXISTS guided_reviews_cadence_check;
+ALTER TABLE guided_reviews ADD CONSTRAINT guided_reviews_cadence_check
+    CHECK (cadence IN ('daily', 'weekly', 'monthly'));
+
+ALTER TABLE guided_reviews DROP CONSTRAINT IF EXISTS guided_reviews_user_id_review_date_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_guided_reviews_user_cadence_date
+    ON guided_reviews(user_id, cadence, review_date);
+
+-- User-defined question templates. Saving a template adds a new version so
+-- answers given under older versions keep their meaning; each review records
+-- the version it was created with.
+CREATE TABLE IF NOT EXISTS review_question_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    cadence VARCHAR(10) NOT NULL CHECK (cadence IN ('daily', 'weekly', 'monthly')),
+    version INT NOT NULL,
+    name VARCHAR(80) NOT NULL,
+    questions JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, cadence, version)
+);
+
+ALTER TABLE guided_reviews ADD COLUMN IF NOT EXISTS template_id UUID REFERENCES review_question_templates(id) ON DELETE SET NULL;
+
+-- Answers to template questions, keyed by question key.
+ALTER TABLE guided_review_items ADD COLUMN IF NOT EXISTS answers JSONB;
+
+-- One streak per cadence.
+ALTER TABLE user_streaks ADD COLUMN IF NOT EXISTS cadence VARCHAR(10) NOT NULL DEFAULT 'daily';
+ALTER TABLE user_streaks DROP CONSTRAINT IF EXISTS user_streaks_pkey;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_streaks_user_cadence ON user_streaks(user_id, cadence);
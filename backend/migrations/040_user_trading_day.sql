@@ -0,0 +1,7 @@
+-- Persistent timezone and trading-day cutoff per user. A trading day starts
+-- at day_cutoff_hour local time, e.g. 9 for a 09:00 KST crypto day.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS day_cutoff_hour SMALLINT NOT NULL DEFAULT 0;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_day_cutoff_hour_check;
+ALTER TABLE users ADD CONSTRAINT users_day_cutoff_hour_check CHECK (day_cutoff_hour BETWEEN 0 AND 23);
@@ -0,0 +1,26 @@
+-- Automatic safety checks for the daily quick-check flow. Each row keeps the
+-- measurements a trade was scored on and the flags raised, so verdicts given
+-- later can recalibrate the checks.
+CREATE TABLE IF NOT EXISTS trade_safety_signals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    trade_id UUID REFERENCES trades(id) ON DELETE CASCADE,
+    trade_event_id UUID REFERENCES trade_events(id) ON DELETE CASCADE,
+    features JSONB NOT NULL DEFAULT '{}'::jsonb,
+    flags JSONB NOT NULL DEFAULT '[]'::jsonb,
+    score NUMERIC(6, 4) NOT NULL DEFAULT 0,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (
+      (trade_id IS NOT NULL AND trade_event_id IS NULL)
+      OR
+      (trade_id IS NULL AND trade_event_id IS NOT NULL)
+    )
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_trade_safety_signals_trade
+  ON trade_safety_signals(user_id, trade_id)
+  WHERE trade_id IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_trade_safety_signals_trade_event
+  ON trade_safety_signals(user_id, trade_event_id)
+  WHERE trade_event_id IS NOT NULL;
@@ -0,0 +1,45 @@
+-- Personal risk limits. Each user keeps their limits as a single alert rule of
+-- type risk_limit (symbol '*'), so breaches raise alerts through the usual
+-- alert pipeline. The price monitor skips these rules.
+
+ALTER TABLE alert_rules DROP CONSTRAINT IF EXISTS alert_rules_rule_type_check;
+ALTER TABLE alert_rules ADD CONSTRAINT alert_rules_rule_type_check
+    CHECK (rule_type IN ('price_change', 'ma_cross', 'price_level', 'volatility_spike', 'risk_limit'));
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_alert_rules_user_risk_limit
+    ON alert_rules(user_id) WHERE rule_type = 'risk_limit';
+
+-- One row per limit breached per trading day. scope is the symbol key for
+-- per-symbol limits and empty for account-level ones, so re-evaluating a day
+-- never alerts twice. alert_id is null for breaches found on past days, which
+-- are recorded without notifying.
+CREATE TABLE IF NOT EXISTS risk_limit_breaches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    limit_type VARCHAR(30) NOT NULL CHECK (limit_type IN (
+        'max_daily_loss', 'max_trades_per_day', 'max_position_notional', 'max_leverage', 'loss_cooldown'
+    )),
+    trade_date DATE NOT NULL,
+    scope VARCHAR(40) NOT NULL DEFAULT '',
+    symbol VARCHAR(40) NOT NULL,
+    observed NUMERIC NOT NULL,
+    limit_value NUMERIC NOT NULL,
+    price NUMERIC NOT NULL,
+    triggered_at TIMESTAMPTZ NOT NULL,
+    alert_id UUID REFERENCES alerts(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, limit_type, trade_date, scope)
+);
+
+CREATE INDEX IF NOT EXISTS idx_risk_limit_breaches_user_date ON risk_limit_breaches(user_id, trade_date DESC);
+
+-- Daily discipline score: 100 minus a penalty per limit breached that day.
+CREATE TABLE IF NOT EXISTS discipline_scores (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    trade_date DATE NOT NULL,
+    score SMALLINT NOT NULL CHECK (score BETWEEN 0 AND 100),
+    trade_count INT NOT NULL DEFAULT 0,
+    breached_limits JSONB NOT NULL DEFAULT '[]'::jsonb,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, trade_date)
+);
@@ -0,0 +1,4 @@
+-- Every exchange trade ID a pack was computed from, so two packs can be
+-- compared for trades that appeared or disappeared between them. NULL on
+-- packs generated before this column existed.
+ALTER TABLE summary_packs ADD COLUMN IF NOT EXISTS evidence_trade_ids TEXT[];
@@ -0,0 +1,29 @@
+-- Per-user schedules that generate a summary pack from the latest completed
+-- run and deliver a digest. send_hour, weekday (0 = Sunday) and day_of_month
+-- are read in timezone; next_run_at is the next due time in UTC.
+CREATE TABLE IF NOT EXISTS summary_pack_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    frequency VARCHAR(10) NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly')),
+    range VARCHAR(20) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    send_hour SMALLINT NOT NULL DEFAULT 9 CHECK (send_hour BETWEEN 0 AND 23),
+    weekday SMALLINT NOT NULL DEFAULT 1 CHECK (weekday BETWEEN 0 AND 6),
+    day_of_month SMALLINT NOT NULL DEFAULT 1 CHECK (day_of_month BETWEEN 1 AND 28),
+    channels TEXT[] NOT NULL CHECK (cardinality(channels) > 0 AND channels <@ ARRAY['telegram', 'email']::TEXT[]),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    last_run_at TIMESTAMPTZ,
+    last_status VARCHAR(20),
+    last_error TEXT,
+    last_pack_id UUID REFERENCES summary_packs(pack_id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, frequency)
+);
+
+CREATE INDEX IF NOT EXISTS idx_summary_pack_schedules_due
+    ON summary_pack_schedules(next_run_at) WHERE enabled;
+
+-- Retention sweeps delete by age.
+CREATE INDEX IF NOT EXISTS idx_summary_packs_created_at ON summary_packs(created_at);
@@ -0,0 +1,13 @@
+-- The evidence bundle behind a pack's evidence_pack_ref: a zip of every
+-- trade the pack was computed from, the normalization applied, the
+-- reconciliation suspects and a manifest with the hash of each file. Packs
+-- generated before this table existed have no bundle.
+CREATE TABLE IF NOT EXISTS summary_pack_evidence_bundles (
+    pack_id UUID PRIMARY KEY REFERENCES summary_packs(pack_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    archive BYTEA NOT NULL,
+    sha256 VARCHAR(64) NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    manifest JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,16 @@
+-- Read-only share links for summary packs. The link token is signed by the
+-- server and not stored; this row is what makes it revocable and counted.
+CREATE TABLE IF NOT EXISTS summary_pack_shares (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pack_id UUID NOT NULL REFERENCES summary_packs(pack_id) ON DELETE CASCADE,
+    redactions TEXT[] NOT NULL DEFAULT '{}'
+        CHECK (redactions <@ ARRAY['amounts', 'evidence']::TEXT[]),
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    view_count INTEGER NOT NULL DEFAULT 0,
+    last_viewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_summary_pack_shares_pack ON summary_pack_shares(user_id, pack_id, created_at DESC);
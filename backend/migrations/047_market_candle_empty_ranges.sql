@@ -0,0 +1,12 @@
+-- Open-time ranges a venue returned no candles for (before listing, after
+-- delisting, maintenance holes), so reads stop re-fetching them.
+CREATE TABLE IF NOT EXISTS market_candle_empty_ranges (
+    venue VARCHAR(20) NOT NULL,
+    symbol VARCHAR(30) NOT NULL,
+    interval VARCHAR(10) NOT NULL,
+    start_time TIMESTAMPTZ NOT NULL,
+    end_time TIMESTAMPTZ NOT NULL,
+    checked_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (venue, symbol, interval, start_time),
+    CHECK (end_time >= start_time)
+);
@@ -0,0 +1,3 @@
+-- Count wrong guesses so a channel verification code locks after a few tries
+ALTER TABLE notification_verify_codes
+    ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
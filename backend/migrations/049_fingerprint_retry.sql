@@ -0,0 +1,18 @@
+-- Fingerprints that failed on a transient candle error are retried with
+-- backoff instead of being parked as unavailable for good.
+ALTER TABLE bubble_fingerprints DROP CONSTRAINT IF EXISTS bubble_fingerprints_status_check;
+ALTER TABLE bubble_fingerprints ADD CONSTRAINT bubble_fingerprints_status_check
+    CHECK (status IN ('ready', 'unavailable', 'retry'));
+
+ALTER TABLE bubble_fingerprints
+    ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS retry_after TIMESTAMPTZ;
+
+-- Unavailable rows written before this change may hide an exchange outage;
+-- give each one more pass.
+UPDATE bubble_fingerprints
+SET status = 'retry', retry_after = NOW()
+WHERE status = 'unavailable' AND attempts = 0;
+
+CREATE INDEX IF NOT EXISTS idx_bubble_fingerprints_retry
+    ON bubble_fingerprints(retry_after) WHERE status = 'retry';
@@ -0,0 +1,6 @@
+-- Each fill counts toward at most one plan. fill_ids records the fills a
+-- plan claimed so other plans on the same symbol skip them.
+ALTER TABLE plan_executions
+    ADD COLUMN IF NOT EXISTS fill_ids UUID[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_plan_executions_evaluated ON plan_executions(evaluated_at);
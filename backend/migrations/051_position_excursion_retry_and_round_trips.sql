@@ -0,0 +1,52 @@
+-- Excursions that failed on a transient candle error are retried with
+-- backoff instead of staying unavailable. fill_signature records the plan
+-- fills an excursion was computed from, so a change in fills recomputes it.
+-- Existing rows have no signature and are all picked up once more.
+ALTER TABLE position_excursions DROP CONSTRAINT IF EXISTS position_excursions_status_check;
+ALTER TABLE position_excursions ADD CONSTRAINT position_excursions_status_check
+    CHECK (status IN ('ready', 'unavailable', 'retry'));
+
+ALTER TABLE position_excursions
+    ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS retry_after TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS fill_signature TEXT;
+
+-- Round trips rebuilt from synced trades: a position on one exchange and
+-- symbol from flat to flat. Each carries its own excursion; there is no stop,
+-- so no R. fill_signature hashes the fills, and a changed signature resets
+-- the excursion to pending.
+CREATE TABLE IF NOT EXISTS trade_round_trips (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    exchange VARCHAR(30) NOT NULL,
+    symbol VARCHAR(40) NOT NULL,
+    position_side VARCHAR(5) NOT NULL CHECK (position_side IN ('long', 'short')),
+    opened_at TIMESTAMPTZ NOT NULL,
+    closed_at TIMESTAMPTZ NOT NULL,
+    qty NUMERIC NOT NULL,
+    entry_price NUMERIC NOT NULL,
+    exit_price NUMERIC NOT NULL,
+    trade_ids UUID[] NOT NULL,
+    fill_signature TEXT NOT NULL,
+    status VARCHAR(12) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'ready', 'unavailable', 'retry')),
+    attempts INT NOT NULL DEFAULT 0,
+    retry_after TIMESTAMPTZ,
+    candle_interval VARCHAR(5),
+    pnl_percent NUMERIC,
+    mae_percent NUMERIC,
+    mfe_percent NUMERIC,
+    mae_price NUMERIC,
+    mfe_price NUMERIC,
+    computed_at TIMESTAMPTZ,
+    UNIQUE (user_id, exchange, symbol, opened_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_trade_round_trips_user_closed ON trade_round_trips(user_id, closed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_trade_round_trips_pending
+    ON trade_round_trips(closed_at DESC) WHERE status IN ('pending', 'retry');
+
+-- When each user's trades were last rebuilt into round trips.
+CREATE TABLE IF NOT EXISTS trade_round_trip_scans (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    scanned_at TIMESTAMPTZ NOT NULL
+);
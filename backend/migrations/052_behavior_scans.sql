@@ -0,0 +1,7 @@
+-- When each user's behavior episodes were last re-detected, so the scan job
+-- works through users in turn instead of always starting with the most
+-- active ones.
+CREATE TABLE IF NOT EXISTS behavior_scans (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    scanned_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,42 @@
+-- users.timezone becomes the only stored timezone. 040 set every existing
+-- user to UTC; fill it from the notification preferences or a pack schedule
+-- where the user picked something other than UTC, and otherwise from
+-- Asia/Seoul, the timezone packs and the app assumed before.
+-- timezone_set_at stays NULL until the user saves a timezone, and until then
+-- the timezone the client sends with day-based requests is still honored.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone_set_at TIMESTAMPTZ;
+ALTER TABLE users ALTER COLUMN timezone SET DEFAULT 'Asia/Seoul';
+
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'notification_preferences' AND column_name = 'timezone'
+    ) THEN
+        UPDATE users u
+        SET timezone = np.timezone, timezone_set_at = np.updated_at
+        FROM notification_preferences np
+        WHERE np.user_id = u.id AND np.timezone <> 'UTC' AND u.timezone_set_at IS NULL;
+    END IF;
+
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'summary_pack_schedules' AND column_name = 'timezone'
+    ) THEN
+        UPDATE users u
+        SET timezone = s.timezone
+        FROM (
+            SELECT DISTINCT ON (user_id) user_id, timezone
+            FROM summary_pack_schedules
+            WHERE timezone <> 'UTC'
+            ORDER BY user_id, updated_at DESC
+        ) s
+        WHERE s.user_id = u.id AND u.timezone_set_at IS NULL;
+    END IF;
+END $$;
+
+UPDATE users SET timezone = 'Asia/Seoul' WHERE timezone = 'UTC' AND timezone_set_at IS NULL;
+
+-- Preferences and schedules read the profile timezone from now on.
+ALTER TABLE notification_preferences DROP COLUMN IF EXISTS timezone;
+ALTER TABLE summary_pack_schedules DROP COLUMN IF EXISTS timezone;
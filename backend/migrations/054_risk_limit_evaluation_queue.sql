@@ -0,0 +1,18 @@
+-- Risk limits are checked by a background job instead of inside the sync or
+-- import that stored the trades. Storing trades queues the user with the
+-- earliest execution time to re-check from; queued_at tells the job whether
+-- more trades arrived while it was evaluating.
+CREATE TABLE IF NOT EXISTS risk_limit_evaluations (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    pending_since TIMESTAMPTZ,
+    queued_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    evaluated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_risk_limit_evaluations_pending
+  ON risk_limit_evaluations(pending_since)
+  WHERE pending_since IS NOT NULL;
+
+-- The daily trade limit counts orders, and an order can fill in several
+-- trades. Trades synced before this have no order id and count one each.
+ALTER TABLE trades ADD COLUMN IF NOT EXISTS order_id VARCHAR(64);
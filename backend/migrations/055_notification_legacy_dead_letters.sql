@@ -0,0 +1,8 @@
+-- Failures logged before the outbox existed have no body to resend; park them
+-- as dead instead of letting the dispatcher pick them up as due. Outbox rows
+-- always carry at least one attempt when they fail, so reruns leave them alone.
+UPDATE notification_deliveries
+SET status = 'dead',
+    error = COALESCE(error, 'legacy delivery without body'),
+    updated_at = NOW()
+WHERE status = 'failed' AND attempts = 0 AND body = '';
@@ -0,0 +1,88 @@
+'use client'
+
+import { useEffect, useState } from 'react'
+import { useParams } from 'next/navigation'
+import Link from 'next/link'
+import { api } from '../../../../src/lib/api'
+
+export default function PackReportPage() {
+  const params = useParams()
+  const id = params.id as string
+  const [html, setHtml] = useState<string | null>(null)
+  const [error, setError] = useState('')
+
+  useEffect(() => {
+    if (!id) return
+    let cancelled = false
+    setHtml(null)
+    setError('')
+    api
+      .get<string>(`/v1/packs/${id}/report`, { responseType: 'text' })
+      .then((response) => {
+        if (!cancelled) setHtml(response.data)
+      })
+      .catch((err: any) => {
+        if (!cancelled) setError(err?.response?.status === 404 ? '요약 팩을 찾을 수 없습니다.' : '리포트를 불러오지 못했습니다.')
+      })
+    return () => {
+      cancelled = true
+    }
+  }, [id])
+
+  const onDownloadPDF = async () => {
+    try {
+      const response = await api.get<Blob>(`/v1/packs/${id}/report`, { params: { format: 'pdf' }, responseType: 'blob' })
+      const url = URL.createObjectURL(response.data)
+      const link = document.createElement('a')
+      link.href = url
+      link.download = `kifu-pack-${id}.pdf`
+      document.body.appendChild(link)
+      link.click()
+      document.body.removeChild(link)
+      URL.revokeObjectURL(url)
+    } catch {
+      setError('PDF를 내려받지 못했습니다.')
+    }
+  }
+
+  return (
+    <div className="flex flex-col gap-6">
+      <header className="flex items-center justify-between gap-4 rounded-2xl border border-white/[0.08] bg-white/[0.04] p-6">
+        <div>
+          <div className="flex items-center gap-2 text-xs text-neutral-500 mb-2">
+            <Link href="/settings" className="hover:text-neutral-300 transition">
+              설정
+            </Link>
+            <span>/</span>
+            <span>요약 팩</span>
+          </div>
+          <h2 className="text-lg font-semibold text-neutral-100">요약 팩 리포트</h2>
+          <p className="mt-1 text-xs text-neutral-500">{id}</p>
+        </div>
+        <button
+          type="button"
+          onClick={onDownloadPDF}
+          disabled={!html}
+          className="shrink-0 rounded-lg border border-neutral-700 px-3 py-1.5 text-xs text-neutral-400 hover:text-neutral-200 transition disabled:opacity-40"
+        >
+          PDF 다운로드
+        </button>
+      </header>
+
+      {error && (
+        <div className="rounded-lg border border-red-500/40 bg-red-500/10 p-4 text-sm text-red-200">{error}</div>
+      )}
+
+      {!error && html === null && <div className="h-96 animate-pulse rounded-2xl bg-white/[0.04]" />}
+
+      {html !== null && (
+        <iframe
+          title="요약 팩 리포트"
+          srcDoc={html}
+          sandbox=""
+          className="h-[80vh] w-full rounded-2xl border border-white/[0.08] bg-white"
+        />
+      )}
+    </div>
+  )
+}